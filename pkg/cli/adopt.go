@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	adoptLabel string
+
+	adoptCmd = &cobra.Command{
+		Use:   "adopt [CONTAINER...]",
+		Short: "Bring running docker in docker containers forming a swarm under sind management.",
+		Run:   runAdopt,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(adoptCmd)
+
+	adoptCmd.Flags().StringVarP(&adoptLabel, "label", "l", "", "Adopt the containers having this label (key or key=value), instead of the given containers.")
+}
+
+func runAdopt(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	defer lockHost(client)()
+
+	disgo.StartStepf("Adopting the containers as cluster %q", clusterName)
+
+	adoptConfig := sind.AdoptConfiguration{
+		ClusterName: clusterBaseName,
+		Namespace:   namespace,
+		Containers:  args,
+		Label:       adoptLabel,
+	}
+
+	if err = sind.AdoptCluster(ctx, client, adoptConfig); err != nil {
+		fail(disgo.FailStepf("Unable to adopt the containers: %v", err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Cluster %q successfully adopted\n", style.Success(style.SymbolCheck), clusterName)
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	autoStopCmd = &cobra.Command{
+		Use:   "autostop",
+		Short: "Stop the clusters created with an idle timeout once idle for longer than it, until interrupted.",
+		Run:   runAutoStop,
+	}
+
+	autoStopInterval time.Duration
+	autoStopPolicy   string
+	autoStopDrain    bool
+)
+
+func init() {
+	rootCmd.AddCommand(autoStopCmd)
+
+	autoStopCmd.Flags().DurationVarP(&autoStopInterval, "interval", "", sind.DefaultAutoStopInterval, "Time between two checks of the activity of the clusters.")
+	autoStopCmd.Flags().StringVarP(&autoStopPolicy, "policy", "", string(sind.IdleNoActivity), "Idle when: no-activity (no swarm API call) or no-tasks (no running task).")
+	autoStopCmd.Flags().BoolVarP(&autoStopDrain, "drain", "", false, "Drain the swarm nodes before stopping them.")
+}
+
+func runAutoStop(cmd *cobra.Command, args []string) {
+	// The clusters are watched until interrupted, the command timeout does not apply.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	opts := sind.AutoStopOptions{
+		Interval:  autoStopInterval,
+		Policy:    sind.IdlePolicy(autoStopPolicy),
+		Namespace: namespace,
+		Stop:      sind.StopOptions{Drain: autoStopDrain},
+	}
+
+	out := make(chan sind.AutoStopEvent)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- sind.AutoStop(ctx, client, opts, out)
+	}()
+
+	disgo.Infof("Watching the activity of the clusters every %s\n", autoStopInterval)
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fail(disgo.FailStepf("Unable to auto stop clusters: %v", err))
+			}
+
+			return
+		case event := <-out:
+			if event.Err != nil {
+				disgo.Errorf("%s %s Cluster %q: %v\n", event.Time.Format(time.RFC3339), style.Failure(style.SymbolCross), event.Cluster, event.Err)
+				continue
+			}
+
+			disgo.Infof("%s %s Stopped cluster %q, idle for %s\n", event.Time.Format(time.RFC3339), style.Success(style.SymbolCheck), event.Cluster, event.IdleFor.Round(time.Second))
+		}
+	}
+}
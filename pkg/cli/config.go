@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	configCmd = &cobra.Command{
+		Use:   "config",
+		Short: "Manage the configs of a cluster.",
+	}
+
+	configCreateCmd = &cobra.Command{
+		Use:   "create NAME [FILE|-]",
+		Short: "Create a config from a file, stdin or a literal.",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runConfigCreate,
+	}
+
+	configListCmd = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the configs of the cluster.",
+		Run:     runConfigList,
+	}
+
+	configRemoveCmd = &cobra.Command{
+		Use:     "rm CONFIG [CONFIG...]",
+		Aliases: []string{"remove"},
+		Short:   "Remove configs from the cluster.",
+		Args:    cobra.MinimumNArgs(1),
+		Run:     runConfigRemove,
+	}
+
+	configLiteral string
+)
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configCreateCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configRemoveCmd)
+
+	configCreateCmd.Flags().StringVarP(&configLiteral, "literal", "l", "", "Value of the config, instead of reading it from a file.")
+}
+
+func runConfigCreate(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	var path string
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	data, err := internal.ReadData(path, configLiteral)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to read the config: %v", err))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Creating config %q on cluster %q", args[0], clusterName)
+
+	if _, err = sind.CreateConfig(ctx, client, clusterName, args[0], data); err != nil {
+		fail(disgo.FailStepf("Unable to create config %q: %v", args[0], err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Config %q successfully created\n", style.Success(style.SymbolCheck), args[0])
+}
+
+func runConfigList(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	configs, err := sind.ListConfigs(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the configs of cluster %q: %v", clusterName, err))
+	}
+
+	internal.RenderSwarmDataList(os.Stdout, configs)
+}
+
+func runConfigRemove(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	for _, name := range args {
+		disgo.StartStepf("Removing config %q from cluster %q", name, clusterName)
+
+		if err = sind.RemoveConfig(ctx, client, clusterName, name); err != nil {
+			fail(disgo.FailStepf("Unable to remove config %q: %v", name, err))
+		}
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Config(s) successfully removed\n", style.Success(style.SymbolCheck))
+}
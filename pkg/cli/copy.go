@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	copyCmd = &cobra.Command{
+		Use:   "cp [--node NODE]... LOCAL_PATH NODE_PATH | NODE:NODE_PATH LOCAL_PATH",
+		Short: "Copy a local file or directory to the nodes of the cluster, or from one of them.",
+		Args:  cobra.ExactArgs(2),
+		Run:   runCopy,
+	}
+
+	copyNodes []string
+	copyJobs  int
+)
+
+func init() {
+	rootCmd.AddCommand(copyCmd)
+
+	copyCmd.Flags().StringSliceVarP(&copyNodes, "node", "", []string{}, "Only copy to given nodes (e.g. manager-0).")
+	copyCmd.Flags().IntVarP(&copyJobs, "jobs", "j", 0, "How many copies in parallel (0 means all the nodes).")
+}
+
+func runCopy(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	if node, nodePath, ok := nodePathArg(args[0]); ok {
+		copyFromNode(ctx, client, node, nodePath, args[1])
+		return
+	}
+
+	disgo.StartStepf("Copying %q to %q on the nodes of cluster %q", args[0], args[1], clusterName)
+
+	err = sind.CopyToNodes(
+		ctx,
+		client,
+		clusterName,
+		sind.CopyConfiguration{
+			SourcePath: args[0],
+			TargetPath: args[1],
+			Nodes:      copyNodes,
+			Jobs:       copyJobs,
+		},
+	)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to copy %q to cluster %q: %v", args[0], clusterName, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Successfully copied %q to cluster %q\n", style.Success(style.SymbolCheck), args[0], clusterName)
+}
+
+func copyFromNode(ctx context.Context, client *docker.Client, node, nodePath, localPath string) {
+	disgo.StartStepf("Copying %q from node %q of cluster %q to %q", nodePath, node, clusterName, localPath)
+
+	if err := sind.CopyFromNode(ctx, client, clusterName, node, nodePath, localPath); err != nil {
+		fail(disgo.FailStepf("Unable to copy %q from node %q: %v", nodePath, node, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Successfully copied %q from node %q to %q\n", style.Success(style.SymbolCheck), nodePath, node, localPath)
+}
+
+// nodePathArg splits a NODE:NODE_PATH argument, node paths being absolute to tell them apart from windows local paths.
+func nodePathArg(arg string) (string, string, bool) {
+	parts := strings.SplitN(arg, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || !strings.HasPrefix(parts[1], "/") {
+		return "", "", false
+	}
+
+	return parts[0], parts[1], true
+}
@@ -2,9 +2,17 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"syscall"
+	"time"
 
 	docker "github.com/docker/docker/client"
+	"github.com/docker/go-units"
 	"github.com/jlevesy/sind/pkg/cli/internal"
 	"github.com/jlevesy/sind/pkg/sind"
 	"github.com/spf13/cobra"
@@ -13,13 +21,68 @@ import (
 )
 
 var (
-	managers      uint16
-	workers       uint16
-	networkName   string
-	portsMapping  []string
-	nodeImageName string
-	daemonArgs    []string
-	pull          bool
+	specFile       string
+	managers       uint16
+	workers        uint16
+	networkName    string
+	portsMapping   []string
+	nodeImageName  string
+	daemonArgs     []string
+	pull           bool
+	loadBalancer   bool
+	force          bool
+	totalMemory    string
+	totalCPUs      float64
+	ttl            time.Duration
+	idleTimeout    time.Duration
+	preHooks       []string
+	postHooks      []string
+	swarmNetworks  []string
+	swarmSecrets   []string
+	swarmConfigs   []string
+	nodeLabels     []string
+	netDriver      string
+	netOptions     []string
+	subnet         string
+	existingNet    string
+	gateway        string
+	ipRange        string
+	auxAddresses   []string
+	nodeAddrs      []string
+	roleRanges     bool
+	airGapped      bool
+	restrictEgress bool
+	egressAllow    []string
+	portsOn        string
+	daemonPort     uint16
+	nodeRuntime    string
+	devices        []string
+	pidsLimit      int64
+	memorySwap     string
+	blkioWeight    uint16
+	registryAuths  []string
+	contentTrust   bool
+	trustServer    string
+	metadata       []string
+	experimental   bool
+	buildKit       bool
+	containerdIS   bool
+	maxAttempts    int
+	plain          bool
+	daemonTLS      bool
+	benchmark      bool
+	progress       bool
+	keepOnFailure  bool
+	dryRun         bool
+	ifNotExists    bool
+	recreate       bool
+	provision      bool
+	clusterCount   int
+	dryRunOutput   string
+	endpointFile   string
+	githubActions  bool
+	managerAvail   string
+	workerAvail    string
 
 	createCmd = &cobra.Command{
 		Use:   "create",
@@ -31,13 +94,68 @@ var (
 func init() {
 	rootCmd.AddCommand(createCmd)
 
+	createCmd.Flags().StringVarP(&specFile, "file", "f", "", "YAML file declaring the cluster, the flags taking precedence over it.")
 	createCmd.Flags().Uint16VarP(&managers, "managers", "m", 1, "Amount of managers in the created cluster.")
 	createCmd.Flags().Uint16VarP(&workers, "workers", "w", 0, "Amount of workers in the created cluster.")
 	createCmd.Flags().StringVarP(&networkName, "network-name", "n", "sind-default", "Name of the network to create.")
-	createCmd.Flags().StringSliceVarP(&portsMapping, "ports", "p", []string{}, "Ingress network port binding.")
+	createCmd.Flags().StringVarP(&existingNet, "existing-network", "", "", "ID or name of an existing network to attach the nodes to, instead of creating one.")
+	createCmd.Flags().StringVarP(&netDriver, "network-driver", "", "bridge", "Driver of the network to create (bridge, macvlan, ipvlan).")
+	createCmd.Flags().StringSliceVarP(&netOptions, "network-opt", "", []string{}, "Driver options of the network to create (key=value).")
+	createCmd.Flags().StringVarP(&subnet, "subnet", "", "", "Subnet of the network to create (random 10.0.X.0/24 if empty).")
+	createCmd.Flags().StringVarP(&gateway, "gateway", "", "", "Gateway of the network to create, requires --subnet.")
+	createCmd.Flags().StringVarP(&ipRange, "ip-range", "", "", "Range of the subnet to allocate node addresses from, requires --subnet.")
+	createCmd.Flags().StringSliceVarP(&auxAddresses, "aux-address", "", []string{}, "Addresses of the subnet to leave to the network driver (name=address), requires --subnet.")
+	createCmd.Flags().StringSliceVarP(&nodeAddrs, "node-address", "", []string{}, "Fixed node addresses, primary then managers and workers, requires --subnet or --existing-network.")
+	createCmd.Flags().BoolVarP(&roleRanges, "role-address-ranges", "", false, "Address managers from the first half of the subnet and workers from its second half.")
+	createCmd.Flags().BoolVarP(&airGapped, "air-gapped", "", false, "Create an internal network without access to external networks, checking the nodes have no default route.")
+	createCmd.Flags().BoolVarP(&restrictEgress, "restrict-egress", "", false, "Only let the nodes reach the cluster network and the destinations of --egress-allow.")
+	createCmd.Flags().StringSliceVarP(&egressAllow, "egress-allow", "", []string{}, "IPv4 addresses or CIDRs the nodes can reach, requires --restrict-egress.")
+	createCmd.Flags().StringSliceVarP(&portsMapping, "ports", "p", []string{}, "Ingress network port bindings, e.g. 8080:80 or 8000-8010:8000-8010.")
+	createCmd.Flags().StringVarP(&portsOn, "publish-ports-on", "", "primary", "Nodes to bind the ports on (primary, managers, all), see sind port for the host ports of the others.")
+	createCmd.Flags().Uint16VarP(&daemonPort, "daemon-port", "", 0, "Port of the docker host to publish the docker daemon of the primary node on (random if 0).")
+	createCmd.Flags().StringVarP(&nodeRuntime, "runtime", "", "", "Container runtime of the nodes (e.g. runsc, kata-runtime), the docker host default if empty.")
+	createCmd.Flags().StringSliceVarP(&devices, "device", "", []string{}, "Host devices to map into the nodes (host-path[:container-path][:permissions]).")
 	createCmd.Flags().StringSliceVarP(&daemonArgs, "daemon-arg", "", []string{}, "Args to pass to nodes docker daemon")
+	createCmd.Flags().BoolVarP(&experimental, "experimental", "", false, "Enable experimental features of the nodes docker daemons.")
+	createCmd.Flags().BoolVarP(&buildKit, "buildkit", "", false, "Build images with BuildKit on the nodes.")
+	createCmd.Flags().StringArrayVarP(&registryAuths, "registry-auth", "", []string{}, "Credentials of a private registry installed in the nodes (registry=username:password).")
+	createCmd.Flags().BoolVarP(&contentTrust, "content-trust", "", false, "Enable Docker Content Trust for the docker CLI of the nodes.")
+	createCmd.Flags().StringVarP(&trustServer, "content-trust-server", "", "", "Notary server of Docker Content Trust, requires --content-trust.")
+	createCmd.Flags().BoolVarP(&containerdIS, "containerd-image-store", "", false, "Use the containerd image store in the nodes docker daemons (requires docker >= 24).")
 	createCmd.Flags().StringVarP(&nodeImageName, "image", "i", sind.DefaultNodeImageName, "Name of the image to use for the nodes.")
 	createCmd.Flags().BoolVarP(&pull, "pull", "", false, "Pull node image before creating the cluster.")
+	createCmd.Flags().StringVarP(&totalMemory, "total-memory", "", "", "Memory budget shared by all nodes (e.g. 8g).")
+	createCmd.Flags().Int64VarP(&pidsLimit, "pids-limit", "", 0, "Maximum amount of processes of each node (-1 for unlimited).")
+	createCmd.Flags().StringVarP(&memorySwap, "memory-swap", "", "", "Memory and swap limit of each node (e.g. 4g, -1 for unlimited swap), requires --total-memory.")
+	createCmd.Flags().Uint16VarP(&blkioWeight, "blkio-weight", "", 0, "Relative block IO weight of the nodes, between 10 and 1000.")
+	createCmd.Flags().Float64VarP(&totalCPUs, "total-cpus", "", 0, "CPU budget shared by all nodes.")
+	createCmd.Flags().DurationVarP(&ttl, "ttl", "", 0, "Time to live of the cluster, after which it is garbage collected (0 means forever).")
+	createCmd.Flags().DurationVarP(&idleTimeout, "idle-timeout", "", 0, "Duration without activity after which the cluster is stopped by sind autostop (0 means never).")
+	createCmd.Flags().StringArrayVarP(&preHooks, "pre-create-hook", "", []string{}, "Shell script to run before creating the cluster, given SIND_CLUSTER and SIND_METADATA_<KEY>.")
+	createCmd.Flags().StringArrayVarP(&postHooks, "post-create-hook", "", []string{}, "Shell script to run once the cluster is ready, DOCKER_HOST targeting it.")
+	createCmd.Flags().StringArrayVarP(&swarmNetworks, "swarm-network", "", []string{}, "Overlay network to create on the swarm (NAME[,attachable][,internal][,subnet=CIDR]).")
+	createCmd.Flags().StringArrayVarP(&swarmSecrets, "swarm-secret", "", []string{}, "Secret to create on the swarm from a file (NAME=FILE).")
+	createCmd.Flags().StringArrayVarP(&swarmConfigs, "swarm-config", "", []string{}, "Config to create on the swarm from a file (NAME=FILE).")
+	createCmd.Flags().StringArrayVarP(&nodeLabels, "node-label", "", []string{}, "Label to add to a swarm node (NODE:KEY=VALUE, e.g. worker-0:zone=a).")
+	createCmd.Flags().StringSliceVarP(&metadata, "metadata", "", []string{}, "Metadata to attach to the cluster (key=value).")
+	createCmd.Flags().IntVarP(&maxAttempts, "max-attempts", "", sind.DefaultRetryPolicy.MaxAttempts, "Maximum attempts of the node operations failing with transient errors.")
+	createCmd.Flags().BoolVarP(&force, "force", "", false, "Skip the docker host capacity check.")
+	createCmd.Flags().BoolVarP(&plain, "plain", "", false, "Create plain docker daemons without forming a swarm, each of them published on the docker host.")
+	createCmd.Flags().BoolVarP(&daemonTLS, "tls", "", false, "Secure the daemon port of the nodes with mutual TLS, using certificates generated for the cluster.")
+	createCmd.Flags().BoolVarP(&ifNotExists, "if-not-exists", "", false, "Succeed without creating anything if a healthy cluster with compatible parameters already exists.")
+	createCmd.Flags().BoolVarP(&recreate, "recreate", "", false, "Delete any existing cluster with the same name before creating it.")
+	createCmd.Flags().BoolVarP(&provision, "provision", "", false, "Only create the stopped nodes of the cluster, a later create of the same cluster claims them.")
+	createCmd.Flags().IntVarP(&clusterCount, "count", "", 1, "Amount of independent clusters to create at once, named after the cluster name suffixed with their index.")
+	createCmd.Flags().BoolVarP(&dryRun, "dry-run", "", false, "Print what would be created on the docker host, without creating anything.")
+	createCmd.Flags().StringVarP(&dryRunOutput, "output", "o", "text", "Output format of the dry run (text, json).")
+	createCmd.Flags().StringVarP(&endpointFile, "output-endpoint", "", "", "Write the connection details of the created cluster to this JSON file.")
+	createCmd.Flags().BoolVarP(&githubActions, "github-actions", "", false, "Export the cluster name and docker host to the outputs and environment of the GitHub Actions workflow.")
+	createCmd.Flags().BoolVarP(&benchmark, "benchmark", "", false, "Report how long each phase of the creation took.")
+	createCmd.Flags().BoolVarP(&keepOnFailure, "keep-on-failure", "", false, "Keep the network and the nodes of a failed creation to debug it, instead of removing them.")
+	createCmd.Flags().BoolVarP(&progress, "progress", "", false, "Report the progress of the creation: image pulls, network, nodes started and joining the swarm.")
+	createCmd.Flags().BoolVarP(&loadBalancer, "load-balancer", "", false, "Bind ports on a load balancer spreading traffic across all nodes.")
+	createCmd.Flags().StringVarP(&managerAvail, "manager-availability", "", "", "Availability the managers join the swarm with (active, drain, pause).")
+	createCmd.Flags().StringVarP(&workerAvail, "worker-availability", "", "", "Availability the workers join the swarm with (active, drain, pause).")
 }
 
 func runCreate(cmd *cobra.Command, args []string) {
@@ -47,6 +165,66 @@ func runCreate(cmd *cobra.Command, args []string) {
 	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	retryPolicy := sind.DefaultRetryPolicy
+	retryPolicy.MaxAttempts = maxAttempts
+	ctx = sind.WithRetryPolicy(ctx, retryPolicy)
+
+	if specFile != "" {
+		spec, err := sind.LoadClusterSpec(specFile)
+		if err != nil {
+			fail(disgo.FailStepf("Unable to load the cluster spec: %v", err))
+		}
+
+		applyClusterSpec(cmd.Flags(), spec)
+	}
+
+	networkOptions, err := parseKeyValues(netOptions)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid network options: %v", err))
+	}
+
+	networkAuxAddresses, err := parseKeyValues(auxAddresses)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid auxiliary addresses: %v", err))
+	}
+
+	clusterMetadata, err := parseKeyValues(metadata)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid metadata: %v", err))
+	}
+
+	registryCredentials, err := parseRegistryAuths(registryAuths)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid registry credentials: %v", err))
+	}
+
+	bootstrap, err := internal.ParseBootstrap(swarmNetworks, swarmSecrets, swarmConfigs, nodeLabels)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid swarm bootstrap: %v", err))
+	}
+
+	var memoryBudget int64
+
+	if totalMemory != "" {
+		memoryBudget, err = units.RAMInBytes(totalMemory)
+		if err != nil {
+			fail(disgo.FailStepf("Invalid total memory %q: %v", totalMemory, err))
+		}
+	}
+
+	var swapLimit int64
+
+	switch memorySwap {
+	case "":
+	case "-1":
+		swapLimit = -1
+	default:
+		swapLimit, err = units.RAMInBytes(memorySwap)
+		if err != nil {
+			fail(disgo.FailStepf("Invalid memory swap %q: %v", memorySwap, err))
+		}
+	}
+
 	disgo.StartStep("Connecting to the docker daemon")
 
 	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
@@ -54,35 +232,300 @@ func runCreate(cmd *cobra.Command, args []string) {
 		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
 	}
 
-	disgo.StartStepf("Checking if a cluster named %q already exists", clusterName)
+	var clusterInfo *sind.ClusterStatus
 
-	clusterInfo, err := sind.InspectCluster(ctx, client, clusterName)
-	if err != nil {
-		fail(disgo.FailStepf("Unable to check if the cluster already exists: %v", err))
-	}
+	// Clusters created at once are suffixed, no cluster is named after the cluster name.
+	if clusterCount == 1 {
+		disgo.StartStepf("Checking if a cluster named %q already exists", clusterName)
 
-	// If cluster info is not nil, then the cluster exist.
-	if clusterInfo != nil {
-		fail(disgo.FailStepf("Cluster %q already exists, run sind delete first to remove it.", clusterName))
+		clusterInfo, err = sind.InspectCluster(ctx, client, clusterName)
+		if err != nil {
+			fail(disgo.FailStepf("Unable to check if the cluster already exists: %v", err))
+		}
 	}
 
-	disgo.StartStepf("Creating a new cluster %q with %d managers and %d workers", clusterName, managers, workers)
+	// A provisioned cluster is claimed by the creation.
+	claim := clusterInfo != nil && clusterInfo.Provisioned && !provision
 
 	clusterConfig := sind.ClusterConfiguration{
 		Managers:     managers,
 		Workers:      workers,
 		NetworkName:  networkName,
-		ClusterName:  clusterName,
+		ClusterName:  clusterBaseName,
+		Namespace:    namespace,
 		PortBindings: portsMapping,
 		ImageName:    nodeImageName,
 		PullImage:    pull,
 		DaemonArgs:   daemonArgs,
+		Experimental: experimental,
+		BuildKit:     buildKit,
+		LoadBalancer: loadBalancer,
+		Plain:        plain,
+		TLS:          daemonTLS,
+		TotalMemory:  memoryBudget,
+		MemorySwap:   swapLimit,
+		PidsLimit:    pidsLimit,
+		BlkioWeight:  blkioWeight,
+		TotalCPU:     totalCPUs,
+		TTL:          ttl,
+		IdleTimeout:  idleTimeout,
+		Metadata:     clusterMetadata,
+
+		ContainerdImageStore: containerdIS,
+		PublishPortsOn:       sind.PublishPortsOn(portsOn),
+		DaemonHostPort:       daemonPort,
+		Runtime:              nodeRuntime,
+		Devices:              devices,
+		RegistryAuths:        registryCredentials,
+		ContentTrust:         contentTrust,
+		ContentTrustServer:   trustServer,
+
+		NetworkDriver:        netDriver,
+		NetworkDriverOptions: networkOptions,
+		NetworkSubnet:        subnet,
+		ExistingNetwork:      existingNet,
+		NetworkGateway:       gateway,
+		NetworkIPRange:       ipRange,
+		NetworkAuxAddresses:  networkAuxAddresses,
+		NodeAddresses:        nodeAddrs,
+		RoleAddressRanges:    roleRanges,
+		AirGapped:            airGapped,
+		RestrictEgress:       restrictEgress,
+		EgressAllowlist:      egressAllow,
+
+		AdoptExisting:     ifNotExists,
+		Recreate:          recreate,
+		Provision:         provision,
+		SkipCapacityCheck: force,
+
+		ManagerAvailability: managerAvail,
+		WorkerAvailability:  workerAvail,
+
+		Bootstrap: bootstrap,
+
+		PreCreateHooks:  internal.ScriptHooks(preHooks),
+		PostCreateHooks: internal.ScriptHooks(postHooks),
+
+		KeepOnFailure: keepOnFailure,
+	}
+
+	if (endpointFile != "" || githubActions) && (clusterCount != 1 || dryRun || provision) {
+		fail(disgo.FailStepf("The connection details can only be exported for a single cluster created and started"))
+	}
+
+	if dryRun {
+		if clusterCount != 1 {
+			fail(disgo.FailStepf("A dry run can't create several clusters at once"))
+		}
+
+		runCreatePlan(ctx, client, clusterConfig)
+		return
+	}
+
+	// A dry run changes nothing, only an actual creation locks the host.
+	defer lockHost(client)()
+
+	if clusterCount != 1 {
+		runCreateBulk(ctx, client, clusterConfig)
+		return
 	}
 
-	if err := sind.CreateCluster(ctx, client, clusterConfig); err != nil {
-		fail(disgo.FailStepf("Unable to create cluster %q: %v", clusterName, err))
+	disgo.StartStepf("Creating a new cluster %q with %d managers and %d workers", clusterName, managers, workers)
+
+	if progress {
+		clusterConfig.OnEvent = func(event sind.CreateEvent) {
+			disgo.Infof("  %s\n", event)
+		}
+	}
+
+	timings, err := sind.CreateClusterWithTimings(ctx, client, clusterConfig)
+	if errors.Is(err, sind.ErrClusterAlreadyExists) {
+		fail(disgo.FailStepf("Cluster %q already exists, run sind delete first to remove it.", clusterName))
+	}
+
+	if err != nil {
+		failure := disgo.FailStepf("Unable to create cluster %q: %v", clusterName, err)
+
+		// An existing cluster, adopted or claimed, is left as is.
+		if internal.Interrupted(ctx) && (clusterInfo == nil || recreate) {
+			reportClusterLeftovers(client, clusterName)
+		}
+
+		fail(failure)
 	}
 
 	disgo.EndStep()
+
+	if clusterInfo != nil && !claim && ifNotExists {
+		disgo.Infof("%s Cluster %q already exists and is compatible, nothing to do\n", style.Success(style.SymbolCheck), clusterName)
+
+		exportConnection(ctx, client)
+
+		return
+	}
+
+	if provision {
+		disgo.Infof("%s Cluster %q successfully provisioned\n", style.Success(style.SymbolCheck), clusterName)
+		return
+	}
+
 	disgo.Infof("%s Cluster %q successfully created\n", style.Success(style.SymbolCheck), clusterName)
+
+	if benchmark {
+		internal.RenderCreateTimings(os.Stdout, timings)
+	}
+
+	exportConnection(ctx, client)
+
+	if !plain {
+		return
+	}
+
+	endpoints, err := sind.NodeEndpoints(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to get the nodes endpoints: %v", err))
+	}
+
+	for _, endpoint := range endpoints {
+		disgo.Infof("%s\t%s\n", endpoint.Name, endpoint.Host)
+	}
+}
+
+// exportConnection exports the connection details of the cluster as requested by the flags.
+func exportConnection(ctx context.Context, client *docker.Client) {
+	if endpointFile != "" {
+		writeEndpointFile(ctx, client, endpointFile)
+	}
+
+	if !githubActions {
+		return
+	}
+
+	host, err := sind.ClusterHost(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to get the cluster host: %v", err))
+	}
+
+	if err = internal.ExportGitHubActions(clusterName, host); err != nil {
+		fail(disgo.FailStepf("Unable to export the cluster to GitHub Actions: %v", err))
+	}
+
+	disgo.Infof("%s Cluster exported to the GitHub Actions workflow\n", style.Success(style.SymbolCheck))
+}
+
+// writeEndpointFile writes the connection details of the cluster to a JSON file only readable by its owner, as it holds
+// the swarm join tokens.
+func writeEndpointFile(ctx context.Context, client *docker.Client, path string) {
+	connection, err := sind.ConnectionDetails(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to get the cluster connection details: %v", err))
+	}
+
+	content, err := json.MarshalIndent(connection, "", "  ")
+	if err != nil {
+		fail(disgo.FailStepf("Unable to encode the cluster connection details: %v", err))
+	}
+
+	if err = ioutil.WriteFile(path, append(content, '\n'), 0600); err != nil {
+		fail(disgo.FailStepf("Unable to write the cluster connection details: %v", err))
+	}
+
+	disgo.Infof("%s Connection details written to %s\n", style.Success(style.SymbolCheck), path)
+}
+
+// parseRegistryAuths parses registry credentials formatted as registry=username:password.
+func parseRegistryAuths(rawAuths []string) (map[string]sind.RegistryAuth, error) {
+	values, err := parseKeyValues(rawAuths)
+	if err != nil {
+		return nil, err
+	}
+
+	auths := make(map[string]sind.RegistryAuth, len(values))
+
+	for registry, credentials := range values {
+		parts := strings.SplitN(credentials, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("credentials of registry %q are not formatted as username:password", registry)
+		}
+
+		auths[registry] = sind.RegistryAuth{Username: parts[0], Password: parts[1]}
+	}
+
+	return auths, nil
+}
+
+func runCreateBulk(ctx context.Context, client *docker.Client, clusterConfig sind.ClusterConfiguration) {
+	existing, err := sind.ListClusters(ctx, client)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the existing clusters: %v", err))
+	}
+
+	disgo.StartStepf("Creating %d clusters with %d managers and %d workers", clusterCount, managers, workers)
+
+	endpoints, err := sind.CreateClusters(ctx, client, clusterConfig, clusterCount)
+	if err != nil {
+		failure := disgo.FailStepf("Unable to create clusters: %v", err)
+
+		if internal.Interrupted(ctx) {
+			reportClusterLeftovers(client, createdClusters(existing)...)
+		}
+
+		fail(failure)
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s %d clusters successfully created\n", style.Success(style.SymbolCheck), clusterCount)
+
+	for _, endpoint := range endpoints {
+		disgo.Infof("%s\t%s\n", endpoint.Name, endpoint.Host)
+	}
+}
+
+func runCreatePlan(ctx context.Context, client *docker.Client, clusterConfig sind.ClusterConfiguration) {
+	if dryRunOutput != "text" && dryRunOutput != "json" {
+		fail(disgo.FailStepf("Invalid output format %q, expected text or json", dryRunOutput))
+	}
+
+	disgo.StartStepf("Planning the creation of cluster %q", clusterName)
+
+	plan, err := sind.PlanCluster(ctx, client, clusterConfig)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid cluster %q: %v", clusterName, err))
+	}
+
+	disgo.EndStep()
+
+	if dryRunOutput == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+
+		if err = encoder.Encode(plan); err != nil {
+			fail(disgo.FailStepf("Unable to encode the plan: %v", err))
+		}
+
+		return
+	}
+
+	internal.RenderClusterPlan(os.Stdout, plan)
+}
+
+// createdClusters returns the names of the clusters a bulk creation creates, except the existing ones.
+func createdClusters(existing []sind.ClusterStatus) []string {
+	exists := make(map[string]bool, len(existing))
+
+	for _, cluster := range existing {
+		exists[cluster.Name] = true
+	}
+
+	var names []string
+
+	for i := 0; i < clusterCount; i++ {
+		name := sind.NamespacedName(namespace, fmt.Sprintf("%s-%d", clusterBaseName, i))
+
+		if !exists[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
 }
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+)
+
+var (
+	curlCmd = &cobra.Command{
+		Use:   "curl [--network NETWORK] -- CURL_ARGS...",
+		Short: "Send an HTTP request with curl from inside the cluster.",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runCurl,
+	}
+
+	curlNetwork string
+	curlImage   string
+)
+
+func init() {
+	rootCmd.AddCommand(curlCmd)
+
+	curlCmd.Flags().StringVarP(&curlNetwork, "network", "", "", "Attachable swarm network to send the request from, to reach services by name.")
+	curlCmd.Flags().StringVarP(&curlImage, "image", "", sind.DefaultCurlImageName, "Image providing curl.")
+}
+
+func runCurl(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	curlCfg := sind.CurlConfig{
+		Network:   curlNetwork,
+		ImageName: curlImage,
+		Args:      args,
+	}
+
+	result, err := sind.Curl(ctx, client, clusterName, curlCfg)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to run curl in cluster %q: %v", clusterName, err))
+	}
+
+	fmt.Fprint(os.Stdout, result.Stdout)
+	fmt.Fprint(os.Stderr, result.Stderr)
+
+	os.Exit(result.ExitCode)
+}
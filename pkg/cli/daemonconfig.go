@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	daemonConfigCmd = &cobra.Command{
+		Use:   "daemon-config FILE|-",
+		Short: "Update the docker daemon configuration (daemon.json) of the nodes of the cluster.",
+		Args:  cobra.ExactArgs(1),
+		Run:   runDaemonConfig,
+	}
+
+	daemonConfigRole    string
+	daemonConfigRestart bool
+)
+
+func init() {
+	rootCmd.AddCommand(daemonConfigCmd)
+
+	daemonConfigCmd.Flags().StringVarP(&daemonConfigRole, "role", "", "", "Only update the nodes with this role, manager or worker.")
+	daemonConfigCmd.Flags().BoolVarP(&daemonConfigRestart, "restart", "", false, "Restart the nodes, for the settings the daemon can't reload.")
+}
+
+func runDaemonConfig(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	config, err := internal.ReadData(args[0], "")
+	if err != nil {
+		fail(disgo.FailStepf("Unable to read the daemon configuration: %v", err))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	defer lockHost(client)()
+
+	disgo.StartStepf("Updating the daemon configuration of the nodes of cluster %q", clusterName)
+
+	update := sind.NodeConfigUpdate{
+		Role:         daemonConfigRole,
+		DaemonConfig: string(config),
+		Restart:      daemonConfigRestart,
+	}
+
+	if err = sind.UpdateNodeConfig(ctx, client, clusterName, update); err != nil {
+		fail(disgo.FailStepf("Unable to update the daemon configuration: %v", err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Daemon configuration of cluster %q successfully updated\n", style.Success(style.SymbolCheck), clusterName)
+}
@@ -2,7 +2,11 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"os"
+	"strings"
 	"syscall"
+	"time"
 
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/cli/internal"
@@ -18,10 +22,24 @@ var (
 		Short: "Delete a swarm cluster.",
 		Run:   runDelete,
 	}
+
+	forceDelete bool
+	stopTimeout time.Duration
+	keepNetwork bool
+	keepVolumes bool
+	deleteJobs  int
+	deleteHooks []string
 )
 
 func init() {
 	rootCmd.AddCommand(deleteCmd)
+
+	deleteCmd.Flags().BoolVarP(&forceDelete, "force", "f", false, "Skip the graceful teardown of the swarm, for broken clusters.")
+	deleteCmd.Flags().BoolVarP(&keepNetwork, "keep-network", "", false, "Keep the cluster network, reattached when creating the cluster again or reused with --existing-network.")
+	deleteCmd.Flags().BoolVarP(&keepVolumes, "keep-volumes", "", false, "Keep the volumes of the nodes, reattached when creating the cluster again.")
+	deleteCmd.Flags().IntVarP(&deleteJobs, "jobs", "j", sind.DefaultDeleteJobs, "How many node removals in parallel (a negative value means all at once).")
+	deleteCmd.Flags().StringArrayVarP(&deleteHooks, "pre-delete-hook", "", []string{}, "Shell script to run before tearing down the cluster, DOCKER_HOST targeting it.")
+	deleteCmd.Flags().DurationVarP(&stopTimeout, "stop-timeout", "", 0, "Time given to the nodes to stop before being killed.")
 }
 
 func runDelete(cmd *cobra.Command, args []string) {
@@ -45,16 +63,64 @@ func runDelete(cmd *cobra.Command, args []string) {
 		fail(disgo.FailStepf("Unable to check if the cluster exists: %v", err))
 	}
 
+	// A cluster which deletion failed may have no node left, but some networks.
 	if clusterInfo == nil {
-		fail(disgo.FailStepf("Cluster %q does not exist, or is already deleted", clusterName))
+		leftovers, err := sind.LeftoverResources(ctx, client, clusterName)
+		if err != nil {
+			fail(disgo.FailStepf("Unable to check if the cluster exists: %v", err))
+		}
+
+		if leftovers == 0 {
+			fail(disgo.FailStepf("Cluster %q does not exist, or is already deleted", clusterName))
+		}
+
+		disgo.Infof("Cluster %q is partially deleted, removing what is left\n", clusterName)
+	}
+
+	// Stacks are removed with the cluster, list them beforehand to report it.
+	if !forceDelete && clusterInfo != nil && clusterInfo.ManagersRunning > 0 && !clusterInfo.Plain && !clusterInfo.ExternalSwarm {
+		disgo.StartStepf("Listing the stacks deployed on cluster %q", clusterName)
+
+		stacks, err := sind.ListStacks(ctx, client, clusterName)
+		if err != nil {
+			fail(disgo.FailStepf("Unable to list the stacks, force the deletion if the cluster is broken: %v", err))
+		}
+
+		for _, stack := range stacks {
+			disgo.Infof("Removing stack %q (%s)\n", stack.Name, strings.Join(stack.Services, ", "))
+		}
 	}
 
 	disgo.StartStepf("Deleting cluster %q", clusterName)
 
-	if err = sind.DeleteCluster(ctx, client, clusterName); err != nil {
+	deleteOpts := sind.DeleteOptions{
+		Force:       forceDelete,
+		StopTimeout: stopTimeout,
+		KeepNetwork: keepNetwork,
+		KeepVolumes: keepVolumes,
+		Jobs:        deleteJobs,
+
+		PreDeleteHooks: internal.ScriptHooks(deleteHooks),
+	}
+
+	err = sind.DeleteClusterWithOptions(ctx, client, clusterName, deleteOpts)
+
+	// A failed teardown is only reported, the cluster being removed anyway.
+	var deleteErr *sind.DeleteError
+	if errors.As(err, &deleteErr) && !deleteErr.Partial() {
+		disgo.Errorf("%s Unable to gracefully teardown cluster %q: %v\n", style.Failure(style.SymbolCross), clusterName, deleteErr.Teardown)
+		err = nil
+	}
+
+	if err != nil {
 		fail(disgo.FailStepf("Unable to delete the cluster %q: %v", clusterName, err))
 	}
 
+	// The certificates written by sind env and sind docker are useless once the cluster is gone.
+	if dir, err := sind.DefaultSocketDir(); err == nil {
+		_ = os.RemoveAll(sind.ClusterCertPath(dir, clusterName))
+	}
+
 	disgo.EndStep()
 	disgo.Infof("%s Cluster %q successfully deleted !\n", style.Success(style.SymbolCheck), clusterName)
 }
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+)
+
+var (
+	dockerCmd = &cobra.Command{
+		Use:   "docker -- DOCKER_ARGS...",
+		Short: "Run the local docker CLI against the cluster.",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runDocker,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(dockerCmd)
+}
+
+func runDocker(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	host, err := sind.ClusterHost(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to get the host of cluster %q: %v", clusterName, err))
+	}
+
+	certPath, err := clusterCerts(ctx, client)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to write the certificates of cluster %q: %v", clusterName, err))
+	}
+
+	runDockerCLI(internal.ClusterEnv(os.Environ(), host, certPath), args...)
+}
+
+// runDockerCLI runs the local docker CLI with given environment and arguments, then exits with its exit code.
+func runDockerCLI(env []string, args ...string) {
+	dockerBin, err := exec.LookPath("docker")
+	if err != nil {
+		fail(disgo.FailStepf("Unable to find the docker CLI: %v", err))
+	}
+
+	// The docker CLI handles the interruptions itself, it runs until it exits whatever the command timeout is.
+	// Signals are caught rather than ignored, ignored signals would be inherited by the docker CLI.
+	signal.Notify(make(chan os.Signal, 1), syscall.SIGINT, syscall.SIGTERM)
+
+	dockerExec := exec.Command(dockerBin, args...)
+	dockerExec.Env = env
+	dockerExec.Stdin = os.Stdin
+	dockerExec.Stdout = os.Stdout
+	dockerExec.Stderr = os.Stderr
+
+	err = dockerExec.Run()
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.ExitCode())
+	}
+
+	if err != nil {
+		fail(disgo.FailStepf("Unable to run the docker CLI: %v", err))
+	}
+
+	os.Exit(0)
+}
@@ -2,6 +2,7 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"syscall"
@@ -15,13 +16,19 @@ import (
 var (
 	envCmd = &cobra.Command{
 		Use:   "env",
-		Short: "Sets up docker env variables.",
+		Short: "Print the docker env variables targeting the cluster, e.g. eval $(sind env).",
 		Run:   runEnv,
 	}
+
+	forWindows bool
+	envSocket  bool
 )
 
 func init() {
 	rootCmd.AddCommand(envCmd)
+
+	envCmd.Flags().BoolVarP(&envSocket, "unix-socket", "", false, "Use the unix socket published by sind publish --unix-socket.")
+	envCmd.Flags().BoolVarP(&forWindows, "windows", "", false, "Advertise the WSL2 VM address, to reach the cluster from Windows.")
 }
 
 func runEnv(cmd *cobra.Command, args []string) {
@@ -31,17 +38,63 @@ func runEnv(cmd *cobra.Command, args []string) {
 	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	if envSocket {
+		dir, err := sind.DefaultSocketDir()
+		if err != nil {
+			fmt.Printf("unable to get the socket directory: %v", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("export DOCKER_HOST=unix://%s\n", sind.ClusterSocketPath(dir, clusterName))
+		return
+	}
+
 	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
 	if err != nil {
 		fmt.Printf("unable to collect to the docker daemon: %v", err)
 		os.Exit(1)
 	}
 
-	host, err := sind.ClusterHost(ctx, client, clusterName)
+	clusterHost := sind.ClusterHost
+	if forWindows {
+		clusterHost = sind.WindowsClusterHost
+	}
+
+	host, err := clusterHost(ctx, client, clusterName)
 	if err != nil {
 		fmt.Printf("unable to collect cluster information: %v", err)
 		os.Exit(1)
 	}
 
-	fmt.Printf("export DOCKER_HOST=%s", host)
+	certPath, err := clusterCerts(ctx, client)
+	if err != nil {
+		fmt.Printf("unable to write the cluster certificates: %v", err)
+		os.Exit(1)
+	}
+
+	for _, variable := range internal.DockerEnv(host, certPath) {
+		fmt.Printf("export %s\n", variable)
+	}
+}
+
+// clusterCerts writes the certificates of the cluster to the sind directory and returns their path, empty if its
+// daemon port is not secured with TLS.
+func clusterCerts(ctx context.Context, client *docker.Client) (string, error) {
+	dir, err := sind.DefaultSocketDir()
+	if err != nil {
+		return "", err
+	}
+
+	certPath := sind.ClusterCertPath(dir, clusterName)
+
+	err = sind.WriteClusterCerts(ctx, client, clusterName, certPath)
+	if errors.Is(err, sind.ErrTLSDisabled) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return certPath, nil
 }
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+)
+
+var (
+	eventsCmd = &cobra.Command{
+		Use:   "events",
+		Short: "Stream the events of the cluster nodes and of their docker daemons.",
+		Run:   runEvents,
+	}
+
+	eventsNodes []string
+	eventsTypes []string
+)
+
+func init() {
+	rootCmd.AddCommand(eventsCmd)
+
+	eventsCmd.Flags().StringSliceVarP(&eventsNodes, "node", "", []string{}, "Only stream events of given nodes (e.g. manager-0).")
+	eventsCmd.Flags().StringSliceVarP(&eventsTypes, "type", "", []string{}, "Only stream events of given types (container, service, node...).")
+}
+
+func runEvents(cmd *cobra.Command, args []string) {
+	// Events are streamed until interrupted, the command timeout does not apply.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	out := make(chan sind.Event)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- sind.ClusterEvents(
+			ctx,
+			client,
+			clusterName,
+			sind.EventsFilter{Nodes: eventsNodes, Types: eventsTypes},
+			out,
+		)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fail(disgo.FailStepf("Unable to stream events of cluster %q: %v", clusterName, err))
+			}
+
+			return
+		case event := <-out:
+			source := "node"
+			if event.FromHost {
+				source = "host"
+			}
+
+			fmt.Printf(
+				"%s %s %s %s %s %s\n",
+				time.Unix(0, event.TimeNano).Format(time.RFC3339Nano),
+				event.Node,
+				source,
+				event.Type,
+				event.Action,
+				event.Actor.ID,
+			)
+		}
+	}
+}
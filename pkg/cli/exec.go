@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+)
+
+var (
+	execCmd = &cobra.Command{
+		Use:   "exec [--role ROLE] [--index INDEX] [--leader] [--all] -- CMD...",
+		Short: "Run a command in a node of the cluster, or in all of them.",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runExec,
+	}
+
+	nodeSelector sind.NodeSelector
+	execAll      bool
+)
+
+func init() {
+	rootCmd.AddCommand(execCmd)
+
+	addNodeSelectorFlags(execCmd)
+
+	execCmd.Flags().BoolVarP(&execAll, "all", "", false, "Run the command in all the nodes concurrently, printing the output of each node.")
+}
+
+// addNodeSelectorFlags adds the flags selecting a node of the cluster by role to a command.
+func addNodeSelectorFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&nodeSelector.Role, "role", "", "", "Role of the node, manager or worker (any node if empty).")
+	cmd.Flags().IntVarP(&nodeSelector.Index, "index", "", 0, "Index of the node among the nodes with the selected role.")
+	cmd.Flags().BoolVarP(&nodeSelector.Leader, "leader", "", false, "Select the manager leading the swarm.")
+}
+
+// selectedNode returns the node of the cluster selected by the node selector flags.
+func selectedNode() types.Container {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	node, err := sind.SelectNode(ctx, client, clusterName, nodeSelector)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to select a node of cluster %q: %v", clusterName, err))
+	}
+
+	return node
+}
+
+// execArgs returns the arguments of a docker exec of cmd in a node, allocating a TTY if stdin is a terminal.
+func execArgs(node types.Container, cmd ...string) []string {
+	args := []string{"exec", "-i"}
+
+	if info, err := os.Stdin.Stat(); err == nil && info.Mode()&os.ModeCharDevice != 0 {
+		args = append(args, "-t")
+	}
+
+	return append(append(args, node.ID), cmd...)
+}
+
+func runExec(cmd *cobra.Command, args []string) {
+	if execAll {
+		runExecAll(args)
+		return
+	}
+
+	runDockerCLI(os.Environ(), execArgs(selectedNode(), args...)...)
+}
+
+// runExecAll runs a command in all the nodes and prints their outputs, failing if it failed in any of them.
+func runExecAll(args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	results, err := sind.ExecAll(ctx, client, clusterName, args)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to run the command on cluster %q: %v", clusterName, err))
+	}
+
+	var failed int
+
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+			fmt.Printf("==> %s: %v\n", result.Node, result.Err)
+
+			continue
+		}
+
+		if result.ExitCode != 0 {
+			failed++
+		}
+
+		fmt.Printf("==> %s (exit code %d)\n", result.Node, result.ExitCode)
+		fmt.Fprint(os.Stdout, result.Stdout)
+		fmt.Fprint(os.Stderr, result.Stderr)
+	}
+
+	if failed > 0 {
+		fail(disgo.FailStepf("Command %v failed on %d of %d nodes", args, failed, len(results)))
+	}
+}
@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	extendHosts       []string
+	extendNodes       uint16
+	extendExistingNet string
+	extendImageName   string
+	extendPull        bool
+	extendAvail       string
+
+	extendCmd = &cobra.Command{
+		Use:   "extend",
+		Short: "Create worker nodes of the cluster on other docker hosts.",
+		Long: "Create worker nodes on other docker hosts, joining the swarm of the cluster. The network given on each host " +
+			"must route to the cluster network, the nodes of each host are deleted by running sind delete against it.",
+		Run: runExtend,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(extendCmd)
+
+	extendCmd.Flags().StringSliceVarP(&extendHosts, "docker-host", "", []string{}, "Docker hosts to create the nodes on (tcp://host:port).")
+	extendCmd.Flags().Uint16VarP(&extendNodes, "nodes", "", 1, "Amount of worker nodes to create on each docker host.")
+	extendCmd.Flags().StringVarP(&extendExistingNet, "existing-network", "", "", "ID or name of the network to attach the nodes to on each host, routed to the cluster network.")
+	extendCmd.Flags().StringVarP(&extendImageName, "image", "i", "", "Name of the image to use for the nodes, the image of the cluster if empty.")
+	extendCmd.Flags().BoolVarP(&extendPull, "pull", "", false, "Pull node image before creating the nodes.")
+	extendCmd.Flags().StringVarP(&extendAvail, "availability", "", "", "Availability the nodes join the swarm with (active, drain, pause).")
+}
+
+func runExtend(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemons")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	defer lockHost(client)()
+
+	extendConfig := sind.ExtendConfiguration{
+		ClusterName: clusterBaseName,
+		Namespace:   namespace,
+		Hosts:       make([]sind.HostNodes, len(extendHosts)),
+	}
+
+	for i, host := range extendHosts {
+		hostClient, err := docker.NewClientWithOpts(docker.WithHost(host), docker.WithAPIVersionNegotiation())
+		if err != nil {
+			fail(disgo.FailStepf("Unable to connect to the docker daemon %q: %v", host, err))
+		}
+
+		defer hostClient.Close()
+
+		extendConfig.Hosts[i] = sind.HostNodes{
+			Client:          hostClient,
+			Nodes:           extendNodes,
+			ExistingNetwork: extendExistingNet,
+			ImageName:       extendImageName,
+			PullImage:       extendPull,
+			Availability:    extendAvail,
+		}
+	}
+
+	disgo.StartStepf("Creating %d nodes on each of the docker hosts %v", extendNodes, extendHosts)
+
+	if err = sind.ExtendCluster(ctx, client, extendConfig); err != nil {
+		fail(disgo.FailStepf("Unable to extend the cluster: %v", err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Cluster %q successfully extended\n", style.Success(style.SymbolCheck), clusterName)
+}
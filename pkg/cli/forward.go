@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	portForwardCmd = &cobra.Command{
+		Use:   "port-forward SERVICE HOST_PORT:SERVICE_PORT",
+		Short: "Forward a port of the docker host to a service of the cluster, until interrupted.",
+		Args:  cobra.ExactArgs(2),
+		Run:   runPortForward,
+	}
+
+	portForwardSlot int
+)
+
+func init() {
+	rootCmd.AddCommand(portForwardCmd)
+
+	portForwardCmd.Flags().IntVarP(&portForwardSlot, "slot", "", 0, "Forward to the task running in this slot instead of the service VIP.")
+}
+
+func runPortForward(cmd *cobra.Command, args []string) {
+	hostPort, servicePort, err := parsePortMapping(args[1])
+	if err != nil {
+		fail(disgo.FailStepf("Invalid port mapping %q: %v", args[1], err))
+	}
+
+	// The port is forwarded until interrupted, the command timeout only applies to the setup.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	setupCtx, cancelSetup := context.WithTimeout(ctx, timeout)
+	defer cancelSetup()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Forwarding host port %d to port %d of service %q", hostPort, servicePort, args[0])
+
+	forwardCfg := sind.PortForwardConfig{
+		Service:    args[0],
+		Slot:       portForwardSlot,
+		HostPort:   hostPort,
+		TargetPort: servicePort,
+	}
+
+	// The host port is allocated during the setup only, the lock is not held while forwarding.
+	unlock := lockHost(client)
+
+	stop, err := sind.PortForward(setupCtx, client, clusterName, forwardCfg)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to forward the port: %v", err))
+	}
+
+	unlock()
+
+	disgo.EndStep()
+	disgo.Infof("%s Forwarding host port %d to port %d of service %q, interrupt to stop\n", style.Success(style.SymbolCheck), hostPort, servicePort, args[0])
+
+	<-ctx.Done()
+
+	disgo.StartStep("Removing the port forwarding")
+
+	stopCtx, cancelStop := context.WithTimeout(context.Background(), timeout)
+	defer cancelStop()
+
+	if err = stop(stopCtx); err != nil {
+		fail(disgo.FailStepf("Unable to remove the port forwarding: %v", err))
+	}
+
+	disgo.EndStep()
+}
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"syscall"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	gcCmd = &cobra.Command{
+		Use:   "gc",
+		Short: "Delete all clusters matching the given criteria.",
+		Run:   runGC,
+	}
+
+	gcOlderThan time.Duration
+	gcStopped   bool
+	gcExpired   bool
+	gcLabels    []string
+	gcDryRun    bool
+)
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().DurationVarP(&gcOlderThan, "older-than", "", 0, "Delete clusters created more than this duration ago.")
+	gcCmd.Flags().BoolVarP(&gcStopped, "stopped", "", false, "Delete clusters with no running nodes.")
+	gcCmd.Flags().BoolVarP(&gcExpired, "expired", "", false, "Delete clusters which TTL is elapsed.")
+	gcCmd.Flags().StringSliceVarP(&gcLabels, "label", "l", []string{}, "Delete clusters having this label (key=value).")
+	gcCmd.Flags().BoolVarP(&gcDryRun, "dry-run", "", false, "Only print the clusters which would be deleted.")
+}
+
+func runGC(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	labels, err := parseKeyValues(gcLabels)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid labels: %v", err))
+	}
+
+	if gcOlderThan == 0 && !gcStopped && !gcExpired && len(labels) == 0 {
+		fail(disgo.FailStepf("At least one of --older-than, --stopped, --expired or --label is required"))
+	}
+
+	criteria := sind.GCCriteria{
+		OlderThan: gcOlderThan,
+		Stopped:   gcStopped,
+		Labels:    labels,
+		Expired:   gcExpired,
+		Namespace: namespace,
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	if gcDryRun {
+		disgo.StartStep("Listing clusters")
+
+		clusters, err := sind.ListClusters(ctx, client)
+		if err != nil {
+			fail(disgo.FailStepf("Unable to list clusters: %v", err))
+		}
+
+		disgo.EndStep()
+
+		for _, cluster := range sind.SelectClusters(clusters, criteria, time.Now()) {
+			disgo.Infof("Would delete cluster %q\n", cluster.Name)
+		}
+
+		return
+	}
+
+	disgo.StartStep("Garbage collecting clusters")
+
+	deleted, err := sind.GarbageCollect(ctx, client, criteria)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to garbage collect clusters (deleted %q): %v", deleted, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Deleted %d cluster(s) %q\n", style.Success(style.SymbolCheck), len(deleted), deleted)
+}
+
+func parseKeyValues(rawValues []string) (map[string]string, error) {
+	values := make(map[string]string, len(rawValues))
+
+	for _, rawValue := range rawValues {
+		parts := strings.SplitN(rawValue, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("%q is not formatted as key=value", rawValue)
+		}
+
+		values[parts[0]] = parts[1]
+	}
+
+	return values, nil
+}
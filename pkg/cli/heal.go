@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	healCmd = &cobra.Command{
+		Use:   "heal",
+		Short: "Recreate the nodes of the cluster which died unexpectedly, and make them rejoin the swarm.",
+		Run:   runHeal,
+	}
+
+	healWatch bool
+)
+
+func init() {
+	rootCmd.AddCommand(healCmd)
+
+	healCmd.Flags().BoolVarP(&healWatch, "watch", "", false, "Keep watching the cluster, healing nodes as soon as they die.")
+}
+
+func runHeal(cmd *cobra.Command, args []string) {
+	if healWatch {
+		runAutoHeal()
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	defer lockHost(client)()
+
+	disgo.StartStepf("Healing the nodes of cluster %q", clusterName)
+
+	healed, err := sind.HealCluster(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to heal cluster %q: %v", clusterName, err))
+	}
+
+	disgo.EndStep()
+
+	if len(healed) == 0 {
+		disgo.Infof("%s No node of cluster %q died unexpectedly, nothing to do\n", style.Success(style.SymbolCheck), clusterName)
+		return
+	}
+
+	disgo.Infof("%s Successfully healed nodes %q of cluster %q\n", style.Success(style.SymbolCheck), healed, clusterName)
+}
+
+func runAutoHeal() {
+	// The cluster is healed until interrupted, the command timeout does not apply. The host is not locked, the other
+	// commands would wait for the watch to end.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	out := make(chan sind.HealReport)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- sind.AutoHealCluster(ctx, client, clusterName, out)
+	}()
+
+	disgo.Infof("Watching cluster %q, nodes dying unexpectedly are healed\n", clusterName)
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fail(disgo.FailStepf("Unable to watch cluster %q: %v", clusterName, err))
+			}
+
+			return
+		case report := <-out:
+			if report.Err != nil {
+				disgo.Errorf("%s Unable to heal node %q: %v\n", style.Failure(style.SymbolCross), report.Node, report.Err)
+				continue
+			}
+
+			disgo.Infof("%s Node %q healed\n", style.Success(style.SymbolCheck), report.Node)
+		}
+	}
+}
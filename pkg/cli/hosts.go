@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+)
+
+var (
+	hostsCmd = &cobra.Command{
+		Use:   "hosts",
+		Short: "Prints an /etc/hosts snippet addressing the cluster nodes by name.",
+		Run:   runHosts,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(hostsCmd)
+}
+
+func runHosts(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fmt.Printf("unable to collect to the docker daemon: %v", err)
+		os.Exit(1)
+	}
+
+	entries, err := sind.ClusterHostsEntries(ctx, client, clusterName)
+	if err != nil {
+		fmt.Printf("unable to collect cluster information: %v", err)
+		os.Exit(1)
+	}
+
+	fmt.Print(sind.HostsSnippet(clusterName, entries))
+}
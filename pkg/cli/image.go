@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	imageCmd = &cobra.Command{
+		Use:   "image",
+		Short: "Manage the node images.",
+	}
+
+	imageBakeCmd = &cobra.Command{
+		Use:   "bake IMAGE [IMAGE...]",
+		Short: "Build a node image with the given images already loaded in its daemon.",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runImageBake,
+	}
+
+	imageWaitCmd = &cobra.Command{
+		Use:   "wait IMAGE",
+		Short: "Wait until an image is present on all the nodes tasks may be scheduled on, e.g. before deploying a stack.",
+		Args:  cobra.ExactArgs(1),
+		Run:   runImageWait,
+	}
+
+	bakeTag       string
+	bakeBaseImage string
+	bakePullBase  bool
+)
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+	imageCmd.AddCommand(imageBakeCmd)
+	imageCmd.AddCommand(imageWaitCmd)
+
+	imageBakeCmd.Flags().StringVar(&bakeTag, "tag", "", "Reference of the baked image.")
+	imageBakeCmd.Flags().StringVar(&bakeBaseImage, "base", sind.DefaultNodeImageName, "Node image the baked image derives from.")
+	imageBakeCmd.Flags().BoolVar(&bakePullBase, "pull", false, "Pull the base image even if it is already present.")
+}
+
+func runImageBake(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Baking images %q into %q", args, bakeTag)
+
+	imageID, err := sind.BakeImage(
+		ctx,
+		client,
+		sind.BakeConfiguration{
+			BaseImage: bakeBaseImage,
+			PullBase:  bakePullBase,
+			ImageRef:  bakeTag,
+			Images:    args,
+		},
+	)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to bake image %q: %v", bakeTag, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Successfully baked image %q (%s)\n", style.Success(style.SymbolCheck), bakeTag, imageID)
+}
+
+func runImageWait(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Waiting for image %q on the nodes of cluster %q", args[0], clusterName)
+
+	if err = sind.WaitForImage(ctx, client, clusterName, args[0]); err != nil {
+		fail(disgo.FailStepf("Unable to wait for the image: %v", err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Image %q is present on all the nodes\n", style.Success(style.SymbolCheck), args[0])
+}
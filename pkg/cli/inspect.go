@@ -2,6 +2,8 @@ package cli
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"syscall"
 
@@ -13,6 +15,9 @@ import (
 )
 
 var (
+	inspectOutput string
+	inspectGraph  string
+
 	inspectCmd = &cobra.Command{
 		Use:   "inspect",
 		Short: "Inspect a specific cluster.",
@@ -22,6 +27,10 @@ var (
 
 func init() {
 	rootCmd.AddCommand(inspectCmd)
+
+	inspectCmd.Flags().StringVarP(&inspectOutput, "output", "o", "text", "Output format (text, or env and json for the cluster connection details).")
+	inspectCmd.Flags().StringVarP(&inspectGraph, "graph", "", "", "Print the nodes, roles, networks and ports of the cluster as a graph, dot or mermaid (e.g. --graph=mermaid).")
+	inspectCmd.Flags().Lookup("graph").NoOptDefVal = string(sind.GraphDOT)
 }
 
 func runInspect(cmd *cobra.Command, args []string) {
@@ -31,6 +40,20 @@ func runInspect(cmd *cobra.Command, args []string) {
 	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	if inspectOutput != "text" && inspectOutput != "env" && inspectOutput != "json" {
+		fail(disgo.FailStepf("Invalid output format %q, expected text, env or json", inspectOutput))
+	}
+
+	if inspectGraph != "" {
+		runInspectGraph(ctx)
+		return
+	}
+
+	if inspectOutput != "text" {
+		runInspectConnection(ctx)
+		return
+	}
+
 	disgo.StartStep("Connecting to the docker daemon")
 
 	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
@@ -38,18 +61,59 @@ func runInspect(cmd *cobra.Command, args []string) {
 		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
 	}
 
-	disgo.StartStepf("Checking if a cluster named %q already exists", clusterName)
+	disgo.StartStepf("Inspecting cluster %q", clusterName)
 
-	clusterInfo, err := sind.InspectCluster(ctx, client, clusterName)
+	clusterInfo, err := sind.FindCluster(ctx, client, clusterName)
 	if err != nil {
-		fail(disgo.FailStepf("Unable to check if the cluster already exists: %v", err))
-	}
-
-	if clusterInfo == nil {
-		fail(disgo.FailStepf("Cluster %q does not exists", clusterName))
+		fail(disgo.FailStepf("Unable to inspect the cluster: %v", err))
 	}
 
 	disgo.EndStep()
 
 	internal.RenderCluster(os.Stdout, *clusterInfo)
 }
+
+// runInspectGraph prints the graph of the cluster, without progress steps polluting the output.
+func runInspectGraph(ctx context.Context) {
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	clusterInfo, err := sind.FindCluster(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to inspect the cluster: %v", err))
+	}
+
+	if err = sind.ClusterGraph(*clusterInfo).Write(os.Stdout, sind.GraphFormat(inspectGraph)); err != nil {
+		fail(disgo.FailStepf("Unable to write the cluster graph: %v", err))
+	}
+}
+
+// runInspectConnection prints the connection details of the cluster, without progress steps polluting the output.
+func runInspectConnection(ctx context.Context) {
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	connection, err := sind.ConnectionDetails(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to get the cluster connection details: %v", err))
+	}
+
+	if inspectOutput == "env" {
+		for _, variable := range connection.Env() {
+			fmt.Println(variable)
+		}
+
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(connection); err != nil {
+		fail(disgo.FailStepf("Unable to encode the cluster connection details: %v", err))
+	}
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/jlevesy/sind/pkg/sind"
+)
+
+// ParseBootstrap parses the swarm objects to bootstrap at creation.
+// Networks are formatted as NAME[,attachable][,internal][,subnet=CIDR], secrets and configs as NAME=FILE and node
+// labels as NODE:KEY=VALUE.
+func ParseBootstrap(networks, secrets, configs, nodeLabels []string) (sind.Bootstrap, error) {
+	var (
+		bootstrap sind.Bootstrap
+		err       error
+	)
+
+	for _, raw := range networks {
+		network, err := parseBootstrapNetwork(raw)
+		if err != nil {
+			return bootstrap, err
+		}
+
+		bootstrap.Networks = append(bootstrap.Networks, network)
+	}
+
+	if bootstrap.Secrets, err = readNamedFiles(secrets); err != nil {
+		return bootstrap, fmt.Errorf("invalid secret: %w", err)
+	}
+
+	if bootstrap.Configs, err = readNamedFiles(configs); err != nil {
+		return bootstrap, fmt.Errorf("invalid config: %w", err)
+	}
+
+	for _, raw := range nodeLabels {
+		parts := strings.SplitN(raw, ":", 2)
+		if len(parts) != 2 {
+			return bootstrap, fmt.Errorf("node label %q is not formatted as NODE:KEY=VALUE", raw)
+		}
+
+		label := strings.SplitN(parts[1], "=", 2)
+		if len(label) != 2 {
+			return bootstrap, fmt.Errorf("node label %q is not formatted as NODE:KEY=VALUE", raw)
+		}
+
+		if bootstrap.NodeLabels == nil {
+			bootstrap.NodeLabels = make(map[string]map[string]string)
+		}
+
+		if bootstrap.NodeLabels[parts[0]] == nil {
+			bootstrap.NodeLabels[parts[0]] = make(map[string]string)
+		}
+
+		bootstrap.NodeLabels[parts[0]][label[0]] = label[1]
+	}
+
+	return bootstrap, nil
+}
+
+func parseBootstrapNetwork(raw string) (sind.BootstrapNetwork, error) {
+	fields := strings.Split(raw, ",")
+	network := sind.BootstrapNetwork{Name: fields[0]}
+
+	for _, field := range fields[1:] {
+		switch {
+		case field == "attachable":
+			network.Attachable = true
+		case field == "internal":
+			network.Internal = true
+		case strings.HasPrefix(field, "subnet="):
+			network.Subnet = strings.TrimPrefix(field, "subnet=")
+		default:
+			return network, fmt.Errorf("unknown option %q of network %q", field, network.Name)
+		}
+	}
+
+	return network, nil
+}
+
+// readNamedFiles reads the content of files formatted as NAME=FILE.
+func readNamedFiles(rawFiles []string) (map[string][]byte, error) {
+	if len(rawFiles) == 0 {
+		return nil, nil
+	}
+
+	files := make(map[string][]byte, len(rawFiles))
+
+	for _, raw := range rawFiles {
+		parts := strings.SplitN(raw, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q is not formatted as NAME=FILE", raw)
+		}
+
+		data, err := ioutil.ReadFile(parts[1])
+		if err != nil {
+			return nil, err
+		}
+
+		files[parts[0]] = data
+	}
+
+	return files, nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/jlevesy/sind/pkg/sind"
@@ -25,6 +26,18 @@ func RenderCluster(out io.Writer, cluster sind.ClusterStatus) {
 		style.Important(fmt.Sprintf("%d/%d", cluster.WorkersRunning, cluster.Workers)),
 	)
 
+	fmt.Fprintf(
+		wr,
+		"Created: %s\tBy: %s\tVersion: %s\t\n",
+		cluster.CreatedAt.Local().Format(time.RFC3339),
+		orUnknown(cluster.CreatedBy),
+		orUnknown(cluster.SindVersion),
+	)
+
+	if len(cluster.PortBindings) > 0 {
+		fmt.Fprintf(wr, "Ports: %s\t\n", strings.Join(cluster.PortBindings, ", "))
+	}
+
 	fmt.Fprintf(wr, "ID\tImage\tRole\tStatus\tIPs\t\n")
 	fmt.Fprintf(wr, "--\t-----\t----\t------\t---\t\n")
 
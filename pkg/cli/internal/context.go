@@ -4,21 +4,31 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync/atomic"
 )
 
+type interruptedKey struct{}
+
 // WithSignal returns a context canceled if the process receives one of the following signal.
+// Once one of them is received, the signals are not trapped anymore: receiving one again terminates the process, e.g.
+// to skip the cleanup of an interrupted command.
 func WithSignal(parent context.Context, signals ...os.Signal) (context.Context, func()) {
-	ctx, cancel := context.WithCancel(parent)
+	var interrupted int32
+
+	ctx, cancel := context.WithCancel(context.WithValue(parent, interruptedKey{}, &interrupted))
 
 	signalReceived := make(chan os.Signal, 1)
 	signal.Notify(signalReceived, signals...)
 
 	go func() {
+		defer signal.Stop(signalReceived)
+
 		for {
 			select {
 			case <-ctx.Done():
 				return
 			case <-signalReceived:
+				atomic.StoreInt32(&interrupted, 1)
 				cancel()
 				return
 			}
@@ -27,3 +37,10 @@ func WithSignal(parent context.Context, signals ...os.Signal) (context.Context,
 
 	return ctx, cancel
 }
+
+// Interrupted returns true if the context returned by WithSignal has been canceled because of a signal.
+func Interrupted(ctx context.Context) bool {
+	interrupted, ok := ctx.Value(interruptedKey{}).(*int32)
+
+	return ok && atomic.LoadInt32(interrupted) == 1
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jlevesy/sind/pkg/sind"
+)
+
+// ErrInvalidDataSource is returned when the data of a secret or a config is read from none or both of a file and a literal.
+var ErrInvalidDataSource = errors.New("the data must be read either from a file, - for stdin, or from a literal")
+
+// ReadData reads the data of a secret or a config from the file at path, stdin if path is -, or returns literal.
+func ReadData(path, literal string) ([]byte, error) {
+	if (path == "") == (literal == "") {
+		return nil, ErrInvalidDataSource
+	}
+
+	switch path {
+	case "":
+		return []byte(literal), nil
+	case "-":
+		return ioutil.ReadAll(os.Stdin)
+	default:
+		return ioutil.ReadFile(path)
+	}
+}
+
+// RenderSwarmDataList renders a list of secrets or configs in the given output.
+func RenderSwarmDataList(out io.Writer, data []sind.SwarmData) {
+	wr := tabwriter.NewWriter(out, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "ID\tName\tStack\tCreated\t\n")
+	fmt.Fprintf(wr, "--\t----\t-----\t-------\t\n")
+
+	for _, d := range data {
+		stack := d.Stack
+		if stack == "" {
+			stack = "-"
+		}
+
+		fmt.Fprintf(wr, "%s\t%s\t%s\t%s\t\n", d.ID, d.Name, stack, d.CreatedAt.Local().Format(time.RFC3339))
+	}
+}
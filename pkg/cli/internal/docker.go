@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"strings"
+
 	docker "github.com/docker/docker/client"
 )
 
@@ -9,3 +11,31 @@ var DefaultDockerOpts = []docker.Opt{
 	docker.FromEnv,
 	docker.WithAPIVersionNegotiation(),
 }
+
+// ClusterEnv returns the environment env with the docker CLI pointed at the cluster daemon listening on host. The TLS
+// and context settings of the host daemon are removed, the cluster daemon is reached with the certificates in certPath,
+// or without TLS if empty.
+func ClusterEnv(env []string, host, certPath string) []string {
+	result := make([]string, 0, len(env)+3)
+
+	for _, variable := range env {
+		switch strings.SplitN(variable, "=", 2)[0] {
+		case "DOCKER_HOST", "DOCKER_TLS_VERIFY", "DOCKER_CERT_PATH", "DOCKER_CONTEXT":
+			continue
+		}
+
+		result = append(result, variable)
+	}
+
+	return append(result, DockerEnv(host, certPath)...)
+}
+
+// DockerEnv returns the variables pointing the docker CLI at the daemon listening on host, verified with the
+// certificates in certPath if not empty.
+func DockerEnv(host, certPath string) []string {
+	if certPath == "" {
+		return []string{"DOCKER_HOST=" + host}
+	}
+
+	return []string{"DOCKER_HOST=" + host, "DOCKER_TLS_VERIFY=1", "DOCKER_CERT_PATH=" + certPath}
+}
@@ -0,0 +1,45 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrNotInGitHubActions is returned when exporting to GitHub Actions outside of a workflow run.
+var ErrNotInGitHubActions = errors.New("not running in a GitHub Actions workflow, $GITHUB_OUTPUT and $GITHUB_ENV are not set")
+
+// ExportGitHubActions writes the cluster name and docker host to the outputs of the current workflow step
+// ($GITHUB_OUTPUT), and to the environment of the next ones ($GITHUB_ENV).
+func ExportGitHubActions(clusterName, dockerHost string) error {
+	outputPath, envPath := os.Getenv("GITHUB_OUTPUT"), os.Getenv("GITHUB_ENV")
+	if outputPath == "" || envPath == "" {
+		return ErrNotInGitHubActions
+	}
+
+	if err := appendVariables(outputPath, "cluster-name="+clusterName, "docker-host="+dockerHost); err != nil {
+		return fmt.Errorf("unable to write the step outputs: %w", err)
+	}
+
+	if err := appendVariables(envPath, "SIND_CLUSTER_NAME="+clusterName, "DOCKER_HOST="+dockerHost); err != nil {
+		return fmt.Errorf("unable to write the workflow environment: %w", err)
+	}
+
+	return nil
+}
+
+func appendVariables(path string, variables ...string) error {
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+
+	for _, variable := range variables {
+		if _, err = fmt.Fprintln(file, variable); err != nil {
+			file.Close()
+			return err
+		}
+	}
+
+	return file.Close()
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"context"
+	"os"
+	"os/exec"
+
+	"github.com/jlevesy/sind/pkg/sind"
+)
+
+// ScriptHooks returns hooks running each of the given shell scripts with sh -c, the hook event being passed in their
+// environment, see sind.HookEvent.Env. Their output is forwarded to the output of the command.
+func ScriptHooks(scripts []string) []sind.Hook {
+	hooks := make([]sind.Hook, 0, len(scripts))
+
+	for _, script := range scripts {
+		script := script
+
+		hooks = append(hooks, func(ctx context.Context, event sind.HookEvent) error {
+			cmd := exec.CommandContext(ctx, "sh", "-c", script)
+			cmd.Env = append(os.Environ(), event.Env()...)
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+
+			return cmd.Run()
+		})
+	}
+
+	return hooks
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"text/tabwriter"
+	"time"
 
 	"github.com/jlevesy/sind/pkg/sind"
 )
@@ -13,28 +14,52 @@ func RenderClusterList(out io.Writer, clusters []sind.ClusterStatus) {
 	wr := tabwriter.NewWriter(out, 4, 8, 2, '\t', 0)
 	defer wr.Flush()
 
-	fmt.Fprintf(wr, "\nName\tStatus\tManagers\tWorkers\t\n")
-	fmt.Fprintf(wr, "----\t------\t--------\t-------\t\n")
+	fmt.Fprintf(wr, "\nName\tStatus\tManagers\tWorkers\tExpires\tCreated by\tVersion\t\n")
+	fmt.Fprintf(wr, "----\t------\t--------\t-------\t-------\t----------\t-------\t\n")
 
 	for _, cluster := range clusters {
 		fmt.Fprintf(
 			wr,
-			"%s\t%s\t%d/%d\t%d/%d\t\n",
+			"%s\t%s\t%d/%d\t%d/%d\t%s\t%s\t%s\t\n",
 			cluster.Name,
 			status(cluster),
 			cluster.ManagersRunning,
 			cluster.Managers,
 			cluster.WorkersRunning,
 			cluster.Workers,
+			expiresAt(cluster),
+			orUnknown(cluster.CreatedBy),
+			orUnknown(cluster.SindVersion),
 		)
 	}
 }
 
+// orUnknown returns value, or unknown if it is empty.
+func orUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+
+	return value
+}
+
+func expiresAt(cluster sind.ClusterStatus) string {
+	if cluster.ExpiresAt.IsZero() {
+		return "never"
+	}
+
+	return cluster.ExpiresAt.Local().Format(time.RFC3339)
+}
+
 func status(cluster sind.ClusterStatus) string {
 	if cluster.ManagersRunning == 0 && cluster.WorkersRunning == 0 {
 		return "Stopped"
 	}
 
+	if cluster.NodesUnhealthy > 0 {
+		return "Unhealthy"
+	}
+
 	if cluster.ManagersRunning == cluster.Managers && cluster.WorkersRunning == cluster.Workers {
 		return "Running"
 	}
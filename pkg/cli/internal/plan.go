@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jlevesy/sind/pkg/sind"
+)
+
+// RenderClusterPlan renders what a cluster creation would do.
+func RenderClusterPlan(out io.Writer, plan *sind.ClusterPlan) {
+	wr := tabwriter.NewWriter(out, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "\nCluster:\t%s\t\n", plan.ClusterName)
+	fmt.Fprintf(wr, "Docker host:\t%s\t\n", plan.DockerHost)
+
+	for _, image := range plan.Images {
+		action := "present"
+		if image.Pull {
+			action = "pull"
+		}
+
+		fmt.Fprintf(wr, "Image:\t%s (%s)\t\n", image.Ref, action)
+	}
+
+	fmt.Fprintf(wr, "Network:\t%s\t\n", networkSummary(plan.Network))
+
+	fmt.Fprintf(wr, "\nName\tRole\tImage\tAddress\tPorts\tCommand\t\n")
+	fmt.Fprintf(wr, "----\t----\t-----\t-------\t-----\t-------\t\n")
+
+	for _, container := range plan.Containers {
+		address := container.Address
+		if address == "" {
+			address = "IPAM"
+		}
+
+		ports := container.Ports
+		if container.PublishAllPorts {
+			ports = append(append([]string{}, ports...), "all exposed")
+		}
+
+		fmt.Fprintf(
+			wr,
+			"%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			container.Name,
+			container.Role,
+			container.Image,
+			address,
+			strings.Join(ports, ", "),
+			strings.Join(container.Command, " "),
+		)
+	}
+}
+
+func networkSummary(network sind.NetworkPlan) string {
+	if network.Existing {
+		return fmt.Sprintf("%s (existing, %s %s)", network.Name, network.Driver, network.Subnet)
+	}
+
+	subnet := network.Subnet
+	if network.RandomSubnet {
+		subnet = "random subnet, e.g. " + subnet
+	}
+
+	if network.Internal {
+		return fmt.Sprintf("%s (create, internal %s %s)", network.Name, network.Driver, subnet)
+	}
+
+	return fmt.Sprintf("%s (create, %s %s)", network.Name, network.Driver, subnet)
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jlevesy/sind/pkg/sind"
+)
+
+// RenderCreateTimings renders the durations of the phases of a cluster creation.
+func RenderCreateTimings(out io.Writer, timings *sind.CreateTimings) {
+	wr := tabwriter.NewWriter(out, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "\nPhase\tDuration\t\n")
+	fmt.Fprintf(wr, "-----\t--------\t\n")
+
+	phases := []struct {
+		name     string
+		duration time.Duration
+	}{
+		{"Image pull", timings.Pull},
+		{"Network", timings.Network},
+		{"Containers start", timings.Containers},
+		{"Primary readiness", timings.Readiness},
+		{"Swarm init", timings.SwarmInit},
+		{"Joins", timings.Joins},
+		{"Bootstrap", timings.Bootstrap},
+		{"Load balancer", timings.LoadBalancer},
+		{"Total", timings.Total},
+	}
+
+	for _, phase := range phases {
+		fmt.Fprintf(wr, "%s\t%s\t\n", phase.name, phase.duration.Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+// cleanupTimeout bounds the cleanup of what an interrupted command left behind, the context of the command being
+// canceled.
+const cleanupTimeout = 2 * time.Minute
+
+// cleanupContext returns the context to clean up what an interrupted command left behind with.
+func cleanupContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), cleanupTimeout)
+}
+
+// reportClusterLeftovers reports what an interrupted creation left of given clusters on the docker host, the creation
+// removing what it created unless asked to keep it.
+func reportClusterLeftovers(client *docker.Client, names ...string) {
+	ctx, cancel := cleanupContext()
+	defer cancel()
+
+	for _, name := range names {
+		leftovers, err := sind.ListLeftovers(ctx, client, name)
+		if err != nil {
+			disgo.Errorf("%s Unable to list what is left of cluster %q: %v\n", style.Failure(style.SymbolCross), name, err)
+			continue
+		}
+
+		reportLeftovers(name, leftovers)
+	}
+}
+
+// reportLeftovers prints the resources of a cluster left on the docker host.
+func reportLeftovers(name string, leftovers *sind.Leftovers) {
+	if len(leftovers.Containers) > 0 {
+		disgo.Infof("Containers left: %s\n", strings.Join(leftovers.Containers, ", "))
+	}
+
+	if len(leftovers.Networks) > 0 {
+		disgo.Infof("Networks left: %s\n", strings.Join(leftovers.Networks, ", "))
+	}
+
+	if len(leftovers.Volumes) > 0 {
+		disgo.Infof("Volumes left: %s\n", strings.Join(leftovers.Volumes, ", "))
+	}
+
+	if leftovers.Count() > 0 {
+		disgo.Infof("Run sind delete --force --cluster %s to remove them\n", name)
+	}
+}
@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	jobCmd = &cobra.Command{
+		Use:   "job [--global | --completions N [--max-concurrent N]] IMAGE [-- CMD...]",
+		Short: "Run a swarm job on the cluster, wait for its completion and print the output of its tasks.",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runJob,
+	}
+
+	jobSpec sind.JobSpec
+)
+
+func init() {
+	rootCmd.AddCommand(jobCmd)
+
+	jobCmd.Flags().StringVarP(&jobSpec.Name, "name", "", "", "Name of the job service.")
+	jobCmd.Flags().BoolVarP(&jobSpec.Global, "global", "", false, "Run a task on each node of the cluster.")
+	jobCmd.Flags().Uint64VarP(&jobSpec.Completions, "completions", "", 1, "Amount of tasks to complete.")
+	jobCmd.Flags().Uint64VarP(&jobSpec.MaxConcurrent, "max-concurrent", "", 0, "Maximum amount of tasks running at once (0 means all of them).")
+	jobCmd.Flags().StringArrayVarP(&jobSpec.Env, "env", "e", []string{}, "Environment variables of the tasks (KEY=value).")
+	jobCmd.Flags().StringArrayVarP(&jobSpec.Networks, "network", "", []string{}, "Networks to attach the tasks to.")
+}
+
+func runJob(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	jobSpec.Image = args[0]
+	jobSpec.Command = args[1:]
+
+	// Completions only apply to replicated jobs, the default is dropped for global ones.
+	if jobSpec.Global && !cmd.Flags().Changed("completions") {
+		jobSpec.Completions = 0
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Running job %s on cluster %q", jobSpec.Image, clusterName)
+
+	result, err := sind.RunJob(ctx, client, clusterName, jobSpec)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to run the job: %v", err))
+	}
+
+	disgo.EndStep()
+
+	_, _ = os.Stdout.Write(result.Stdout)
+	_, _ = os.Stderr.Write(result.Stderr)
+
+	if !result.Succeeded() {
+		fail(disgo.FailStepf("Job failed: %d task(s) completed, %d failed", result.Completed, result.Failed))
+	}
+
+	disgo.Infof("%s Job completed, %d task(s)\n", style.Success(style.SymbolCheck), result.Completed)
+}
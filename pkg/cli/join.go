@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	joinToken       string
+	joinAddrs       []string
+	joinNodes       uint16
+	joinNetworkName string
+	joinExistingNet string
+	joinImageName   string
+	joinPull        bool
+	joinAvail       string
+
+	joinCmd = &cobra.Command{
+		Use:   "join",
+		Short: "Create nodes joining an existing swarm, not managed by sind.",
+		Run:   runJoin,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(joinCmd)
+
+	joinCmd.Flags().StringVarP(&joinToken, "token", "", "", "Manager or worker join token of the swarm.")
+	joinCmd.Flags().StringSliceVarP(&joinAddrs, "addr", "", []string{}, "Addresses of the swarm managers to join through (port 2377 if not specified).")
+	joinCmd.Flags().Uint16VarP(&joinNodes, "nodes", "", 1, "Amount of nodes joining the swarm.")
+	joinCmd.Flags().StringVarP(&joinNetworkName, "network-name", "n", "sind-default", "Name of the network to create.")
+	joinCmd.Flags().StringVarP(&joinExistingNet, "existing-network", "", "", "ID or name of an existing network to attach the nodes to, instead of creating one.")
+	joinCmd.Flags().StringVarP(&joinImageName, "image", "i", sind.DefaultNodeImageName, "Name of the image to use for the nodes.")
+	joinCmd.Flags().BoolVarP(&joinPull, "pull", "", false, "Pull node image before creating the nodes.")
+	joinCmd.Flags().StringVarP(&joinAvail, "availability", "", "", "Availability the nodes join the swarm with (active, drain, pause).")
+}
+
+func runJoin(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if joinNodes < 1 {
+		fail(disgo.FailStepf("Invalid node count %d, must be >= 1", joinNodes))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	defer lockHost(client)()
+
+	disgo.StartStepf("Checking if a cluster named %q already exists", clusterName)
+
+	clusterInfo, err := sind.InspectCluster(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to check if the cluster already exists: %v", err))
+	}
+
+	if clusterInfo != nil {
+		fail(disgo.FailStepf("Cluster %q already exists, run sind delete first to remove it.", clusterName))
+	}
+
+	disgo.StartStepf("Creating %d nodes joining the swarm through %v", joinNodes, joinAddrs)
+
+	joinConfig := sind.JoinConfiguration{
+		Cluster: sind.ClusterConfiguration{
+			ClusterName:     clusterBaseName,
+			Namespace:       namespace,
+			NetworkName:     joinNetworkName,
+			ExistingNetwork: joinExistingNet,
+			Managers:        1,
+			Workers:         joinNodes - 1,
+			ImageName:       joinImageName,
+			PullImage:       joinPull,
+		},
+		JoinToken:        joinToken,
+		ManagerAddresses: joinAddrs,
+		Availability:     joinAvail,
+	}
+
+	if err := sind.JoinExternalSwarm(ctx, client, joinConfig); err != nil {
+		fail(disgo.FailStepf("Unable to join the swarm: %v", err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Cluster %q successfully joined the swarm\n", style.Success(style.SymbolCheck), clusterName)
+}
@@ -20,10 +20,14 @@ var (
 		Short:   "List sind clusters.",
 		Run:     runList,
 	}
+
+	listFilters []string
 )
 
 func init() {
 	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringSliceVarP(&listFilters, "filter", "f", []string{}, "Only list clusters having this metadata (key=value).")
 }
 
 func runList(cmd *cobra.Command, args []string) {
@@ -33,6 +37,11 @@ func runList(cmd *cobra.Command, args []string) {
 	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
+	filters, err := parseKeyValues(listFilters)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid filters: %v", err))
+	}
+
 	disgo.StartStep("Connecting to the docker daemon")
 
 	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
@@ -47,6 +56,8 @@ func runList(cmd *cobra.Command, args []string) {
 		fail(disgo.FailStepf("Unable to list clusters: %v", err))
 	}
 
+	clusters = sind.FilterClusters(sind.FilterNamespace(clusters, namespace), filters)
+
 	disgo.EndStep()
 	disgo.Infof("%s Found %d cluster(s)\n", style.Success(style.SymbolCheck), len(clusters))
 
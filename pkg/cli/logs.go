@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	logsCmd = &cobra.Command{
+		Use:   "logs [--role ROLE] [--index INDEX] [--leader]",
+		Short: "Print the logs of the docker daemon of a node of the cluster.",
+		Args:  cobra.NoArgs,
+		Run:   runLogs,
+	}
+
+	followLogs bool
+	logsTail   string
+)
+
+func init() {
+	rootCmd.AddCommand(logsCmd)
+
+	addNodeSelectorFlags(logsCmd)
+	logsCmd.Flags().BoolVarP(&followLogs, "follow", "f", false, "Follow the logs output.")
+	logsCmd.Flags().StringVarP(&logsTail, "tail", "", "all", "Number of lines to show from the end of the logs.")
+}
+
+func runLogs(cmd *cobra.Command, args []string) {
+	dockerArgs := []string{"logs", "--tail", logsTail}
+
+	if followLogs {
+		dockerArgs = append(dockerArgs, "--follow")
+	}
+
+	runDockerCLI(os.Environ(), append(dockerArgs, selectedNode().ID)...)
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	notifyCmd = &cobra.Command{
+		Use:   "notify",
+		Short: "Print and post to webhooks the clusters created, stopped and deleted, and the nodes becoming unhealthy, until interrupted.",
+		Run:   runNotify,
+	}
+
+	webhooks []string
+)
+
+func init() {
+	rootCmd.AddCommand(notifyCmd)
+
+	notifyCmd.Flags().StringArrayVarP(&webhooks, "webhook", "", []string{}, "URL to post the notifications to as JSON.")
+}
+
+func runNotify(cmd *cobra.Command, args []string) {
+	// The clusters are watched until interrupted, the command timeout does not apply.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	notifiers := []sind.Notifier{printNotification}
+
+	for _, url := range webhooks {
+		notifiers = append(notifiers, sind.WebhookNotifier(url))
+	}
+
+	errs := make(chan error)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- sind.Notify(ctx, client, notifiers, errs)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fail(disgo.FailStepf("Unable to watch the clusters: %v", err))
+			}
+
+			return
+		case err := <-errs:
+			disgo.Errorf("%s %v\n", style.Failure(style.SymbolCross), err)
+		}
+	}
+}
+
+func printNotification(ctx context.Context, notification sind.Notification) error {
+	subject := notification.Cluster
+	if notification.Node != "" {
+		subject += "/" + notification.Node
+	}
+
+	fmt.Printf("%s %s %s\n", notification.Time.Format(time.RFC3339Nano), notification.Kind, subject)
+
+	return nil
+}
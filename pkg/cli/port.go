@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+)
+
+var (
+	portCmd = &cobra.Command{
+		Use:   "port [PORT[/PROTO]]",
+		Short: "List the host ports the port bindings of the cluster are published on, by each node.",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runPort,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(portCmd)
+}
+
+func runPort(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	ports, err := sind.PublishedPorts(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the published ports: %v", err))
+	}
+
+	var filter string
+	if len(args) == 1 {
+		filter = args[0]
+		if !strings.Contains(filter, "/") {
+			filter += "/tcp"
+		}
+	}
+
+	wr := tabwriter.NewWriter(os.Stdout, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "Node\tRole\tPort\tHost\t\n")
+	fmt.Fprintf(wr, "----\t----\t----\t----\t\n")
+
+	for _, port := range ports {
+		if filter != "" && port.Port != filter {
+			continue
+		}
+
+		fmt.Fprintf(wr, "%s\t%s\t%s\t%s:%d\t\n", port.Name, port.Role, port.Port, port.HostIP, port.HostPort)
+	}
+}
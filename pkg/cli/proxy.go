@@ -0,0 +1,77 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	proxyCmd = &cobra.Command{
+		Use:   "proxy",
+		Short: "Serve a filtered docker API of the cluster, blocking destructive requests.",
+		Run:   runProxy,
+	}
+
+	proxyListenAddr string
+	proxyReadOnly   bool
+)
+
+func init() {
+	rootCmd.AddCommand(proxyCmd)
+
+	proxyCmd.Flags().StringVarP(&proxyListenAddr, "listen", "l", "127.0.0.1:2380", "Address to serve the filtered docker API on.")
+	proxyCmd.Flags().BoolVarP(&proxyReadOnly, "read-only", "", false, "Only allow requests which do not modify the cluster.")
+}
+
+func runProxy(cmd *cobra.Command, args []string) {
+	// The proxy is served until interrupted, the command timeout does not apply.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Getting the docker API of cluster %q", clusterName)
+
+	host, err := sind.ClusterHost(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to collect cluster information: %v", err))
+	}
+
+	filter := sind.NonDestructiveAPIFilter
+	if proxyReadOnly {
+		filter = sind.ReadOnlyAPIFilter
+	}
+
+	handler, err := sind.NewAPIProxy(host, filter)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to create the API proxy: %v", err))
+	}
+
+	server := http.Server{Addr: proxyListenAddr, Handler: handler}
+
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	disgo.EndStep()
+	disgo.Infof("%s Serving the docker API of cluster %q on tcp://%s\n", style.Success(style.SymbolCheck), clusterName, proxyListenAddr)
+
+	if err = server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fail(disgo.FailStepf("Unable to serve the API proxy: %v", err))
+	}
+}
@@ -0,0 +1,130 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	publishCmd = &cobra.Command{
+		Use:   "publish [HOST_PORT:NODE_PORT]",
+		Short: "Publish a port of the cluster, or its docker API as a unix socket, on the docker host.",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runPublish,
+	}
+
+	publishTarget string
+	publishSocket bool
+	socketDir     string
+)
+
+func init() {
+	rootCmd.AddCommand(publishCmd)
+
+	publishCmd.Flags().BoolVarP(&publishSocket, "unix-socket", "", false, "Publish the docker API of the cluster as a unix socket, the docker host must be local.")
+	publishCmd.Flags().StringVarP(&socketDir, "socket-dir", "", "", "Directory of the unix socket (defaults to ~/.sind).")
+	publishCmd.Flags().StringVarP(&publishTarget, "target", "", "", "Host on the cluster network to forward to (defaults to the primary node).")
+}
+
+func runPublish(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if !publishSocket && len(args) == 0 {
+		fail(disgo.FailStepf("A port mapping or --unix-socket is required"))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Checking if a cluster named %q exists", clusterName)
+
+	clusterInfo, err := sind.InspectCluster(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to check if the cluster exists: %v", err))
+	}
+
+	if clusterInfo == nil {
+		fail(disgo.FailStepf("Cluster %q does not exists", clusterName))
+	}
+
+	if publishSocket {
+		runPublishSocket(ctx, client)
+		return
+	}
+
+	hostPort, nodePort, err := parsePortMapping(args[0])
+	if err != nil {
+		fail(disgo.FailStepf("Invalid port mapping %q: %v", args[0], err))
+	}
+
+	defer lockHost(client)()
+
+	disgo.StartStepf("Publishing port %d of cluster %q on host port %d", nodePort, clusterName, hostPort)
+
+	if err = sind.PublishPort(ctx, client, clusterName, hostPort, nodePort, publishTarget); err != nil {
+		fail(disgo.FailStepf("Unable to publish port %d of cluster %q: %v", nodePort, clusterName, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Port %d of cluster %q successfully published on host port %d\n", style.Success(style.SymbolCheck), nodePort, clusterName, hostPort)
+}
+
+func runPublishSocket(ctx context.Context, client *docker.Client) {
+	dir := socketDir
+
+	if dir == "" {
+		defaultDir, err := sind.DefaultSocketDir()
+		if err != nil {
+			fail(disgo.FailStepf("Unable to get the socket directory: %v", err))
+		}
+
+		dir = defaultDir
+	}
+
+	disgo.StartStepf("Publishing the docker API of cluster %q in %s", clusterName, dir)
+
+	socketPath, err := sind.PublishSocket(ctx, client, clusterName, dir)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to publish the docker API of cluster %q: %v", clusterName, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Docker API of cluster %q successfully published on unix://%s\n", style.Success(style.SymbolCheck), clusterName, socketPath)
+}
+
+func parsePortMapping(mapping string) (uint16, uint16, error) {
+	parts := strings.Split(mapping, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("expected HOST_PORT:NODE_PORT")
+	}
+
+	hostPort, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid host port: %v", err)
+	}
+
+	nodePort, err := strconv.ParseUint(parts[1], 10, 16)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid node port: %v", err)
+	}
+
+	return uint16(hostPort), uint16(nodePort), nil
+}
@@ -64,7 +64,9 @@ func runPush(cmd *cobra.Command, args []string) {
 	disgo.StartStepf("Pushing images %q to cluster %q", args, clusterName)
 
 	if err = sind.PushImageRefs(ctx, client, clusterInfo.Name, jobs, args); err != nil {
-		fail(disgo.FailStepf("Unable to push images %q to %q: %v", args, clusterName, err))
+		failure := disgo.FailStepf("Unable to push images %q to %q: %v", args, clusterName, err)
+		reportInterruptedPush(ctx)
+		fail(failure)
 	}
 
 	disgo.EndStep()
@@ -81,9 +83,21 @@ func pushFile(ctx context.Context, client *docker.Client, clusterName string, fi
 	defer file.Close()
 
 	if err = sind.PushImageFile(ctx, client, clusterName, jobs, file); err != nil {
-		fail(disgo.FailStepf("Unable to push image archive %q to %q: %v", filePath, clusterName, err))
+		failure := disgo.FailStepf("Unable to push image archive %q to %q: %v", filePath, clusterName, err)
+		reportInterruptedPush(ctx)
+		fail(failure)
 	}
 
 	disgo.EndStep()
 	disgo.Infof("%s Successfully pushed images archive %q to cluster %q\n", style.Success(style.SymbolCheck), filePath, clusterName)
 }
+
+// reportInterruptedPush tells what an interrupted push left behind. The archives copied to the nodes are removed by the
+// push itself, but some nodes may have loaded the images already.
+func reportInterruptedPush(ctx context.Context) {
+	if !internal.Interrupted(ctx) {
+		return
+	}
+
+	disgo.Infof("Interrupted, the images may be loaded on some nodes only, push them again to load them on all nodes\n")
+}
@@ -5,15 +5,20 @@ import (
 	"os"
 	"time"
 
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind"
 	"github.com/spf13/cobra"
 	"github.com/ullaakut/disgo"
 	"github.com/ullaakut/disgo/style"
 )
 
 var (
-	clusterName    string
-	timeout        time.Duration
-	nonInteractive bool
+	// clusterName is the name of the cluster, prefixed with its namespace.
+	clusterName     string
+	clusterBaseName string
+	namespace       string
+	timeout         time.Duration
+	nonInteractive  bool
 )
 
 var rootCmd = &cobra.Command{
@@ -23,10 +28,14 @@ var rootCmd = &cobra.Command{
 	PreRun: func(*cobra.Command, []string) {
 		disgo.SetTerminalOptions(disgo.WithInteractive(!nonInteractive))
 	},
+	PersistentPreRun: func(*cobra.Command, []string) {
+		clusterName = sind.NamespacedName(namespace, clusterBaseName)
+	},
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVarP(&clusterName, "cluster", "c", "default", "Cluster name.")
+	rootCmd.PersistentFlags().StringVarP(&clusterBaseName, "cluster", "c", "default", "Cluster name.")
+	rootCmd.PersistentFlags().StringVarP(&namespace, "namespace", "", "", "Namespace of the clusters, isolating them from the clusters of other namespaces.")
 	rootCmd.PersistentFlags().DurationVarP(&timeout, "timeout", "t", 300*time.Second, "Command timeout.")
 	rootCmd.PersistentFlags().BoolVarP(&nonInteractive, "non-interactive", "y", false, "Non interactive mode.")
 }
@@ -40,6 +49,21 @@ func Execute() {
 	}
 }
 
+// lockHost takes the lock of the docker host and returns its release function. A command exiting on failure without
+// releasing it leaves nothing behind, the OS releases the lock.
+func lockHost(client *docker.Client) func() {
+	lock, err := sind.LockHost(client)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to lock the docker host: %v", err))
+	}
+
+	return func() {
+		if err := lock.Release(); err != nil {
+			disgo.Errorln(style.Failure(err))
+		}
+	}
+}
+
 func fail(err error) {
 	disgo.Errorln(style.Failure(err))
 	os.Exit(1)
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	saveCmd = &cobra.Command{
+		Use:   "save [--node NODE] [--output FILE] IMAGE...",
+		Short: "Load images of a node of the cluster on the docker host, or write them to an archive.",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runSave,
+	}
+
+	saveNode   string
+	saveOutput string
+)
+
+func init() {
+	rootCmd.AddCommand(saveCmd)
+
+	saveCmd.Flags().StringVarP(&saveNode, "node", "", "", "Node to save the images from (e.g. worker-0), the primary node if empty.")
+	saveCmd.Flags().StringVarP(&saveOutput, "output", "o", "", "Write the images to an archive file instead of loading them on the docker host.")
+}
+
+func runSave(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	if saveOutput != "" {
+		saveArchive(ctx, client, args)
+		return
+	}
+
+	disgo.StartStepf("Loading images %q of cluster %q on the docker host", args, clusterName)
+
+	if err = sind.LoadImageRefs(ctx, client, clusterName, saveNode, args); err != nil {
+		fail(disgo.FailStepf("Unable to load images %q of cluster %q: %v", args, clusterName, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Successfully loaded images %q of cluster %q\n", style.Success(style.SymbolCheck), args, clusterName)
+}
+
+func saveArchive(ctx context.Context, client *docker.Client, refs []string) {
+	disgo.StartStepf("Saving images %q of cluster %q to %q", refs, clusterName, saveOutput)
+
+	file, err := os.Create(saveOutput)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to create file %q: %v", saveOutput, err))
+	}
+	defer file.Close()
+
+	if err = sind.SaveImageRefs(ctx, client, clusterName, saveNode, refs, file); err != nil {
+		fail(disgo.FailStepf("Unable to save images %q of cluster %q: %v", refs, clusterName, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Successfully saved images %q of cluster %q to %q\n", style.Success(style.SymbolCheck), refs, clusterName, saveOutput)
+}
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	scaleCmd = &cobra.Command{
+		Use:   "scale",
+		Short: "Add or remove nodes of a running cluster, joining or leaving the swarm.",
+		Run:   runScale,
+	}
+
+	scaleManagers uint16
+	scaleWorkers  uint16
+)
+
+func init() {
+	rootCmd.AddCommand(scaleCmd)
+
+	scaleCmd.Flags().Uint16VarP(&scaleManagers, "managers", "m", 1, "Amount of managers the cluster must have, primary node included.")
+	scaleCmd.Flags().Uint16VarP(&scaleWorkers, "workers", "w", 0, "Amount of workers the cluster must have.")
+}
+
+func runScale(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if !cmd.Flags().Changed("managers") && !cmd.Flags().Changed("workers") {
+		fail(disgo.FailStepf("At least one of --managers and --workers is required"))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	defer lockHost(client)()
+
+	disgo.StartStepf("Inspecting cluster %q", clusterName)
+
+	clusterInfo, err := sind.InspectCluster(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to inspect cluster %q: %v", clusterName, err))
+	}
+
+	if clusterInfo == nil {
+		fail(disgo.FailStepf("Cluster %q does not exists", clusterName))
+	}
+
+	if cmd.Flags().Changed("managers") {
+		disgo.StartStepf("Scaling the managers of cluster %q from %d to %d", clusterName, clusterInfo.Managers, scaleManagers)
+
+		if err = sind.ScaleManagers(ctx, client, clusterName, int(scaleManagers)-int(clusterInfo.Managers)); err != nil {
+			fail(disgo.FailStepf("Unable to scale the managers of cluster %q: %v", clusterName, err))
+		}
+	}
+
+	if cmd.Flags().Changed("workers") {
+		disgo.StartStepf("Scaling the workers of cluster %q from %d to %d", clusterName, clusterInfo.Workers, scaleWorkers)
+
+		if err = sind.ScaleWorkers(ctx, client, clusterName, int(scaleWorkers)-int(clusterInfo.Workers)); err != nil {
+			fail(disgo.FailStepf("Unable to scale the workers of cluster %q: %v", clusterName, err))
+		}
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Cluster %q successfully scaled\n", style.Success(style.SymbolCheck), clusterName)
+}
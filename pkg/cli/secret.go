@@ -0,0 +1,134 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	secretCmd = &cobra.Command{
+		Use:   "secret",
+		Short: "Manage the secrets of a cluster.",
+	}
+
+	secretCreateCmd = &cobra.Command{
+		Use:   "create NAME [FILE|-]",
+		Short: "Create a secret from a file, stdin or a literal.",
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runSecretCreate,
+	}
+
+	secretListCmd = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the secrets of the cluster.",
+		Run:     runSecretList,
+	}
+
+	secretRemoveCmd = &cobra.Command{
+		Use:     "rm SECRET [SECRET...]",
+		Aliases: []string{"remove"},
+		Short:   "Remove secrets from the cluster.",
+		Args:    cobra.MinimumNArgs(1),
+		Run:     runSecretRemove,
+	}
+
+	secretLiteral string
+)
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+	secretCmd.AddCommand(secretCreateCmd)
+	secretCmd.AddCommand(secretListCmd)
+	secretCmd.AddCommand(secretRemoveCmd)
+
+	secretCreateCmd.Flags().StringVarP(&secretLiteral, "literal", "l", "", "Value of the secret, instead of reading it from a file.")
+}
+
+func runSecretCreate(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	var path string
+	if len(args) > 1 {
+		path = args[1]
+	}
+
+	data, err := internal.ReadData(path, secretLiteral)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to read the secret: %v", err))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Creating secret %q on cluster %q", args[0], clusterName)
+
+	if _, err = sind.CreateSecret(ctx, client, clusterName, args[0], data); err != nil {
+		fail(disgo.FailStepf("Unable to create secret %q: %v", args[0], err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Secret %q successfully created\n", style.Success(style.SymbolCheck), args[0])
+}
+
+func runSecretList(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	secrets, err := sind.ListSecrets(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the secrets of cluster %q: %v", clusterName, err))
+	}
+
+	internal.RenderSwarmDataList(os.Stdout, secrets)
+}
+
+func runSecretRemove(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	for _, name := range args {
+		disgo.StartStepf("Removing secret %q from cluster %q", name, clusterName)
+
+		if err = sind.RemoveSecret(ctx, client, clusterName, name); err != nil {
+			fail(disgo.FailStepf("Unable to remove secret %q: %v", name, err))
+		}
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Secret(s) successfully removed\n", style.Success(style.SymbolCheck))
+}
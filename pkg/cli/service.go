@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	serviceCmd = &cobra.Command{
+		Use:   "service",
+		Short: "Manage the services deployed on a cluster.",
+	}
+
+	serviceListCmd = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the services deployed on the cluster.",
+		Run:     runServiceList,
+	}
+
+	servicePsCmd = &cobra.Command{
+		Use:   "ps SERVICE",
+		Short: "List the tasks of a service.",
+		Args:  cobra.ExactArgs(1),
+		Run:   runServicePs,
+	}
+
+	serviceInspectCmd = &cobra.Command{
+		Use:   "inspect SERVICE",
+		Short: "Print the swarm representation of a service as JSON.",
+		Args:  cobra.ExactArgs(1),
+		Run:   runServiceInspect,
+	}
+
+	serviceRemoveCmd = &cobra.Command{
+		Use:     "rm SERVICE [SERVICE...]",
+		Aliases: []string{"remove"},
+		Short:   "Remove services deployed on the cluster.",
+		Args:    cobra.MinimumNArgs(1),
+		Run:     runServiceRemove,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(serviceCmd)
+	serviceCmd.AddCommand(serviceListCmd)
+	serviceCmd.AddCommand(servicePsCmd)
+	serviceCmd.AddCommand(serviceInspectCmd)
+	serviceCmd.AddCommand(serviceRemoveCmd)
+}
+
+func runServiceList(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	services, err := sind.ListServices(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the services of cluster %q: %v", clusterName, err))
+	}
+
+	wr := tabwriter.NewWriter(os.Stdout, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "Name\tMode\tReplicas\tImage\tPorts\tStack\t\n")
+	fmt.Fprintf(wr, "----\t----\t--------\t-----\t-----\t-----\t\n")
+
+	for _, service := range services {
+		stack := service.Stack
+		if stack == "" {
+			stack = "-"
+		}
+
+		fmt.Fprintf(
+			wr,
+			"%s\t%s\t%d/%d\t%s\t%s\t%s\t\n",
+			service.Name,
+			service.Mode,
+			service.Running,
+			service.Desired,
+			service.Image,
+			strings.Join(service.Ports, ", "),
+			stack,
+		)
+	}
+}
+
+func runServicePs(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	tasks, err := sind.ServiceTasks(ctx, client, clusterName, args[0])
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the tasks of service %q: %v", args[0], err))
+	}
+
+	renderTasks(tasks)
+}
+
+// renderTasks renders a list of service tasks on stdout, named after their service and slot.
+func renderTasks(tasks []sind.ServiceTask) {
+	wr := tabwriter.NewWriter(os.Stdout, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "ID\tName\tNode\tImage\tDesired\tState\tUpdated\tError\t\n")
+	fmt.Fprintf(wr, "--\t----\t----\t-----\t-------\t-----\t-------\t-----\t\n")
+
+	for _, task := range tasks {
+		name := task.Service
+		if task.Slot != 0 {
+			name = fmt.Sprintf("%s.%d", task.Service, task.Slot)
+		}
+
+		fmt.Fprintf(
+			wr,
+			"%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			task.ID,
+			name,
+			task.Node,
+			task.Image,
+			task.DesiredState,
+			task.State,
+			task.UpdatedAt.Local().Format(time.RFC3339),
+			task.Err,
+		)
+	}
+}
+
+func runServiceInspect(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	service, err := sind.InspectService(ctx, client, clusterName, args[0])
+	if err != nil {
+		fail(disgo.FailStepf("Unable to inspect service %q: %v", args[0], err))
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err = encoder.Encode(service); err != nil {
+		fail(disgo.FailStepf("Unable to encode service %q: %v", args[0], err))
+	}
+}
+
+func runServiceRemove(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	for _, service := range args {
+		disgo.StartStepf("Removing service %q from cluster %q", service, clusterName)
+
+		if err = sind.RemoveService(ctx, client, clusterName, service); err != nil {
+			fail(disgo.FailStepf("Unable to remove service %q: %v", service, err))
+		}
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Service(s) successfully removed\n", style.Success(style.SymbolCheck))
+}
@@ -0,0 +1,26 @@
+package cli
+
+import (
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	shellCmd = &cobra.Command{
+		Use:   "shell [--role ROLE] [--index INDEX] [--leader]",
+		Short: "Open a shell in a node of the cluster.",
+		Args:  cobra.NoArgs,
+		Run:   runShell,
+	}
+)
+
+func init() {
+	rootCmd.AddCommand(shellCmd)
+
+	addNodeSelectorFlags(shellCmd)
+}
+
+func runShell(cmd *cobra.Command, args []string) {
+	runDockerCLI(os.Environ(), execArgs(selectedNode(), "sh")...)
+}
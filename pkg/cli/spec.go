@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/pflag"
+)
+
+// applyClusterSpec sets the create flags from a cluster spec, the flags given on the command line taking precedence.
+func applyClusterSpec(flags *pflag.FlagSet, spec *sind.ClusterSpec) {
+	unset := func(name string) bool { return !flags.Changed(name) }
+
+	if spec.Name != "" && unset("cluster") {
+		clusterBaseName = spec.Name
+	}
+
+	if spec.Namespace != "" && unset("namespace") {
+		namespace = spec.Namespace
+	}
+
+	clusterName = sind.NamespacedName(namespace, clusterBaseName)
+
+	if spec.Managers != 0 && unset("managers") {
+		managers = spec.Managers
+	}
+
+	if spec.Workers != 0 && unset("workers") {
+		workers = spec.Workers
+	}
+
+	if spec.Image != "" && unset("image") {
+		nodeImageName = spec.Image
+	}
+
+	if spec.Pull && unset("pull") {
+		pull = true
+	}
+
+	if spec.LoadBalancer && unset("load-balancer") {
+		loadBalancer = true
+	}
+
+	if spec.Plain && unset("plain") {
+		plain = true
+	}
+
+	if spec.TLS && unset("tls") {
+		daemonTLS = true
+	}
+
+	if len(spec.DaemonArgs) > 0 && unset("daemon-arg") {
+		daemonArgs = spec.DaemonArgs
+	}
+
+	if len(spec.Ports) > 0 && unset("ports") {
+		portsMapping = spec.Ports
+	}
+
+	if len(spec.Metadata) > 0 && unset("metadata") {
+		metadata = keyValues(spec.Metadata)
+	}
+
+	if spec.TTL != 0 && unset("ttl") {
+		ttl = spec.TTL
+	}
+
+	if spec.IdleTimeout != 0 && unset("idle-timeout") {
+		idleTimeout = spec.IdleTimeout
+	}
+
+	// The network is named after the cluster, as the default network name is shared by all clusters.
+	if spec.Name != "" && spec.Network.Name == "" && spec.Network.Existing == "" && unset("network-name") {
+		networkName = "sind-" + spec.Name
+	}
+
+	applyNetworkSpec(unset, spec.Network)
+	applySwarmSpec(unset, spec.Swarm)
+}
+
+func applyNetworkSpec(unset func(string) bool, spec sind.NetworkSpec) {
+	if spec.Name != "" && unset("network-name") {
+		networkName = spec.Name
+	}
+
+	if spec.Existing != "" && unset("existing-network") {
+		existingNet = spec.Existing
+	}
+
+	if spec.Driver != "" && unset("network-driver") {
+		netDriver = spec.Driver
+	}
+
+	if len(spec.Options) > 0 && unset("network-opt") {
+		netOptions = keyValues(spec.Options)
+	}
+
+	if spec.Subnet != "" && unset("subnet") {
+		subnet = spec.Subnet
+	}
+
+	if spec.Gateway != "" && unset("gateway") {
+		gateway = spec.Gateway
+	}
+
+	if spec.IPRange != "" && unset("ip-range") {
+		ipRange = spec.IPRange
+	}
+}
+
+func applySwarmSpec(unset func(string) bool, spec sind.SwarmSpec) {
+	if spec.ManagerAvailability != "" && unset("manager-availability") {
+		managerAvail = spec.ManagerAvailability
+	}
+
+	if spec.WorkerAvailability != "" && unset("worker-availability") {
+		workerAvail = spec.WorkerAvailability
+	}
+
+	if len(spec.Networks) > 0 && unset("swarm-network") {
+		swarmNetworks = nil
+
+		for _, network := range spec.Networks {
+			swarmNetworks = append(swarmNetworks, swarmNetworkFlag(network))
+		}
+	}
+
+	if len(spec.Secrets) > 0 && unset("swarm-secret") {
+		swarmSecrets = keyValues(spec.Secrets)
+	}
+
+	if len(spec.Configs) > 0 && unset("swarm-config") {
+		swarmConfigs = keyValues(spec.Configs)
+	}
+
+	if len(spec.NodeLabels) > 0 && unset("node-label") {
+		nodeLabels = nil
+
+		for node, labels := range spec.NodeLabels {
+			for _, label := range keyValues(labels) {
+				nodeLabels = append(nodeLabels, node+":"+label)
+			}
+		}
+	}
+}
+
+// swarmNetworkFlag formats a swarm network as the --swarm-network flag.
+func swarmNetworkFlag(network sind.SwarmNetworkSpec) string {
+	flag := network.Name
+
+	if network.Attachable {
+		flag += ",attachable"
+	}
+
+	if network.Internal {
+		flag += ",internal"
+	}
+
+	if network.Subnet != "" {
+		flag += ",subnet=" + network.Subnet
+	}
+
+	return flag
+}
+
+// keyValues formats a map as key=value strings.
+func keyValues(values map[string]string) []string {
+	result := make([]string, 0, len(values))
+
+	for key, value := range values {
+		result = append(result, key+"="+value)
+	}
+
+	return result
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	stackCmd = &cobra.Command{
+		Use:   "stack",
+		Short: "Manage the stacks deployed on a cluster.",
+	}
+
+	stackDeployCmd = &cobra.Command{
+		Use:   "deploy STACK",
+		Short: "Deploy a stack on the cluster from a compose file, or update it.",
+		Args:  cobra.ExactArgs(1),
+		Run:   runStackDeploy,
+	}
+
+	stackListCmd = &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List the stacks deployed on the cluster.",
+		Run:     runStackList,
+	}
+
+	stackPsCmd = &cobra.Command{
+		Use:   "ps STACK",
+		Short: "List the tasks of a stack.",
+		Args:  cobra.ExactArgs(1),
+		Run:   runStackPs,
+	}
+
+	stackRemoveCmd = &cobra.Command{
+		Use:     "rm STACK [STACK...]",
+		Aliases: []string{"remove"},
+		Short:   "Remove stacks deployed on the cluster.",
+		Args:    cobra.MinimumNArgs(1),
+		Run:     runStackRemove,
+	}
+
+	composeFile string
+)
+
+func init() {
+	rootCmd.AddCommand(stackCmd)
+	stackCmd.AddCommand(stackDeployCmd)
+	stackCmd.AddCommand(stackListCmd)
+	stackCmd.AddCommand(stackPsCmd)
+	stackCmd.AddCommand(stackRemoveCmd)
+
+	stackDeployCmd.Flags().StringVarP(&composeFile, "compose-file", "", "", "Path to the compose file of the stack, - for stdin.")
+}
+
+func runStackDeploy(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if composeFile == "" {
+		fail(disgo.FailStepf("A compose file is required"))
+	}
+
+	compose, err := internal.ReadData(composeFile, "")
+	if err != nil {
+		fail(disgo.FailStepf("Unable to read the compose file: %v", err))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	stacks, err := sind.ListStacks(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the stacks of cluster %q: %v", clusterName, err))
+	}
+
+	disgo.StartStepf("Deploying stack %q on cluster %q", args[0], clusterName)
+
+	if err = sind.DeployStack(ctx, client, clusterName, args[0], compose); err != nil {
+		failure := disgo.FailStepf("Unable to deploy stack %q: %v", args[0], err)
+
+		// An existing stack is partially updated, removing it would remove what was running before.
+		if internal.Interrupted(ctx) && !stackExists(stacks, args[0]) {
+			cleanupStack(client, args[0])
+		}
+
+		fail(failure)
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Stack %q successfully deployed\n", style.Success(style.SymbolCheck), args[0])
+}
+
+func runStackList(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	stacks, err := sind.ListStacks(ctx, client, clusterName)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the stacks of cluster %q: %v", clusterName, err))
+	}
+
+	wr := tabwriter.NewWriter(os.Stdout, 4, 8, 2, '\t', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "Name\tServices\t\n")
+	fmt.Fprintf(wr, "----\t--------\t\n")
+
+	for _, stack := range stacks {
+		fmt.Fprintf(wr, "%s\t%s\t\n", stack.Name, strings.Join(stack.Services, ", "))
+	}
+}
+
+func runStackPs(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	tasks, err := sind.StackTasks(ctx, client, clusterName, args[0])
+	if err != nil {
+		fail(disgo.FailStepf("Unable to list the tasks of stack %q: %v", args[0], err))
+	}
+
+	renderTasks(tasks)
+}
+
+func runStackRemove(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	for _, stackName := range args {
+		disgo.StartStepf("Removing stack %q from cluster %q", stackName, clusterName)
+
+		if err = sind.RemoveStack(ctx, client, clusterName, stackName); err != nil {
+			fail(disgo.FailStepf("Unable to remove stack %q: %v", stackName, err))
+		}
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Stack(s) successfully removed\n", style.Success(style.SymbolCheck))
+}
+
+func stackExists(stacks []sind.Stack, name string) bool {
+	for _, stack := range stacks {
+		if stack.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// cleanupStack removes what an interrupted deployment created of a stack.
+func cleanupStack(client *docker.Client, name string) {
+	disgo.Infof("Interrupted, removing what has been deployed, interrupt again to leave it as is\n")
+
+	ctx, cancel := cleanupContext()
+	defer cancel()
+
+	if err := sind.RemoveStack(ctx, client, clusterName, name); err != nil {
+		disgo.Errorf("%s Unable to remove what has been deployed of stack %q, run sind stack rm %s: %v\n", style.Failure(style.SymbolCross), name, name, err)
+		return
+	}
+
+	disgo.Infof("%s Removed what has been deployed of stack %q\n", style.Success(style.SymbolCheck), name)
+}
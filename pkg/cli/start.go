@@ -15,7 +15,7 @@ import (
 var (
 	startCmd = &cobra.Command{
 		Use:   "start",
-		Short: "Start a sind cluster.",
+		Short: "Start a stopped sind cluster, waiting for its swarm to converge.",
 		Run:   runStart,
 	}
 )
@@ -55,6 +55,12 @@ func runStart(cmd *cobra.Command, args []string) {
 		fail(disgo.FailStepf("Unable to start cluster %q: %v", clusterInfo.Name, err))
 	}
 
+	// The daemon port of the primary node may be published on another host port once restarted.
+	host, err := sind.ClusterHost(ctx, client, clusterInfo.Name)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to get the cluster host: %v", err))
+	}
+
 	disgo.EndStep()
-	disgo.Infof("%s Cluster %q successfully started\n", style.Success(style.SymbolCheck), clusterName)
+	disgo.Infof("%s Cluster %q successfully started, reachable at %s\n", style.Success(style.SymbolCheck), clusterName, host)
 }
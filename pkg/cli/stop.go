@@ -3,6 +3,7 @@ package cli
 import (
 	"context"
 	"syscall"
+	"time"
 
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/cli/internal"
@@ -18,10 +19,16 @@ var (
 		Short: "Stop a sind cluster.",
 		Run:   runStop,
 	}
+
+	stopNodesTimeout time.Duration
+	drainNodes       bool
 )
 
 func init() {
 	rootCmd.AddCommand(stopCmd)
+
+	stopCmd.Flags().DurationVarP(&stopNodesTimeout, "stop-timeout", "", 0, "Time given to the nodes to stop before being killed.")
+	stopCmd.Flags().BoolVarP(&drainNodes, "drain", "", false, "Drain the swarm nodes before stopping them, they are restored when the cluster is started.")
 }
 
 func runStop(cmd *cobra.Command, args []string) {
@@ -51,7 +58,7 @@ func runStop(cmd *cobra.Command, args []string) {
 
 	disgo.StartStepf("Stopping cluster %q", clusterName)
 
-	if err = sind.StopCluster(ctx, client, clusterInfo.Name); err != nil {
+	if err = sind.StopClusterWithOptions(ctx, client, clusterInfo.Name, sind.StopOptions{Timeout: stopNodesTimeout, Drain: drainNodes}); err != nil {
 		fail(disgo.FailStepf("Unable to stop cluster %q: %v", clusterInfo.Name, err))
 	}
 
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	swarmCmd = &cobra.Command{
+		Use:   "swarm",
+		Short: "Manage the swarm of a cluster.",
+	}
+
+	swarmUpdateCmd = &cobra.Command{
+		Use:   "update",
+		Short: "Update the swarm wide settings of the cluster, the settings not given are left unchanged.",
+		Args:  cobra.NoArgs,
+		Run:   runSwarmUpdate,
+	}
+
+	taskHistoryLimit    int64
+	autolock            bool
+	certExpiry          time.Duration
+	dispatcherHeartbeat time.Duration
+)
+
+func init() {
+	rootCmd.AddCommand(swarmCmd)
+	swarmCmd.AddCommand(swarmUpdateCmd)
+
+	swarmUpdateCmd.Flags().Int64VarP(&taskHistoryLimit, "task-history-limit", "", 5, "Amount of terminated tasks kept per slot.")
+	swarmUpdateCmd.Flags().BoolVarP(&autolock, "autolock", "", false, "Lock the managers on restart, a locked cluster can't be started again by sind.")
+	swarmUpdateCmd.Flags().DurationVarP(&certExpiry, "cert-expiry", "", 90*24*time.Hour, "Validity period of the node certificates.")
+	swarmUpdateCmd.Flags().DurationVarP(&dispatcherHeartbeat, "dispatcher-heartbeat", "", 5*time.Second, "Delay between the heartbeats of the nodes.")
+}
+
+func runSwarmUpdate(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	var update sind.SwarmSpecUpdate
+
+	if cmd.Flags().Changed("task-history-limit") {
+		update.TaskHistoryLimit = &taskHistoryLimit
+	}
+
+	if cmd.Flags().Changed("autolock") {
+		update.Autolock = &autolock
+	}
+
+	if cmd.Flags().Changed("cert-expiry") {
+		update.CertExpiry = &certExpiry
+	}
+
+	if cmd.Flags().Changed("dispatcher-heartbeat") {
+		update.DispatcherHeartbeat = &dispatcherHeartbeat
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	disgo.StartStepf("Updating the swarm of cluster %q", clusterName)
+
+	if err = sind.UpdateSwarmSpec(ctx, client, clusterName, update); err != nil {
+		fail(disgo.FailStepf("Unable to update the swarm of cluster %q: %v", clusterName, err))
+	}
+
+	if update.Autolock != nil && autolock {
+		disgo.StartStep("Getting the unlock key")
+
+		unlockKey, err := sind.SwarmUnlockKey(ctx, client, clusterName)
+		if err != nil {
+			fail(disgo.FailStepf("Unable to get the unlock key: %v", err))
+		}
+
+		disgo.EndStep()
+		disgo.Infof("Unlock key: %s\n", unlockKey)
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Swarm of cluster %q successfully updated\n", style.Success(style.SymbolCheck), clusterName)
+}
@@ -0,0 +1,80 @@
+package cli
+
+import (
+	"context"
+	"syscall"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+	"github.com/ullaakut/disgo/style"
+)
+
+var (
+	throttleCmd = &cobra.Command{
+		Use:   "throttle [--node NODE]... --rate RATE | --reset",
+		Short: "Cap the bandwidth of the nodes on the cluster network, or remove the caps.",
+		Args:  cobra.NoArgs,
+		Run:   runThrottle,
+	}
+
+	throttleRate  string
+	throttleReset bool
+	throttleNodes []string
+)
+
+func init() {
+	rootCmd.AddCommand(throttleCmd)
+
+	throttleCmd.Flags().StringVarP(&throttleRate, "rate", "", "", "Outbound bandwidth of each node (e.g. 500kbit, 10mbit, 1gbit).")
+	throttleCmd.Flags().BoolVarP(&throttleReset, "reset", "", false, "Remove the bandwidth caps of the nodes.")
+	throttleCmd.Flags().StringSliceVarP(&throttleNodes, "node", "", []string{}, "Only throttle given nodes (e.g. worker-0).")
+}
+
+func runThrottle(cmd *cobra.Command, args []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ctx, cancel = internal.WithSignal(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	if (throttleRate == "") == !throttleReset {
+		fail(disgo.FailStepf("Either --rate or --reset is required"))
+	}
+
+	disgo.StartStep("Connecting to the docker daemon")
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	if throttleReset {
+		disgo.StartStepf("Removing the bandwidth caps of cluster %q", clusterName)
+
+		if err = sind.UnthrottleCluster(ctx, client, clusterName, throttleNodes); err != nil {
+			fail(disgo.FailStepf("Unable to remove the bandwidth caps of cluster %q: %v", clusterName, err))
+		}
+
+		disgo.EndStep()
+		disgo.Infof("%s Successfully removed the bandwidth caps of cluster %q\n", style.Success(style.SymbolCheck), clusterName)
+
+		return
+	}
+
+	rate, err := sind.ParseBandwidth(throttleRate)
+	if err != nil {
+		fail(disgo.FailStepf("Invalid rate: %v", err))
+	}
+
+	disgo.StartStepf("Capping the bandwidth of the nodes of cluster %q to %s", clusterName, throttleRate)
+
+	if err = sind.ThrottleCluster(ctx, client, clusterName, sind.ThrottleConfiguration{Rate: rate, Nodes: throttleNodes}); err != nil {
+		fail(disgo.FailStepf("Unable to throttle cluster %q: %v", clusterName, err))
+	}
+
+	disgo.EndStep()
+	disgo.Infof("%s Successfully capped the bandwidth of cluster %q to %s\n", style.Success(style.SymbolCheck), clusterName, throttleRate)
+}
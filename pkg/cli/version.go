@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"runtime"
 
+	"github.com/jlevesy/sind/pkg/sind"
 	"github.com/spf13/cobra"
 )
 
@@ -21,6 +22,8 @@ var (
 
 func init() {
 	rootCmd.AddCommand(versionCmd)
+
+	sind.Version = version
 }
 
 func runVersion(cmd *cobra.Command, args []string) {
@@ -0,0 +1,60 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/cli/internal"
+	"github.com/jlevesy/sind/pkg/sind"
+	"github.com/spf13/cobra"
+	"github.com/ullaakut/disgo"
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Print the lifecycle changes of the cluster: nodes dying or restarting, networks removed.",
+	Run:   runWatch,
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+}
+
+func runWatch(cmd *cobra.Command, args []string) {
+	// The cluster is watched until interrupted, the command timeout does not apply.
+	ctx, cancel := internal.WithSignal(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	client, err := docker.NewClientWithOpts(internal.DefaultDockerOpts...)
+	if err != nil {
+		fail(disgo.FailStepf("Unable to connect to the docker daemon: %v", err))
+	}
+
+	out := make(chan sind.Notification)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- sind.WatchCluster(ctx, client, clusterName, out)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			if err != nil {
+				fail(disgo.FailStepf("Unable to watch cluster %q: %v", clusterName, err))
+			}
+
+			return
+		case notification := <-out:
+			subject := notification.Node
+			if notification.Kind == sind.NetworkRemoved {
+				subject = notification.Network
+			}
+
+			fmt.Printf("%s %s %s\n", notification.Time.Format(time.RFC3339Nano), notification.Kind, subject)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// AdoptConfiguration represents the selection of existing containers forming a swarm, adopted as a sind cluster.
+type AdoptConfiguration struct {
+	ClusterName string
+	// Namespace is the namespace the cluster is adopted in, see NamespacedName.
+	Namespace string
+
+	// Containers are the IDs or names of the containers to adopt.
+	Containers []string
+	// Label selects the containers to adopt instead of Containers, as key or key=value.
+	Label string
+}
+
+func (a *AdoptConfiguration) validate() error {
+	if a.ClusterName == "" {
+		return ErrEmptyClusterName
+	}
+
+	if (len(a.Containers) == 0) == (a.Label == "") {
+		return ErrInvalidAdoptSelection
+	}
+
+	return nil
+}
+
+// adoptedNode is a container to adopt, along with its role in the cluster.
+type adoptedNode struct {
+	container types.Container
+	role      string
+}
+
+// AdoptCluster brings running docker in docker containers forming a swarm under sind management, as a cluster which can
+// then be inspected, stopped or deleted like the clusters created by sind.
+// Labels can't be added to existing containers: each container is replaced by an identical one, labeled as a node of
+// the cluster, mounting the volumes and keeping the address of the replaced one. The containers must then be attached
+// to a single network with a user defined subnet. The first manager becomes the primary node of the cluster, its
+// docker daemon must listen on tcp port 2375 without TLS.
+func AdoptCluster(ctx context.Context, hostClient *docker.Client, params AdoptConfiguration) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	clusterName := NamespacedName(params.Namespace, params.ClusterName)
+
+	status, err := InspectCluster(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to check if the cluster already exists: %w", err)
+	}
+
+	if status != nil {
+		return fmt.Errorf("%w: %q", ErrClusterAlreadyExists, clusterName)
+	}
+
+	containers, err := adoptedContainers(ctx, hostClient, params)
+	if err != nil {
+		return err
+	}
+
+	nodes := make([]adoptedNode, len(containers))
+
+	for i, container := range containers {
+		if err = checkAdoptable(container); err != nil {
+			return err
+		}
+
+		role, err := internal.SwarmRole(ctx, hostClient, container.ID)
+		if err != nil {
+			return err
+		}
+
+		nodes[i] = adoptedNode{container: container, role: role}
+	}
+
+	if err = electPrimary(nodes); err != nil {
+		return err
+	}
+
+	networkName, _, err := internal.NodeNetwork(nodes[0].container)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		labels := creationLabels(time.Now())
+		labels[internal.ClusterNameLabel] = clusterName
+		labels[internal.NodeRoleLabel] = node.role
+		labels[internal.NetworkNameLabel] = networkName
+
+		if params.Namespace != "" {
+			labels[internal.NamespaceLabel] = params.Namespace
+		}
+
+		newID, err := internal.AdoptContainer(ctx, hostClient, node.container.ID, labels, node.role == internal.NodeRolePrimary)
+		if err != nil {
+			return fmt.Errorf("unable to adopt container %q: %w", containerName(node.container), err)
+		}
+
+		// Nodes are adopted one at a time, for the swarm to keep its quorum.
+		if err = internal.WaitNodesReady(ctx, hostClient, []types.Container{{ID: newID}}); err != nil {
+			return fmt.Errorf("unable to contact the daemon of adopted container %q: %w", containerName(node.container), err)
+		}
+	}
+
+	return nil
+}
+
+// adoptedContainers returns the containers selected by the configuration, sorted by name.
+func adoptedContainers(ctx context.Context, hostClient internal.ContainerLister, params AdoptConfiguration) ([]types.Container, error) {
+	args := filters.NewArgs()
+
+	if params.Label != "" {
+		args.Add("label", params.Label)
+	}
+
+	containers, err := hostClient.ContainerList(ctx, types.ContainerListOptions{All: true, Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list containers: %w", err)
+	}
+
+	if params.Label == "" {
+		containers, err = selectContainers(containers, params.Containers)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(containers) == 0 {
+		return nil, fmt.Errorf("%w: no container matches label %q", ErrNoContainerToAdopt, params.Label)
+	}
+
+	sort.Slice(containers, func(i, j int) bool {
+		return containerName(containers[i]) < containerName(containers[j])
+	})
+
+	return containers, nil
+}
+
+// selectContainers returns the containers with given IDs, ID prefixes or names.
+func selectContainers(containers []types.Container, refs []string) ([]types.Container, error) {
+	selected := make([]types.Container, 0, len(refs))
+
+	for _, ref := range refs {
+		var found bool
+
+		for _, container := range containers {
+			if strings.HasPrefix(container.ID, ref) || containerName(container) == ref {
+				selected = append(selected, container)
+				found = true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, fmt.Errorf("%w: container %q not found", ErrNoContainerToAdopt, ref)
+		}
+	}
+
+	return selected, nil
+}
+
+// checkAdoptable returns an error if a container can't be adopted.
+func checkAdoptable(container types.Container) error {
+	if cluster, ok := container.Labels[internal.ClusterNameLabel]; ok {
+		return fmt.Errorf("%w: container %q is already managed by cluster %q", ErrContainerNotAdoptable, containerName(container), cluster)
+	}
+
+	if container.State != "running" {
+		return fmt.Errorf("%w: container %q is %s", ErrContainerNotAdoptable, containerName(container), container.State)
+	}
+
+	if container.NetworkSettings == nil || len(container.NetworkSettings.Networks) != 1 {
+		return fmt.Errorf("%w: container %q must be attached to a single network", ErrContainerNotAdoptable, containerName(container))
+	}
+
+	return nil
+}
+
+// electPrimary makes the first manager the primary node, first of the nodes.
+func electPrimary(nodes []adoptedNode) error {
+	for i, node := range nodes {
+		if node.role != internal.NodeRoleManager {
+			continue
+		}
+
+		nodes[0], nodes[i] = nodes[i], nodes[0]
+		nodes[0].role = internal.NodeRolePrimary
+
+		return nil
+	}
+
+	return fmt.Errorf("%w: none of the containers is a swarm manager", ErrContainerNotAdoptable)
+}
+
+func containerName(container types.Container) string {
+	if len(container.Names) == 0 {
+		return container.ID
+	}
+
+	return strings.TrimPrefix(container.Names[0], "/")
+}
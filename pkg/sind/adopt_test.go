@@ -0,0 +1,122 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdoptConfigurationValidate(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		cfg           AdoptConfiguration
+		expectedError error
+	}{
+		{desc: "by name", cfg: AdoptConfiguration{ClusterName: "test", Containers: []string{"a"}}},
+		{desc: "by label", cfg: AdoptConfiguration{ClusterName: "test", Label: "swarm=test"}},
+		{desc: "without cluster name", cfg: AdoptConfiguration{Label: "swarm=test"}, expectedError: ErrEmptyClusterName},
+		{desc: "without selection", cfg: AdoptConfiguration{ClusterName: "test"}, expectedError: ErrInvalidAdoptSelection},
+		{
+			desc:          "with both selections",
+			cfg:           AdoptConfiguration{ClusterName: "test", Containers: []string{"a"}, Label: "swarm=test"},
+			expectedError: ErrInvalidAdoptSelection,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expectedError, test.cfg.validate())
+		})
+	}
+}
+
+func TestSelectContainers(t *testing.T) {
+	containers := []types.Container{
+		{ID: "aaaaaa", Names: []string{"/manager"}},
+		{ID: "bbbbbb", Names: []string{"/worker"}},
+	}
+
+	selected, err := selectContainers(containers, []string{"worker", "aaa"})
+	require.NoError(t, err)
+	assert.Equal(t, []types.Container{containers[1], containers[0]}, selected)
+
+	_, err = selectContainers(containers, []string{"other"})
+	assert.True(t, errors.Is(err, ErrNoContainerToAdopt))
+}
+
+func TestCheckAdoptable(t *testing.T) {
+	networks := &types.SummaryNetworkSettings{Networks: map[string]*network.EndpointSettings{"swarm-net": {}}}
+
+	testCases := []struct {
+		desc      string
+		container types.Container
+		adoptable bool
+	}{
+		{
+			desc:      "running on a single network",
+			container: types.Container{State: "running", NetworkSettings: networks},
+			adoptable: true,
+		},
+		{
+			desc:      "stopped",
+			container: types.Container{State: "exited", NetworkSettings: networks},
+		},
+		{
+			desc: "already managed",
+			container: types.Container{
+				State:           "running",
+				NetworkSettings: networks,
+				Labels:          map[string]string{internal.ClusterNameLabel: "other"},
+			},
+		},
+		{
+			desc: "on several networks",
+			container: types.Container{
+				State: "running",
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"a": {}, "b": {}},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := checkAdoptable(test.container)
+			if test.adoptable {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrContainerNotAdoptable))
+		})
+	}
+}
+
+func TestElectPrimary(t *testing.T) {
+	nodes := []adoptedNode{
+		{container: types.Container{ID: "a"}, role: internal.NodeRoleWorker},
+		{container: types.Container{ID: "b"}, role: internal.NodeRoleManager},
+		{container: types.Container{ID: "c"}, role: internal.NodeRoleManager},
+	}
+
+	require.NoError(t, electPrimary(nodes))
+
+	assert.Equal(
+		t,
+		[]adoptedNode{
+			{container: types.Container{ID: "b"}, role: internal.NodeRolePrimary},
+			{container: types.Container{ID: "a"}, role: internal.NodeRoleWorker},
+			{container: types.Container{ID: "c"}, role: internal.NodeRoleManager},
+		},
+		nodes,
+	)
+
+	err := electPrimary([]adoptedNode{{role: internal.NodeRoleWorker}})
+	assert.True(t, errors.Is(err, ErrContainerNotAdoptable))
+}
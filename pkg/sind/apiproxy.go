@@ -0,0 +1,70 @@
+package sind
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"regexp"
+)
+
+// APIFilter decides whether a request to the docker API of a cluster is allowed.
+type APIFilter func(*http.Request) bool
+
+// ReadOnlyAPIFilter only allows requests which do not modify the cluster state.
+func ReadOnlyAPIFilter(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead
+}
+
+// Docker API paths may be prefixed by a version, e.g. /v1.41/containers/prune.
+var destructivePaths = regexp.MustCompile(
+	`^(/v[0-9.]+)?/(` +
+		`[a-z]+/prune|` +
+		`containers/[^/]+/(kill|stop|restart|pause)|` +
+		`swarm/(leave|init|join|update)|` +
+		`nodes/[^/]+/update|` +
+		`system/prune` +
+		`)$`,
+)
+
+// NonDestructiveAPIFilter allows all requests but removals, prunes, and the ones stopping containers or altering the swarm.
+func NonDestructiveAPIFilter(req *http.Request) bool {
+	if req.Method == http.MethodDelete {
+		return false
+	}
+
+	return !destructivePaths.MatchString(req.URL.Path)
+}
+
+// NewAPIProxy returns a handler proxying the requests allowed by filter to the docker API at clusterHost
+// (e.g. tcp://localhost:32768, as returned by ClusterHost). Denied requests get a 403 response.
+func NewAPIProxy(clusterHost string, filter APIFilter) (http.Handler, error) {
+	hostURL, err := url.Parse(clusterHost)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse the cluster host: %w", err)
+	}
+
+	if hostURL.Scheme != "tcp" {
+		return nil, fmt.Errorf("unsupported cluster host scheme %q", hostURL.Scheme)
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: hostURL.Host})
+	// Flush immediately, so streamed responses (logs, events) are forwarded as they come.
+	proxy.FlushInterval = -1
+
+	return http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !filter(req) {
+			rw.Header().Set("Content-Type", "application/json")
+			rw.WriteHeader(http.StatusForbidden)
+
+			_ = json.NewEncoder(rw).Encode(map[string]string{
+				"message": fmt.Sprintf("%s %s is denied by the sind API proxy", req.Method, req.URL.Path),
+			})
+
+			return
+		}
+
+		proxy.ServeHTTP(rw, req)
+	}), nil
+}
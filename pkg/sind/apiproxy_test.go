@@ -0,0 +1,62 @@
+package sind
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIFilters(t *testing.T) {
+	testCases := []struct {
+		method         string
+		path           string
+		readOnly       bool
+		nonDestructive bool
+	}{
+		{method: http.MethodGet, path: "/v1.41/containers/json", readOnly: true, nonDestructive: true},
+		{method: http.MethodHead, path: "/_ping", readOnly: true, nonDestructive: true},
+		{method: http.MethodPost, path: "/v1.41/services/create", nonDestructive: true},
+		{method: http.MethodPost, path: "/containers/abc/start", nonDestructive: true},
+		{method: http.MethodDelete, path: "/v1.41/services/web"},
+		{method: http.MethodPost, path: "/v1.41/containers/prune"},
+		{method: http.MethodPost, path: "/v1.41/containers/abc/kill"},
+		{method: http.MethodPost, path: "/swarm/leave"},
+		{method: http.MethodPost, path: "/v1.41/nodes/abc/update"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.method+" "+test.path, func(t *testing.T) {
+			req := httptest.NewRequest(test.method, test.path, nil)
+
+			assert.Equal(t, test.readOnly, ReadOnlyAPIFilter(req))
+			assert.Equal(t, test.nonDestructive, NonDestructiveAPIFilter(req))
+		})
+	}
+}
+
+func TestNewAPIProxy(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte("OK"))
+	}))
+	defer upstream.Close()
+
+	proxy, err := NewAPIProxy("tcp://"+strings.TrimPrefix(upstream.URL, "http://"), ReadOnlyAPIFilter)
+	require.NoError(t, err)
+
+	rec := httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/_ping", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "OK", rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	proxy.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/services/web", nil))
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.JSONEq(t, `{"message":"DELETE /services/web is denied by the sind API proxy"}`, rec.Body.String())
+
+	_, err = NewAPIProxy("unix:///var/run/docker.sock", ReadOnlyAPIFilter)
+	assert.Error(t, err)
+}
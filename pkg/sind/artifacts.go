@@ -0,0 +1,215 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// CaptureArtifacts dumps what's needed to debug a cluster after the fact to dir:
+//   - logs/<node>.log, the logs of the docker daemon of each node.
+//   - events/<node>.jsonl, the events recently emitted by the docker daemon of each running node.
+//   - nodes.txt, services.txt and tasks.txt, the states of the swarm nodes, services and tasks.
+//
+// The capture is best effort: an artifact failing to be captured does not prevent the others from being captured.
+func CaptureArtifacts(ctx context.Context, hostClient *docker.Client, clusterName, dir string) error {
+	ctx, span := internal.StartSpan(ctx, "sind.capture_artifacts", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := captureArtifacts(ctx, hostClient, clusterName, dir)
+
+	span.End(err)
+
+	return err
+}
+
+func captureArtifacts(ctx context.Context, hostClient *docker.Client, clusterName, dir string) error {
+	status, err := InspectCluster(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to inspect the cluster: %w", err)
+	}
+
+	if status == nil {
+		return ErrClusterNotFound
+	}
+
+	var failures []string
+
+	capture := func(path string, write func(io.Writer) error) {
+		if err := writeArtifact(filepath.Join(dir, path), write); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", path, err))
+		}
+	}
+
+	now := time.Now()
+
+	for _, node := range status.Nodes {
+		cID, name := node.ID, nodeName(clusterName, containerName(node))
+
+		capture(filepath.Join("logs", name+".log"), func(out io.Writer) error {
+			return internal.WriteContainerLogs(ctx, hostClient, cID, out)
+		})
+
+		if node.State != "running" {
+			continue
+		}
+
+		capture(filepath.Join("events", name+".jsonl"), func(out io.Writer) error {
+			events, err := internal.PastNodeEvents(ctx, hostClient, cID, status.CreatedAt, now)
+			if err != nil {
+				return err
+			}
+
+			_, err = io.WriteString(out, events)
+
+			return err
+		})
+	}
+
+	if !status.Plain && status.ManagersRunning > 0 {
+		if err = captureSwarmArtifacts(ctx, hostClient, clusterName, capture); err != nil {
+			failures = append(failures, fmt.Sprintf("swarm: %v", err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("unable to capture artifacts: %s", strings.Join(failures, ", "))
+	}
+
+	return nil
+}
+
+// captureSwarmArtifacts captures the states of the swarm nodes, services and tasks of a cluster.
+func captureSwarmArtifacts(ctx context.Context, hostClient *docker.Client, clusterName string, capture func(string, func(io.Writer) error)) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	capture("nodes.txt", func(out io.Writer) error {
+		nodes, err := swarmClient.NodeList(ctx, types.NodeListOptions{})
+		if err != nil {
+			return fmt.Errorf("unable to list swarm nodes: %w", err)
+		}
+
+		renderSwarmNodes(out, nodes)
+
+		return nil
+	})
+
+	services, err := internal.ListServices(ctx, swarmClient)
+
+	capture("services.txt", func(out io.Writer) error {
+		if err != nil {
+			return err
+		}
+
+		renderServices(out, services)
+
+		return nil
+	})
+
+	capture("tasks.txt", func(out io.Writer) error {
+		if err != nil {
+			return err
+		}
+
+		var tasks []ServiceTask
+
+		for _, service := range services {
+			serviceTasks, err := internal.ServiceTasks(ctx, swarmClient, service.ID)
+			if err != nil {
+				return err
+			}
+
+			tasks = append(tasks, serviceTasks...)
+		}
+
+		renderServiceTasks(out, tasks)
+
+		return nil
+	})
+
+	return nil
+}
+
+// writeArtifact creates the artifact file at path, and its parent directories, with the content written by write.
+func writeArtifact(path string, write func(io.Writer) error) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err = write(file); err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func renderSwarmNodes(out io.Writer, nodes []swarm.Node) {
+	wr := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "ID\tHostname\tRole\tAvailability\tState\tManager status\t\n")
+
+	for _, node := range nodes {
+		managerStatus := "-"
+		if node.ManagerStatus != nil {
+			managerStatus = string(node.ManagerStatus.Reachability)
+
+			if node.ManagerStatus.Leader {
+				managerStatus = "leader"
+			}
+		}
+
+		fmt.Fprintf(
+			wr,
+			"%s\t%s\t%s\t%s\t%s\t%s\t\n",
+			node.ID,
+			node.Description.Hostname,
+			node.Spec.Role,
+			node.Spec.Availability,
+			node.Status.State,
+			managerStatus,
+		)
+	}
+}
+
+func renderServices(out io.Writer, services []Service) {
+	wr := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "ID\tName\tMode\tReplicas\tImage\t\n")
+
+	for _, service := range services {
+		fmt.Fprintf(wr, "%s\t%s\t%s\t%d/%d\t%s\t\n", service.ID, service.Name, service.Mode, service.Running, service.Desired, service.Image)
+	}
+}
+
+func renderServiceTasks(out io.Writer, tasks []ServiceTask) {
+	wr := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	defer wr.Flush()
+
+	fmt.Fprintf(wr, "ID\tService\tSlot\tNode\tDesired state\tState\tError\t\n")
+
+	for _, task := range tasks {
+		fmt.Fprintf(wr, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t\n", task.ID, task.Service, task.Slot, task.Node, task.DesiredState, task.State, task.Err)
+	}
+}
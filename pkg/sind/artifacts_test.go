@@ -0,0 +1,81 @@
+package sind
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteArtifact(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sind-artifacts")
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, "logs", "manager-0.log")
+
+	err = writeArtifact(path, func(out io.Writer) error {
+		_, err := io.WriteString(out, "daemon started\n")
+		return err
+	})
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "daemon started\n", string(content))
+
+	err = writeArtifact(filepath.Join(dir, "nodes.txt"), func(out io.Writer) error {
+		return errors.New("boom")
+	})
+	assert.EqualError(t, err, "boom")
+}
+
+func TestRenderSwarmNodes(t *testing.T) {
+	var out bytes.Buffer
+
+	renderSwarmNodes(&out, []swarm.Node{
+		{
+			ID:            "m0",
+			Description:   swarm.NodeDescription{Hostname: "sind-test-manager-0"},
+			Spec:          swarm.NodeSpec{Role: swarm.NodeRoleManager, Availability: swarm.NodeAvailabilityActive},
+			Status:        swarm.NodeStatus{State: swarm.NodeStateReady},
+			ManagerStatus: &swarm.ManagerStatus{Leader: true, Reachability: swarm.ReachabilityReachable},
+		},
+		{
+			ID:          "w0",
+			Description: swarm.NodeDescription{Hostname: "sind-test-worker-0"},
+			Spec:        swarm.NodeSpec{Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityDrain},
+			Status:      swarm.NodeStatus{State: swarm.NodeStateDown},
+		},
+	})
+
+	assert.Equal(
+		t,
+		"ID  Hostname             Role     Availability  State  Manager status  \n"+
+			"m0  sind-test-manager-0  manager  active        ready  leader          \n"+
+			"w0  sind-test-worker-0   worker   drain         down   -               \n",
+		out.String(),
+	)
+}
+
+func TestRenderServiceTasks(t *testing.T) {
+	var out bytes.Buffer
+
+	renderServiceTasks(&out, []ServiceTask{
+		{ID: "t1", Service: "web", Slot: 1, Node: "sind-test-worker-0", DesiredState: "running", State: "running"},
+		{ID: "t2", Service: "web", Slot: 2, Node: "sind-test-worker-1", DesiredState: "shutdown", State: "rejected", Err: "no such image"},
+	})
+
+	assert.Equal(
+		t,
+		"ID  Service  Slot  Node                Desired state  State     Error          \n"+
+			"t1  web      1     sind-test-worker-0  running        running                  \n"+
+			"t2  web      2     sind-test-worker-1  shutdown       rejected  no such image  \n",
+		out.String(),
+	)
+}
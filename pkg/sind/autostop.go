@@ -0,0 +1,241 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// DefaultAutoStopInterval is the time between two checks of the activity of the clusters, if none is configured.
+const DefaultAutoStopInterval = time.Minute
+
+// IdlePolicy tells when a cluster is considered idle.
+type IdlePolicy string
+
+// Idle policies.
+const (
+	// IdleNoActivity considers a cluster idle when nothing uses its API: no service, node, secret or config changes on
+	// its swarm.
+	IdleNoActivity IdlePolicy = "no-activity"
+	// IdleNoTasks considers a cluster idle when its swarm runs no task, whatever the activity of its API.
+	IdleNoTasks IdlePolicy = "no-tasks"
+)
+
+// AutoStopOptions tunes the stop of the idle clusters.
+type AutoStopOptions struct {
+	// Interval is the time between two checks of the activity of the clusters, DefaultAutoStopInterval if zero.
+	Interval time.Duration
+	// Policy tells when a cluster is idle, IdleNoActivity if empty. Plain clusters, and clusters which nodes joined a
+	// swarm not managed by sind, are idle when no container is created, started or removed on their primary node.
+	Policy IdlePolicy
+	// Namespace restricts the auto stop to the clusters created in the given namespace, if any.
+	Namespace string
+	// Stop tunes the stop of the idle clusters.
+	Stop StopOptions
+}
+
+func (o *AutoStopOptions) validate() error {
+	switch o.Policy {
+	case "", IdleNoActivity, IdleNoTasks:
+	default:
+		return ErrInvalidIdlePolicy
+	}
+
+	if o.Interval < 0 {
+		return ErrInvalidAutoStopInterval
+	}
+
+	return nil
+}
+
+// AutoStopEvent reports a cluster stopped for being idle, or the failure to check or to stop it.
+type AutoStopEvent struct {
+	Cluster string
+	Time    time.Time
+	// IdleFor is the duration the cluster has been idle for.
+	IdleFor time.Duration
+	Err     error
+}
+
+// AutoStop stops the running clusters created with an idle timeout once idle for longer than it, and sends an event to
+// out for each of them. It checks the activity of the clusters every interval, until the context is done.
+// A cluster is first seen active when AutoStop starts, or when it is started again.
+func AutoStop(ctx context.Context, hostClient *docker.Client, opts AutoStopOptions, out chan<- AutoStopEvent) error {
+	if err := opts.validate(); err != nil {
+		return err
+	}
+
+	interval := opts.Interval
+	if interval == 0 {
+		interval = DefaultAutoStopInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	tracker := newIdleTracker()
+
+	for {
+		clusters, err := ListClusters(ctx, hostClient)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("unable to list clusters: %w", err)
+		}
+
+		for _, event := range autoStopClusters(ctx, hostClient, opts, tracker, FilterNamespace(clusters, opts.Namespace)) {
+			select {
+			case <-ctx.Done():
+				return nil
+			case out <- event:
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// autoStopClusters checks the activity of given clusters, stops the ones idle for longer than their idle timeout, and
+// returns the events of the ones stopped or failing.
+func autoStopClusters(ctx context.Context, hostClient *docker.Client, opts AutoStopOptions, tracker *idleTracker, clusters []ClusterStatus) []AutoStopEvent {
+	var events []AutoStopEvent
+
+	for _, cluster := range tracker.watched(clusters) {
+		now := time.Now()
+
+		since, ok := tracker.observe(cluster.Name, now)
+		if !ok {
+			continue
+		}
+
+		active, err := clusterActive(ctx, hostClient, cluster, opts.Policy, since, now)
+		if err != nil {
+			events = append(events, AutoStopEvent{Cluster: cluster.Name, Time: now, Err: err})
+			continue
+		}
+
+		idleFor := tracker.record(cluster.Name, now, active)
+		if idleFor < cluster.IdleTimeout {
+			continue
+		}
+
+		event := AutoStopEvent{Cluster: cluster.Name, Time: now, IdleFor: idleFor}
+
+		ctx, span := internal.StartSpan(ctx, "sind.autostop", map[string]string{internal.ClusterAttribute: cluster.Name})
+		err = StopClusterWithOptions(ctx, hostClient, cluster.Name, opts.Stop)
+		span.End(err)
+
+		if err != nil {
+			event.Err = fmt.Errorf("unable to stop cluster %q: %w", cluster.Name, err)
+		}
+
+		tracker.forget(cluster.Name)
+
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// clusterActive returns true if a cluster has been active between since and until, according to given policy.
+func clusterActive(ctx context.Context, hostClient *docker.Client, cluster ClusterStatus, policy IdlePolicy, since, until time.Time) (bool, error) {
+	client, err := ClusterClient(ctx, hostClient, cluster.Name)
+	if err != nil {
+		return false, err
+	}
+
+	defer client.Close()
+
+	if cluster.Plain || cluster.ExternalSwarm {
+		events, err := internal.Activity(ctx, client, internal.DaemonActivityTypes, since, until)
+		return events > 0, err
+	}
+
+	if policy == IdleNoTasks {
+		tasks, err := internal.RunningTasks(ctx, client)
+		return tasks > 0, err
+	}
+
+	events, err := internal.Activity(ctx, client, internal.SwarmActivityTypes, since, until)
+
+	return events > 0, err
+}
+
+// idleTracker records when the watched clusters have been checked and seen active for the last time.
+type idleTracker struct {
+	clusters map[string]idleState
+}
+
+type idleState struct {
+	checkedAt time.Time
+	activeAt  time.Time
+}
+
+func newIdleTracker() *idleTracker {
+	return &idleTracker{clusters: make(map[string]idleState)}
+}
+
+// watched returns the running clusters having an idle timeout, and forgets the other ones: a cluster started again is
+// seen active.
+func (t *idleTracker) watched(clusters []ClusterStatus) []ClusterStatus {
+	var result []ClusterStatus
+
+	watched := make(map[string]bool)
+
+	for _, cluster := range clusters {
+		primary, ok := primaryNode(cluster.Nodes)
+		if !ok || primary.State != "running" || cluster.IdleTimeout <= 0 {
+			continue
+		}
+
+		watched[cluster.Name] = true
+		result = append(result, cluster)
+	}
+
+	for name := range t.clusters {
+		if !watched[name] {
+			delete(t.clusters, name)
+		}
+	}
+
+	return result
+}
+
+// observe returns the date from which the activity of a cluster is to be checked. It returns false for a cluster seen
+// for the first time, which is then considered active.
+func (t *idleTracker) observe(name string, now time.Time) (time.Time, bool) {
+	state, ok := t.clusters[name]
+	if !ok {
+		t.clusters[name] = idleState{checkedAt: now, activeAt: now}
+		return time.Time{}, false
+	}
+
+	return state.checkedAt, true
+}
+
+// record records the activity of a cluster checked at given date, and returns the duration it has been idle for.
+func (t *idleTracker) record(name string, now time.Time, active bool) time.Duration {
+	state := t.clusters[name]
+	state.checkedAt = now
+
+	if active {
+		state.activeAt = now
+	}
+
+	t.clusters[name] = state
+
+	return now.Sub(state.activeAt)
+}
+
+func (t *idleTracker) forget(name string) {
+	delete(t.clusters, name)
+}
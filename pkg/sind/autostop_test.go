@@ -0,0 +1,96 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoStopOptionsValidation(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		opts          AutoStopOptions
+		expectedError error
+	}{
+		{
+			desc: "with default options",
+		},
+		{
+			desc: "without tasks policy",
+			opts: AutoStopOptions{Policy: IdleNoTasks, Interval: time.Minute},
+		},
+		{
+			desc:          "with an unknown policy",
+			opts:          AutoStopOptions{Policy: "sleepy"},
+			expectedError: ErrInvalidIdlePolicy,
+		},
+		{
+			desc:          "with a negative interval",
+			opts:          AutoStopOptions{Interval: -time.Minute},
+			expectedError: ErrInvalidAutoStopInterval,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.opts.validate()
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+		})
+	}
+}
+
+func TestIdleTrackerWatched(t *testing.T) {
+	node := func(state string) []types.Container {
+		return []types.Container{
+			{State: state, Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary}},
+		}
+	}
+
+	tracker := newIdleTracker()
+	tracker.clusters["stopped"] = idleState{}
+	tracker.clusters["deleted"] = idleState{}
+	tracker.clusters["running"] = idleState{}
+
+	watched := tracker.watched([]ClusterStatus{
+		{Name: "running", IdleTimeout: time.Hour, Nodes: node("running")},
+		{Name: "stopped", IdleTimeout: time.Hour, Nodes: node("exited")},
+		{Name: "no-timeout", Nodes: node("running")},
+	})
+
+	assert.Len(t, watched, 1)
+	assert.Equal(t, "running", watched[0].Name)
+	assert.Equal(t, map[string]idleState{"running": {}}, tracker.clusters)
+}
+
+func TestIdleTracker(t *testing.T) {
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	tracker := newIdleTracker()
+
+	_, ok := tracker.observe("foo", now)
+	assert.False(t, ok)
+
+	since, ok := tracker.observe("foo", now.Add(time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, now, since)
+	assert.Equal(t, time.Minute, tracker.record("foo", now.Add(time.Minute), false))
+
+	since, ok = tracker.observe("foo", now.Add(2*time.Minute))
+	assert.True(t, ok)
+	assert.Equal(t, now.Add(time.Minute), since)
+	assert.Equal(t, time.Duration(0), tracker.record("foo", now.Add(2*time.Minute), true))
+	assert.Equal(t, time.Minute, tracker.record("foo", now.Add(3*time.Minute), false))
+
+	tracker.forget("foo")
+
+	_, ok = tracker.observe("foo", now.Add(4*time.Minute))
+	assert.False(t, ok)
+}
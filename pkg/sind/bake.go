@@ -0,0 +1,89 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// BakeConfiguration is the configuration of a node image build.
+type BakeConfiguration struct {
+	// BaseImage is the dind image the baked image derives from, DefaultNodeImageName if empty.
+	BaseImage string
+	// PullBase pulls the base image even if it is already present on the host.
+	PullBase bool
+	// ImageRef is the reference of the baked image.
+	ImageRef string
+	// Images are the refs, present on the host, loaded in the baked image daemon.
+	Images []string
+}
+
+func (b *BakeConfiguration) validate() error {
+	if b.ImageRef == "" {
+		return ErrEmptyBakeImageRef
+	}
+
+	if len(b.Images) == 0 {
+		return ErrNoImageToBake
+	}
+
+	return nil
+}
+
+func (b *BakeConfiguration) baseImage() string {
+	if b.BaseImage != "" {
+		return b.BaseImage
+	}
+
+	return DefaultNodeImageName
+}
+
+// BakeImage builds a node image with the given images already loaded in its docker daemon, and returns its ID.
+// Images are stored under internal.BakeDataRoot, which is configured in the baked image /etc/docker/daemon.json.
+// As a consequence, the daemon configuration of a cluster created from a baked image must keep this data-root.
+// Being out of the image volume, this data root may force the node daemons to fall back to a slower storage driver.
+func BakeImage(ctx context.Context, hostClient *docker.Client, cfg BakeConfiguration) (string, error) {
+	if err := cfg.validate(); err != nil {
+		return "", err
+	}
+
+	baseImage := cfg.baseImage()
+
+	if err := ensureImage(ctx, hostClient, baseImage, cfg.PullBase, nil); err != nil {
+		return "", err
+	}
+
+	baseInspect, _, err := hostClient.ImageInspectWithRaw(ctx, baseImage)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect the base image %s: %w", baseImage, err)
+	}
+
+	cID, err := internal.StartBakeNode(ctx, hostClient, baseImage)
+	if err != nil {
+		return "", fmt.Errorf("unable to start the bake node: %w", err)
+	}
+
+	defer func() {
+		_ = hostClient.ContainerRemove(ctx, cID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true})
+	}()
+
+	nodes := []types.Container{{ID: cID}}
+
+	if err = internal.WaitNodesReady(ctx, hostClient, nodes); err != nil {
+		return "", fmt.Errorf("unable to wait for the bake node daemon: %w", err)
+	}
+
+	if err = pushImageRefs(ctx, hostClient, nodes, 1, cfg.Images); err != nil {
+		return "", fmt.Errorf("unable to load images in the bake node: %w", err)
+	}
+
+	imageID, err := internal.CommitBakeNode(ctx, hostClient, cID, cfg.ImageRef, baseInspect.Config)
+	if err != nil {
+		return "", err
+	}
+
+	return imageID, nil
+}
@@ -0,0 +1,44 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBakeConfigurationValidationErrors(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        BakeConfiguration
+		expectedError error
+	}{
+		{
+			desc:          "without image reference",
+			config:        BakeConfiguration{Images: []string{"nginx:latest"}},
+			expectedError: ErrEmptyBakeImageRef,
+		},
+		{
+			desc:          "without image to bake",
+			config:        BakeConfiguration{ImageRef: "sind-node:baked"},
+			expectedError: ErrNoImageToBake,
+		},
+		{
+			desc:   "with a valid configuration",
+			config: BakeConfiguration{ImageRef: "sind-node:baked", Images: []string{"nginx:latest"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.config.validate()
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package sind
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// BootstrapNetwork is an overlay network created on the swarm of a cluster.
+type BootstrapNetwork = internal.SwarmNetwork
+
+// Bootstrap declares the swarm objects CreateCluster provisions once the swarm is formed, for a cluster to be ready to
+// deploy stacks relying on external networks, secrets, configs or placement constraints.
+type Bootstrap struct {
+	Networks []BootstrapNetwork
+	// Secrets and Configs map the names of the secrets and the configs to their data.
+	Secrets map[string][]byte
+	Configs map[string][]byte
+	// NodeLabels maps the names of the nodes, e.g. manager-0 or worker-2, to the labels added to their swarm node.
+	NodeLabels map[string]map[string]string
+}
+
+func (b *Bootstrap) empty() bool {
+	return len(b.Networks) == 0 && len(b.Secrets) == 0 && len(b.Configs) == 0 && len(b.NodeLabels) == 0
+}
+
+// validate checks the bootstrap of a cluster with given amount of managers and workers.
+func (b *Bootstrap) validate(managers, workers uint16) error {
+	for _, net := range b.Networks {
+		if net.Name == "" {
+			return fmt.Errorf("%w: a network name is required", ErrInvalidBootstrap)
+		}
+
+		if err := validateSubnet(net.Subnet); err != nil {
+			return fmt.Errorf("%w: network %q: %v", ErrInvalidBootstrap, net.Name, err)
+		}
+	}
+
+	for name := range b.Secrets {
+		if name == "" {
+			return fmt.Errorf("%w: a secret name is required", ErrInvalidBootstrap)
+		}
+	}
+
+	for name := range b.Configs {
+		if name == "" {
+			return fmt.Errorf("%w: a config name is required", ErrInvalidBootstrap)
+		}
+	}
+
+	for name := range b.NodeLabels {
+		if !validNodeName(name, managers, workers) {
+			return fmt.Errorf("%w: no node named %q, expected manager-N or worker-N", ErrInvalidBootstrap, name)
+		}
+	}
+
+	return nil
+}
+
+func validateSubnet(subnet string) error {
+	if subnet == "" {
+		return nil
+	}
+
+	_, _, err := net.ParseCIDR(subnet)
+
+	return err
+}
+
+// validNodeName returns true if a cluster with given amount of managers and workers has a node with given name.
+func validNodeName(name string, managers, workers uint16) bool {
+	for prefix, count := range map[string]uint16{"manager-": managers, "worker-": workers} {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		suffix := strings.TrimPrefix(name, prefix)
+		index, err := strconv.ParseUint(suffix, 10, 16)
+
+		// Node names have no leading zeros, manager-01 is not manager-1.
+		return err == nil && strconv.FormatUint(index, 10) == suffix && index < uint64(count)
+	}
+
+	return false
+}
+
+// swarmBootstrap returns the bootstrap of the swarm of given cluster, its node labels keyed by hostname.
+func (b *Bootstrap) swarmBootstrap(clusterName string) internal.SwarmBootstrap {
+	bootstrap := internal.SwarmBootstrap{
+		Networks:   b.Networks,
+		Secrets:    b.Secrets,
+		Configs:    b.Configs,
+		NodeLabels: make(map[string]map[string]string, len(b.NodeLabels)),
+	}
+
+	for name, labels := range b.NodeLabels {
+		bootstrap.NodeLabels["sind-"+clusterName+"-"+name] = labels
+	}
+
+	return bootstrap
+}
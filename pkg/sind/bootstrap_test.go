@@ -0,0 +1,26 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBootstrapSwarmBootstrap(t *testing.T) {
+	bootstrap := Bootstrap{
+		Networks:   []BootstrapNetwork{{Name: "backend", Attachable: true}},
+		Secrets:    map[string][]byte{"token": []byte("t")},
+		NodeLabels: map[string]map[string]string{"worker-1": {"zone": "a"}},
+	}
+
+	assert.Equal(
+		t,
+		internal.SwarmBootstrap{
+			Networks:   []internal.SwarmNetwork{{Name: "backend", Attachable: true}},
+			Secrets:    map[string][]byte{"token": []byte("t")},
+			NodeLabels: map[string]map[string]string{"sind-test-worker-1": {"zone": "a"}},
+		},
+		bootstrap.swarmBootstrap("test"),
+	)
+}
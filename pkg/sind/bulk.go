@@ -0,0 +1,132 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	docker "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
+	"github.com/golang/sync/errgroup"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// ClusterEndpoint is the docker host to use in order to communicate with a cluster.
+type ClusterEndpoint struct {
+	Name string
+	Host string
+}
+
+// CreateClusters creates count independent clusters from the same configuration, concurrently, and returns their
+// endpoints. Clusters and their networks are named after the configured ones, suffixed with their index, e.g. ci-0.
+// Each cluster gets its own subnet, disjoint from the others.
+// Clusters created before a failure are left on the docker host, the failed ones and the ones interrupted by the failure
+// are removed unless KeepOnFailure is set.
+func CreateClusters(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration, count int) ([]ClusterEndpoint, error) {
+	if err := validateBulk(params, count); err != nil {
+		return nil, err
+	}
+
+	// Clusters are checked and pulled all at once, instead of racing each other.
+	if !params.SkipCapacityCheck {
+		if err := internal.CheckHostCapacity(ctx, hostClient, count*(int(params.Managers)+int(params.Workers)), int64(count)*params.TotalMemory); err != nil {
+			return nil, fmt.Errorf("host capacity check failed, skip it if you know what you are doing: %w", err)
+		}
+	}
+
+	if err := ensureImage(ctx, hostClient, params.imageName(), params.PullImage, nil); err != nil {
+		return nil, fmt.Errorf("unable to get node image: %w", err)
+	}
+
+	subnets, err := internal.PickSubnets(count)
+	if err != nil {
+		return nil, err
+	}
+
+	configs := bulkConfigurations(params, subnets)
+	endpoints := make([]ClusterEndpoint, len(configs))
+
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	for i, config := range configs {
+		i, config := i, config
+
+		errg.Go(func() error {
+			name := NamespacedName(config.Namespace, config.ClusterName)
+
+			if err := CreateCluster(groupCtx, hostClient, config); err != nil {
+				return fmt.Errorf("unable to create cluster %q: %w", name, err)
+			}
+
+			host, err := ClusterHost(groupCtx, hostClient, name)
+			if err != nil {
+				return fmt.Errorf("unable to get the host of cluster %q: %w", name, err)
+			}
+
+			endpoints[i] = ClusterEndpoint{Name: name, Host: host}
+
+			return nil
+		})
+	}
+
+	if err = errg.Wait(); err != nil {
+		return nil, err
+	}
+
+	return endpoints, nil
+}
+
+func validateBulk(params ClusterConfiguration, count int) error {
+	if count < 1 {
+		return ErrInvalidClusterCount
+	}
+
+	if params.NetworkSubnet != "" {
+		return ErrBulkNetworkSubnet
+	}
+
+	if len(params.NodeAddresses) > 0 {
+		return ErrBulkNodeAddresses
+	}
+
+	if params.DaemonHostPort != 0 {
+		return ErrBulkHostPort
+	}
+
+	_, portBindings, err := nat.ParsePortSpecs(params.PortBindings)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidConfiguration, err)
+	}
+
+	for _, bindings := range portBindings {
+		for _, binding := range bindings {
+			if binding.HostPort != "" {
+				return ErrBulkHostPort
+			}
+		}
+	}
+
+	return nil
+}
+
+// bulkConfigurations returns the configurations of the clusters created by CreateClusters, one per given subnet.
+func bulkConfigurations(params ClusterConfiguration, subnets []*net.IPNet) []ClusterConfiguration {
+	configs := make([]ClusterConfiguration, len(subnets))
+
+	for i, subnet := range subnets {
+		config := params
+		config.ClusterName = fmt.Sprintf("%s-%d", params.ClusterName, i)
+		config.PullImage = false
+		config.SkipCapacityCheck = true
+
+		// Clusters attached to an existing network share it, their nodes addresses are picked by its IPAM.
+		if params.ExistingNetwork == "" {
+			config.NetworkName = fmt.Sprintf("%s-%d", params.NetworkName, i)
+			config.NetworkSubnet = subnet.String()
+		}
+
+		configs[i] = config
+	}
+
+	return configs
+}
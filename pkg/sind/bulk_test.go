@@ -0,0 +1,98 @@
+package sind
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateBulk(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		params        ClusterConfiguration
+		count         int
+		expectedError error
+	}{
+		{
+			desc:          "without cluster",
+			count:         0,
+			expectedError: ErrInvalidClusterCount,
+		},
+		{
+			desc:          "with a network subnet",
+			params:        ClusterConfiguration{NetworkSubnet: "10.0.12.0/24"},
+			count:         2,
+			expectedError: ErrBulkNetworkSubnet,
+		},
+		{
+			desc:          "with a fixed host port",
+			params:        ClusterConfiguration{PortBindings: []string{"8080:80"}},
+			count:         2,
+			expectedError: ErrBulkHostPort,
+		},
+		{
+			desc:          "with a fixed daemon host port",
+			params:        ClusterConfiguration{DaemonHostPort: 12375},
+			count:         2,
+			expectedError: ErrBulkHostPort,
+		},
+		{
+			desc:          "with node addresses",
+			params:        ClusterConfiguration{NodeAddresses: []string{"10.0.0.10"}},
+			count:         2,
+			expectedError: ErrBulkNodeAddresses,
+		},
+		{
+			desc:   "with random host ports",
+			params: ClusterConfiguration{PortBindings: []string{"80"}},
+			count:  2,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := validateBulk(test.params, test.count)
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
+
+func TestBulkConfigurations(t *testing.T) {
+	_, first, err := net.ParseCIDR("10.0.12.0/24")
+	require.NoError(t, err)
+
+	_, second, err := net.ParseCIDR("10.0.200.0/24")
+	require.NoError(t, err)
+
+	params := ClusterConfiguration{ClusterName: "ci", NetworkName: "ci-net", Managers: 1, PullImage: true}
+
+	configs := bulkConfigurations(params, []*net.IPNet{first, second})
+
+	require.Len(t, configs, 2)
+
+	assert.Equal(t, "ci-0", configs[0].ClusterName)
+	assert.Equal(t, "ci-net-0", configs[0].NetworkName)
+	assert.Equal(t, "10.0.12.0/24", configs[0].NetworkSubnet)
+	assert.False(t, configs[0].PullImage)
+	assert.True(t, configs[0].SkipCapacityCheck)
+
+	assert.Equal(t, "ci-1", configs[1].ClusterName)
+	assert.Equal(t, "ci-net-1", configs[1].NetworkName)
+	assert.Equal(t, "10.0.200.0/24", configs[1].NetworkSubnet)
+
+	params.ExistingNetwork = "shared"
+
+	configs = bulkConfigurations(params, []*net.IPNet{first, second})
+
+	assert.Equal(t, "ci-net", configs[1].NetworkName)
+	assert.Empty(t, configs[1].NetworkSubnet)
+}
@@ -3,8 +3,14 @@ package sind
 import (
 	"context"
 	"fmt"
+	"io/ioutil"
 	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
 
+	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/sind/internal"
 )
@@ -13,18 +19,209 @@ import (
 func ClusterHost(ctx context.Context, hostClient *docker.Client, clusterName string) (string, error) {
 	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
 	if err != nil {
-		return "", fmt.Errorf("unable to get the primary node informations: %v", err)
+		return "", fmt.Errorf("unable to get the primary node informations: %w", err)
 	}
 
-	swarmPort, err := internal.SwarmPort(*primaryNode)
+	return nodeHost(hostClient, *primaryNode)
+}
+
+// WindowsClusterHost returns the host to use in order to communicate with the swarm cluster from Windows, when sind runs
+// inside a WSL2 distribution. Ports published on localhost inside the WSL2 VM are advertised on the VM address instead,
+// as localhost forwarding to Windows is not always available.
+func WindowsClusterHost(ctx context.Context, hostClient *docker.Client, clusterName string) (string, error) {
+	host, err := ClusterHost(ctx, hostClient, clusterName)
+	if err != nil || !internal.IsWSL2() {
+		return host, err
+	}
+
+	hostURL, err := url.Parse(host)
 	if err != nil {
-		return "", fmt.Errorf("unable to get the remote docker daemon port: %v", err)
+		return "", fmt.Errorf("unable to parse the cluster host: %w", err)
+	}
+
+	if hostURL.Hostname() != "localhost" {
+		return host, nil
+	}
+
+	vmAddress, err := internal.WSLAddress()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the WSL2 VM address: %w", err)
+	}
+
+	hostURL.Host = net.JoinHostPort(vmAddress, hostURL.Port())
+
+	return hostURL.String(), nil
+}
+
+// nodeHost returns the host to use in order to communicate with the docker daemon of a node publishing its port.
+func nodeHost(hostClient *docker.Client, node types.Container) (string, error) {
+	swarmPort, err := internal.SwarmPort(node)
+	if err != nil {
+		// The daemon port is not published on networks without NAT (macvlan, ipvlan) nor on internal networks, the node is
+		// reachable directly.
+		if address, ok := internal.DirectDaemonAddress(node); ok {
+			return "tcp://" + address, nil
+		}
+
+		return "", fmt.Errorf("unable to get the remote docker daemon port: %w", err)
 	}
 
 	swarmHost, err := internal.SwarmHost(hostClient)
 	if err != nil {
-		return "", fmt.Errorf("unable to get the remote docker daemon host: %v", err)
+		return "", fmt.Errorf("unable to get the remote docker daemon host: %w", err)
 	}
 
 	return "tcp://" + net.JoinHostPort(swarmHost, fmt.Sprintf("%d", swarmPort)), nil
 }
+
+// ClusterClient returns a docker client connected to the swarm cluster, over TLS if its daemon port is secured.
+func ClusterClient(ctx context.Context, hostClient *docker.Client, clusterName string) (*docker.Client, error) {
+	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the primary node informations: %w", err)
+	}
+
+	host, err := nodeHost(hostClient, *primaryNode)
+	if err != nil {
+		return nil, err
+	}
+
+	return nodeClient(ctx, hostClient, clusterName, *primaryNode, host)
+}
+
+// ClusterTLSMaterial is the PEM encoded material securing the daemon port of the nodes of a cluster created with TLS.
+type ClusterTLSMaterial = internal.TLSMaterial
+
+// ClusterTLS returns the TLS material of a cluster, ErrTLSDisabled if its daemon port is not secured with TLS.
+func ClusterTLS(ctx context.Context, hostClient *docker.Client, clusterName string) (*ClusterTLSMaterial, error) {
+	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the primary node informations: %w", err)
+	}
+
+	material, err := nodeTLS(ctx, hostClient, *primaryNode)
+	if err != nil {
+		return nil, err
+	}
+
+	if material == nil {
+		return nil, ErrTLSDisabled
+	}
+
+	return material, nil
+}
+
+// WriteClusterCerts writes the CA and the client certificate and key of a cluster to dir, as ca.pem, cert.pem and
+// key.pem, for the docker CLI to use it as DOCKER_CERT_PATH.
+func WriteClusterCerts(ctx context.Context, hostClient *docker.Client, clusterName, dir string) error {
+	material, err := ClusterTLS(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(dir, 0700); err != nil {
+		return fmt.Errorf("unable to create the certificates directory: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{name: "ca.pem", content: material.CACert},
+		{name: "cert.pem", content: material.ClientCert},
+		{name: "key.pem", content: material.ClientKey},
+	}
+
+	for _, file := range files {
+		if err = ioutil.WriteFile(filepath.Join(dir, file.name), []byte(file.content), 0600); err != nil {
+			return fmt.Errorf("unable to write %s: %w", file.name, err)
+		}
+	}
+
+	return nil
+}
+
+// ClusterCertPath returns the directory the certificates of a cluster are written to in sindDir, see DefaultSocketDir and
+// WriteClusterCerts.
+func ClusterCertPath(sindDir, clusterName string) string {
+	return filepath.Join(sindDir, "certs", clusterName)
+}
+
+// nodeClient returns a docker client connected to the daemon of a node at given host, over TLS if its daemon port is
+// secured.
+func nodeClient(ctx context.Context, hostClient *docker.Client, clusterName string, node types.Container, host string) (*docker.Client, error) {
+	material, err := nodeTLS(ctx, hostClient, node)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := []docker.Opt{docker.WithAPIVersionNegotiation()}
+
+	if material != nil {
+		tlsConfig, err := material.ClientConfig(internal.TLSServerName(clusterName))
+		if err != nil {
+			return nil, err
+		}
+
+		// The transport must be set before the host for the client to pick the https scheme.
+		opts = append(
+			opts,
+			docker.WithHTTPClient(&http.Client{
+				Transport:     &http.Transport{TLSClientConfig: tlsConfig},
+				CheckRedirect: docker.CheckRedirect,
+			}),
+		)
+	}
+
+	swarmClient, err := docker.NewClientWithOpts(append(opts, docker.WithHost(host))...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create swarm client: %w", err)
+	}
+
+	return swarmClient, nil
+}
+
+// nodeTLS returns the TLS material of a node, nil if its daemon port is not secured with TLS.
+func nodeTLS(ctx context.Context, hostClient *docker.Client, node types.Container) (*internal.TLSMaterial, error) {
+	if node.Labels[internal.TLSLabel] != "true" {
+		return nil, nil
+	}
+
+	material, err := internal.ReadTLS(ctx, hostClient, node.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clients have no use of the CA key, which stays in the primary node.
+	material.CAKey = ""
+
+	return material, nil
+}
+
+// tlsHosts returns the hosts the server certificate of the nodes of a cluster must be valid for, for the docker CLI to
+// reach the daemon of the primary node through the docker host.
+func tlsHosts(hostClient *docker.Client) ([]string, error) {
+	swarmHost, err := internal.SwarmHost(hostClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the remote docker daemon host: %w", err)
+	}
+
+	if host, _, err := net.SplitHostPort(swarmHost); err == nil {
+		swarmHost = host
+	}
+
+	hosts := []string{"localhost", "127.0.0.1"}
+
+	if swarmHost != "localhost" && swarmHost != "127.0.0.1" && swarmHost != "" {
+		hosts = append(hosts, swarmHost)
+	}
+
+	// The daemon is reached at the WSL2 VM address from Windows, see WindowsClusterHost.
+	if internal.IsWSL2() {
+		if vmAddress, err := internal.WSLAddress(); err == nil {
+			hosts = append(hosts, vmAddress)
+		}
+	}
+
+	return hosts, nil
+}
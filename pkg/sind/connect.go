@@ -0,0 +1,100 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// ConnectConfiguration represents the configuration of a network shared by two clusters.
+type ConnectConfiguration struct {
+	// NetworkName is the name of the shared network, sind-<clusterA>-<clusterB> if empty.
+	NetworkName string
+	// Nodes are the names of the nodes to attach (e.g. manager-0), all the nodes of both clusters if empty.
+	Nodes []string
+}
+
+// ConnectClusters creates a network shared by two clusters, attaches their selected nodes to it, and returns its name.
+// The shared network is not labeled as a resource of any of the clusters, DisconnectClusters removes it.
+func ConnectClusters(ctx context.Context, hostClient *docker.Client, clusterA, clusterB string, cfg ConnectConfiguration) (string, error) {
+	networkName := cfg.NetworkName
+	if networkName == "" {
+		networkName = fmt.Sprintf("sind-%s-%s", clusterA, clusterB)
+	}
+
+	var nodes []types.Container
+
+	for _, clusterName := range []string{clusterA, clusterB} {
+		clusterNodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+		if err != nil {
+			return "", fmt.Errorf("unable to list nodes of cluster %q: %w", clusterName, err)
+		}
+
+		if len(clusterNodes) == 0 {
+			return "", fmt.Errorf("%w for cluster %q", ErrClusterNotFound, clusterName)
+		}
+
+		nodes = append(nodes, selectNodes(clusterName, clusterNodes, cfg.Nodes)...)
+	}
+
+	labels := creationLabels(time.Now())
+	labels[internal.ConnectedClustersLabel] = clusterA + "," + clusterB
+
+	sharedNet, err := hostClient.NetworkCreate(ctx, networkName, types.NetworkCreate{
+		Driver: "bridge",
+		Labels: labels,
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to create the shared network: %w", err)
+	}
+
+	if err = internal.ConnectContainers(ctx, hostClient, sharedNet.ID, nodes); err != nil {
+		return "", fmt.Errorf("unable to connect nodes to the shared network: %w", err)
+	}
+
+	return networkName, nil
+}
+
+// DisconnectClusters detaches all the nodes connected to a network shared by two clusters, then removes it.
+func DisconnectClusters(ctx context.Context, hostClient *docker.Client, networkName string) error {
+	sharedNet, err := hostClient.NetworkInspect(ctx, networkName, types.NetworkInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to inspect the shared network: %w", err)
+	}
+
+	if _, ok := sharedNet.Labels[internal.ConnectedClustersLabel]; !ok {
+		return fmt.Errorf("network %q is not shared by sind clusters", networkName)
+	}
+
+	for cID := range sharedNet.Containers {
+		if err = hostClient.NetworkDisconnect(ctx, sharedNet.ID, cID, true); err != nil {
+			return fmt.Errorf("unable to disconnect container %q: %w", cID, err)
+		}
+	}
+
+	if err = hostClient.NetworkRemove(ctx, sharedNet.ID); err != nil {
+		return fmt.Errorf("unable to remove the shared network: %w", err)
+	}
+
+	return nil
+}
+
+func selectNodes(clusterName string, nodes []types.Container, names []string) []types.Container {
+	if len(names) == 0 {
+		return nodes
+	}
+
+	var selected []types.Container
+
+	for _, node := range nodes {
+		if len(node.Names) > 0 && matchAny(names, nodeName(clusterName, node.Names[0])) {
+			selected = append(selected, node)
+		}
+	}
+
+	return selected
+}
@@ -0,0 +1,20 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectNodes(t *testing.T) {
+	nodes := []types.Container{
+		{ID: "a", Names: []string{"/sind-test-manager-0"}},
+		{ID: "b", Names: []string{"/sind-test-worker-0"}},
+		{ID: "c", Names: []string{"/sind-test-worker-1"}},
+	}
+
+	assert.Equal(t, nodes, selectNodes("test", nodes, nil))
+	assert.Equal(t, []types.Container{nodes[0], nodes[2]}, selectNodes("test", nodes, []string{"manager-0", "worker-1"}))
+	assert.Empty(t, selectNodes("test", nodes, []string{"worker-2"}))
+}
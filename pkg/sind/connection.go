@@ -0,0 +1,105 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// ClusterConnection holds what a client needs to use a cluster, to hand it over to another process.
+type ClusterConnection struct {
+	ClusterName string `json:"cluster_name"`
+	// DockerHost is the host of the docker API of the cluster, to use as DOCKER_HOST.
+	DockerHost string `json:"docker_host"`
+	// TLS is the material to connect to the node daemons with, nil if they don't use TLS.
+	TLS *ClusterTLSMaterial `json:"tls,omitempty"`
+	// Nodes are the docker daemon endpoints of the nodes reachable from the docker host, see NodeEndpoints.
+	Nodes []NodeEndpoint `json:"nodes"`
+
+	// ManagerJoinToken and WorkerJoinToken are the join tokens of the swarm, empty for plain clusters and clusters
+	// which joined an external swarm.
+	ManagerJoinToken string `json:"manager_join_token,omitempty"`
+	WorkerJoinToken  string `json:"worker_join_token,omitempty"`
+}
+
+// Env returns the connection as KEY=value environment variables.
+func (c *ClusterConnection) Env() []string {
+	env := []string{
+		"SIND_CLUSTER_NAME=" + c.ClusterName,
+		"DOCKER_HOST=" + c.DockerHost,
+	}
+
+	if c.ManagerJoinToken != "" {
+		env = append(env, "SIND_MANAGER_JOIN_TOKEN="+c.ManagerJoinToken, "SIND_WORKER_JOIN_TOKEN="+c.WorkerJoinToken)
+	}
+
+	return env
+}
+
+// ConnectionDetails returns what a client needs to use a cluster.
+func ConnectionDetails(ctx context.Context, hostClient *docker.Client, clusterName string) (*ClusterConnection, error) {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, ErrClusterNotFound
+	}
+
+	endpoints, err := nodeEndpoints(hostClient, clusterName, nodes)
+	if err != nil {
+		return nil, err
+	}
+
+	connection := ClusterConnection{ClusterName: clusterName, Nodes: endpoints}
+
+	for _, endpoint := range endpoints {
+		if endpoint.Role == internal.NodeRolePrimary {
+			connection.DockerHost = endpoint.Host
+		}
+	}
+
+	var (
+		primaryNode types.Container
+		ownSwarm    bool
+	)
+
+	for _, node := range nodes {
+		if node.Labels[internal.NodeRoleLabel] != internal.NodeRolePrimary {
+			continue
+		}
+
+		_, external := node.Labels[internal.ExternalSwarmLabel]
+		ownSwarm = node.Labels[internal.PlainClusterLabel] != "true" && !external
+		primaryNode = node
+	}
+
+	if connection.TLS, err = nodeTLS(ctx, hostClient, primaryNode); err != nil {
+		return nil, err
+	}
+
+	if !ownSwarm {
+		return &connection, nil
+	}
+
+	swarmClient, err := nodeClient(ctx, hostClient, clusterName, primaryNode, connection.DockerHost)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	swarmInfo, err := swarmClient.SwarmInspect(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the swarm join tokens: %w", err)
+	}
+
+	connection.ManagerJoinToken = swarmInfo.JoinTokens.Manager
+	connection.WorkerJoinToken = swarmInfo.JoinTokens.Worker
+
+	return &connection, nil
+}
@@ -0,0 +1,27 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterConnectionEnv(t *testing.T) {
+	connection := ClusterConnection{ClusterName: "test", DockerHost: "tcp://localhost:32768"}
+
+	assert.Equal(t, []string{"SIND_CLUSTER_NAME=test", "DOCKER_HOST=tcp://localhost:32768"}, connection.Env())
+
+	connection.ManagerJoinToken = "SWMTKN-1-manager"
+	connection.WorkerJoinToken = "SWMTKN-1-worker"
+
+	assert.Equal(
+		t,
+		[]string{
+			"SIND_CLUSTER_NAME=test",
+			"DOCKER_HOST=tcp://localhost:32768",
+			"SIND_MANAGER_JOIN_TOKEN=SWMTKN-1-manager",
+			"SIND_WORKER_JOIN_TOKEN=SWMTKN-1-worker",
+		},
+		connection.Env(),
+	)
+}
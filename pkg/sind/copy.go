@@ -0,0 +1,124 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// CopyConfiguration represents the copy of a local file or directory to the nodes of a cluster.
+type CopyConfiguration struct {
+	// SourcePath is the path of the local file or directory to copy.
+	SourcePath string
+	// TargetPath is the absolute path of the copy in the nodes, its missing parent directories are created.
+	TargetPath string
+	// Nodes are the names of the nodes to copy to (e.g. manager-0), all the nodes of the cluster if empty.
+	Nodes []string
+	// Jobs is the amount of nodes copied to in parallel, all of them if 0.
+	Jobs int
+}
+
+func (c *CopyConfiguration) validate() error {
+	if c.SourcePath == "" {
+		return ErrEmptyCopySource
+	}
+
+	if !path.IsAbs(c.TargetPath) || path.Clean(c.TargetPath) == "/" {
+		return fmt.Errorf("%w: %q", ErrInvalidCopyTarget, c.TargetPath)
+	}
+
+	return nil
+}
+
+// CopyToNodes copies a local file or directory at a path inside the nodes of a cluster, e.g. to distribute
+// certificates, configuration files or test fixtures. An existing file at the target path is replaced.
+func CopyToNodes(ctx context.Context, hostClient *docker.Client, clusterName string, params CopyConfiguration) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	ctx, span := internal.StartSpan(ctx, "sind.copy", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := copyToNodes(ctx, hostClient, clusterName, params)
+
+	span.End(err)
+
+	return err
+}
+
+func copyToNodes(ctx context.Context, hostClient *docker.Client, clusterName string, params CopyConfiguration) error {
+	nodes, err := listSelectedNodes(ctx, hostClient, clusterName, params.Nodes)
+	if err != nil {
+		return err
+	}
+
+	archiveFile, err := ioutil.TempFile(os.TempDir(), "sind_archive")
+	if err != nil {
+		return fmt.Errorf("unable to create a temporary archive file: %w", err)
+	}
+
+	defer os.Remove(archiveFile.Name())
+	defer archiveFile.Close()
+
+	targetPath := path.Clean(params.TargetPath)
+	targetDir, targetName := path.Split(targetPath)
+
+	err = tracePhase(ctx, "sind.copy.archive", nil, func(context.Context) error {
+		return internal.TarPath(params.SourcePath, targetName, archiveFile)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to tar %q: %w", params.SourcePath, err)
+	}
+
+	// The archive is extracted in the parent directory of the target path, which must exist.
+	err = tracePhase(ctx, "sind.copy.prepare", nil, func(ctx context.Context) error {
+		return internal.ExecContainers(ctx, hostClient, nodes, params.Jobs, []string{"mkdir", "-p", targetDir})
+	})
+	if err != nil {
+		return fmt.Errorf("unable to create the target directory on nodes: %w", err)
+	}
+
+	err = tracePhase(ctx, "sind.copy.copy", nil, func(ctx context.Context) error {
+		return internal.CopyToContainers(ctx, hostClient, nodes, params.Jobs, archiveFile.Name(), targetDir)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to copy content to nodes: %w", err)
+	}
+
+	return nil
+}
+
+// CopyFromNode copies a file or directory at a path inside a node of a cluster to a local path, e.g. to collect test
+// artifacts or the state of the node docker daemon. The node is designated by its name, e.g. manager-0, the primary
+// node if empty.
+func CopyFromNode(ctx context.Context, hostClient *docker.Client, clusterName, node, sourcePath, targetPath string) error {
+	if sourcePath == "" {
+		return ErrEmptyCopySource
+	}
+
+	if targetPath == "" {
+		return ErrEmptyCopyTarget
+	}
+
+	container, err := clusterNode(ctx, hostClient, clusterName, node)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := internal.StartSpan(
+		ctx,
+		"sind.copy",
+		map[string]string{internal.ClusterAttribute: clusterName, internal.NodeAttribute: node},
+	)
+
+	err = internal.CopyFromContainer(ctx, hostClient, container.ID, sourcePath, targetPath)
+
+	span.End(err)
+
+	return err
+}
@@ -0,0 +1,49 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCopyConfigurationValidationErrors(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        CopyConfiguration
+		expectedError error
+	}{
+		{
+			desc:          "without source path",
+			config:        CopyConfiguration{TargetPath: "/etc/certs"},
+			expectedError: ErrEmptyCopySource,
+		},
+		{
+			desc:          "with a relative target path",
+			config:        CopyConfiguration{SourcePath: "certs", TargetPath: "etc/certs"},
+			expectedError: ErrInvalidCopyTarget,
+		},
+		{
+			desc:          "with the root directory as target path",
+			config:        CopyConfiguration{SourcePath: "certs", TargetPath: "/etc/.."},
+			expectedError: ErrInvalidCopyTarget,
+		},
+		{
+			desc:   "with a valid configuration",
+			config: CopyConfiguration{SourcePath: "certs", TargetPath: "/etc/certs/"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.config.validate()
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
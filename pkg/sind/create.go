@@ -2,12 +2,18 @@ package sind
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/swarm"
 	docker "github.com/docker/docker/client"
+	"github.com/docker/go-connections/nat"
 	"github.com/jlevesy/sind/pkg/sind/internal"
 )
 
@@ -16,6 +22,22 @@ const (
 	DefaultNodeImageName = "docker:20.10-dind"
 )
 
+// PublishPortsOn selects the nodes the port bindings of a cluster are applied to.
+type PublishPortsOn string
+
+// Nodes the port bindings of a cluster can be applied to.
+const (
+	PublishPortsOnPrimary  PublishPortsOn = "primary"
+	PublishPortsOnManagers PublishPortsOn = "managers"
+	PublishPortsOnAll      PublishPortsOn = "all"
+)
+
+// RegistryAuth are the credentials of a private registry.
+type RegistryAuth struct {
+	Username string
+	Password string
+}
+
 // ClusterConfiguration represents the configuration for a new cluster.
 type ClusterConfiguration struct {
 	ClusterName string
@@ -28,24 +50,475 @@ type ClusterConfiguration struct {
 	PullImage    bool
 	PortBindings []string
 	DaemonArgs   []string
+
+	// DaemonHostPort is the port of the docker host the docker daemon of the primary node is published on, for the
+	// cluster endpoint to be stable. A random port is picked if 0.
+	DaemonHostPort uint16
+
+	// Runtime is the container runtime the nodes run with, e.g. runsc or kata-runtime for hosts requiring privileged
+	// workloads to be sandboxed. It must be configured on the docker host, the host default runtime is used if empty.
+	Runtime string
+
+	// Devices are the host devices mapped into all nodes, as host-path[:container-path][:permissions] like docker run
+	// --device, e.g. /dev/fuse or /dev/kvm for workloads relying on them. Privileged nodes can already access the devices
+	// of the host present at their creation, mappings make them appear at the given container path.
+	Devices []string
+
+	// Experimental enables the experimental features of the node daemons.
+	Experimental bool
+	// BuildKit makes the docker CLI of the nodes build images with BuildKit.
+	BuildKit bool
+	// ContainerdImageStore makes the node daemons store images with the containerd snapshotters.
+	// It requires a node image running docker 24 or later.
+	ContainerdImageStore bool
+
+	// RegistryAuths are the credentials of private registries keyed by registry address, e.g. registry.local:5000 or
+	// https://index.docker.io/v1/ for the Docker Hub. They are installed in the docker client configuration of every node,
+	// as docker login would. The configuration is copied to the node containers rather than passed through their
+	// environment, it remains readable by anyone able to exec in the nodes.
+	RegistryAuths map[string]RegistryAuth
+	// ContentTrust enables Docker Content Trust for the docker CLI of the nodes, images being verified against
+	// ContentTrustServer, the Docker Hub notary server if empty.
+	ContentTrust       bool
+	ContentTrustServer string
+
+	// AirGapped creates the cluster network as an internal network, without access to external networks, for stacks to
+	// be tested without egress. The absence of default route in every node is checked once they are created. The nodes
+	// can't publish ports, sind reaches their daemons at their address on the network, which the docker host must route.
+	// Images must then be pushed to the cluster, see PushImageRefs.
+	AirGapped bool
+
+	// RestrictEgress restricts the outbound traffic of the nodes and of their containers with iptables rules: only the
+	// cluster network and the addresses or CIDRs of EgressAllowlist, e.g. the one of a local registry, can be reached.
+	// Unlike AirGapped, ports can still be published. The DNS servers must be allowed for external names to resolve.
+	RestrictEgress  bool
+	EgressAllowlist []string
+
+	// ExistingNetwork is the ID or name of an existing network to attach the nodes to, instead of creating one.
+	// Nodes addresses are then picked by the network IPAM, and the network is left untouched on cluster deletion.
+	ExistingNetwork string
+
+	// NetworkDriver is the driver of the cluster network (bridge, macvlan, ipvlan...), bridge if empty.
+	NetworkDriver        string
+	NetworkDriverOptions map[string]string
+	// NetworkSubnet is the subnet of the cluster network, a random 10.0.X.0/24 subnet is picked if empty.
+	NetworkSubnet string
+	// NetworkGateway, NetworkIPRange and NetworkAuxAddresses refine the IPAM configuration of NetworkSubnet, which is
+	// then required. When any of them is set, node addresses are picked by the network IPAM instead of being static.
+	NetworkGateway      string
+	NetworkIPRange      string
+	NetworkAuxAddresses map[string]string
+	// NodeAddresses are the fixed addresses of the nodes on the cluster network: primary first, then the managers and the
+	// workers in index order. They require NetworkSubnet, or an ExistingNetwork with a user defined subnet.
+	// Without them, nodes of a network created with NetworkSubnet get consecutive addresses following the gateway.
+	NodeAddresses []string
+	// RoleAddressRanges partitions the subnet of the cluster network: the managers, primary included, are addressed
+	// from the first half of the subnet following the gateway, and the workers from the start of its second half.
+	// For instance, managers of 10.0.0.0/24 get addresses from 10.0.0.2 and workers from 10.0.0.128.
+	// It requires a network created by sind without custom IPAM, and can't be used with NodeAddresses.
+	RoleAddressRanges bool
+
+	// TLS secures the daemon port of the nodes with mutual TLS, using certificates generated for the cluster, see
+	// ClusterTLS. Clients returned by ClusterClient connect over TLS.
+	// The material is copied to /etc/sind/tls in the node containers, not passed through their environment: the keys
+	// are not listed by docker inspect, but remain readable by anyone able to exec in the nodes or copy from them. The
+	// CA key is kept in the primary node, to issue the certificates of the nodes added by ScaleCluster or ExtendCluster.
+	TLS bool
+
+	// Plain creates the nodes without forming a swarm, for raw disposable docker daemons.
+	// The daemon of every node is then published on the docker host, see NodeEndpoints.
+	Plain bool
+
+	// PublishPortsOn selects the nodes PortBindings are applied to: the primary node only if empty. The primary node
+	// binds the requested host ports, the other nodes bind the same container ports on host ports picked by the daemon,
+	// listed by PublishedPorts, for the swarm ingress to stay reachable when the primary node is stopped.
+	PublishPortsOn PublishPortsOn
+
+	// LoadBalancer binds PortBindings on a load balancer container round-robining across the ingress of all nodes,
+	// instead of binding them on the primary node. It is reloaded when the cluster is scaled, healed or started again.
+	// The nodes ExtendCluster adds on other docker hosts are out of its reach, the swarm routing mesh of the nodes it
+	// balances across serves the services running on them.
+	LoadBalancer bool
+
+	// TotalMemory (bytes) and TotalCPU (CPUs) are budgets divided across all nodes, managers getting a bigger share.
+	// Zero means no limit.
+	TotalMemory int64
+	TotalCPU    float64
+
+	// PidsLimit is the maximum amount of processes of each node, nodes running many tasks hitting the default limit of
+	// the docker host. -1 means unlimited, and zero the docker host default.
+	PidsLimit int64
+	// MemorySwap is the limit of memory and swap of each node, like docker run --memory-swap: it requires TotalMemory and
+	// must be greater than the memory share of every node. -1 means unlimited swap, and zero the docker host default.
+	MemorySwap int64
+	// BlkioWeight is the relative block IO weight of the nodes, between 10 and 1000, the docker host default if zero.
+	BlkioWeight uint16
+
+	// TTL is the time to live of the cluster, after which it is deleted by DeleteExpiredClusters.
+	// Zero means that the cluster never expires.
+	TTL time.Duration
+	// IdleTimeout is the duration without activity after which the cluster is stopped by AutoStop.
+	// Zero means that the cluster is never stopped for being idle.
+	IdleTimeout time.Duration
+
+	// Metadata is arbitrary user defined key/value metadata, applied as labels on the cluster resources.
+	Metadata map[string]string
+
+	// Namespace isolates the cluster from the clusters of other namespaces. The cluster and network names are prefixed
+	// with it, see NamespacedName, and the cluster resources are labeled with it.
+	Namespace string
+
+	// AdoptExisting makes CreateCluster succeed without creating anything when a healthy cluster with the same name and
+	// compatible parameters (nodes, image, network) is already running. ErrClusterNotAdoptable is returned otherwise.
+	AdoptExisting bool
+	// Recreate makes CreateCluster force the deletion of any existing cluster with the same name before creating it.
+	Recreate bool
+
+	// Provision makes CreateCluster only create the network and the stopped node containers of the cluster. A later
+	// CreateCluster of the same cluster claims them: it starts them and forms the swarm, skipping the nodes creation.
+	Provision bool
+
+	// SkipCapacityCheck disables the check of the docker host memory and disk before creating the nodes.
+	SkipCapacityCheck bool
+
+	// ManagerAvailability and WorkerAvailability are the swarm availabilities (active, drain or pause) the managers,
+	// primary included, and the workers join the swarm with. Nodes are active if empty. Joining drained lets the caller
+	// decide when tasks start being scheduled on them.
+	ManagerAvailability string
+	WorkerAvailability  string
+
+	// Bootstrap declares the networks, secrets, configs and node labels created on the swarm once it is formed.
+	Bootstrap Bootstrap
+
+	// PreCreateHooks are run in order before the nodes of the cluster are created, or claimed. PostCreateHooks are run in
+	// order once the cluster is ready, unless only provisioned. A failing hook fails the creation.
+	PreCreateHooks  []Hook
+	PostCreateHooks []Hook
+
+	// KeepOnFailure keeps the network and the containers created by a failed creation on the docker host, e.g. to debug
+	// it, instead of removing them.
+	KeepOnFailure bool
+
+	// OnEvent is called with the progress events of the creation, one at a time, e.g. to render its progress. It must
+	// not block, as the creation waits for it.
+	OnEvent func(CreateEvent)
+
+	// clusterTLS is the material of the cluster the nodes extend, their certificates being issued by its CA instead of
+	// a new one.
+	clusterTLS *internal.TLSMaterial
 }
 
 func (n *ClusterConfiguration) validate() error {
 	if n.ClusterName == "" {
-		return errors.New("cluster name is required")
+		return ErrEmptyClusterName
 	}
 
-	if n.NetworkName == "" {
-		return errors.New("network name is required")
+	if n.NetworkName == "" && n.ExistingNetwork == "" {
+		return ErrEmptyNetworkName
 	}
 
 	if n.Managers < 1 {
-		return errors.New("invalid manager count, must be >= 1")
+		return ErrInvalidManagerCount
+	}
+
+	if n.AdoptExisting && n.Recreate {
+		return ErrAdoptAndRecreate
+	}
+
+	if n.Plain && n.LoadBalancer {
+		return ErrPlainLoadBalancer
+	}
+
+	switch n.PublishPortsOn {
+	case "", PublishPortsOnPrimary:
+	case PublishPortsOnManagers, PublishPortsOnAll:
+		if n.LoadBalancer {
+			return ErrLoadBalancerPublishPorts
+		}
+	default:
+		return ErrInvalidPublishPortsOn
+	}
+
+	if n.TotalMemory < 0 {
+		return ErrInvalidTotalMemory
+	}
+
+	if _, _, err := nat.ParsePortSpecs(n.PortBindings); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidPortBindings, err)
+	}
+
+	if n.AirGapped {
+		if n.ExistingNetwork != "" {
+			return ErrAirGappedExistingNetwork
+		}
+
+		if len(n.PortBindings) > 0 || n.LoadBalancer || n.DaemonHostPort != 0 {
+			return ErrAirGappedPorts
+		}
+	}
+
+	if err := n.validateEgress(); err != nil {
+		return err
+	}
+
+	if n.NetworkSubnet != "" {
+		if _, _, err := net.ParseCIDR(n.NetworkSubnet); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidNetworkSubnet, err)
+		}
+	}
+
+	if err := n.validateIPAM(); err != nil {
+		return err
+	}
+
+	if err := n.validateAddresses(); err != nil {
+		return err
+	}
+
+	if n.TTL < 0 {
+		return ErrInvalidTTL
+	}
+
+	if n.IdleTimeout < 0 {
+		return ErrInvalidIdleTimeout
+	}
+
+	if !n.Bootstrap.empty() && n.Plain {
+		return fmt.Errorf("%w: plain clusters have no swarm to bootstrap", ErrInvalidBootstrap)
+	}
+
+	if err := n.Bootstrap.validate(n.Managers, n.Workers); err != nil {
+		return err
+	}
+
+	if n.TotalCPU < 0 {
+		return ErrInvalidTotalCPU
+	}
+
+	if err := n.validateResources(); err != nil {
+		return err
+	}
+
+	if _, err := parseDevices(n.Devices); err != nil {
+		return err
+	}
+
+	if !validAvailability(n.ManagerAvailability) || !validAvailability(n.WorkerAvailability) {
+		return ErrInvalidNodeAvailability
+	}
+
+	for registry, auth := range n.RegistryAuths {
+		if registry == "" || auth.Username == "" {
+			return fmt.Errorf("%w: %q", ErrInvalidRegistryAuth, registry)
+		}
+	}
+
+	if n.ContentTrustServer != "" && !n.ContentTrust {
+		return ErrContentTrustServer
+	}
+
+	return nil
+}
+
+func (n *ClusterConfiguration) validateEgress() error {
+	if len(n.EgressAllowlist) > 0 && !n.RestrictEgress {
+		return fmt.Errorf("%w: an allowlist requires restricting the egress", ErrInvalidEgressAllowlist)
+	}
+
+	// The rules only apply to IPv4 traffic.
+	for _, destination := range n.EgressAllowlist {
+		ip := net.ParseIP(destination)
+		if ip == nil {
+			ip, _, _ = net.ParseCIDR(destination)
+		}
+
+		if ip == nil || ip.To4() == nil {
+			return fmt.Errorf("%w: %q is neither an IPv4 address nor an IPv4 CIDR", ErrInvalidEgressAllowlist, destination)
+		}
+	}
+
+	return nil
+}
+
+// validAvailability returns true if availability is empty, or a valid swarm node availability.
+func validAvailability(availability string) bool {
+	switch swarm.NodeAvailability(availability) {
+	case "", swarm.NodeAvailabilityActive, swarm.NodeAvailabilityDrain, swarm.NodeAvailabilityPause:
+		return true
+	default:
+		return false
+	}
+}
+
+func (n *ClusterConfiguration) validateResources() error {
+	if n.PidsLimit < -1 {
+		return ErrInvalidPidsLimit
+	}
+
+	if n.BlkioWeight != 0 && (n.BlkioWeight < 10 || n.BlkioWeight > 1000) {
+		return ErrInvalidBlkioWeight
+	}
+
+	// A zero share would leave the nodes unlimited, workers get the smallest one.
+	_, workerResources := internal.SplitResources(n.TotalMemory, int64(n.TotalCPU*1e9), n.Managers, n.Workers)
+	if (n.TotalMemory > 0 && workerResources.Memory == 0) || (n.TotalCPU > 0 && workerResources.NanoCPUs == 0) {
+		return ErrBudgetTooSmall
+	}
+
+	if n.MemorySwap == 0 || n.MemorySwap == -1 {
+		return nil
+	}
+
+	if n.MemorySwap < -1 || n.TotalMemory == 0 {
+		return fmt.Errorf("%w: it requires a total memory", ErrInvalidMemorySwap)
+	}
+
+	// Managers get the biggest memory share.
+	managerResources, _ := internal.SplitResources(n.TotalMemory, 0, n.Managers, n.Workers)
+	if n.MemorySwap < managerResources.Memory {
+		return fmt.Errorf("%w: it is lower than the %d bytes of memory of the managers", ErrInvalidMemorySwap, managerResources.Memory)
+	}
+
+	return nil
+}
+
+func (n *ClusterConfiguration) validateAddresses() error {
+	var subnet *net.IPNet
+
+	if n.NetworkSubnet != "" {
+		_, subnet, _ = net.ParseCIDR(n.NetworkSubnet)
+	}
+
+	nodes := int(n.Managers) + int(n.Workers)
+
+	if n.RoleAddressRanges {
+		return n.validateRoleRanges(subnet)
+	}
+
+	if len(n.NodeAddresses) == 0 {
+		if subnet != nil && !n.customIPAM() && !subnet.Contains(internal.NodeAddress(*subnet, uint16(nodes-1))) {
+			return fmt.Errorf("%w: %s is too small for %d nodes", ErrInvalidNetworkSubnet, subnet, nodes)
+		}
+
+		return nil
+	}
+
+	if subnet == nil && n.ExistingNetwork == "" {
+		return fmt.Errorf("%w: a network subnet or an existing network is required", ErrInvalidNodeAddresses)
+	}
+
+	if len(n.NodeAddresses) != nodes {
+		return fmt.Errorf("%w: %d addresses given for %d nodes", ErrInvalidNodeAddresses, len(n.NodeAddresses), nodes)
+	}
+
+	seen := make(map[string]bool, nodes)
+
+	for _, address := range n.NodeAddresses {
+		ip := net.ParseIP(address).To4()
+
+		switch {
+		case ip == nil:
+			return fmt.Errorf("%w: %q is not an IPv4 address", ErrInvalidNodeAddresses, address)
+		case subnet != nil && !subnet.Contains(ip):
+			return fmt.Errorf("%w: %q is not an address of %s", ErrInvalidNodeAddresses, address, subnet)
+		case seen[ip.String()]:
+			return fmt.Errorf("%w: %q is given more than once", ErrInvalidNodeAddresses, address)
+		}
+
+		seen[ip.String()] = true
+	}
+
+	return nil
+}
+
+func (n *ClusterConfiguration) validateRoleRanges(subnet *net.IPNet) error {
+	if len(n.NodeAddresses) > 0 || n.customIPAM() || n.ExistingNetwork != "" {
+		return ErrInvalidRoleRanges
+	}
+
+	// Random subnets are /24 subnets.
+	ones, bits := 24, 32
+	if subnet != nil {
+		ones, bits = subnet.Mask.Size()
+	}
+
+	var half int
+	if ones < bits {
+		half = 1 << uint(bits-ones-1)
+	}
+
+	// The first half starts with the subnet address and the gateway, the second one ends with the broadcast address.
+	if int(n.Managers)+2 > half || int(n.Workers)+1 > half {
+		return fmt.Errorf(
+			"%w: the halves of a /%d subnet are too small for %d managers and %d workers",
+			ErrInvalidNetworkSubnet,
+			ones,
+			n.Managers,
+			n.Workers,
+		)
+	}
+
+	return nil
+}
+
+func (n *ClusterConfiguration) validateIPAM() error {
+	if !n.customIPAM() {
+		return nil
+	}
+
+	if n.NetworkSubnet == "" {
+		return fmt.Errorf("%w: a network subnet is required", ErrInvalidNetworkIPAM)
+	}
+
+	_, subnet, _ := net.ParseCIDR(n.NetworkSubnet)
+
+	if n.NetworkGateway != "" {
+		if ip := net.ParseIP(n.NetworkGateway); ip == nil || !subnet.Contains(ip) {
+			return fmt.Errorf("%w: gateway %q is not an address of %s", ErrInvalidNetworkIPAM, n.NetworkGateway, subnet)
+		}
+	}
+
+	if n.NetworkIPRange != "" {
+		ip, _, err := net.ParseCIDR(n.NetworkIPRange)
+		if err != nil || !subnet.Contains(ip) {
+			return fmt.Errorf("%w: IP range %q is not a range of %s", ErrInvalidNetworkIPAM, n.NetworkIPRange, subnet)
+		}
+	}
+
+	for name, address := range n.NetworkAuxAddresses {
+		if ip := net.ParseIP(address); ip == nil || !subnet.Contains(ip) {
+			return fmt.Errorf("%w: auxiliary address %s=%q is not an address of %s", ErrInvalidNetworkIPAM, name, address, subnet)
+		}
 	}
 
 	return nil
 }
 
+func (n *ClusterConfiguration) customIPAM() bool {
+	return n.NetworkGateway != "" || n.NetworkIPRange != "" || len(n.NetworkAuxAddresses) > 0
+}
+
+// NamespacedName returns the name of a cluster or network, created in given namespace.
+func NamespacedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+
+	return namespace + "." + name
+}
+
+// namespaced returns the configuration with its cluster and network names prefixed with its namespace.
+// An existing network is referenced as is.
+func (n ClusterConfiguration) namespaced() ClusterConfiguration {
+	n.ClusterName = NamespacedName(n.Namespace, n.ClusterName)
+
+	if n.ExistingNetwork == "" {
+		n.NetworkName = NamespacedName(n.Namespace, n.NetworkName)
+	}
+
+	return n
+}
+
 func (n *ClusterConfiguration) imageName() string {
 	if n.ImageName != "" {
 		return n.ImageName
@@ -54,111 +527,841 @@ func (n *ClusterConfiguration) imageName() string {
 	return DefaultNodeImageName
 }
 
-// CreateCluster creates a new swarm cluster.
+func (n *ClusterConfiguration) daemonArgs() []string {
+	if !n.Experimental {
+		return n.DaemonArgs
+	}
+
+	return append(append([]string{}, n.DaemonArgs...), "--experimental")
+}
+
+func (n *ClusterConfiguration) nodeEnv() []string {
+	var env []string
+
+	if n.BuildKit {
+		env = append(env, "DOCKER_BUILDKIT=1")
+	}
+
+	if n.ContentTrust {
+		env = append(env, "DOCKER_CONTENT_TRUST=1")
+	}
+
+	if n.ContentTrustServer != "" {
+		env = append(env, "DOCKER_CONTENT_TRUST_SERVER="+n.ContentTrustServer)
+	}
+
+	return env
+}
+
+// clientConfig returns the docker client configuration of the nodes holding the registry credentials, empty without
+// credentials.
+func (n *ClusterConfiguration) clientConfig() string {
+	if len(n.RegistryAuths) == 0 {
+		return ""
+	}
+
+	type authEntry struct {
+		Auth string `json:"auth"`
+	}
+
+	auths := make(map[string]authEntry, len(n.RegistryAuths))
+
+	for registry, auth := range n.RegistryAuths {
+		auths[registry] = authEntry{Auth: base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))}
+	}
+
+	// Marshaling strings can't fail.
+	config, _ := json.Marshal(map[string]interface{}{"auths": auths})
+
+	return string(config)
+}
+
+func (n *ClusterConfiguration) daemonConfig() string {
+	if !n.ContainerdImageStore {
+		return ""
+	}
+
+	return `{"features":{"containerd-snapshotter":true}}`
+}
+
+func (n *ClusterConfiguration) subnet() (*net.IPNet, error) {
+	if n.NetworkSubnet == "" {
+		return internal.PickSubnet()
+	}
+
+	_, subnet, err := net.ParseCIDR(n.NetworkSubnet)
+
+	return subnet, err
+}
+
+// adoptable returns an ErrClusterNotAdoptable error if the existing cluster is not healthy, or has not been created with
+// compatible parameters.
+func (n *ClusterConfiguration) adoptable(status *ClusterStatus) error {
+	if err := n.compatible(status, ErrClusterNotAdoptable); err != nil {
+		return err
+	}
+
+	switch {
+	case status.ManagersRunning != status.Managers || status.WorkersRunning != status.Workers:
+		return fmt.Errorf("%w: some of its nodes are not running", ErrClusterNotAdoptable)
+	case status.NodesUnhealthy > 0:
+		return fmt.Errorf("%w: %d of its nodes are unhealthy", ErrClusterNotAdoptable, status.NodesUnhealthy)
+	}
+
+	return nil
+}
+
+// claimable returns an ErrClusterNotClaimable error if the provisioned cluster has not been provisioned with compatible
+// parameters.
+func (n *ClusterConfiguration) claimable(status *ClusterStatus) error {
+	return n.compatible(status, ErrClusterNotClaimable)
+}
+
+// compatible returns an error wrapping errIncompatible if the existing cluster nodes don't match the configuration.
+func (n *ClusterConfiguration) compatible(status *ClusterStatus, errIncompatible error) error {
+	switch {
+	case status.Managers != n.Managers || status.Workers != n.Workers:
+		return fmt.Errorf(
+			"%w: it has %d managers and %d workers, expected %d and %d",
+			errIncompatible,
+			status.Managers,
+			status.Workers,
+			n.Managers,
+			n.Workers,
+		)
+	case status.Plain != n.Plain:
+		return fmt.Errorf("%w: plain nodes mismatch", errIncompatible)
+	case status.TLS != n.TLS:
+		return fmt.Errorf("%w: TLS mismatch", errIncompatible)
+	}
+
+	for _, node := range status.Nodes {
+		if node.Image != n.imageName() {
+			return fmt.Errorf("%w: node %q runs image %q, expected %q", errIncompatible, node.ID, node.Image, n.imageName())
+		}
+
+		// An existing network can be referenced by ID, only names are compared.
+		if n.ExistingNetwork == "" && node.Labels[internal.NetworkNameLabel] != n.NetworkName {
+			return fmt.Errorf(
+				"%w: node %q is attached to network %q, expected %q",
+				errIncompatible,
+				node.ID,
+				node.Labels[internal.NetworkNameLabel],
+				n.NetworkName,
+			)
+		}
+	}
+
+	return nil
+}
+
+// nodesConfig returns the configuration of the nodes, but their network.
+func (n *ClusterConfiguration) nodesConfig(labels map[string]string) internal.NodesConfig {
+	nodesCfg := internal.NodesConfig{
+		ClusterName: n.ClusterName,
+		ImageRef:    n.imageName(),
+
+		Managers: n.Managers,
+		Workers:  n.Workers,
+
+		DaemonArgs:     n.daemonArgs(),
+		PublishDaemons: n.Plain,
+		DaemonHostPort: n.DaemonHostPort,
+		Runtime:        n.Runtime,
+		Env:            n.nodeEnv(),
+
+		DaemonConfig: n.daemonConfig(),
+		ClientConfig: n.clientConfig(),
+
+		RestrictEgress:  n.RestrictEgress,
+		EgressAllowlist: n.EgressAllowlist,
+
+		PidsLimit:   n.PidsLimit,
+		MemorySwap:  n.MemorySwap,
+		BlkioWeight: n.BlkioWeight,
+
+		Stopped: n.Provision,
+
+		Addresses:  n.NodeAddresses,
+		RoleRanges: n.RoleAddressRanges,
+
+		Labels: labels,
+	}
+
+	nodesCfg.ManagerResources, nodesCfg.WorkerResources = internal.SplitResources(
+		n.TotalMemory,
+		int64(n.TotalCPU*1e9),
+		n.Managers,
+		n.Workers,
+	)
+
+	// Devices mappings are validated along with the configuration.
+	nodesCfg.Devices, _ = parseDevices(n.Devices)
+
+	if !n.LoadBalancer {
+		nodesCfg.PortBindings = n.PortBindings
+		nodesCfg.ManagerPortBindings = n.PublishPortsOn == PublishPortsOnManagers || n.PublishPortsOn == PublishPortsOnAll
+		nodesCfg.WorkerPortBindings = n.PublishPortsOn == PublishPortsOnAll
+	}
+
+	return nodesCfg
+}
+
+func (n *ClusterConfiguration) networkConfig(subnet *net.IPNet, labels map[string]string) internal.NetworkConfig {
+	return internal.NetworkConfig{
+		Name:        n.NetworkName,
+		ClusterName: n.ClusterName,
+		Subnet:      subnet.String(),
+		Labels:      labels,
+		Driver:      n.NetworkDriver,
+		Options:     n.NetworkDriverOptions,
+
+		Gateway:      n.NetworkGateway,
+		IPRange:      n.NetworkIPRange,
+		AuxAddresses: n.NetworkAuxAddresses,
+
+		Internal: n.AirGapped,
+	}
+}
+
+// hookEvent returns the event of the hooks run at given stage of the creation of the cluster, without host.
+func (n *ClusterConfiguration) hookEvent(stage HookStage) HookEvent {
+	return HookEvent{
+		Stage:       stage,
+		ClusterName: n.ClusterName,
+		Namespace:   n.Namespace,
+		Metadata:    n.Metadata,
+	}
+}
+
+func (n *ClusterConfiguration) labels() map[string]string {
+	now := time.Now()
+	labels := creationLabels(now)
+
+	for key, value := range n.Metadata {
+		labels[internal.MetadataLabelPrefix+key] = value
+	}
+
+	if n.Plain {
+		labels[internal.PlainClusterLabel] = "true"
+	}
+
+	if n.Namespace != "" {
+		labels[internal.NamespaceLabel] = n.Namespace
+	}
+
+	if n.TTL > 0 {
+		labels[internal.ExpiresAtLabel] = now.Add(n.TTL).UTC().Format(time.RFC3339)
+	}
+
+	if n.IdleTimeout > 0 {
+		labels[internal.IdleTimeoutLabel] = n.IdleTimeout.String()
+	}
+
+	// Port bindings are validated, the ranges are recorded as parsed by the daemon.
+	if _, bindings, err := nat.ParsePortSpecs(n.PortBindings); err == nil && len(bindings) > 0 {
+		labels[internal.PortBindingsLabel] = strings.Join(internal.PortRanges(bindings), ",")
+	}
+
+	return labels
+}
+
+// CreateTimings are the durations of the phases of a cluster creation, zero for the skipped ones.
+type CreateTimings struct {
+	// Pull covers the node and load balancer images presence checks and pulls.
+	Pull    time.Duration
+	Network time.Duration
+	// Containers covers the creation and the start of the node containers.
+	Containers time.Duration
+	// Readiness is the time the primary node daemon took to answer once started.
+	Readiness time.Duration
+	SwarmInit time.Duration
+	// Joins covers the managers and workers joining the swarm.
+	Joins time.Duration
+	// Bootstrap covers the creation of the swarm objects declared by the bootstrap.
+	Bootstrap    time.Duration
+	LoadBalancer time.Duration
+
+	Total time.Duration
+}
+
+// CreateCluster creates a new swarm cluster, or plain docker daemons if configured so. It returns an
+// ErrClusterAlreadyExists error if a cluster with the same name exists and is neither adopted, claimed nor recreated.
+// The network and the containers created by a failed creation are removed, unless KeepOnFailure is set.
 func CreateCluster(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration) error {
-	if err := params.validate(); err != nil {
-		return fmt.Errorf("invalid configuration: %v", err)
+	_, err := CreateClusterWithTimings(ctx, hostClient, params)
+
+	return err
+}
+
+// CreateClusterWithTimings creates a cluster like CreateCluster, and reports how long each phase of the creation took.
+func CreateClusterWithTimings(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration) (*CreateTimings, error) {
+	var timings CreateTimings
+
+	ctx, span := internal.StartSpan(
+		ctx,
+		"sind.create",
+		map[string]string{internal.ClusterAttribute: NamespacedName(params.Namespace, params.ClusterName)},
+	)
+	start := time.Now()
+
+	err := createCluster(ctx, hostClient, params, &timings)
+
+	span.End(err)
+
+	if err != nil {
+		return nil, err
+	}
+
+	timings.Total = time.Since(start)
+
+	return &timings, nil
+}
+
+func createCluster(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration, timings *CreateTimings) (err error) {
+	if err = params.validate(); err != nil {
+		return err
+	}
+
+	params = params.namespaced()
+
+	if params.OnEvent != nil {
+		params.OnEvent = serializedEvents(params.OnEvent)
+	}
+
+	var status *ClusterStatus
+
+	if params.Recreate {
+		// The cluster is thrown away, no need for a graceful teardown.
+		if err = DeleteClusterWithOptions(ctx, hostClient, params.ClusterName, DeleteOptions{Force: true}); err != nil {
+			return fmt.Errorf("unable to delete the existing cluster: %w", err)
+		}
+	} else {
+		if status, err = InspectCluster(ctx, hostClient, params.ClusterName); err != nil {
+			return fmt.Errorf("unable to check if the cluster already exists: %w", err)
+		}
+	}
+
+	claim := status != nil && status.Provisioned && !params.Provision
+
+	if status != nil && !claim {
+		if params.AdoptExisting {
+			return params.adoptable(status)
+		}
+
+		return fmt.Errorf("%w: %q", ErrClusterAlreadyExists, params.ClusterName)
+	}
+
+	if err = runHooks(ctx, params.PreCreateHooks, params.hookEvent(PreCreate)); err != nil {
+		return err
+	}
+
+	// Only what this creation created is rolled back, an existing cluster being claimed is left as is.
+	if status == nil && !params.KeepOnFailure {
+		var rollbackOpts DeleteOptions
+
+		if rollbackOpts, err = rollbackOptions(ctx, hostClient, params); err != nil {
+			return err
+		}
+
+		defer func() {
+			if err != nil {
+				err = rollbackCreation(hostClient, params.ClusterName, rollbackOpts, err)
+			}
+		}()
+	}
+
+	var nodecIDs *internal.NodeIDs
+
+	if claim {
+		nodecIDs, err = claimNodes(ctx, hostClient, params, status, timings)
+	} else {
+		nodecIDs, err = createNodes(ctx, hostClient, params, params.labels(), timings)
 	}
 
-	imageExists, err := internal.ImageExists(ctx, hostClient, params.imageName())
 	if err != nil {
-		return fmt.Errorf("unable to check node image existence: %v", err)
+		return err
 	}
 
-	if params.PullImage || !imageExists {
-		if err = internal.PullImage(ctx, hostClient, params.imageName()); err != nil {
-			return fmt.Errorf("unable to pull the %s image: %v", params.imageName(), err)
+	if params.Provision {
+		return nil
+	}
+
+	if !params.Plain {
+		if err = formSwarm(ctx, hostClient, params, nodecIDs, timings); err != nil {
+			return err
 		}
 	}
 
-	subnet, err := internal.PickSubnet()
+	if len(params.PostCreateHooks) == 0 {
+		return nil
+	}
+
+	event := params.hookEvent(PostCreate)
+
+	if event.Host, err = ClusterHost(ctx, hostClient, params.ClusterName); err != nil {
+		return err
+	}
+
+	return runHooks(ctx, params.PostCreateHooks, event)
+}
+
+// rollbackTimeout bounds the removal of what a failed creation created.
+const rollbackTimeout = time.Minute
+
+// rollbackOptions returns the options of the deletion rolling back a failed creation of a cluster. The network and the
+// volumes kept by a previous deletion of the cluster are reattached by the creation, they are left on the host.
+func rollbackOptions(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration) (DeleteOptions, error) {
+	// The cluster is thrown away, no need for a graceful teardown.
+	opts := DeleteOptions{Force: true}
+
+	keptNet, err := internal.FindNetwork(ctx, hostClient, params.ClusterName, params.NetworkName)
+	if err != nil {
+		return opts, fmt.Errorf("unable to look for an existing cluster network: %w", err)
+	}
+
+	keptVolumes, err := internal.ListVolumes(ctx, hostClient, params.ClusterName)
 	if err != nil {
-		return fmt.Errorf("unable to pick an internal subnet: %v", err)
+		return opts, fmt.Errorf("unable to look for existing cluster volumes: %w", err)
 	}
 
-	networkCfg := internal.NetworkConfig{
-		Name:        params.NetworkName,
-		ClusterName: params.ClusterName,
-		Subnet:      subnet.String(),
+	opts.KeepNetwork = keptNet != nil
+	opts.KeepVolumes = len(keptVolumes) > 0
+
+	return opts, nil
+}
+
+// rollbackCreation removes what a failed creation created of a cluster, on its own context as the one of the creation
+// may be canceled. The creation error is returned, along with the rollback one if it failed too.
+func rollbackCreation(hostClient *docker.Client, clusterName string, opts DeleteOptions, createErr error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), rollbackTimeout)
+	defer cancel()
+
+	if err := DeleteClusterWithOptions(ctx, hostClient, clusterName, opts); err != nil {
+		return fmt.Errorf("%w, and unable to remove what has been created: %v", createErr, err)
 	}
 
-	clusterNet, err := internal.CreateNetwork(ctx, hostClient, networkCfg)
+	return createErr
+}
+
+// formSwarm forms the swarm of a cluster which nodes are created, bootstraps it and creates its load balancer if
+// configured so.
+func formSwarm(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration, nodecIDs *internal.NodeIDs, timings *CreateTimings) error {
+	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, params.ClusterName)
 	if err != nil {
-		return fmt.Errorf("unable to create cluster network: %v", err)
+		return fmt.Errorf("unable to get the primary node informations: %w", err)
 	}
 
-	nodesCfg := internal.NodesConfig{
-		ClusterName: params.ClusterName,
-		ImageRef:    params.imageName(),
+	swarmHost, err := nodeHost(hostClient, *primaryNode)
+	if err != nil {
+		return err
+	}
 
-		NetworkID:    clusterNet.ID,
-		NetworkName:  params.NetworkName,
-		Subnet:       *subnet,
-		PortBindings: params.PortBindings,
+	swarmClient, err := nodeClient(ctx, hostClient, params.ClusterName, *primaryNode, swarmHost)
+	if err != nil {
+		return err
+	}
+
+	err = tracePhase(ctx, "sind.create.readiness", &timings.Readiness, func(ctx context.Context) error {
+		return internal.WaitNodeDaemonReady(ctx, swarmClient, hostClient, primaryNode.ID)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to contact the primary node daemon: %w", err)
+	}
+
+	var swarmInfo swarm.Swarm
+
+	err = tracePhase(ctx, "sind.create.swarm_init", &timings.SwarmInit, func(ctx context.Context) error {
+		if _, err := swarmClient.SwarmInit(ctx, swarm.InitRequest{
+			ListenAddr:   internal.SwarmDefaultListenAddress(),
+			Availability: swarm.NodeAvailability(params.ManagerAvailability),
+		}); err != nil {
+			return fmt.Errorf("unable to init the swarm: %w", err)
+		}
 
-		Managers: params.Managers,
-		Workers:  params.Workers,
+		var err error
 
-		DaemonArgs: params.DaemonArgs,
+		if swarmInfo, err = swarmClient.SwarmInspect(ctx); err != nil {
+			return fmt.Errorf("unable to collect swarm cluster informations: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
-	nodecIDs, err := internal.CreateNodes(ctx, hostClient, nodesCfg)
+	params.emit(CreateEvent{Kind: SwarmInitialized})
+
+	err = tracePhase(ctx, "sind.create.joins", &timings.Joins, func(ctx context.Context) error {
+		nodes, err := internal.ListNodes(ctx, hostClient, params.ClusterName)
+		if err != nil {
+			return fmt.Errorf("unable to list cluster nodes: %w", err)
+		}
+
+		clusterConfig := internal.ClusterParams{
+			IDs: *nodecIDs,
+
+			ManagerIPs:       internal.ManagerIPs(nodes),
+			ManagerJoinToken: swarmInfo.JoinTokens.Manager,
+			WorkerJoinToken:  swarmInfo.JoinTokens.Worker,
+
+			ManagerAvailability: params.ManagerAvailability,
+			WorkerAvailability:  params.WorkerAvailability,
+		}
+
+		names := make(map[string]string, len(nodes))
+		for _, node := range nodes {
+			names[node.ID] = nodeName(params.ClusterName, containerName(node))
+		}
+
+		clusterConfig.OnJoined = func(cID string) {
+			params.emit(CreateEvent{Kind: NodeJoined, Node: names[cID]})
+		}
+
+		if err = internal.FormCluster(ctx, hostClient, clusterConfig); err != nil {
+			return fmt.Errorf("unable to form the swarm cluster: %w", err)
+		}
+
+		if err = internal.WaitSwarmNodesReady(ctx, swarmClient, int(params.Managers)+int(params.Workers)); err != nil {
+			return fmt.Errorf("unable to wait for the swarm nodes: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("unable to create nodes: %v", err)
+		return err
 	}
 
-	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, params.ClusterName)
+	if !params.Bootstrap.empty() {
+		err = tracePhase(ctx, "sind.create.bootstrap", &timings.Bootstrap, func(ctx context.Context) error {
+			return internal.BootstrapSwarm(ctx, swarmClient, params.Bootstrap.swarmBootstrap(params.ClusterName))
+		})
+		if err != nil {
+			return fmt.Errorf("unable to bootstrap the swarm: %w", err)
+		}
+	}
+
+	if !params.LoadBalancer {
+		return nil
+	}
+
+	err = tracePhase(ctx, "sind.create.load_balancer", &timings.LoadBalancer, func(ctx context.Context) error {
+		return createLoadBalancer(ctx, hostClient, params)
+	})
 	if err != nil {
-		return fmt.Errorf("unable to get the primary node informations: %v", err)
+		return fmt.Errorf("unable to create the load balancer: %w", err)
 	}
 
-	swarmPort, err := internal.SwarmPort(*primaryNode)
+	return nil
+}
+
+// createNodes creates the network and the node containers of a cluster, with given labels, and records the timings
+// of these phases.
+func createNodes(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration, labels map[string]string, timings *CreateTimings) (*internal.NodeIDs, error) {
+	if params.Runtime != "" {
+		if err := internal.CheckRuntime(ctx, hostClient, params.Runtime); err != nil {
+			return nil, err
+		}
+	}
+
+	if !params.SkipCapacityCheck {
+		if err := internal.CheckHostCapacity(ctx, hostClient, int(params.Managers)+int(params.Workers), params.TotalMemory); err != nil {
+			return nil, fmt.Errorf("host capacity check failed, skip it if you know what you are doing: %w", err)
+		}
+	}
+
+	err := tracePhase(ctx, "sind.create.pull", &timings.Pull, func(ctx context.Context) error {
+		if err := ensureImage(ctx, hostClient, params.imageName(), params.PullImage, params.emit); err != nil {
+			return fmt.Errorf("unable to get node image: %w", err)
+		}
+
+		if params.LoadBalancer {
+			if err := ensureImage(ctx, hostClient, internal.DefaultLoadBalancerImageName, false, params.emit); err != nil {
+				return fmt.Errorf("unable to get load balancer image: %w", err)
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("unable to get the remote docker daemon port: %v", err)
+		return nil, err
 	}
 
-	swarmHost, err := internal.SwarmHost(hostClient)
+	nodesCfg := params.nodesConfig(labels)
+
+	if params.TLS {
+		hosts, err := tlsHosts(hostClient)
+		if err != nil {
+			return nil, err
+		}
+
+		if params.clusterTLS != nil {
+			nodesCfg.TLS, err = params.clusterTLS.Reissue(params.ClusterName, hosts)
+		} else {
+			nodesCfg.TLS, err = internal.GenerateTLS(params.ClusterName, hosts)
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate the cluster certificates: %w", err)
+		}
+	}
+
+	err = tracePhase(ctx, "sind.create.network", &timings.Network, func(ctx context.Context) error {
+		if params.ExistingNetwork != "" {
+			existingNet, err := hostClient.NetworkInspect(ctx, params.ExistingNetwork, types.NetworkInspectOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to inspect the existing network: %w", err)
+			}
+
+			nodesCfg.NetworkID = existingNet.ID
+			nodesCfg.NetworkName = existingNet.Name
+
+			return nil
+		}
+
+		// The network kept by the deletion of the cluster is reattached.
+		keptNet, err := internal.FindNetwork(ctx, hostClient, params.ClusterName, params.NetworkName)
+		if err != nil {
+			return fmt.Errorf("unable to look for an existing cluster network: %w", err)
+		}
+
+		if keptNet != nil {
+			nodesCfg.NetworkID = keptNet.ID
+			nodesCfg.NetworkName = keptNet.Name
+
+			if !params.customIPAM() && len(keptNet.IPAM.Config) > 0 {
+				if _, subnet, err := net.ParseCIDR(keptNet.IPAM.Config[0].Subnet); err == nil {
+					nodesCfg.Subnet = *subnet
+				}
+			}
+
+			return nil
+		}
+
+		subnet, err := params.subnet()
+		if err != nil {
+			return fmt.Errorf("unable to pick an internal subnet: %w", err)
+		}
+
+		clusterNet, err := internal.CreateNetwork(ctx, hostClient, params.networkConfig(subnet, labels))
+		if err != nil {
+			return fmt.Errorf("unable to create cluster network: %w", err)
+		}
+
+		nodesCfg.NetworkID = clusterNet.ID
+		nodesCfg.NetworkName = params.NetworkName
+
+		if !params.customIPAM() {
+			nodesCfg.Subnet = *subnet
+		}
+
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("unable to get the remote docker daemon host: %v", err)
+		return nil, err
 	}
 
-	swarmClient, err := docker.NewClientWithOpts(
-		docker.WithHost("tcp://"+net.JoinHostPort(swarmHost, fmt.Sprintf("%d", swarmPort))),
-		docker.WithAPIVersionNegotiation(),
-	)
+	params.emit(CreateEvent{Kind: NetworkCreated, Network: nodesCfg.NetworkName})
+
+	nodesCfg.OnNodeStarted = func(hostname string) {
+		params.emit(CreateEvent{Kind: NodeStarted, Node: nodeName(params.ClusterName, hostname)})
+	}
+
+	var nodecIDs *internal.NodeIDs
+
+	err = tracePhase(ctx, "sind.create.containers", &timings.Containers, func(ctx context.Context) error {
+		var err error
+
+		nodecIDs, err = internal.CreateNodes(ctx, hostClient, nodesCfg)
+
+		return err
+	})
 	if err != nil {
-		return fmt.Errorf("unable to create swarm client: %v", err)
+		return nil, fmt.Errorf("unable to create nodes: %w", err)
 	}
 
-	if err = internal.WaitDaemonReady(ctx, swarmClient); err != nil {
-		return fmt.Errorf("unable to contact the primary node daemon: %v", err)
+	if !params.AirGapped || params.Provision {
+		return nodecIDs, nil
 	}
 
-	if _, err = swarmClient.SwarmInit(
-		ctx, swarm.InitRequest{ListenAddr: internal.SwarmDefaultListenAddress()}); err != nil {
-		return fmt.Errorf("unable to init the swarm: %v", err)
+	err = tracePhase(ctx, "sind.create.egress_check", nil, func(ctx context.Context) error {
+		nodes, err := internal.ListNodes(ctx, hostClient, params.ClusterName)
+		if err != nil {
+			return fmt.Errorf("unable to list cluster nodes: %w", err)
+		}
+
+		return internal.CheckNoEgress(ctx, hostClient, nodes)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to check the nodes are air-gapped: %w", err)
 	}
 
-	primaryNodeEndpoint, present := primaryNode.NetworkSettings.Networks[params.NetworkName]
-	if !present {
-		return fmt.Errorf("primary node is not a member of the cluster network")
+	return nodecIDs, nil
+}
+
+// claimNodes starts the nodes of a provisioned cluster, once checked they have been provisioned with compatible
+// parameters.
+func claimNodes(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration, status *ClusterStatus, timings *CreateTimings) (*internal.NodeIDs, error) {
+	if err := params.claimable(status); err != nil {
+		return nil, err
 	}
 
-	swarmInfo, err := swarmClient.SwarmInspect(ctx)
+	if params.LoadBalancer {
+		if err := ensureImage(ctx, hostClient, internal.DefaultLoadBalancerImageName, false, params.emit); err != nil {
+			return nil, fmt.Errorf("unable to get load balancer image: %w", err)
+		}
+	}
+
+	err := tracePhase(ctx, "sind.create.containers", &timings.Containers, func(ctx context.Context) error {
+		return internal.StartContainers(ctx, hostClient, status.Nodes)
+	})
 	if err != nil {
-		return fmt.Errorf("unable to collect swarm cluster informations: %v", err)
+		return nil, fmt.Errorf("unable to start the provisioned nodes: %w", err)
 	}
 
-	clusterConfig := internal.ClusterParams{
-		IDs: *nodecIDs,
+	for _, node := range status.Nodes {
+		params.emit(CreateEvent{Kind: NodeStarted, Node: nodeName(params.ClusterName, containerName(node))})
+	}
+
+	nodecIDs := internal.NodeIDsOf(status.Nodes)
+
+	return &nodecIDs, nil
+}
+
+func createLoadBalancer(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration) error {
+	nodes, err := internal.ListNodes(ctx, hostClient, params.ClusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	networkName, networkID, err := loadBalancerNetwork(nodes)
+	if err != nil {
+		return err
+	}
+
+	backends, err := loadBalancerBackends(nodes)
+	if err != nil {
+		return err
+	}
+
+	lbCfg := internal.LoadBalancerConfig{
+		ClusterName:  params.ClusterName,
+		ImageRef:     internal.DefaultLoadBalancerImageName,
+		NetworkID:    networkID,
+		NetworkName:  networkName,
+		PortBindings: params.PortBindings,
+		Backends:     backends,
+		Labels:       creationLabels(time.Now()),
+	}
+
+	_, err = internal.CreateLoadBalancer(ctx, hostClient, lbCfg)
+
+	return err
+}
+
+// loadBalancerNetwork returns the name and the ID of the cluster network the load balancer of given nodes is attached
+// to, all the nodes being on it.
+func loadBalancerNetwork(nodes []types.Container) (string, string, error) {
+	if len(nodes) == 0 {
+		return "", "", errors.New("no node to balance across")
+	}
+
+	networkName, endpoint, err := internal.NodeNetwork(nodes[0])
+	if err != nil {
+		return "", "", err
+	}
+
+	if endpoint == nil || endpoint.NetworkID == "" {
+		return "", "", fmt.Errorf("node %q has no endpoint on the cluster network %q", nodes[0].ID, networkName)
+	}
+
+	return networkName, endpoint.NetworkID, nil
+}
+
+// reloadLoadBalancer makes the load balancer of a cluster, if any, balance across its current nodes. It is called once
+// nodes are added, removed or recreated with another address.
+func reloadLoadBalancer(ctx context.Context, hostClient *docker.Client, clusterName string) error {
+	containers, err := internal.ListContainers(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	var (
+		lbID  string
+		nodes = make([]types.Container, 0, len(containers))
+	)
+
+	for _, container := range containers {
+		component, ok := container.Labels[internal.ComponentLabel]
+
+		switch {
+		case component == internal.ComponentLoadBalancer:
+			lbID = container.ID
+		case !ok:
+			nodes = append(nodes, container)
+		}
+	}
+
+	if lbID == "" {
+		return nil
+	}
+
+	backends, err := loadBalancerBackends(nodes)
+	if err != nil {
+		return err
+	}
+
+	if err = internal.ReloadLoadBalancer(ctx, hostClient, lbID, backends); err != nil {
+		return fmt.Errorf("unable to reload the load balancer: %w", err)
+	}
+
+	return nil
+}
+
+// loadBalancerBackends returns the addresses of the running nodes on the cluster network.
+func loadBalancerBackends(nodes []types.Container) ([]string, error) {
+	backends := make([]string, 0, len(nodes))
+
+	for _, node := range nodes {
+		_, endpoint, err := internal.NodeNetwork(node)
+		if err != nil {
+			return nil, err
+		}
+
+		// Stopped nodes have no address, they are balanced across once started and the load balancer reloaded.
+		if endpoint.IPAddress == "" {
+			continue
+		}
+
+		backends = append(backends, endpoint.IPAddress)
+	}
+
+	return backends, nil
+}
+
+// ensureImage pulls an image missing from the docker host, or any image if pull is set. The events of the pull are
+// sent to onEvent, if not nil.
+func ensureImage(ctx context.Context, hostClient *docker.Client, imageRef string, pull bool, onEvent func(CreateEvent)) error {
+	imageExists, err := internal.ImageExists(ctx, hostClient, imageRef)
+	if err != nil {
+		return fmt.Errorf("unable to check image existence: %w", err)
+	}
+
+	if imageExists && !pull {
+		return nil
+	}
+
+	if onEvent != nil {
+		onEvent(CreateEvent{Kind: ImagePulling, Image: imageRef})
+	}
 
-		PrimaryNodeIP:    primaryNodeEndpoint.IPAddress,
-		ManagerJoinToken: swarmInfo.JoinTokens.Manager,
-		WorkerJoinToken:  swarmInfo.JoinTokens.Worker,
+	if err = internal.PullImage(ctx, hostClient, imageRef); err != nil {
+		return fmt.Errorf("unable to pull the %s image: %w", imageRef, err)
 	}
 
-	if err = internal.FormCluster(ctx, hostClient, clusterConfig); err != nil {
-		return fmt.Errorf("unable to form the swarm cluster: %v", err)
+	if onEvent != nil {
+		onEvent(CreateEvent{Kind: ImagePulled, Image: imageRef})
 	}
 
 	return nil
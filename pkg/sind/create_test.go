@@ -0,0 +1,328 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterConfigurationDaemonArgs(t *testing.T) {
+	cfg := ClusterConfiguration{DaemonArgs: []string{"--debug"}}
+
+	assert.Equal(t, []string{"--debug"}, cfg.daemonArgs())
+	assert.Nil(t, cfg.nodeEnv())
+
+	cfg.Experimental = true
+	cfg.BuildKit = true
+
+	assert.Equal(t, []string{"--debug", "--experimental"}, cfg.daemonArgs())
+	assert.Equal(t, []string{"--debug"}, cfg.DaemonArgs)
+	assert.Equal(t, []string{"DOCKER_BUILDKIT=1"}, cfg.nodeEnv())
+}
+
+func TestClusterConfigurationDaemonConfig(t *testing.T) {
+	cfg := ClusterConfiguration{}
+
+	assert.Empty(t, cfg.daemonConfig())
+
+	cfg.ContainerdImageStore = true
+
+	assert.JSONEq(t, `{"features":{"containerd-snapshotter":true}}`, cfg.daemonConfig())
+}
+
+func TestClusterConfigurationRegistryAuths(t *testing.T) {
+	cfg := ClusterConfiguration{ContentTrust: true, ContentTrustServer: "https://notary.local:4443"}
+
+	assert.Empty(t, cfg.clientConfig())
+	assert.Equal(
+		t,
+		[]string{"DOCKER_CONTENT_TRUST=1", "DOCKER_CONTENT_TRUST_SERVER=https://notary.local:4443"},
+		cfg.nodeEnv(),
+	)
+
+	cfg.RegistryAuths = map[string]RegistryAuth{
+		"registry.local:5000": {Username: "foo", Password: "bar"},
+	}
+
+	assert.JSONEq(t, `{"auths":{"registry.local:5000":{"auth":"Zm9vOmJhcg=="}}}`, cfg.clientConfig())
+}
+
+func TestClusterConfigurationAdoptable(t *testing.T) {
+	cfg := ClusterConfiguration{ClusterName: "test", NetworkName: "test-net", Managers: 1, Workers: 1}
+
+	node := func(image, network string) types.Container {
+		return types.Container{ID: "node", Image: image, Labels: map[string]string{internal.NetworkNameLabel: network}}
+	}
+
+	status := func(mutate func(*ClusterStatus)) *ClusterStatus {
+		status := ClusterStatus{
+			Managers:        1,
+			ManagersRunning: 1,
+			Workers:         1,
+			WorkersRunning:  1,
+			Nodes: []types.Container{
+				node(DefaultNodeImageName, "test-net"),
+				node(DefaultNodeImageName, "test-net"),
+			},
+		}
+
+		mutate(&status)
+
+		return &status
+	}
+
+	testCases := []struct {
+		desc        string
+		status      *ClusterStatus
+		expectError bool
+	}{
+		{
+			desc:   "compatible cluster",
+			status: status(func(*ClusterStatus) {}),
+		},
+		{
+			desc:        "with other node counts",
+			status:      status(func(s *ClusterStatus) { s.Workers = 2 }),
+			expectError: true,
+		},
+		{
+			desc:        "with stopped nodes",
+			status:      status(func(s *ClusterStatus) { s.WorkersRunning = 0 }),
+			expectError: true,
+		},
+		{
+			desc:        "with unhealthy nodes",
+			status:      status(func(s *ClusterStatus) { s.NodesUnhealthy = 1 }),
+			expectError: true,
+		},
+		{
+			desc:        "with plain nodes",
+			status:      status(func(s *ClusterStatus) { s.Plain = true }),
+			expectError: true,
+		},
+		{
+			desc:        "with TLS nodes",
+			status:      status(func(s *ClusterStatus) { s.TLS = true }),
+			expectError: true,
+		},
+		{
+			desc:        "with another image",
+			status:      status(func(s *ClusterStatus) { s.Nodes[1] = node("docker:19.03-dind", "test-net") }),
+			expectError: true,
+		},
+		{
+			desc:        "with another network",
+			status:      status(func(s *ClusterStatus) { s.Nodes[1] = node(DefaultNodeImageName, "other-net") }),
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := cfg.adoptable(test.status)
+			if !test.expectError {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrClusterNotAdoptable))
+		})
+	}
+}
+
+func TestClusterConfigurationClaimable(t *testing.T) {
+	cfg := ClusterConfiguration{ClusterName: "test", NetworkName: "test-net", Managers: 1, Workers: 1}
+
+	node := func(image string) types.Container {
+		return types.Container{ID: "node", State: "created", Image: image, Labels: map[string]string{internal.NetworkNameLabel: "test-net"}}
+	}
+
+	testCases := []struct {
+		desc        string
+		status      *ClusterStatus
+		expectError bool
+	}{
+		{
+			desc: "provisioned with compatible parameters",
+			status: &ClusterStatus{
+				Managers:    1,
+				Workers:     1,
+				Provisioned: true,
+				Nodes:       []types.Container{node(DefaultNodeImageName), node(DefaultNodeImageName)},
+			},
+		},
+		{
+			desc: "provisioned with other node counts",
+			status: &ClusterStatus{
+				Managers:    1,
+				Workers:     2,
+				Provisioned: true,
+				Nodes:       []types.Container{node(DefaultNodeImageName), node(DefaultNodeImageName), node(DefaultNodeImageName)},
+			},
+			expectError: true,
+		},
+		{
+			desc: "provisioned with another image",
+			status: &ClusterStatus{
+				Managers:    1,
+				Workers:     1,
+				Provisioned: true,
+				Nodes:       []types.Container{node(DefaultNodeImageName), node("docker:19.03-dind")},
+			},
+			expectError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := cfg.claimable(test.status)
+			if !test.expectError {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, ErrClusterNotClaimable))
+		})
+	}
+}
+
+func TestClusterConfigurationNamespaced(t *testing.T) {
+	cfg := ClusterConfiguration{ClusterName: "test", NetworkName: "test-net", Namespace: "ci-1"}
+
+	namespaced := cfg.namespaced()
+
+	assert.Equal(t, "ci-1.test", namespaced.ClusterName)
+	assert.Equal(t, "ci-1.test-net", namespaced.NetworkName)
+	assert.Equal(t, "ci-1", namespaced.labels()[internal.NamespaceLabel])
+
+	cfg.ExistingNetwork = "shared"
+	assert.Equal(t, "test-net", cfg.namespaced().NetworkName)
+
+	cfg.Namespace = ""
+	assert.Equal(t, "test", cfg.namespaced().ClusterName)
+	assert.NotContains(t, cfg.labels(), internal.NamespaceLabel)
+}
+
+func TestLoadBalancerBackends(t *testing.T) {
+	nodeOn := func(ip string) types.Container {
+		return types.Container{
+			Labels: map[string]string{internal.NetworkNameLabel: "sind"},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{
+					"sind":   {IPAddress: ip},
+					"egress": {IPAddress: "172.30.0.2"},
+				},
+			},
+		}
+	}
+
+	backends, err := loadBalancerBackends([]types.Container{nodeOn("10.0.117.2"), nodeOn(""), nodeOn("10.0.117.4")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.0.117.2", "10.0.117.4"}, backends)
+
+	_, err = loadBalancerBackends([]types.Container{{ID: "broken"}})
+	assert.Error(t, err)
+}
+
+func TestLoadBalancerNetwork(t *testing.T) {
+	nodeOn := func(networkID string) types.Container {
+		return types.Container{
+			ID:     "node",
+			Labels: map[string]string{internal.NetworkNameLabel: "sind"},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{"sind": {NetworkID: networkID}},
+			},
+		}
+	}
+
+	networkName, networkID, err := loadBalancerNetwork([]types.Container{nodeOn("net"), nodeOn("net")})
+	require.NoError(t, err)
+	assert.Equal(t, "sind", networkName)
+	assert.Equal(t, "net", networkID)
+
+	_, _, err = loadBalancerNetwork(nil)
+	assert.Error(t, err)
+
+	_, _, err = loadBalancerNetwork([]types.Container{nodeOn("")})
+	assert.Error(t, err)
+
+	_, _, err = loadBalancerNetwork([]types.Container{{ID: "broken"}})
+	assert.Error(t, err)
+}
+
+func TestRollbackOptions(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		networks string
+		volumes  string
+		want     DeleteOptions
+	}{
+		{
+			desc:     "nothing kept",
+			networks: `[]`,
+			volumes:  `{"Volumes":[]}`,
+			want:     DeleteOptions{Force: true},
+		},
+		{
+			desc:     "kept network",
+			networks: `[{"Name":"test-net","Id":"net"}]`,
+			volumes:  `{"Volumes":[]}`,
+			want:     DeleteOptions{Force: true, KeepNetwork: true},
+		},
+		{
+			desc:     "network of another name",
+			networks: `[{"Name":"other-net","Id":"net"}]`,
+			volumes:  `{"Volumes":[]}`,
+			want:     DeleteOptions{Force: true},
+		},
+		{
+			desc:     "kept volumes",
+			networks: `[]`,
+			volumes:  `{"Volumes":[{"Name":"sind-test-manager-0"}]}`,
+			want:     DeleteOptions{Force: true, KeepVolumes: true},
+		},
+	}
+
+	for _, test := range testCases {
+		test := test
+		t.Run(test.desc, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.Header().Set("Content-Type", "application/json")
+
+				switch {
+				case strings.HasSuffix(req.URL.Path, "/networks"):
+					_, _ = rw.Write([]byte(test.networks))
+				case strings.HasSuffix(req.URL.Path, "/volumes"):
+					_, _ = rw.Write([]byte(test.volumes))
+				default:
+					http.NotFound(rw, req)
+				}
+			}))
+			defer server.Close()
+
+			hostClient, err := docker.NewClientWithOpts(
+				docker.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+				docker.WithVersion("1.40"),
+			)
+			require.NoError(t, err)
+
+			opts, err := rollbackOptions(
+				context.Background(),
+				hostClient,
+				ClusterConfiguration{ClusterName: "test", NetworkName: "test-net"},
+			)
+			require.NoError(t, err)
+			assert.Equal(t, test.want, opts)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// DefaultCurlImageName is the default image used to run curl inside a cluster.
+const DefaultCurlImageName = "curlimages/curl:latest"
+
+// CurlConfig is the configuration of an HTTP request sent from inside a cluster.
+type CurlConfig struct {
+	// Network is the swarm network the request is sent from, which must be attachable, e.g. a stack network created
+	// with attachable: true. Services on this network are reachable by name and resolve to their VIP.
+	// The request is sent from the default bridge of the primary node if empty.
+	Network string
+	// ImageName is the curl image, DefaultCurlImageName if empty.
+	ImageName string
+	// Args are the curl arguments, e.g. ["-sv", "http://web:8080"].
+	Args []string
+}
+
+// CurlResult is the outcome of curl run inside a cluster.
+type CurlResult = internal.RunResult
+
+// Curl runs curl with given arguments in a short lived container on the primary node of the cluster, to observe what a
+// service of the cluster would. A non zero exit code of curl is reported in the result, not as an error.
+func Curl(ctx context.Context, hostClient *docker.Client, clusterName string, cfg CurlConfig) (*CurlResult, error) {
+	imageName := cfg.imageName()
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	if err = ensureImage(ctx, swarmClient, imageName, false, nil); err != nil {
+		return nil, fmt.Errorf("unable to get curl image: %w", err)
+	}
+
+	return internal.RunContainer(
+		ctx,
+		swarmClient,
+		internal.RunConfig{ImageRef: imageName, Network: cfg.Network, Cmd: cfg.Args},
+	)
+}
+
+func (c *CurlConfig) imageName() string {
+	if c.ImageName == "" {
+		return DefaultCurlImageName
+	}
+
+	return c.ImageName
+}
@@ -0,0 +1,12 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurlConfigImageName(t *testing.T) {
+	assert.Equal(t, DefaultCurlImageName, (&CurlConfig{}).imageName())
+	assert.Equal(t, "curlimages/curl:7.78.0", (&CurlConfig{ImageName: "curlimages/curl:7.78.0"}).imageName())
+}
@@ -0,0 +1,83 @@
+package sind
+
+import (
+	"context"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// SwarmData is a secret or a config stored in a cluster.
+type SwarmData = internal.SwarmData
+
+// CreateSecret creates a secret holding given data on a cluster, and returns its ID.
+func CreateSecret(ctx context.Context, hostClient *docker.Client, clusterName, name string, data []byte) (string, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.CreateSecret(ctx, swarmClient, name, data)
+}
+
+// ListSecrets returns the secrets of a cluster, sorted by name. Their data can't be read back.
+func ListSecrets(ctx context.Context, hostClient *docker.Client, clusterName string) ([]SwarmData, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.ListSecrets(ctx, swarmClient)
+}
+
+// RemoveSecret removes the secret with given name or ID from a cluster.
+func RemoveSecret(ctx context.Context, hostClient *docker.Client, clusterName, name string) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.RemoveSecret(ctx, swarmClient, name)
+}
+
+// CreateConfig creates a config holding given data on a cluster, and returns its ID.
+func CreateConfig(ctx context.Context, hostClient *docker.Client, clusterName, name string, data []byte) (string, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.CreateConfig(ctx, swarmClient, name, data)
+}
+
+// ListConfigs returns the configs of a cluster, sorted by name.
+func ListConfigs(ctx context.Context, hostClient *docker.Client, clusterName string) ([]SwarmData, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.ListConfigs(ctx, swarmClient)
+}
+
+// RemoveConfig removes the config with given name or ID from a cluster.
+func RemoveConfig(ctx context.Context, hostClient *docker.Client, clusterName, name string) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.RemoveConfig(ctx, swarmClient, name)
+}
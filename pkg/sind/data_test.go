@@ -0,0 +1,86 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwarmDataWithoutCluster(t *testing.T) {
+	// The docker host knows no container, hence no cluster.
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if !strings.HasSuffix(req.URL.Path, "/containers/json") {
+			http.NotFound(rw, req)
+			return
+		}
+
+		rw.Header().Set("Content-Type", "application/json")
+		_, _ = rw.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	hostClient, err := docker.NewClientWithOpts(
+		docker.WithHost("tcp://"+strings.TrimPrefix(server.URL, "http://")),
+		docker.WithVersion("1.40"),
+	)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	testCases := []struct {
+		desc string
+		call func() error
+	}{
+		{
+			desc: "creating a secret",
+			call: func() error {
+				_, err := CreateSecret(ctx, hostClient, "test", "token", []byte("s3cr3t"))
+				return err
+			},
+		},
+		{
+			desc: "listing secrets",
+			call: func() error {
+				_, err := ListSecrets(ctx, hostClient, "test")
+				return err
+			},
+		},
+		{
+			desc: "removing a secret",
+			call: func() error { return RemoveSecret(ctx, hostClient, "test", "token") },
+		},
+		{
+			desc: "creating a config",
+			call: func() error {
+				_, err := CreateConfig(ctx, hostClient, "test", "nginx", []byte("server {}"))
+				return err
+			},
+		},
+		{
+			desc: "listing configs",
+			call: func() error {
+				_, err := ListConfigs(ctx, hostClient, "test")
+				return err
+			},
+		},
+		{
+			desc: "removing a config",
+			call: func() error { return RemoveConfig(ctx, hostClient, "test", "nginx") },
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.call()
+			assert.True(t, errors.Is(err, internal.ErrPrimaryContainerNotFound), "unexpected error: %v", err)
+		})
+	}
+}
@@ -2,31 +2,322 @@ package sind
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/sind/internal"
 )
 
+// DefaultDeleteJobs is the amount of containers removed in parallel when DeleteOptions.Jobs is zero.
+const DefaultDeleteJobs = 10
+
+// DeleteOptions tunes the deletion of a cluster.
+type DeleteOptions struct {
+	// Force skips the graceful teardown of the swarm, useful when the cluster is broken.
+	Force bool
+	// StopTimeout is the time given to the nodes to stop before being killed, daemon default if zero.
+	StopTimeout time.Duration
+	// Jobs is the amount of containers removed in parallel, DefaultDeleteJobs if zero, all of them at once if negative.
+	Jobs int
+	// KeepNetwork leaves the cluster network on the host. Creating the cluster again reattaches its nodes to it, and it
+	// can be used by another cluster as its ExistingNetwork. It is removed by deleting the cluster again without it.
+	KeepNetwork bool
+	// KeepVolumes leaves the volumes holding the daemon state of the nodes on the host, images included. Creating the
+	// cluster again reattaches them to the nodes with the same name, which join the new swarm. They are removed by
+	// deleting the cluster again without it.
+	KeepVolumes bool
+	// PreDeleteHooks are run in order before the cluster is torn down, unless what is left is a partially deleted
+	// cluster. A failing hook fails the deletion, leaving the cluster untouched.
+	PreDeleteHooks []Hook
+}
+
+func (o *DeleteOptions) jobs() int {
+	if o.Jobs == 0 {
+		return DefaultDeleteJobs
+	}
+
+	return o.Jobs
+}
+
+// DeleteError reports the resources of a cluster its deletion failed to remove, along with their error. They are left
+// on the docker host, deleting the cluster again removes them. It also reports a failed graceful teardown, the
+// resources of the cluster being removed anyway.
+type DeleteError struct {
+	// Teardown is the error the graceful teardown of the swarm failed with, if it did.
+	Teardown error
+	// Containers maps the IDs of the containers left to the error their removal failed with.
+	Containers map[string]error
+	// Networks maps the IDs of the networks left to the error their removal failed with.
+	Networks map[string]error
+	// Volumes maps the names of the volumes left to the error their removal failed with.
+	Volumes map[string]error
+}
+
+func (e *DeleteError) Error() string {
+	var failures []string
+
+	if e.Teardown != nil && !e.Partial() {
+		return fmt.Sprintf("cluster deleted without a graceful teardown: %v", e.Teardown)
+	}
+
+	if e.Teardown != nil {
+		failures = append(failures, fmt.Sprintf("unable to gracefully teardown the cluster: %v", e.Teardown))
+	}
+
+	if len(e.Containers) > 0 {
+		failures = append(failures, (&internal.ContainersError{Operation: "remove", Errors: e.Containers}).Error())
+	}
+
+	if len(e.Networks) > 0 {
+		failures = append(failures, (&internal.NetworksError{Operation: "delete", Errors: e.Networks}).Error())
+	}
+
+	if len(e.Volumes) > 0 {
+		failures = append(failures, (&internal.VolumesError{Operation: "remove", Errors: e.Volumes}).Error())
+	}
+
+	return fmt.Sprintf("cluster partially deleted, delete it again to remove what is left: %s", strings.Join(failures, ", "))
+}
+
+// Unwrap returns the error the graceful teardown failed with.
+func (e *DeleteError) Unwrap() error {
+	return e.Teardown
+}
+
+// Partial tells if resources of the cluster are left on the docker host.
+func (e *DeleteError) Partial() bool {
+	return len(e.Containers) > 0 || len(e.Networks) > 0 || len(e.Volumes) > 0
+}
+
+// Leftovers are the names of the containers, networks and volumes of a cluster on the docker host.
+type Leftovers struct {
+	Containers []string
+	Networks   []string
+	Volumes    []string
+}
+
+// Count returns the amount of resources left.
+func (l *Leftovers) Count() int {
+	return len(l.Containers) + len(l.Networks) + len(l.Volumes)
+}
+
+// ListLeftovers returns the containers, networks and volumes of a cluster left on the docker host, including the ones
+// left by a failed deletion, an interrupted creation or kept by DeleteOptions.
+func ListLeftovers(ctx context.Context, client *docker.Client, clusterName string) (*Leftovers, error) {
+	containers, err := internal.ListContainers(ctx, client, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list containers: %w", err)
+	}
+
+	nets, err := internal.ListNetworks(ctx, client, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list networks: %w", err)
+	}
+
+	volumes, err := internal.ListVolumes(ctx, client, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list volumes: %w", err)
+	}
+
+	return leftovers(containers, nets, volumes), nil
+}
+
+func leftovers(containers []types.Container, nets []types.NetworkResource, volumes []string) *Leftovers {
+	result := Leftovers{Volumes: volumes}
+
+	for _, container := range containers {
+		result.Containers = append(result.Containers, containerName(container))
+	}
+
+	for _, net := range nets {
+		result.Networks = append(result.Networks, net.Name)
+	}
+
+	sort.Strings(result.Containers)
+	sort.Strings(result.Networks)
+
+	return &result
+}
+
+// LeftoverResources returns the amount of containers, networks and volumes of a cluster left on the docker host,
+// including the ones left by a failed deletion.
+func LeftoverResources(ctx context.Context, client *docker.Client, clusterName string) (int, error) {
+	leftovers, err := ListLeftovers(ctx, client, clusterName)
+	if err != nil {
+		return 0, err
+	}
+
+	return leftovers.Count(), nil
+}
+
 // DeleteCluster removes all ressources related to a sind cluster from the host.
+// Stacks and services are removed, nodes leave the swarm and are stopped before being removed.
+// All the containers, networks and volumes are removed even if the teardown or some of them fail to, which is reported
+// by a *DeleteError.
 func DeleteCluster(ctx context.Context, client *docker.Client, clusterName string) error {
+	return DeleteClusterWithOptions(ctx, client, clusterName, DeleteOptions{})
+}
+
+// DeleteClusterWithOptions removes a cluster like DeleteCluster, according to the given options.
+func DeleteClusterWithOptions(ctx context.Context, client *docker.Client, clusterName string, opts DeleteOptions) error {
+	ctx, span := internal.StartSpan(ctx, "sind.delete", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := deleteCluster(ctx, client, clusterName, opts)
+
+	span.End(err)
+
+	return err
+}
+
+func deleteCluster(ctx context.Context, client *docker.Client, clusterName string, opts DeleteOptions) error {
+	if len(opts.PreDeleteHooks) > 0 {
+		status, err := InspectCluster(ctx, client, clusterName)
+		if err != nil {
+			return fmt.Errorf("unable to inspect the cluster: %w", err)
+		}
+
+		if status != nil {
+			if err = runHooks(ctx, opts.PreDeleteHooks, clusterHookEvent(ctx, client, PreDelete, status)); err != nil {
+				return err
+			}
+		}
+	}
+
 	nodes, err := internal.ListContainers(ctx, client, clusterName)
 	if err != nil {
-		return fmt.Errorf("unable to list nodes: %v", err)
+		return fmt.Errorf("unable to list nodes: %w", err)
 	}
 
 	nets, err := internal.ListNetworks(ctx, client, clusterName)
 	if err != nil {
-		return fmt.Errorf("unable to list cluster networks: %v", err)
+		return fmt.Errorf("unable to list cluster networks: %w", err)
+	}
+
+	// All the resources are removed even if the teardown or some of them fail to, the ones left are removed by deleting
+	// again.
+	var deleteErr DeleteError
+
+	if !opts.Force {
+		deleteErr.Teardown = tracePhase(ctx, "sind.delete.teardown", nil, func(ctx context.Context) error {
+			return teardownCluster(ctx, client, clusterName, nodes, opts)
+		})
+	}
+
+	err = tracePhase(ctx, "sind.delete.containers", nil, func(ctx context.Context) error {
+		return internal.RemoveContainers(ctx, client, nodes, opts.jobs(), !opts.KeepVolumes)
+	})
+
+	var containersErr *internal.ContainersError
+	if errors.As(err, &containersErr) {
+		deleteErr.Containers = containersErr.Errors
+	} else if err != nil {
+		return fmt.Errorf("unable to delete nodes: %w", err)
 	}
 
-	if err := internal.RemoveContainers(ctx, client, nodes); err != nil {
-		return fmt.Errorf("unable to delete nodes: %v", err)
+	if !opts.KeepNetwork {
+		err = tracePhase(ctx, "sind.delete.networks", nil, func(ctx context.Context) error {
+			return internal.DeleteNetworks(ctx, client, nets)
+		})
+
+		var networksErr *internal.NetworksError
+		if errors.As(err, &networksErr) {
+			deleteErr.Networks = networksErr.Errors
+		} else if err != nil {
+			return fmt.Errorf("unable to delete networks: %w", err)
+		}
 	}
 
-	if err := internal.DeleteNetworks(ctx, client, nets); err != nil {
-		return fmt.Errorf("unable to delete networks: %v", err)
+	if !opts.KeepVolumes {
+		err = tracePhase(ctx, "sind.delete.volumes", nil, func(ctx context.Context) error {
+			// Listed once the nodes are removed, volumes in use can't be removed.
+			volumes, err := internal.ListVolumes(ctx, client, clusterName)
+			if err != nil {
+				return err
+			}
+
+			return internal.RemoveVolumes(ctx, client, volumes)
+		})
+
+		var volumesErr *internal.VolumesError
+		if errors.As(err, &volumesErr) {
+			deleteErr.Volumes = volumesErr.Errors
+		} else if err != nil {
+			return fmt.Errorf("unable to remove volumes: %w", err)
+		}
+	}
+
+	if deleteErr.Teardown != nil || deleteErr.Partial() {
+		return &deleteErr
+	}
+
+	return nil
+}
+
+func teardownCluster(ctx context.Context, client *docker.Client, clusterName string, containers []types.Container, opts DeleteOptions) error {
+	var running []types.Container
+
+	for _, container := range containers {
+		if container.State == "running" {
+			running = append(running, container)
+		}
+	}
+
+	// Services can only be removed through a running primary node, a stopped cluster has nothing left to teardown.
+	// Services of an external swarm are not owned by the cluster, its nodes only leave it.
+	primaryRunning, plain, external := false, false, false
+
+	for _, container := range running {
+		if container.Labels[internal.NodeRoleLabel] == internal.NodeRolePrimary {
+			primaryRunning = true
+			plain = container.Labels[internal.PlainClusterLabel] == "true"
+			_, external = container.Labels[internal.ExternalSwarmLabel]
+		}
+	}
+
+	if primaryRunning && !plain && !external {
+		swarmClient, err := ClusterClient(ctx, client, clusterName)
+		if err != nil {
+			return err
+		}
+
+		defer swarmClient.Close()
+
+		if _, err = internal.RemoveStacks(ctx, swarmClient); err != nil {
+			return fmt.Errorf("unable to remove stacks: %w", err)
+		}
+
+		if err = internal.RemoveServices(ctx, swarmClient); err != nil {
+			return fmt.Errorf("unable to remove services: %w", err)
+		}
+	}
+
+	var runningNodes []types.Container
+
+	for _, container := range running {
+		if _, ok := container.Labels[internal.ComponentLabel]; !ok {
+			runningNodes = append(runningNodes, container)
+		}
+	}
+
+	if !plain {
+		if err := internal.LeaveSwarm(ctx, client, runningNodes); err != nil {
+			return err
+		}
+	}
+
+	if err := internal.StopContainers(ctx, client, running, stopTimeout(opts.StopTimeout)); err != nil {
+		return fmt.Errorf("unable to stop nodes: %w", err)
 	}
 
 	return nil
 }
+
+// DeleteExpiredClusters deletes all the clusters which TTL is elapsed, and returns their names.
+func DeleteExpiredClusters(ctx context.Context, client *docker.Client) ([]string, error) {
+	return GarbageCollect(ctx, client, GCCriteria{Expired: true})
+}
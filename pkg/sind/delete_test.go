@@ -0,0 +1,75 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeleteError(t *testing.T) {
+	err := &DeleteError{
+		Containers: map[string]error{"node": errors.New("device busy")},
+		Networks:   map[string]error{"net": errors.New("active endpoints")},
+		Volumes:    map[string]error{"sind-test-worker-0-data": errors.New("volume in use")},
+	}
+
+	assert.Equal(
+		t,
+		"cluster partially deleted, delete it again to remove what is left: "+
+			"failed to remove 1 container(s): node: device busy, failed to delete 1 network(s): net: active endpoints, "+
+			"failed to remove 1 volume(s): sind-test-worker-0-data: volume in use",
+		err.Error(),
+	)
+	assert.True(t, err.Partial())
+}
+
+func TestDeleteErrorTeardown(t *testing.T) {
+	teardownErr := errors.New("no manager running")
+
+	err := &DeleteError{Teardown: teardownErr}
+
+	assert.Equal(t, "cluster deleted without a graceful teardown: no manager running", err.Error())
+	assert.False(t, err.Partial())
+	assert.True(t, errors.Is(err, teardownErr))
+
+	err.Networks = map[string]error{"net": errors.New("active endpoints")}
+
+	assert.Equal(
+		t,
+		"cluster partially deleted, delete it again to remove what is left: "+
+			"unable to gracefully teardown the cluster: no manager running, failed to delete 1 network(s): net: active endpoints",
+		err.Error(),
+	)
+	assert.True(t, err.Partial())
+}
+
+func TestLeftovers(t *testing.T) {
+	result := leftovers(
+		[]types.Container{
+			{ID: "b", Names: []string{"/sind-test-worker-0"}},
+			{ID: "a", Names: []string{"/sind-test-manager-0"}},
+			{ID: "c"},
+		},
+		[]types.NetworkResource{{ID: "n", Name: "test"}},
+		[]string{"sind-test-worker-0-data"},
+	)
+
+	assert.Equal(
+		t,
+		&Leftovers{
+			Containers: []string{"c", "sind-test-manager-0", "sind-test-worker-0"},
+			Networks:   []string{"test"},
+			Volumes:    []string{"sind-test-worker-0-data"},
+		},
+		result,
+	)
+	assert.Equal(t, 5, result.Count())
+}
+
+func TestDeleteOptionsJobs(t *testing.T) {
+	assert.Equal(t, DefaultDeleteJobs, (&DeleteOptions{}).jobs())
+	assert.Equal(t, 3, (&DeleteOptions{Jobs: 3}).jobs())
+	assert.Equal(t, -1, (&DeleteOptions{Jobs: -1}).jobs())
+}
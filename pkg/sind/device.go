@@ -0,0 +1,80 @@
+package sind
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/docker/docker/api/types/container"
+)
+
+// defaultDevicePermissions are the cgroup permissions of a device mapping which doesn't specify them.
+const defaultDevicePermissions = "rwm"
+
+// parseDevices parses device mappings of the form host-path[:container-path][:permissions], like docker run --device.
+func parseDevices(devices []string) ([]container.DeviceMapping, error) {
+	if len(devices) == 0 {
+		return nil, nil
+	}
+
+	mappings := make([]container.DeviceMapping, len(devices))
+
+	for i, device := range devices {
+		mapping, err := parseDevice(device)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDeviceMapping, err)
+		}
+
+		mappings[i] = mapping
+	}
+
+	return mappings, nil
+}
+
+func parseDevice(device string) (container.DeviceMapping, error) {
+	mapping := container.DeviceMapping{CgroupPermissions: defaultDevicePermissions}
+
+	parts := strings.Split(device, ":")
+
+	switch len(parts) {
+	case 3:
+		mapping.PathOnHost, mapping.PathInContainer, mapping.CgroupPermissions = parts[0], parts[1], parts[2]
+	case 2:
+		mapping.PathOnHost = parts[0]
+
+		if validDevicePermissions(parts[1]) {
+			mapping.PathInContainer, mapping.CgroupPermissions = parts[0], parts[1]
+		} else {
+			mapping.PathInContainer = parts[1]
+		}
+	case 1:
+		mapping.PathOnHost, mapping.PathInContainer = parts[0], parts[0]
+	default:
+		return mapping, fmt.Errorf("device %q has too many parts", device)
+	}
+
+	if !path.IsAbs(mapping.PathOnHost) || !path.IsAbs(mapping.PathInContainer) {
+		return mapping, fmt.Errorf("device %q paths must be absolute", device)
+	}
+
+	if !validDevicePermissions(mapping.CgroupPermissions) {
+		return mapping, fmt.Errorf("device %q permissions must be a combination of r, w and m", device)
+	}
+
+	return mapping, nil
+}
+
+// validDevicePermissions returns true if permissions is a combination of read (r), write (w) and mknod (m).
+func validDevicePermissions(permissions string) bool {
+	if permissions == "" || len(permissions) > 3 {
+		return false
+	}
+
+	for _, permission := range permissions {
+		if !strings.ContainsRune(defaultDevicePermissions, permission) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,37 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDevices(t *testing.T) {
+	devices, err := parseDevices([]string{"/dev/fuse", "/dev/kvm:r", "/dev/sda:/dev/xvda", "/dev/net/tun:/dev/tun:rw"})
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]container.DeviceMapping{
+			{PathOnHost: "/dev/fuse", PathInContainer: "/dev/fuse", CgroupPermissions: "rwm"},
+			{PathOnHost: "/dev/kvm", PathInContainer: "/dev/kvm", CgroupPermissions: "r"},
+			{PathOnHost: "/dev/sda", PathInContainer: "/dev/xvda", CgroupPermissions: "rwm"},
+			{PathOnHost: "/dev/net/tun", PathInContainer: "/dev/tun", CgroupPermissions: "rw"},
+		},
+		devices,
+	)
+}
+
+func TestParseDevicesErrors(t *testing.T) {
+	for _, device := range []string{"dev/fuse", "/dev/fuse:/dev/fuse:rwx", "/dev/a:/dev/b:rw:m", "/dev/fuse:fuse"} {
+		t.Run(device, func(t *testing.T) {
+			_, err := parseDevices([]string{device})
+
+			assert.True(t, errors.Is(err, ErrInvalidDeviceMapping))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
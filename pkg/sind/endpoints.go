@@ -0,0 +1,61 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// NodeEndpoint is the docker daemon endpoint of a cluster node.
+type NodeEndpoint struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// Host is the host to use in order to communicate with the node docker daemon, e.g. tcp://localhost:32768.
+	Host string `json:"host"`
+}
+
+// NodeEndpoints returns the docker daemon endpoints of the nodes of a cluster.
+// Only the primary node daemon is reachable from the docker host for a swarm cluster, all of them are for a plain one.
+func NodeEndpoints(ctx context.Context, hostClient *docker.Client, clusterName string) ([]NodeEndpoint, error) {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, ErrClusterNotFound
+	}
+
+	return nodeEndpoints(hostClient, clusterName, nodes)
+}
+
+func nodeEndpoints(hostClient *docker.Client, clusterName string, nodes []types.Container) ([]NodeEndpoint, error) {
+	var endpoints []NodeEndpoint
+
+	for _, node := range nodes {
+		role := node.Labels[internal.NodeRoleLabel]
+		if role != internal.NodeRolePrimary && node.Labels[internal.PlainClusterLabel] != "true" {
+			continue
+		}
+
+		if len(node.Names) == 0 {
+			return nil, fmt.Errorf("node %q has no name", node.ID)
+		}
+
+		host, err := nodeHost(hostClient, node)
+		if err != nil {
+			return nil, fmt.Errorf("unable to get the host of node %q: %w", node.ID, err)
+		}
+
+		endpoints = append(endpoints, NodeEndpoint{
+			Name: nodeName(clusterName, node.Names[0]),
+			Role: role,
+			Host: host,
+		})
+	}
+
+	return endpoints, nil
+}
@@ -0,0 +1,66 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeEndpoints(t *testing.T) {
+	hostClient, err := docker.NewClientWithOpts(docker.WithHost("unix:///var/run/docker.sock"))
+	require.NoError(t, err)
+
+	node := func(name, role string, port uint16, plain bool) types.Container {
+		labels := map[string]string{internal.NodeRoleLabel: role}
+		if plain {
+			labels[internal.PlainClusterLabel] = "true"
+		}
+
+		return types.Container{
+			ID:     name,
+			Names:  []string{"/" + name},
+			Labels: labels,
+			Ports:  []types.Port{{PrivatePort: 2375, PublicPort: port}},
+		}
+	}
+
+	testCases := []struct {
+		desc              string
+		nodes             []types.Container
+		expectedEndpoints []NodeEndpoint
+	}{
+		{
+			desc: "swarm cluster",
+			nodes: []types.Container{
+				node("sind-test-manager-0", internal.NodeRolePrimary, 32768, false),
+				node("sind-test-worker-0", internal.NodeRoleWorker, 0, false),
+			},
+			expectedEndpoints: []NodeEndpoint{
+				{Name: "manager-0", Role: internal.NodeRolePrimary, Host: "tcp://localhost:32768"},
+			},
+		},
+		{
+			desc: "plain cluster",
+			nodes: []types.Container{
+				node("sind-test-manager-0", internal.NodeRolePrimary, 32768, true),
+				node("sind-test-worker-0", internal.NodeRoleWorker, 32769, true),
+			},
+			expectedEndpoints: []NodeEndpoint{
+				{Name: "manager-0", Role: internal.NodeRolePrimary, Host: "tcp://localhost:32768"},
+				{Name: "worker-0", Role: internal.NodeRoleWorker, Host: "tcp://localhost:32769"},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			endpoints, err := nodeEndpoints(hostClient, "test", test.nodes)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedEndpoints, endpoints)
+		})
+	}
+}
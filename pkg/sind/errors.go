@@ -0,0 +1,213 @@
+package sind
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+var (
+	// ErrInvalidConfiguration is wrapped by all the cluster configuration validation errors.
+	ErrInvalidConfiguration = errors.New("invalid configuration")
+
+	// ErrEmptyClusterName is returned when a cluster configuration has no cluster name.
+	ErrEmptyClusterName = fmt.Errorf("%w: cluster name is required", ErrInvalidConfiguration)
+	// ErrEmptyNetworkName is returned when a cluster configuration has no network name.
+	ErrEmptyNetworkName = fmt.Errorf("%w: network name is required", ErrInvalidConfiguration)
+	// ErrInvalidManagerCount is returned when a cluster configuration has less than one manager.
+	ErrInvalidManagerCount = fmt.Errorf("%w: invalid manager count, must be >= 1", ErrInvalidConfiguration)
+	// ErrInvalidTotalMemory is returned when a cluster configuration has a negative memory budget.
+	ErrInvalidTotalMemory = fmt.Errorf("%w: invalid total memory, must be >= 0", ErrInvalidConfiguration)
+	// ErrInvalidTotalCPU is returned when a cluster configuration has a negative CPU budget.
+	ErrInvalidTotalCPU = fmt.Errorf("%w: invalid total CPU, must be >= 0", ErrInvalidConfiguration)
+	// ErrBudgetTooSmall is returned when a cluster configuration has a memory or CPU budget too small to give each node
+	// a share of it.
+	ErrBudgetTooSmall = fmt.Errorf("%w: budget too small to be divided across the nodes", ErrInvalidConfiguration)
+	// ErrInvalidPidsLimit is returned when a cluster configuration has a PIDs limit lower than -1.
+	ErrInvalidPidsLimit = fmt.Errorf("%w: invalid PIDs limit, must be >= -1", ErrInvalidConfiguration)
+	// ErrInvalidMemorySwap is returned when a cluster configuration has a memory and swap limit which can't be applied.
+	ErrInvalidMemorySwap = fmt.Errorf("%w: invalid memory swap", ErrInvalidConfiguration)
+	// ErrInvalidBlkioWeight is returned when a cluster configuration has a block IO weight out of the 10 to 1000 range.
+	ErrInvalidBlkioWeight = fmt.Errorf("%w: invalid block IO weight, must be between 10 and 1000", ErrInvalidConfiguration)
+	// ErrInvalidNetworkSubnet is returned when a cluster configuration has an invalid network subnet.
+	ErrInvalidNetworkSubnet = fmt.Errorf("%w: invalid network subnet", ErrInvalidConfiguration)
+	// ErrInvalidNetworkIPAM is returned when a cluster configuration has an invalid network gateway, IP range or auxiliary address.
+	ErrInvalidNetworkIPAM = fmt.Errorf("%w: invalid network IPAM configuration", ErrInvalidConfiguration)
+	// ErrInvalidNodeAddresses is returned when a cluster configuration has invalid, duplicate or missing node addresses.
+	ErrInvalidNodeAddresses = fmt.Errorf("%w: invalid node addresses", ErrInvalidConfiguration)
+	// ErrInvalidRoleRanges is returned when a cluster configuration partitions its subnet per role along with node addresses,
+	// a custom IPAM configuration or an existing network.
+	ErrInvalidRoleRanges = fmt.Errorf("%w: role address ranges require a network created without node addresses nor custom IPAM", ErrInvalidConfiguration)
+	// ErrInvalidPortBindings is returned when a cluster configuration has invalid port bindings or port ranges.
+	ErrInvalidPortBindings = fmt.Errorf("%w: invalid port bindings", ErrInvalidConfiguration)
+	// ErrInvalidDeviceMapping is returned when a cluster configuration has an invalid device mapping.
+	ErrInvalidDeviceMapping = fmt.Errorf("%w: invalid device mapping", ErrInvalidConfiguration)
+	// ErrAirGappedExistingNetwork is returned when a cluster configuration requests an air-gapped cluster attached to an
+	// existing network.
+	ErrAirGappedExistingNetwork = fmt.Errorf("%w: an air-gapped cluster requires a network created by sind", ErrInvalidConfiguration)
+	// ErrAirGappedPorts is returned when a cluster configuration requests an air-gapped cluster publishing ports.
+	ErrAirGappedPorts = fmt.Errorf("%w: an air-gapped cluster can't publish ports, nor use a load balancer", ErrInvalidConfiguration)
+	// ErrInvalidEgressAllowlist is returned when a cluster configuration has an invalid egress allowlist.
+	ErrInvalidEgressAllowlist = fmt.Errorf("%w: invalid egress allowlist", ErrInvalidConfiguration)
+	// ErrInvalidRegistryAuth is returned when a cluster configuration has registry credentials without registry address
+	// or username.
+	ErrInvalidRegistryAuth = fmt.Errorf("%w: invalid registry credentials, a registry address and a username are required", ErrInvalidConfiguration)
+	// ErrContentTrustServer is returned when a cluster configuration has a content trust server without content trust.
+	ErrContentTrustServer = fmt.Errorf("%w: a content trust server requires content trust", ErrInvalidConfiguration)
+	// ErrAdoptAndRecreate is returned when a cluster configuration requests both to adopt and to recreate an existing cluster.
+	ErrAdoptAndRecreate = fmt.Errorf("%w: an existing cluster can't be both adopted and recreated", ErrInvalidConfiguration)
+	// ErrPlainLoadBalancer is returned when a cluster configuration requests a load balancer without forming a swarm.
+	ErrPlainLoadBalancer = fmt.Errorf("%w: a load balancer requires a swarm, it can't be used with plain nodes", ErrInvalidConfiguration)
+	// ErrInvalidPublishPortsOn is returned when a cluster configuration publishes its ports on nodes other than the primary,
+	// the managers or all of them.
+	ErrInvalidPublishPortsOn = fmt.Errorf("%w: ports must be published on the primary, the managers or all the nodes", ErrInvalidConfiguration)
+	// ErrLoadBalancerPublishPorts is returned when a cluster configuration publishes its ports on several nodes while
+	// binding them on a load balancer.
+	ErrLoadBalancerPublishPorts = fmt.Errorf("%w: ports bound on a load balancer can't be published on the nodes", ErrInvalidConfiguration)
+	// ErrUnsupportedJoinOption is returned when joining an external swarm with plain nodes or a load balancer.
+	ErrUnsupportedJoinOption = fmt.Errorf("%w: plain nodes and load balancers can't join an external swarm", ErrInvalidConfiguration)
+	// ErrEmptyJoinToken is returned when joining an external swarm without join token.
+	ErrEmptyJoinToken = fmt.Errorf("%w: a join token is required", ErrInvalidConfiguration)
+	// ErrEmptyJoinAddress is returned when joining an external swarm without manager address.
+	ErrEmptyJoinAddress = fmt.Errorf("%w: at least one manager address is required", ErrInvalidConfiguration)
+	// ErrEmptyBakeImageRef is returned when baking a node image without image reference.
+	ErrEmptyBakeImageRef = fmt.Errorf("%w: a baked image reference is required", ErrInvalidConfiguration)
+	// ErrNoImageToBake is returned when baking a node image without any image to load.
+	ErrNoImageToBake = fmt.Errorf("%w: at least one image to bake is required", ErrInvalidConfiguration)
+	// ErrInvalidClusterCount is returned when creating less than one cluster at once.
+	ErrInvalidClusterCount = fmt.Errorf("%w: invalid cluster count, must be >= 1", ErrInvalidConfiguration)
+	// ErrBulkNetworkSubnet is returned when creating several clusters at once with a fixed network subnet.
+	ErrBulkNetworkSubnet = fmt.Errorf("%w: clusters created at once can't share a network subnet", ErrInvalidConfiguration)
+	// ErrBulkNodeAddresses is returned when creating several clusters at once with fixed node addresses.
+	ErrBulkNodeAddresses = fmt.Errorf("%w: clusters created at once can't share node addresses", ErrInvalidConfiguration)
+	// ErrBulkHostPort is returned when creating several clusters at once binding fixed ports of the docker host.
+	ErrBulkHostPort = fmt.Errorf("%w: clusters created at once can't bind the same host ports", ErrInvalidConfiguration)
+	// ErrInvalidAdoptSelection is returned when adopting a cluster without selecting its containers by name or by label, or both.
+	ErrInvalidAdoptSelection = fmt.Errorf("%w: containers to adopt must be selected either by name or by label", ErrInvalidConfiguration)
+	// ErrInvalidTTL is returned when a cluster configuration has a negative TTL.
+	ErrInvalidTTL = fmt.Errorf("%w: invalid TTL, must be >= 0", ErrInvalidConfiguration)
+	// ErrInvalidIdleTimeout is returned when a cluster configuration has a negative idle timeout.
+	ErrInvalidIdleTimeout = fmt.Errorf("%w: invalid idle timeout, must be >= 0", ErrInvalidConfiguration)
+	// ErrInvalidIdlePolicy is returned when auto stopping clusters with an idle policy other than no-activity or no-tasks.
+	ErrInvalidIdlePolicy = fmt.Errorf("%w: invalid idle policy, must be no-activity or no-tasks", ErrInvalidConfiguration)
+	// ErrInvalidAutoStopInterval is returned when auto stopping clusters with a negative interval.
+	ErrInvalidAutoStopInterval = fmt.Errorf("%w: invalid auto stop interval, must be >= 0", ErrInvalidConfiguration)
+	// ErrInvalidNodeAvailability is returned when a cluster configuration has a node availability other than active, drain or pause.
+	ErrInvalidNodeAvailability = fmt.Errorf("%w: invalid node availability, must be active, drain or pause", ErrInvalidConfiguration)
+	// ErrInvalidNodeRole is returned when selecting nodes with a role other than manager or worker.
+	ErrInvalidNodeRole = fmt.Errorf("%w: invalid node role, must be manager or worker", ErrInvalidConfiguration)
+	// ErrInvalidNodeIndex is returned when selecting a node with a negative index.
+	ErrInvalidNodeIndex = fmt.Errorf("%w: invalid node index, must be >= 0", ErrInvalidConfiguration)
+	// ErrInvalidTaskHistoryLimit is returned when updating the swarm with a negative task history limit.
+	ErrInvalidTaskHistoryLimit = fmt.Errorf("%w: invalid task history limit, must be >= 0", ErrInvalidConfiguration)
+	// ErrInvalidSwarmPeriod is returned when updating the swarm with a non positive certificate expiry or heartbeat period.
+	ErrInvalidSwarmPeriod = fmt.Errorf("%w: invalid certificate expiry or heartbeat period, must be > 0", ErrInvalidConfiguration)
+	// ErrInvalidDaemonConfig is returned when updating the daemon configuration of nodes with invalid JSON.
+	ErrInvalidDaemonConfig = fmt.Errorf("%w: invalid daemon configuration, must be valid JSON", ErrInvalidConfiguration)
+	// ErrDrainUnmanagedSwarm is returned when draining a plain cluster, or a cluster joined to an external swarm.
+	ErrDrainUnmanagedSwarm = fmt.Errorf("%w: only the nodes of a swarm formed by the cluster can be drained", ErrInvalidConfiguration)
+	// ErrNoExtensionHost is returned when extending a cluster without any additional docker host.
+	ErrNoExtensionHost = fmt.Errorf("%w: at least one docker host is required", ErrInvalidConfiguration)
+	// ErrEmptyHostNetwork is returned when extending a cluster on a docker host without network routed to the cluster.
+	ErrEmptyHostNetwork = fmt.Errorf("%w: a network routed to the cluster network is required on each docker host", ErrInvalidConfiguration)
+	// ErrInvalidHostNodeCount is returned when extending a cluster with no node on a docker host.
+	ErrInvalidHostNodeCount = fmt.Errorf("%w: invalid node count, must be >= 1 on each docker host", ErrInvalidConfiguration)
+	// ErrExtendUnmanagedSwarm is returned when extending a plain cluster, or a cluster joined to an external swarm.
+	ErrExtendUnmanagedSwarm = fmt.Errorf("%w: only a swarm formed by the cluster can be extended", ErrInvalidConfiguration)
+	// ErrScaleUnmanagedSwarm is returned when scaling a cluster which joined an external swarm.
+	ErrScaleUnmanagedSwarm = fmt.Errorf("%w: only a swarm formed by the cluster, or plain nodes, can be scaled", ErrInvalidConfiguration)
+	// ErrInvalidScale is returned when scaling a cluster down to no manager, or removing more workers than it has.
+	ErrInvalidScale = fmt.Errorf("%w: invalid scale, a cluster keeps its primary node and can't remove more nodes than it has", ErrInvalidConfiguration)
+	// ErrEmptyCommand is returned when running a command on the nodes of a cluster without command.
+	ErrEmptyCommand = fmt.Errorf("%w: a command is required", ErrInvalidConfiguration)
+	// ErrInvalidBootstrap is returned when a cluster configuration has an invalid bootstrap.
+	ErrInvalidBootstrap = fmt.Errorf("%w: invalid bootstrap", ErrInvalidConfiguration)
+	// ErrInvalidClusterSpec is returned when a cluster spec file can't be decoded.
+	ErrInvalidClusterSpec = fmt.Errorf("%w: invalid cluster spec", ErrInvalidConfiguration)
+	// ErrEmptyJobImage is returned when creating a job on a cluster without image.
+	ErrEmptyJobImage = fmt.Errorf("%w: a job image is required", ErrInvalidConfiguration)
+	// ErrInvalidGlobalJob is returned when creating a global job with completions or a maximum of concurrent tasks.
+	ErrInvalidGlobalJob = fmt.Errorf("%w: completions and max concurrent tasks only apply to replicated jobs", ErrInvalidConfiguration)
+	// ErrEmptyCopySource is returned when copying to the nodes of a cluster without local path.
+	ErrEmptyCopySource = fmt.Errorf("%w: a source path is required", ErrInvalidConfiguration)
+	// ErrInvalidCopyTarget is returned when copying to the nodes of a cluster at a relative path, or at the root.
+	ErrInvalidCopyTarget = fmt.Errorf("%w: invalid target path, must be absolute and not the root directory", ErrInvalidConfiguration)
+	// ErrEmptyCopyTarget is returned when copying from a node of a cluster without local path.
+	ErrEmptyCopyTarget = fmt.Errorf("%w: a target path is required", ErrInvalidConfiguration)
+	// ErrNoImageRef is returned when saving images from a node of a cluster without image reference.
+	ErrNoImageRef = fmt.Errorf("%w: at least one image reference is required", ErrInvalidConfiguration)
+	// ErrInvalidBandwidth is returned when throttling the nodes of a cluster with an invalid bandwidth.
+	ErrInvalidBandwidth = fmt.Errorf("%w: invalid bandwidth", ErrInvalidConfiguration)
+	// ErrSocketTLS is returned when publishing as a unix socket the docker API of a cluster secured with TLS.
+	ErrSocketTLS = fmt.Errorf("%w: the docker API of a cluster secured with TLS can't be published as a unix socket", ErrInvalidConfiguration)
+	// ErrInvalidGraphFormat is returned when writing the graph of a cluster in a format other than dot or mermaid.
+	ErrInvalidGraphFormat = fmt.Errorf("%w: invalid graph format, must be dot or mermaid", ErrInvalidConfiguration)
+
+	// ErrClusterNotFound is returned when an operation targets a cluster which does not exist on the docker host.
+	ErrClusterNotFound = internal.ErrPrimaryContainerNotFound
+
+	// ErrHookFailed is returned when a lifecycle hook of a cluster fails.
+	ErrHookFailed = errors.New("hook failed")
+
+	// ErrNotifierFailed is returned when a notifier fails to deliver a notification.
+	ErrNotifierFailed = errors.New("notifier failed")
+
+	// ErrClusterNotAdoptable is returned when a cluster with the same name exists but can't be adopted by CreateCluster.
+	ErrClusterNotAdoptable = errors.New("existing cluster can't be adopted")
+
+	// ErrClusterAlreadyExists is returned when creating a cluster, or adopting containers as a cluster, which already
+	// exists.
+	ErrClusterAlreadyExists = errors.New("cluster already exists")
+
+	// ErrNoContainerToAdopt is returned when the containers selected for adoption are not found.
+	ErrNoContainerToAdopt = errors.New("no container to adopt")
+
+	// ErrContainerNotAdoptable is returned when a container selected for adoption can't be adopted in a cluster.
+	ErrContainerNotAdoptable = errors.New("container can't be adopted")
+
+	// ErrNotSwarmNode is returned when the docker daemon of a container selected for adoption is not an active swarm node.
+	ErrNotSwarmNode = internal.ErrNotSwarmNode
+
+	// ErrInvalidArchiveEntry is returned when a file copied from a node holds an entry escaping the target path.
+	ErrInvalidArchiveEntry = internal.ErrInvalidArchiveEntry
+
+	// ErrClusterNotClaimable is returned when a provisioned cluster with the same name can't be claimed by CreateCluster.
+	ErrClusterNotClaimable = errors.New("provisioned cluster can't be claimed")
+
+	// ErrExternalSwarmNode is returned when healing a node which joined a swarm not managed by sind.
+	ErrExternalSwarmNode = errors.New("node joined an external swarm")
+
+	// ErrHostLocked is returned when another sind operation holds the lock of the docker host.
+	ErrHostLocked = internal.ErrLocked
+
+	// ErrNodeNotFound is returned when no node of the cluster matches a node selector.
+	ErrNodeNotFound = errors.New("node not found")
+
+	// ErrStackNotFound is returned when an operation targets a stack which is not deployed on the cluster.
+	ErrStackNotFound = errors.New("stack not found")
+
+	// ErrTLSDisabled is returned when getting the TLS material of a cluster which daemon port is not secured with TLS.
+	ErrTLSDisabled = errors.New("cluster daemon port is not secured with TLS")
+
+	// ErrPortNotPublished is returned when a service doesn't publish the requested port on the swarm ingress.
+	ErrPortNotPublished = internal.ErrPortNotPublished
+
+	// ErrTaskNotFound is returned when a service has no running task in the requested slot.
+	ErrTaskNotFound = internal.ErrTaskNotFound
+
+	// ErrNodeNotRunning is returned when a node container stops running while waiting for its docker daemon.
+	ErrNodeNotRunning = internal.ErrNodeNotRunning
+
+	// ErrNodeEgress is returned when a node of an air-gapped cluster has a default route to external networks.
+	ErrNodeEgress = internal.ErrNodeEgress
+
+	// ErrSwarmNodeDown is returned when a node of the swarm is down while waiting for the cluster to be ready.
+	ErrSwarmNodeDown = internal.ErrSwarmNodeDown
+
+	// ErrRuntimeNotFound is returned when the container runtime of the nodes is not configured on the docker host.
+	ErrRuntimeNotFound = internal.ErrRuntimeNotFound
+
+	// ErrServiceUpdateFailed is returned when swarm pauses or rolls back a service update.
+	ErrServiceUpdateFailed = internal.ErrServiceUpdateFailed
+)
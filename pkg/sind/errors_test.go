@@ -0,0 +1,397 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterConfigurationValidationErrors(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		config        ClusterConfiguration
+		expectedError error
+	}{
+		{
+			desc:          "without cluster name",
+			config:        ClusterConfiguration{NetworkName: "foo", Managers: 1},
+			expectedError: ErrEmptyClusterName,
+		},
+		{
+			desc:          "without network name",
+			config:        ClusterConfiguration{ClusterName: "foo", Managers: 1},
+			expectedError: ErrEmptyNetworkName,
+		},
+		{
+			desc:          "without managers",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo"},
+			expectedError: ErrInvalidManagerCount,
+		},
+		{
+			desc:          "with a negative memory budget",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, TotalMemory: -1},
+			expectedError: ErrInvalidTotalMemory,
+		},
+		{
+			desc:          "with a negative CPU budget",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, TotalCPU: -1},
+			expectedError: ErrInvalidTotalCPU,
+		},
+		{
+			desc:          "with a memory budget smaller than the node count",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Workers: 2, TotalMemory: 3},
+			expectedError: ErrBudgetTooSmall,
+		},
+		{
+			desc:          "with a CPU budget too small to be divided",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 3, TotalCPU: 1e-9},
+			expectedError: ErrBudgetTooSmall,
+		},
+		{
+			desc:          "with port ranges of different sizes",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, PortBindings: []string{"8000-8010:8000-8005"}},
+			expectedError: ErrInvalidPortBindings,
+		},
+		{
+			desc:          "air-gapped on an existing network",
+			config:        ClusterConfiguration{ClusterName: "foo", ExistingNetwork: "foo", Managers: 1, AirGapped: true},
+			expectedError: ErrAirGappedExistingNetwork,
+		},
+		{
+			desc:          "air-gapped with port bindings",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, AirGapped: true, PortBindings: []string{"8080:80"}},
+			expectedError: ErrAirGappedPorts,
+		},
+		{
+			desc:          "air-gapped with a load balancer",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, AirGapped: true, LoadBalancer: true},
+			expectedError: ErrAirGappedPorts,
+		},
+		{
+			desc:          "with an egress allowlist without restricting the egress",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, EgressAllowlist: []string{"10.1.0.5"}},
+			expectedError: ErrInvalidEgressAllowlist,
+		},
+		{
+			desc: "with an invalid egress allowlist",
+			config: ClusterConfiguration{
+				ClusterName:     "foo",
+				NetworkName:     "foo",
+				Managers:        1,
+				RestrictEgress:  true,
+				EgressAllowlist: []string{"10.1.0.0/16", "registry.local"},
+			},
+			expectedError: ErrInvalidEgressAllowlist,
+		},
+		{
+			desc: "with an IPv6 egress allowlist",
+			config: ClusterConfiguration{
+				ClusterName:     "foo",
+				NetworkName:     "foo",
+				Managers:        1,
+				RestrictEgress:  true,
+				EgressAllowlist: []string{"fd00::/64"},
+			},
+			expectedError: ErrInvalidEgressAllowlist,
+		},
+		{
+			desc: "with a restricted egress",
+			config: ClusterConfiguration{
+				ClusterName:     "foo",
+				NetworkName:     "foo",
+				Managers:        1,
+				RestrictEgress:  true,
+				EgressAllowlist: []string{"10.1.0.0/16", "192.168.1.10"},
+			},
+		},
+		{
+			desc: "with registry credentials without username",
+			config: ClusterConfiguration{
+				ClusterName:   "foo",
+				NetworkName:   "foo",
+				Managers:      1,
+				RegistryAuths: map[string]RegistryAuth{"registry.local:5000": {Password: "bar"}},
+			},
+			expectedError: ErrInvalidRegistryAuth,
+		},
+		{
+			desc:          "with a content trust server without content trust",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, ContentTrustServer: "https://notary.local"},
+			expectedError: ErrContentTrustServer,
+		},
+		{
+			desc:          "with an invalid PIDs limit",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, PidsLimit: -2},
+			expectedError: ErrInvalidPidsLimit,
+		},
+		{
+			desc:          "with an invalid block IO weight",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, BlkioWeight: 5},
+			expectedError: ErrInvalidBlkioWeight,
+		},
+		{
+			desc:          "with a memory swap and without total memory",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, MemorySwap: 1 << 30},
+			expectedError: ErrInvalidMemorySwap,
+		},
+		{
+			desc: "with a memory swap lower than the memory of the managers",
+			config: ClusterConfiguration{
+				ClusterName: "foo",
+				NetworkName: "foo",
+				Managers:    1,
+				Workers:     1,
+				TotalMemory: 4 << 30,
+				MemorySwap:  1 << 30,
+			},
+			expectedError: ErrInvalidMemorySwap,
+		},
+		{
+			desc: "with resource limits",
+			config: ClusterConfiguration{
+				ClusterName: "foo",
+				NetworkName: "foo",
+				Managers:    1,
+				Workers:     1,
+				TotalMemory: 4 << 30,
+				MemorySwap:  8 << 30,
+				PidsLimit:   -1,
+				BlkioWeight: 500,
+			},
+		},
+		{
+			desc:          "adopting and recreating an existing cluster",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, AdoptExisting: true, Recreate: true},
+			expectedError: ErrAdoptAndRecreate,
+		},
+		{
+			desc:          "with a load balancer and plain nodes",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Plain: true, LoadBalancer: true},
+			expectedError: ErrPlainLoadBalancer,
+		},
+		{
+			desc:          "publishing ports on invalid nodes",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, PublishPortsOn: "workers"},
+			expectedError: ErrInvalidPublishPortsOn,
+		},
+		{
+			desc:          "publishing ports on the managers and binding them on a load balancer",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, PublishPortsOn: PublishPortsOnManagers, LoadBalancer: true},
+			expectedError: ErrLoadBalancerPublishPorts,
+		},
+		{
+			desc:          "with an invalid network subnet",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, NetworkSubnet: "nope"},
+			expectedError: ErrInvalidNetworkSubnet,
+		},
+		{
+			desc:          "with a gateway and without subnet",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, NetworkGateway: "10.0.0.254"},
+			expectedError: ErrInvalidNetworkIPAM,
+		},
+		{
+			desc: "with a gateway outside of the subnet",
+			config: ClusterConfiguration{
+				ClusterName:    "foo",
+				NetworkName:    "foo",
+				Managers:       1,
+				NetworkSubnet:  "10.0.0.0/24",
+				NetworkGateway: "10.0.1.254",
+			},
+			expectedError: ErrInvalidNetworkIPAM,
+		},
+		{
+			desc: "with an invalid IP range",
+			config: ClusterConfiguration{
+				ClusterName:    "foo",
+				NetworkName:    "foo",
+				Managers:       1,
+				NetworkSubnet:  "10.0.0.0/24",
+				NetworkIPRange: "nope",
+			},
+			expectedError: ErrInvalidNetworkIPAM,
+		},
+		{
+			desc: "with an auxiliary address outside of the subnet",
+			config: ClusterConfiguration{
+				ClusterName:         "foo",
+				NetworkName:         "foo",
+				Managers:            1,
+				NetworkSubnet:       "10.0.0.0/24",
+				NetworkAuxAddresses: map[string]string{"router": "192.168.0.1"},
+			},
+			expectedError: ErrInvalidNetworkIPAM,
+		},
+		{
+			desc:          "with a subnet too small for the nodes",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 3, Workers: 4, NetworkSubnet: "10.0.0.0/29"},
+			expectedError: ErrInvalidNetworkSubnet,
+		},
+		{
+			desc:          "with node addresses and without subnet",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, NodeAddresses: []string{"10.0.0.10"}},
+			expectedError: ErrInvalidNodeAddresses,
+		},
+		{
+			desc: "with less node addresses than nodes",
+			config: ClusterConfiguration{
+				ClusterName:   "foo",
+				NetworkName:   "foo",
+				Managers:      1,
+				Workers:       1,
+				NetworkSubnet: "10.0.0.0/24",
+				NodeAddresses: []string{"10.0.0.10"},
+			},
+			expectedError: ErrInvalidNodeAddresses,
+		},
+		{
+			desc: "with a node address outside of the subnet",
+			config: ClusterConfiguration{
+				ClusterName:   "foo",
+				NetworkName:   "foo",
+				Managers:      1,
+				NetworkSubnet: "10.0.0.0/24",
+				NodeAddresses: []string{"10.0.1.10"},
+			},
+			expectedError: ErrInvalidNodeAddresses,
+		},
+		{
+			desc: "with a duplicate node address",
+			config: ClusterConfiguration{
+				ClusterName:   "foo",
+				NetworkName:   "foo",
+				Managers:      2,
+				NetworkSubnet: "10.0.0.0/24",
+				NodeAddresses: []string{"10.0.0.10", "10.0.0.10"},
+			},
+			expectedError: ErrInvalidNodeAddresses,
+		},
+		{
+			desc: "with node addresses",
+			config: ClusterConfiguration{
+				ClusterName:   "foo",
+				NetworkName:   "foo",
+				Managers:      1,
+				Workers:       1,
+				NetworkSubnet: "10.0.0.0/24",
+				NodeAddresses: []string{"10.0.0.10", "10.0.0.20"},
+			},
+		},
+		{
+			desc: "with role address ranges and node addresses",
+			config: ClusterConfiguration{
+				ClusterName:       "foo",
+				NetworkName:       "foo",
+				Managers:          1,
+				NetworkSubnet:     "10.0.0.0/24",
+				NodeAddresses:     []string{"10.0.0.10"},
+				RoleAddressRanges: true,
+			},
+			expectedError: ErrInvalidRoleRanges,
+		},
+		{
+			desc: "with role address ranges too small for the workers",
+			config: ClusterConfiguration{
+				ClusterName:       "foo",
+				NetworkName:       "foo",
+				Managers:          1,
+				Workers:           8,
+				NetworkSubnet:     "10.0.0.0/28",
+				RoleAddressRanges: true,
+			},
+			expectedError: ErrInvalidNetworkSubnet,
+		},
+		{
+			desc: "with role address ranges",
+			config: ClusterConfiguration{
+				ClusterName:       "foo",
+				NetworkName:       "foo",
+				Managers:          3,
+				Workers:           7,
+				NetworkSubnet:     "10.0.0.0/28",
+				RoleAddressRanges: true,
+			},
+		},
+		{
+			desc: "with a custom IPAM configuration",
+			config: ClusterConfiguration{
+				ClusterName:         "foo",
+				NetworkName:         "foo",
+				Managers:            1,
+				NetworkSubnet:       "10.0.0.0/24",
+				NetworkGateway:      "10.0.0.254",
+				NetworkIPRange:      "10.0.0.128/25",
+				NetworkAuxAddresses: map[string]string{"router": "10.0.0.253"},
+			},
+		},
+		{
+			desc:          "with a negative idle timeout",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, IdleTimeout: -time.Minute},
+			expectedError: ErrInvalidIdleTimeout,
+		},
+		{
+			desc:          "with a bootstrap on a plain cluster",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Plain: true, Bootstrap: Bootstrap{Networks: []BootstrapNetwork{{Name: "backend"}}}},
+			expectedError: ErrInvalidBootstrap,
+		},
+		{
+			desc:          "with labels on an unknown node",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Workers: 2, Bootstrap: Bootstrap{NodeLabels: map[string]map[string]string{"worker-2": {"zone": "a"}}}},
+			expectedError: ErrInvalidBootstrap,
+		},
+		{
+			desc:          "with an invalid bootstrap network subnet",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Bootstrap: Bootstrap{Networks: []BootstrapNetwork{{Name: "backend", Subnet: "10.0.0.0"}}}},
+			expectedError: ErrInvalidBootstrap,
+		},
+		{
+			desc:   "with a valid bootstrap",
+			config: ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Workers: 2, Bootstrap: Bootstrap{Networks: []BootstrapNetwork{{Name: "backend", Subnet: "10.1.0.0/24"}}, NodeLabels: map[string]map[string]string{"manager-0": {"zone": "a"}, "worker-1": {"zone": "b"}}}},
+		},
+		{
+			desc:          "with an invalid worker availability",
+			config:        ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, WorkerAvailability: "asleep"},
+			expectedError: ErrInvalidNodeAvailability,
+		},
+		{
+			desc:   "with drained workers",
+			config: ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, WorkerAvailability: "drain"},
+		},
+		{
+			desc:   "with a valid configuration",
+			config: ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1},
+		},
+		{
+			desc:   "with an existing network and without network name",
+			config: ClusterConfiguration{ClusterName: "foo", ExistingNetwork: "bar", Managers: 1},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.config.validate()
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
+
+func TestInspectClusterWrapsErrors(t *testing.T) {
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return nil, context.DeadlineExceeded
+	})
+
+	_, err := InspectCluster(context.Background(), client, "foo")
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
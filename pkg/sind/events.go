@@ -0,0 +1,143 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types/events"
+	docker "github.com/docker/docker/client"
+	"github.com/golang/sync/errgroup"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// Event is an event of a cluster, either a node container lifecycle event from the docker host, or an event emitted
+// by the docker daemon of a node.
+type Event struct {
+	// Node is the name of the node concerned by the event, e.g. manager-0.
+	Node string
+	// FromHost is true for node container events emitted by the docker host.
+	FromHost bool
+
+	events.Message
+}
+
+// EventsFilter restricts the events streamed by ClusterEvents. Empty fields match everything.
+type EventsFilter struct {
+	Nodes []string
+	Types []string
+}
+
+func (f EventsFilter) matchNode(node string) bool {
+	return matchAny(f.Nodes, node)
+}
+
+func (f EventsFilter) matchType(eventType string) bool {
+	return matchAny(f.Types, eventType)
+}
+
+func matchAny(values []string, value string) bool {
+	if len(values) == 0 {
+		return true
+	}
+
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ClusterEvents streams the aggregated events of a cluster to out, until the context is done.
+func ClusterEvents(ctx context.Context, hostClient *docker.Client, clusterName string, filter EventsFilter, out chan<- Event) error {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	if filter.matchType(events.ContainerEventType) {
+		errg.Go(func() error {
+			return streamHostEvents(groupCtx, hostClient, clusterName, filter, out)
+		})
+	}
+
+	for _, node := range nodes {
+		if len(node.Names) == 0 {
+			continue
+		}
+
+		cID, name := node.ID, nodeName(clusterName, node.Names[0])
+
+		if !filter.matchNode(name) {
+			continue
+		}
+
+		errg.Go(func() error {
+			return streamNodeEvents(groupCtx, hostClient, cID, name, filter, out)
+		})
+	}
+
+	return errg.Wait()
+}
+
+func streamHostEvents(ctx context.Context, hostClient *docker.Client, clusterName string, filter EventsFilter, out chan<- Event) error {
+	messages, errs := internal.HostEvents(ctx, hostClient, clusterName)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("unable to stream the docker host events: %w", err)
+		case msg := <-messages:
+			event := Event{Node: nodeName(clusterName, msg.Actor.Attributes["name"]), FromHost: true, Message: msg}
+
+			if !filter.matchNode(event.Node) {
+				continue
+			}
+
+			select {
+			case <-ctx.Done():
+				return nil
+			case out <- event:
+			}
+		}
+	}
+}
+
+func streamNodeEvents(ctx context.Context, hostClient *docker.Client, cID, name string, filter EventsFilter, out chan<- Event) error {
+	messages := make(chan events.Message)
+
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	errg.Go(func() error {
+		defer close(messages)
+
+		if err := internal.NodeEvents(groupCtx, hostClient, cID, filter.Types, messages); err != nil {
+			return fmt.Errorf("unable to stream the events of node %q: %w", name, err)
+		}
+
+		return nil
+	})
+
+	errg.Go(func() error {
+		for msg := range messages {
+			select {
+			case <-groupCtx.Done():
+				return nil
+			case out <- Event{Node: name, Message: msg}:
+			}
+		}
+
+		return nil
+	})
+
+	return errg.Wait()
+}
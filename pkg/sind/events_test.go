@@ -0,0 +1,21 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventsFilter(t *testing.T) {
+	var empty EventsFilter
+
+	assert.True(t, empty.matchNode("manager-0"))
+	assert.True(t, empty.matchType("service"))
+
+	filter := EventsFilter{Nodes: []string{"manager-0", "worker-1"}, Types: []string{"container"}}
+
+	assert.True(t, filter.matchNode("worker-1"))
+	assert.False(t, filter.matchNode("worker-0"))
+	assert.True(t, filter.matchType("container"))
+	assert.False(t, filter.matchType("service"))
+}
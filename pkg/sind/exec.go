@@ -0,0 +1,66 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// ExecResult is the outcome of a command run on a node of a cluster by ExecAll.
+type ExecResult struct {
+	Node     string `json:"node"`
+	Role     string `json:"role"`
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout"`
+	Stderr   string `json:"stderr"`
+	// Err is the error which prevented the command from running on the node, ExitCode is meaningless if it is set.
+	Err error `json:"-"`
+}
+
+// ExecAll runs a command on every node of a cluster concurrently, and returns its outcome on each node, sorted by node
+// name. A command exiting with a non zero code or failing to run on a node is reported in the result of the node, it
+// doesn't stop the command on the other nodes.
+func ExecAll(ctx context.Context, hostClient *docker.Client, clusterName string, cmd []string) ([]ExecResult, error) {
+	if len(cmd) == 0 {
+		return nil, ErrEmptyCommand
+	}
+
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, ErrClusterNotFound
+	}
+
+	ctx, span := internal.StartSpan(ctx, "sind.exec", map[string]string{internal.ClusterAttribute: clusterName})
+	defer span.End(nil)
+
+	return execResults(clusterName, nodes, internal.BroadcastExec(ctx, hostClient, nodes, cmd)), nil
+}
+
+// execResults returns the outcomes of a command on the nodes of a cluster, given in the order of the nodes, sorted by
+// node name.
+func execResults(clusterName string, nodes []types.Container, outcomes []internal.ExecResult) []ExecResult {
+	results := make([]ExecResult, len(nodes))
+
+	for i, node := range nodes {
+		results[i] = ExecResult{
+			Node:     nodeName(clusterName, containerName(node)),
+			Role:     node.Labels[internal.NodeRoleLabel],
+			ExitCode: outcomes[i].ExitCode,
+			Stdout:   outcomes[i].Stdout,
+			Stderr:   outcomes[i].Stderr,
+			Err:      outcomes[i].Err,
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Node < results[j].Node })
+
+	return results
+}
@@ -0,0 +1,32 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecResults(t *testing.T) {
+	nodes := []types.Container{
+		{ID: "w0", Names: []string{"/sind-test-worker-0"}, Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRoleWorker}},
+		{ID: "m0", Names: []string{"/sind-test-manager-0"}, Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary}},
+	}
+	execErr := errors.New("container not running")
+
+	results := execResults("test", nodes, []internal.ExecResult{
+		{ContainerID: "w0", Err: execErr},
+		{ContainerID: "m0", ExitCode: 1, Stdout: "out", Stderr: "err"},
+	})
+
+	assert.Equal(
+		t,
+		[]ExecResult{
+			{Node: "manager-0", Role: internal.NodeRolePrimary, ExitCode: 1, Stdout: "out", Stderr: "err"},
+			{Node: "worker-0", Role: internal.NodeRoleWorker, Err: execErr},
+		},
+		results,
+	)
+}
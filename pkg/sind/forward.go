@@ -0,0 +1,99 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// PortForwardConfig is the configuration of a port forwarding to a service deployed on a cluster.
+type PortForwardConfig struct {
+	Service string
+	// Slot targets the task of the service running in this slot instead of the service VIP, if not zero.
+	Slot int
+
+	HostPort   uint16
+	TargetPort uint16
+}
+
+// PortForward forwards the port HostPort of the docker host to the port TargetPort of a service, or of one of its tasks,
+// even if the service doesn't publish it. Traffic goes through a port proxy to a forwarder service attached to the
+// networks of the target service. It returns a function removing the proxy and the forwarder.
+func PortForward(ctx context.Context, hostClient *docker.Client, clusterName string, cfg PortForwardConfig) (func(context.Context) error, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	networks, err := internal.ServiceNetworks(ctx, swarmClient, cfg.Service)
+	if err != nil {
+		swarmClient.Close()
+		return nil, err
+	}
+
+	if len(networks) == 0 {
+		swarmClient.Close()
+		return nil, fmt.Errorf("service %q is not attached to any network the forwarder can join", cfg.Service)
+	}
+
+	target := cfg.Service
+
+	if cfg.Slot != 0 {
+		target, err = internal.TaskAddress(ctx, swarmClient, cfg.Service, cfg.Slot, networks[0])
+		if err != nil {
+			swarmClient.Close()
+			return nil, err
+		}
+	}
+
+	forwarderID, ingressPort, err := internal.CreateForwarder(ctx, swarmClient, cfg.forwarderConfig(networks, target))
+
+	removeForwarder := func(ctx context.Context) error {
+		defer swarmClient.Close()
+
+		if err := swarmClient.ServiceRemove(ctx, forwarderID); err != nil {
+			return fmt.Errorf("unable to remove the forwarder service: %w", err)
+		}
+
+		return nil
+	}
+
+	if err != nil {
+		if forwarderID != "" {
+			_ = removeForwarder(ctx)
+		} else {
+			swarmClient.Close()
+		}
+
+		return nil, err
+	}
+
+	proxyID, err := publishPort(ctx, hostClient, clusterName, cfg.HostPort, ingressPort, "")
+	if err != nil {
+		_ = removeForwarder(ctx)
+		return nil, err
+	}
+
+	return func(ctx context.Context) error {
+		if err := hostClient.ContainerRemove(ctx, proxyID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			_ = removeForwarder(ctx)
+			return fmt.Errorf("unable to remove the port proxy: %w", err)
+		}
+
+		return removeForwarder(ctx)
+	}, nil
+}
+
+// forwarderConfig returns the configuration of the forwarder service reaching target on given networks.
+func (c *PortForwardConfig) forwarderConfig(networks []string, target string) internal.ForwarderConfig {
+	return internal.ForwarderConfig{
+		Name:       fmt.Sprintf("sind-port-forward-%d", c.HostPort),
+		ImageRef:   internal.DefaultProxyImageName,
+		Networks:   networks,
+		TargetHost: target,
+		TargetPort: c.TargetPort,
+	}
+}
@@ -0,0 +1,24 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPortForwardConfigForwarderConfig(t *testing.T) {
+	cfg := PortForwardConfig{Service: "web", Slot: 2, HostPort: 8080, TargetPort: 80}
+
+	assert.Equal(
+		t,
+		internal.ForwarderConfig{
+			Name:       "sind-port-forward-8080",
+			ImageRef:   internal.DefaultProxyImageName,
+			Networks:   []string{"front", "back"},
+			TargetHost: "10.0.1.5",
+			TargetPort: 80,
+		},
+		cfg.forwarderConfig([]string{"front", "back"}, "10.0.1.5"),
+	)
+}
@@ -0,0 +1,92 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	docker "github.com/docker/docker/client"
+)
+
+// GCCriteria selects the clusters to garbage collect. A cluster is collected if it matches all the given criteria.
+type GCCriteria struct {
+	// OlderThan matches clusters created more than the given duration ago.
+	OlderThan time.Duration
+	// Stopped matches clusters which have no running nodes.
+	Stopped bool
+	// Labels matches clusters having all the given labels.
+	Labels map[string]string
+	// Expired matches clusters which TTL is elapsed.
+	Expired bool
+	// Namespace restricts the collection to the clusters created in the given namespace, if any.
+	Namespace string
+}
+
+func (c GCCriteria) empty() bool {
+	return c.OlderThan == 0 && !c.Stopped && len(c.Labels) == 0 && !c.Expired
+}
+
+func (c GCCriteria) matches(cluster ClusterStatus, now time.Time) bool {
+	if c.OlderThan > 0 && now.Sub(cluster.CreatedAt) < c.OlderThan {
+		return false
+	}
+
+	if c.Stopped && (cluster.ManagersRunning > 0 || cluster.WorkersRunning > 0) {
+		return false
+	}
+
+	for key, value := range c.Labels {
+		if cluster.Labels[key] != value {
+			return false
+		}
+	}
+
+	if c.Expired && !cluster.Expired(now) {
+		return false
+	}
+
+	if c.Namespace != "" && cluster.Namespace != c.Namespace {
+		return false
+	}
+
+	return true
+}
+
+// SelectClusters returns the clusters matching given criteria at given time.
+func SelectClusters(clusters []ClusterStatus, criteria GCCriteria, now time.Time) []ClusterStatus {
+	var selected []ClusterStatus
+
+	for _, cluster := range clusters {
+		if criteria.matches(cluster, now) {
+			selected = append(selected, cluster)
+		}
+	}
+
+	return selected
+}
+
+// GarbageCollect deletes all the clusters matching given criteria, and returns their names.
+func GarbageCollect(ctx context.Context, client *docker.Client, criteria GCCriteria) ([]string, error) {
+	if criteria.empty() {
+		return nil, errors.New("at least one garbage collection criteria is required")
+	}
+
+	clusters, err := ListClusters(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list clusters: %w", err)
+	}
+
+	var deleted []string
+
+	for _, cluster := range SelectClusters(clusters, criteria, time.Now()) {
+		// Collected clusters are abandoned, there is no point in gracefully tearing them down.
+		if err := DeleteClusterWithOptions(ctx, client, cluster.Name, DeleteOptions{Force: true}); err != nil {
+			return deleted, fmt.Errorf("unable to delete cluster %q: %w", cluster.Name, err)
+		}
+
+		deleted = append(deleted, cluster.Name)
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,92 @@
+package sind
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelectClusters(t *testing.T) {
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	clusters := []ClusterStatus{
+		{
+			Name:            "running-old",
+			CreatedAt:       now.Add(-48 * time.Hour),
+			ManagersRunning: 1,
+			Labels:          map[string]string{"team": "payments"},
+			Namespace:       "ci-1",
+		},
+		{
+			Name:      "stopped-old",
+			CreatedAt: now.Add(-48 * time.Hour),
+			Labels:    map[string]string{"team": "search"},
+		},
+		{
+			Name:            "running-recent",
+			CreatedAt:       now.Add(-time.Hour),
+			ManagersRunning: 1,
+			Labels:          map[string]string{"team": "payments"},
+		},
+		{
+			Name:            "expired",
+			CreatedAt:       now.Add(-3 * time.Hour),
+			ExpiresAt:       now.Add(-time.Hour),
+			ManagersRunning: 1,
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		criteria      GCCriteria
+		expectedNames []string
+	}{
+		{
+			desc:          "older than",
+			criteria:      GCCriteria{OlderThan: 24 * time.Hour},
+			expectedNames: []string{"running-old", "stopped-old"},
+		},
+		{
+			desc:          "stopped",
+			criteria:      GCCriteria{Stopped: true},
+			expectedNames: []string{"stopped-old"},
+		},
+		{
+			desc:          "with labels",
+			criteria:      GCCriteria{Labels: map[string]string{"team": "payments"}},
+			expectedNames: []string{"running-old", "running-recent"},
+		},
+		{
+			desc:          "expired",
+			criteria:      GCCriteria{Expired: true},
+			expectedNames: []string{"expired"},
+		},
+		{
+			desc:          "combined",
+			criteria:      GCCriteria{OlderThan: 24 * time.Hour, Labels: map[string]string{"team": "payments"}},
+			expectedNames: []string{"running-old"},
+		},
+		{
+			desc:          "in a namespace",
+			criteria:      GCCriteria{OlderThan: 24 * time.Hour, Namespace: "ci-1"},
+			expectedNames: []string{"running-old"},
+		},
+		{
+			desc:     "matching nothing",
+			criteria: GCCriteria{Stopped: true, Labels: map[string]string{"team": "payments"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var names []string
+
+			for _, cluster := range SelectClusters(clusters, test.criteria, now) {
+				names = append(names, cluster.Name)
+			}
+
+			assert.Equal(t, test.expectedNames, names)
+		})
+	}
+}
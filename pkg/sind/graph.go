@@ -0,0 +1,233 @@
+package sind
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// GraphFormat is the language a cluster graph is written in.
+type GraphFormat string
+
+// Graph formats.
+const (
+	// GraphDOT is the Graphviz DOT language.
+	GraphDOT GraphFormat = "dot"
+	// GraphMermaid is the mermaid flowchart syntax, rendered by most markdown viewers.
+	GraphMermaid GraphFormat = "mermaid"
+)
+
+// Graph describes the nodes of a cluster, with their role and published ports, and the networks they are attached to.
+type Graph struct {
+	ClusterName string
+	// PortBindings are the port bindings of the cluster, see ClusterStatus.
+	PortBindings []string
+
+	Nodes    []GraphNode
+	Networks []GraphNetwork
+}
+
+// GraphNode is a node of a cluster graph.
+type GraphNode struct {
+	// Name is the name of the node, e.g. manager-0.
+	Name  string
+	Role  string
+	State string
+	// Ports are the ports of the node published on the docker host, as published:private/protocol.
+	Ports []string
+	// Addresses maps the names of the networks the node is attached to to its address on them.
+	Addresses map[string]string
+}
+
+// GraphNetwork is a network of a cluster graph.
+type GraphNetwork struct {
+	Name string
+	// Nodes are the names of the nodes attached to the network.
+	Nodes []string
+}
+
+// ClusterGraph returns the graph of the nodes and networks of a cluster, sorted by name.
+func ClusterGraph(cluster ClusterStatus) Graph {
+	graph := Graph{ClusterName: cluster.Name, PortBindings: cluster.PortBindings}
+	networks := make(map[string][]string)
+
+	for _, container := range cluster.Nodes {
+		node := GraphNode{
+			Name:      nodeName(cluster.Name, containerName(container)),
+			Role:      container.Labels[internal.NodeRoleLabel],
+			State:     container.State,
+			Addresses: make(map[string]string),
+		}
+
+		// Ports published on both IPv4 and IPv6 are listed twice.
+		published := make(map[string]bool)
+
+		for _, port := range container.Ports {
+			binding := fmt.Sprintf("%d:%d/%s", port.PublicPort, port.PrivatePort, port.Type)
+
+			if port.PublicPort == 0 || published[binding] {
+				continue
+			}
+
+			published[binding] = true
+			node.Ports = append(node.Ports, binding)
+		}
+
+		sort.Strings(node.Ports)
+
+		if container.NetworkSettings != nil {
+			for name, endpoint := range container.NetworkSettings.Networks {
+				node.Addresses[name] = endpoint.IPAddress
+				networks[name] = append(networks[name], node.Name)
+			}
+		}
+
+		graph.Nodes = append(graph.Nodes, node)
+	}
+
+	for name, nodes := range networks {
+		sort.Strings(nodes)
+		graph.Networks = append(graph.Networks, GraphNetwork{Name: name, Nodes: nodes})
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool { return graph.Nodes[i].Name < graph.Nodes[j].Name })
+	sort.Slice(graph.Networks, func(i, j int) bool { return graph.Networks[i].Name < graph.Networks[j].Name })
+
+	return graph
+}
+
+// Write writes the graph to out in given format.
+func (g Graph) Write(out io.Writer, format GraphFormat) error {
+	var b strings.Builder
+
+	switch format {
+	case GraphDOT:
+		g.writeDOT(&b)
+	case GraphMermaid:
+		g.writeMermaid(&b)
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidGraphFormat, format)
+	}
+
+	_, err := io.WriteString(out, b.String())
+
+	return err
+}
+
+// roleColors are the colors of the nodes, by role.
+var roleColors = map[string]string{
+	internal.NodeRolePrimary: "red",
+	internal.NodeRoleManager: "blue",
+	internal.NodeRoleWorker:  "darkgreen",
+}
+
+func (g Graph) writeDOT(b *strings.Builder) {
+	fmt.Fprintf(b, "graph %q {\n", g.ClusterName)
+	fmt.Fprintf(b, "  label=%q;\n", g.title())
+	fmt.Fprintf(b, "  node [shape=box];\n")
+
+	for _, network := range g.Networks {
+		fmt.Fprintf(b, "  %q [shape=ellipse, label=%q];\n", "network:"+network.Name, network.Name)
+	}
+
+	for _, node := range g.Nodes {
+		style := "solid"
+		if node.State != "running" {
+			style = "dashed"
+		}
+
+		fmt.Fprintf(
+			b,
+			"  %q [label=%q, color=%q, style=%q];\n",
+			node.Name,
+			strings.Join(node.labelLines(), "\n"),
+			roleColors[node.Role],
+			style,
+		)
+	}
+
+	for _, network := range g.Networks {
+		for _, name := range network.Nodes {
+			fmt.Fprintf(b, "  %q -- %q [label=%q];\n", name, "network:"+network.Name, g.address(name, network.Name))
+		}
+	}
+
+	b.WriteString("}\n")
+}
+
+func (g Graph) writeMermaid(b *strings.Builder) {
+	b.WriteString("graph LR\n")
+	fmt.Fprintf(b, "  %%%% %s\n", g.title())
+
+	ids := make(map[string]string, len(g.Nodes))
+
+	for i, node := range g.Nodes {
+		ids[node.Name] = fmt.Sprintf("node%d", i)
+		fmt.Fprintf(b, "  %s[%q]\n", ids[node.Name], strings.Join(node.labelLines(), "<br/>"))
+	}
+
+	for i, network := range g.Networks {
+		fmt.Fprintf(b, "  network%d((%q))\n", i, network.Name)
+
+		for _, name := range network.Nodes {
+			// Mermaid rejects empty edge labels.
+			if address := g.address(name, network.Name); address != "" {
+				fmt.Fprintf(b, "  %s ---|%s| network%d\n", ids[name], address, i)
+			} else {
+				fmt.Fprintf(b, "  %s --- network%d\n", ids[name], i)
+			}
+		}
+	}
+
+	for _, role := range []string{internal.NodeRolePrimary, internal.NodeRoleManager, internal.NodeRoleWorker} {
+		fmt.Fprintf(b, "  classDef %s stroke:%s\n", role, roleColors[role])
+	}
+
+	b.WriteString("  classDef stopped stroke-dasharray:5\n")
+
+	for _, node := range g.Nodes {
+		if node.Role != "" {
+			fmt.Fprintf(b, "  class %s %s\n", ids[node.Name], node.Role)
+		}
+
+		if node.State != "running" {
+			fmt.Fprintf(b, "  class %s stopped\n", ids[node.Name])
+		}
+	}
+}
+
+// title is the description of the cluster heading the graph.
+func (g Graph) title() string {
+	title := "cluster " + g.ClusterName
+
+	if len(g.PortBindings) > 0 {
+		title += ", ports " + strings.Join(g.PortBindings, ", ")
+	}
+
+	return title
+}
+
+// address returns the address of a node on a network.
+func (g Graph) address(nodeName, networkName string) string {
+	for _, node := range g.Nodes {
+		if node.Name == nodeName {
+			return node.Addresses[networkName]
+		}
+	}
+
+	return ""
+}
+
+// labelLines are the lines of the label of a node: its name, role, state if not running and published ports.
+func (n GraphNode) labelLines() []string {
+	lines := []string{n.Name, n.Role}
+
+	if n.State != "running" {
+		lines = append(lines, n.State)
+	}
+
+	return append(lines, n.Ports...)
+}
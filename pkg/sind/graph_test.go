@@ -0,0 +1,131 @@
+package sind
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testGraphCluster() ClusterStatus {
+	node := func(name, role, state, address string, ports ...types.Port) types.Container {
+		return types.Container{
+			Names:  []string{"/sind-foo-" + name},
+			State:  state,
+			Labels: map[string]string{internal.NodeRoleLabel: role},
+			Ports:  ports,
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{"foo-net": {IPAddress: address}},
+			},
+		}
+	}
+
+	return ClusterStatus{
+		Name:         "foo",
+		PortBindings: []string{"8080:80/tcp"},
+		Nodes: []types.Container{
+			node("worker-0", internal.NodeRoleWorker, "exited", ""),
+			node(
+				"primary-0",
+				internal.NodeRolePrimary,
+				"running",
+				"10.0.0.2",
+				types.Port{IP: "0.0.0.0", PublicPort: 32768, PrivatePort: 2375, Type: "tcp"},
+				types.Port{IP: "::", PublicPort: 32768, PrivatePort: 2375, Type: "tcp"},
+				types.Port{PrivatePort: 2376, Type: "tcp"},
+			),
+		},
+	}
+}
+
+func TestClusterGraph(t *testing.T) {
+	graph := ClusterGraph(testGraphCluster())
+
+	assert.Equal(
+		t,
+		Graph{
+			ClusterName:  "foo",
+			PortBindings: []string{"8080:80/tcp"},
+			Nodes: []GraphNode{
+				{
+					Name:      "primary-0",
+					Role:      internal.NodeRolePrimary,
+					State:     "running",
+					Ports:     []string{"32768:2375/tcp"},
+					Addresses: map[string]string{"foo-net": "10.0.0.2"},
+				},
+				{
+					Name:      "worker-0",
+					Role:      internal.NodeRoleWorker,
+					State:     "exited",
+					Addresses: map[string]string{"foo-net": ""},
+				},
+			},
+			Networks: []GraphNetwork{{Name: "foo-net", Nodes: []string{"primary-0", "worker-0"}}},
+		},
+		graph,
+	)
+}
+
+func TestGraphWrite(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		format   GraphFormat
+		expected string
+	}{
+		{
+			desc:   "dot",
+			format: GraphDOT,
+			expected: `graph "foo" {
+  label="cluster foo, ports 8080:80/tcp";
+  node [shape=box];
+  "network:foo-net" [shape=ellipse, label="foo-net"];
+  "primary-0" [label="primary-0\nprimary\n32768:2375/tcp", color="red", style="solid"];
+  "worker-0" [label="worker-0\nworker\nexited", color="darkgreen", style="dashed"];
+  "primary-0" -- "network:foo-net" [label="10.0.0.2"];
+  "worker-0" -- "network:foo-net" [label=""];
+}
+`,
+		},
+		{
+			desc:   "mermaid",
+			format: GraphMermaid,
+			expected: `graph LR
+  %% cluster foo, ports 8080:80/tcp
+  node0["primary-0<br/>primary<br/>32768:2375/tcp"]
+  node1["worker-0<br/>worker<br/>exited"]
+  network0(("foo-net"))
+  node0 ---|10.0.0.2| network0
+  node1 --- network0
+  classDef primary stroke:red
+  classDef manager stroke:blue
+  classDef worker stroke:darkgreen
+  classDef stopped stroke-dasharray:5
+  class node0 primary
+  class node1 worker
+  class node1 stopped
+`,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var out strings.Builder
+
+			require.NoError(t, ClusterGraph(testGraphCluster()).Write(&out, test.format))
+			assert.Equal(t, test.expected, out.String())
+		})
+	}
+}
+
+func TestGraphWriteInvalidFormat(t *testing.T) {
+	var out strings.Builder
+
+	err := ClusterGraph(testGraphCluster()).Write(&out, "svg")
+	assert.True(t, errors.Is(err, ErrInvalidGraphFormat))
+}
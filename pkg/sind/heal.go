@@ -0,0 +1,225 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/golang/sync/errgroup"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// HealReport is the outcome of the healing of a node by AutoHealCluster.
+type HealReport struct {
+	Node string
+	Err  error
+}
+
+// HealCluster heals the nodes of a cluster which died unexpectedly, and returns the names of the healed nodes.
+// See HealNode.
+func HealCluster(ctx context.Context, hostClient *docker.Client, clusterName string) ([]string, error) {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	var healed []string
+
+	// The primary goes first, the other nodes rejoin the swarm through it.
+	for _, node := range sortedByRole(nodes) {
+		if !diedUnexpectedly(node) {
+			continue
+		}
+
+		if err = HealNode(ctx, hostClient, clusterName, node); err != nil {
+			return healed, err
+		}
+
+		healed = append(healed, nodeName(clusterName, node.Names[0]))
+	}
+
+	return healed, nil
+}
+
+// HealNode recreates a dead node of a cluster with a fresh docker daemon, and makes it rejoin the swarm in place of the
+// dead one. The primary node, which the sind swarm is managed through, is restarted with its state instead.
+// Nodes of clusters which joined an external swarm can't be healed, their join token is not known by sind.
+// The load balancer of the cluster, if any, is reloaded with the address of the healed node.
+func HealNode(ctx context.Context, hostClient *docker.Client, clusterName string, node types.Container) error {
+	if err := healNode(ctx, hostClient, clusterName, node); err != nil {
+		return err
+	}
+
+	return reloadLoadBalancer(ctx, hostClient, clusterName)
+}
+
+func healNode(ctx context.Context, hostClient *docker.Client, clusterName string, node types.Container) error {
+	if len(node.Names) == 0 {
+		return fmt.Errorf("node %q has no name", node.ID)
+	}
+
+	hostname := strings.TrimPrefix(node.Names[0], "/")
+	role := node.Labels[internal.NodeRoleLabel]
+
+	if role == internal.NodeRolePrimary {
+		if err := hostClient.ContainerStart(ctx, node.ID, types.ContainerStartOptions{}); err != nil {
+			return fmt.Errorf("unable to restart the primary node: %w", err)
+		}
+
+		if err := internal.WaitNodesReady(ctx, hostClient, []types.Container{node}); err != nil {
+			return fmt.Errorf("unable to wait for the daemon of the primary node: %w", err)
+		}
+
+		return nil
+	}
+
+	if _, ok := node.Labels[internal.ExternalSwarmLabel]; ok {
+		return fmt.Errorf("unable to heal node %q: %w", hostname, ErrExternalSwarmNode)
+	}
+
+	cID, err := internal.RecreateNode(ctx, hostClient, node.ID)
+	if err != nil {
+		return err
+	}
+
+	if node.Labels[internal.PlainClusterLabel] == "true" {
+		return nil
+	}
+
+	if err = internal.WaitNodesReady(ctx, hostClient, []types.Container{{ID: cID}}); err != nil {
+		return fmt.Errorf("unable to wait for the daemon of node %q: %w", hostname, err)
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	swarmInfo, err := swarmClient.SwarmInspect(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to collect swarm cluster informations: %w", err)
+	}
+
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list cluster nodes: %w", err)
+	}
+
+	clusterConfig := internal.ClusterParams{
+		ManagerIPs:       internal.ManagerIPs(withoutNode(nodes, cID)),
+		ManagerJoinToken: swarmInfo.JoinTokens.Manager,
+		WorkerJoinToken:  swarmInfo.JoinTokens.Worker,
+	}
+
+	if role == internal.NodeRoleManager {
+		clusterConfig.IDs.Managers = []string{cID}
+	} else {
+		clusterConfig.IDs.Workers = []string{cID}
+	}
+
+	if err = internal.FormCluster(ctx, hostClient, clusterConfig); err != nil {
+		return fmt.Errorf("unable to make node %q rejoin the swarm: %w", hostname, err)
+	}
+
+	if err = internal.RemoveReplacedSwarmNodes(ctx, swarmClient, hostname); err != nil {
+		return fmt.Errorf("unable to remove the dead node %q from the swarm: %w", hostname, err)
+	}
+
+	return nil
+}
+
+// AutoHealCluster heals the nodes of a cluster as soon as they die unexpectedly, and reports each healing to out,
+// until the context is done.
+func AutoHealCluster(ctx context.Context, hostClient *docker.Client, clusterName string, out chan<- HealReport) error {
+	notifications := make(chan Notification)
+
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	errg.Go(func() error {
+		return WatchCluster(groupCtx, hostClient, clusterName, notifications)
+	})
+
+	errg.Go(func() error {
+		for {
+			select {
+			case <-groupCtx.Done():
+				return nil
+			case notification := <-notifications:
+				// Nodes stopped by sind exit cleanly.
+				if notification.Kind != NodeDied || notification.ExitCode == 0 {
+					continue
+				}
+
+				report := HealReport{
+					Node: notification.Node,
+					Err:  healContainer(groupCtx, hostClient, clusterName, notification.ContainerID),
+				}
+
+				select {
+				case <-groupCtx.Done():
+					return nil
+				case out <- report:
+				}
+			}
+		}
+	})
+
+	return errg.Wait()
+}
+
+func healContainer(ctx context.Context, hostClient *docker.Client, clusterName, cID string) error {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	for _, node := range nodes {
+		if node.ID == cID {
+			return HealNode(ctx, hostClient, clusterName, node)
+		}
+	}
+
+	return errors.New("node container not found")
+}
+
+// diedUnexpectedly returns true if the node container exited with an error, e.g. "Exited (137) 5 seconds ago".
+func diedUnexpectedly(node types.Container) bool {
+	switch node.State {
+	case "dead":
+		return true
+	case "exited":
+		return !strings.HasPrefix(node.Status, "Exited (0)")
+	default:
+		return false
+	}
+}
+
+func sortedByRole(nodes []types.Container) []types.Container {
+	result := make([]types.Container, 0, len(nodes))
+
+	for _, node := range nodes {
+		if node.Labels[internal.NodeRoleLabel] == internal.NodeRolePrimary {
+			result = append([]types.Container{node}, result...)
+			continue
+		}
+
+		result = append(result, node)
+	}
+
+	return result
+}
+
+func withoutNode(nodes []types.Container, cID string) []types.Container {
+	result := make([]types.Container, 0, len(nodes))
+
+	for _, node := range nodes {
+		if node.ID != cID {
+			result = append(result, node)
+		}
+	}
+
+	return result
+}
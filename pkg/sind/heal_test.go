@@ -0,0 +1,66 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiedUnexpectedly(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		node     types.Container
+		expected bool
+	}{
+		{
+			desc: "running node",
+			node: types.Container{State: "running", Status: "Up 2 minutes"},
+		},
+		{
+			desc: "stopped node",
+			node: types.Container{State: "exited", Status: "Exited (0) 5 seconds ago"},
+		},
+		{
+			desc:     "killed node",
+			node:     types.Container{State: "exited", Status: "Exited (137) 5 seconds ago"},
+			expected: true,
+		},
+		{
+			desc:     "dead node",
+			node:     types.Container{State: "dead", Status: "Dead"},
+			expected: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, diedUnexpectedly(test.node))
+		})
+	}
+}
+
+func TestSortedByRole(t *testing.T) {
+	node := func(id, role string) types.Container {
+		return types.Container{ID: id, Labels: map[string]string{internal.NodeRoleLabel: role}}
+	}
+
+	nodes := sortedByRole(
+		[]types.Container{
+			node("worker-0", internal.NodeRoleWorker),
+			node("manager-1", internal.NodeRoleManager),
+			node("manager-0", internal.NodeRolePrimary),
+		},
+	)
+
+	assert.Equal(
+		t,
+		[]types.Container{
+			node("manager-0", internal.NodeRolePrimary),
+			node("worker-0", internal.NodeRoleWorker),
+			node("manager-1", internal.NodeRoleManager),
+		},
+		nodes,
+	)
+}
@@ -0,0 +1,112 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// HookStage is a stage of the lifecycle of a cluster hooks are run at.
+type HookStage string
+
+// Hook stages.
+const (
+	// PreCreate hooks run before any resource of the cluster is created.
+	PreCreate HookStage = "pre-create"
+	// PostCreate hooks run once the cluster is ready, its swarm formed and its load balancer running.
+	PostCreate HookStage = "post-create"
+	// PreDelete hooks run before the cluster is torn down.
+	PreDelete HookStage = "pre-delete"
+)
+
+// HookEvent describes the cluster a hook is run for.
+type HookEvent struct {
+	Stage HookStage
+
+	// ClusterName is the name of the cluster, prefixed with its namespace if any.
+	ClusterName string
+	Namespace   string
+	// Metadata is the user defined metadata attached to the cluster.
+	Metadata map[string]string
+
+	// Host is the docker host to use to communicate with the cluster, e.g. tcp://localhost:32768. It is empty before the
+	// cluster is created, or when its primary node is not running.
+	Host string
+}
+
+// Env returns the event as environment variables: SIND_HOOK, SIND_CLUSTER, SIND_NAMESPACE, DOCKER_HOST if the cluster
+// is reachable, and SIND_METADATA_<KEY> for each metadata, the key being upper cased and its dashes and dots replaced
+// by underscores.
+func (e HookEvent) Env() []string {
+	env := []string{
+		"SIND_HOOK=" + string(e.Stage),
+		"SIND_CLUSTER=" + e.ClusterName,
+		"SIND_NAMESPACE=" + e.Namespace,
+	}
+
+	if e.Host != "" {
+		env = append(env, "DOCKER_HOST="+e.Host)
+	}
+
+	keys := make([]string, 0, len(e.Metadata))
+
+	for key := range e.Metadata {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		env = append(env, "SIND_METADATA_"+metadataEnvReplacer.Replace(strings.ToUpper(key))+"="+e.Metadata[key])
+	}
+
+	return env
+}
+
+var metadataEnvReplacer = strings.NewReplacer("-", "_", ".", "_")
+
+// Hook is a callback run at a stage of the lifecycle of a cluster. An error aborts the operation it is run for, a
+// failing PostCreate hook leaves the cluster created.
+type Hook func(ctx context.Context, event HookEvent) error
+
+// runHooks runs given hooks in order, in a span, until one of them fails.
+func runHooks(ctx context.Context, hooks []Hook, event HookEvent) error {
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	ctx, span := internal.StartSpan(ctx, "sind.hooks."+string(event.Stage), map[string]string{internal.ClusterAttribute: event.ClusterName})
+
+	var err error
+
+	for i, hook := range hooks {
+		if err = hook(ctx, event); err != nil {
+			err = fmt.Errorf("%w: %s hook #%d: %v", ErrHookFailed, event.Stage, i+1, err)
+			break
+		}
+	}
+
+	span.End(err)
+
+	return err
+}
+
+// clusterHookEvent returns the event of an existing cluster, its host being left empty if it is not reachable.
+func clusterHookEvent(ctx context.Context, hostClient *docker.Client, stage HookStage, status *ClusterStatus) HookEvent {
+	event := HookEvent{
+		Stage:       stage,
+		ClusterName: status.Name,
+		Namespace:   status.Namespace,
+		Metadata:    status.Metadata,
+	}
+
+	if status.ManagersRunning > 0 {
+		event.Host, _ = ClusterHost(ctx, hostClient, status.Name)
+	}
+
+	return event
+}
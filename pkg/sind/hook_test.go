@@ -0,0 +1,73 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHookEventEnv(t *testing.T) {
+	event := HookEvent{
+		Stage:       PostCreate,
+		ClusterName: "ci-1.foo",
+		Namespace:   "ci-1",
+		Metadata:    map[string]string{"team": "payments", "com.example.build-id": "42"},
+		Host:        "tcp://localhost:32768",
+	}
+
+	assert.Equal(
+		t,
+		[]string{
+			"SIND_HOOK=post-create",
+			"SIND_CLUSTER=ci-1.foo",
+			"SIND_NAMESPACE=ci-1",
+			"DOCKER_HOST=tcp://localhost:32768",
+			"SIND_METADATA_COM_EXAMPLE_BUILD_ID=42",
+			"SIND_METADATA_TEAM=payments",
+		},
+		event.Env(),
+	)
+}
+
+func TestRunHooks(t *testing.T) {
+	hookErr := errors.New("boom")
+	event := HookEvent{Stage: PreDelete, ClusterName: "foo"}
+
+	var calls []string
+
+	hook := func(name string, err error) Hook {
+		return func(ctx context.Context, got HookEvent) error {
+			assert.Equal(t, event, got)
+			calls = append(calls, name)
+
+			return err
+		}
+	}
+
+	err := runHooks(context.Background(), []Hook{hook("first", nil), hook("second", hookErr), hook("third", nil)}, event)
+
+	assert.True(t, errors.Is(err, ErrHookFailed))
+	assert.Contains(t, err.Error(), "pre-delete hook #2: boom")
+	assert.Equal(t, []string{"first", "second"}, calls)
+}
+
+func TestClusterConfigurationHookEvent(t *testing.T) {
+	config := ClusterConfiguration{
+		ClusterName: "foo",
+		Namespace:   "ci-1",
+		Metadata:    map[string]string{"team": "payments"},
+	}.namespaced()
+
+	assert.Equal(
+		t,
+		HookEvent{
+			Stage:       PreCreate,
+			ClusterName: "ci-1.foo",
+			Namespace:   "ci-1",
+			Metadata:    map[string]string{"team": "payments"},
+		},
+		config.hookEvent(PreCreate),
+	)
+}
@@ -0,0 +1,75 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// HostsDomain is the top level domain of node names, e.g. manager-0.mycluster.sind.
+const HostsDomain = "sind"
+
+// HostsEntry maps a node name to its address on the cluster network.
+type HostsEntry struct {
+	Hostname string
+	Address  string
+}
+
+// ClusterHostsEntries returns the names and addresses of all the nodes of a cluster.
+func ClusterHostsEntries(ctx context.Context, hostClient *docker.Client, clusterName string) ([]HostsEntry, error) {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	return hostsEntries(clusterName, nodes)
+}
+
+// HostsSnippet renders entries in the /etc/hosts format, between markers identifying the cluster.
+func HostsSnippet(clusterName string, entries []HostsEntry) string {
+	var snippet strings.Builder
+
+	fmt.Fprintf(&snippet, "# BEGIN sind cluster %s\n", clusterName)
+
+	for _, entry := range entries {
+		fmt.Fprintf(&snippet, "%s\t%s\n", entry.Address, entry.Hostname)
+	}
+
+	fmt.Fprintf(&snippet, "# END sind cluster %s\n", clusterName)
+
+	return snippet.String()
+}
+
+func hostsEntries(clusterName string, nodes []types.Container) ([]HostsEntry, error) {
+	entries := make([]HostsEntry, 0, len(nodes))
+
+	for _, node := range nodes {
+		if len(node.Names) == 0 {
+			return nil, fmt.Errorf("node %q has no name", node.ID)
+		}
+
+		_, endpoint, err := internal.NodeNetwork(node)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, HostsEntry{
+			Hostname: fmt.Sprintf("%s.%s.%s", nodeName(clusterName, node.Names[0]), clusterName, HostsDomain),
+			Address:  endpoint.IPAddress,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Hostname < entries[j].Hostname })
+
+	return entries, nil
+}
+
+// nodeName returns the name of a node within its cluster (e.g. manager-0) from its container name.
+func nodeName(clusterName, containerName string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(containerName, "/"), "sind-"+clusterName+"-")
+}
@@ -0,0 +1,73 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostsEntries(t *testing.T) {
+	node := func(name, address string) types.Container {
+		return types.Container{
+			ID:    name,
+			Names: []string{"/" + name},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{"net": {IPAddress: address}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		desc            string
+		nodes           []types.Container
+		expectedEntries []HostsEntry
+		expectedError   error
+	}{
+		{
+			desc: "sorted entries",
+			nodes: []types.Container{
+				node("sind-test-worker-0", "10.0.0.4"),
+				node("sind-test-manager-0", "10.0.0.2"),
+				node("sind-test-manager-1", "10.0.0.3"),
+			},
+			expectedEntries: []HostsEntry{
+				{Hostname: "manager-0.test.sind", Address: "10.0.0.2"},
+				{Hostname: "manager-1.test.sind", Address: "10.0.0.3"},
+				{Hostname: "worker-0.test.sind", Address: "10.0.0.4"},
+			},
+		},
+		{
+			desc:          "node without name",
+			nodes:         []types.Container{{ID: "nope"}},
+			expectedError: errors.New("node \"nope\" has no name"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			entries, err := hostsEntries("test", test.nodes)
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedEntries, entries)
+		})
+	}
+}
+
+func TestHostsSnippet(t *testing.T) {
+	snippet := HostsSnippet("test", []HostsEntry{
+		{Hostname: "manager-0.test.sind", Address: "10.0.0.2"},
+		{Hostname: "worker-0.test.sind", Address: "10.0.0.3"},
+	})
+
+	assert.Equal(
+		t,
+		"# BEGIN sind cluster test\n10.0.0.2\tmanager-0.test.sind\n10.0.0.3\tworker-0.test.sind\n# END sind cluster test\n",
+		snippet,
+	)
+}
@@ -0,0 +1,160 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+const imagePollInterval = 500 * time.Millisecond
+
+// WaitForImage waits until an image is present on all the nodes of the cluster tasks may be scheduled on: the active and
+// ready nodes of the swarm, or all the running nodes of a cluster which swarm is not managed by sind. The ref is an image
+// reference or an image ID (sha256:...). The image must have the same ID on the nodes as on the docker host, telling
+// apart a tag pushed again, unless it is unknown to the docker host.
+func WaitForImage(ctx context.Context, hostClient *docker.Client, clusterName, ref string) error {
+	ctx, span := internal.StartSpan(ctx, "sind.wait_image", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := waitForImage(ctx, hostClient, clusterName, ref)
+
+	span.End(err)
+
+	return err
+}
+
+func waitForImage(ctx context.Context, hostClient *docker.Client, clusterName, ref string) error {
+	imageID, err := hostImageID(ctx, hostClient, ref)
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(imagePollInterval)
+	defer ticker.Stop()
+
+	for {
+		missing, err := nodesMissingImage(ctx, hostClient, clusterName, ref, imageID)
+		if err != nil {
+			return err
+		}
+
+		if len(missing) == 0 {
+			return nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return fmt.Errorf("image %q is missing on nodes %q: %w", ref, missing, ctx.Err())
+		}
+	}
+}
+
+// hostImageID returns the ID of an image on the docker host, empty if the image is not present.
+func hostImageID(ctx context.Context, hostClient *docker.Client, ref string) (string, error) {
+	if strings.HasPrefix(ref, "sha256:") {
+		return ref, nil
+	}
+
+	image, _, err := hostClient.ImageInspectWithRaw(ctx, ref)
+	if docker.IsErrNotFound(err) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect image %q: %w", ref, err)
+	}
+
+	return image.ID, nil
+}
+
+// nodesMissingImage returns the names of the schedulable nodes on which the image is not present, or has another ID
+// than the given one if any.
+func nodesMissingImage(ctx context.Context, hostClient *docker.Client, clusterName, ref, imageID string) ([]string, error) {
+	nodes, err := schedulableNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+
+	for _, node := range nodes {
+		id, err := internal.NodeImageID(ctx, hostClient, node.ID, ref)
+		if err != nil {
+			return nil, fmt.Errorf("unable to inspect image %q on node %q: %w", ref, containerName(node), err)
+		}
+
+		if id == "" || (imageID != "" && id != imageID) {
+			missing = append(missing, nodeName(clusterName, containerName(node)))
+		}
+	}
+
+	return missing, nil
+}
+
+// schedulableNodes returns the nodes of the cluster tasks may be scheduled on.
+func schedulableNodes(ctx context.Context, hostClient *docker.Client, clusterName string) ([]types.Container, error) {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	primary, ok := primaryNode(nodes)
+	if !ok {
+		return nil, ErrClusterNotFound
+	}
+
+	if !managedSwarm(primary) {
+		return runningNodes(nodes), nil
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	swarmNodes, err := swarmClient.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	return schedulable(nodes, swarmNodes), nil
+}
+
+// schedulable returns the nodes which swarm node is active and ready.
+func schedulable(nodes []types.Container, swarmNodes []swarm.Node) []types.Container {
+	var result []types.Container
+
+	for _, swarmNode := range swarmNodes {
+		if swarmNode.Spec.Availability != swarm.NodeAvailabilityActive || swarmNode.Status.State != swarm.NodeStateReady {
+			continue
+		}
+
+		for _, node := range nodes {
+			if containerName(node) == swarmNode.Description.Hostname {
+				result = append(result, node)
+			}
+		}
+	}
+
+	return result
+}
+
+func runningNodes(nodes []types.Container) []types.Container {
+	var result []types.Container
+
+	for _, node := range nodes {
+		if node.State == "running" {
+			result = append(result, node)
+		}
+	}
+
+	return result
+}
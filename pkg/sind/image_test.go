@@ -0,0 +1,45 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulable(t *testing.T) {
+	nodes := []types.Container{
+		{ID: "primary", Names: []string{"/sind-foo-manager-0"}},
+		{ID: "drained", Names: []string{"/sind-foo-manager-1"}},
+		{ID: "worker", Names: []string{"/sind-foo-worker-0"}},
+		{ID: "down", Names: []string{"/sind-foo-worker-1"}},
+	}
+
+	swarmNode := func(hostname string, availability swarm.NodeAvailability, state swarm.NodeState) swarm.Node {
+		return swarm.Node{
+			Description: swarm.NodeDescription{Hostname: hostname},
+			Spec:        swarm.NodeSpec{Availability: availability},
+			Status:      swarm.NodeStatus{State: state},
+		}
+	}
+
+	swarmNodes := []swarm.Node{
+		swarmNode("sind-foo-manager-0", swarm.NodeAvailabilityActive, swarm.NodeStateReady),
+		swarmNode("sind-foo-manager-1", swarm.NodeAvailabilityDrain, swarm.NodeStateReady),
+		swarmNode("sind-foo-worker-0", swarm.NodeAvailabilityActive, swarm.NodeStateReady),
+		swarmNode("sind-foo-worker-1", swarm.NodeAvailabilityActive, swarm.NodeStateDown),
+		swarmNode("external-node", swarm.NodeAvailabilityActive, swarm.NodeStateReady),
+	}
+
+	assert.Equal(t, []types.Container{nodes[0], nodes[2]}, schedulable(nodes, swarmNodes))
+}
+
+func TestRunningNodes(t *testing.T) {
+	nodes := []types.Container{
+		{ID: "running", State: "running"},
+		{ID: "exited", State: "exited"},
+	}
+
+	assert.Equal(t, []types.Container{nodes[0]}, runningNodes(nodes))
+}
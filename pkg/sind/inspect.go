@@ -3,6 +3,8 @@ package sind
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/jlevesy/sind/pkg/sind/internal"
@@ -16,14 +18,66 @@ type ClusterStatus struct {
 	ManagersRunning uint16
 	Workers         uint16
 	WorkersRunning  uint16
+	// NodesUnhealthy is the amount of nodes which docker daemon stopped answering to their health check.
+	NodesUnhealthy uint16
+
+	// CreatedAt is the creation date of the primary node of the cluster.
+	CreatedAt time.Time
+	// CreatedBy is the user@host that created the cluster, and SindVersion the version of sind it used.
+	// Both are empty for clusters created by versions of sind not recording them.
+	CreatedBy   string
+	SindVersion string
+
+	// Labels are the labels of the primary node of the cluster.
+	Labels map[string]string
+
+	// Metadata is the user defined metadata attached to the cluster at creation.
+	Metadata map[string]string
+
+	// Namespace is the namespace the cluster has been created in, empty if none.
+	Namespace string
+	// NetworkName is the name of the network the nodes of the cluster are attached to.
+	NetworkName string
+	// PortBindings are the port bindings of the cluster, ports ranges being kept as ranges, e.g. 8000-8010:8000-8010/tcp.
+	PortBindings []string
+
+	// Plain is true if the nodes of the cluster don't form a swarm.
+	Plain bool
+	// TLS is true if the daemon port of the nodes of the cluster is secured with mutual TLS.
+	TLS bool
+	// ExternalSwarm is true if the nodes of the cluster joined a swarm not managed by sind.
+	ExternalSwarm bool
+	// Provisioned is true if the nodes of the cluster have been created but never started, waiting to be claimed.
+	Provisioned bool
+
+	// ExpiresAt is the date after which the cluster can be garbage collected, zero if the cluster never expires.
+	ExpiresAt time.Time
+	// IdleTimeout is the duration without activity after which the cluster is stopped by AutoStop, zero if never.
+	IdleTimeout time.Duration
 
 	Nodes []types.Container
 }
 
+// Expired returns true if the cluster has a TTL which is elapsed at given time.
+func (c *ClusterStatus) Expired(now time.Time) bool {
+	return !c.ExpiresAt.IsZero() && now.After(c.ExpiresAt)
+}
+
+// MatchMetadata returns true if the cluster has all the given metadata.
+func (c *ClusterStatus) MatchMetadata(metadata map[string]string) bool {
+	for key, value := range metadata {
+		if current, ok := c.Metadata[key]; !ok || current != value {
+			return false
+		}
+	}
+
+	return true
+}
+
 // InspectCluster returns current status for a given cluster.
 // It returns nil,nil if the cluster is not found on the configured docker host.
 func InspectCluster(ctx context.Context, hostClient internal.ContainerLister, clusterName string) (*ClusterStatus, error) {
-	nodes, err := internal.ListContainers(ctx, hostClient, clusterName)
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
 	if err != nil {
 		return nil, err
 	}
@@ -32,7 +86,7 @@ func InspectCluster(ctx context.Context, hostClient internal.ContainerLister, cl
 		return nil, nil
 	}
 
-	result := &ClusterStatus{Name: clusterName, Nodes: nodes}
+	result := &ClusterStatus{Name: clusterName, Nodes: nodes, Provisioned: true}
 
 	for _, node := range nodes {
 		role, ok := node.Labels[internal.NodeRoleLabel]
@@ -40,6 +94,39 @@ func InspectCluster(ctx context.Context, hostClient internal.ContainerLister, cl
 			return nil, fmt.Errorf("node %q has no role label", node.ID)
 		}
 
+		if role == internal.NodeRolePrimary {
+			result.CreatedAt = time.Unix(node.Created, 0)
+			result.Labels = node.Labels
+			result.Metadata = metadata(node)
+			result.CreatedBy = node.Labels[internal.CreatedByLabel]
+			result.SindVersion = node.Labels[internal.VersionLabel]
+			result.Namespace = node.Labels[internal.NamespaceLabel]
+			result.NetworkName = node.Labels[internal.NetworkNameLabel]
+			result.Plain = node.Labels[internal.PlainClusterLabel] == "true"
+			result.TLS = node.Labels[internal.TLSLabel] == "true"
+			_, result.ExternalSwarm = node.Labels[internal.ExternalSwarmLabel]
+
+			if bindings := node.Labels[internal.PortBindingsLabel]; bindings != "" {
+				result.PortBindings = strings.Split(bindings, ",")
+			}
+
+			if result.ExpiresAt, err = expiresAt(node); err != nil {
+				return nil, err
+			}
+
+			if result.IdleTimeout, err = idleTimeout(node); err != nil {
+				return nil, err
+			}
+		}
+
+		if node.State != "created" {
+			result.Provisioned = false
+		}
+
+		if unhealthy(node) {
+			result.NodesUnhealthy++
+		}
+
 		if role == internal.NodeRoleManager ||
 			role == internal.NodeRolePrimary {
 			result.Managers++
@@ -60,3 +147,63 @@ func InspectCluster(ctx context.Context, hostClient internal.ContainerLister, cl
 
 	return result, nil
 }
+
+// FindCluster returns the current status of a given cluster, rebuilt from the labels and the state of its containers.
+// It returns ErrClusterNotFound if the cluster is not found on the configured docker host.
+func FindCluster(ctx context.Context, hostClient internal.ContainerLister, clusterName string) (*ClusterStatus, error) {
+	status, err := InspectCluster(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	if status == nil {
+		return nil, fmt.Errorf("%w for cluster %q", ErrClusterNotFound, clusterName)
+	}
+
+	return status, nil
+}
+
+// unhealthy reads the health of a node from its status, e.g. "Up 2 minutes (unhealthy)".
+func unhealthy(node types.Container) bool {
+	return strings.HasSuffix(node.Status, "(unhealthy)")
+}
+
+func expiresAt(node types.Container) (time.Time, error) {
+	value, ok := node.Labels[internal.ExpiresAtLabel]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	expiresAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("node %q has an invalid expiration date: %w", node.ID, err)
+	}
+
+	return expiresAt, nil
+}
+
+func idleTimeout(node types.Container) (time.Duration, error) {
+	value, ok := node.Labels[internal.IdleTimeoutLabel]
+	if !ok {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("node %q has an invalid idle timeout: %w", node.ID, err)
+	}
+
+	return timeout, nil
+}
+
+func metadata(node types.Container) map[string]string {
+	result := make(map[string]string)
+
+	for key, value := range node.Labels {
+		if strings.HasPrefix(key, internal.MetadataLabelPrefix) {
+			result[strings.TrimPrefix(key, internal.MetadataLabelPrefix)] = value
+		}
+	}
+
+	return result
+}
@@ -2,7 +2,9 @@ package sind
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/jlevesy/sind/pkg/sind/internal"
@@ -28,11 +30,20 @@ func TestInspectCluster(t *testing.T) {
 				{
 					State: "running",
 					Labels: map[string]string{
-						internal.NodeRoleLabel: internal.NodeRolePrimary,
+						internal.NodeRoleLabel:                internal.NodeRolePrimary,
+						internal.ExpiresAtLabel:               "2021-06-01T10:00:00Z",
+						internal.IdleTimeoutLabel:             "30m0s",
+						internal.MetadataLabelPrefix + "team": "payments",
+						internal.NamespaceLabel:               "ci-1",
+						internal.NetworkNameLabel:             "ci-1.foo-net",
+						internal.CreatedByLabel:               "ci@runner-1",
+						internal.VersionLabel:                 "v0.9.0",
+						internal.PortBindingsLabel:            "8000-8010:8000-8010/tcp,53:53/udp",
 					},
 				},
 				{
-					State: "running",
+					State:  "running",
+					Status: "Up 2 minutes (unhealthy)",
 					Labels: map[string]string{
 						internal.NodeRoleLabel: internal.NodeRoleManager,
 					},
@@ -67,6 +78,39 @@ func TestInspectCluster(t *testing.T) {
 				ManagersRunning: 2,
 				Workers:         3,
 				WorkersRunning:  2,
+				NodesUnhealthy:  1,
+				ExpiresAt:       time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+				IdleTimeout:     30 * time.Minute,
+				Metadata:        map[string]string{"team": "payments"},
+				Namespace:       "ci-1",
+				NetworkName:     "ci-1.foo-net",
+				CreatedBy:       "ci@runner-1",
+				SindVersion:     "v0.9.0",
+				PortBindings:    []string{"8000-8010:8000-8010/tcp", "53:53/udp"},
+			},
+		},
+		{
+			desc: "with provisioned containers",
+
+			discoveredContainers: []types.Container{
+				{
+					State: "created",
+					Labels: map[string]string{
+						internal.NodeRoleLabel: internal.NodeRolePrimary,
+					},
+				},
+				{
+					State: "created",
+					Labels: map[string]string{
+						internal.NodeRoleLabel: internal.NodeRoleWorker,
+					},
+				},
+			},
+			expectedStatus: &ClusterStatus{
+				Managers:    1,
+				Workers:     1,
+				Metadata:    map[string]string{},
+				Provisioned: true,
 			},
 		},
 	}
@@ -93,7 +137,89 @@ func TestInspectCluster(t *testing.T) {
 			assert.Equal(t, test.expectedStatus.ManagersRunning, res.ManagersRunning)
 			assert.Equal(t, test.expectedStatus.Workers, res.Workers)
 			assert.Equal(t, test.expectedStatus.WorkersRunning, res.WorkersRunning)
+			assert.Equal(t, test.expectedStatus.NodesUnhealthy, res.NodesUnhealthy)
+			assert.Equal(t, test.expectedStatus.ExpiresAt, res.ExpiresAt)
+			assert.Equal(t, test.expectedStatus.IdleTimeout, res.IdleTimeout)
+			assert.Equal(t, test.expectedStatus.Metadata, res.Metadata)
+			assert.Equal(t, test.expectedStatus.Provisioned, res.Provisioned)
+			assert.Equal(t, test.expectedStatus.Namespace, res.Namespace)
+			assert.Equal(t, test.expectedStatus.NetworkName, res.NetworkName)
+			assert.Equal(t, test.expectedStatus.CreatedBy, res.CreatedBy)
+			assert.Equal(t, test.expectedStatus.SindVersion, res.SindVersion)
+			assert.Equal(t, test.expectedStatus.PortBindings, res.PortBindings)
 			assert.Equal(t, test.discoveredContainers, res.Nodes)
 		})
 	}
 }
+
+func TestInspectClusterFailsWithAnInvalidExpirationDate(t *testing.T) {
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return []types.Container{
+			{
+				Labels: map[string]string{
+					internal.NodeRoleLabel:  internal.NodeRolePrimary,
+					internal.ExpiresAtLabel: "tomorrow",
+				},
+			},
+		}, nil
+	})
+
+	_, err := InspectCluster(context.Background(), client, "foo")
+	assert.Error(t, err)
+}
+
+func TestInspectClusterFailsWithAnInvalidIdleTimeout(t *testing.T) {
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return []types.Container{
+			{
+				Labels: map[string]string{
+					internal.NodeRoleLabel:    internal.NodeRolePrimary,
+					internal.IdleTimeoutLabel: "a while",
+				},
+			},
+		}, nil
+	})
+
+	_, err := InspectCluster(context.Background(), client, "foo")
+	assert.Error(t, err)
+}
+
+func TestClusterStatusExpired(t *testing.T) {
+	now := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	assert.False(t, (&ClusterStatus{}).Expired(now))
+	assert.False(t, (&ClusterStatus{ExpiresAt: now.Add(time.Minute)}).Expired(now))
+	assert.True(t, (&ClusterStatus{ExpiresAt: now.Add(-time.Minute)}).Expired(now))
+}
+
+func TestClusterStatusMatchMetadata(t *testing.T) {
+	status := ClusterStatus{Metadata: map[string]string{"team": "payments", "env": "ci"}}
+
+	assert.True(t, status.MatchMetadata(nil))
+	assert.True(t, status.MatchMetadata(map[string]string{"team": "payments"}))
+	assert.True(t, status.MatchMetadata(map[string]string{"team": "payments", "env": "ci"}))
+	assert.False(t, status.MatchMetadata(map[string]string{"team": "billing"}))
+	assert.False(t, status.MatchMetadata(map[string]string{"owner": "payments"}))
+}
+
+func TestFindCluster(t *testing.T) {
+	ctx := context.Background()
+
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return nil, nil
+	})
+
+	_, err := FindCluster(ctx, client, "foo")
+	assert.True(t, errors.Is(err, ErrClusterNotFound))
+
+	client = internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return []types.Container{
+			{State: "running", Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary}},
+		}, nil
+	})
+
+	status, err := FindCluster(ctx, client, "foo")
+	require.NoError(t, err)
+	assert.Equal(t, "foo", status.Name)
+	assert.Equal(t, uint16(1), status.ManagersRunning)
+}
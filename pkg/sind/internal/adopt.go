@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+// ErrNotSwarmNode is returned when the docker daemon of a container is not an active swarm node.
+var ErrNotSwarmNode = errors.New("not an active swarm node")
+
+// adoptedSuffix is appended to the name of a container being replaced by AdoptContainer.
+const adoptedSuffix = "-sind-adopted"
+
+// SwarmRole returns the swarm role, manager or worker, of the docker daemon running in a container.
+func SwarmRole(ctx context.Context, client executor, cID string) (string, error) {
+	out, err := ExecOutput(
+		ctx,
+		client,
+		cID,
+		[]string{"docker", "info", "--format", "{{.Swarm.LocalNodeState}} {{.Swarm.ControlAvailable}}"},
+	)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the swarm state of container %q: %w", cID, err)
+	}
+
+	switch strings.TrimSpace(out) {
+	case "active true":
+		return NodeRoleManager, nil
+	case "active false":
+		return NodeRoleWorker, nil
+	default:
+		return "", fmt.Errorf("%w: container %q swarm state is %q", ErrNotSwarmNode, cID, strings.TrimSpace(out))
+	}
+}
+
+type containerAdopter interface {
+	nodeCreator
+	ContainerInspect(context.Context, string) (types.ContainerJSON, error)
+	ContainerStop(context.Context, string, *time.Duration) error
+	ContainerRename(context.Context, string, string) error
+	ContainerRemove(context.Context, string, types.ContainerRemoveOptions) error
+}
+
+// AdoptContainer replaces a container by a new one with the same configuration and given additional labels, labels of
+// an existing container being immutable. The new container mounts the volumes of the replaced one, holding its docker
+// daemon state, and keeps its addresses. The daemon port is published if publishDaemon is true.
+// The replaced container is restored if its replacement can't be started.
+func AdoptContainer(ctx context.Context, client containerAdopter, cID string, labels map[string]string, publishDaemon bool) (string, error) {
+	adopted, err := client.ContainerInspect(ctx, cID)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect container %q: %w", cID, err)
+	}
+
+	name := strings.TrimPrefix(adopted.Name, "/")
+	cConfig, hConfig := adoptedConfig(adopted, labels, publishDaemon)
+	nConfig := adoptedNetworkingConfig(adopted)
+
+	// A stopped container releases its addresses, and renaming it frees its name for its replacement.
+	if err = client.ContainerStop(ctx, cID, nil); err != nil {
+		return "", fmt.Errorf("unable to stop container %q: %w", cID, err)
+	}
+
+	if err = client.ContainerRename(ctx, cID, name+adoptedSuffix); err != nil {
+		_ = client.ContainerStart(ctx, cID, types.ContainerStartOptions{})
+
+		return "", fmt.Errorf("unable to rename container %q: %w", cID, err)
+	}
+
+	newID, err := runNamedContainer(ctx, client, name, cConfig, hConfig, nConfig)
+	if err != nil {
+		return "", restoreContainer(ctx, client, cID, name, newID, err)
+	}
+
+	// The volumes are mounted by the replacement, they must be kept.
+	if err = client.ContainerRemove(ctx, cID, types.ContainerRemoveOptions{}); err != nil {
+		return "", fmt.Errorf("unable to remove replaced container %q: %w", cID, err)
+	}
+
+	return newID, nil
+}
+
+// restoreContainer removes the replacement of a container if any, then renames and starts the container again.
+func restoreContainer(ctx context.Context, client containerAdopter, cID, name, newID string, cause error) error {
+	if newID != "" {
+		if err := client.ContainerRemove(ctx, newID, types.ContainerRemoveOptions{Force: true}); err != nil {
+			return fmt.Errorf("unable to replace container %q: %v, and unable to remove its replacement: %w", cID, cause, err)
+		}
+	}
+
+	if err := client.ContainerRename(ctx, cID, name); err != nil {
+		return fmt.Errorf("unable to replace container %q: %v, and unable to restore its name: %w", cID, cause, err)
+	}
+
+	if err := client.ContainerStart(ctx, cID, types.ContainerStartOptions{}); err != nil {
+		return fmt.Errorf("unable to replace container %q: %v, and unable to restart it: %w", cID, cause, err)
+	}
+
+	return fmt.Errorf("unable to replace container %q: %w", cID, cause)
+}
+
+// runNamedContainer creates and starts a container with given name. The ID of the container is returned along with
+// the error if it has been created but not started.
+func runNamedContainer(
+	ctx context.Context,
+	client nodeCreator,
+	name string,
+	cConfig *container.Config,
+	hConfig *container.HostConfig,
+	nConfig *network.NetworkingConfig,
+) (string, error) {
+	var resp container.ContainerCreateCreatedBody
+
+	err := retry(ctx, func() error {
+		var err error
+
+		resp, err = client.ContainerCreate(ctx, cConfig, hConfig, nConfig, name)
+
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	err = retry(ctx, func() error {
+		return client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{})
+	})
+
+	return resp.ID, err
+}
+
+// adoptedConfig returns the configuration of the replacement of an adopted container.
+func adoptedConfig(adopted types.ContainerJSON, labels map[string]string, publishDaemon bool) (*container.Config, *container.HostConfig) {
+	cConfig := *adopted.Config
+	hConfig := *adopted.HostConfig
+
+	cConfig.Labels = make(map[string]string, len(adopted.Config.Labels)+len(labels))
+
+	for k, v := range adopted.Config.Labels {
+		cConfig.Labels[k] = v
+	}
+
+	for k, v := range labels {
+		cConfig.Labels[k] = v
+	}
+
+	if publishDaemon {
+		cConfig.ExposedPorts = make(nat.PortSet, len(adopted.Config.ExposedPorts)+1)
+
+		for port := range adopted.Config.ExposedPorts {
+			cConfig.ExposedPorts[port] = struct{}{}
+		}
+
+		cConfig.ExposedPorts[nat.Port(fmt.Sprintf("%d/tcp", dockerDaemonPort))] = struct{}{}
+		hConfig.PublishAllPorts = true
+	}
+
+	mounted := make(map[string]bool)
+
+	for _, bind := range hConfig.Binds {
+		if parts := strings.Split(bind, ":"); len(parts) > 1 {
+			mounted[parts[1]] = true
+		}
+	}
+
+	for _, m := range hConfig.Mounts {
+		mounted[m.Target] = true
+	}
+
+	hConfig.Binds = append([]string{}, hConfig.Binds...)
+
+	// Anonymous volumes, such as the docker daemon state of the dind image, would be created again otherwise.
+	for _, m := range adopted.Mounts {
+		if m.Type == "volume" && !mounted[m.Destination] {
+			hConfig.Binds = append(hConfig.Binds, m.Name+":"+m.Destination)
+		}
+	}
+
+	return &cConfig, &hConfig
+}
+
+// adoptedNetworkingConfig returns the networking configuration of the replacement of an adopted container, keeping its
+// addresses for the swarm to keep working. Docker only accepts them on networks with a user defined subnet.
+func adoptedNetworkingConfig(adopted types.ContainerJSON) *network.NetworkingConfig {
+	nConfig := &network.NetworkingConfig{EndpointsConfig: make(map[string]*network.EndpointSettings)}
+
+	if adopted.NetworkSettings == nil {
+		return nConfig
+	}
+
+	for name, endpoint := range adopted.NetworkSettings.Networks {
+		nConfig.EndpointsConfig[name] = &network.EndpointSettings{
+			NetworkID:  endpoint.NetworkID,
+			Aliases:    endpoint.Aliases,
+			IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: endpoint.IPAddress},
+		}
+	}
+
+	return nConfig
+}
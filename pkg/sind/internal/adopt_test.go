@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSwarmRole(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		output        string
+		expectedRole  string
+		expectedError error
+	}{
+		{desc: "manager", output: "active true\n", expectedRole: NodeRoleManager},
+		{desc: "worker", output: "active false\n", expectedRole: NodeRoleWorker},
+		{desc: "not in a swarm", output: "inactive false\n", expectedError: ErrNotSwarmNode},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := executorMock{
+				containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+					assert.Equal(t, "node", cID)
+					return types.IDResponse{ID: "exec"}, nil
+				},
+				containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+					return execOutput(test.output, ""), nil
+				},
+			}
+
+			role, err := SwarmRole(context.Background(), &client, "node")
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.Equal(t, test.expectedRole, role)
+		})
+	}
+}
+
+type containerAdopterMock struct {
+	nodeRecreatorMock
+
+	containerStop   func(context.Context, string, *time.Duration) error
+	containerRename func(context.Context, string, string) error
+}
+
+func (m containerAdopterMock) ContainerStop(ctx context.Context, cID string, timeout *time.Duration) error {
+	return m.containerStop(ctx, cID, timeout)
+}
+
+func (m containerAdopterMock) ContainerRename(ctx context.Context, cID, name string) error {
+	return m.containerRename(ctx, cID, name)
+}
+
+func adoptedContainer() types.ContainerJSON {
+	return types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID:         "old",
+			Name:       "/swarm-manager",
+			HostConfig: &container.HostConfig{Privileged: true, Binds: []string{"/certs:/certs"}},
+		},
+		Config: &container.Config{Image: "docker:dind", Labels: map[string]string{"team": "payments"}},
+		Mounts: []types.MountPoint{
+			{Type: "volume", Name: "abcdef", Destination: "/var/lib/docker"},
+			{Type: "bind", Source: "/certs", Destination: "/certs"},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"swarm-net": {NetworkID: "net", IPAddress: "172.20.0.2", Aliases: []string{"manager"}},
+			},
+		},
+	}
+}
+
+func TestAdoptContainer(t *testing.T) {
+	var calls []string
+
+	client := containerAdopterMock{
+		nodeRecreatorMock: nodeRecreatorMock{
+			nodeStarterMock: nodeStarterMock{
+				containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+					calls = append(calls, "create")
+
+					assert.Equal(t, "swarm-manager", cName)
+					assert.Equal(t, map[string]string{"team": "payments", ClusterNameLabel: "test"}, ccfg.Labels)
+					assert.Equal(t, nat.PortSet{"2375/tcp": {}}, ccfg.ExposedPorts)
+					assert.True(t, hcfg.PublishAllPorts)
+					assert.Equal(t, []string{"/certs:/certs", "abcdef:/var/lib/docker"}, hcfg.Binds)
+					assert.Equal(
+						t,
+						map[string]*network.EndpointSettings{
+							"swarm-net": {
+								NetworkID:  "net",
+								Aliases:    []string{"manager"},
+								IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "172.20.0.2"},
+							},
+						},
+						ncfg.EndpointsConfig,
+					)
+
+					return container.ContainerCreateCreatedBody{ID: "new"}, nil
+				},
+				containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+					calls = append(calls, "start "+cID)
+					return nil
+				},
+			},
+			containerInspect: func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+				return adoptedContainer(), nil
+			},
+			containerRemove: func(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+				calls = append(calls, "remove "+cID)
+				assert.False(t, opts.RemoveVolumes)
+				return nil
+			},
+		},
+		containerStop: func(ctx context.Context, cID string, timeout *time.Duration) error {
+			calls = append(calls, "stop "+cID)
+			return nil
+		},
+		containerRename: func(ctx context.Context, cID, name string) error {
+			calls = append(calls, "rename "+cID+" "+name)
+			return nil
+		},
+	}
+
+	newID, err := AdoptContainer(context.Background(), client, "old", map[string]string{ClusterNameLabel: "test"}, true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "new", newID)
+	assert.Equal(t, []string{"stop old", "rename old swarm-manager-sind-adopted", "create", "start new", "remove old"}, calls)
+}
+
+func TestAdoptContainerRestoresTheContainerOnFailure(t *testing.T) {
+	var calls []string
+
+	createErr := errors.New("user specified IP address is supported only when connecting to networks with user configured subnets")
+
+	client := containerAdopterMock{
+		nodeRecreatorMock: nodeRecreatorMock{
+			nodeStarterMock: nodeStarterMock{
+				containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+					calls = append(calls, "create")
+					assert.False(t, hcfg.PublishAllPorts)
+					return container.ContainerCreateCreatedBody{}, createErr
+				},
+				containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+					calls = append(calls, "start "+cID)
+					return nil
+				},
+			},
+			containerInspect: func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+				return adoptedContainer(), nil
+			},
+			containerRemove: func(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+				calls = append(calls, "remove "+cID)
+				return nil
+			},
+		},
+		containerStop: func(ctx context.Context, cID string, timeout *time.Duration) error {
+			calls = append(calls, "stop "+cID)
+			return nil
+		},
+		containerRename: func(ctx context.Context, cID, name string) error {
+			calls = append(calls, "rename "+cID+" "+name)
+			return nil
+		},
+	}
+
+	ctx := WithRetryPolicy(context.Background(), RetryPolicy{MaxAttempts: 1})
+
+	_, err := AdoptContainer(ctx, client, "old", nil, false)
+	assert.True(t, errors.Is(err, createErr))
+
+	assert.Equal(
+		t,
+		[]string{"stop old", "rename old swarm-manager-sind-adopted", "create", "rename old swarm-manager", "start old"},
+		calls,
+	)
+}
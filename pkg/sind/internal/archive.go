@@ -2,39 +2,281 @@ package internal
 
 import (
 	"archive/tar"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"path/filepath"
+	"strings"
 )
 
-// TarFile writes the given file file to a tar archive.
-func TarFile(file, dest *os.File) error {
-	contentInfo, err := file.Stat()
+// ErrInvalidArchiveEntry is returned when extracting an archive with an entry escaping its destination.
+var ErrInvalidArchiveEntry = errors.New("invalid archive entry")
+
+// TarStream writes the content read from src to a tar archive, as a directory named name holding the content in
+// parts of at most chunkSize bytes. A tar entry requires its size upfront, the content being split lets it be streamed
+// while only buffering a part in memory. Parts are named after their position, concatenating them in lexical order
+// restores the content.
+func TarStream(src io.Reader, name string, chunkSize int, dest io.Writer) error {
+	tarWriter := tar.NewWriter(dest)
+
+	err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: name + "/", Mode: 0o700})
 	if err != nil {
-		return fmt.Errorf("unable to collect images file info: %v", err)
+		return fmt.Errorf("unable to write the tar header of %q: %w", name, err)
 	}
 
+	var (
+		chunk   = make([]byte, chunkSize)
+		written int64
+	)
+
+	for part := 0; ; part++ {
+		n, readErr := io.ReadFull(src, chunk)
+		if readErr != nil && readErr != io.EOF && readErr != io.ErrUnexpectedEOF {
+			return fmt.Errorf("unable to read the content (wrote %d): %w", written, readErr)
+		}
+
+		if n == 0 {
+			break
+		}
+
+		err = tarWriter.WriteHeader(
+			&tar.Header{
+				Typeflag: tar.TypeReg,
+				Name:     path.Join(name, fmt.Sprintf("%06d", part)),
+				Size:     int64(n),
+				Mode:     0o600,
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("unable to write tar file header (wrote %d): %w", written, err)
+		}
+
+		if _, err = tarWriter.Write(chunk[:n]); err != nil {
+			return fmt.Errorf("unable to tar the content (wrote %d): %w", written, err)
+		}
+
+		written += int64(n)
+
+		if readErr != nil {
+			break
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return fmt.Errorf("unable to close the tar writer properly (wrote %d): %w", written, err)
+	}
+
+	return nil
+}
+
+// TarPath writes a file or a directory and its content to a tar archive, as an entry named name.
+func TarPath(srcPath, name string, dest io.Writer) error {
 	tarWriter := tar.NewWriter(dest)
 
-	err = tarWriter.WriteHeader(
-		&tar.Header{
-			Typeflag: tar.TypeReg,
-			Name:     contentInfo.Name(),
-			Size:     contentInfo.Size(),
-			Mode:     int64(contentInfo.Mode()),
-		},
-	)
+	err := filepath.Walk(srcPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(srcPath, filePath)
+		if err != nil {
+			return err
+		}
+
+		var link string
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			if link, err = os.Readlink(filePath); err != nil {
+				return fmt.Errorf("unable to read link %q: %w", filePath, err)
+			}
+		}
+
+		header, err := tar.FileInfoHeader(info, link)
+		if err != nil {
+			return fmt.Errorf("unable to create the tar header of %q: %w", filePath, err)
+		}
+
+		// Entries are extracted in linux containers, names are slash separated whatever the host OS is.
+		header.Name = path.Join(name, filepath.ToSlash(rel))
+
+		if err = tarWriter.WriteHeader(header); err != nil {
+			return fmt.Errorf("unable to write the tar header of %q: %w", filePath, err)
+		}
+
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		return tarRegularFile(tarWriter, filePath)
+	})
 	if err != nil {
-		return fmt.Errorf("unable to write tar file header: %v", err)
+		return fmt.Errorf("unable to tar %q: %w", srcPath, err)
 	}
 
-	bytes, err := io.Copy(tarWriter, file)
+	if err = tarWriter.Close(); err != nil {
+		return fmt.Errorf("unable to close the tar writer properly: %w", err)
+	}
+
+	return nil
+}
+
+func tarRegularFile(tarWriter *tar.Writer, filePath string) error {
+	file, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("unable to tar image files (wrote %d): %v", bytes, err)
+		return err
 	}
 
-	if err = tarWriter.Close(); err != nil {
-		return fmt.Errorf("unable to close the tar writer properly (wrote %d): %v", bytes, err)
+	defer file.Close()
+
+	_, err = io.Copy(tarWriter, file)
+
+	return err
+}
+
+// UntarPath extracts a tar archive holding a file or a directory and its content, such as the archives returned by
+// CopyFromContainer, at destPath. Entries outside of the first entry of the archive are rejected.
+func UntarPath(src io.Reader, destPath string) error {
+	tarReader := tar.NewReader(src)
+
+	var root string
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to read the archive: %w", err)
+		}
+
+		name := path.Clean(header.Name)
+		if root == "" {
+			root = name
+		}
+
+		rel, ok := entryPath(root, name)
+		if !ok {
+			return fmt.Errorf("%w: %q is outside of %q", ErrInvalidArchiveEntry, header.Name, root)
+		}
+
+		if err = checkNoSymlink(destPath, rel); err != nil {
+			return fmt.Errorf("%w: %q: %v", ErrInvalidArchiveEntry, header.Name, err)
+		}
+
+		if err = untarEntry(tarReader, header, filepath.Join(destPath, filepath.FromSlash(rel))); err != nil {
+			return fmt.Errorf("unable to extract %q: %w", header.Name, err)
+		}
+	}
+}
+
+// entryPath returns the path of an archive entry relative to the root entry of the archive, and false if it is not
+// under the root entry.
+func entryPath(root, name string) (string, bool) {
+	if name == root {
+		return ".", true
+	}
+
+	if root == "." || root == "/" {
+		return name, name != ".." && !strings.HasPrefix(name, "../")
+	}
+
+	if !strings.HasPrefix(name, root+"/") {
+		return "", false
+	}
+
+	return strings.TrimPrefix(name, root+"/"), true
+}
+
+// checkNoSymlink fails if one of the directories between destPath and the entry at rel is a symlink. Writing through a
+// symlink extracted from the archive, e.g. root/link -> /etc then root/link/passwd, would write outside of destPath.
+func checkNoSymlink(destPath, rel string) error {
+	dir := path.Dir(rel)
+	if dir == "." {
+		return nil
+	}
+
+	current := destPath
+
+	for _, part := range strings.Split(dir, "/") {
+		current = filepath.Join(current, part)
+
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("%q is a symlink", current)
+		}
+	}
+
+	return nil
+}
+
+func untarEntry(tarReader *tar.Reader, header *tar.Header, destPath string) error {
+	mode := os.FileMode(header.Mode) & os.ModePerm
+
+	switch header.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(destPath, mode)
+	case tar.TypeReg:
+		if err := os.MkdirAll(filepath.Dir(destPath), 0o755); err != nil {
+			return err
+		}
+
+		// An existing symlink is replaced rather than written through.
+		if info, err := os.Lstat(destPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if err = os.Remove(destPath); err != nil {
+				return err
+			}
+		}
+
+		file, err := os.OpenFile(destPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+
+		if _, err = io.Copy(file, tarReader); err != nil {
+			_ = file.Close()
+			return err
+		}
+
+		return file.Close()
+	case tar.TypeSymlink:
+		if err := os.Remove(destPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+
+		return os.Symlink(header.Linkname, destPath)
+	default:
+		// Devices, fifos and hard links of the node filesystems are not extracted.
+		return nil
+	}
+}
+
+// UntarFile writes the content of the single regular file of a tar archive, such as the archives returned by
+// CopyFromContainer for a file, to dest.
+func UntarFile(src io.Reader, dest io.Writer) error {
+	tarReader := tar.NewReader(src)
+
+	header, err := tarReader.Next()
+	if err != nil {
+		return fmt.Errorf("unable to read the archive: %w", err)
+	}
+
+	if header.Typeflag != tar.TypeReg {
+		return fmt.Errorf("%w: %q is not a regular file", ErrInvalidArchiveEntry, header.Name)
+	}
+
+	if _, err = io.Copy(dest, tarReader); err != nil {
+		return fmt.Errorf("unable to extract %q: %w", header.Name, err)
 	}
 
 	return nil
@@ -2,58 +2,290 @@ package internal
 
 import (
 	"archive/tar"
+	"bytes"
+	"errors"
 	"io"
 	"io/ioutil"
-	"log"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
-func TestTarFile(t *testing.T) {
-	file, err := ioutil.TempFile(os.TempDir(), "test_sind_tar_file")
-	require.NoError(t, err)
+func TestTarStream(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		content       string
+		expectedParts map[string]string
+	}{
+		{
+			desc:          "content split in parts",
+			content:       "0123456789",
+			expectedParts: map[string]string{"images/000000": "0123", "images/000001": "4567", "images/000002": "89"},
+		},
+		{
+			desc:          "content filling its parts",
+			content:       "01234567",
+			expectedParts: map[string]string{"images/000000": "0123", "images/000001": "4567"},
+		},
+		{
+			desc:          "empty content",
+			expectedParts: map[string]string{},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var archive bytes.Buffer
+
+			require.NoError(t, TarStream(strings.NewReader(test.content), "images", 4, &archive))
+
+			tr := tar.NewReader(&archive)
+
+			hdr, err := tr.Next()
+			require.NoError(t, err)
+
+			assert.Equal(t, "images/", hdr.Name)
+			assert.EqualValues(t, tar.TypeDir, hdr.Typeflag)
+
+			parts := make(map[string]string)
+
+			for {
+				hdr, err = tr.Next()
+				if err == io.EOF {
+					break
+				}
+
+				require.NoError(t, err)
 
-	defer os.Remove(file.Name())
-	defer file.Close()
+				content, err := ioutil.ReadAll(tr)
+				require.NoError(t, err)
 
-	destFile, err := ioutil.TempFile(os.TempDir(), "test_sind_tar_file")
+				parts[hdr.Name] = string(content)
+			}
+
+			assert.Equal(t, test.expectedParts, parts)
+		})
+	}
+}
+
+func TestTarStreamReadFailure(t *testing.T) {
+	readErr := errors.New("save failed")
+
+	err := TarStream(io.MultiReader(strings.NewReader("0123"), iotest.ErrReader(readErr)), "images", 4, ioutil.Discard)
+	assert.True(t, errors.Is(err, readErr))
+}
+
+func TestTarPath(t *testing.T) {
+	srcDir, err := ioutil.TempDir(os.TempDir(), "test_sind_tar_path")
 	require.NoError(t, err)
 
-	defer os.Remove(destFile.Name())
-	defer destFile.Close()
+	defer os.RemoveAll(srcDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "ca"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "ca", "ca.pem"), []byte("ca"), 0o644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "key.pem"), []byte("key"), 0o600))
+	require.NoError(t, os.Symlink("key.pem", filepath.Join(srcDir, "current.pem")))
+
+	// Modes are set explicitly, the umask applies to the creations.
+	require.NoError(t, os.Chmod(filepath.Join(srcDir, "ca"), 0o755))
+	require.NoError(t, os.Chmod(filepath.Join(srcDir, "ca", "ca.pem"), 0o644))
 
-	content := []byte("test")
+	var archive bytes.Buffer
 
-	_, err = file.Write(content)
+	require.NoError(t, TarPath(srcDir, "certs", &archive))
+
+	type entry struct {
+		typeflag byte
+		mode     int64
+		linkname string
+		content  string
+	}
+
+	entries := make(map[string]entry)
+	tr := tar.NewReader(&archive)
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		content, err := ioutil.ReadAll(tr)
+		require.NoError(t, err)
+
+		entries[hdr.Name] = entry{typeflag: hdr.Typeflag, mode: hdr.Mode & 0o777, linkname: hdr.Linkname, content: string(content)}
+	}
+
+	assert.Equal(
+		t,
+		map[string]entry{
+			"certs":             {typeflag: tar.TypeDir, mode: 0o700},
+			"certs/ca":          {typeflag: tar.TypeDir, mode: 0o755},
+			"certs/ca/ca.pem":   {typeflag: tar.TypeReg, mode: 0o644, content: "ca"},
+			"certs/current.pem": {typeflag: tar.TypeSymlink, mode: 0o777, linkname: "key.pem"},
+			"certs/key.pem":     {typeflag: tar.TypeReg, mode: 0o600, content: "key"},
+		},
+		entries,
+	)
+}
+
+func TestUntarPath(t *testing.T) {
+	srcDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_path")
 	require.NoError(t, err)
 
-	_, err = file.Seek(0, 0)
+	defer os.RemoveAll(srcDir)
+
+	require.NoError(t, os.MkdirAll(filepath.Join(srcDir, "reports"), 0o755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(srcDir, "reports", "junit.xml"), []byte("<testsuites/>"), 0o644))
+	require.NoError(t, os.Symlink("reports/junit.xml", filepath.Join(srcDir, "latest.xml")))
+
+	var archive bytes.Buffer
+
+	require.NoError(t, TarPath(srcDir, "artifacts", &archive))
+
+	destDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_path")
 	require.NoError(t, err)
 
-	require.NoError(t, TarFile(file, destFile))
+	defer os.RemoveAll(destDir)
+
+	destPath := filepath.Join(destDir, "collected")
+
+	require.NoError(t, UntarPath(&archive, destPath))
+
+	content, err := ioutil.ReadFile(filepath.Join(destPath, "reports", "junit.xml"))
+	require.NoError(t, err)
+	assert.Equal(t, "<testsuites/>", string(content))
 
-	_, err = destFile.Seek(0, 0)
+	link, err := os.Readlink(filepath.Join(destPath, "latest.xml"))
 	require.NoError(t, err)
+	assert.Equal(t, "reports/junit.xml", link)
+}
+
+func TestUntarPathRejectsEscapingEntries(t *testing.T) {
+	var archive bytes.Buffer
+
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "artifacts/", Mode: 0o755}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "artifacts/../../escaped", Mode: 0o644}))
+	require.NoError(t, tw.Close())
 
-	fileInfo, err := file.Stat()
+	destDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_path")
 	require.NoError(t, err)
 
-	tr := tar.NewReader(destFile)
+	defer os.RemoveAll(destDir)
+
+	err = UntarPath(&archive, filepath.Join(destDir, "collected"))
+	assert.True(t, errors.Is(err, ErrInvalidArchiveEntry))
+
+	_, err = os.Stat(filepath.Join(destDir, "escaped"))
+	assert.True(t, os.IsNotExist(err))
+}
 
-	hdr, err := tr.Next()
+func TestUntarPathRejectsWritesThroughSymlinks(t *testing.T) {
+	outsideDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_outside")
 	require.NoError(t, err)
 
-	assert.Equal(t, fileInfo.Name(), hdr.Name)
-	assert.Equal(t, fileInfo.Size(), hdr.Size)
-	assert.Equal(t, int64(fileInfo.Mode()), hdr.Mode)
+	defer os.RemoveAll(outsideDir)
 
-	if _, err := io.Copy(os.Stdout, tr); err != nil {
-		log.Fatal(err)
+	testCases := []struct {
+		desc  string
+		entry string
+	}{
+		{desc: "file under the symlink", entry: "root/link/passwd"},
+		{desc: "file under a directory under the symlink", entry: "root/link/sub/passwd"},
 	}
 
-	_, err = tr.Next()
-	assert.Equal(t, io.EOF, err)
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var archive bytes.Buffer
+
+			tw := tar.NewWriter(&archive)
+			require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "root/", Mode: 0o755}))
+			require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "root/link", Linkname: outsideDir, Mode: 0o777}))
+			require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: test.entry, Mode: 0o644, Size: 4}))
+			_, err := tw.Write([]byte("evil"))
+			require.NoError(t, err)
+			require.NoError(t, tw.Close())
+
+			destDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_path")
+			require.NoError(t, err)
+
+			defer os.RemoveAll(destDir)
+
+			err = UntarPath(&archive, filepath.Join(destDir, "collected"))
+			assert.True(t, errors.Is(err, ErrInvalidArchiveEntry))
+
+			entries, err := ioutil.ReadDir(outsideDir)
+			require.NoError(t, err)
+			assert.Empty(t, entries)
+		})
+	}
+}
+
+func TestUntarPathReplacesSymlinkedFiles(t *testing.T) {
+	outsideDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_outside")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(outsideDir)
+
+	target := filepath.Join(outsideDir, "passwd")
+	require.NoError(t, ioutil.WriteFile(target, []byte("root"), 0o644))
+
+	var archive bytes.Buffer
+
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "root/", Mode: 0o755}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeSymlink, Name: "root/passwd", Linkname: target, Mode: 0o777}))
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "root/passwd", Mode: 0o644, Size: 4}))
+	_, err = tw.Write([]byte("evil"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	destDir, err := ioutil.TempDir(os.TempDir(), "test_sind_untar_path")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(destDir)
+
+	require.NoError(t, UntarPath(&archive, filepath.Join(destDir, "collected")))
+
+	content, err := ioutil.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "root", string(content))
+
+	content, err = ioutil.ReadFile(filepath.Join(destDir, "collected", "passwd"))
+	require.NoError(t, err)
+	assert.Equal(t, "evil", string(content))
+}
+
+func TestUntarFile(t *testing.T) {
+	var archive bytes.Buffer
+
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "images.tar", Mode: 0o644, Size: 6}))
+	_, err := tw.Write([]byte("images"))
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	var content bytes.Buffer
+
+	require.NoError(t, UntarFile(&archive, &content))
+	assert.Equal(t, "images", content.String())
+}
+
+func TestUntarFileRejectsDirectories(t *testing.T) {
+	var archive bytes.Buffer
+
+	tw := tar.NewWriter(&archive)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: "images/", Mode: 0o755}))
+	require.NoError(t, tw.Close())
+
+	err := UntarFile(&archive, ioutil.Discard)
+	assert.True(t, errors.Is(err, ErrInvalidArchiveEntry))
 }
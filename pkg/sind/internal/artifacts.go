@@ -0,0 +1,42 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type containerLogger interface {
+	ContainerLogs(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+}
+
+// WriteContainerLogs writes the timestamped logs of a container to out, its stdout and stderr interleaved.
+func WriteContainerLogs(ctx context.Context, client containerLogger, cID string, out io.Writer) error {
+	logs, err := client.ContainerLogs(ctx, cID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true})
+	if err != nil {
+		return fmt.Errorf("unable to get the container logs: %w", err)
+	}
+
+	defer logs.Close()
+
+	if _, err = stdcopy.StdCopy(out, out, logs); err != nil {
+		return fmt.Errorf("unable to read the container logs: %w", err)
+	}
+
+	return nil
+}
+
+// PastNodeEvents returns the events the docker daemon of a node emitted between since and until, as JSON lines.
+// The daemon only keeps its most recent events.
+func PastNodeEvents(ctx context.Context, client executor, cID string, since, until time.Time) (string, error) {
+	return ExecOutput(
+		ctx,
+		client,
+		cID,
+		[]string{"docker", "events", "--since", unixNano(since), "--until", unixNano(until), "--format", "{{json .}}"},
+	)
+}
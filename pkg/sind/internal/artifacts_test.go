@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type containerLoggerMock func(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+
+func (m containerLoggerMock) ContainerLogs(ctx context.Context, cID string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+	return m(ctx, cID, opts)
+}
+
+func TestWriteContainerLogs(t *testing.T) {
+	client := containerLoggerMock(func(ctx context.Context, cID string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+		assert.Equal(t, "abcd", cID)
+		assert.Equal(t, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true, Timestamps: true}, opts)
+
+		var logs bytes.Buffer
+
+		_, _ = stdcopy.NewStdWriter(&logs, stdcopy.Stdout).Write([]byte("daemon started\n"))
+		_, _ = stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte("swarm join failed\n"))
+
+		return ioutil.NopCloser(&logs), nil
+	})
+
+	var out bytes.Buffer
+
+	require.NoError(t, WriteContainerLogs(context.Background(), client, "abcd", &out))
+	assert.Equal(t, "daemon started\nswarm join failed\n", out.String())
+}
+
+func TestPastNodeEvents(t *testing.T) {
+	since := time.Unix(1622541600, 0)
+	until := time.Unix(1622545200, 500)
+
+	client := executorMock{
+		containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+			assert.Equal(t, "abcd", cID)
+			assert.Equal(
+				t,
+				[]string{"docker", "events", "--since", "1622541600.000000000", "--until", "1622545200.000000500", "--format", "{{json .}}"},
+				opts.Cmd,
+			)
+
+			return types.IDResponse{ID: "exec"}, nil
+		},
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+			return execOutput("{\"Type\":\"service\"}\n", ""), nil
+		},
+	}
+
+	events, err := PastNodeEvents(context.Background(), &client, "abcd", since, until)
+	require.NoError(t, err)
+	assert.Equal(t, "{\"Type\":\"service\"}\n", events)
+}
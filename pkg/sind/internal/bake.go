@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+)
+
+// BakeDataRoot is the data root of the daemon of baked images.
+// The default /var/lib/docker is declared as a volume by the dind images, its content would be lost on commit.
+const BakeDataRoot = "/var/lib/sind/docker"
+
+// bakeStopTimeout leaves some time to the bake daemon to flush its state before being killed.
+var bakeStopTimeout = 30 * time.Second
+
+// bakeEntrypoint moves the daemon data root out of the image volume, and keeps it moved in the baked image.
+var bakeEntrypoint = []string{
+	"sh",
+	"-c",
+	fmt.Sprintf(`mkdir -p /etc/docker && echo '{"data-root":%q}' > /etc/docker/daemon.json && exec dockerd`, BakeDataRoot),
+}
+
+// StartBakeNode starts a node used to load the images to bake.
+func StartBakeNode(ctx context.Context, client nodeCreator, imageRef string) (string, error) {
+	return runContainer(
+		ctx,
+		client,
+		&container.Config{
+			Image:      imageRef,
+			Entrypoint: bakeEntrypoint,
+		},
+		&container.HostConfig{Privileged: true},
+		nil,
+	)
+}
+
+type bakeCommitter interface {
+	ContainerStop(context.Context, string, *time.Duration) error
+	ContainerCommit(context.Context, string, types.ContainerCommitOptions) (types.IDResponse, error)
+}
+
+// CommitBakeNode stops a bake node then commits it to a new image, restoring the configuration of the base image.
+func CommitBakeNode(ctx context.Context, client bakeCommitter, cID, imageRef string, baseConfig *container.Config) (string, error) {
+	if err := client.ContainerStop(ctx, cID, &bakeStopTimeout); err != nil {
+		return "", fmt.Errorf("unable to stop the bake node: %w", err)
+	}
+
+	resp, err := client.ContainerCommit(ctx, cID, types.ContainerCommitOptions{Reference: imageRef, Config: baseConfig})
+	if err != nil {
+		return "", fmt.Errorf("unable to commit the bake node: %w", err)
+	}
+
+	return resp.ID, nil
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartBakeNode(t *testing.T) {
+	client := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			assert.Equal(t, "docker:20.10-dind", cConfig.Image)
+			assert.Contains(t, cConfig.Entrypoint[2], `{"data-root":"/var/lib/sind/docker"}`)
+			assert.True(t, hConfig.Privileged)
+
+			return container.ContainerCreateCreatedBody{ID: "bake"}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			assert.Equal(t, "bake", cID)
+			return nil
+		},
+	}
+
+	cID, err := StartBakeNode(context.Background(), client, "docker:20.10-dind")
+	require.NoError(t, err)
+	assert.Equal(t, "bake", cID)
+}
+
+type bakeCommitterMock struct {
+	containerStop   func(context.Context, string, *time.Duration) error
+	containerCommit func(context.Context, string, types.ContainerCommitOptions) (types.IDResponse, error)
+}
+
+func (m bakeCommitterMock) ContainerStop(ctx context.Context, cID string, timeout *time.Duration) error {
+	return m.containerStop(ctx, cID, timeout)
+}
+
+func (m bakeCommitterMock) ContainerCommit(ctx context.Context, cID string, opts types.ContainerCommitOptions) (types.IDResponse, error) {
+	return m.containerCommit(ctx, cID, opts)
+}
+
+func TestCommitBakeNode(t *testing.T) {
+	baseConfig := &container.Config{Entrypoint: []string{"dockerd-entrypoint.sh"}}
+
+	testCases := []struct {
+		desc          string
+		stopError     error
+		commitError   error
+		expectedID    string
+		expectedError bool
+	}{
+		{
+			desc:       "commits the stopped node",
+			expectedID: "sha256:baked",
+		},
+		{
+			desc:          "fails if the node can't be stopped",
+			stopError:     errors.New("boom"),
+			expectedError: true,
+		},
+		{
+			desc:          "fails if the node can't be committed",
+			commitError:   errors.New("boom"),
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var stopped bool
+
+			client := bakeCommitterMock{
+				containerStop: func(ctx context.Context, cID string, timeout *time.Duration) error {
+					assert.Equal(t, "bake", cID)
+					stopped = true
+					return test.stopError
+				},
+				containerCommit: func(ctx context.Context, cID string, opts types.ContainerCommitOptions) (types.IDResponse, error) {
+					assert.True(t, stopped)
+					assert.Equal(t, "bake", cID)
+					assert.Equal(t, "sind-node:baked", opts.Reference)
+					assert.Equal(t, baseConfig, opts.Config)
+
+					return types.IDResponse{ID: "sha256:baked"}, test.commitError
+				},
+			}
+
+			imageID, err := CommitBakeNode(context.Background(), client, "bake", "sind-node:baked", baseConfig)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedID, imageID)
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// SwarmNetwork is an overlay network created on a swarm.
+type SwarmNetwork struct {
+	Name string
+	// Attachable allows standalone containers, e.g. run with docker run, to join the network along with services.
+	Attachable bool
+	// Internal denies the access of the network to external networks.
+	Internal bool
+	// Subnet is the subnet of the network, picked by swarm if empty.
+	Subnet string
+}
+
+// SwarmBootstrap are the swarm objects created once a swarm is formed.
+type SwarmBootstrap struct {
+	Networks []SwarmNetwork
+	// Secrets and Configs map the names of the secrets and the configs to their data.
+	Secrets map[string][]byte
+	Configs map[string][]byte
+	// NodeLabels maps the hostnames of the swarm nodes to the labels to add to them.
+	NodeLabels map[string]map[string]string
+}
+
+type swarmBootstrapper interface {
+	secretManager
+	configManager
+	nodeLister
+	NetworkCreate(context.Context, string, types.NetworkCreate) (types.NetworkCreateResponse, error)
+	NodeUpdate(context.Context, string, swarm.Version, swarm.NodeSpec) error
+}
+
+// BootstrapSwarm creates the networks, the secrets and the configs of the bootstrap on a swarm, then labels its nodes.
+func BootstrapSwarm(ctx context.Context, client swarmBootstrapper, bootstrap SwarmBootstrap) error {
+	for _, net := range bootstrap.Networks {
+		if _, err := client.NetworkCreate(ctx, net.Name, swarmNetworkCreate(net)); err != nil {
+			return fmt.Errorf("unable to create network %q: %w", net.Name, err)
+		}
+	}
+
+	for _, name := range sortedNames(bootstrap.Secrets) {
+		if _, err := CreateSecret(ctx, client, name, bootstrap.Secrets[name]); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range sortedNames(bootstrap.Configs) {
+		if _, err := CreateConfig(ctx, client, name, bootstrap.Configs[name]); err != nil {
+			return err
+		}
+	}
+
+	if len(bootstrap.NodeLabels) == 0 {
+		return nil
+	}
+
+	return labelNodes(ctx, client, bootstrap.NodeLabels)
+}
+
+func swarmNetworkCreate(net SwarmNetwork) types.NetworkCreate {
+	create := types.NetworkCreate{
+		Driver:     "overlay",
+		Attachable: net.Attachable,
+		Internal:   net.Internal,
+	}
+
+	if net.Subnet != "" {
+		create.IPAM = &network.IPAM{Driver: "default", Config: []network.IPAMConfig{{Subnet: net.Subnet}}}
+	}
+
+	return create
+}
+
+// labelNodes adds labels to the swarm nodes with given hostnames.
+func labelNodes(ctx context.Context, client swarmBootstrapper, nodeLabels map[string]map[string]string) error {
+	nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	labeled := make(map[string]bool, len(nodeLabels))
+
+	for _, node := range nodes {
+		labels, ok := nodeLabels[node.Description.Hostname]
+		if !ok {
+			continue
+		}
+
+		spec := node.Spec
+		spec.Labels = make(map[string]string, len(node.Spec.Labels)+len(labels))
+
+		for k, v := range node.Spec.Labels {
+			spec.Labels[k] = v
+		}
+
+		for k, v := range labels {
+			spec.Labels[k] = v
+		}
+
+		if err = client.NodeUpdate(ctx, node.ID, node.Version, spec); err != nil {
+			return fmt.Errorf("unable to label node %q: %w", node.Description.Hostname, err)
+		}
+
+		labeled[node.Description.Hostname] = true
+	}
+
+	var missing []string
+
+	for hostname := range nodeLabels {
+		if !labeled[hostname] {
+			missing = append(missing, hostname)
+		}
+	}
+
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("unable to label nodes %q: not found in the swarm", missing)
+	}
+
+	return nil
+}
+
+// sortedNames returns the names of given secrets or configs, sorted.
+func sortedNames(data map[string][]byte) []string {
+	names := make([]string, 0, len(data))
+
+	for name := range data {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,156 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type swarmBootstrapperMock struct {
+	secretManagerMock
+	configManagerMock
+
+	networkCreate func(context.Context, string, types.NetworkCreate) (types.NetworkCreateResponse, error)
+	nodeList      func(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+	nodeUpdate    func(context.Context, string, swarm.Version, swarm.NodeSpec) error
+}
+
+func (m swarmBootstrapperMock) NetworkCreate(ctx context.Context, name string, opts types.NetworkCreate) (types.NetworkCreateResponse, error) {
+	return m.networkCreate(ctx, name, opts)
+}
+
+func (m swarmBootstrapperMock) NodeList(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+	return m.nodeList(ctx, opts)
+}
+
+func (m swarmBootstrapperMock) NodeUpdate(ctx context.Context, id string, version swarm.Version, spec swarm.NodeSpec) error {
+	return m.nodeUpdate(ctx, id, version, spec)
+}
+
+func TestBootstrapSwarm(t *testing.T) {
+	var (
+		networks = make(map[string]types.NetworkCreate)
+		secrets  []string
+		configs  []string
+		updates  = make(map[string]swarm.NodeSpec)
+	)
+
+	client := swarmBootstrapperMock{
+		secretManagerMock: secretManagerMock{
+			secretCreate: func(ctx context.Context, spec swarm.SecretSpec) (types.SecretCreateResponse, error) {
+				secrets = append(secrets, spec.Name+"="+string(spec.Data))
+				return types.SecretCreateResponse{ID: spec.Name}, nil
+			},
+		},
+		configManagerMock: configManagerMock{
+			configCreate: func(ctx context.Context, spec swarm.ConfigSpec) (types.ConfigCreateResponse, error) {
+				configs = append(configs, spec.Name+"="+string(spec.Data))
+				return types.ConfigCreateResponse{ID: spec.Name}, nil
+			},
+		},
+		networkCreate: func(ctx context.Context, name string, opts types.NetworkCreate) (types.NetworkCreateResponse, error) {
+			networks[name] = opts
+			return types.NetworkCreateResponse{ID: name}, nil
+		},
+		nodeList: func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			return []swarm.Node{
+				{
+					ID:          "manager",
+					Meta:        swarm.Meta{Version: swarm.Version{Index: 4}},
+					Description: swarm.NodeDescription{Hostname: "sind-test-manager-0"},
+					Spec:        swarm.NodeSpec{Availability: swarm.NodeAvailabilityActive},
+				},
+				{
+					ID:          "worker",
+					Meta:        swarm.Meta{Version: swarm.Version{Index: 7}},
+					Description: swarm.NodeDescription{Hostname: "sind-test-worker-0"},
+					Spec: swarm.NodeSpec{
+						Annotations:  swarm.Annotations{Labels: map[string]string{"existing": "label"}},
+						Availability: swarm.NodeAvailabilityActive,
+					},
+				},
+			}, nil
+		},
+		nodeUpdate: func(ctx context.Context, id string, version swarm.Version, spec swarm.NodeSpec) error {
+			assert.Equal(t, swarm.Version{Index: 7}, version)
+
+			updates[id] = spec
+
+			return nil
+		},
+	}
+
+	err := BootstrapSwarm(context.Background(), client, SwarmBootstrap{
+		Networks: []SwarmNetwork{
+			{Name: "backend", Attachable: true, Subnet: "10.20.0.0/24"},
+			{Name: "private", Internal: true},
+		},
+		Secrets:    map[string][]byte{"token": []byte("t"), "password": []byte("p")},
+		Configs:    map[string][]byte{"nginx": []byte("n")},
+		NodeLabels: map[string]map[string]string{"sind-test-worker-0": {"zone": "a"}},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[string]types.NetworkCreate{
+			"backend": {
+				Driver:     "overlay",
+				Attachable: true,
+				IPAM:       &network.IPAM{Driver: "default", Config: []network.IPAMConfig{{Subnet: "10.20.0.0/24"}}},
+			},
+			"private": {Driver: "overlay", Internal: true},
+		},
+		networks,
+	)
+	assert.Equal(t, []string{"password=p", "token=t"}, secrets)
+	assert.Equal(t, []string{"nginx=n"}, configs)
+	assert.Equal(
+		t,
+		map[string]swarm.NodeSpec{
+			"worker": {
+				Annotations:  swarm.Annotations{Labels: map[string]string{"existing": "label", "zone": "a"}},
+				Availability: swarm.NodeAvailabilityActive,
+			},
+		},
+		updates,
+	)
+}
+
+func TestBootstrapSwarmFailsOnMissingNode(t *testing.T) {
+	client := swarmBootstrapperMock{
+		nodeList: func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			return []swarm.Node{
+				{ID: "manager", Description: swarm.NodeDescription{Hostname: "sind-test-manager-0"}},
+			}, nil
+		},
+		nodeUpdate: func(ctx context.Context, id string, version swarm.Version, spec swarm.NodeSpec) error {
+			return nil
+		},
+	}
+
+	err := BootstrapSwarm(context.Background(), client, SwarmBootstrap{
+		NodeLabels: map[string]map[string]string{
+			"sind-test-manager-0": {"zone": "a"},
+			"sind-test-worker-3":  {"zone": "b"},
+		},
+	})
+	assert.EqualError(t, err, `unable to label nodes ["sind-test-worker-3"]: not found in the swarm`)
+}
+
+func TestBootstrapSwarmFailsOnNetworkError(t *testing.T) {
+	client := swarmBootstrapperMock{
+		networkCreate: func(ctx context.Context, name string, opts types.NetworkCreate) (types.NetworkCreateResponse, error) {
+			return types.NetworkCreateResponse{}, errors.New("boom")
+		},
+	}
+
+	err := BootstrapSwarm(context.Background(), client, SwarmBootstrap{Networks: []SwarmNetwork{{Name: "backend"}}})
+	assert.EqualError(t, err, `unable to create network "backend": boom`)
+}
@@ -0,0 +1,29 @@
+package internal
+
+import "github.com/docker/docker/api/types/container"
+
+const (
+	managerWeight = 2
+	workerWeight  = 1
+)
+
+// SplitResources divides a total memory (bytes) and CPU (nano CPUs) budget across the nodes of a cluster,
+// managers getting twice the share of a worker. A zero budget leaves the matching limit unset.
+func SplitResources(totalMemory, totalNanoCPUs int64, managers, workers uint16) (container.Resources, container.Resources) {
+	shares := int64(managers)*managerWeight + int64(workers)*workerWeight
+	if shares == 0 {
+		return container.Resources{}, container.Resources{}
+	}
+
+	memoryShare := totalMemory / shares
+	cpuShare := totalNanoCPUs / shares
+
+	return container.Resources{
+			Memory:   memoryShare * managerWeight,
+			NanoCPUs: cpuShare * managerWeight,
+		},
+		container.Resources{
+			Memory:   memoryShare * workerWeight,
+			NanoCPUs: cpuShare * workerWeight,
+		}
+}
@@ -0,0 +1,55 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitResources(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		totalMemory     int64
+		totalNanoCPUs   int64
+		managers        uint16
+		workers         uint16
+		expectedManager container.Resources
+		expectedWorker  container.Resources
+	}{
+		{
+			desc:     "without budget",
+			managers: 3,
+			workers:  3,
+		},
+		{
+			desc:        "without nodes",
+			totalMemory: 1024,
+		},
+		{
+			desc:            "with managers only",
+			totalMemory:     6000,
+			totalNanoCPUs:   3000,
+			managers:        3,
+			expectedManager: container.Resources{Memory: 2000, NanoCPUs: 1000},
+			expectedWorker:  container.Resources{Memory: 1000, NanoCPUs: 500},
+		},
+		{
+			desc:            "with managers and workers",
+			totalMemory:     8000,
+			totalNanoCPUs:   4000,
+			managers:        1,
+			workers:         6,
+			expectedManager: container.Resources{Memory: 2000, NanoCPUs: 1000},
+			expectedWorker:  container.Resources{Memory: 1000, NanoCPUs: 500},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			manager, worker := SplitResources(test.totalMemory, test.totalNanoCPUs, test.managers, test.workers)
+			assert.Equal(t, test.expectedManager, manager)
+			assert.Equal(t, test.expectedWorker, worker)
+		})
+	}
+}
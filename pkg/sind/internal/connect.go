@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/golang/sync/errgroup"
+)
+
+type networkConnector interface {
+	NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error
+}
+
+// ConnectContainers attaches all given containers to a network concurrently.
+func ConnectContainers(ctx context.Context, client networkConnector, networkID string, containers []types.Container) error {
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	for _, container := range containers {
+		cID := container.ID
+
+		errg.Go(func() error {
+			return client.NetworkConnect(groupCtx, networkID, cID, &network.EndpointSettings{})
+		})
+	}
+
+	if err := errg.Wait(); err != nil {
+		return fmt.Errorf("failed to connect at least one container: %w", err)
+	}
+
+	return nil
+}
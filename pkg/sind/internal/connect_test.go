@@ -0,0 +1,65 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+)
+
+type networkConnectorMock func(context.Context, string, string, *network.EndpointSettings) error
+
+func (n networkConnectorMock) NetworkConnect(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+	return n(ctx, networkID, containerID, config)
+}
+
+func TestConnectContainers(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		connectError  error
+		expectedError error
+	}{
+		{
+			desc: "connects successfully",
+		},
+		{
+			desc:          "failed to connect a container",
+			connectError:  errors.New("nope"),
+			expectedError: errors.New("failed to connect at least one container: nope"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			containers := []types.Container{{ID: "a"}, {ID: "b"}}
+			connected := make(chan string, len(containers))
+
+			mock := networkConnectorMock(func(ctx context.Context, networkID, containerID string, config *network.EndpointSettings) error {
+				assert.Equal(t, "net", networkID)
+				connected <- containerID
+				return test.connectError
+			})
+
+			err := ConnectContainers(ctx, mock, "net", containers)
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+				return
+			}
+
+			assert.NoError(t, err)
+
+			close(connected)
+
+			var connectedIDs []string
+			for cID := range connected {
+				connectedIDs = append(connectedIDs, cID)
+			}
+
+			assert.ElementsMatch(t, []string{"a", "b"}, connectedIDs)
+		})
+	}
+}
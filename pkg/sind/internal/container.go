@@ -1,17 +1,28 @@
 package internal
 
 import (
+	"archive/tar"
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/golang/sync/errgroup"
 )
 
+// ErrPrimaryContainerNotFound is returned when the primary container of a cluster can't be found.
+var ErrPrimaryContainerNotFound = errors.New("primary container not found")
+
 // ContainerLister is something able to list containers.
 type ContainerLister interface {
 	ContainerList(context.Context, types.ContainerListOptions) ([]types.Container, error)
@@ -34,12 +45,32 @@ func ListContainers(ctx context.Context, docker ContainerLister, clusterName str
 		All:     true,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to get container list: %v", err)
+		return nil, fmt.Errorf("unable to get container list: %w", err)
 	}
 
 	return containers, nil
 }
 
+// ListNodes returns the lists of node containers for given cluster, leaving auxiliary containers aside.
+func ListNodes(ctx context.Context, docker ContainerLister, clusterName string) ([]types.Container, error) {
+	containers, err := ListContainers(ctx, docker, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := make([]types.Container, 0, len(containers))
+
+	for _, container := range containers {
+		if _, ok := container.Labels[ComponentLabel]; ok {
+			continue
+		}
+
+		nodes = append(nodes, container)
+	}
+
+	return nodes, nil
+}
+
 // PrimaryContainer returns the primary container of given cluster.
 func PrimaryContainer(ctx context.Context, docker ContainerLister, clusterName string) (*types.Container, error) {
 	containers, err := docker.ContainerList(ctx, types.ContainerListOptions{
@@ -50,11 +81,11 @@ func PrimaryContainer(ctx context.Context, docker ContainerLister, clusterName s
 		All: true,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to list containers: %v", err)
+		return nil, fmt.Errorf("unable to list containers: %w", err)
 	}
 
 	if len(containers) == 0 {
-		return nil, fmt.Errorf("primary container for cluster %q not found", clusterName)
+		return nil, fmt.Errorf("%w for cluster %q", ErrPrimaryContainerNotFound, clusterName)
 	}
 
 	if len(containers) > 1 {
@@ -68,26 +99,80 @@ type containerRemover interface {
 	ContainerRemove(ctx context.Context, containerID string, opts types.ContainerRemoveOptions) error
 }
 
-// RemoveContainers removes all given containers concurrently.
-func RemoveContainers(ctx context.Context, hostClient containerRemover, containers []types.Container) error {
-	errg, groupCtx := errgroup.WithContext(ctx)
+// ContainersError reports the containers an operation failed on, along with their error.
+type ContainersError struct {
+	Operation string
+	Errors    map[string]error
+}
+
+func (e *ContainersError) Error() string {
+	cIDs := make([]string, 0, len(e.Errors))
+	for cID := range e.Errors {
+		cIDs = append(cIDs, cID)
+	}
+
+	sort.Strings(cIDs)
+
+	failures := make([]string, 0, len(cIDs))
+	for _, cID := range cIDs {
+		failures = append(failures, fmt.Sprintf("%s: %v", cID, e.Errors[cID]))
+	}
+
+	return fmt.Sprintf("failed to %s %d container(s): %s", e.Operation, len(cIDs), strings.Join(failures, ", "))
+}
+
+// RemoveContainers removes all given containers, jobs at a time (all at once if 0), along with their anonymous volumes
+// if removeVolumes is true. All the containers are processed, failures are reported by a *ContainersError.
+func RemoveContainers(ctx context.Context, hostClient containerRemover, containers []types.Container, jobs int, removeVolumes bool) error {
+	if jobs <= 0 || jobs > len(containers) {
+		jobs = len(containers)
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures = make(map[string]error)
+		in       = make(chan string, len(containers))
+	)
 
 	for _, container := range containers {
-		cid := container.ID
+		in <- container.ID
+	}
 
-		errg.Go(func() error {
-			return hostClient.ContainerRemove(groupCtx,
-				cid,
-				types.ContainerRemoveOptions{
-					Force:         true,
-					RemoveVolumes: true,
-				},
-			)
-		})
+	close(in)
+
+	for i := 0; i < jobs; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for cID := range in {
+				spanCtx, span := StartSpan(ctx, "sind.container.remove", map[string]string{ContainerAttribute: cID})
+
+				err := hostClient.ContainerRemove(spanCtx,
+					cID,
+					types.ContainerRemoveOptions{
+						Force:         true,
+						RemoveVolumes: removeVolumes,
+					},
+				)
+
+				span.End(err)
+
+				if err != nil {
+					mu.Lock()
+					failures[cID] = err
+					mu.Unlock()
+				}
+			}
+		}()
 	}
 
-	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("failed to remove at least one container: %v", err)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &ContainersError{Operation: "remove", Errors: failures}
 	}
 
 	return nil
@@ -110,7 +195,7 @@ func StartContainers(ctx context.Context, hostClient containerStarter, container
 	}
 
 	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("failed to start at least one container: %v", err)
+		return fmt.Errorf("failed to start at least one container: %w", err)
 	}
 
 	return nil
@@ -120,20 +205,20 @@ type containerStopper interface {
 	ContainerStop(ctx context.Context, containerID string, timeout *time.Duration) error
 }
 
-// StopContainers stops all given containers concurrently.
-func StopContainers(ctx context.Context, hostClient containerStopper, containers []types.Container) error {
+// StopContainers stops all given containers concurrently, killing them after timeout (daemon default if nil).
+func StopContainers(ctx context.Context, hostClient containerStopper, containers []types.Container, timeout *time.Duration) error {
 	errg, groupCtx := errgroup.WithContext(ctx)
 
 	for _, container := range containers {
 		cID := container.ID
 
 		errg.Go(func() error {
-			return hostClient.ContainerStop(groupCtx, cID, nil)
+			return hostClient.ContainerStop(groupCtx, cID, timeout)
 		})
 	}
 
 	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("failed to stop at least one container: %v", err)
+		return fmt.Errorf("failed to stop at least one container: %w", err)
 	}
 
 	return nil
@@ -164,7 +249,15 @@ func CopyToContainers(ctx context.Context, hostClient containerContentCopier, co
 						return nil
 					}
 
-					if err := copyToContainer(groupCtx, hostClient, cID, contentPath, destPath); err != nil {
+					spanCtx, span := StartSpan(groupCtx, "sind.container.copy", map[string]string{ContainerAttribute: cID})
+
+					err := retry(spanCtx, func() error {
+						return copyToContainer(spanCtx, hostClient, cID, contentPath, destPath)
+					})
+
+					span.End(err)
+
+					if err != nil {
 						return err
 					}
 				}
@@ -179,7 +272,7 @@ func CopyToContainers(ctx context.Context, hostClient containerContentCopier, co
 	close(in)
 
 	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("unable to deploy the image to host: %v", err)
+		return fmt.Errorf("unable to deploy the image to host: %w", err)
 	}
 
 	return nil
@@ -188,7 +281,7 @@ func CopyToContainers(ctx context.Context, hostClient containerContentCopier, co
 func copyToContainer(ctx context.Context, hostClient containerContentCopier, cID, contentPath, destPath string) error {
 	file, err := os.Open(contentPath)
 	if err != nil {
-		return fmt.Errorf("unable to open content: %v", err)
+		return fmt.Errorf("unable to open content: %w", err)
 	}
 	defer file.Close()
 
@@ -200,15 +293,208 @@ func copyToContainer(ctx context.Context, hostClient containerContentCopier, cID
 		types.CopyToContainerOptions{},
 	)
 	if err != nil {
-		return fmt.Errorf("unable to copy the content to container %q: %v", cID, err)
+		return fmt.Errorf("unable to copy the content to container %q: %w", cID, err)
+	}
+
+	return nil
+}
+
+// containerFile is a file written to a container by writeFiles, skipped if its content is empty.
+type containerFile struct {
+	Name    string
+	Content string
+	Mode    int64
+}
+
+// writeFiles writes files to dir in a container, created only readable by root if missing. The container can be created
+// but not started yet.
+func writeFiles(ctx context.Context, client containerContentCopier, cID, dir string, files []containerFile) error {
+	var archive bytes.Buffer
+
+	tarWriter := tar.NewWriter(&archive)
+	dir = strings.TrimPrefix(dir, "/")
+
+	err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0o700, ModTime: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if file.Content == "" {
+			continue
+		}
+
+		header := tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     path.Join(dir, file.Name),
+			Mode:     file.Mode,
+			Size:     int64(len(file.Content)),
+			ModTime:  time.Now(),
+		}
+
+		if err = tarWriter.WriteHeader(&header); err != nil {
+			return err
+		}
+
+		if _, err = io.WriteString(tarWriter, file.Content); err != nil {
+			return err
+		}
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return retry(ctx, func() error {
+		return client.CopyToContainer(ctx, cID, "/", bytes.NewReader(archive.Bytes()), types.CopyToContainerOptions{})
+	})
+}
+
+// StreamToContainers copies the tar archive written by write to destPath in given containers, without storing it.
+// The archive is streamed to jobs containers at a time (all at once if 0 or less), write being called once per batch of
+// containers.
+func StreamToContainers(
+	ctx context.Context,
+	hostClient containerContentCopier,
+	containers []types.Container,
+	jobs int,
+	destPath string,
+	write func(context.Context, io.Writer) error,
+) error {
+	if jobs <= 0 {
+		jobs = len(containers)
+	}
+
+	for start := 0; start < len(containers); start += jobs {
+		end := start + jobs
+		if end > len(containers) {
+			end = len(containers)
+		}
+
+		if err := streamToContainers(ctx, hostClient, containers[start:end], destPath, write); err != nil {
+			return fmt.Errorf("unable to deploy the image to host: %w", err)
+		}
 	}
 
 	return nil
 }
 
+// streamToContainers fans the archive written by write out to all given containers at once, a failed copy failing the
+// others. A stream can't be replayed, copies are not retried.
+func streamToContainers(ctx context.Context, hostClient containerContentCopier, containers []types.Container, destPath string, write func(context.Context, io.Writer) error) error {
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	var (
+		writers = make([]io.Writer, len(containers))
+		pipes   = make([]*io.PipeWriter, len(containers))
+	)
+
+	for i, container := range containers {
+		reader, writer := io.Pipe()
+		writers[i], pipes[i] = writer, writer
+
+		cID := container.ID
+
+		errg.Go(func() error {
+			spanCtx, span := StartSpan(groupCtx, "sind.container.copy", map[string]string{ContainerAttribute: cID})
+
+			err := hostClient.CopyToContainer(spanCtx, cID, destPath, reader, types.CopyToContainerOptions{})
+
+			span.End(err)
+
+			// Unblocks the writer if the copy ended without consuming the whole archive.
+			if err != nil {
+				_ = reader.CloseWithError(err)
+				return fmt.Errorf("unable to copy the content to container %q: %w", cID, err)
+			}
+
+			_ = reader.Close()
+
+			return nil
+		})
+	}
+
+	errg.Go(func() error {
+		err := write(groupCtx, io.MultiWriter(writers...))
+
+		// A nil error ends the copies, which read the whole archive.
+		for _, pipe := range pipes {
+			_ = pipe.CloseWithError(err)
+		}
+
+		return err
+	})
+
+	return errg.Wait()
+}
+
+type containerContentReader interface {
+	CopyFromContainer(context.Context, string, string) (io.ReadCloser, types.ContainerPathStat, error)
+}
+
+// CopyFromContainer copies the file or directory at srcPath in a container to destPath.
+func CopyFromContainer(ctx context.Context, hostClient containerContentReader, cID, srcPath, destPath string) error {
+	var content io.ReadCloser
+
+	err := retry(ctx, func() error {
+		var err error
+
+		content, _, err = hostClient.CopyFromContainer(ctx, cID, srcPath)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to copy %q from container %q: %w", srcPath, cID, err)
+	}
+
+	defer content.Close()
+
+	return UntarPath(content, destPath)
+}
+
+// CopyFileFromContainer writes the content of the file at srcPath in a container to dest.
+func CopyFileFromContainer(ctx context.Context, hostClient containerContentReader, cID, srcPath string, dest io.Writer) error {
+	var content io.ReadCloser
+
+	err := retry(ctx, func() error {
+		var err error
+
+		content, _, err = hostClient.CopyFromContainer(ctx, cID, srcPath)
+
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to copy %q from container %q: %w", srcPath, cID, err)
+	}
+
+	defer content.Close()
+
+	return UntarFile(content, dest)
+}
+
 type executor interface {
 	ContainerExecCreate(context.Context, string, types.ExecConfig) (types.IDResponse, error)
-	ContainerExecStart(context.Context, string, types.ExecStartCheck) error
+	ContainerExecAttach(context.Context, string, types.ExecStartCheck) (types.HijackedResponse, error)
+	ContainerExecInspect(context.Context, string) (types.ContainerExecInspect, error)
+}
+
+// ExecError is returned when a command executed in a container exits with a non zero code.
+type ExecError struct {
+	ContainerID string
+	Cmd         []string
+	ExitCode    int
+	Stdout      string
+	Stderr      string
+}
+
+func (e *ExecError) Error() string {
+	return fmt.Sprintf(
+		"command %v exited with code %d on container %q: %s",
+		e.Cmd,
+		e.ExitCode,
+		e.ContainerID,
+		strings.TrimSpace(e.Stderr),
+	)
 }
 
 // ExecContainers execute given command to given containers
@@ -231,7 +517,13 @@ func ExecContainers(ctx context.Context, hostClient executor, containers []types
 					if !ok {
 						return nil
 					}
-					if err := execContainer(groupCtx, hostClient, cID, cmd); err != nil {
+					spanCtx, span := StartSpan(groupCtx, "sind.container.exec", map[string]string{ContainerAttribute: cID})
+
+					err := execContainer(spanCtx, hostClient, cID, cmd)
+
+					span.End(err)
+
+					if err != nil {
 						return err
 					}
 				}
@@ -246,29 +538,152 @@ func ExecContainers(ctx context.Context, hostClient executor, containers []types
 	close(in)
 
 	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("unable to exec command %v: %v", cmd, err)
+		return fmt.Errorf("unable to exec command %v: %w", cmd, err)
 	}
 
 	return nil
 }
 
+// execContainer runs cmd in a container, waits for it to exit and returns an *ExecError if it failed.
 func execContainer(ctx context.Context, client executor, cID string, cmd []string) error {
-	exec, err := client.ContainerExecCreate(
-		ctx,
-		cID,
-		types.ExecConfig{
-			Cmd:          cmd,
-			AttachStdout: true,
-			AttachStderr: true,
-		},
+	_, err := ExecOutput(ctx, client, cID, cmd)
+
+	return err
+}
+
+// ExecOutput runs cmd in a container, waits for it to exit and returns its standard output, or an *ExecError if it
+// failed.
+func ExecOutput(ctx context.Context, client executor, cID string, cmd []string) (string, error) {
+	result := execCapture(ctx, client, cID, cmd)
+	if result.Err != nil {
+		return "", result.Err
+	}
+
+	if result.ExitCode != 0 {
+		return "", &ExecError{
+			ContainerID: cID,
+			Cmd:         cmd,
+			ExitCode:    result.ExitCode,
+			Stdout:      result.Stdout,
+			Stderr:      result.Stderr,
+		}
+	}
+
+	return result.Stdout, nil
+}
+
+// ExecResult is the outcome of a command run in a container.
+type ExecResult struct {
+	ContainerID string
+	ExitCode    int
+	Stdout      string
+	Stderr      string
+	// Err is the error which prevented the command from running or its outcome from being known, ExitCode is
+	// meaningless if it is set.
+	Err error
+}
+
+// BroadcastExec runs cmd in given containers concurrently, and returns its outcome in each container, in the order of
+// the containers. Unlike ExecContainers, the command keeps running in all the containers when it fails in one of them.
+func BroadcastExec(ctx context.Context, client executor, containers []types.Container, cmd []string) []ExecResult {
+	results := make([]ExecResult, len(containers))
+
+	var wg sync.WaitGroup
+
+	for i, container := range containers {
+		wg.Add(1)
+
+		go func(i int, cID string) {
+			defer wg.Done()
+
+			spanCtx, span := StartSpan(ctx, "sind.container.exec", map[string]string{ContainerAttribute: cID})
+
+			results[i] = execCapture(spanCtx, client, cID, cmd)
+
+			span.End(results[i].Err)
+		}(i, container.ID)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// execCapture runs cmd in a container, waits for it to exit and returns its outputs and exit code.
+func execCapture(ctx context.Context, client executor, cID string, cmd []string) ExecResult {
+	var (
+		execID string
+		stream types.HijackedResponse
 	)
-	if err != nil {
+
+	result := ExecResult{ContainerID: cID}
+
+	// Only the exec creation and attachment are retried, the command must not run twice.
+	err := retry(ctx, func() error {
+		exec, err := client.ContainerExecCreate(
+			ctx,
+			cID,
+			types.ExecConfig{
+				Cmd:          cmd,
+				AttachStdout: true,
+				AttachStderr: true,
+			},
+		)
+		if err != nil {
+			return err
+		}
+
+		execID = exec.ID
+
+		stream, err = client.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+
 		return err
+	})
+	if err != nil {
+		result.Err = err
+		return result
 	}
 
-	if err := client.ContainerExecStart(ctx, exec.ID, types.ExecStartCheck{}); err != nil {
-		return err
+	defer stream.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if _, err = stdcopy.StdCopy(&stdout, &stderr, stream.Reader); err != nil {
+		result.Err = fmt.Errorf("unable to read the output of command %v on container %q: %w", cmd, cID, err)
+		return result
 	}
 
-	return nil
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	result.ExitCode, result.Err = waitExecExited(ctx, client, execID)
+
+	return result
+}
+
+// waitExecExited returns the exit code of an exec, once it is not running anymore.
+func waitExecExited(ctx context.Context, client executor, execID string) (int, error) {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		var inspect types.ContainerExecInspect
+
+		err := retry(ctx, func() error {
+			var err error
+			inspect, err = client.ContainerExecInspect(ctx, execID)
+			return err
+		})
+		if err != nil {
+			return 0, fmt.Errorf("unable to inspect exec %q: %w", execID, err)
+		}
+
+		if !inspect.Running {
+			return inspect.ExitCode, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-ticker.C:
+		}
+	}
 }
@@ -1,16 +1,20 @@
 package internal
 
 import (
+	"bufio"
 	"context"
 	"errors"
 	"io"
 	"io/ioutil"
+	"net"
 	"os"
 	"sort"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/pkg/stdcopy"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -98,6 +102,25 @@ func TestListContainersFailsOnListError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestListNodes(t *testing.T) {
+	ctx := context.Background()
+
+	containers := []types.Container{
+		{ID: "foo", Labels: map[string]string{NodeRoleLabel: NodeRolePrimary}},
+		{ID: "bar", Labels: map[string]string{ComponentLabel: ComponentPortProxy}},
+		{ID: "biz", Labels: map[string]string{NodeRoleLabel: NodeRoleWorker}},
+	}
+
+	mock := ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return containers, nil
+	})
+
+	result, err := ListNodes(ctx, mock, "supercluster")
+
+	require.NoError(t, err)
+	assert.Equal(t, []types.Container{containers[0], containers[2]}, result)
+}
+
 func TestPrimaryContainer(t *testing.T) {
 	testCases := []struct {
 		desc           string
@@ -109,7 +132,7 @@ func TestPrimaryContainer(t *testing.T) {
 		{
 			desc:          "No containers found",
 			containers:    []types.Container{},
-			expectedError: errors.New("primary container for cluster \"blah\" not found"),
+			expectedError: errors.New("primary container not found for cluster \"blah\""),
 		},
 		{
 			desc:          "List error",
@@ -152,7 +175,7 @@ func TestPrimaryContainer(t *testing.T) {
 			)
 
 			if test.expectedError != nil {
-				assert.Equal(t, test.expectedError, err)
+				assert.EqualError(t, err, test.expectedError.Error())
 			}
 
 			if test.expectedResult != nil {
@@ -203,15 +226,17 @@ func TestStopContainers(t *testing.T) {
 		t.Run(test.desc, func(t *testing.T) {
 			ctx := context.Background()
 			containerStopped := make(chan string, len(test.containers))
+			stopTimeout := 5 * time.Second
 			mock := containerStopperMock(func(ctx context.Context, cID string, timeout *time.Duration) error {
+				assert.Equal(t, &stopTimeout, timeout)
 				containerStopped <- cID
 				return test.stopError
 			})
 
-			err := StopContainers(ctx, mock, test.containers)
+			err := StopContainers(ctx, mock, test.containers, &stopTimeout)
 
 			if test.expectedError != nil {
-				assert.Equal(t, test.expectedError, err)
+				assert.EqualError(t, err, test.expectedError.Error())
 			}
 
 			close(containerStopped)
@@ -239,6 +264,8 @@ func TestRemoveContainers(t *testing.T) {
 	testCases := []struct {
 		desc          string
 		containers    []types.Container
+		jobs          int
+		keepVolumes   bool
 		removeError   error
 		expectedError error
 	}{
@@ -250,7 +277,7 @@ func TestRemoveContainers(t *testing.T) {
 				{ID: "ccccc"},
 			},
 			removeError:   errors.New("still nope nope nope"),
-			expectedError: errors.New("failed to remove at least one container: still nope nope nope"),
+			expectedError: errors.New("failed to remove 3 container(s): aaaaa: still nope nope nope, bbbbb: still nope nope nope, ccccc: still nope nope nope"),
 		},
 		{
 			desc:       "empty containers list",
@@ -264,6 +291,23 @@ func TestRemoveContainers(t *testing.T) {
 				{ID: "ccccc"},
 			},
 		},
+		{
+			desc: "removes successfully with bounded jobs",
+			containers: []types.Container{
+				{ID: "aaaaa"},
+				{ID: "bbbbb"},
+				{ID: "ccccc"},
+			},
+			jobs: 2,
+		},
+		{
+			desc: "keeps volumes",
+			containers: []types.Container{
+				{ID: "aaaaa"},
+				{ID: "bbbbb"},
+			},
+			keepVolumes: true,
+		},
 	}
 
 	for _, test := range testCases {
@@ -277,10 +321,10 @@ func TestRemoveContainers(t *testing.T) {
 				return test.removeError
 			})
 
-			err := RemoveContainers(ctx, mock, test.containers)
+			err := RemoveContainers(ctx, mock, test.containers, test.jobs, !test.keepVolumes)
 
 			if test.expectedError != nil {
-				assert.Equal(t, test.expectedError, err)
+				assert.EqualError(t, err, test.expectedError.Error())
 			}
 
 			close(containerStopped)
@@ -288,7 +332,7 @@ func TestRemoveContainers(t *testing.T) {
 
 			for opt := range sentOpts {
 				assert.True(t, opt.Force)
-				assert.True(t, opt.RemoveVolumes)
+				assert.Equal(t, !test.keepVolumes, opt.RemoveVolumes)
 			}
 
 			var removedCIDs []string
@@ -353,7 +397,7 @@ func TestStartContainers(t *testing.T) {
 			err := StartContainers(ctx, mock, test.containers)
 
 			if test.expectedError != nil {
-				assert.Equal(t, test.expectedError, err)
+				assert.EqualError(t, err, test.expectedError.Error())
 			}
 
 			close(containerStarted)
@@ -440,17 +484,141 @@ func TestCopyToContainers(t *testing.T) {
 	}
 }
 
+func TestStreamToContainers(t *testing.T) {
+	ctx := context.Background()
+
+	destPath := "/"
+	containers := []types.Container{
+		{ID: "AAA"},
+		{ID: "BBB"},
+		{ID: "CCC"},
+	}
+
+	var writes int32
+
+	contentSent := make(chan sentContent, len(containers))
+
+	client := containerContentCopierMock(func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+		contentBytes, err := ioutil.ReadAll(content)
+		require.NoError(t, err)
+
+		contentSent <- sentContent{
+			cID:     cID,
+			path:    path,
+			content: contentBytes,
+		}
+		return nil
+	})
+
+	write := func(ctx context.Context, dest io.Writer) error {
+		atomic.AddInt32(&writes, 1)
+
+		_, err := dest.Write([]byte("content"))
+		return err
+	}
+
+	require.NoError(t, StreamToContainers(ctx, client, containers, 2, destPath, write))
+
+	close(contentSent)
+
+	var sentContent []sentContent
+	for content := range contentSent {
+		sentContent = append(sentContent, content)
+	}
+
+	sort.Slice(sentContent, func(i, j int) bool { return sentContent[i].cID < sentContent[j].cID })
+
+	assert.Len(t, sentContent, len(containers))
+	assert.EqualValues(t, 2, writes)
+
+	for index, content := range sentContent {
+		assert.Equal(t, containers[index].ID, content.cID)
+		assert.Equal(t, destPath, content.path)
+		assert.Equal(t, []byte("content"), content.content)
+	}
+}
+
+func TestStreamToContainersAllAtOnce(t *testing.T) {
+	client := containerContentCopierMock(func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+		_, err := ioutil.ReadAll(content)
+		return err
+	})
+
+	containers := []types.Container{{ID: "AAA"}, {ID: "BBB"}, {ID: "CCC"}}
+
+	for _, jobs := range []int{0, -1} {
+		var writes int32
+
+		write := func(ctx context.Context, dest io.Writer) error {
+			atomic.AddInt32(&writes, 1)
+
+			_, err := dest.Write([]byte("content"))
+			return err
+		}
+
+		require.NoError(t, StreamToContainers(context.Background(), client, containers, jobs, "/", write))
+		assert.EqualValues(t, 1, writes, "jobs %d", jobs)
+	}
+}
+
+func TestStreamToContainersCopyFailure(t *testing.T) {
+	copyErr := errors.New("no space left on device")
+
+	client := containerContentCopierMock(func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+		if cID == "BBB" {
+			return copyErr
+		}
+
+		_, err := ioutil.ReadAll(content)
+		return err
+	})
+
+	// The writer outlives the failed copy, its writes must not block.
+	write := func(ctx context.Context, dest io.Writer) error {
+		for {
+			if _, err := dest.Write([]byte("content")); err != nil {
+				return err
+			}
+		}
+	}
+
+	err := StreamToContainers(context.Background(), client, []types.Container{{ID: "AAA"}, {ID: "BBB"}}, 0, "/", write)
+	assert.True(t, errors.Is(err, copyErr))
+}
+
 type executorMock struct {
-	containerExecCreate func(context.Context, string, types.ExecConfig) (types.IDResponse, error)
-	containerExecStart  func(context.Context, string, types.ExecStartCheck) error
+	containerExecCreate  func(context.Context, string, types.ExecConfig) (types.IDResponse, error)
+	containerExecAttach  func(context.Context, string, types.ExecStartCheck) (types.HijackedResponse, error)
+	containerExecInspect func(context.Context, string) (types.ContainerExecInspect, error)
 }
 
 func (e *executorMock) ContainerExecCreate(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
 	return e.containerExecCreate(ctx, cID, opts)
 }
 
-func (e *executorMock) ContainerExecStart(ctx context.Context, eID string, opts types.ExecStartCheck) error {
-	return e.containerExecStart(ctx, eID, opts)
+func (e *executorMock) ContainerExecAttach(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+	return e.containerExecAttach(ctx, eID, opts)
+}
+
+func (e *executorMock) ContainerExecInspect(ctx context.Context, eID string) (types.ContainerExecInspect, error) {
+	if e.containerExecInspect == nil {
+		return types.ContainerExecInspect{ExecID: eID}, nil
+	}
+
+	return e.containerExecInspect(ctx, eID)
+}
+
+// execOutput returns an attached exec stream carrying given stdout and stderr.
+func execOutput(stdout, stderr string) types.HijackedResponse {
+	serverConn, clientConn := net.Pipe()
+
+	go func() {
+		_, _ = stdcopy.NewStdWriter(serverConn, stdcopy.Stdout).Write([]byte(stdout))
+		_, _ = stdcopy.NewStdWriter(serverConn, stdcopy.Stderr).Write([]byte(stderr))
+		serverConn.Close()
+	}()
+
+	return types.HijackedResponse{Conn: clientConn, Reader: bufio.NewReader(clientConn)}
 }
 
 func TestExecContainers(t *testing.T) {
@@ -481,9 +649,9 @@ func TestExecContainers(t *testing.T) {
 				ID: cID,
 			}, nil
 		},
-		containerExecStart: func(ctx context.Context, eID string, opts types.ExecStartCheck) error {
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
 			execStarted <- eID
-			return nil
+			return execOutput("", ""), nil
 		},
 	}
 
@@ -514,3 +682,90 @@ func TestExecContainers(t *testing.T) {
 		assert.Equal(t, cmd, createdExecs[index].Cmd)
 	}
 }
+
+func TestExecContainerFailure(t *testing.T) {
+	ctx := context.Background()
+
+	var inspects int
+
+	client := executorMock{
+		containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+			return types.IDResponse{ID: "exec"}, nil
+		},
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+			return execOutput("joining\n", "Error response from daemon: timeout\n"), nil
+		},
+		containerExecInspect: func(ctx context.Context, eID string) (types.ContainerExecInspect, error) {
+			inspects++
+			// The exec is still seen running at first inspect.
+			return types.ContainerExecInspect{ExecID: eID, Running: inspects < 2, ExitCode: 1}, nil
+		},
+	}
+
+	err := execContainer(ctx, &client, "node", []string{"docker", "swarm", "join"})
+
+	var execErr *ExecError
+	require.True(t, errors.As(err, &execErr))
+	assert.Equal(
+		t,
+		&ExecError{
+			ContainerID: "node",
+			Cmd:         []string{"docker", "swarm", "join"},
+			ExitCode:    1,
+			Stdout:      "joining\n",
+			Stderr:      "Error response from daemon: timeout\n",
+		},
+		execErr,
+	)
+	assert.EqualError(t, err, `command [docker swarm join] exited with code 1 on container "node": Error response from daemon: timeout`)
+	assert.Equal(t, 2, inspects)
+}
+
+func TestBroadcastExec(t *testing.T) {
+	ctx := context.Background()
+	errCreate := errors.New("no such container")
+
+	containers := []types.Container{
+		{ID: "AAA"},
+		{ID: "BBB"},
+		{ID: "CCC"},
+	}
+
+	client := executorMock{
+		containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+			assert.Equal(t, []string{"sysctl", "-w", "vm.max_map_count=262144"}, opts.Cmd)
+
+			if cID == "CCC" {
+				return types.IDResponse{}, errCreate
+			}
+
+			return types.IDResponse{ID: cID}, nil
+		},
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+			if eID == "BBB" {
+				return execOutput("", "permission denied\n"), nil
+			}
+
+			return execOutput("vm.max_map_count = 262144\n", ""), nil
+		},
+		containerExecInspect: func(ctx context.Context, eID string) (types.ContainerExecInspect, error) {
+			if eID == "BBB" {
+				return types.ContainerExecInspect{ExecID: eID, ExitCode: 255}, nil
+			}
+
+			return types.ContainerExecInspect{ExecID: eID}, nil
+		},
+	}
+
+	results := BroadcastExec(ctx, &client, containers, []string{"sysctl", "-w", "vm.max_map_count=262144"})
+
+	assert.Equal(
+		t,
+		[]ExecResult{
+			{ContainerID: "AAA", Stdout: "vm.max_map_count = 262144\n"},
+			{ContainerID: "BBB", ExitCode: 255, Stderr: "permission denied\n"},
+			{ContainerID: "CCC", Err: errCreate},
+		},
+		results,
+	)
+}
@@ -2,29 +2,134 @@ package internal
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
 )
 
+// ErrNodeNotRunning is returned when waiting for the daemon of a node which container stopped running.
+var ErrNodeNotRunning = errors.New("node is not running")
+
 type pinger interface {
 	Ping(context.Context) (types.Ping, error)
 }
 
-// WaitDaemonReady waits until remote daemon is ready.
-func WaitDaemonReady(ctx context.Context, client pinger) error {
+type containerInspector interface {
+	ContainerInspect(context.Context, string) (types.ContainerJSON, error)
+}
+
+// WaitNodeDaemonReady waits until the daemon of a node is ready, and fails as soon as the node container stops running
+// instead of waiting for the context to be done.
+func WaitNodeDaemonReady(ctx context.Context, client pinger, hostClient containerInspector, cID string) error {
 	ticker := time.NewTicker(100 * time.Millisecond)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ticker.C:
-			_, err := client.Ping(ctx)
+			if _, err := client.Ping(ctx); err == nil {
+				return nil
+			}
+
+			node, err := hostClient.ContainerInspect(ctx, cID)
 			if err != nil {
-				continue
+				return fmt.Errorf("unable to inspect node %q: %w", cID, err)
 			}
 
-			return nil
+			if node.State != nil && !node.State.Running {
+				return fmt.Errorf("%w: node %q %s", ErrNodeNotRunning, strings.TrimPrefix(node.Name, "/"), stateDiagnostic(node.State))
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// stateDiagnostic describes why a container is not running.
+func stateDiagnostic(state *types.ContainerState) string {
+	diagnostic := fmt.Sprintf("is %s with exit code %d", state.Status, state.ExitCode)
+
+	if state.OOMKilled {
+		diagnostic += ", killed by the OOM killer"
+	}
+
+	if state.Error != "" {
+		diagnostic += ": " + state.Error
+	}
+
+	return diagnostic
+}
+
+// WaitNodesReady waits until the docker daemon of given nodes is ready, checking it from inside the nodes.
+func WaitNodesReady(ctx context.Context, client executor, nodes []types.Container) error {
+	return ExecContainers(
+		ctx,
+		client,
+		nodes,
+		0,
+		[]string{"sh", "-c", "until docker version > /dev/null 2>&1; do sleep 0.1; done"},
+	)
+}
+
+// daemonConfigMarker is created in the nodes which daemon configuration has been updated after their creation, for
+// their entrypoint not to overwrite it on restart.
+const daemonConfigMarker = "/etc/docker/.sind-updated"
+
+type daemonConfigUpdater interface {
+	executor
+	ContainerRestart(context.Context, string, *time.Duration) error
+}
+
+// UpdateDaemonConfig writes the daemon.json file of a node, then restarts the node if restart is true, or makes its
+// daemon reload its configuration otherwise. Only some settings can be reloaded, see the dockerd documentation.
+func UpdateDaemonConfig(ctx context.Context, client daemonConfigUpdater, cID, config string, restart bool) error {
+	// The configuration is given as an argument of the script, to avoid quoting it.
+	err := execContainer(
+		ctx,
+		client,
+		cID,
+		[]string{
+			"sh",
+			"-c",
+			fmt.Sprintf(`mkdir -p /etc/docker && printf '%%s\n' "$1" > /etc/docker/daemon.json && touch %s`, daemonConfigMarker),
+			"sh",
+			config,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to write the daemon configuration of node %q: %w", cID, err)
+	}
+
+	if restart {
+		if err = client.ContainerRestart(ctx, cID, nil); err != nil {
+			return fmt.Errorf("unable to restart node %q: %w", cID, err)
+		}
+
+		return nil
+	}
+
+	if err = execContainer(ctx, client, cID, []string{"pkill", "-HUP", "-x", "dockerd"}); err != nil {
+		return fmt.Errorf("unable to reload the daemon configuration of node %q: %w", cID, err)
+	}
+
+	return nil
+}
+
+// WaitSwarmNodeActive waits until the daemon of a node is an active swarm node, checking it from inside the node.
+func WaitSwarmNodeActive(ctx context.Context, client executor, cID string) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			out, err := ExecOutput(ctx, client, cID, []string{"docker", "info", "--format", "{{.Swarm.LocalNodeState}}"})
+			if err == nil && strings.TrimSpace(out) == "active" {
+				return nil
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -0,0 +1,148 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type pingerMock func(context.Context) (types.Ping, error)
+
+func (p pingerMock) Ping(ctx context.Context) (types.Ping, error) {
+	return p(ctx)
+}
+
+type containerInspectorMock func(context.Context, string) (types.ContainerJSON, error)
+
+func (c containerInspectorMock) ContainerInspect(ctx context.Context, cID string) (types.ContainerJSON, error) {
+	return c(ctx, cID)
+}
+
+func TestWaitNodeDaemonReady(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		pings         int
+		state         types.ContainerState
+		expectedError error
+	}{
+		{
+			desc:  "daemon answering",
+			pings: 3,
+			state: types.ContainerState{Status: "running", Running: true},
+		},
+		{
+			desc:          "node exited",
+			pings:         -1,
+			state:         types.ContainerState{Status: "exited", ExitCode: 1, Error: "dockerd failed"},
+			expectedError: ErrNodeNotRunning,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var pings int
+
+			client := pingerMock(func(ctx context.Context) (types.Ping, error) {
+				pings++
+
+				if test.pings < 0 || pings < test.pings {
+					return types.Ping{}, errors.New("connection refused")
+				}
+
+				return types.Ping{}, nil
+			})
+
+			hostClient := containerInspectorMock(func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+				assert.Equal(t, "primary", cID)
+
+				return types.ContainerJSON{ContainerJSONBase: &types.ContainerJSONBase{Name: "/sind-test-manager-0", State: &test.state}}, nil
+			})
+
+			err := WaitNodeDaemonReady(context.Background(), client, hostClient, "primary")
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.Contains(t, err.Error(), `node "sind-test-manager-0" is exited with exit code 1: dockerd failed`)
+		})
+	}
+}
+
+func TestStateDiagnostic(t *testing.T) {
+	assert.Equal(
+		t,
+		"is exited with exit code 137, killed by the OOM killer",
+		stateDiagnostic(&types.ContainerState{Status: "exited", ExitCode: 137, OOMKilled: true}),
+	)
+}
+
+type daemonConfigUpdaterMock struct {
+	executorMock
+
+	containerRestart func(context.Context, string, *time.Duration) error
+}
+
+func (m *daemonConfigUpdaterMock) ContainerRestart(ctx context.Context, cID string, timeout *time.Duration) error {
+	return m.containerRestart(ctx, cID, timeout)
+}
+
+func TestUpdateDaemonConfig(t *testing.T) {
+	config := `{"debug":true}`
+
+	testCases := []struct {
+		desc             string
+		restart          bool
+		expectedCmds     int
+		expectedRestarts int
+	}{
+		{desc: "reloading the daemon", restart: false, expectedCmds: 2, expectedRestarts: 0},
+		{desc: "restarting the node", restart: true, expectedCmds: 1, expectedRestarts: 1},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var (
+				cmds     [][]string
+				restarts int
+			)
+
+			client := daemonConfigUpdaterMock{
+				executorMock: executorMock{
+					containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+						assert.Equal(t, "node", cID)
+						cmds = append(cmds, opts.Cmd)
+
+						return types.IDResponse{ID: "exec"}, nil
+					},
+					containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+						return execOutput("", ""), nil
+					},
+				},
+				containerRestart: func(ctx context.Context, cID string, timeout *time.Duration) error {
+					assert.Equal(t, "node", cID)
+					restarts++
+
+					return nil
+				},
+			}
+
+			require.NoError(t, UpdateDaemonConfig(context.Background(), &client, "node", config, test.restart))
+
+			require.Len(t, cmds, test.expectedCmds)
+			assert.Equal(t, config, cmds[0][len(cmds[0])-1])
+			assert.Contains(t, cmds[0][2], daemonConfigMarker)
+			assert.Equal(t, test.expectedRestarts, restarts)
+
+			if !test.restart {
+				assert.Equal(t, []string{"pkill", "-HUP", "-x", "dockerd"}, cmds[1])
+			}
+		})
+	}
+}
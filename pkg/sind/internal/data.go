@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// SwarmData is a secret or a config stored in a swarm cluster.
+type SwarmData struct {
+	ID        string
+	Name      string
+	CreatedAt time.Time
+	// Stack is the name of the stack the data has been deployed with, empty if it has been created on its own.
+	Stack string
+}
+
+type secretManager interface {
+	SecretCreate(context.Context, swarm.SecretSpec) (types.SecretCreateResponse, error)
+	SecretList(context.Context, types.SecretListOptions) ([]swarm.Secret, error)
+	SecretRemove(context.Context, string) error
+}
+
+// CreateSecret creates a secret holding given data, and returns its ID.
+func CreateSecret(ctx context.Context, client secretManager, name string, data []byte) (string, error) {
+	resp, err := client.SecretCreate(ctx, swarm.SecretSpec{Annotations: swarm.Annotations{Name: name}, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("unable to create secret %q: %w", name, err)
+	}
+
+	return resp.ID, nil
+}
+
+// ListSecrets returns the secrets of a swarm cluster, sorted by name.
+func ListSecrets(ctx context.Context, client secretManager) ([]SwarmData, error) {
+	secrets, err := client.SecretList(ctx, types.SecretListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list secrets: %w", err)
+	}
+
+	result := make([]SwarmData, len(secrets))
+
+	for i, secret := range secrets {
+		result[i] = SwarmData{
+			ID:        secret.ID,
+			Name:      secret.Spec.Name,
+			CreatedAt: secret.CreatedAt,
+			Stack:     secret.Spec.Labels[StackNamespaceLabel],
+		}
+	}
+
+	sortSwarmData(result)
+
+	return result, nil
+}
+
+// RemoveSecret removes the secret with given name or ID.
+func RemoveSecret(ctx context.Context, client secretManager, name string) error {
+	if err := client.SecretRemove(ctx, name); err != nil {
+		return fmt.Errorf("unable to remove secret %q: %w", name, err)
+	}
+
+	return nil
+}
+
+type configManager interface {
+	ConfigCreate(context.Context, swarm.ConfigSpec) (types.ConfigCreateResponse, error)
+	ConfigList(context.Context, types.ConfigListOptions) ([]swarm.Config, error)
+	ConfigRemove(context.Context, string) error
+}
+
+// CreateConfig creates a config holding given data, and returns its ID.
+func CreateConfig(ctx context.Context, client configManager, name string, data []byte) (string, error) {
+	resp, err := client.ConfigCreate(ctx, swarm.ConfigSpec{Annotations: swarm.Annotations{Name: name}, Data: data})
+	if err != nil {
+		return "", fmt.Errorf("unable to create config %q: %w", name, err)
+	}
+
+	return resp.ID, nil
+}
+
+// ListConfigs returns the configs of a swarm cluster, sorted by name.
+func ListConfigs(ctx context.Context, client configManager) ([]SwarmData, error) {
+	configs, err := client.ConfigList(ctx, types.ConfigListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list configs: %w", err)
+	}
+
+	result := make([]SwarmData, len(configs))
+
+	for i, config := range configs {
+		result[i] = SwarmData{
+			ID:        config.ID,
+			Name:      config.Spec.Name,
+			CreatedAt: config.CreatedAt,
+			Stack:     config.Spec.Labels[StackNamespaceLabel],
+		}
+	}
+
+	sortSwarmData(result)
+
+	return result, nil
+}
+
+// RemoveConfig removes the config with given name or ID.
+func RemoveConfig(ctx context.Context, client configManager, name string) error {
+	if err := client.ConfigRemove(ctx, name); err != nil {
+		return fmt.Errorf("unable to remove config %q: %w", name, err)
+	}
+
+	return nil
+}
+
+func sortSwarmData(data []SwarmData) {
+	sort.Slice(data, func(i, j int) bool { return data[i].Name < data[j].Name })
+}
@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type secretManagerMock struct {
+	secretCreate func(context.Context, swarm.SecretSpec) (types.SecretCreateResponse, error)
+	secretList   func(context.Context, types.SecretListOptions) ([]swarm.Secret, error)
+	secretRemove func(context.Context, string) error
+}
+
+func (m secretManagerMock) SecretCreate(ctx context.Context, spec swarm.SecretSpec) (types.SecretCreateResponse, error) {
+	return m.secretCreate(ctx, spec)
+}
+
+func (m secretManagerMock) SecretList(ctx context.Context, opts types.SecretListOptions) ([]swarm.Secret, error) {
+	return m.secretList(ctx, opts)
+}
+
+func (m secretManagerMock) SecretRemove(ctx context.Context, id string) error {
+	return m.secretRemove(ctx, id)
+}
+
+func TestCreateSecret(t *testing.T) {
+	client := secretManagerMock{
+		secretCreate: func(ctx context.Context, spec swarm.SecretSpec) (types.SecretCreateResponse, error) {
+			assert.Equal(t, "db-password", spec.Name)
+			assert.Equal(t, []byte("hunter2"), spec.Data)
+
+			return types.SecretCreateResponse{ID: "secret-id"}, nil
+		},
+	}
+
+	id, err := CreateSecret(context.Background(), client, "db-password", []byte("hunter2"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret-id", id)
+}
+
+func TestListSecrets(t *testing.T) {
+	createdAt := time.Now()
+
+	client := secretManagerMock{
+		secretList: func(ctx context.Context, opts types.SecretListOptions) ([]swarm.Secret, error) {
+			return []swarm.Secret{
+				{
+					ID:   "b",
+					Meta: swarm.Meta{CreatedAt: createdAt},
+					Spec: swarm.SecretSpec{Annotations: swarm.Annotations{
+						Name:   "web_token",
+						Labels: map[string]string{StackNamespaceLabel: "web"},
+					}},
+				},
+				{
+					ID:   "a",
+					Meta: swarm.Meta{CreatedAt: createdAt},
+					Spec: swarm.SecretSpec{Annotations: swarm.Annotations{Name: "db-password"}},
+				},
+			}, nil
+		},
+	}
+
+	secrets, err := ListSecrets(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]SwarmData{
+			{ID: "a", Name: "db-password", CreatedAt: createdAt},
+			{ID: "b", Name: "web_token", CreatedAt: createdAt, Stack: "web"},
+		},
+		secrets,
+	)
+}
+
+type configManagerMock struct {
+	configCreate func(context.Context, swarm.ConfigSpec) (types.ConfigCreateResponse, error)
+	configList   func(context.Context, types.ConfigListOptions) ([]swarm.Config, error)
+	configRemove func(context.Context, string) error
+}
+
+func (m configManagerMock) ConfigCreate(ctx context.Context, spec swarm.ConfigSpec) (types.ConfigCreateResponse, error) {
+	return m.configCreate(ctx, spec)
+}
+
+func (m configManagerMock) ConfigList(ctx context.Context, opts types.ConfigListOptions) ([]swarm.Config, error) {
+	return m.configList(ctx, opts)
+}
+
+func (m configManagerMock) ConfigRemove(ctx context.Context, id string) error {
+	return m.configRemove(ctx, id)
+}
+
+func TestCreateConfig(t *testing.T) {
+	client := configManagerMock{
+		configCreate: func(ctx context.Context, spec swarm.ConfigSpec) (types.ConfigCreateResponse, error) {
+			assert.Equal(t, "nginx.conf", spec.Name)
+			assert.Equal(t, []byte("server {}"), spec.Data)
+
+			return types.ConfigCreateResponse{ID: "config-id"}, nil
+		},
+	}
+
+	id, err := CreateConfig(context.Background(), client, "nginx.conf", []byte("server {}"))
+	require.NoError(t, err)
+	assert.Equal(t, "config-id", id)
+}
+
+func TestRemoveConfig(t *testing.T) {
+	var removed string
+
+	client := configManagerMock{
+		configRemove: func(ctx context.Context, id string) error {
+			removed = id
+			return nil
+		},
+	}
+
+	require.NoError(t, RemoveConfig(context.Background(), client, "nginx.conf"))
+	assert.Equal(t, "nginx.conf", removed)
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+type nodeDrainer interface {
+	nodeLister
+	NodeUpdate(context.Context, string, swarm.Version, swarm.NodeSpec) error
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+// DrainNodes drains all the nodes of a swarm, recording their availability in their DrainedAvailabilityLabel for
+// RestoreDrainedNodes, then waits for all the tasks scheduled on them to be terminated.
+func DrainNodes(ctx context.Context, client nodeDrainer) error {
+	nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	for _, node := range nodes {
+		if node.Spec.Availability == swarm.NodeAvailabilityDrain {
+			continue
+		}
+
+		spec := node.Spec
+		spec.Labels = make(map[string]string, len(node.Spec.Labels)+1)
+
+		for k, v := range node.Spec.Labels {
+			spec.Labels[k] = v
+		}
+
+		spec.Labels[DrainedAvailabilityLabel] = string(node.Spec.Availability)
+		spec.Availability = swarm.NodeAvailabilityDrain
+
+		if err = client.NodeUpdate(ctx, node.ID, node.Version, spec); err != nil {
+			return fmt.Errorf("unable to drain node %q: %w", node.Description.Hostname, err)
+		}
+	}
+
+	return waitTasksTerminated(ctx, client, types.TaskListOptions{})
+}
+
+// waitTasksTerminated waits until none of the tasks of the swarm listed with opts runs on a node, tasks left pending by
+// a drain are never assigned one.
+func waitTasksTerminated(ctx context.Context, client nodeDrainer, opts types.TaskListOptions) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tasks, err := client.TaskList(ctx, opts)
+			if err != nil {
+				return fmt.Errorf("unable to list the swarm tasks: %w", err)
+			}
+
+			terminated := true
+
+			for _, task := range tasks {
+				if task.NodeID != "" && !taskTerminated(task.Status.State) {
+					terminated = false
+					break
+				}
+			}
+
+			if terminated {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// RestoreDrainedNodes restores the availability of the nodes drained by DrainNodes. It waits for the swarm to be
+// available first, as it is restored when the nodes have just been started.
+func RestoreDrainedNodes(ctx context.Context, client nodeDrainer) error {
+	nodes, err := waitNodeList(ctx, client)
+	if err != nil {
+		return err
+	}
+
+	for _, node := range nodes {
+		availability, ok := node.Spec.Labels[DrainedAvailabilityLabel]
+		if !ok {
+			continue
+		}
+
+		spec := node.Spec
+		spec.Labels = make(map[string]string, len(node.Spec.Labels))
+
+		for k, v := range node.Spec.Labels {
+			if k != DrainedAvailabilityLabel {
+				spec.Labels[k] = v
+			}
+		}
+
+		spec.Availability = swarm.NodeAvailability(availability)
+
+		if err = client.NodeUpdate(ctx, node.ID, node.Version, spec); err != nil {
+			return fmt.Errorf("unable to restore the availability of node %q: %w", node.Description.Hostname, err)
+		}
+	}
+
+	return nil
+}
+
+// waitNodeList lists the swarm nodes, waiting for the swarm managers to elect a leader.
+func waitNodeList(ctx context.Context, client nodeLister) ([]swarm.Node, error) {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+		if err == nil {
+			return nodes, nil
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, fmt.Errorf("unable to list the swarm nodes: %w", err)
+		}
+	}
+}
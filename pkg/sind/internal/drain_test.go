@@ -0,0 +1,115 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nodeDrainerMock struct {
+	nodeList   func(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+	nodeUpdate func(context.Context, string, swarm.Version, swarm.NodeSpec) error
+	taskList   func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+func (m nodeDrainerMock) NodeList(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+	return m.nodeList(ctx, opts)
+}
+
+func (m nodeDrainerMock) NodeUpdate(ctx context.Context, nodeID string, version swarm.Version, spec swarm.NodeSpec) error {
+	return m.nodeUpdate(ctx, nodeID, version, spec)
+}
+
+func (m nodeDrainerMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return m.taskList(ctx, opts)
+}
+
+func TestDrainNodes(t *testing.T) {
+	var (
+		updated = make(map[string]swarm.NodeSpec)
+		polls   int
+	)
+
+	client := nodeDrainerMock{
+		nodeList: func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			return []swarm.Node{
+				{ID: "active", Spec: swarm.NodeSpec{Availability: swarm.NodeAvailabilityActive, Annotations: swarm.Annotations{Labels: map[string]string{"foo": "bar"}}}},
+				{ID: "pause", Spec: swarm.NodeSpec{Availability: swarm.NodeAvailabilityPause}},
+				{ID: "drain", Spec: swarm.NodeSpec{Availability: swarm.NodeAvailabilityDrain}},
+			}, nil
+		},
+		nodeUpdate: func(ctx context.Context, nodeID string, version swarm.Version, spec swarm.NodeSpec) error {
+			updated[nodeID] = spec
+			return nil
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			polls++
+
+			if polls == 1 {
+				return []swarm.Task{
+					{NodeID: "active", Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+				}, nil
+			}
+
+			return []swarm.Task{
+				{NodeID: "active", Status: swarm.TaskStatus{State: swarm.TaskStateShutdown}},
+				{Status: swarm.TaskStatus{State: swarm.TaskStatePending}},
+			}, nil
+		},
+	}
+
+	require.NoError(t, DrainNodes(context.Background(), client))
+
+	assert.Equal(
+		t,
+		map[string]swarm.NodeSpec{
+			"active": {
+				Availability: swarm.NodeAvailabilityDrain,
+				Annotations:  swarm.Annotations{Labels: map[string]string{"foo": "bar", DrainedAvailabilityLabel: "active"}},
+			},
+			"pause": {
+				Availability: swarm.NodeAvailabilityDrain,
+				Annotations:  swarm.Annotations{Labels: map[string]string{DrainedAvailabilityLabel: "pause"}},
+			},
+		},
+		updated,
+	)
+	assert.Equal(t, 2, polls)
+}
+
+func TestRestoreDrainedNodes(t *testing.T) {
+	updated := make(map[string]swarm.NodeSpec)
+
+	client := nodeDrainerMock{
+		nodeList: func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			return []swarm.Node{
+				{ID: "drained", Spec: swarm.NodeSpec{
+					Availability: swarm.NodeAvailabilityDrain,
+					Annotations:  swarm.Annotations{Labels: map[string]string{"foo": "bar", DrainedAvailabilityLabel: "pause"}},
+				}},
+				{ID: "user-drained", Spec: swarm.NodeSpec{Availability: swarm.NodeAvailabilityDrain}},
+			}, nil
+		},
+		nodeUpdate: func(ctx context.Context, nodeID string, version swarm.Version, spec swarm.NodeSpec) error {
+			updated[nodeID] = spec
+			return nil
+		},
+	}
+
+	require.NoError(t, RestoreDrainedNodes(context.Background(), client))
+
+	assert.Equal(
+		t,
+		map[string]swarm.NodeSpec{
+			"drained": {
+				Availability: swarm.NodeAvailabilityPause,
+				Annotations:  swarm.Annotations{Labels: map[string]string{"foo": "bar"}},
+			},
+		},
+		updated,
+	)
+}
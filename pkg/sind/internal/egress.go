@@ -0,0 +1,37 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// egressChain is the iptables chain restricting the outbound traffic of a node.
+const egressChain = "SIND-EGRESS"
+
+// egressSetup returns the shell commands restricting the outbound traffic leaving a node through its cluster network
+// interface, its own and the one of its containers, to the cluster network subnet and given allowed destinations.
+// Replies to inbound connections, e.g. on published ports, are still allowed.
+func egressSetup(allowlist []string) string {
+	cmds := []string{
+		`SUBNET=$(ip -4 route show dev eth0 scope link | cut -d ' ' -f 1)`,
+		fmt.Sprintf("{ iptables -N %[1]s || iptables -F %[1]s; }", egressChain),
+		fmt.Sprintf("iptables -A %s -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN", egressChain),
+		fmt.Sprintf(`iptables -A %s -d "$SUBNET" -j RETURN`, egressChain),
+	}
+
+	for _, destination := range allowlist {
+		cmds = append(cmds, fmt.Sprintf("iptables -A %s -d %s -j RETURN", egressChain, destination))
+	}
+
+	// The daemon started afterwards appends its FORWARD rules after the jump to the egress chain, only the jump to its
+	// DOCKER-USER chain comes first.
+	return strings.Join(
+		append(
+			cmds,
+			fmt.Sprintf("iptables -A %s -j REJECT", egressChain),
+			fmt.Sprintf("iptables -I OUTPUT -o eth0 -j %s", egressChain),
+			fmt.Sprintf("iptables -I FORWARD -o eth0 -j %s", egressChain),
+		),
+		" && ",
+	)
+}
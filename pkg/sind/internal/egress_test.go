@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEgressSetup(t *testing.T) {
+	testCases := []struct {
+		desc         string
+		allowlist    []string
+		expectedCmds []string
+	}{
+		{
+			desc: "without allowed destinations",
+			expectedCmds: []string{
+				`SUBNET=$(ip -4 route show dev eth0 scope link | cut -d ' ' -f 1)`,
+				`{ iptables -N SIND-EGRESS || iptables -F SIND-EGRESS; }`,
+				`iptables -A SIND-EGRESS -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN`,
+				`iptables -A SIND-EGRESS -d "$SUBNET" -j RETURN`,
+				`iptables -A SIND-EGRESS -j REJECT`,
+				`iptables -I OUTPUT -o eth0 -j SIND-EGRESS`,
+				`iptables -I FORWARD -o eth0 -j SIND-EGRESS`,
+			},
+		},
+		{
+			desc:      "with allowed destinations",
+			allowlist: []string{"10.10.0.0/16", "192.168.1.1"},
+			expectedCmds: []string{
+				`SUBNET=$(ip -4 route show dev eth0 scope link | cut -d ' ' -f 1)`,
+				`{ iptables -N SIND-EGRESS || iptables -F SIND-EGRESS; }`,
+				`iptables -A SIND-EGRESS -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN`,
+				`iptables -A SIND-EGRESS -d "$SUBNET" -j RETURN`,
+				`iptables -A SIND-EGRESS -d 10.10.0.0/16 -j RETURN`,
+				`iptables -A SIND-EGRESS -d 192.168.1.1 -j RETURN`,
+				`iptables -A SIND-EGRESS -j REJECT`,
+				`iptables -I OUTPUT -o eth0 -j SIND-EGRESS`,
+				`iptables -I FORWARD -o eth0 -j SIND-EGRESS`,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, strings.Join(test.expectedCmds, " && "), egressSetup(test.allowlist))
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+type eventsStreamer interface {
+	Events(context.Context, types.EventsOptions) (<-chan events.Message, <-chan error)
+}
+
+// HostEvents streams the lifecycle events of the containers of a cluster, as seen by the docker host.
+func HostEvents(ctx context.Context, client eventsStreamer, clusterName string) (<-chan events.Message, <-chan error) {
+	return client.Events(ctx, types.EventsOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", events.ContainerEventType),
+			filters.Arg("label", ClusterLabel(clusterName)),
+		),
+	})
+}
+
+// ClustersEvents streams the events of the containers of all the clusters of the docker host.
+func ClustersEvents(ctx context.Context, client eventsStreamer) (<-chan events.Message, <-chan error) {
+	return client.Events(ctx, types.EventsOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("type", events.ContainerEventType),
+			filters.Arg("label", ClusterNameLabel),
+		),
+	})
+}
+
+// NetworkEvents streams the events of given networks, as seen by the docker host.
+func NetworkEvents(ctx context.Context, client eventsStreamer, networks []string) (<-chan events.Message, <-chan error) {
+	args := filters.NewArgs(filters.Arg("type", events.NetworkEventType))
+
+	for _, network := range networks {
+		args.Add("network", network)
+	}
+
+	return client.Events(ctx, types.EventsOptions{Filters: args})
+}
+
+type execAttacher interface {
+	ContainerExecCreate(context.Context, string, types.ExecConfig) (types.IDResponse, error)
+	ContainerExecAttach(context.Context, string, types.ExecStartCheck) (types.HijackedResponse, error)
+}
+
+// NodeEvents streams the events emitted by the docker daemon of a node to out, restricted to given event types if any.
+// It blocks until the context is done or the stream ends.
+func NodeEvents(ctx context.Context, client execAttacher, cID string, eventTypes []string, out chan<- events.Message) error {
+	cmd := []string{"docker", "events", "--format", "{{json .}}"}
+
+	for _, eventType := range eventTypes {
+		cmd = append(cmd, "--filter", "type="+eventType)
+	}
+
+	exec, err := client.ContainerExecCreate(
+		ctx,
+		cID,
+		types.ExecConfig{
+			Cmd:          cmd,
+			AttachStdout: true,
+			AttachStderr: true,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("unable to create the events exec on node %q: %w", cID, err)
+	}
+
+	stream, err := client.ContainerExecAttach(ctx, exec.ID, types.ExecStartCheck{})
+	if err != nil {
+		return fmt.Errorf("unable to attach to the events exec on node %q: %w", cID, err)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Closing the stream unblocks the decoder when the context is done.
+	go func() {
+		<-ctx.Done()
+		stream.Close()
+	}()
+
+	stdout, stdoutWriter := io.Pipe()
+
+	go func() {
+		_, err := stdcopy.StdCopy(stdoutWriter, ioutil.Discard, stream.Reader)
+		stdoutWriter.CloseWithError(err)
+	}()
+
+	decoder := json.NewDecoder(stdout)
+
+	for {
+		var msg events.Message
+
+		if err := decoder.Decode(&msg); err != nil {
+			if ctx.Err() != nil || err == io.EOF {
+				return nil
+			}
+
+			return fmt.Errorf("unable to decode an event of node %q: %w", cID, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- msg:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventsStreamerMock func(context.Context, types.EventsOptions) (<-chan events.Message, <-chan error)
+
+func (e eventsStreamerMock) Events(ctx context.Context, opts types.EventsOptions) (<-chan events.Message, <-chan error) {
+	return e(ctx, opts)
+}
+
+func TestHostEvents(t *testing.T) {
+	var sentOpts types.EventsOptions
+
+	client := eventsStreamerMock(func(ctx context.Context, opts types.EventsOptions) (<-chan events.Message, <-chan error) {
+		sentOpts = opts
+		return nil, nil
+	})
+
+	_, _ = HostEvents(context.Background(), client, "test")
+
+	assert.True(t, sentOpts.Filters.ExactMatch("type", "container"))
+	assert.True(t, sentOpts.Filters.ExactMatch("label", ClusterLabel("test")))
+}
+
+func TestClustersEvents(t *testing.T) {
+	var sentOpts types.EventsOptions
+
+	client := eventsStreamerMock(func(ctx context.Context, opts types.EventsOptions) (<-chan events.Message, <-chan error) {
+		sentOpts = opts
+		return nil, nil
+	})
+
+	_, _ = ClustersEvents(context.Background(), client)
+
+	assert.True(t, sentOpts.Filters.ExactMatch("type", "container"))
+	assert.True(t, sentOpts.Filters.ExactMatch("label", ClusterNameLabel))
+}
+
+func TestNetworkEvents(t *testing.T) {
+	var sentOpts types.EventsOptions
+
+	client := eventsStreamerMock(func(ctx context.Context, opts types.EventsOptions) (<-chan events.Message, <-chan error) {
+		sentOpts = opts
+		return nil, nil
+	})
+
+	_, _ = NetworkEvents(context.Background(), client, []string{"test-net", "shared-net"})
+
+	assert.True(t, sentOpts.Filters.ExactMatch("type", "network"))
+	assert.True(t, sentOpts.Filters.ExactMatch("network", "test-net"))
+	assert.True(t, sentOpts.Filters.ExactMatch("network", "shared-net"))
+}
+
+type execAttacherMock struct {
+	containerExecCreate func(context.Context, string, types.ExecConfig) (types.IDResponse, error)
+	containerExecAttach func(context.Context, string, types.ExecStartCheck) (types.HijackedResponse, error)
+}
+
+func (m *execAttacherMock) ContainerExecCreate(ctx context.Context, cID string, cfg types.ExecConfig) (types.IDResponse, error) {
+	return m.containerExecCreate(ctx, cID, cfg)
+}
+
+func (m *execAttacherMock) ContainerExecAttach(ctx context.Context, execID string, cfg types.ExecStartCheck) (types.HijackedResponse, error) {
+	return m.containerExecAttach(ctx, execID, cfg)
+}
+
+func TestNodeEvents(t *testing.T) {
+	ctx := context.Background()
+	serverConn, clientConn := net.Pipe()
+
+	sentEvents := []events.Message{
+		{Type: "service", Action: "create", Actor: events.Actor{ID: "a"}},
+		{Type: "service", Action: "remove", Actor: events.Actor{ID: "a"}},
+	}
+
+	go func() {
+		writer := stdcopy.NewStdWriter(serverConn, stdcopy.Stdout)
+		encoder := json.NewEncoder(writer)
+
+		for _, event := range sentEvents {
+			require.NoError(t, encoder.Encode(event))
+		}
+
+		serverConn.Close()
+	}()
+
+	var sentCmd []string
+
+	client := execAttacherMock{
+		containerExecCreate: func(ctx context.Context, cID string, cfg types.ExecConfig) (types.IDResponse, error) {
+			assert.Equal(t, "node", cID)
+			sentCmd = cfg.Cmd
+			return types.IDResponse{ID: "exec"}, nil
+		},
+		containerExecAttach: func(ctx context.Context, execID string, cfg types.ExecStartCheck) (types.HijackedResponse, error) {
+			assert.Equal(t, "exec", execID)
+			return types.HijackedResponse{Conn: clientConn, Reader: bufio.NewReader(clientConn)}, nil
+		},
+	}
+
+	out := make(chan events.Message, len(sentEvents))
+
+	err := NodeEvents(ctx, &client, "node", []string{"service"}, out)
+	require.NoError(t, err)
+	close(out)
+
+	assert.Equal(t, []string{"docker", "events", "--format", "{{json .}}", "--filter", "type=service"}, sentCmd)
+
+	var received []events.Message
+	for event := range out {
+		received = append(received, event)
+	}
+
+	assert.Equal(t, sentEvents, received)
+}
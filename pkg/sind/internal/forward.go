@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// ErrTaskNotFound is returned when a service has no running task in a given slot.
+var ErrTaskNotFound = errors.New("task not found")
+
+// ForwarderConfig is the configuration of a port forwarder service.
+type ForwarderConfig struct {
+	Name     string
+	ImageRef string
+
+	// Networks are the swarm networks the forwarder is attached to, to reach its target.
+	Networks []string
+
+	TargetHost string
+	TargetPort uint16
+}
+
+type forwarderCreator interface {
+	serviceUpdater
+	ServiceCreate(context.Context, swarm.ServiceSpec, types.ServiceCreateOptions) (types.ServiceCreateResponse, error)
+}
+
+// CreateForwarder runs a service forwarding a port of the swarm ingress to TargetHost:TargetPort, and waits for it to be
+// running. It returns the ID of the service and the ingress port it publishes.
+func CreateForwarder(ctx context.Context, client forwarderCreator, cfg ForwarderConfig) (string, uint16, error) {
+	replicas := uint64(1)
+
+	networks := make([]swarm.NetworkAttachmentConfig, len(cfg.Networks))
+	for i, network := range cfg.Networks {
+		networks[i] = swarm.NetworkAttachmentConfig{Target: network}
+	}
+
+	resp, err := client.ServiceCreate(
+		ctx,
+		swarm.ServiceSpec{
+			Annotations: swarm.Annotations{
+				Name:   cfg.Name,
+				Labels: map[string]string{ComponentLabel: ComponentPortForwarder},
+			},
+			TaskTemplate: swarm.TaskSpec{
+				ContainerSpec: &swarm.ContainerSpec{
+					Image: cfg.ImageRef,
+					Args: []string{
+						fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", cfg.TargetPort),
+						fmt.Sprintf("TCP-CONNECT:%s:%d", cfg.TargetHost, cfg.TargetPort),
+					},
+				},
+				Networks: networks,
+			},
+			Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+			EndpointSpec: &swarm.EndpointSpec{
+				Ports: []swarm.PortConfig{
+					{
+						Protocol:    swarm.PortConfigProtocolTCP,
+						TargetPort:  uint32(cfg.TargetPort),
+						PublishMode: swarm.PortConfigPublishModeIngress,
+					},
+				},
+			},
+		},
+		types.ServiceCreateOptions{},
+	)
+	if err != nil {
+		return "", 0, fmt.Errorf("unable to create the forwarder service: %w", err)
+	}
+
+	if err = waitServiceReplicas(ctx, client, resp.ID, replicas); err != nil {
+		return resp.ID, 0, fmt.Errorf("unable to wait for the forwarder to be running: %w", err)
+	}
+
+	// The ingress port is picked by swarm.
+	port, err := PublishedPort(ctx, client, resp.ID, cfg.TargetPort)
+	if err != nil {
+		return resp.ID, 0, err
+	}
+
+	return resp.ID, uint16(port.PublishedPort), nil
+}
+
+// ServiceNetworks returns the IDs of the swarm networks a service is attached to.
+func ServiceNetworks(ctx context.Context, client serviceInspector, name string) ([]string, error) {
+	service, _, err := client.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect service %q: %w", name, err)
+	}
+
+	networks := make([]string, 0, len(service.Spec.TaskTemplate.Networks))
+	for _, network := range service.Spec.TaskTemplate.Networks {
+		networks = append(networks, network.Target)
+	}
+
+	return networks, nil
+}
+
+// TaskAddress returns the address of the running task of a service in a given slot, on the network networkID.
+func TaskAddress(ctx context.Context, client taskLister, service string, slot int, networkID string) (string, error) {
+	tasks, err := client.TaskList(ctx, types.TaskListOptions{
+		Filters: filters.NewArgs(
+			filters.Arg("service", service),
+			filters.Arg("desired-state", string(swarm.TaskStateRunning)),
+		),
+	})
+	if err != nil {
+		return "", fmt.Errorf("unable to list tasks of service %q: %w", service, err)
+	}
+
+	for _, task := range tasks {
+		if task.Slot != slot || task.Status.State != swarm.TaskStateRunning {
+			continue
+		}
+
+		for _, attachment := range task.NetworksAttachments {
+			if attachment.Network.ID != networkID || len(attachment.Addresses) == 0 {
+				continue
+			}
+
+			// Addresses are in the CIDR notation.
+			ip, _, err := net.ParseCIDR(attachment.Addresses[0])
+			if err != nil {
+				return "", fmt.Errorf("task %q has an invalid address: %w", task.ID, err)
+			}
+
+			return ip.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: service %q has no running task in slot %d", ErrTaskNotFound, service, slot)
+}
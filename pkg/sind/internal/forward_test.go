@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type forwarderCreatorMock struct {
+	serviceUpdaterMock
+
+	serviceCreate func(context.Context, swarm.ServiceSpec, types.ServiceCreateOptions) (types.ServiceCreateResponse, error)
+}
+
+func (m *forwarderCreatorMock) ServiceCreate(ctx context.Context, spec swarm.ServiceSpec, opts types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+	return m.serviceCreate(ctx, spec, opts)
+}
+
+func TestCreateForwarder(t *testing.T) {
+	ctx := context.Background()
+	cfg := ForwarderConfig{
+		Name:       "sind-port-forward-8080",
+		ImageRef:   "alpine/socat:latest",
+		Networks:   []string{"net-id"},
+		TargetHost: "web",
+		TargetPort: 80,
+	}
+
+	client := forwarderCreatorMock{
+		serviceUpdaterMock: serviceUpdaterMock{
+			taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+				assert.True(t, opts.Filters.ExactMatch("service", "forwarder-id"))
+				return []swarm.Task{{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}}}, nil
+			},
+			serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+				assert.Equal(t, "forwarder-id", id)
+
+				return swarm.Service{
+					Endpoint: swarm.Endpoint{
+						Ports: []swarm.PortConfig{
+							{TargetPort: 80, PublishedPort: 30000, PublishMode: swarm.PortConfigPublishModeIngress},
+						},
+					},
+				}, nil, nil
+			},
+		},
+		serviceCreate: func(ctx context.Context, spec swarm.ServiceSpec, opts types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+			assert.Equal(t, cfg.Name, spec.Name)
+			assert.Equal(t, ComponentPortForwarder, spec.Labels[ComponentLabel])
+			assert.Equal(t, cfg.ImageRef, spec.TaskTemplate.ContainerSpec.Image)
+			assert.Equal(t, []string{"TCP-LISTEN:80,fork,reuseaddr", "TCP-CONNECT:web:80"}, spec.TaskTemplate.ContainerSpec.Args)
+			assert.Equal(t, []swarm.NetworkAttachmentConfig{{Target: "net-id"}}, spec.TaskTemplate.Networks)
+			assert.Equal(t, uint32(80), spec.EndpointSpec.Ports[0].TargetPort)
+
+			return types.ServiceCreateResponse{ID: "forwarder-id"}, nil
+		},
+	}
+
+	id, port, err := CreateForwarder(ctx, &client, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "forwarder-id", id)
+	assert.Equal(t, uint16(30000), port)
+
+	client.serviceCreate = func(ctx context.Context, spec swarm.ServiceSpec, opts types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+		return types.ServiceCreateResponse{}, errors.New("nope")
+	}
+
+	id, _, err = CreateForwarder(ctx, &client, cfg)
+	assert.Error(t, err)
+	assert.Empty(t, id)
+}
+
+func TestServiceNetworks(t *testing.T) {
+	ctx := context.Background()
+	client := serviceUpdaterMock{
+		serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+			return swarm.Service{
+				Spec: swarm.ServiceSpec{
+					TaskTemplate: swarm.TaskSpec{
+						Networks: []swarm.NetworkAttachmentConfig{{Target: "a"}, {Target: "b"}},
+					},
+				},
+			}, nil, nil
+		},
+	}
+
+	networks, err := ServiceNetworks(ctx, &client, "web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, networks)
+}
+
+func TestTaskAddress(t *testing.T) {
+	ctx := context.Background()
+	task := func(slot int, state swarm.TaskState, address string) swarm.Task {
+		return swarm.Task{
+			Slot:   slot,
+			Status: swarm.TaskStatus{State: state},
+			NetworksAttachments: []swarm.NetworkAttachment{
+				{Network: swarm.Network{ID: "ingress"}, Addresses: []string{"10.255.0.5/16"}},
+				{Network: swarm.Network{ID: "net"}, Addresses: []string{address}},
+			},
+		}
+	}
+
+	client := serviceUpdaterMock{
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.True(t, opts.Filters.ExactMatch("service", "web"))
+
+			return []swarm.Task{
+				task(1, swarm.TaskStateRunning, "10.0.1.3/24"),
+				task(2, swarm.TaskStatePreparing, "10.0.1.4/24"),
+				task(3, swarm.TaskStateRunning, "nope"),
+			}, nil
+		},
+	}
+
+	address, err := TaskAddress(ctx, &client, "web", 1, "net")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.1.3", address)
+
+	_, err = TaskAddress(ctx, &client, "web", 2, "net")
+	assert.True(t, errors.Is(err, ErrTaskNotFound))
+
+	_, err = TaskAddress(ctx, &client, "web", 3, "net")
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+type nodeRecreator interface {
+	nodeCreator
+	containerContentReader
+	volumeRemover
+	ContainerInspect(context.Context, string) (types.ContainerJSON, error)
+	ContainerRemove(context.Context, string, types.ContainerRemoveOptions) error
+}
+
+// RecreateNode replaces a node container by a new one with the same configuration, TLS material and client
+// configuration, and a fresh docker daemon state.
+func RecreateNode(ctx context.Context, client nodeRecreator, cID string) (string, error) {
+	node, err := client.ContainerInspect(ctx, cID)
+	if err != nil {
+		return "", fmt.Errorf("unable to inspect node %q: %w", cID, err)
+	}
+
+	var endpoints map[string]*network.EndpointSettings
+
+	if node.NetworkSettings != nil {
+		endpoints = make(map[string]*network.EndpointSettings, len(node.NetworkSettings.Networks))
+
+		// Only keep the endpoints configuration, runtime settings are allocated again by the daemon.
+		for name, endpoint := range node.NetworkSettings.Networks {
+			endpoints[name] = &network.EndpointSettings{
+				NetworkID:  endpoint.NetworkID,
+				IPAMConfig: endpoint.IPAMConfig,
+				Aliases:    endpoint.Aliases,
+			}
+		}
+	}
+
+	var (
+		material     *TLSMaterial
+		clientConfig string
+	)
+
+	// The TLS material and the client configuration live in the container filesystem, copy them to the replacement.
+	if node.Config.Labels[TLSLabel] == "true" {
+		if material, err = ReadTLS(ctx, client, cID); err != nil {
+			return "", err
+		}
+	}
+
+	if node.Config.Labels[RegistryAuthLabel] == "true" {
+		if clientConfig, err = ReadClientConfig(ctx, client, cID); err != nil {
+			return "", err
+		}
+	}
+
+	// The node daemon state lives in a volume, remove it with the container. Named volumes are not removed with it.
+	if err = client.ContainerRemove(ctx, cID, types.ContainerRemoveOptions{Force: true, RemoveVolumes: true}); err != nil {
+		return "", fmt.Errorf("unable to remove node %q: %w", cID, err)
+	}
+
+	if name := dataVolume(node.Mounts); name != "" {
+		if err = client.VolumeRemove(ctx, name, true); err != nil {
+			return "", fmt.Errorf("unable to remove the volume of node %q: %w", cID, err)
+		}
+	}
+
+	newID, err := createContainer(ctx, client, node.Config, node.HostConfig, &network.NetworkingConfig{EndpointsConfig: endpoints})
+	if err != nil {
+		return "", fmt.Errorf("unable to create a replacement for node %q: %w", cID, err)
+	}
+
+	if material != nil {
+		if err = WriteTLS(ctx, client, newID, material); err != nil {
+			return "", err
+		}
+	}
+
+	if clientConfig != "" {
+		if err = WriteClientConfig(ctx, client, newID, clientConfig); err != nil {
+			return "", err
+		}
+	}
+
+	if err = startContainer(ctx, client, newID); err != nil {
+		return "", fmt.Errorf("unable to start the replacement of node %q: %w", cID, err)
+	}
+
+	return newID, nil
+}
+
+type swarmNodeRemover interface {
+	NodeList(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+	NodeUpdate(context.Context, string, swarm.Version, swarm.NodeSpec) error
+	NodeRemove(context.Context, string, types.NodeRemoveOptions) error
+}
+
+// RemoveReplacedSwarmNodes removes from the swarm the nodes with given hostname but the most recent one, which replaced
+// them. Managers are demoted before being removed.
+func RemoveReplacedSwarmNodes(ctx context.Context, client swarmNodeRemover, hostname string) error {
+	nodes, err := client.NodeList(ctx, types.NodeListOptions{Filters: filters.NewArgs(filters.Arg("name", hostname))})
+	if err != nil {
+		return fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	var (
+		replaced []swarm.Node
+		latest   *swarm.Node
+	)
+
+	for i, node := range nodes {
+		// The name filter matches hostname prefixes.
+		if node.Description.Hostname != hostname {
+			continue
+		}
+
+		if latest == nil {
+			latest = &nodes[i]
+			continue
+		}
+
+		if node.CreatedAt.After(latest.CreatedAt) {
+			replaced = append(replaced, *latest)
+			latest = &nodes[i]
+
+			continue
+		}
+
+		replaced = append(replaced, node)
+	}
+
+	for _, node := range replaced {
+		if node.Spec.Role == swarm.NodeRoleManager {
+			spec := node.Spec
+			spec.Role = swarm.NodeRoleWorker
+
+			if err = client.NodeUpdate(ctx, node.ID, node.Version, spec); err != nil {
+				return fmt.Errorf("unable to demote the replaced node %q: %w", node.ID, err)
+			}
+		}
+
+		if err = client.NodeRemove(ctx, node.ID, types.NodeRemoveOptions{Force: true}); err != nil {
+			return fmt.Errorf("unable to remove the replaced node %q: %w", node.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,237 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type nodeRecreatorMock struct {
+	nodeStarterMock
+
+	containerInspect  func(context.Context, string) (types.ContainerJSON, error)
+	containerRemove   func(context.Context, string, types.ContainerRemoveOptions) error
+	copyFromContainer func(context.Context, string, string) (io.ReadCloser, types.ContainerPathStat, error)
+	volumeRemove      func(context.Context, string, bool) error
+}
+
+func (m nodeRecreatorMock) ContainerInspect(ctx context.Context, cID string) (types.ContainerJSON, error) {
+	return m.containerInspect(ctx, cID)
+}
+
+func (m nodeRecreatorMock) ContainerRemove(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+	return m.containerRemove(ctx, cID, opts)
+}
+
+func (m nodeRecreatorMock) CopyFromContainer(ctx context.Context, cID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+	return m.copyFromContainer(ctx, cID, srcPath)
+}
+
+func (m nodeRecreatorMock) VolumeRemove(ctx context.Context, name string, force bool) error {
+	return m.volumeRemove(ctx, name, force)
+}
+
+func TestRecreateNode(t *testing.T) {
+	cConfig := &container.Config{Hostname: "sind-test-worker-0", Image: "docker:20.10-dind"}
+	hConfig := &container.HostConfig{Privileged: true}
+	ipamConfig := &network.EndpointIPAMConfig{IPv4Address: "10.0.117.3"}
+
+	var removed, volumeRemoved bool
+
+	client := nodeRecreatorMock{
+		nodeStarterMock: nodeStarterMock{
+			containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+				assert.True(t, volumeRemoved)
+				assert.Equal(t, cConfig, ccfg)
+				assert.Equal(t, hConfig, hcfg)
+				assert.Equal(t, "sind-test-worker-0", cName)
+				assert.Equal(
+					t,
+					map[string]*network.EndpointSettings{"test-net": {NetworkID: "net", IPAMConfig: ipamConfig}},
+					ncfg.EndpointsConfig,
+				)
+
+				return container.ContainerCreateCreatedBody{ID: "new"}, nil
+			},
+			containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+				assert.Equal(t, "new", cID)
+				return nil
+			},
+		},
+		containerInspect: func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: cID, HostConfig: hConfig},
+				Mounts: []types.MountPoint{
+					{Type: mount.TypeVolume, Name: "sind-test-worker-0-data", Destination: "/var/lib/docker"},
+				},
+				Config: cConfig,
+				NetworkSettings: &types.NetworkSettings{
+					Networks: map[string]*network.EndpointSettings{
+						"test-net": {NetworkID: "net", IPAMConfig: ipamConfig, IPAddress: "10.0.117.3", EndpointID: "endpoint"},
+					},
+				},
+			}, nil
+		},
+		containerRemove: func(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+			assert.Equal(t, "old", cID)
+			assert.True(t, opts.Force)
+			assert.True(t, opts.RemoveVolumes)
+			removed = true
+
+			return nil
+		},
+		volumeRemove: func(ctx context.Context, name string, force bool) error {
+			assert.True(t, removed)
+			assert.Equal(t, "sind-test-worker-0-data", name)
+			volumeRemoved = true
+
+			return nil
+		},
+	}
+
+	newID, err := RecreateNode(context.Background(), client, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "new", newID)
+	assert.True(t, volumeRemoved)
+}
+
+func TestRecreateNodeKeepsTLS(t *testing.T) {
+	material := &TLSMaterial{CACert: "ca", CAKey: "ca-key", ServerCert: "server-cert", ServerKey: "server-key", ClientCert: "client-cert", ClientKey: "client-key"}
+
+	var archive bytes.Buffer
+
+	err := WriteTLS(
+		context.Background(),
+		containerContentCopierMock(func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+			_, err := io.Copy(&archive, content)
+			return err
+		}),
+		"old",
+		material,
+	)
+	require.NoError(t, err)
+
+	var removed, written bool
+
+	client := nodeRecreatorMock{
+		nodeStarterMock: nodeStarterMock{
+			containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+				return container.ContainerCreateCreatedBody{ID: "new"}, nil
+			},
+			containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+				assert.True(t, written)
+				return nil
+			},
+			copyToContainer: func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+				assert.Equal(t, "new", cID)
+
+				read, err := readTLSArchive(content)
+				require.NoError(t, err)
+				assert.Equal(t, material, read)
+
+				written = true
+
+				return nil
+			},
+		},
+		containerInspect: func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+			return types.ContainerJSON{
+				ContainerJSONBase: &types.ContainerJSONBase{ID: cID, HostConfig: &container.HostConfig{}},
+				Config:            &container.Config{Hostname: "sind-test-manager-0", Labels: map[string]string{TLSLabel: "true"}},
+			}, nil
+		},
+		containerRemove: func(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+			removed = true
+			return nil
+		},
+		copyFromContainer: func(ctx context.Context, cID, srcPath string) (io.ReadCloser, types.ContainerPathStat, error) {
+			// The material must be read before the container is removed.
+			assert.False(t, removed)
+			assert.Equal(t, "old", cID)
+			assert.Equal(t, TLSDir, srcPath)
+
+			return ioutil.NopCloser(bytes.NewReader(archive.Bytes())), types.ContainerPathStat{}, nil
+		},
+	}
+
+	newID, err := RecreateNode(context.Background(), client, "old")
+	require.NoError(t, err)
+	assert.Equal(t, "new", newID)
+	assert.True(t, written)
+}
+
+type swarmNodeRemoverMock struct {
+	nodeList   func(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+	nodeUpdate func(context.Context, string, swarm.Version, swarm.NodeSpec) error
+	nodeRemove func(context.Context, string, types.NodeRemoveOptions) error
+}
+
+func (m swarmNodeRemoverMock) NodeList(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+	return m.nodeList(ctx, opts)
+}
+
+func (m swarmNodeRemoverMock) NodeUpdate(ctx context.Context, nodeID string, version swarm.Version, spec swarm.NodeSpec) error {
+	return m.nodeUpdate(ctx, nodeID, version, spec)
+}
+
+func (m swarmNodeRemoverMock) NodeRemove(ctx context.Context, nodeID string, opts types.NodeRemoveOptions) error {
+	return m.nodeRemove(ctx, nodeID, opts)
+}
+
+func TestRemoveReplacedSwarmNodes(t *testing.T) {
+	now := time.Now()
+
+	node := func(id, hostname string, role swarm.NodeRole, createdAt time.Time) swarm.Node {
+		return swarm.Node{
+			ID:          id,
+			Meta:        swarm.Meta{CreatedAt: createdAt},
+			Spec:        swarm.NodeSpec{Role: role},
+			Description: swarm.NodeDescription{Hostname: hostname},
+		}
+	}
+
+	var (
+		demoted []string
+		removed []string
+	)
+
+	client := swarmNodeRemoverMock{
+		nodeList: func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			assert.True(t, opts.Filters.ExactMatch("name", "sind-test-manager-1"))
+
+			return []swarm.Node{
+				node("old", "sind-test-manager-1", swarm.NodeRoleManager, now.Add(-time.Hour)),
+				node("new", "sind-test-manager-1", swarm.NodeRoleManager, now),
+				node("other", "sind-test-manager-10", swarm.NodeRoleManager, now.Add(-time.Hour)),
+				node("older", "sind-test-manager-1", swarm.NodeRoleWorker, now.Add(-2*time.Hour)),
+			}, nil
+		},
+		nodeUpdate: func(ctx context.Context, nodeID string, version swarm.Version, spec swarm.NodeSpec) error {
+			assert.Equal(t, swarm.NodeRoleWorker, spec.Role)
+			demoted = append(demoted, nodeID)
+			return nil
+		},
+		nodeRemove: func(ctx context.Context, nodeID string, opts types.NodeRemoveOptions) error {
+			assert.True(t, opts.Force)
+			removed = append(removed, nodeID)
+			return nil
+		},
+	}
+
+	err := RemoveReplacedSwarmNodes(context.Background(), client, "sind-test-manager-1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"old"}, demoted)
+	assert.Equal(t, []string{"old", "older"}, removed)
+}
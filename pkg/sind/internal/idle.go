@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// activityActions are the actions of the events counted as activity, the health checks and execs of the containers are
+// not.
+var activityActions = []string{"create", "update", "remove", "start", "destroy"}
+
+// SwarmActivityTypes are the types of the events telling a swarm API is in use.
+var SwarmActivityTypes = []string{
+	events.ServiceEventType,
+	events.NodeEventType,
+	events.SecretEventType,
+	events.ConfigEventType,
+}
+
+// DaemonActivityTypes are the types of the events telling a plain docker daemon is in use.
+var DaemonActivityTypes = []string{events.ContainerEventType}
+
+// Activity returns the amount of events of given types emitted by a docker daemon between since and until.
+func Activity(ctx context.Context, client eventsStreamer, eventTypes []string, since, until time.Time) (int, error) {
+	args := filters.NewArgs()
+
+	for _, eventType := range eventTypes {
+		args.Add("type", eventType)
+	}
+
+	for _, action := range activityActions {
+		args.Add("event", action)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The daemon ends the stream once the until date is reached.
+	messages, errs := client.Events(ctx, types.EventsOptions{
+		Since:   unixNano(since),
+		Until:   unixNano(until),
+		Filters: args,
+	})
+
+	var count int
+
+	for {
+		select {
+		case <-messages:
+			count++
+		case err := <-errs:
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+
+			return 0, fmt.Errorf("unable to read the daemon events: %w", err)
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+// RunningTasks returns the amount of tasks of a swarm in the running state.
+func RunningTasks(ctx context.Context, client taskLister) (int, error) {
+	tasks, err := client.TaskList(ctx, types.TaskListOptions{
+		Filters: filters.NewArgs(filters.Arg("desired-state", string(swarm.TaskStateRunning))),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("unable to list the swarm tasks: %w", err)
+	}
+
+	var count int
+
+	for _, task := range tasks {
+		if task.Status.State == swarm.TaskStateRunning {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// unixNano formats a date as expected by the events API, seconds and nanoseconds since the epoch.
+func unixNano(t time.Time) string {
+	return fmt.Sprintf("%d.%09d", t.Unix(), t.Nanosecond())
+}
@@ -0,0 +1,88 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActivity(t *testing.T) {
+	var sentOpts types.EventsOptions
+
+	since := time.Unix(1622541600, 5)
+	until := since.Add(time.Minute)
+
+	client := eventsStreamerMock(func(ctx context.Context, opts types.EventsOptions) (<-chan events.Message, <-chan error) {
+		sentOpts = opts
+
+		messages := make(chan events.Message)
+		errs := make(chan error, 1)
+
+		go func() {
+			messages <- events.Message{Type: events.ServiceEventType, Action: "create"}
+			messages <- events.Message{Type: events.NodeEventType, Action: "update"}
+			errs <- io.EOF
+		}()
+
+		return messages, errs
+	})
+
+	count, err := Activity(context.Background(), client, SwarmActivityTypes, since, until)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, count)
+	assert.Equal(t, "1622541600.000000005", sentOpts.Since)
+	assert.Equal(t, "1622541660.000000005", sentOpts.Until)
+	assert.True(t, sentOpts.Filters.ExactMatch("type", "service"))
+	assert.True(t, sentOpts.Filters.ExactMatch("type", "config"))
+	assert.True(t, sentOpts.Filters.ExactMatch("event", "update"))
+	assert.False(t, sentOpts.Filters.ExactMatch("event", "exec_start"))
+}
+
+func TestActivityFailsOnStreamError(t *testing.T) {
+	streamErr := errors.New("boom")
+
+	client := eventsStreamerMock(func(ctx context.Context, opts types.EventsOptions) (<-chan events.Message, <-chan error) {
+		errs := make(chan error, 1)
+		errs <- streamErr
+
+		return nil, errs
+	})
+
+	_, err := Activity(context.Background(), client, DaemonActivityTypes, time.Now(), time.Now())
+	assert.True(t, errors.Is(err, streamErr))
+}
+
+type taskListerMock func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+
+func (m taskListerMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return m(ctx, opts)
+}
+
+func TestRunningTasks(t *testing.T) {
+	var sentOpts types.TaskListOptions
+
+	client := taskListerMock(func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+		sentOpts = opts
+
+		return []swarm.Task{
+			{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+			{Status: swarm.TaskStatus{State: swarm.TaskStatePreparing}},
+			{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+		}, nil
+	})
+
+	count, err := RunningTasks(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, count)
+	assert.True(t, sentOpts.Filters.ExactMatch("desired-state", "running"))
+}
@@ -2,9 +2,11 @@ package internal
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"strings"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
@@ -25,7 +27,7 @@ func ImageExists(ctx context.Context, docker imageLister, imageRef string) (bool
 		Filters: filters.NewArgs(filters.Arg(imageFilterReference, imageRef)),
 	})
 	if err != nil {
-		return false, fmt.Errorf("unable to list images: %v", err)
+		return false, fmt.Errorf("unable to list images: %w", err)
 	}
 
 	if len(imageList) == 0 {
@@ -43,38 +45,61 @@ type imagePuller interface {
 func PullImage(ctx context.Context, docker imagePuller, imageRef string) error {
 	out, err := docker.ImagePull(ctx, imageRef, types.ImagePullOptions{})
 	if err != nil {
-		return fmt.Errorf("unable to pull %q: %v", imageRef, err)
+		return fmt.Errorf("unable to pull %q: %w", imageRef, err)
 	}
 	defer out.Close()
 
 	if _, err = io.Copy(ioutil.Discard, out); err != nil {
-		return fmt.Errorf("unable to pull %q: %v", imageRef, err)
+		return fmt.Errorf("unable to pull %q: %w", imageRef, err)
 	}
 
 	return nil
 }
 
-type imageSaver interface {
-	ImageSave(ctx context.Context, refs []string) (io.ReadCloser, error)
+type imageLoader interface {
+	ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error)
 }
 
-// SaveImages saves all given images refs to given target.
-func SaveImages(ctx context.Context, hostClient imageSaver, dest io.WriteSeeker, refs []string) error {
-	imgReader, err := hostClient.ImageSave(ctx, refs)
+// LoadImages loads the images of an archive written by docker save.
+func LoadImages(ctx context.Context, hostClient imageLoader, src io.Reader) error {
+	resp, err := hostClient.ImageLoad(ctx, src, true)
 	if err != nil {
-		return fmt.Errorf("unable to save the images: %v", err)
+		return fmt.Errorf("unable to load the images: %w", err)
 	}
-	defer imgReader.Close()
+	defer resp.Body.Close()
 
-	var bytes int64
+	// Load failures are reported in the progress messages, once the request succeeded.
+	decoder := json.NewDecoder(resp.Body)
 
-	if bytes, err = io.Copy(dest, imgReader); err != nil {
-		return fmt.Errorf("unable to save the images (copied %d): %v", bytes, err)
+	for {
+		var message struct {
+			Error string `json:"error"`
+		}
+
+		if err = decoder.Decode(&message); err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("unable to read the load progress: %w", err)
+		}
+
+		if message.Error != "" {
+			return fmt.Errorf("unable to load the images: %s", message.Error)
+		}
 	}
+}
 
-	if _, err = dest.Seek(0, 0); err != nil {
-		return fmt.Errorf("unable to seek the image: %v", err)
+// NodeImageID returns the ID of an image present on the docker daemon of a node, empty if the image is not present.
+func NodeImageID(ctx context.Context, client executor, cID, imageRef string) (string, error) {
+	result := execCapture(ctx, client, cID, []string{"docker", "image", "inspect", "--format", "{{.Id}}", imageRef})
+	if result.Err != nil {
+		return "", result.Err
 	}
 
-	return nil
+	if result.ExitCode != 0 {
+		return "", nil
+	}
+
+	return strings.TrimSpace(result.Stdout), nil
 }
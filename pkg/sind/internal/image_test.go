@@ -59,7 +59,11 @@ func TestImageExists(t *testing.T) {
 			res, err := ImageExists(ctx, mock, "foo")
 			assert.True(t, sentOpts.All)
 			assert.True(t, sentOpts.Filters.ExactMatch(imageFilterReference, "foo"))
-			assert.Equal(t, test.expectedError, err)
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
 			assert.Equal(t, test.expectedResult, res)
 		})
 	}
@@ -133,7 +137,11 @@ func TestPullImage(t *testing.T) {
 
 			err := PullImage(ctx, mock, "foo")
 
-			assert.Equal(t, test.expectedError, err)
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
 			if test.shouldClose {
 				assert.True(t, readerClosed)
 			}
@@ -141,54 +149,47 @@ func TestPullImage(t *testing.T) {
 	}
 }
 
-type imageSaverMock func(ctx context.Context, refs []string) (io.ReadCloser, error)
-
-func (s imageSaverMock) ImageSave(ctx context.Context, refs []string) (io.ReadCloser, error) {
-	return s(ctx, refs)
-}
-
-type mockWriteSeeker struct {
-	io.Writer
-
-	seek func(int64, int) (int64, error)
-}
+type imageLoaderMock func(context.Context, io.Reader, bool) (types.ImageLoadResponse, error)
 
-func (m mockWriteSeeker) Seek(offset int64, whence int) (int64, error) {
-	return m.seek(offset, whence)
+func (m imageLoaderMock) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	return m(ctx, input, quiet)
 }
 
-func TestSaveImages(t *testing.T) {
-	ctx := context.Background()
-
-	content := []byte("test")
-	refs := []string{"a", "b", "c"}
-
-	var (
-		seekCalled bool
-		seekOffset int64
-		seekWhence int
-	)
-
-	destBuf := &bytes.Buffer{}
-	dest := mockWriteSeeker{
-		Writer: destBuf,
-
-		seek: func(offset int64, whence int) (int64, error) {
-			seekCalled = true
-			seekOffset = offset
-			seekWhence = whence
-			return 0, nil
+func TestLoadImages(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		progress      string
+		expectedError string
+	}{
+		{
+			desc:     "loads the images",
+			progress: `{"stream":"Loaded image: foo:latest\n"}` + "\n",
+		},
+		{
+			desc:          "reports a load failure",
+			progress:      `{"errorDetail":{"message":"unexpected EOF"},"error":"unexpected EOF"}` + "\n",
+			expectedError: "unable to load the images: unexpected EOF",
 		},
 	}
 
-	client := imageSaverMock(func(ctx context.Context, refs []string) (io.ReadCloser, error) {
-		return ioutil.NopCloser(bytes.NewBuffer(content)), nil
-	})
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := imageLoaderMock(func(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+				content, err := ioutil.ReadAll(input)
+				require.NoError(t, err)
+				assert.Equal(t, "archive", string(content))
+				assert.True(t, quiet)
+
+				return types.ImageLoadResponse{Body: ioutil.NopCloser(bytes.NewBufferString(test.progress))}, nil
+			})
 
-	require.NoError(t, SaveImages(ctx, client, dest, refs))
+			err := LoadImages(context.Background(), client, bytes.NewBufferString("archive"))
+			if test.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
 
-	assert.Equal(t, content, destBuf.Bytes())
-	assert.True(t, seekCalled)
-	assert.EqualValues(t, 0, seekOffset)
-	assert.EqualValues(t, 0, seekWhence)
+			assert.EqualError(t, err, test.expectedError)
+		})
+	}
 }
@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// JobSpec describes a swarm job, a service which tasks run to completion instead of being restarted.
+type JobSpec struct {
+	// Name is the name of the job service, generated by swarm if empty.
+	Name     string
+	Image    string
+	Command  []string
+	Env      []string
+	Networks []string
+
+	// Global runs a task on each node of the swarm (global job), instead of Completions tasks (replicated job).
+	Global bool
+	// Completions is the amount of tasks of a replicated job to complete, 1 if zero.
+	Completions uint64
+	// MaxConcurrent is the maximum amount of tasks of a replicated job running at once, all of them if zero.
+	MaxConcurrent uint64
+}
+
+// completions returns the amount of tasks to complete for the job to succeed, 0 for a global job.
+func (s *JobSpec) completions() uint64 {
+	switch {
+	case s.Global:
+		return 0
+	case s.Completions == 0:
+		return 1
+	default:
+		return s.Completions
+	}
+}
+
+// jobCreateCmd returns the docker command creating the service of a job, labeled with its completions. Jobs are created
+// with the docker CLI of a node, as the docker API version sind uses predates them.
+func jobCreateCmd(spec JobSpec) []string {
+	cmd := []string{"docker", "service", "create", "--detach", "--quiet", "--restart-condition", "none"}
+
+	if spec.Name != "" {
+		cmd = append(cmd, "--name", spec.Name)
+	}
+
+	if spec.Global {
+		cmd = append(cmd, "--mode", "global-job")
+	} else {
+		cmd = append(
+			cmd,
+			"--mode", "replicated-job",
+			"--replicas", strconv.FormatUint(spec.completions(), 10),
+			"--label", JobCompletionsLabel+"="+strconv.FormatUint(spec.completions(), 10),
+		)
+
+		if spec.MaxConcurrent > 0 {
+			cmd = append(cmd, "--max-concurrent", strconv.FormatUint(spec.MaxConcurrent, 10))
+		}
+	}
+
+	for _, env := range spec.Env {
+		cmd = append(cmd, "--env", env)
+	}
+
+	for _, network := range spec.Networks {
+		cmd = append(cmd, "--network", network)
+	}
+
+	return append(append(cmd, spec.Image), spec.Command...)
+}
+
+// CreateJob creates a swarm job with the docker CLI of a manager node, and returns the ID of its service.
+func CreateJob(ctx context.Context, client executor, cID string, spec JobSpec) (string, error) {
+	out, err := ExecOutput(ctx, client, cID, jobCreateCmd(spec))
+	if err != nil {
+		return "", fmt.Errorf("unable to create the job: %w", err)
+	}
+
+	return strings.TrimSpace(out), nil
+}
+
+// JobStatus counts the tasks of a job by state.
+type JobStatus struct {
+	Completed int
+	// Failed counts the tasks which failed, were rejected or were shut down before completing.
+	Failed int
+	// Pending counts the tasks which are not terminated yet.
+	Pending int
+}
+
+// Succeeded returns true if none of the tasks of the job failed.
+func (s JobStatus) Succeeded() bool {
+	return s.Failed == 0
+}
+
+// done returns true if the job is over: no task is left pending and all the tasks to complete did, or one failed.
+// Global jobs have as many tasks to complete as nodes, which are all created at once.
+func (s JobStatus) done(completions uint64) bool {
+	if s.Pending > 0 || s.Completed+s.Failed == 0 {
+		return false
+	}
+
+	return completions == 0 || s.Failed > 0 || uint64(s.Completed) >= completions
+}
+
+func jobStatus(tasks []swarm.Task) JobStatus {
+	var status JobStatus
+
+	for _, task := range tasks {
+		switch {
+		case task.Status.State == swarm.TaskStateComplete:
+			status.Completed++
+		case taskTerminated(task.Status.State):
+			status.Failed++
+		default:
+			status.Pending++
+		}
+	}
+
+	return status
+}
+
+type jobWaiter interface {
+	serviceInspector
+	taskLister
+}
+
+// WaitJob waits until the job with given service ID or name is over, and returns the status of its tasks.
+func WaitJob(ctx context.Context, client jobWaiter, name string) (JobStatus, error) {
+	service, _, err := client.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+	if err != nil {
+		return JobStatus{}, fmt.Errorf("unable to inspect job %q: %w", name, err)
+	}
+
+	var completions uint64
+
+	if value, ok := service.Spec.Labels[JobCompletionsLabel]; ok {
+		if completions, err = strconv.ParseUint(value, 10, 64); err != nil {
+			return JobStatus{}, fmt.Errorf("job %q has invalid completions %q: %w", name, value, err)
+		}
+	}
+
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tasks, err := client.TaskList(ctx, types.TaskListOptions{
+				Filters: filters.NewArgs(filters.Arg("service", service.ID)),
+			})
+			if err != nil {
+				return JobStatus{}, fmt.Errorf("unable to list the tasks of job %q: %w", name, err)
+			}
+
+			if status := jobStatus(tasks); status.done(completions) {
+				return status, nil
+			}
+		case <-ctx.Done():
+			return JobStatus{}, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobCreateCmd(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		spec        JobSpec
+		expectedCmd []string
+	}{
+		{
+			desc: "replicated job",
+			spec: JobSpec{
+				Name:          "migrate",
+				Image:         "alpine",
+				Command:       []string{"echo", "hello"},
+				Env:           []string{"FOO=bar"},
+				Networks:      []string{"backend"},
+				Completions:   4,
+				MaxConcurrent: 2,
+			},
+			expectedCmd: []string{
+				"docker", "service", "create", "--detach", "--quiet", "--restart-condition", "none",
+				"--name", "migrate",
+				"--mode", "replicated-job", "--replicas", "4", "--label", "com.sind.job.completions=4",
+				"--max-concurrent", "2",
+				"--env", "FOO=bar",
+				"--network", "backend",
+				"alpine", "echo", "hello",
+			},
+		},
+		{
+			desc: "replicated job with default completions",
+			spec: JobSpec{Image: "alpine"},
+			expectedCmd: []string{
+				"docker", "service", "create", "--detach", "--quiet", "--restart-condition", "none",
+				"--mode", "replicated-job", "--replicas", "1", "--label", "com.sind.job.completions=1",
+				"alpine",
+			},
+		},
+		{
+			desc: "global job",
+			spec: JobSpec{Image: "alpine", Global: true},
+			expectedCmd: []string{
+				"docker", "service", "create", "--detach", "--quiet", "--restart-condition", "none",
+				"--mode", "global-job",
+				"alpine",
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expectedCmd, jobCreateCmd(test.spec))
+		})
+	}
+}
+
+func TestJobStatusDone(t *testing.T) {
+	testCases := []struct {
+		desc        string
+		status      JobStatus
+		completions uint64
+		expected    bool
+	}{
+		{desc: "without tasks", status: JobStatus{}, completions: 1, expected: false},
+		{desc: "with pending tasks", status: JobStatus{Completed: 1, Pending: 1}, completions: 2, expected: false},
+		{desc: "with tasks left to schedule", status: JobStatus{Completed: 2}, completions: 4, expected: false},
+		{desc: "with all tasks completed", status: JobStatus{Completed: 4}, completions: 4, expected: true},
+		{desc: "with a failed task", status: JobStatus{Completed: 1, Failed: 1}, completions: 4, expected: true},
+		{desc: "with a global job terminated", status: JobStatus{Completed: 3}, completions: 0, expected: true},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.status.done(test.completions))
+		})
+	}
+}
+
+type jobWaiterMock struct {
+	serviceInspectWithRaw func(context.Context, string, types.ServiceInspectOptions) (swarm.Service, []byte, error)
+	taskList              func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+func (m *jobWaiterMock) ServiceInspectWithRaw(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+	return m.serviceInspectWithRaw(ctx, id, opts)
+}
+
+func (m *jobWaiterMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return m.taskList(ctx, opts)
+}
+
+func TestWaitJob(t *testing.T) {
+	task := func(state swarm.TaskState) swarm.Task {
+		return swarm.Task{Status: swarm.TaskStatus{State: state}}
+	}
+
+	var calls int
+
+	client := &jobWaiterMock{
+		serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+			service := swarm.Service{ID: "job-id"}
+			service.Spec.Labels = map[string]string{JobCompletionsLabel: "3"}
+
+			return service, nil, nil
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.True(t, opts.Filters.ExactMatch("service", "job-id"))
+
+			calls++
+
+			if calls == 1 {
+				return []swarm.Task{task(swarm.TaskStateComplete), task(swarm.TaskStateRunning)}, nil
+			}
+
+			return []swarm.Task{task(swarm.TaskStateComplete), task(swarm.TaskStateComplete), task(swarm.TaskStateFailed)}, nil
+		},
+	}
+
+	status, err := WaitJob(context.Background(), client, "migrate")
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, JobStatus{Completed: 2, Failed: 1}, status)
+	assert.False(t, status.Succeeded())
+}
@@ -8,6 +8,59 @@ const (
 
 	// NodeRoleLabel is the label containing the cluster role applied to nodes (containers) of a cluster.
 	NodeRoleLabel = "com.sind.cluster.role"
+
+	// NetworkNameLabel is the label containing the name of the cluster network applied to nodes of a cluster.
+	NetworkNameLabel = "com.sind.cluster.network"
+
+	// ExpiresAtLabel is the label containing the RFC3339 date after which a cluster can be garbage collected.
+	ExpiresAtLabel = "com.sind.cluster.expires-at"
+
+	// IdleTimeoutLabel is the label containing the duration without activity after which a cluster is stopped by AutoStop.
+	IdleTimeoutLabel = "com.sind.cluster.idle-timeout"
+
+	// MetadataLabelPrefix prefixes the labels carrying the user defined metadata of a cluster.
+	MetadataLabelPrefix = "com.sind.cluster.metadata."
+
+	// ConnectedClustersLabel is the label containing the comma separated names of the clusters a shared network connects.
+	ConnectedClustersLabel = "com.sind.connected-clusters"
+
+	// PlainClusterLabel is the label applied to the nodes of a cluster created without forming a swarm.
+	PlainClusterLabel = "com.sind.cluster.plain"
+
+	// ExternalSwarmLabel is the label containing the comma separated manager addresses of the swarm, not managed by sind,
+	// the nodes of a cluster joined.
+	ExternalSwarmLabel = "com.sind.cluster.external-swarm"
+
+	// TLSLabel is the label applied to the nodes of a cluster which daemon port is secured with mutual TLS.
+	TLSLabel = "com.sind.cluster.tls"
+
+	// RegistryAuthLabel is the label applied to the nodes which docker client configuration holds registry credentials.
+	RegistryAuthLabel = "com.sind.cluster.registry-auth"
+
+	// NamespaceLabel is the label containing the namespace of the resources of a cluster created in a namespace.
+	NamespaceLabel = "com.sind.namespace"
+
+	// CreatedAtLabel is the label containing the RFC3339 date a resource has been created at.
+	CreatedAtLabel = "com.sind.created-at"
+
+	// CreatedByLabel is the label containing the user@host that created a resource.
+	CreatedByLabel = "com.sind.created-by"
+
+	// VersionLabel is the label containing the version of sind that created a resource.
+	VersionLabel = "com.sind.version"
+
+	// DrainedAvailabilityLabel is the swarm node label containing the availability of a node drained before the cluster
+	// has been stopped.
+	DrainedAvailabilityLabel = "com.sind.drained-availability"
+
+	// PortBindingsLabel is the label containing the comma separated port bindings of a cluster, as ranges of ports.
+	PortBindingsLabel = "com.sind.cluster.port-bindings"
+
+	// JobCompletionsLabel is the label containing the amount of tasks a replicated job created by sind has to complete.
+	JobCompletionsLabel = "com.sind.job.completions"
+
+	// ComponentLabel is the label containing the kind of an auxiliary (non node) container of a cluster.
+	ComponentLabel = "com.sind.cluster.component"
 )
 
 // Node roles.
@@ -17,6 +70,16 @@ const (
 	NodeRoleWorker  = "worker"
 )
 
+// Auxiliary components.
+const (
+	ComponentPortProxy    = "port-proxy"
+	ComponentLoadBalancer = "load-balancer"
+	ComponentSocketProxy  = "socket-proxy"
+
+	// ComponentPortForwarder is applied to the port forwarder services, deployed on the swarm cluster.
+	ComponentPortForwarder = "port-forwarder"
+)
+
 // PrimaryNodeLabel is the label applied to the primary node of a cluster.:
 func PrimaryNodeLabel() string {
 	return fmt.Sprintf("%s=%s", NodeRoleLabel, NodeRolePrimary)
@@ -26,3 +89,17 @@ func PrimaryNodeLabel() string {
 func ClusterLabel(name string) string {
 	return fmt.Sprintf("%s=%s", ClusterNameLabel, name)
 }
+
+// componentLabels returns the labels of an auxiliary container of a cluster, along with given additional labels.
+func componentLabels(clusterName, component string, labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+2)
+
+	for k, v := range labels {
+		result[k] = v
+	}
+
+	result[ClusterNameLabel] = clusterName
+	result[ComponentLabel] = component
+
+	return result
+}
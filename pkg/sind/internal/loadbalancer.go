@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// DefaultLoadBalancerImageName is the image used to run the cluster load balancer.
+	DefaultLoadBalancerImageName = "haproxy:2.4-alpine"
+
+	loadBalancerConfigPath = "/tmp/haproxy.cfg"
+)
+
+// LoadBalancerConfig is the configuration of the cluster ingress load balancer.
+type LoadBalancerConfig struct {
+	ClusterName string
+	ImageRef    string
+
+	NetworkID    string
+	NetworkName  string
+	PortBindings []string
+
+	Backends []string
+
+	// Labels are additional labels applied to the container.
+	Labels map[string]string
+}
+
+// CreateLoadBalancer runs a container balancing the given port bindings across the ingress of all backends.
+// HAProxy runs in master-worker mode, for ReloadLoadBalancer to make it reload its configuration.
+func CreateLoadBalancer(ctx context.Context, docker nodeCreator, cfg LoadBalancerConfig) (string, error) {
+	exposedPorts, portBindings, err := nat.ParsePortSpecs(cfg.PortBindings)
+	if err != nil {
+		return "", fmt.Errorf("unable to define port bindings: %w", err)
+	}
+
+	lbConfig, err := haproxyConfig(exposedPorts, cfg.Backends)
+	if err != nil {
+		return "", fmt.Errorf("unable to generate the load balancer configuration: %w", err)
+	}
+
+	cID, err := createContainer(
+		ctx,
+		docker,
+		&container.Config{
+			Hostname:     fmt.Sprintf("sind-%s-lb", cfg.ClusterName),
+			Image:        cfg.ImageRef,
+			ExposedPorts: nat.PortSet(exposedPorts),
+			Labels:       componentLabels(cfg.ClusterName, ComponentLoadBalancer, cfg.Labels),
+			Cmd:          []string{"haproxy", "-W", "-db", "-f", loadBalancerConfigPath},
+		},
+		&container.HostConfig{
+			PortBindings: nat.PortMap(portBindings),
+		},
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				cfg.NetworkName: {NetworkID: cfg.NetworkID},
+			},
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err = writeLoadBalancerConfig(ctx, docker, cID, lbConfig); err != nil {
+		return "", fmt.Errorf("unable to write the load balancer configuration: %w", err)
+	}
+
+	if err = startContainer(ctx, docker, cID); err != nil {
+		return "", err
+	}
+
+	return cID, nil
+}
+
+type loadBalancerReloader interface {
+	containerContentCopier
+	containerInspector
+	ContainerKill(context.Context, string, string) error
+}
+
+// ReloadLoadBalancer regenerates the configuration of a load balancer for given backends, and makes HAProxy reload it
+// if the load balancer is running. A stopped load balancer uses it once started.
+func ReloadLoadBalancer(ctx context.Context, client loadBalancerReloader, cID string, backends []string) error {
+	info, err := client.ContainerInspect(ctx, cID)
+	if err != nil {
+		return fmt.Errorf("unable to inspect the load balancer: %w", err)
+	}
+
+	lbConfig, err := haproxyConfig(info.Config.ExposedPorts, backends)
+	if err != nil {
+		return fmt.Errorf("unable to generate the load balancer configuration: %w", err)
+	}
+
+	if err = writeLoadBalancerConfig(ctx, client, cID, lbConfig); err != nil {
+		return fmt.Errorf("unable to write the load balancer configuration: %w", err)
+	}
+
+	if info.State == nil || !info.State.Running {
+		return nil
+	}
+
+	// The HAProxy master process reloads its configuration on SIGUSR2.
+	if err = client.ContainerKill(ctx, cID, "SIGUSR2"); err != nil {
+		return fmt.Errorf("unable to reload the load balancer: %w", err)
+	}
+
+	return nil
+}
+
+// writeLoadBalancerConfig writes the HAProxy configuration of a load balancer, readable by the unprivileged user HAProxy
+// runs as. The directory it is written to is left as is.
+func writeLoadBalancerConfig(ctx context.Context, client containerContentCopier, cID, lbConfig string) error {
+	var archive bytes.Buffer
+
+	tarWriter := tar.NewWriter(&archive)
+
+	header := tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     path.Base(loadBalancerConfigPath),
+		Mode:     0o644,
+		Size:     int64(len(lbConfig)),
+		ModTime:  time.Now(),
+	}
+
+	if err := tarWriter.WriteHeader(&header); err != nil {
+		return err
+	}
+
+	if _, err := tarWriter.Write([]byte(lbConfig)); err != nil {
+		return err
+	}
+
+	if err := tarWriter.Close(); err != nil {
+		return err
+	}
+
+	return retry(ctx, func() error {
+		return client.CopyToContainer(ctx, cID, path.Dir(loadBalancerConfigPath), bytes.NewReader(archive.Bytes()), types.CopyToContainerOptions{})
+	})
+}
+
+func haproxyConfig(ports map[nat.Port]struct{}, backends []string) (string, error) {
+	sortedPorts := make([]nat.Port, 0, len(ports))
+
+	for port := range ports {
+		if port.Proto() != "tcp" {
+			return "", fmt.Errorf("unsupported protocol %q for port %s", port.Proto(), port.Port())
+		}
+
+		sortedPorts = append(sortedPorts, port)
+	}
+
+	sort.Slice(sortedPorts, func(i, j int) bool { return sortedPorts[i].Int() < sortedPorts[j].Int() })
+
+	var buf bytes.Buffer
+
+	fmt.Fprintln(&buf, "defaults")
+	fmt.Fprintln(&buf, "  mode tcp")
+	fmt.Fprintln(&buf, "  timeout connect 5s")
+	fmt.Fprintln(&buf, "  timeout client 1m")
+	fmt.Fprintln(&buf, "  timeout server 1m")
+
+	for _, port := range sortedPorts {
+		fmt.Fprintf(&buf, "frontend port_%s\n", port.Port())
+		fmt.Fprintf(&buf, "  bind :%s\n", port.Port())
+		fmt.Fprintf(&buf, "  default_backend port_%s\n", port.Port())
+		fmt.Fprintf(&buf, "backend port_%s\n", port.Port())
+		fmt.Fprintln(&buf, "  balance roundrobin")
+
+		for i, backend := range backends {
+			fmt.Fprintf(&buf, "  server node-%d %s:%s check\n", i, backend, port.Port())
+		}
+	}
+
+	return buf.String(), nil
+}
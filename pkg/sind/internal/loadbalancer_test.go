@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"archive/tar"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const expectedLoadBalancerConfig = `defaults
+  mode tcp
+  timeout connect 5s
+  timeout client 1m
+  timeout server 1m
+frontend port_80
+  bind :80
+  default_backend port_80
+backend port_80
+  balance roundrobin
+  server node-0 10.0.117.2:80 check
+  server node-1 10.0.117.3:80 check
+frontend port_443
+  bind :443
+  default_backend port_443
+backend port_443
+  balance roundrobin
+  server node-0 10.0.117.2:443 check
+  server node-1 10.0.117.3:443 check
+`
+
+func TestCreateLoadBalancer(t *testing.T) {
+	ctx := context.Background()
+	cfg := LoadBalancerConfig{
+		ClusterName:  "TestCluster",
+		ImageRef:     "haproxy",
+		NetworkID:    "ababababab",
+		NetworkName:  "bar",
+		PortBindings: []string{"8080:80", "8443:443"},
+		Backends:     []string{"10.0.117.2", "10.0.117.3"},
+	}
+
+	var (
+		created *fakeContainer
+		written map[string]string
+		started bool
+	)
+
+	mock := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			created = &fakeContainer{
+				name:    cName,
+				cConfig: cConfig,
+				hConfig: hConfig,
+				nConfig: nConfig,
+			}
+
+			return container.ContainerCreateCreatedBody{ID: cName}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			// The configuration is written before HAProxy starts.
+			assert.NotEmpty(t, written)
+			started = true
+			return nil
+		},
+		copyToContainer: func(ctx context.Context, cID, dstPath string, content io.Reader, opts types.CopyToContainerOptions) error {
+			assert.Equal(t, "/tmp", dstPath)
+			written = readLoadBalancerArchive(t, content)
+			return nil
+		},
+	}
+
+	cID, err := CreateLoadBalancer(ctx, mock, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sind-TestCluster-lb", cID)
+	assert.Equal(
+		t,
+		map[string]string{
+			"com.sind.cluster.name":      "TestCluster",
+			"com.sind.cluster.component": "load-balancer",
+		},
+		created.cConfig.Labels,
+	)
+	assert.True(t, started)
+	assert.Equal(t, strslice.StrSlice{"haproxy", "-W", "-db", "-f", "/tmp/haproxy.cfg"}, created.cConfig.Cmd)
+	assert.Equal(t, map[string]string{"haproxy.cfg": expectedLoadBalancerConfig}, written)
+	assert.Equal(
+		t,
+		nat.PortMap{
+			nat.Port("80/tcp"):  {{HostPort: "8080"}},
+			nat.Port("443/tcp"): {{HostPort: "8443"}},
+		},
+		created.hConfig.PortBindings,
+	)
+}
+
+func TestCreateLoadBalancerFailsWithUDPPorts(t *testing.T) {
+	ctx := context.Background()
+	cfg := LoadBalancerConfig{
+		PortBindings: []string{"53:53/udp"},
+	}
+
+	_, err := CreateLoadBalancer(ctx, nodeStarterMock{}, cfg)
+	assert.Error(t, err)
+}
+
+type loadBalancerReloaderMock struct {
+	containerInspect func(context.Context, string) (types.ContainerJSON, error)
+	containerKill    func(context.Context, string, string) error
+	copyToContainer  func(context.Context, string, string, io.Reader, types.CopyToContainerOptions) error
+}
+
+func (m loadBalancerReloaderMock) ContainerInspect(ctx context.Context, cID string) (types.ContainerJSON, error) {
+	return m.containerInspect(ctx, cID)
+}
+
+func (m loadBalancerReloaderMock) ContainerKill(ctx context.Context, cID, signal string) error {
+	return m.containerKill(ctx, cID, signal)
+}
+
+func (m loadBalancerReloaderMock) CopyToContainer(ctx context.Context, cID, dstPath string, content io.Reader, opts types.CopyToContainerOptions) error {
+	return m.copyToContainer(ctx, cID, dstPath, content, opts)
+}
+
+func TestReloadLoadBalancer(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		running         bool
+		expectedSignals []string
+	}{
+		{
+			desc:            "with a running load balancer",
+			running:         true,
+			expectedSignals: []string{"SIGUSR2"},
+		},
+		{
+			desc: "with a stopped load balancer",
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var (
+				written map[string]string
+				signals []string
+			)
+
+			mock := loadBalancerReloaderMock{
+				containerInspect: func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+					assert.Equal(t, "lb", cID)
+
+					return types.ContainerJSON{
+						ContainerJSONBase: &types.ContainerJSONBase{State: &types.ContainerState{Running: test.running}},
+						Config: &container.Config{
+							ExposedPorts: nat.PortSet{"80/tcp": {}, "443/tcp": {}},
+						},
+					}, nil
+				},
+				containerKill: func(ctx context.Context, cID, signal string) error {
+					// The configuration is written before HAProxy reloads it.
+					assert.NotEmpty(t, written)
+					signals = append(signals, signal)
+					return nil
+				},
+				copyToContainer: func(ctx context.Context, cID, dstPath string, content io.Reader, opts types.CopyToContainerOptions) error {
+					assert.Equal(t, "/tmp", dstPath)
+					written = readLoadBalancerArchive(t, content)
+					return nil
+				},
+			}
+
+			err := ReloadLoadBalancer(context.Background(), mock, "lb", []string{"10.0.117.2", "10.0.117.3"})
+			require.NoError(t, err)
+
+			assert.Equal(t, map[string]string{"haproxy.cfg": expectedLoadBalancerConfig}, written)
+			assert.Equal(t, test.expectedSignals, signals)
+		})
+	}
+}
+
+func readLoadBalancerArchive(t *testing.T, content io.Reader) map[string]string {
+	files := make(map[string]string)
+	tarReader := tar.NewReader(content)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return files
+		}
+
+		require.NoError(t, err)
+		assert.EqualValues(t, 0o644, header.Mode)
+
+		data, err := ioutil.ReadAll(tarReader)
+		require.NoError(t, err)
+
+		files[header.Name] = string(data)
+	}
+}
@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrLocked is returned when the lock is held by another sind operation.
+var ErrLocked = errors.New("another sind operation is in progress")
+
+// Lock is an advisory lock, shared by the sind processes of the machine through a lock file. It is an OS file lock
+// (flock, LockFileEx), released by the OS when the process holding it exits, whatever the way it exits.
+type Lock struct {
+	file *os.File
+}
+
+// AcquireLock takes the lock of given key in dir, or returns ErrLocked if another process holds it.
+func AcquireLock(dir, key string) (*Lock, error) {
+	sum := sha256.Sum256([]byte(key))
+	path := filepath.Join(dir, "sind-"+hex.EncodeToString(sum[:8])+".lock")
+
+	// The lock file is never removed: a process could otherwise lock a file removed and recreated by another one.
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open the lock file %q: %w", path, err)
+	}
+
+	locked, err := tryLockFile(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("unable to lock the lock file %q: %w", path, err)
+	}
+
+	if !locked {
+		_ = file.Close()
+		return nil, fmt.Errorf("%w, lock file %q", ErrLocked, path)
+	}
+
+	return &Lock{file: file}, nil
+}
+
+// Release releases the lock.
+func (l *Lock) Release() error {
+	if err := unlockFile(l.file); err != nil {
+		_ = l.file.Close()
+		return fmt.Errorf("unable to unlock the lock file %q: %w", l.file.Name(), err)
+	}
+
+	return l.file.Close()
+}
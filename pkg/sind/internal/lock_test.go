@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcquireLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sind-lock")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	lock, err := AcquireLock(dir, "unix:///var/run/docker.sock")
+	require.NoError(t, err)
+
+	_, err = AcquireLock(dir, "unix:///var/run/docker.sock")
+	assert.True(t, errors.Is(err, ErrLocked))
+
+	other, err := AcquireLock(dir, "tcp://10.0.0.1:2375")
+	require.NoError(t, err)
+	require.NoError(t, other.Release())
+
+	require.NoError(t, lock.Release())
+
+	lock, err = AcquireLock(dir, "unix:///var/run/docker.sock")
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
+
+const lockHelperDirEnv = "SIND_TEST_LOCK_HELPER_DIR"
+
+// TestLockHelperProcess takes a lock and exits without releasing it, as a command failing would. It is only run as a
+// subprocess of TestAcquireLockAfterHolderExit.
+func TestLockHelperProcess(t *testing.T) {
+	dir := os.Getenv(lockHelperDirEnv)
+	if dir == "" {
+		t.Skip("only run as a subprocess")
+	}
+
+	if _, err := AcquireLock(dir, "unix:///var/run/docker.sock"); err != nil {
+		os.Exit(2)
+	}
+
+	os.Exit(0)
+}
+
+func TestAcquireLockAfterHolderExit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sind-lock")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		desc     string
+		heldByUs bool
+		wantErr  error
+	}{
+		{desc: "lock left by an exited process"},
+		{desc: "lock held by a running process", heldByUs: true, wantErr: ErrLocked},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var held *Lock
+
+			if test.heldByUs {
+				held, err = AcquireLock(dir, "unix:///var/run/docker.sock")
+				require.NoError(t, err)
+
+				defer held.Release()
+			} else {
+				cmd := exec.Command(os.Args[0], "-test.run=^TestLockHelperProcess$")
+				// A race enabled process sleeps a second on exit by default.
+				cmd.Env = append(os.Environ(), lockHelperDirEnv+"="+dir, "GORACE=atexit_sleep_ms=0")
+				require.NoError(t, cmd.Run())
+			}
+
+			lock, err := AcquireLock(dir, "unix:///var/run/docker.sock")
+			if test.wantErr != nil {
+				assert.True(t, errors.Is(err, test.wantErr))
+				return
+			}
+
+			require.NoError(t, err)
+			require.NoError(t, lock.Release())
+		})
+	}
+}
@@ -2,23 +2,42 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"net"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/network"
-	"github.com/golang/sync/errgroup"
 )
 
+const defaultNetworkDriver = "bridge"
+
+// ErrNodeEgress is returned when a container attached to an internal network is able to reach external networks.
+var ErrNodeEgress = errors.New("node has a default route")
+
 // NetworkConfig represents possible configuration for sind network.
 type NetworkConfig struct {
 	Name        string
 	ClusterName string
 	Subnet      string
 	Labels      map[string]string
+
+	// Gateway, IPRange and AuxAddresses refine the IPAM configuration of the subnet, they are left to the IPAM driver if empty.
+	Gateway      string
+	IPRange      string
+	AuxAddresses map[string]string
+
+	// Driver is the network driver to use, bridge if empty.
+	Driver  string
+	Options map[string]string
+	// Internal creates a network without access to external networks.
+	Internal bool
 }
 
 type networkCreator interface {
@@ -33,6 +52,28 @@ func PickSubnet() (*net.IPNet, error) {
 	return res, err
 }
 
+// PickSubnets returns count distinct subnets to use for the container networks of several clusters.
+func PickSubnets(count int) ([]*net.IPNet, error) {
+	if count > 256 {
+		return nil, fmt.Errorf("unable to pick %d distinct subnets, at most 256 are available", count)
+	}
+
+	rand.Seed(time.Now().UnixNano())
+
+	subnets := make([]*net.IPNet, count)
+
+	for i, third := range rand.Perm(256)[:count] {
+		_, subnet, err := net.ParseCIDR(fmt.Sprintf("10.0.%d.0/24", third))
+		if err != nil {
+			return nil, err
+		}
+
+		subnets[i] = subnet
+	}
+
+	return subnets, nil
+}
+
 // CreateNetwork creates network according to given network config.
 func CreateNetwork(ctx context.Context, client networkCreator, cfg NetworkConfig) (types.NetworkCreateResponse, error) {
 	if cfg.Labels == nil {
@@ -41,15 +82,26 @@ func CreateNetwork(ctx context.Context, client networkCreator, cfg NetworkConfig
 
 	cfg.Labels[ClusterNameLabel] = cfg.ClusterName
 
+	if cfg.Driver == "" {
+		cfg.Driver = defaultNetworkDriver
+	}
+
 	return client.NetworkCreate(
 		ctx,
 		cfg.Name,
 		types.NetworkCreate{
-			Driver: "bridge",
+			Driver:   cfg.Driver,
+			Options:  cfg.Options,
+			Internal: cfg.Internal,
 			IPAM: &network.IPAM{
 				Driver: "default",
 				Config: []network.IPAMConfig{
-					{Subnet: cfg.Subnet},
+					{
+						Subnet:     cfg.Subnet,
+						Gateway:    cfg.Gateway,
+						IPRange:    cfg.IPRange,
+						AuxAddress: cfg.AuxAddresses,
+					},
 				},
 			},
 			Labels: cfg.Labels,
@@ -71,24 +123,127 @@ func ListNetworks(ctx context.Context, hostClient networkLister, clusterName str
 	)
 }
 
+// FindNetwork returns the network of a cluster with given name, nil if there is none, e.g. a network kept on the docker
+// host by the deletion of the cluster.
+func FindNetwork(ctx context.Context, hostClient networkLister, clusterName, name string) (*types.NetworkResource, error) {
+	nets, err := ListNetworks(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, clusterNet := range nets {
+		if clusterNet.Name == name {
+			return &nets[i], nil
+		}
+	}
+
+	return nil, nil
+}
+
+// NodeNetwork returns the name and the endpoint of the cluster network a node is attached to.
+// Nodes created without a network label are expected to be attached to a single network.
+func NodeNetwork(node types.Container) (string, *network.EndpointSettings, error) {
+	if node.NetworkSettings == nil {
+		return "", nil, fmt.Errorf("node %q has no network settings", node.ID)
+	}
+
+	networkName, labeled := node.Labels[NetworkNameLabel]
+	if !labeled {
+		if len(node.NetworkSettings.Networks) != 1 {
+			return "", nil, fmt.Errorf("unable to find the cluster network of node %q", node.ID)
+		}
+
+		for name := range node.NetworkSettings.Networks {
+			networkName = name
+		}
+	}
+
+	endpoint, present := node.NetworkSettings.Networks[networkName]
+	if !present {
+		return "", nil, fmt.Errorf("node %q is not a member of the cluster network %q", node.ID, networkName)
+	}
+
+	return networkName, endpoint, nil
+}
+
 type networkRemover interface {
 	NetworkRemove(ctx context.Context, networkID string) error
 }
 
-// DeleteNetworks deletes all given networks.
+// NetworksError reports the networks an operation failed on, along with their error.
+type NetworksError struct {
+	Operation string
+	Errors    map[string]error
+}
+
+func (e *NetworksError) Error() string {
+	netIDs := make([]string, 0, len(e.Errors))
+	for netID := range e.Errors {
+		netIDs = append(netIDs, netID)
+	}
+
+	sort.Strings(netIDs)
+
+	failures := make([]string, 0, len(netIDs))
+	for _, netID := range netIDs {
+		failures = append(failures, fmt.Sprintf("%s: %v", netID, e.Errors[netID]))
+	}
+
+	return fmt.Sprintf("failed to %s %d network(s): %s", e.Operation, len(netIDs), strings.Join(failures, ", "))
+}
+
+// DeleteNetworks deletes all given networks. All the networks are processed, failures are reported by a
+// *NetworksError.
 func DeleteNetworks(ctx context.Context, hostClient networkRemover, networks []types.NetworkResource) error {
-	errg, groupCtx := errgroup.WithContext(ctx)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures = make(map[string]error)
+	)
 
 	for _, network := range networks {
-		netID := network.ID
+		wg.Add(1)
+
+		go func(netID string) {
+			defer wg.Done()
 
-		errg.Go(func() error {
-			return hostClient.NetworkRemove(groupCtx, netID)
-		})
+			if err := hostClient.NetworkRemove(ctx, netID); err != nil {
+				mu.Lock()
+				failures[netID] = err
+				mu.Unlock()
+			}
+		}(network.ID)
 	}
 
-	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("unable to delete a network: %v", err)
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &NetworksError{Operation: "delete", Errors: failures}
+	}
+
+	return nil
+}
+
+// CheckNoEgress returns ErrNodeEgress if one of given containers has a default route, through which it could reach
+// external networks.
+func CheckNoEgress(ctx context.Context, client executor, containers []types.Container) error {
+	for _, result := range BroadcastExec(ctx, client, containers, []string{"ip", "route", "show", "default"}) {
+		if result.Err != nil {
+			return fmt.Errorf("unable to list the routes of container %q: %w", result.ContainerID, result.Err)
+		}
+
+		if result.ExitCode != 0 {
+			return fmt.Errorf(
+				"unable to list the routes of container %q, exited with code %d: %s",
+				result.ContainerID,
+				result.ExitCode,
+				strings.TrimSpace(result.Stderr),
+			)
+		}
+
+		if route := strings.TrimSpace(result.Stdout); route != "" {
+			return fmt.Errorf("%w: container %q routes %q", ErrNodeEgress, result.ContainerID, route)
+		}
 	}
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"sort"
+	"sync"
 	"testing"
 
 	"github.com/docker/docker/api/types"
@@ -18,6 +19,23 @@ func (n networkCreatorMock) NetworkCreate(ctx context.Context, name string, opts
 	return n(ctx, name, opts)
 }
 
+func TestPickSubnets(t *testing.T) {
+	subnets, err := PickSubnets(256)
+	require.NoError(t, err)
+
+	picked := make(map[string]struct{}, len(subnets))
+
+	for _, subnet := range subnets {
+		assert.Equal(t, byte(10), subnet.IP.To4()[0])
+		picked[subnet.String()] = struct{}{}
+	}
+
+	assert.Len(t, picked, 256)
+
+	_, err = PickSubnets(257)
+	assert.Error(t, err)
+}
+
 func TestCreateNetwork(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -65,6 +83,79 @@ func TestCreateNetwork(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc: "internal",
+			cfg: NetworkConfig{
+				Name:        "hello",
+				ClusterName: "toto",
+				Subnet:      "10.0.117.0/24",
+				Internal:    true,
+			},
+			expectedOpts: types.NetworkCreate{
+				Driver:   "bridge",
+				Internal: true,
+				IPAM: &network.IPAM{
+					Driver: "default",
+					Config: []network.IPAMConfig{
+						{Subnet: "10.0.117.0/24"},
+					},
+				},
+				Labels: map[string]string{
+					ClusterNameLabel: "toto",
+				},
+			},
+		},
+		{
+			desc: "with a macvlan driver",
+			cfg: NetworkConfig{
+				Name:        "hello",
+				ClusterName: "toto",
+				Subnet:      "192.168.1.0/24",
+				Driver:      "macvlan",
+				Options:     map[string]string{"parent": "eth0"},
+			},
+			expectedOpts: types.NetworkCreate{
+				Driver:  "macvlan",
+				Options: map[string]string{"parent": "eth0"},
+				IPAM: &network.IPAM{
+					Driver: "default",
+					Config: []network.IPAMConfig{
+						{Subnet: "192.168.1.0/24"},
+					},
+				},
+				Labels: map[string]string{
+					ClusterNameLabel: "toto",
+				},
+			},
+		},
+		{
+			desc: "with a custom IPAM configuration",
+			cfg: NetworkConfig{
+				Name:         "hello",
+				ClusterName:  "toto",
+				Subnet:       "10.0.117.0/24",
+				Gateway:      "10.0.117.254",
+				IPRange:      "10.0.117.128/25",
+				AuxAddresses: map[string]string{"router": "10.0.117.253"},
+			},
+			expectedOpts: types.NetworkCreate{
+				Driver: "bridge",
+				IPAM: &network.IPAM{
+					Driver: "default",
+					Config: []network.IPAMConfig{
+						{
+							Subnet:     "10.0.117.0/24",
+							Gateway:    "10.0.117.254",
+							IPRange:    "10.0.117.128/25",
+							AuxAddress: map[string]string{"router": "10.0.117.253"},
+						},
+					},
+				},
+				Labels: map[string]string{
+					ClusterNameLabel: "toto",
+				},
+			},
+		},
 	}
 
 	for _, test := range testCases {
@@ -140,6 +231,99 @@ func TestNetworkList(t *testing.T) {
 	}
 }
 
+func TestFindNetwork(t *testing.T) {
+	client := networkListerMock(func(ctx context.Context, opts types.NetworkListOptions) ([]types.NetworkResource, error) {
+		assert.True(t, opts.Filters.ExactMatch(ClusterNameLabel, "test"))
+		return []types.NetworkResource{{ID: "shared", Name: "shared-net"}, {ID: "kept", Name: "sind-test"}}, nil
+	})
+
+	found, err := FindNetwork(context.Background(), client, "test", "sind-test")
+	require.NoError(t, err)
+	assert.Equal(t, &types.NetworkResource{ID: "kept", Name: "sind-test"}, found)
+
+	found, err = FindNetwork(context.Background(), client, "test", "other")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestNodeNetwork(t *testing.T) {
+	endpoint := &network.EndpointSettings{NetworkID: "netID", IPAddress: "10.0.0.1"}
+
+	testCases := []struct {
+		desc             string
+		node             types.Container
+		expectedName     string
+		expectedEndpoint *network.EndpointSettings
+		expectedError    error
+	}{
+		{
+			desc:          "no network settings",
+			node:          types.Container{ID: "node"},
+			expectedError: errors.New("node \"node\" has no network settings"),
+		},
+		{
+			desc: "labeled network",
+			node: types.Container{
+				ID:     "node",
+				Labels: map[string]string{NetworkNameLabel: "net"},
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{
+						"net":   endpoint,
+						"other": {},
+					},
+				},
+			},
+			expectedName:     "net",
+			expectedEndpoint: endpoint,
+		},
+		{
+			desc: "labeled network not attached",
+			node: types.Container{
+				ID:     "node",
+				Labels: map[string]string{NetworkNameLabel: "net"},
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"other": {}},
+				},
+			},
+			expectedError: errors.New("node \"node\" is not a member of the cluster network \"net\""),
+		},
+		{
+			desc: "unlabeled single network",
+			node: types.Container{
+				ID: "node",
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"net": endpoint},
+				},
+			},
+			expectedName:     "net",
+			expectedEndpoint: endpoint,
+		},
+		{
+			desc: "unlabeled multiple networks",
+			node: types.Container{
+				ID: "node",
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"net": endpoint, "other": {}},
+				},
+			},
+			expectedError: errors.New("unable to find the cluster network of node \"node\""),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			name, endpoint, err := NodeNetwork(test.node)
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedName, name)
+			assert.Equal(t, test.expectedEndpoint, endpoint)
+		})
+	}
+}
+
 type networkRemoverMock func(ctx context.Context, networkID string) error
 
 func (n networkRemoverMock) NetworkRemove(ctx context.Context, networkID string) error {
@@ -176,3 +360,86 @@ func TestDeleteNetwork(t *testing.T) {
 	sort.Strings(removedNetworks)
 	assert.Equal(t, []string{"a", "b", "c", "d"}, removedNetworks)
 }
+
+func TestDeleteNetworksFailure(t *testing.T) {
+	ctx := context.Background()
+	networks := []types.NetworkResource{{ID: "a"}, {ID: "b"}, {ID: "c"}}
+
+	var (
+		mu      sync.Mutex
+		removed []string
+	)
+
+	client := networkRemoverMock(func(ctx context.Context, networkID string) error {
+		if networkID == "b" {
+			return errors.New("network has active endpoints")
+		}
+
+		mu.Lock()
+		removed = append(removed, networkID)
+		mu.Unlock()
+
+		return nil
+	})
+
+	err := DeleteNetworks(ctx, client, networks)
+
+	var networksErr *NetworksError
+	require.True(t, errors.As(err, &networksErr))
+	assert.Equal(t, []string{"b"}, failedIDs(networksErr.Errors))
+	assert.Equal(t, "failed to delete 1 network(s): b: network has active endpoints", err.Error())
+
+	sort.Strings(removed)
+	assert.Equal(t, []string{"a", "c"}, removed)
+}
+
+func failedIDs(m map[string]error) []string {
+	result := make([]string, 0, len(m))
+	for k := range m {
+		result = append(result, k)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+func TestCheckNoEgress(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		routes        map[string]string
+		expectedError error
+	}{
+		{
+			desc:   "without default route",
+			routes: map[string]string{"AAA": "", "BBB": ""},
+		},
+		{
+			desc:          "with a default route",
+			routes:        map[string]string{"AAA": "", "BBB": "default via 10.0.117.1 dev eth0\n"},
+			expectedError: ErrNodeEgress,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			client := executorMock{
+				containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+					assert.Equal(t, []string{"ip", "route", "show", "default"}, opts.Cmd)
+					return types.IDResponse{ID: cID}, nil
+				},
+				containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+					return execOutput(test.routes[eID], ""), nil
+				},
+			}
+
+			err := CheckNoEgress(context.Background(), &client, []types.Container{{ID: "AAA"}, {ID: "BBB"}})
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+		})
+	}
+}
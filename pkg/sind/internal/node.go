@@ -1,9 +1,14 @@
 package internal
 
 import (
+	"bytes"
 	"context"
+	"encoding/binary"
 	"fmt"
 	"net"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
@@ -12,6 +17,9 @@ import (
 	"github.com/golang/sync/errgroup"
 )
 
+// clientConfigDir is the directory of the docker client configuration of the nodes.
+const clientConfigDir = "/root/.docker"
+
 // NodesConfig is the configuration for the swarm cluster containers.
 type NodesConfig struct {
 	ClusterName string
@@ -20,12 +28,74 @@ type NodesConfig struct {
 	NetworkID    string
 	NetworkName  string
 	PortBindings []string
-	Subnet       net.IPNet
+	// ManagerPortBindings and WorkerPortBindings publish the container ports of PortBindings on the non primary managers
+	// and on the workers as well, each of them on host ports picked by the daemon.
+	ManagerPortBindings bool
+	WorkerPortBindings  bool
+	// Subnet is used to assign static addresses to the nodes, see NodeAddress. Addresses are picked by the network IPAM
+	// if empty.
+	Subnet net.IPNet
+	// Addresses are the static addresses of the nodes, primary first, then managers and workers. They take precedence
+	// over the addresses derived from Subnet.
+	Addresses []string
+	// RoleRanges derives the addresses of the nodes from the ranges of their role in Subnet, see RoleRanges.
+	RoleRanges bool
 
 	Managers uint16
 	Workers  uint16
 
 	DaemonArgs []string
+	// PublishDaemons publishes the daemon port of every node on the docker host, not only the primary one.
+	PublishDaemons bool
+	// Runtime is the container runtime of the nodes, e.g. runsc or kata-runtime, the docker host default if empty.
+	Runtime string
+	// Devices are the host devices mapped into all nodes.
+	Devices []container.DeviceMapping
+	// DaemonHostPort is the port of the docker host the daemon port of the primary node is bound to, a random one if 0.
+	DaemonHostPort uint16
+	// Env is the environment applied to all nodes.
+	Env []string
+	// DaemonConfig is the content of the daemon.json file of all nodes, the image default is kept if empty.
+	DaemonConfig string
+	// ClientConfig is the content of the docker client configuration file of all nodes, none is written if empty.
+	ClientConfig string
+	// TLS secures the daemon port of the nodes with mutual TLS, it is plain TCP if nil.
+	TLS *TLSMaterial
+	// RestrictEgress restricts the outbound traffic of all nodes to the cluster network and EgressAllowlist, addresses
+	// or CIDRs.
+	RestrictEgress  bool
+	EgressAllowlist []string
+
+	// Stopped creates the nodes containers without starting them.
+	Stopped bool
+
+	ManagerResources container.Resources
+	WorkerResources  container.Resources
+	// PidsLimit, MemorySwap and BlkioWeight are applied to the resources of all nodes, unless zero.
+	PidsLimit   int64
+	MemorySwap  int64
+	BlkioWeight uint16
+
+	// Labels are additional labels applied to all nodes.
+	Labels map[string]string
+
+	// OnNodeStarted is called concurrently with the hostname of each node once started, unless Stopped.
+	OnNodeStarted func(hostname string)
+}
+
+// nodeHealthcheck reports a node as unhealthy when its docker daemon stops answering.
+var nodeHealthcheck = &container.HealthConfig{
+	Test:        []string{"CMD", "docker", "version"},
+	Interval:    10 * time.Second,
+	Timeout:     5 * time.Second,
+	StartPeriod: 30 * time.Second,
+	Retries:     3,
+}
+
+// daemonHostArgs make a node daemon listen on its docker daemon port.
+var daemonHostArgs = []string{
+	"-H unix:///var/run/docker.sock",
+	"-H tcp://0.0.0.0:2375",
 }
 
 // NodeIDs carries the IDs of various nodes in the cluster.
@@ -35,9 +105,28 @@ type NodeIDs struct {
 	Workers  []string
 }
 
+// NodeIDsOf sorts given nodes IDs by role.
+func NodeIDsOf(nodes []types.Container) NodeIDs {
+	var result NodeIDs
+
+	for _, node := range nodes {
+		switch node.Labels[NodeRoleLabel] {
+		case NodeRolePrimary:
+			result.Primary = node.ID
+		case NodeRoleManager:
+			result.Managers = append(result.Managers, node.ID)
+		case NodeRoleWorker:
+			result.Workers = append(result.Workers, node.ID)
+		}
+	}
+
+	return result
+}
+
 type nodeCreator interface {
 	ContainerCreate(context.Context, *container.Config, *container.HostConfig, *network.NetworkingConfig, string) (container.ContainerCreateCreatedBody, error)
 	ContainerStart(context.Context, string, types.ContainerStartOptions) error
+	containerContentCopier
 }
 
 // CreateNodes creates the nodes containers of the cluster.
@@ -45,9 +134,7 @@ func CreateNodes(ctx context.Context, docker nodeCreator, cfg NodesConfig) (*Nod
 	var (
 		managerIndex uint16
 		workerIndex  uint16
-
-		// Start at 2, 1 is the network gateway.
-		nodeIPIdentifier uint16 = 2
+		nodeIndex    uint16
 	)
 
 	primaryCreated := make(chan string, 1)
@@ -56,55 +143,50 @@ func CreateNodes(ctx context.Context, docker nodeCreator, cfg NodesConfig) (*Nod
 
 	exposedPorts, portBindings, err := nat.ParsePortSpecs(cfg.PortBindings)
 	if err != nil {
-		return nil, fmt.Errorf("unable to define port bindings: %v", err)
+		return nil, fmt.Errorf("unable to define port bindings: %w", err)
 	}
 
+	if cfg.RoleRanges && len(cfg.Addresses) == 0 && cfg.Subnet.IP != nil {
+		cfg.Addresses = roleAddresses(cfg)
+	}
+
+	primaryPorts, primaryBindings, err := nat.ParsePortSpecs(primaryPortSpecs(cfg))
+	if err != nil {
+		return nil, fmt.Errorf("unable to define port bindings: %w", err)
+	}
+
+	managerPorts, managerBindings := replicaPorts(cfg.ManagerPortBindings, exposedPorts, portBindings)
+	workerPorts, workerBindings := replicaPorts(cfg.WorkerPortBindings, exposedPorts, portBindings)
+
 	errg, groupCtx := errgroup.WithContext(ctx)
 
 	// Create the primary node.
 	primaryIndex := managerIndex
-	primaryIPSuffix := nodeIPIdentifier
+	primaryNodeIndex := nodeIndex
 
 	errg.Go(func() error {
 		nodeName := fmt.Sprintf("sind-%s-manager-%d", cfg.ClusterName, primaryIndex)
-		cID, err := runContainer(
+		cID, err := runNode(
 			groupCtx,
 			docker,
+			cfg,
 			&container.Config{
 				Hostname:     nodeName,
 				Image:        cfg.ImageRef,
-				Entrypoint:   []string{"dockerd"},
-				ExposedPorts: nat.PortSet(exposedPorts),
-				Labels: map[string]string{
-					ClusterNameLabel: cfg.ClusterName,
-					NodeRoleLabel:    NodeRolePrimary,
-				},
-				Cmd: append([]string{
-					"-H unix:///var/run/docker.sock",
-					"-H tcp://0.0.0.0:2375",
-				}, cfg.DaemonArgs...),
+				Entrypoint:   nodeEntrypoint(cfg),
+				ExposedPorts: nat.PortSet(primaryPorts),
+				Labels:       nodeLabels(cfg, NodeRolePrimary),
+				Env:          nodeEnv(cfg),
+				Healthcheck:  nodeHealthcheck,
+				Cmd:          primaryCmd(cfg),
 			},
 			&container.HostConfig{
 				Privileged:      true,
 				PublishAllPorts: true,
-				PortBindings:    nat.PortMap(portBindings),
-			},
-			&network.NetworkingConfig{
-				EndpointsConfig: map[string]*network.EndpointSettings{
-					cfg.NetworkName: {
-						NetworkID: cfg.NetworkID,
-						IPAMConfig: &network.EndpointIPAMConfig{
-							IPv4Address: fmt.Sprintf(
-								"%d.%d.%d.%d",
-								cfg.Subnet.IP[0],
-								cfg.Subnet.IP[1],
-								cfg.Subnet.IP[2],
-								primaryIPSuffix,
-							),
-						},
-					},
-				},
+				PortBindings:    nat.PortMap(primaryBindings),
+				Resources:       cfg.ManagerResources,
 			},
+			nodeNetworkingConfig(cfg, primaryNodeIndex),
 		)
 
 		if err != nil {
@@ -114,46 +196,37 @@ func CreateNodes(ctx context.Context, docker nodeCreator, cfg NodesConfig) (*Nod
 		return nil
 	})
 
-	nodeIPIdentifier++
+	nodeIndex++
 	managerIndex++
 
 	// Create the managers.
 	for ; managerIndex < cfg.Managers; managerIndex++ {
 		idx := managerIndex
-		ipSuffix := nodeIPIdentifier
+		nodeIdx := nodeIndex
 
 		errg.Go(func() error {
 			nodeName := fmt.Sprintf("sind-%s-manager-%d", cfg.ClusterName, idx)
-			cID, err := runContainer(
+			cID, err := runNode(
 				groupCtx,
 				docker,
+				cfg,
 				&container.Config{
-					Image:      cfg.ImageRef,
-					Entrypoint: []string{"dockerd"},
-					Hostname:   nodeName,
-					Labels: map[string]string{
-						ClusterNameLabel: cfg.ClusterName,
-						NodeRoleLabel:    NodeRoleManager,
-					},
-					Cmd: cfg.DaemonArgs,
+					Image:        cfg.ImageRef,
+					Entrypoint:   nodeEntrypoint(cfg),
+					Hostname:     nodeName,
+					ExposedPorts: managerPorts,
+					Labels:       nodeLabels(cfg, NodeRoleManager),
+					Env:          nodeEnv(cfg),
+					Healthcheck:  nodeHealthcheck,
+					Cmd:          nodeCmd(cfg),
 				},
-				&container.HostConfig{Privileged: true},
-				&network.NetworkingConfig{
-					EndpointsConfig: map[string]*network.EndpointSettings{
-						cfg.NetworkName: {
-							NetworkID: cfg.NetworkID,
-							IPAMConfig: &network.EndpointIPAMConfig{
-								IPv4Address: fmt.Sprintf(
-									"%d.%d.%d.%d",
-									cfg.Subnet.IP[0],
-									cfg.Subnet.IP[1],
-									cfg.Subnet.IP[2],
-									ipSuffix,
-								),
-							},
-						},
-					},
+				&container.HostConfig{
+					Privileged:      true,
+					PublishAllPorts: cfg.PublishDaemons,
+					PortBindings:    managerBindings,
+					Resources:       cfg.ManagerResources,
 				},
+				nodeNetworkingConfig(cfg, nodeIdx),
 			)
 
 			if err != nil {
@@ -164,46 +237,37 @@ func CreateNodes(ctx context.Context, docker nodeCreator, cfg NodesConfig) (*Nod
 
 			return nil
 		})
-		nodeIPIdentifier++
+		nodeIndex++
 	}
 
 	// Create the workers.
 	for ; workerIndex < cfg.Workers; workerIndex++ {
 		idx := workerIndex
-		ipSuffix := nodeIPIdentifier
+		nodeIdx := nodeIndex
 
 		errg.Go(func() error {
 			nodeName := fmt.Sprintf("sind-%s-worker-%d", cfg.ClusterName, idx)
-			cID, err := runContainer(
+			cID, err := runNode(
 				ctx,
 				docker,
+				cfg,
 				&container.Config{
-					Image:      cfg.ImageRef,
-					Hostname:   nodeName,
-					Entrypoint: []string{"dockerd"},
-					Labels: map[string]string{
-						ClusterNameLabel: cfg.ClusterName,
-						NodeRoleLabel:    NodeRoleWorker,
-					},
-					Cmd: cfg.DaemonArgs,
+					Image:        cfg.ImageRef,
+					Hostname:     nodeName,
+					Entrypoint:   nodeEntrypoint(cfg),
+					ExposedPorts: workerPorts,
+					Labels:       nodeLabels(cfg, NodeRoleWorker),
+					Env:          nodeEnv(cfg),
+					Healthcheck:  nodeHealthcheck,
+					Cmd:          nodeCmd(cfg),
 				},
-				&container.HostConfig{Privileged: true},
-				&network.NetworkingConfig{
-					EndpointsConfig: map[string]*network.EndpointSettings{
-						cfg.NetworkName: {
-							NetworkID: cfg.NetworkID,
-							IPAMConfig: &network.EndpointIPAMConfig{
-								IPv4Address: fmt.Sprintf(
-									"%d.%d.%d.%d",
-									cfg.Subnet.IP[0],
-									cfg.Subnet.IP[1],
-									cfg.Subnet.IP[2],
-									ipSuffix,
-								),
-							},
-						},
-					},
+				&container.HostConfig{
+					Privileged:      true,
+					PublishAllPorts: cfg.PublishDaemons,
+					PortBindings:    workerBindings,
+					Resources:       cfg.WorkerResources,
 				},
+				nodeNetworkingConfig(cfg, nodeIdx),
 			)
 
 			if err != nil {
@@ -213,11 +277,11 @@ func CreateNodes(ctx context.Context, docker nodeCreator, cfg NodesConfig) (*Nod
 			workerCreated <- cID
 			return nil
 		})
-		nodeIPIdentifier++
+		nodeIndex++
 	}
 
 	if err = errg.Wait(); err != nil {
-		return nil, fmt.Errorf("unable to create the cluster: %v", err)
+		return nil, fmt.Errorf("unable to create the cluster: %w", err)
 	}
 
 	close(primaryCreated)
@@ -239,19 +303,301 @@ func CreateNodes(ctx context.Context, docker nodeCreator, cfg NodesConfig) (*Nod
 	return &result, nil
 }
 
-func runContainer(ctx context.Context, client nodeCreator, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig) (string, error) {
-	resp, err := client.ContainerCreate(
-		ctx,
-		cConfig,
-		hConfig,
-		nConfig,
-		cConfig.Hostname,
+// primaryPortSpecs returns the port bindings of the primary node, binding its daemon port on DaemonHostPort if set.
+func primaryPortSpecs(cfg NodesConfig) []string {
+	if cfg.DaemonHostPort == 0 {
+		return cfg.PortBindings
+	}
+
+	return append(
+		append([]string{}, cfg.PortBindings...),
+		fmt.Sprintf("%d:%d/tcp", cfg.DaemonHostPort, dockerDaemonPort),
 	)
+}
+
+// replicaPorts returns the exposed ports and the port bindings of non primary nodes. If publish is true, the ports bound
+// on the primary node are bound on host ports picked by the daemon, distinct for each node, on the same host IPs.
+func replicaPorts(publish bool, exposedPorts map[nat.Port]struct{}, bindings map[nat.Port][]nat.PortBinding) (nat.PortSet, nat.PortMap) {
+	if !publish || len(bindings) == 0 {
+		return nil, nil
+	}
+
+	replicaBindings := make(nat.PortMap, len(bindings))
+
+	for port, portBindings := range bindings {
+		for _, binding := range portBindings {
+			replicaBindings[port] = append(replicaBindings[port], nat.PortBinding{HostIP: binding.HostIP})
+		}
+	}
+
+	return nat.PortSet(exposedPorts), replicaBindings
+}
+
+// NodeAddress returns the static address of the node created at given index, primary first, then managers and workers,
+// on a network with given subnet. Nodes addresses follow the subnet address and the network gateway.
+func NodeAddress(subnet net.IPNet, index uint16) net.IP {
+	return offsetAddress(subnet.IP, 2+uint32(index))
+}
+
+// RoleRanges splits a subnet in two halves: the managers, primary included, get the addresses of the first one
+// following the network gateway, and the workers get the addresses of the second one from its start.
+// For instance, managers of 10.0.0.0/24 are addressed from 10.0.0.2 and workers from 10.0.0.128.
+func RoleRanges(subnet net.IPNet) (managers, workers net.IPNet) {
+	ones, bits := subnet.Mask.Size()
+	halfMask := net.CIDRMask(ones+1, bits)
+
+	managers = net.IPNet{IP: subnet.IP, Mask: halfMask}
+	workers = net.IPNet{IP: offsetAddress(subnet.IP, 1<<uint(bits-ones-1)), Mask: halfMask}
+
+	return managers, workers
+}
+
+// roleAddresses returns the addresses of the nodes, primary first, picked from the ranges of their role in the subnet.
+func roleAddresses(cfg NodesConfig) []string {
+	managers, workers := RoleRanges(cfg.Subnet)
+	addresses := make([]string, 0, int(cfg.Managers)+int(cfg.Workers))
+
+	for i := uint16(0); i < cfg.Managers; i++ {
+		addresses = append(addresses, NodeAddress(managers, i).String())
+	}
+
+	for i := uint16(0); i < cfg.Workers; i++ {
+		addresses = append(addresses, offsetAddress(workers.IP, uint32(i)).String())
+	}
+
+	return addresses
+}
+
+// offsetAddress returns the IPv4 address following ip by offset, nil if ip is not an IPv4 address.
+func offsetAddress(ip net.IP, offset uint32) net.IP {
+	base := ip.To4()
+	if base == nil {
+		return nil
+	}
+
+	address := make(net.IP, net.IPv4len)
+	binary.BigEndian.PutUint32(address, binary.BigEndian.Uint32(base)+offset)
+
+	return address
+}
+
+func nodeNetworkingConfig(cfg NodesConfig, index uint16) *network.NetworkingConfig {
+	endpoint := network.EndpointSettings{NetworkID: cfg.NetworkID}
+
+	// Without addresses nor subnet, let the network IPAM pick the node address.
+	switch {
+	case len(cfg.Addresses) > int(index):
+		endpoint.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: cfg.Addresses[index]}
+	case cfg.Subnet.IP != nil:
+		endpoint.IPAMConfig = &network.EndpointIPAMConfig{IPv4Address: NodeAddress(cfg.Subnet, index).String()}
+	}
+
+	return &network.NetworkingConfig{
+		EndpointsConfig: map[string]*network.EndpointSettings{
+			cfg.NetworkName: &endpoint,
+		},
+	}
+}
+
+// primaryCmd returns the daemon args of the primary node, which always listens on the daemon port.
+func primaryCmd(cfg NodesConfig) []string {
+	args := append([]string{}, daemonHostArgs...)
+
+	if cfg.TLS != nil {
+		args = append(args, tlsDaemonArgs()...)
+	}
+
+	return append(args, cfg.DaemonArgs...)
+}
+
+// nodeCmd returns the daemon args of the non primary nodes, which only listen on the daemon port when published.
+func nodeCmd(cfg NodesConfig) []string {
+	if !cfg.PublishDaemons {
+		return cfg.DaemonArgs
+	}
+
+	return primaryCmd(cfg)
+}
+
+func nodeEntrypoint(cfg NodesConfig) []string {
+	setup := []string{swarmStateReset()}
+
+	// Write the daemon configuration before starting dockerd, "$@" being the daemon args. It is kept on restart once
+	// updated by UpdateDaemonConfig.
+	if cfg.DaemonConfig != "" {
+		setup = append(
+			setup,
+			fmt.Sprintf(
+				`mkdir -p /etc/docker && { [ -f %s ] || echo "$SIND_DAEMON_CONFIG" > /etc/docker/daemon.json; }`,
+				daemonConfigMarker,
+			),
+		)
+	}
+
+	if cfg.RestrictEgress {
+		setup = append(setup, egressSetup(cfg.EgressAllowlist))
+	}
+
+	return []string{
+		"sh",
+		"-c",
+		strings.Join(append(setup, `exec dockerd "$@"`), " && "),
+		"dockerd",
+	}
+}
+
+func nodeEnv(cfg NodesConfig) []string {
+	env := cfg.Env
+
+	if cfg.DaemonConfig != "" {
+		env = append(append([]string{}, env...), "SIND_DAEMON_CONFIG="+cfg.DaemonConfig)
+	}
+
+	return env
+}
+
+func nodeLabels(cfg NodesConfig, role string) map[string]string {
+	labels := make(map[string]string, len(cfg.Labels)+3)
+
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+
+	labels[ClusterNameLabel] = cfg.ClusterName
+	labels[NodeRoleLabel] = role
+	labels[NetworkNameLabel] = cfg.NetworkName
+
+	if cfg.TLS != nil {
+		labels[TLSLabel] = "true"
+	}
+
+	if cfg.ClientConfig != "" {
+		labels[RegistryAuthLabel] = "true"
+	}
+
+	return labels
+}
+
+// runNode creates a node container, then starts it unless the nodes are created stopped. The TLS material and the
+// client configuration are copied to the container before starting it, the CA key only to the primary node.
+func runNode(
+	ctx context.Context,
+	client nodeCreator,
+	cfg NodesConfig,
+	cConfig *container.Config,
+	hConfig *container.HostConfig,
+	nConfig *network.NetworkingConfig,
+) (string, error) {
+	applyNodeHostConfig(cfg, hConfig)
+
+	hConfig.Mounts = append(hConfig.Mounts, nodeVolumeMount(cfg, cConfig.Hostname))
+
+	ctx, span := StartSpan(ctx, "sind.node.create", map[string]string{NodeAttribute: cConfig.Hostname})
+
+	cID, err := createContainer(ctx, client, cConfig, hConfig, nConfig)
+
+	if err == nil && cfg.TLS != nil {
+		material := cfg.TLS
+		if cConfig.Labels[NodeRoleLabel] != NodeRolePrimary {
+			material = material.withoutCAKey()
+		}
+
+		err = WriteTLS(ctx, client, cID, material)
+	}
+
+	if err == nil && cfg.ClientConfig != "" {
+		err = WriteClientConfig(ctx, client, cID, cfg.ClientConfig)
+	}
+
+	if err == nil && !cfg.Stopped {
+		err = startContainer(ctx, client, cID)
+	}
+
+	span.End(err)
+
+	if err != nil {
+		return "", err
+	}
+
+	if !cfg.Stopped && cfg.OnNodeStarted != nil {
+		cfg.OnNodeStarted(cConfig.Hostname)
+	}
+
+	return cID, nil
+}
+
+// WriteClientConfig writes the docker client configuration of a node container, which can be created but not started
+// yet. It is copied rather than passed through the container environment, for the registry credentials it holds not to
+// be listed by its inspection.
+func WriteClientConfig(ctx context.Context, client containerContentCopier, cID, config string) error {
+	err := writeFiles(ctx, client, cID, clientConfigDir, []containerFile{{Name: "config.json", Content: config, Mode: 0o600}})
 	if err != nil {
+		return fmt.Errorf("unable to copy the client configuration to container %q: %w", cID, err)
+	}
+
+	return nil
+}
+
+// ReadClientConfig returns the docker client configuration written to a node container by WriteClientConfig.
+func ReadClientConfig(ctx context.Context, client containerContentReader, cID string) (string, error) {
+	var config bytes.Buffer
+
+	if err := CopyFileFromContainer(ctx, client, cID, path.Join(clientConfigDir, "config.json"), &config); err != nil {
 		return "", err
 	}
 
-	if err = client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+	return config.String(), nil
+}
+
+// applyNodeHostConfig applies the host configuration shared by all the nodes.
+func applyNodeHostConfig(cfg NodesConfig, hConfig *container.HostConfig) {
+	hConfig.Runtime = cfg.Runtime
+	hConfig.Devices = cfg.Devices
+	hConfig.MemorySwap = cfg.MemorySwap
+	hConfig.BlkioWeight = cfg.BlkioWeight
+
+	if cfg.PidsLimit != 0 {
+		pidsLimit := cfg.PidsLimit
+		hConfig.PidsLimit = &pidsLimit
+	}
+}
+
+func runContainer(ctx context.Context, client nodeCreator, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig) (string, error) {
+	cID, err := createContainer(ctx, client, cConfig, hConfig, nConfig)
+	if err != nil {
+		return "", err
+	}
+
+	if err = startContainer(ctx, client, cID); err != nil {
+		return "", err
+	}
+
+	return cID, nil
+}
+
+func startContainer(ctx context.Context, client nodeCreator, cID string) error {
+	return retry(ctx, func() error {
+		return client.ContainerStart(ctx, cID, types.ContainerStartOptions{})
+	})
+}
+
+func createContainer(ctx context.Context, client nodeCreator, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig) (string, error) {
+	var resp container.ContainerCreateCreatedBody
+
+	err := retry(ctx, func() error {
+		var err error
+
+		resp, err = client.ContainerCreate(
+			ctx,
+			cConfig,
+			hConfig,
+			nConfig,
+			cConfig.Hostname,
+		)
+
+		return err
+	})
+	if err != nil {
 		return "", err
 	}
 
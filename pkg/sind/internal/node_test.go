@@ -1,14 +1,18 @@
 package internal
 
 import (
+	"archive/tar"
 	"context"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"net"
 	"sort"
 	"testing"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/api/types/network"
 	"github.com/docker/go-connections/nat"
 	"github.com/stretchr/testify/assert"
@@ -18,6 +22,7 @@ import (
 type nodeStarterMock struct {
 	containerCreate func(context.Context, *container.Config, *container.HostConfig, *network.NetworkingConfig, string) (container.ContainerCreateCreatedBody, error)
 	containerStart  func(context.Context, string, types.ContainerStartOptions) error
+	copyToContainer func(context.Context, string, string, io.Reader, types.CopyToContainerOptions) error
 }
 
 func (s nodeStarterMock) ContainerCreate(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
@@ -26,6 +31,9 @@ func (s nodeStarterMock) ContainerCreate(ctx context.Context, ccfg *container.Co
 func (s nodeStarterMock) ContainerStart(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
 	return s.containerStart(ctx, cID, opts)
 }
+func (s nodeStarterMock) CopyToContainer(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+	return s.copyToContainer(ctx, cID, path, content, opts)
+}
 
 type fakeContainer struct {
 	name string
@@ -81,11 +89,17 @@ func TestCreateNodes(t *testing.T) {
 		},
 	}
 
+	nodeStarted := make(chan string, cfg.Managers+cfg.Workers)
+	cfg.OnNodeStarted = func(hostname string) { nodeStarted <- hostname }
+
 	cIDs, err := CreateNodes(ctx, mock, cfg)
 	require.NoError(t, err)
 
 	close(containerCreated)
 	close(containerRun)
+	close(nodeStarted)
+
+	assert.Len(t, nodeStarted, int(cfg.Managers+cfg.Workers))
 
 	t.Log(cIDs)
 
@@ -113,6 +127,8 @@ func TestCreateNodes(t *testing.T) {
 	managers := createdContainers[1:3]
 	workers := createdContainers[3:]
 
+	entrypoint := []string{"sh", "-c", swarmStateReset() + ` && exec dockerd "$@"`, "dockerd"}
+
 	// primary node container
 	assert.Equal(t, "sind-TestCluster-manager-0", primary.name)
 	assert.Equal(
@@ -121,12 +137,14 @@ func TestCreateNodes(t *testing.T) {
 			Hostname:     "sind-TestCluster-manager-0",
 			Image:        cfg.ImageRef,
 			ExposedPorts: nat.PortSet(map[nat.Port]struct{}{nat.Port("8080/tcp"): {}}),
-			Entrypoint:   []string{"dockerd"},
+			Entrypoint:   entrypoint,
 			Cmd:          []string{"-H unix:///var/run/docker.sock", "-H tcp://0.0.0.0:2375", "--fake-arg"},
 			Labels: map[string]string{
-				"com.sind.cluster.name": "TestCluster",
-				"com.sind.cluster.role": "primary",
+				"com.sind.cluster.name":    "TestCluster",
+				"com.sind.cluster.network": "bar",
+				"com.sind.cluster.role":    "primary",
 			},
+			Healthcheck: nodeHealthcheck,
 		},
 		primary.cConfig,
 	)
@@ -141,6 +159,7 @@ func TestCreateNodes(t *testing.T) {
 					{HostPort: "8080"},
 				},
 			},
+			Mounts: []mount.Mount{nodeVolumeMount(cfg, "sind-TestCluster-manager-0")},
 		},
 		primary.hConfig,
 	)
@@ -169,19 +188,21 @@ func TestCreateNodes(t *testing.T) {
 			&container.Config{
 				Hostname:   expectedContainerName,
 				Image:      cfg.ImageRef,
-				Entrypoint: []string{"dockerd"},
+				Entrypoint: entrypoint,
 				Labels: map[string]string{
-					"com.sind.cluster.name": "TestCluster",
-					"com.sind.cluster.role": "manager",
+					"com.sind.cluster.name":    "TestCluster",
+					"com.sind.cluster.network": "bar",
+					"com.sind.cluster.role":    "manager",
 				},
-				Cmd: []string{"--fake-arg"},
+				Cmd:         []string{"--fake-arg"},
+				Healthcheck: nodeHealthcheck,
 			},
 			c.cConfig,
 		)
 
 		assert.Equal(
 			t,
-			&container.HostConfig{Privileged: true},
+			&container.HostConfig{Privileged: true, Mounts: []mount.Mount{nodeVolumeMount(cfg, expectedContainerName)}},
 			c.hConfig,
 		)
 
@@ -210,19 +231,21 @@ func TestCreateNodes(t *testing.T) {
 			&container.Config{
 				Hostname:   expectedContainerName,
 				Image:      cfg.ImageRef,
-				Entrypoint: []string{"dockerd"},
+				Entrypoint: entrypoint,
 				Labels: map[string]string{
-					"com.sind.cluster.name": "TestCluster",
-					"com.sind.cluster.role": "worker",
+					"com.sind.cluster.name":    "TestCluster",
+					"com.sind.cluster.network": "bar",
+					"com.sind.cluster.role":    "worker",
 				},
-				Cmd: []string{"--fake-arg"},
+				Cmd:         []string{"--fake-arg"},
+				Healthcheck: nodeHealthcheck,
 			},
 			c.cConfig,
 		)
 
 		assert.Equal(
 			t,
-			&container.HostConfig{Privileged: true},
+			&container.HostConfig{Privileged: true, Mounts: []mount.Mount{nodeVolumeMount(cfg, expectedContainerName)}},
 			c.hConfig,
 		)
 
@@ -273,3 +296,468 @@ func TestCreateNodes(t *testing.T) {
 		},
 	})
 }
+
+func TestCreateNodesAppliesResources(t *testing.T) {
+	ctx := context.Background()
+	cfg := NodesConfig{
+		ClusterName:      "TestCluster",
+		Subnet:           net.IPNet{IP: net.IP([]byte{10, 0, 117, 0})},
+		Managers:         2,
+		Workers:          1,
+		ManagerResources: container.Resources{Memory: 2000},
+		WorkerResources:  container.Resources{Memory: 1000},
+		Labels:           map[string]string{"foo": "bar"},
+	}
+
+	resources := make(chan map[string]container.Resources, cfg.Managers+cfg.Workers)
+
+	mock := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			assert.Equal(t, "bar", cConfig.Labels["foo"])
+			resources <- map[string]container.Resources{cName: hConfig.Resources}
+			return container.ContainerCreateCreatedBody{ID: cName}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			return nil
+		},
+	}
+
+	_, err := CreateNodes(ctx, mock, cfg)
+	require.NoError(t, err)
+
+	close(resources)
+
+	result := make(map[string]container.Resources)
+	for r := range resources {
+		for name, res := range r {
+			result[name] = res
+		}
+	}
+
+	assert.Equal(
+		t,
+		map[string]container.Resources{
+			"sind-TestCluster-manager-0": cfg.ManagerResources,
+			"sind-TestCluster-manager-1": cfg.ManagerResources,
+			"sind-TestCluster-worker-0":  cfg.WorkerResources,
+		},
+		result,
+	)
+}
+
+func TestCreateNodesStopped(t *testing.T) {
+	ctx := context.Background()
+	cfg := NodesConfig{
+		ClusterName: "TestCluster",
+		Subnet:      net.IPNet{IP: net.IP([]byte{10, 0, 117, 0})},
+		Managers:    1,
+		Workers:     2,
+		Stopped:     true,
+	}
+
+	mock := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			return container.ContainerCreateCreatedBody{ID: cName}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			t.Errorf("unexpected start of container %q", cID)
+			return nil
+		},
+	}
+
+	nodeIDs, err := CreateNodes(ctx, mock, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sind-TestCluster-manager-0", nodeIDs.Primary)
+	assert.Len(t, nodeIDs.Workers, 2)
+}
+
+func TestNodeIDsOf(t *testing.T) {
+	node := func(id, role string) types.Container {
+		return types.Container{ID: id, Labels: map[string]string{NodeRoleLabel: role}}
+	}
+
+	nodeIDs := NodeIDsOf(
+		[]types.Container{
+			node("worker-0", NodeRoleWorker),
+			node("manager-1", NodeRoleManager),
+			node("manager-0", NodeRolePrimary),
+			node("worker-1", NodeRoleWorker),
+		},
+	)
+
+	assert.Equal(
+		t,
+		NodeIDs{Primary: "manager-0", Managers: []string{"manager-1"}, Workers: []string{"worker-0", "worker-1"}},
+		nodeIDs,
+	)
+}
+
+func TestNodeNetworkingConfig(t *testing.T) {
+	cfg := NodesConfig{NetworkID: "foo", NetworkName: "bar"}
+
+	assert.Equal(
+		t,
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"bar": {NetworkID: "foo"},
+			},
+		},
+		nodeNetworkingConfig(cfg, 0),
+	)
+
+	cfg.Subnet = net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(24, 32)}
+
+	assert.Equal(
+		t,
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"bar": {
+					NetworkID:  "foo",
+					IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "10.0.0.2"},
+				},
+			},
+		},
+		nodeNetworkingConfig(cfg, 0),
+	)
+}
+
+func TestNodeNetworkingConfigWithAddresses(t *testing.T) {
+	cfg := NodesConfig{
+		NetworkID:   "foo",
+		NetworkName: "bar",
+		Subnet:      net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(24, 32)},
+		Addresses:   []string{"10.0.0.100", "10.0.0.200"},
+	}
+
+	assert.Equal(
+		t,
+		&network.EndpointIPAMConfig{IPv4Address: "10.0.0.200"},
+		nodeNetworkingConfig(cfg, 1).EndpointsConfig["bar"].IPAMConfig,
+	)
+}
+
+func TestNodeAddress(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.1.2.128/25")
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.1.2.130", NodeAddress(*subnet, 0).String())
+	assert.Equal(t, "10.1.2.140", NodeAddress(*subnet, 10).String())
+
+	_, subnet, err = net.ParseCIDR("10.1.0.0/16")
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.1.1.2", NodeAddress(*subnet, 256).String())
+}
+
+func TestRoleRanges(t *testing.T) {
+	_, subnet, err := net.ParseCIDR("10.0.4.0/23")
+	require.NoError(t, err)
+
+	managers, workers := RoleRanges(*subnet)
+
+	assert.Equal(t, "10.0.4.0/24", managers.String())
+	assert.Equal(t, "10.0.5.0/24", workers.String())
+}
+
+func TestRoleAddresses(t *testing.T) {
+	cfg := NodesConfig{
+		Subnet:   net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(24, 32)},
+		Managers: 2,
+		Workers:  3,
+	}
+
+	assert.Equal(
+		t,
+		[]string{"10.0.0.2", "10.0.0.3", "10.0.0.128", "10.0.0.129", "10.0.0.130"},
+		roleAddresses(cfg),
+	)
+}
+
+func TestPrimaryPortSpecs(t *testing.T) {
+	cfg := NodesConfig{PortBindings: []string{"8080:80"}}
+
+	assert.Equal(t, []string{"8080:80"}, primaryPortSpecs(cfg))
+
+	cfg.DaemonHostPort = 12375
+
+	assert.Equal(t, []string{"8080:80", "12375:2375/tcp"}, primaryPortSpecs(cfg))
+	assert.Equal(t, []string{"8080:80"}, cfg.PortBindings)
+}
+
+func TestReplicaPorts(t *testing.T) {
+	exposedPorts, bindings, err := nat.ParsePortSpecs([]string{"127.0.0.1:8080:80", "8000-8001:8000-8001/udp"})
+	require.NoError(t, err)
+
+	ports, replicaBindings := replicaPorts(false, exposedPorts, bindings)
+	assert.Nil(t, ports)
+	assert.Nil(t, replicaBindings)
+
+	ports, replicaBindings = replicaPorts(true, exposedPorts, bindings)
+	assert.Equal(t, nat.PortSet(exposedPorts), ports)
+	assert.Equal(
+		t,
+		nat.PortMap{
+			"80/tcp":   {{HostIP: "127.0.0.1"}},
+			"8000/udp": {{}},
+			"8001/udp": {{}},
+		},
+		replicaBindings,
+	)
+}
+
+func TestApplyNodeHostConfig(t *testing.T) {
+	hConfig := container.HostConfig{Privileged: true, Resources: container.Resources{Memory: 1024}}
+
+	applyNodeHostConfig(NodesConfig{}, &hConfig)
+
+	assert.Equal(t, container.HostConfig{Privileged: true, Resources: container.Resources{Memory: 1024}}, hConfig)
+
+	applyNodeHostConfig(NodesConfig{Runtime: "runsc", PidsLimit: 4096, MemorySwap: -1, BlkioWeight: 300}, &hConfig)
+
+	assert.Equal(t, "runsc", hConfig.Runtime)
+	assert.Equal(t, int64(1024), hConfig.Memory)
+	assert.Equal(t, int64(-1), hConfig.MemorySwap)
+	assert.Equal(t, uint16(300), hConfig.BlkioWeight)
+	require.NotNil(t, hConfig.PidsLimit)
+	assert.Equal(t, int64(4096), *hConfig.PidsLimit)
+}
+
+func TestNodeCmd(t *testing.T) {
+	cfg := NodesConfig{DaemonArgs: []string{"--fake-arg"}}
+
+	assert.Equal(t, []string{"--fake-arg"}, nodeCmd(cfg))
+
+	cfg.PublishDaemons = true
+
+	assert.Equal(
+		t,
+		[]string{"-H unix:///var/run/docker.sock", "-H tcp://0.0.0.0:2375", "--fake-arg"},
+		nodeCmd(cfg),
+	)
+	assert.Equal(t, []string{"--fake-arg"}, cfg.DaemonArgs)
+}
+
+func TestNodeDaemonTLS(t *testing.T) {
+	cfg := NodesConfig{
+		ClusterName: "test",
+		DaemonArgs:  []string{"--fake-arg"},
+		Env:         []string{"FOO=bar"},
+		TLS: &TLSMaterial{
+			CACert:     "ca",
+			CAKey:      "ca-key",
+			ServerCert: "server-cert",
+			ServerKey:  "server-key",
+			ClientCert: "client-cert",
+			ClientKey:  "client-key",
+		},
+	}
+
+	tlsArgs := []string{
+		"--tlsverify",
+		"--tlscacert=/etc/sind/tls/ca.pem",
+		"--tlscert=/etc/sind/tls/server-cert.pem",
+		"--tlskey=/etc/sind/tls/server-key.pem",
+	}
+
+	assert.Equal(
+		t,
+		append(append([]string{"-H unix:///var/run/docker.sock", "-H tcp://0.0.0.0:2375"}, tlsArgs...), "--fake-arg"),
+		primaryCmd(cfg),
+	)
+	assert.Equal(t, []string{"--fake-arg"}, nodeCmd(cfg))
+	// The material is copied to the nodes, not passed through their environment.
+	assert.Equal(t, nodeEntrypoint(NodesConfig{}), nodeEntrypoint(cfg))
+	assert.Equal(t, []string{"FOO=bar"}, nodeEnv(cfg))
+	assert.Equal(t, "true", nodeLabels(cfg, NodeRolePrimary)[TLSLabel])
+
+	cfg.PublishDaemons = true
+
+	assert.Equal(t, primaryCmd(cfg), nodeCmd(cfg))
+}
+
+func TestRunNodeWritesTLS(t *testing.T) {
+	cfg := NodesConfig{
+		TLS: &TLSMaterial{
+			CACert:     "ca",
+			CAKey:      "ca-key",
+			ServerCert: "server-cert",
+			ServerKey:  "server-key",
+			ClientCert: "client-cert",
+			ClientKey:  "client-key",
+		},
+	}
+
+	testCases := []struct {
+		role      string
+		wantCAKey string
+	}{
+		{role: NodeRolePrimary, wantCAKey: "ca-key"},
+		{role: NodeRoleManager},
+		{role: NodeRoleWorker},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.role, func(t *testing.T) {
+			var (
+				written *TLSMaterial
+				started bool
+			)
+
+			client := nodeStarterMock{
+				containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+					return container.ContainerCreateCreatedBody{ID: "node"}, nil
+				},
+				containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+					// The daemon reads the material once started.
+					require.NotNil(t, written)
+					started = true
+					return nil
+				},
+				copyToContainer: func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+					assert.Equal(t, "node", cID)
+					assert.Equal(t, "/", path)
+
+					var err error
+
+					written, err = readTLSArchive(content)
+					return err
+				},
+			}
+
+			cID, err := runNode(
+				context.Background(),
+				client,
+				cfg,
+				&container.Config{Labels: map[string]string{NodeRoleLabel: test.role}},
+				&container.HostConfig{},
+				&network.NetworkingConfig{},
+			)
+			require.NoError(t, err)
+
+			assert.Equal(t, "node", cID)
+			assert.True(t, started)
+			assert.Equal(t, test.wantCAKey, written.CAKey)
+			assert.Equal(t, "server-key", written.ServerKey)
+			assert.Equal(t, "client-key", written.ClientKey)
+		})
+	}
+}
+
+func TestNodeEntrypoint(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{
+			"sh",
+			"-c",
+			`{ [ -f /etc/sind/.started ] || { rm -rf /var/lib/docker/swarm && mkdir -p /etc/sind && touch /etc/sind/.started; }; } && ` +
+				`exec dockerd "$@"`,
+			"dockerd",
+		},
+		nodeEntrypoint(NodesConfig{}),
+	)
+}
+
+func TestNodeDaemonConfig(t *testing.T) {
+	cfg := NodesConfig{Env: []string{"FOO=bar"}}
+
+	assert.Equal(t, nodeEntrypoint(NodesConfig{}), nodeEntrypoint(cfg))
+	assert.Equal(t, []string{"FOO=bar"}, nodeEnv(cfg))
+
+	cfg.DaemonConfig = `{"debug":true}`
+
+	assert.Equal(
+		t,
+		[]string{
+			"sh",
+			"-c",
+			swarmStateReset() + " && " +
+				`mkdir -p /etc/docker && { [ -f /etc/docker/.sind-updated ] || echo "$SIND_DAEMON_CONFIG" > /etc/docker/daemon.json; } && exec dockerd "$@"`,
+			"dockerd",
+		},
+		nodeEntrypoint(cfg),
+	)
+	assert.Equal(t, []string{"FOO=bar", `SIND_DAEMON_CONFIG={"debug":true}`}, nodeEnv(cfg))
+	assert.Equal(t, []string{"FOO=bar"}, cfg.Env)
+}
+
+func TestNodeRestrictEgress(t *testing.T) {
+	cfg := NodesConfig{RestrictEgress: true, EgressAllowlist: []string{"10.1.0.0/16"}}
+
+	assert.Equal(
+		t,
+		[]string{
+			"sh",
+			"-c",
+			swarmStateReset() + " && " +
+				`SUBNET=$(ip -4 route show dev eth0 scope link | cut -d ' ' -f 1) && ` +
+				`{ iptables -N SIND-EGRESS || iptables -F SIND-EGRESS; } && ` +
+				`iptables -A SIND-EGRESS -m conntrack --ctstate ESTABLISHED,RELATED -j RETURN && ` +
+				`iptables -A SIND-EGRESS -d "$SUBNET" -j RETURN && ` +
+				`iptables -A SIND-EGRESS -d 10.1.0.0/16 -j RETURN && ` +
+				`iptables -A SIND-EGRESS -j REJECT && ` +
+				`iptables -I OUTPUT -o eth0 -j SIND-EGRESS && ` +
+				`iptables -I FORWARD -o eth0 -j SIND-EGRESS && ` +
+				`exec dockerd "$@"`,
+			"dockerd",
+		},
+		nodeEntrypoint(cfg),
+	)
+}
+
+func TestNodeClientConfig(t *testing.T) {
+	cfg := NodesConfig{Env: []string{"FOO=bar"}, ClientConfig: `{"auths":{}}`}
+
+	// The configuration is copied to the nodes, not passed through their environment.
+	assert.Equal(t, nodeEntrypoint(NodesConfig{}), nodeEntrypoint(cfg))
+	assert.Equal(t, []string{"FOO=bar"}, nodeEnv(cfg))
+	assert.Equal(t, "true", nodeLabels(cfg, NodeRoleWorker)[RegistryAuthLabel])
+
+	var (
+		written string
+		started bool
+	)
+
+	client := nodeStarterMock{
+		containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			return container.ContainerCreateCreatedBody{ID: "node"}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			assert.NotEmpty(t, written)
+			started = true
+			return nil
+		},
+		copyToContainer: func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+			assert.Equal(t, "node", cID)
+			assert.Equal(t, "/", path)
+
+			tarReader := tar.NewReader(content)
+
+			for {
+				header, err := tarReader.Next()
+				if err == io.EOF {
+					return nil
+				}
+
+				require.NoError(t, err)
+
+				if header.Name != "root/.docker/config.json" {
+					continue
+				}
+
+				assert.Equal(t, int64(0o600), header.Mode)
+
+				data, err := ioutil.ReadAll(tarReader)
+				require.NoError(t, err)
+
+				written = string(data)
+			}
+		},
+	}
+
+	_, err := runNode(context.Background(), client, cfg, &container.Config{}, &container.HostConfig{}, &network.NetworkingConfig{})
+	require.NoError(t, err)
+
+	assert.True(t, started)
+	assert.Equal(t, `{"auths":{}}`, written)
+}
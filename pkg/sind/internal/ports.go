@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/docker/go-connections/nat"
+)
+
+// portMapping is a container port bound to the docker host.
+type portMapping struct {
+	proto         string
+	hostIP        string
+	hostPort      string
+	containerPort int
+}
+
+// PortRanges returns port bindings as specs of consecutive ranges, e.g. 8000-8010:8000-8010/tcp, the way ParsePortSpecs
+// expects them. Each port of a range is a distinct binding once parsed, ranges are rebuilt from the consecutive ports
+// bound to consecutive host ports of the same host IP.
+func PortRanges(bindings nat.PortMap) []string {
+	var mappings []portMapping
+
+	for port, portBindings := range bindings {
+		for _, binding := range portBindings {
+			mappings = append(mappings, portMapping{
+				proto:         port.Proto(),
+				hostIP:        binding.HostIP,
+				hostPort:      binding.HostPort,
+				containerPort: port.Int(),
+			})
+		}
+	}
+
+	sort.Slice(mappings, func(i, j int) bool {
+		a, b := mappings[i], mappings[j]
+
+		switch {
+		case a.proto != b.proto:
+			return a.proto < b.proto
+		case a.hostIP != b.hostIP:
+			return a.hostIP < b.hostIP
+		case a.containerPort != b.containerPort:
+			return a.containerPort < b.containerPort
+		default:
+			return a.hostPort < b.hostPort
+		}
+	})
+
+	var specs []string
+
+	for i := 0; i < len(mappings); {
+		end := i
+
+		for end+1 < len(mappings) && consecutive(mappings[end], mappings[end+1]) {
+			end++
+		}
+
+		specs = append(specs, portRangeSpec(mappings[i], mappings[end]))
+		i = end + 1
+	}
+
+	return specs
+}
+
+// consecutive returns true if the mapping next follows prev in a range.
+func consecutive(prev, next portMapping) bool {
+	if prev.proto != next.proto || prev.hostIP != next.hostIP || prev.containerPort+1 != next.containerPort {
+		return false
+	}
+
+	// Dynamic host ports are picked by the daemon.
+	if prev.hostPort == "" || next.hostPort == "" {
+		return prev.hostPort == next.hostPort
+	}
+
+	prevHost, err := strconv.Atoi(prev.hostPort)
+	if err != nil {
+		return false
+	}
+
+	nextHost, err := strconv.Atoi(next.hostPort)
+
+	return err == nil && prevHost+1 == nextHost
+}
+
+// portRangeSpec returns the spec of the range of mappings from first to last.
+func portRangeSpec(first, last portMapping) string {
+	containerPorts := strconv.Itoa(first.containerPort)
+	hostPorts := first.hostPort
+
+	if last.containerPort != first.containerPort {
+		containerPorts = fmt.Sprintf("%s-%d", containerPorts, last.containerPort)
+
+		if hostPorts != "" {
+			hostPorts = fmt.Sprintf("%s-%s", hostPorts, last.hostPort)
+		}
+	}
+
+	spec := fmt.Sprintf("%s/%s", containerPorts, first.proto)
+
+	if hostPorts != "" || first.hostIP != "" {
+		spec = hostPorts + ":" + spec
+	}
+
+	if first.hostIP != "" {
+		spec = first.hostIP + ":" + spec
+	}
+
+	return spec
+}
@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortRanges(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		specs    []string
+		expected []string
+	}{
+		{
+			desc:     "single port",
+			specs:    []string{"127.0.0.1:8080:80"},
+			expected: []string{"127.0.0.1:8080:80/tcp"},
+		},
+		{
+			desc:     "port range",
+			specs:    []string{"8000-8010:8000-8010"},
+			expected: []string{"8000-8010:8000-8010/tcp"},
+		},
+		{
+			desc:     "shifted port range",
+			specs:    []string{"9000-9002:8000-8002/udp"},
+			expected: []string{"9000-9002:8000-8002/udp"},
+		},
+		{
+			desc:     "dynamic host ports",
+			specs:    []string{"8000-8002"},
+			expected: []string{"8000-8002/tcp"},
+		},
+		{
+			desc:     "host port range for a single container port",
+			specs:    []string{"8000-8010:80"},
+			expected: []string{"8000-8010:80/tcp"},
+		},
+		{
+			desc:     "disjoint ranges",
+			specs:    []string{"8000-8001:8000-8001", "8003:8003", "0.0.0.0:8002:8002"},
+			expected: []string{"8000-8001:8000-8001/tcp", "8003:8003/tcp", "0.0.0.0:8002:8002/tcp"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			_, bindings, err := nat.ParsePortSpecs(test.specs)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.expected, PortRanges(bindings))
+
+			// Ranges are parsed back to the same bindings.
+			_, parsed, err := nat.ParsePortSpecs(PortRanges(bindings))
+			require.NoError(t, err)
+			assert.Equal(t, bindings, parsed)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package internal
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-units"
+)
+
+const (
+	// NodeMemoryOverhead is the estimated amount of memory used by an idle node.
+	NodeMemoryOverhead int64 = 256 * units.MiB
+
+	// NodeDiskOverhead is the estimated amount of disk used by an idle node.
+	NodeDiskOverhead int64 = 512 * units.MiB
+
+	dataSpaceAvailableStatus = "Data Space Available"
+)
+
+// memInfoPath is where the available memory of a docker host running on the local machine is read from.
+var memInfoPath = "/proc/meminfo"
+
+// ErrRuntimeNotFound is returned when a container runtime is not configured on the docker host.
+var ErrRuntimeNotFound = errors.New("container runtime not found")
+
+type infoer interface {
+	Info(context.Context) (types.Info, error)
+}
+
+// CheckHostCapacity makes sure that the docker host has enough memory and disk to run the given amount of nodes, and
+// that the memory budget of the nodes, if not zero, is enough to run them.
+func CheckHostCapacity(ctx context.Context, client infoer, nodeCount int, totalMemory int64) error {
+	requiredMemory := NodeMemoryOverhead * int64(nodeCount)
+	if totalMemory > 0 && requiredMemory > totalMemory {
+		return fmt.Errorf(
+			"memory budget too small: %d nodes require about %s, budget is %s",
+			nodeCount,
+			units.BytesSize(float64(requiredMemory)),
+			units.BytesSize(float64(totalMemory)),
+		)
+	}
+
+	info, err := client.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get docker host informations: %w", err)
+	}
+
+	availableMemory := availableMemory(info)
+	if availableMemory > 0 && requiredMemory > availableMemory {
+		return fmt.Errorf(
+			"not enough memory on the docker host: %d nodes require about %s, host has %s available",
+			nodeCount,
+			units.BytesSize(float64(requiredMemory)),
+			units.BytesSize(float64(availableMemory)),
+		)
+	}
+
+	availableDisk, ok, err := availableDiskSpace(info)
+	if err != nil {
+		return err
+	}
+
+	// Most storage drivers do not report available space, in that case there is nothing to check.
+	if !ok {
+		return nil
+	}
+
+	requiredDisk := NodeDiskOverhead * int64(nodeCount)
+	if requiredDisk > availableDisk {
+		return fmt.Errorf(
+			"not enough disk space on the docker host: %d nodes require about %s, host has %s available",
+			nodeCount,
+			units.BytesSize(float64(requiredDisk)),
+			units.BytesSize(float64(availableDisk)),
+		)
+	}
+
+	return nil
+}
+
+// availableMemory returns the memory available on the docker host. The docker API only reports the total memory, the
+// available memory is only known for a docker host running on the local machine.
+func availableMemory(info types.Info) int64 {
+	hostname, err := os.Hostname()
+	if err != nil || info.Name != hostname {
+		return info.MemTotal
+	}
+
+	file, err := os.Open(memInfoPath)
+	if err != nil {
+		return info.MemTotal
+	}
+
+	defer file.Close()
+
+	available, ok := parseMemAvailable(file)
+	if !ok {
+		return info.MemTotal
+	}
+
+	return available
+}
+
+// parseMemAvailable returns the available memory reported by a /proc/meminfo content, if any.
+func parseMemAvailable(r io.Reader) (int64, bool) {
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || fields[0] != "MemAvailable:" || fields[2] != "kB" {
+			continue
+		}
+
+		kiloBytes, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		return kiloBytes * units.KiB, true
+	}
+
+	return 0, false
+}
+
+func availableDiskSpace(info types.Info) (int64, bool, error) {
+	for _, status := range info.DriverStatus {
+		if status[0] != dataSpaceAvailableStatus {
+			continue
+		}
+
+		size, err := units.FromHumanSize(status[1])
+		if err != nil {
+			return 0, false, fmt.Errorf("unable to parse available disk space %q: %w", status[1], err)
+		}
+
+		return size, true, nil
+	}
+
+	return 0, false, nil
+}
+
+// CheckRuntime makes sure that the docker host is configured with a container runtime, e.g. runsc or kata-runtime.
+func CheckRuntime(ctx context.Context, client infoer, runtime string) error {
+	info, err := client.Info(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get docker host informations: %w", err)
+	}
+
+	if _, ok := info.Runtimes[runtime]; ok {
+		return nil
+	}
+
+	runtimes := make([]string, 0, len(info.Runtimes))
+	for name := range info.Runtimes {
+		runtimes = append(runtimes, name)
+	}
+
+	sort.Strings(runtimes)
+
+	return fmt.Errorf("%w: %q is not one of %s", ErrRuntimeNotFound, runtime, strings.Join(runtimes, ", "))
+}
@@ -0,0 +1,155 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type infoerMock func(context.Context) (types.Info, error)
+
+func (i infoerMock) Info(ctx context.Context) (types.Info, error) {
+	return i(ctx)
+}
+
+func TestCheckHostCapacity(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		info          types.Info
+		infoError     error
+		nodeCount     int
+		totalMemory   int64
+		memInfo       string
+		expectedError error
+	}{
+		{
+			desc:          "with an info error",
+			infoError:     errors.New("nope"),
+			nodeCount:     1,
+			expectedError: errors.New("unable to get docker host informations: nope"),
+		},
+		{
+			desc:      "with enough memory",
+			info:      types.Info{MemTotal: 4 * 1024 * 1024 * 1024},
+			nodeCount: 3,
+		},
+		{
+			desc:          "with not enough memory",
+			info:          types.Info{MemTotal: 512 * 1024 * 1024},
+			nodeCount:     3,
+			expectedError: errors.New("not enough memory on the docker host: 3 nodes require about 768MiB, host has 512MiB available"),
+		},
+		{
+			desc:      "with enough available memory on a local host",
+			info:      types.Info{MemTotal: 512 * 1024 * 1024},
+			nodeCount: 3,
+			memInfo:   "MemTotal:       524288 kB\nMemAvailable:   4194304 kB\n",
+		},
+		{
+			desc:          "with not enough available memory on a local host",
+			info:          types.Info{MemTotal: 4 * 1024 * 1024 * 1024},
+			nodeCount:     3,
+			memInfo:       "MemTotal:       4194304 kB\nMemAvailable:   524288 kB\n",
+			expectedError: errors.New("not enough memory on the docker host: 3 nodes require about 768MiB, host has 512MiB available"),
+		},
+		{
+			desc:        "with enough memory budget",
+			info:        types.Info{MemTotal: 4 * 1024 * 1024 * 1024},
+			nodeCount:   3,
+			totalMemory: 1024 * 1024 * 1024,
+		},
+		{
+			desc:          "with not enough memory budget",
+			info:          types.Info{MemTotal: 4 * 1024 * 1024 * 1024},
+			nodeCount:     3,
+			totalMemory:   512 * 1024 * 1024,
+			expectedError: errors.New("memory budget too small: 3 nodes require about 768MiB, budget is 512MiB"),
+		},
+		{
+			desc: "with enough disk",
+			info: types.Info{
+				MemTotal:     4 * 1024 * 1024 * 1024,
+				DriverStatus: [][2]string{{"Data Space Available", "10GB"}},
+			},
+			nodeCount: 3,
+		},
+		{
+			desc: "with not enough disk",
+			info: types.Info{
+				MemTotal:     4 * 1024 * 1024 * 1024,
+				DriverStatus: [][2]string{{"Data Space Available", "1GB"}},
+			},
+			nodeCount:     3,
+			expectedError: errors.New("not enough disk space on the docker host: 3 nodes require about 1.5GiB, host has 953.7MiB available"),
+		},
+		{
+			desc: "with an invalid disk status",
+			info: types.Info{
+				DriverStatus: [][2]string{{"Data Space Available", "lots"}},
+			},
+			nodeCount:     3,
+			expectedError: errors.New("unable to parse available disk space \"lots\": invalid size: 'lots'"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+			info := test.info
+
+			// The memory available on the docker host is only read when it runs on the local machine.
+			if test.memInfo != "" {
+				hostname, err := os.Hostname()
+				require.NoError(t, err)
+
+				info.Name = hostname
+
+				path := filepath.Join(t.TempDir(), "meminfo")
+				require.NoError(t, ioutil.WriteFile(path, []byte(test.memInfo), 0600))
+
+				defer func(previous string) { memInfoPath = previous }(memInfoPath)
+				memInfoPath = path
+			}
+
+			client := infoerMock(func(ctx context.Context) (types.Info, error) {
+				return info, test.infoError
+			})
+
+			err := CheckHostCapacity(ctx, client, test.nodeCount, test.totalMemory)
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestParseMemAvailable(t *testing.T) {
+	available, ok := parseMemAvailable(strings.NewReader("MemTotal:       8048576 kB\nMemFree:         123456 kB\nMemAvailable:    2097152 kB\n"))
+	assert.True(t, ok)
+	assert.EqualValues(t, 2*1024*1024*1024, available)
+
+	_, ok = parseMemAvailable(strings.NewReader("MemTotal:       8048576 kB\nMemFree:         123456 kB\n"))
+	assert.False(t, ok)
+}
+
+func TestCheckRuntime(t *testing.T) {
+	client := infoerMock(func(ctx context.Context) (types.Info, error) {
+		return types.Info{Runtimes: map[string]types.Runtime{"runc": {}, "runsc": {Path: "/usr/bin/runsc"}}}, nil
+	})
+
+	assert.NoError(t, CheckRuntime(context.Background(), client, "runsc"))
+
+	err := CheckRuntime(context.Background(), client, "kata-runtime")
+	assert.True(t, errors.Is(err, ErrRuntimeNotFound))
+	assert.EqualError(t, err, `container runtime not found: "kata-runtime" is not one of runc, runsc`)
+}
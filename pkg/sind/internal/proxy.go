@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+const (
+	// DefaultProxyImageName is the image used to run the port proxies containers.
+	DefaultProxyImageName = "alpine/socat:latest"
+)
+
+// ProxyConfig is the configuration of a port proxy container.
+type ProxyConfig struct {
+	ClusterName string
+	ImageRef    string
+
+	NetworkID   string
+	NetworkName string
+
+	HostPort   uint16
+	TargetHost string
+	TargetPort uint16
+
+	// Labels are additional labels applied to the container.
+	Labels map[string]string
+}
+
+// CreateProxy runs a container forwarding the port HostPort of the docker host to TargetHost:TargetPort on the cluster network.
+func CreateProxy(ctx context.Context, docker nodeCreator, cfg ProxyConfig) (string, error) {
+	targetPort := nat.Port(fmt.Sprintf("%d/tcp", cfg.TargetPort))
+
+	return runContainer(
+		ctx,
+		docker,
+		&container.Config{
+			Hostname:     fmt.Sprintf("sind-%s-proxy-%d", cfg.ClusterName, cfg.HostPort),
+			Image:        cfg.ImageRef,
+			ExposedPorts: nat.PortSet{targetPort: struct{}{}},
+			Labels:       componentLabels(cfg.ClusterName, ComponentPortProxy, cfg.Labels),
+			Cmd: []string{
+				fmt.Sprintf("TCP-LISTEN:%d,fork,reuseaddr", cfg.TargetPort),
+				fmt.Sprintf("TCP-CONNECT:%s:%d", cfg.TargetHost, cfg.TargetPort),
+			},
+		},
+		&container.HostConfig{
+			PortBindings: nat.PortMap{
+				targetPort: {{HostPort: strconv.Itoa(int(cfg.HostPort))}},
+			},
+		},
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				cfg.NetworkName: {NetworkID: cfg.NetworkID},
+			},
+		},
+	)
+}
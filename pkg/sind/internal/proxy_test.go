@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateProxy(t *testing.T) {
+	ctx := context.Background()
+	cfg := ProxyConfig{
+		ClusterName: "TestCluster",
+		ImageRef:    "socat",
+		NetworkID:   "ababababab",
+		NetworkName: "bar",
+		HostPort:    8081,
+		TargetHost:  "10.0.117.2",
+		TargetPort:  80,
+	}
+
+	var created *fakeContainer
+
+	mock := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			created = &fakeContainer{
+				name:    cName,
+				cConfig: cConfig,
+				hConfig: hConfig,
+				nConfig: nConfig,
+			}
+
+			return container.ContainerCreateCreatedBody{ID: cName}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			return nil
+		},
+	}
+
+	cID, err := CreateProxy(ctx, mock, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sind-TestCluster-proxy-8081", cID)
+	assert.Equal(
+		t,
+		&container.Config{
+			Hostname:     "sind-TestCluster-proxy-8081",
+			Image:        cfg.ImageRef,
+			ExposedPorts: nat.PortSet{nat.Port("80/tcp"): {}},
+			Labels: map[string]string{
+				"com.sind.cluster.name":      "TestCluster",
+				"com.sind.cluster.component": "port-proxy",
+			},
+			Cmd: []string{"TCP-LISTEN:80,fork,reuseaddr", "TCP-CONNECT:10.0.117.2:80"},
+		},
+		created.cConfig,
+	)
+	assert.Equal(
+		t,
+		&container.HostConfig{
+			PortBindings: nat.PortMap{
+				nat.Port("80/tcp"): {{HostPort: "8081"}},
+			},
+		},
+		created.hConfig,
+	)
+	assert.Equal(
+		t,
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"bar": {NetworkID: "ababababab"},
+			},
+		},
+		created.nConfig,
+	)
+}
+
+func TestCreateProxyFailsOnCreateError(t *testing.T) {
+	ctx := context.Background()
+	mock := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			return container.ContainerCreateCreatedBody{}, errors.New("nope")
+		},
+	}
+
+	_, err := CreateProxy(ctx, mock, ProxyConfig{})
+	assert.Error(t, err)
+}
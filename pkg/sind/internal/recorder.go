@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+// RecordedContainer is a container a ContainerRecorder has been asked to create.
+type RecordedContainer struct {
+	Name             string
+	Config           *container.Config
+	HostConfig       *container.HostConfig
+	NetworkingConfig *network.NetworkingConfig
+}
+
+// ContainerRecorder records the containers it is asked to create instead of creating them, to plan a creation without
+// touching the docker host.
+type ContainerRecorder struct {
+	mu         sync.Mutex
+	containers []RecordedContainer
+}
+
+// ContainerCreate records the container, its name is used as its ID.
+func (r *ContainerRecorder) ContainerCreate(
+	_ context.Context,
+	cConfig *container.Config,
+	hConfig *container.HostConfig,
+	nConfig *network.NetworkingConfig,
+	name string,
+) (container.ContainerCreateCreatedBody, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.containers = append(r.containers, RecordedContainer{
+		Name:             name,
+		Config:           cConfig,
+		HostConfig:       hConfig,
+		NetworkingConfig: nConfig,
+	})
+
+	return container.ContainerCreateCreatedBody{ID: name}, nil
+}
+
+// ContainerStart does nothing.
+func (r *ContainerRecorder) ContainerStart(context.Context, string, types.ContainerStartOptions) error {
+	return nil
+}
+
+// CopyToContainer does nothing.
+func (r *ContainerRecorder) CopyToContainer(context.Context, string, string, io.Reader, types.CopyToContainerOptions) error {
+	return nil
+}
+
+// Containers returns the recorded containers, sorted by name.
+func (r *ContainerRecorder) Containers() []RecordedContainer {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	containers := append([]RecordedContainer{}, r.containers...)
+	sort.Slice(containers, func(i, j int) bool { return containers[i].Name < containers[j].Name })
+
+	return containers
+}
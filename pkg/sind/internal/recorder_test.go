@@ -0,0 +1,29 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContainerRecorder(t *testing.T) {
+	ctx := context.Background()
+	recorder := ContainerRecorder{}
+
+	for _, name := range []string{"b", "a"} {
+		resp, err := recorder.ContainerCreate(ctx, &container.Config{Image: name}, &container.HostConfig{}, nil, name)
+		require.NoError(t, err)
+		assert.Equal(t, name, resp.ID)
+		require.NoError(t, recorder.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}))
+	}
+
+	containers := recorder.Containers()
+	require.Len(t, containers, 2)
+	assert.Equal(t, "a", containers[0].Name)
+	assert.Equal(t, "a", containers[0].Config.Image)
+	assert.Equal(t, "b", containers[1].Name)
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// RetryPolicy configures the retries of the docker API operations failing with a transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum amount of attempts of an operation, 1 disables retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry, doubled at each attempt up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is the retry policy used when none is set in the context.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: 100 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+type retryPolicyKey struct{}
+
+// WithRetryPolicy returns a context carrying given retry policy, applied to the docker API operations using it.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return context.WithValue(ctx, retryPolicyKey{}, policy)
+}
+
+func retryPolicyFrom(ctx context.Context) RetryPolicy {
+	if policy, ok := ctx.Value(retryPolicyKey{}).(RetryPolicy); ok {
+		return policy
+	}
+
+	return DefaultRetryPolicy
+}
+
+// retry runs op until it succeeds, fails with a non transient error, or the attempts of the context policy are exhausted.
+func retry(ctx context.Context, op func() error) error {
+	policy := retryPolicyFrom(ctx)
+
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil || attempt >= policy.MaxAttempts || !transient(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.backoff(attempt))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// backoff returns the delay before retrying an operation which failed attempt times.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.InitialBackoff
+
+	for i := 1; i < attempt && backoff < p.MaxBackoff; i++ {
+		backoff *= 2
+	}
+
+	if backoff > p.MaxBackoff {
+		return p.MaxBackoff
+	}
+
+	return backoff
+}
+
+// transient returns true for the connection errors dind daemons routinely return under load.
+func transient(err error) bool {
+	if errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	// The docker client does not always wrap the underlying network errors.
+	msg := err.Error()
+
+	return strings.HasSuffix(msg, "EOF") ||
+		strings.Contains(msg, "connection reset by peer") ||
+		strings.Contains(msg, "connection refused")
+}
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	testCases := []struct {
+		desc             string
+		errs             []error
+		expectedAttempts int
+		expectedError    error
+	}{
+		{
+			desc:             "succeeds at first attempt",
+			errs:             []error{nil},
+			expectedAttempts: 1,
+		},
+		{
+			desc:             "succeeds after transient errors",
+			errs:             []error{io.EOF, fmt.Errorf("read: connection reset by peer"), nil},
+			expectedAttempts: 3,
+		},
+		{
+			desc:             "gives up after max attempts",
+			errs:             []error{io.EOF, io.EOF, io.EOF, nil},
+			expectedAttempts: 3,
+			expectedError:    io.EOF,
+		},
+		{
+			desc:             "does not retry other errors",
+			errs:             []error{errors.New("no such image"), nil},
+			expectedAttempts: 1,
+			expectedError:    errors.New("no such image"),
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := WithRetryPolicy(context.Background(), policy)
+
+			var attempts int
+
+			err := retry(ctx, func() error {
+				err := test.errs[attempts]
+				attempts++
+				return err
+			})
+
+			if test.expectedError != nil {
+				assert.EqualError(t, err, test.expectedError.Error())
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, test.expectedAttempts, attempts)
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MaxAttempts: 10, InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+
+	testCases := []struct {
+		attempt         int
+		expectedBackoff time.Duration
+	}{
+		{attempt: 1, expectedBackoff: 100 * time.Millisecond},
+		{attempt: 2, expectedBackoff: 200 * time.Millisecond},
+		{attempt: 3, expectedBackoff: 400 * time.Millisecond},
+		{attempt: 4, expectedBackoff: 800 * time.Millisecond},
+		{attempt: 5, expectedBackoff: time.Second},
+		{attempt: 9, expectedBackoff: time.Second},
+	}
+
+	for _, test := range testCases {
+		t.Run(fmt.Sprintf("attempt %d", test.attempt), func(t *testing.T) {
+			assert.Equal(t, test.expectedBackoff, policy.backoff(test.attempt))
+		})
+	}
+}
+
+func TestRetryStopsWhenContextIsDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(WithRetryPolicy(
+		context.Background(),
+		RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Hour, MaxBackoff: time.Hour},
+	))
+	cancel()
+
+	var attempts int
+
+	err := retry(ctx, func() error {
+		attempts++
+		return io.EOF
+	})
+
+	assert.Equal(t, io.EOF, err)
+	assert.Equal(t, 1, attempts)
+}
+
+func TestRetryPolicyFrom(t *testing.T) {
+	assert.Equal(t, DefaultRetryPolicy, retryPolicyFrom(context.Background()))
+
+	policy := RetryPolicy{MaxAttempts: 1}
+	assert.Equal(t, policy, retryPolicyFrom(WithRetryPolicy(context.Background(), policy)))
+}
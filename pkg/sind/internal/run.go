@@ -0,0 +1,95 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// RunConfig is the configuration of a short lived container.
+type RunConfig struct {
+	ImageRef string
+	// Network is the network the container is attached to, the daemon default if empty.
+	Network string
+	Cmd     []string
+}
+
+// RunResult is the outcome of a short lived container.
+type RunResult struct {
+	ExitCode int
+	Stdout   string
+	Stderr   string
+}
+
+type containerRunner interface {
+	nodeCreator
+	ContainerWait(context.Context, string, container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error)
+	ContainerLogs(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	ContainerRemove(context.Context, string, types.ContainerRemoveOptions) error
+}
+
+// RunContainer runs a container until it exits, collects its output then removes it.
+func RunContainer(ctx context.Context, client containerRunner, cfg RunConfig) (*RunResult, error) {
+	var networkingConfig network.NetworkingConfig
+
+	if cfg.Network != "" {
+		networkingConfig.EndpointsConfig = map[string]*network.EndpointSettings{cfg.Network: {}}
+	}
+
+	resp, err := client.ContainerCreate(
+		ctx,
+		&container.Config{Image: cfg.ImageRef, Cmd: cfg.Cmd},
+		&container.HostConfig{NetworkMode: container.NetworkMode(cfg.Network)},
+		&networkingConfig,
+		"",
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the container: %w", err)
+	}
+
+	defer func() {
+		_ = client.ContainerRemove(ctx, resp.ID, types.ContainerRemoveOptions{Force: true})
+	}()
+
+	if err = client.ContainerStart(ctx, resp.ID, types.ContainerStartOptions{}); err != nil {
+		return nil, fmt.Errorf("unable to start the container: %w", err)
+	}
+
+	var result RunResult
+
+	waitResult, waitErr := client.ContainerWait(ctx, resp.ID, container.WaitConditionNotRunning)
+
+	select {
+	case body := <-waitResult:
+		if body.Error != nil {
+			return nil, fmt.Errorf("unable to wait for the container to exit: %s", body.Error.Message)
+		}
+
+		result.ExitCode = int(body.StatusCode)
+	case err = <-waitErr:
+		return nil, fmt.Errorf("unable to wait for the container to exit: %w", err)
+	}
+
+	logs, err := client.ContainerLogs(ctx, resp.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the container logs: %w", err)
+	}
+
+	defer logs.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if _, err = stdcopy.StdCopy(&stdout, &stderr, logs); err != nil {
+		return nil, fmt.Errorf("unable to read the container logs: %w", err)
+	}
+
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+
+	return &result, nil
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type containerRunnerMock struct {
+	nodeStarterMock
+
+	containerWait   func(context.Context, string, container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error)
+	containerLogs   func(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	containerRemove func(context.Context, string, types.ContainerRemoveOptions) error
+}
+
+func (m containerRunnerMock) ContainerWait(ctx context.Context, cID string, condition container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error) {
+	return m.containerWait(ctx, cID, condition)
+}
+
+func (m containerRunnerMock) ContainerLogs(ctx context.Context, cID string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+	return m.containerLogs(ctx, cID, opts)
+}
+
+func (m containerRunnerMock) ContainerRemove(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+	return m.containerRemove(ctx, cID, opts)
+}
+
+func TestRunContainer(t *testing.T) {
+	ctx := context.Background()
+	cfg := RunConfig{ImageRef: "curlimages/curl:latest", Network: "web_default", Cmd: []string{"-s", "http://web"}}
+
+	var removed []string
+
+	client := containerRunnerMock{
+		nodeStarterMock: nodeStarterMock{
+			containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+				assert.Equal(t, cfg.ImageRef, cConfig.Image)
+				assert.Equal(t, cfg.Cmd, []string(cConfig.Cmd))
+				assert.Equal(t, container.NetworkMode("web_default"), hConfig.NetworkMode)
+				assert.Contains(t, nConfig.EndpointsConfig, "web_default")
+
+				return container.ContainerCreateCreatedBody{ID: "run"}, nil
+			},
+			containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+				return nil
+			},
+		},
+		containerWait: func(ctx context.Context, cID string, condition container.WaitCondition) (<-chan container.ContainerWaitOKBody, <-chan error) {
+			result := make(chan container.ContainerWaitOKBody, 1)
+			result <- container.ContainerWaitOKBody{StatusCode: 7}
+
+			return result, make(chan error)
+		},
+		containerLogs: func(ctx context.Context, cID string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+			var logs bytes.Buffer
+
+			_, _ = stdcopy.NewStdWriter(&logs, stdcopy.Stdout).Write([]byte("out"))
+			_, _ = stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte("connection refused"))
+
+			return ioutil.NopCloser(&logs), nil
+		},
+		containerRemove: func(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+			assert.True(t, opts.Force)
+			removed = append(removed, cID)
+			return nil
+		},
+	}
+
+	result, err := RunContainer(ctx, client, cfg)
+	require.NoError(t, err)
+	assert.Equal(t, &RunResult{ExitCode: 7, Stdout: "out", Stderr: "connection refused"}, result)
+	assert.Equal(t, []string{"run"}, removed)
+}
@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-connections/nat"
+)
+
+type nodeReplicator interface {
+	nodeCreator
+	containerContentReader
+	ContainerInspect(context.Context, string) (types.ContainerJSON, error)
+}
+
+// ReplicateNode creates and starts nodes with given role and indexes, copying the configuration of the template node.
+// When the template is the primary node, its port bindings and the listener of its daemon port are not copied, unless
+// the nodes of the cluster are plain, which publish their daemon port on random host ports.
+// The TLS material, if not nil, and the client configuration of the template are copied to the nodes before starting
+// them.
+func ReplicateNode(ctx context.Context, client nodeReplicator, templateID, role string, indexes []int, material *TLSMaterial) ([]string, error) {
+	template, err := client.ContainerInspect(ctx, templateID)
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect node %q: %w", templateID, err)
+	}
+
+	clusterName := template.Config.Labels[ClusterNameLabel]
+
+	var clientConfig string
+
+	if template.Config.Labels[RegistryAuthLabel] == "true" {
+		if clientConfig, err = ReadClientConfig(ctx, client, templateID); err != nil {
+			return nil, err
+		}
+	}
+
+	cIDs := make([]string, 0, len(indexes))
+
+	for _, index := range indexes {
+		cConfig, hConfig := replicaConfig(template, role)
+		cConfig.Hostname = fmt.Sprintf("sind-%s-%s-%d", clusterName, role, index)
+		hConfig.Mounts = nodeMounts(hConfig.Mounts, cConfig.Hostname)
+
+		spanCtx, span := StartSpan(ctx, "sind.node.create", map[string]string{NodeAttribute: cConfig.Hostname})
+
+		cID, err := createContainer(spanCtx, client, cConfig, hConfig, replicaNetworkingConfig(template))
+
+		if err == nil && material != nil {
+			err = WriteTLS(spanCtx, client, cID, material)
+		}
+
+		if err == nil && clientConfig != "" {
+			err = WriteClientConfig(spanCtx, client, cID, clientConfig)
+		}
+
+		if err == nil {
+			err = startContainer(spanCtx, client, cID)
+		}
+
+		span.End(err)
+
+		if err != nil {
+			return cIDs, fmt.Errorf("unable to create node %q: %w", cConfig.Hostname, err)
+		}
+
+		cIDs = append(cIDs, cID)
+	}
+
+	return cIDs, nil
+}
+
+// replicaConfig returns the configuration of a node with given role copied from template, without hostname.
+func replicaConfig(template types.ContainerJSON, role string) (*container.Config, *container.HostConfig) {
+	cConfig := *template.Config
+	hConfig := *template.HostConfig
+
+	cConfig.Labels = make(map[string]string, len(template.Config.Labels))
+
+	for k, v := range template.Config.Labels {
+		cConfig.Labels[k] = v
+	}
+
+	cConfig.Labels[NodeRoleLabel] = role
+
+	if template.Config.Labels[NodeRoleLabel] != NodeRolePrimary {
+		return &cConfig, &hConfig
+	}
+
+	hConfig.PortBindings = nil
+
+	if template.Config.Labels[PlainClusterLabel] == "true" {
+		cConfig.ExposedPorts = nat.PortSet{nat.Port(fmt.Sprintf("%d/tcp", dockerDaemonPort)): struct{}{}}
+
+		return &cConfig, &hConfig
+	}
+
+	cConfig.ExposedPorts = nil
+	cConfig.Cmd = withoutDaemonHostArgs(template.Config.Cmd)
+	hConfig.PublishAllPorts = false
+
+	return &cConfig, &hConfig
+}
+
+// withoutDaemonHostArgs returns the daemon args of the primary node without the ones making it listen on its daemon
+// port.
+func withoutDaemonHostArgs(args []string) []string {
+	listenArgs := make(map[string]struct{})
+
+	for _, arg := range append(append([]string{}, daemonHostArgs...), tlsDaemonArgs()...) {
+		listenArgs[arg] = struct{}{}
+	}
+
+	var result []string
+
+	for _, arg := range args {
+		if _, ok := listenArgs[arg]; !ok {
+			result = append(result, arg)
+		}
+	}
+
+	return result
+}
+
+// replicaNetworkingConfig returns the endpoints of the template networks, addresses being picked by the daemon.
+func replicaNetworkingConfig(template types.ContainerJSON) *network.NetworkingConfig {
+	endpoints := make(map[string]*network.EndpointSettings)
+
+	if template.NetworkSettings != nil {
+		for name, endpoint := range template.NetworkSettings.Networks {
+			endpoints[name] = &network.EndpointSettings{NetworkID: endpoint.NetworkID}
+		}
+	}
+
+	return &network.NetworkingConfig{EndpointsConfig: endpoints}
+}
+
+// DrainSwarmNodes drains the swarm nodes with given hostnames, demoting the managers, then waits for the tasks scheduled
+// on them to be terminated. It returns the IDs of the swarm nodes.
+func DrainSwarmNodes(ctx context.Context, client nodeDrainer, hostnames []string) ([]string, error) {
+	nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	drained := make(map[string]struct{}, len(hostnames))
+	for _, hostname := range hostnames {
+		drained[hostname] = struct{}{}
+	}
+
+	var (
+		nodeIDs []string
+		args    = filters.NewArgs()
+	)
+
+	for _, node := range nodes {
+		if _, ok := drained[node.Description.Hostname]; !ok {
+			continue
+		}
+
+		spec := node.Spec
+		spec.Availability = swarm.NodeAvailabilityDrain
+		spec.Role = swarm.NodeRoleWorker
+
+		if err = client.NodeUpdate(ctx, node.ID, node.Version, spec); err != nil {
+			return nil, fmt.Errorf("unable to drain node %q: %w", node.Description.Hostname, err)
+		}
+
+		nodeIDs = append(nodeIDs, node.ID)
+		args.Add("node", node.ID)
+	}
+
+	if len(nodeIDs) == 0 {
+		return nil, nil
+	}
+
+	if err = waitTasksTerminated(ctx, client, types.TaskListOptions{Filters: args}); err != nil {
+		return nil, err
+	}
+
+	return nodeIDs, nil
+}
+
+// RemoveSwarmNodes removes the swarm nodes with given IDs, which left the swarm.
+func RemoveSwarmNodes(ctx context.Context, client swarmNodeRemover, nodeIDs []string) error {
+	for _, nodeID := range nodeIDs {
+		if err := client.NodeRemove(ctx, nodeID, types.NodeRemoveOptions{Force: true}); err != nil {
+			return fmt.Errorf("unable to remove node %q from the swarm: %w", nodeID, err)
+		}
+	}
+
+	return nil
+}
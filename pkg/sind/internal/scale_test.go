@@ -0,0 +1,207 @@
+package internal
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/strslice"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/go-connections/nat"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReplicateNode(t *testing.T) {
+	template := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			ID: "worker",
+			HostConfig: &container.HostConfig{
+				Privileged:   true,
+				PortBindings: nat.PortMap{"80/tcp": {{HostPort: ""}}},
+				Mounts:       []mount.Mount{{Type: mount.TypeVolume, Source: "sind-test-worker-0-data", Target: "/var/lib/docker"}},
+			},
+		},
+		Config: &container.Config{
+			Hostname: "sind-test-worker-0",
+			Image:    "docker:dind",
+			Cmd:      []string{"--fake-arg"},
+			Labels:   map[string]string{ClusterNameLabel: "test", NodeRoleLabel: NodeRoleWorker},
+		},
+		NetworkSettings: &types.NetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"test-net": {
+					NetworkID:  "net",
+					IPAMConfig: &network.EndpointIPAMConfig{IPv4Address: "10.0.117.3"},
+					IPAddress:  "10.0.117.3",
+				},
+			},
+		},
+	}
+
+	var created, written []string
+
+	material := &TLSMaterial{CACert: "ca", ServerCert: "server-cert", ServerKey: "server-key", ClientCert: "client-cert", ClientKey: "client-key"}
+
+	client := nodeRecreatorMock{
+		nodeStarterMock: nodeStarterMock{
+			containerCreate: func(ctx context.Context, ccfg *container.Config, hcfg *container.HostConfig, ncfg *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+				assert.Equal(t, cName, ccfg.Hostname)
+				assert.Equal(t, "docker:dind", ccfg.Image)
+				assert.Equal(t, strslice.StrSlice{"--fake-arg"}, ccfg.Cmd)
+				assert.Equal(t, NodeRoleWorker, ccfg.Labels[NodeRoleLabel])
+				assert.Equal(t, template.HostConfig.PortBindings, hcfg.PortBindings)
+				// Each node gets its own daemon state volume.
+				assert.Equal(
+					t,
+					[]mount.Mount{{Type: mount.TypeVolume, Source: cName + "-data", Target: "/var/lib/docker"}},
+					hcfg.Mounts,
+				)
+				assert.Equal(
+					t,
+					map[string]*network.EndpointSettings{"test-net": {NetworkID: "net"}},
+					ncfg.EndpointsConfig,
+				)
+
+				created = append(created, cName)
+
+				return container.ContainerCreateCreatedBody{ID: cName}, nil
+			},
+			containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+				assert.Contains(t, written, cID)
+				return nil
+			},
+			copyToContainer: func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+				read, err := readTLSArchive(content)
+				require.NoError(t, err)
+				assert.Equal(t, material, read)
+
+				written = append(written, cID)
+
+				return nil
+			},
+		},
+		containerInspect: func(ctx context.Context, cID string) (types.ContainerJSON, error) {
+			assert.Equal(t, "worker", cID)
+			return template, nil
+		},
+	}
+
+	cIDs, err := ReplicateNode(context.Background(), client, "worker", NodeRoleWorker, []int{1, 2}, material)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"sind-test-worker-1", "sind-test-worker-2"}, cIDs)
+	assert.Equal(t, cIDs, created)
+	assert.Equal(t, cIDs, written)
+	assert.Equal(t, "sind-test-worker-0", template.Config.Hostname)
+	assert.Equal(t, "sind-test-worker-0-data", template.HostConfig.Mounts[0].Source)
+}
+
+func TestReplicaConfig(t *testing.T) {
+	primary := func(plain bool) types.ContainerJSON {
+		labels := map[string]string{ClusterNameLabel: "test", NodeRoleLabel: NodeRolePrimary}
+		if plain {
+			labels[PlainClusterLabel] = "true"
+		}
+
+		return types.ContainerJSON{
+			ContainerJSONBase: &types.ContainerJSONBase{
+				HostConfig: &container.HostConfig{
+					Privileged:      true,
+					PublishAllPorts: true,
+					PortBindings:    nat.PortMap{"2375/tcp": {{HostPort: "2375"}}, "80/tcp": {{HostPort: "8080"}}},
+				},
+			},
+			Config: &container.Config{
+				ExposedPorts: nat.PortSet{"2375/tcp": {}, "80/tcp": {}},
+				Cmd:          append(append(append([]string{}, daemonHostArgs...), tlsDaemonArgs()...), "--fake-arg"),
+				Labels:       labels,
+			},
+		}
+	}
+
+	template := primary(false)
+	cConfig, hConfig := replicaConfig(template, NodeRoleManager)
+
+	assert.Equal(t, NodeRoleManager, cConfig.Labels[NodeRoleLabel])
+	assert.Equal(t, NodeRolePrimary, template.Config.Labels[NodeRoleLabel])
+	assert.Equal(t, strslice.StrSlice{"--fake-arg"}, cConfig.Cmd)
+	assert.Nil(t, cConfig.ExposedPorts)
+	assert.Nil(t, hConfig.PortBindings)
+	assert.False(t, hConfig.PublishAllPorts)
+	assert.True(t, hConfig.Privileged)
+	assert.True(t, template.HostConfig.PublishAllPorts)
+
+	template = primary(true)
+	cConfig, hConfig = replicaConfig(template, NodeRoleWorker)
+
+	assert.Equal(t, NodeRoleWorker, cConfig.Labels[NodeRoleLabel])
+	assert.Equal(t, template.Config.Cmd, cConfig.Cmd)
+	assert.Equal(t, nat.PortSet{"2375/tcp": {}}, cConfig.ExposedPorts)
+	assert.Nil(t, hConfig.PortBindings)
+	assert.True(t, hConfig.PublishAllPorts)
+}
+
+func TestDrainSwarmNodes(t *testing.T) {
+	var (
+		updated = make(map[string]swarm.NodeSpec)
+		polls   int
+	)
+
+	client := nodeDrainerMock{
+		nodeList: func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			return []swarm.Node{
+				{ID: "primary", Spec: swarm.NodeSpec{Role: swarm.NodeRoleManager}, Description: swarm.NodeDescription{Hostname: "sind-test-manager-0"}},
+				{ID: "manager", Spec: swarm.NodeSpec{Role: swarm.NodeRoleManager}, Description: swarm.NodeDescription{Hostname: "sind-test-manager-1"}},
+				{ID: "worker", Spec: swarm.NodeSpec{Role: swarm.NodeRoleWorker}, Description: swarm.NodeDescription{Hostname: "sind-test-worker-0"}},
+			}, nil
+		},
+		nodeUpdate: func(ctx context.Context, nodeID string, version swarm.Version, spec swarm.NodeSpec) error {
+			updated[nodeID] = spec
+			return nil
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.ElementsMatch(t, []string{"manager", "worker"}, opts.Filters.Get("node"))
+
+			polls++
+			if polls == 1 {
+				return []swarm.Task{{NodeID: "worker", Status: swarm.TaskStatus{State: swarm.TaskStateRunning}}}, nil
+			}
+
+			return []swarm.Task{{NodeID: "worker", Status: swarm.TaskStatus{State: swarm.TaskStateShutdown}}}, nil
+		},
+	}
+
+	nodeIDs, err := DrainSwarmNodes(context.Background(), client, []string{"sind-test-manager-1", "sind-test-worker-0"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"manager", "worker"}, nodeIDs)
+	assert.Equal(
+		t,
+		map[string]swarm.NodeSpec{
+			"manager": {Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityDrain},
+			"worker":  {Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityDrain},
+		},
+		updated,
+	)
+	assert.Equal(t, 2, polls)
+}
+
+func TestRemoveSwarmNodes(t *testing.T) {
+	var removed []string
+
+	client := swarmNodeRemoverMock{
+		nodeRemove: func(ctx context.Context, nodeID string, opts types.NodeRemoveOptions) error {
+			assert.True(t, opts.Force)
+			removed = append(removed, nodeID)
+			return nil
+		},
+	}
+
+	require.NoError(t, RemoveSwarmNodes(context.Background(), client, []string{"manager", "worker"}))
+	assert.Equal(t, []string{"manager", "worker"}, removed)
+}
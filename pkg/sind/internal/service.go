@@ -0,0 +1,362 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+const servicePollInterval = 100 * time.Millisecond
+
+// ErrServiceUpdateFailed is returned when a service update is paused or rolled back by swarm.
+var ErrServiceUpdateFailed = errors.New("service update failed")
+
+type serviceUpdater interface {
+	ServiceInspectWithRaw(context.Context, string, types.ServiceInspectOptions) (swarm.Service, []byte, error)
+	ServiceUpdate(context.Context, string, swarm.Version, swarm.ServiceSpec, types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error)
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+// ScaleService sets the amount of replicas of a replicated service then waits for all of them to be running.
+func ScaleService(ctx context.Context, client serviceUpdater, name string, replicas uint64) error {
+	service, _, err := client.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to inspect service %q: %w", name, err)
+	}
+
+	if service.Spec.Mode.Replicated == nil {
+		return fmt.Errorf("service %q is not a replicated service", name)
+	}
+
+	service.Spec.Mode.Replicated.Replicas = &replicas
+
+	if _, err = client.ServiceUpdate(ctx, service.ID, service.Version, service.Spec, types.ServiceUpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update service %q: %w", name, err)
+	}
+
+	return waitServiceReplicas(ctx, client, service.ID, replicas)
+}
+
+// UpdateServiceImage changes the image of a service then waits for the rollout to complete.
+// It returns an ErrServiceUpdateFailed error if swarm pauses or rolls back the update.
+func UpdateServiceImage(ctx context.Context, client serviceUpdater, name, image string) error {
+	service, _, err := client.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to inspect service %q: %w", name, err)
+	}
+
+	if service.Spec.TaskTemplate.ContainerSpec == nil {
+		return fmt.Errorf("service %q has no container spec", name)
+	}
+
+	if service.Spec.TaskTemplate.ContainerSpec.Image == image {
+		return nil
+	}
+
+	previousStatus := service.UpdateStatus
+	service.Spec.TaskTemplate.ContainerSpec.Image = image
+
+	if _, err = client.ServiceUpdate(ctx, service.ID, service.Version, service.Spec, types.ServiceUpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update service %q: %w", name, err)
+	}
+
+	return waitServiceUpdated(ctx, client, service.ID, previousStatus)
+}
+
+func waitServiceReplicas(ctx context.Context, client serviceUpdater, serviceID string, replicas uint64) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tasks, err := client.TaskList(ctx, types.TaskListOptions{
+				Filters: filters.NewArgs(
+					filters.Arg("service", serviceID),
+					filters.Arg("desired-state", string(swarm.TaskStateRunning)),
+				),
+			})
+			if err != nil {
+				return fmt.Errorf("unable to list tasks of service %q: %w", serviceID, err)
+			}
+
+			var running uint64
+
+			for _, task := range tasks {
+				if task.Status.State == swarm.TaskStateRunning {
+					running++
+				}
+			}
+
+			if running == replicas && uint64(len(tasks)) == replicas {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func waitServiceUpdated(ctx context.Context, client serviceUpdater, serviceID string, previousStatus *swarm.UpdateStatus) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			service, _, err := client.ServiceInspectWithRaw(ctx, serviceID, types.ServiceInspectOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to inspect service %q: %w", serviceID, err)
+			}
+
+			status := service.UpdateStatus
+			if status == nil || sameUpdate(status, previousStatus) {
+				continue
+			}
+
+			switch status.State {
+			case swarm.UpdateStateCompleted:
+				return nil
+			case swarm.UpdateStatePaused, swarm.UpdateStateRollbackPaused, swarm.UpdateStateRollbackCompleted:
+				return fmt.Errorf("%w: %s: %s", ErrServiceUpdateFailed, status.State, status.Message)
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func sameUpdate(status, previous *swarm.UpdateStatus) bool {
+	if previous == nil || status.StartedAt == nil || previous.StartedAt == nil {
+		return false
+	}
+
+	return status.StartedAt.Equal(*previous.StartedAt)
+}
+
+// ErrPortNotPublished is returned when a service doesn't publish a port on the swarm ingress.
+var ErrPortNotPublished = errors.New("port not published")
+
+type serviceInspector interface {
+	ServiceInspectWithRaw(context.Context, string, types.ServiceInspectOptions) (swarm.Service, []byte, error)
+}
+
+// PublishedPort returns the port of the swarm ingress on which a service publishes its targetPort.
+func PublishedPort(ctx context.Context, client serviceInspector, name string, targetPort uint16) (swarm.PortConfig, error) {
+	service, _, err := client.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+	if err != nil {
+		return swarm.PortConfig{}, fmt.Errorf("unable to inspect service %q: %w", name, err)
+	}
+
+	for _, port := range service.Endpoint.Ports {
+		if port.TargetPort == uint32(targetPort) &&
+			port.PublishedPort != 0 &&
+			port.PublishMode == swarm.PortConfigPublishModeIngress {
+			return port, nil
+		}
+	}
+
+	return swarm.PortConfig{}, fmt.Errorf("%w: service %q doesn't publish port %d on the ingress", ErrPortNotPublished, name, targetPort)
+}
+
+type serviceRemover interface {
+	ServiceList(context.Context, types.ServiceListOptions) ([]swarm.Service, error)
+	ServiceRemove(context.Context, string) error
+}
+
+// RemoveServices removes all the services deployed on a swarm cluster.
+func RemoveServices(ctx context.Context, client serviceRemover) error {
+	services, err := client.ServiceList(ctx, types.ServiceListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list services: %w", err)
+	}
+
+	for _, service := range services {
+		if err := client.ServiceRemove(ctx, service.ID); err != nil {
+			return fmt.Errorf("unable to remove service %q: %w", service.Spec.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Service is a service deployed on a swarm cluster.
+type Service struct {
+	ID    string
+	Name  string
+	Mode  string
+	Image string
+	// Stack is the name of the stack the service has been deployed with, empty if it has been created on its own.
+	Stack string
+	// Running is the amount of running tasks, out of Desired.
+	Running uint64
+	Desired uint64
+	// Ports are the ports published on the swarm ingress, as published:target/protocol.
+	Ports []string
+}
+
+// Service modes.
+const (
+	ServiceModeReplicated = "replicated"
+	ServiceModeGlobal     = "global"
+)
+
+type serviceTaskLister interface {
+	ServiceList(context.Context, types.ServiceListOptions) ([]swarm.Service, error)
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+// ListServices returns the services deployed on a swarm cluster, sorted by name, along with their running tasks.
+func ListServices(ctx context.Context, client serviceTaskLister) ([]Service, error) {
+	services, err := client.ServiceList(ctx, types.ServiceListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list services: %w", err)
+	}
+
+	tasks, err := client.TaskList(ctx, types.TaskListOptions{
+		Filters: filters.NewArgs(filters.Arg("desired-state", string(swarm.TaskStateRunning))),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tasks: %w", err)
+	}
+
+	running := make(map[string]uint64)
+	desired := make(map[string]uint64)
+
+	for _, task := range tasks {
+		desired[task.ServiceID]++
+
+		if task.Status.State == swarm.TaskStateRunning {
+			running[task.ServiceID]++
+		}
+	}
+
+	result := make([]Service, len(services))
+
+	for i, service := range services {
+		result[i] = Service{
+			ID:      service.ID,
+			Name:    service.Spec.Name,
+			Mode:    ServiceModeGlobal,
+			Stack:   service.Spec.Labels[StackNamespaceLabel],
+			Running: running[service.ID],
+			Desired: desired[service.ID],
+		}
+
+		if service.Spec.TaskTemplate.ContainerSpec != nil {
+			result[i].Image = service.Spec.TaskTemplate.ContainerSpec.Image
+		}
+
+		if replicated := service.Spec.Mode.Replicated; replicated != nil {
+			result[i].Mode = ServiceModeReplicated
+
+			if replicated.Replicas != nil {
+				result[i].Desired = *replicated.Replicas
+			}
+		}
+
+		for _, port := range service.Endpoint.Ports {
+			if port.PublishedPort != 0 {
+				result[i].Ports = append(result[i].Ports, fmt.Sprintf("%d:%d/%s", port.PublishedPort, port.TargetPort, port.Protocol))
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+
+	return result, nil
+}
+
+// ServiceTask is a task of a service deployed on a swarm cluster.
+type ServiceTask struct {
+	ID      string
+	Service string
+	// Slot is the slot of the task of a replicated service, 0 for a global service.
+	Slot         int
+	Node         string
+	Image        string
+	DesiredState string
+	State        string
+	Err          string
+	UpdatedAt    time.Time
+}
+
+type serviceTaskInspector interface {
+	serviceInspector
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+	NodeList(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+}
+
+// ServiceTasks returns the tasks of a service, sorted by slot then by most recent update.
+func ServiceTasks(ctx context.Context, client serviceTaskInspector, name string) ([]ServiceTask, error) {
+	service, _, err := client.ServiceInspectWithRaw(ctx, name, types.ServiceInspectOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to inspect service %q: %w", name, err)
+	}
+
+	return listTasks(
+		ctx,
+		client,
+		filters.NewArgs(filters.Arg("service", service.ID)),
+		map[string]string{service.ID: service.Spec.Name},
+	)
+}
+
+// listTasks returns the tasks matching given filter, sorted by service, slot then by most recent update.
+// serviceNames maps the IDs of their services to their names.
+func listTasks(ctx context.Context, client serviceTaskInspector, taskFilter filters.Args, serviceNames map[string]string) ([]ServiceTask, error) {
+	tasks, err := client.TaskList(ctx, types.TaskListOptions{Filters: taskFilter})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list tasks: %w", err)
+	}
+
+	nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	hostnames := make(map[string]string, len(nodes))
+
+	for _, node := range nodes {
+		hostnames[node.ID] = node.Description.Hostname
+	}
+
+	result := make([]ServiceTask, len(tasks))
+
+	for i, task := range tasks {
+		result[i] = ServiceTask{
+			ID:           task.ID,
+			Service:      serviceNames[task.ServiceID],
+			Slot:         task.Slot,
+			Node:         hostnames[task.NodeID],
+			DesiredState: string(task.DesiredState),
+			State:        string(task.Status.State),
+			Err:          task.Status.Err,
+			UpdatedAt:    task.UpdatedAt,
+		}
+
+		if task.Spec.ContainerSpec != nil {
+			result[i].Image = task.Spec.ContainerSpec.Image
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Service != result[j].Service {
+			return result[i].Service < result[j].Service
+		}
+
+		if result[i].Slot != result[j].Slot {
+			return result[i].Slot < result[j].Slot
+		}
+
+		return result[i].UpdatedAt.After(result[j].UpdatedAt)
+	})
+
+	return result, nil
+}
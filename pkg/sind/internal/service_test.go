@@ -0,0 +1,311 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type serviceUpdaterMock struct {
+	serviceInspectWithRaw func(context.Context, string, types.ServiceInspectOptions) (swarm.Service, []byte, error)
+	serviceUpdate         func(context.Context, string, swarm.Version, swarm.ServiceSpec, types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error)
+	taskList              func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+func (s *serviceUpdaterMock) ServiceInspectWithRaw(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+	return s.serviceInspectWithRaw(ctx, id, opts)
+}
+
+func (s *serviceUpdaterMock) ServiceUpdate(ctx context.Context, id string, version swarm.Version, spec swarm.ServiceSpec, opts types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+	return s.serviceUpdate(ctx, id, version, spec, opts)
+}
+
+func (s *serviceUpdaterMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return s.taskList(ctx, opts)
+}
+
+func TestScaleService(t *testing.T) {
+	ctx := context.Background()
+	initialReplicas := uint64(1)
+
+	var (
+		sentSpec swarm.ServiceSpec
+		polls    int
+	)
+
+	client := serviceUpdaterMock{
+		serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+			return swarm.Service{
+				ID:   "web-id",
+				Spec: swarm.ServiceSpec{Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &initialReplicas}}},
+			}, nil, nil
+		},
+		serviceUpdate: func(ctx context.Context, id string, version swarm.Version, spec swarm.ServiceSpec, opts types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+			assert.Equal(t, "web-id", id)
+			sentSpec = spec
+			return types.ServiceUpdateResponse{}, nil
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.True(t, opts.Filters.ExactMatch("service", "web-id"))
+			polls++
+
+			tasks := []swarm.Task{
+				{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+				{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+				{Status: swarm.TaskStatus{State: swarm.TaskStatePreparing}},
+			}
+
+			if polls > 1 {
+				tasks[2].Status.State = swarm.TaskStateRunning
+			}
+
+			return tasks, nil
+		},
+	}
+
+	require.NoError(t, ScaleService(ctx, &client, "web", 3))
+	assert.Equal(t, uint64(3), *sentSpec.Mode.Replicated.Replicas)
+	assert.Equal(t, 2, polls)
+}
+
+func TestScaleServiceFailsOnGlobalService(t *testing.T) {
+	ctx := context.Background()
+	client := serviceUpdaterMock{
+		serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+			return swarm.Service{Spec: swarm.ServiceSpec{Mode: swarm.ServiceMode{Global: &swarm.GlobalService{}}}}, nil, nil
+		},
+	}
+
+	assert.Error(t, ScaleService(ctx, &client, "web", 3))
+}
+
+func TestUpdateServiceImage(t *testing.T) {
+	previousStart := time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)
+	newStart := previousStart.Add(time.Hour)
+
+	testCases := []struct {
+		desc          string
+		finalState    swarm.UpdateState
+		expectedError error
+	}{
+		{
+			desc:       "completed",
+			finalState: swarm.UpdateStateCompleted,
+		},
+		{
+			desc:          "rolled back",
+			finalState:    swarm.UpdateStateRollbackCompleted,
+			expectedError: ErrServiceUpdateFailed,
+		},
+		{
+			desc:          "paused",
+			finalState:    swarm.UpdateStatePaused,
+			expectedError: ErrServiceUpdateFailed,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			ctx := context.Background()
+
+			var (
+				sentSpec swarm.ServiceSpec
+				inspects int
+			)
+
+			client := serviceUpdaterMock{
+				serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+					inspects++
+
+					service := swarm.Service{
+						ID: "web-id",
+						Spec: swarm.ServiceSpec{
+							TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "nginx:1"}},
+						},
+						UpdateStatus: &swarm.UpdateStatus{State: swarm.UpdateStateCompleted, StartedAt: &previousStart},
+					}
+
+					// Second inspect still sees the previous update, third one sees the new one.
+					if inspects > 2 {
+						service.UpdateStatus = &swarm.UpdateStatus{State: test.finalState, StartedAt: &newStart}
+					}
+
+					return service, nil, nil
+				},
+				serviceUpdate: func(ctx context.Context, id string, version swarm.Version, spec swarm.ServiceSpec, opts types.ServiceUpdateOptions) (types.ServiceUpdateResponse, error) {
+					sentSpec = spec
+					return types.ServiceUpdateResponse{}, nil
+				},
+			}
+
+			err := UpdateServiceImage(ctx, &client, "web", "nginx:2")
+			assert.Equal(t, "nginx:2", sentSpec.TaskTemplate.ContainerSpec.Image)
+			assert.Equal(t, 3, inspects)
+
+			if test.expectedError != nil {
+				assert.True(t, errors.Is(err, test.expectedError))
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestUpdateServiceImageDoesNothingWithTheSameImage(t *testing.T) {
+	ctx := context.Background()
+	client := serviceUpdaterMock{
+		serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+			return swarm.Service{
+				Spec: swarm.ServiceSpec{
+					TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "nginx:1"}},
+				},
+			}, nil, nil
+		},
+	}
+
+	assert.NoError(t, UpdateServiceImage(ctx, &client, "web", "nginx:1"))
+}
+
+type serviceRemoverMock struct {
+	serviceList   func(context.Context, types.ServiceListOptions) ([]swarm.Service, error)
+	serviceRemove func(context.Context, string) error
+}
+
+func (m *serviceRemoverMock) ServiceList(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+	return m.serviceList(ctx, opts)
+}
+
+func (m *serviceRemoverMock) ServiceRemove(ctx context.Context, id string) error {
+	return m.serviceRemove(ctx, id)
+}
+
+func TestRemoveServices(t *testing.T) {
+	ctx := context.Background()
+
+	var removed []string
+
+	client := serviceRemoverMock{
+		serviceList: func(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+			return []swarm.Service{{ID: "a"}, {ID: "b"}}, nil
+		},
+		serviceRemove: func(ctx context.Context, id string) error {
+			removed = append(removed, id)
+			return nil
+		},
+	}
+
+	require.NoError(t, RemoveServices(ctx, &client))
+	assert.Equal(t, []string{"a", "b"}, removed)
+
+	client.serviceRemove = func(ctx context.Context, id string) error {
+		return errors.New("nope")
+	}
+
+	assert.Error(t, RemoveServices(ctx, &client))
+}
+
+func TestPublishedPort(t *testing.T) {
+	ctx := context.Background()
+	client := serviceUpdaterMock{
+		serviceInspectWithRaw: func(ctx context.Context, id string, opts types.ServiceInspectOptions) (swarm.Service, []byte, error) {
+			return swarm.Service{
+				Endpoint: swarm.Endpoint{
+					Ports: []swarm.PortConfig{
+						{TargetPort: 80, PublishedPort: 8080, PublishMode: swarm.PortConfigPublishModeHost},
+						{TargetPort: 80, PublishedPort: 8081, PublishMode: swarm.PortConfigPublishModeIngress, Protocol: swarm.PortConfigProtocolTCP},
+					},
+				},
+			}, nil, nil
+		},
+	}
+
+	port, err := PublishedPort(ctx, &client, "web", 80)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(8081), port.PublishedPort)
+	assert.Equal(t, swarm.PortConfigProtocolTCP, port.Protocol)
+
+	_, err = PublishedPort(ctx, &client, "web", 443)
+	assert.True(t, errors.Is(err, ErrPortNotPublished))
+}
+
+type serviceTaskListerMock struct {
+	serviceList func(context.Context, types.ServiceListOptions) ([]swarm.Service, error)
+	taskList    func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+func (m serviceTaskListerMock) ServiceList(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+	return m.serviceList(ctx, opts)
+}
+
+func (m serviceTaskListerMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return m.taskList(ctx, opts)
+}
+
+func TestListServices(t *testing.T) {
+	replicas := uint64(3)
+
+	client := serviceTaskListerMock{
+		serviceList: func(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+			return []swarm.Service{
+				{
+					ID: "web",
+					Spec: swarm.ServiceSpec{
+						Annotations:  swarm.Annotations{Name: "shop_web", Labels: map[string]string{StackNamespaceLabel: "shop"}},
+						TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "nginx:alpine"}},
+						Mode:         swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+					},
+					Endpoint: swarm.Endpoint{Ports: []swarm.PortConfig{
+						{Protocol: swarm.PortConfigProtocolTCP, TargetPort: 80, PublishedPort: 8080},
+						{Protocol: swarm.PortConfigProtocolTCP, TargetPort: 443},
+					}},
+				},
+				{
+					ID: "agent",
+					Spec: swarm.ServiceSpec{
+						Annotations:  swarm.Annotations{Name: "agent"},
+						TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "agent:latest"}},
+						Mode:         swarm.ServiceMode{Global: &swarm.GlobalService{}},
+					},
+				},
+			}, nil
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.True(t, opts.Filters.ExactMatch("desired-state", "running"))
+
+			return []swarm.Task{
+				{ServiceID: "web", Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+				{ServiceID: "web", Status: swarm.TaskStatus{State: swarm.TaskStatePending}},
+				{ServiceID: "agent", Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+				{ServiceID: "agent", Status: swarm.TaskStatus{State: swarm.TaskStateRunning}},
+			}, nil
+		},
+	}
+
+	services, err := ListServices(context.Background(), client)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]Service{
+			{ID: "agent", Name: "agent", Mode: ServiceModeGlobal, Image: "agent:latest", Running: 2, Desired: 2},
+			{
+				ID:      "web",
+				Name:    "shop_web",
+				Mode:    ServiceModeReplicated,
+				Image:   "nginx:alpine",
+				Stack:   "shop",
+				Running: 1,
+				Desired: 3,
+				Ports:   []string{"8080:80/tcp"},
+			},
+		},
+		services,
+	)
+}
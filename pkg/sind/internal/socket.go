@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path"
+
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+)
+
+const socketProxyMountPath = "/sind"
+
+// SocketProxyConfig is the configuration of a container publishing the docker API of a node as a unix socket on the host.
+type SocketProxyConfig struct {
+	ClusterName string
+	ImageRef    string
+
+	NetworkID   string
+	NetworkName string
+
+	// SocketDir is the directory of the docker host where the socket is created, as <ClusterName>.sock.
+	SocketDir string
+	// SocketUID owns the socket, which is only accessible to its owner.
+	SocketUID  int
+	TargetHost string
+
+	// Labels are additional labels applied to the container.
+	Labels map[string]string
+}
+
+// CreateSocketProxy runs a container forwarding a unix socket created in SocketDir to the docker API of TargetHost.
+func CreateSocketProxy(ctx context.Context, docker nodeCreator, cfg SocketProxyConfig) (string, error) {
+	socketPath := path.Join(socketProxyMountPath, cfg.ClusterName+".sock")
+
+	return runContainer(
+		ctx,
+		docker,
+		&container.Config{
+			Hostname: fmt.Sprintf("sind-%s-socket-proxy", cfg.ClusterName),
+			Image:    cfg.ImageRef,
+			Labels:   componentLabels(cfg.ClusterName, ComponentSocketProxy, cfg.Labels),
+			Cmd: []string{
+				fmt.Sprintf("UNIX-LISTEN:%s,fork,unlink-early,user=%d,mode=600", socketPath, cfg.SocketUID),
+				fmt.Sprintf("TCP-CONNECT:%s:%d", cfg.TargetHost, dockerDaemonPort),
+			},
+		},
+		&container.HostConfig{
+			Binds: []string{cfg.SocketDir + ":" + socketProxyMountPath},
+		},
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				cfg.NetworkName: {NetworkID: cfg.NetworkID},
+			},
+		},
+	)
+}
@@ -0,0 +1,74 @@
+package internal
+
+import (
+	"context"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateSocketProxy(t *testing.T) {
+	ctx := context.Background()
+	cfg := SocketProxyConfig{
+		ClusterName: "TestCluster",
+		ImageRef:    "socat",
+		NetworkID:   "ababababab",
+		NetworkName: "bar",
+		SocketDir:   "/home/test/.sind",
+		SocketUID:   1000,
+		TargetHost:  "10.0.117.2",
+	}
+
+	var created *fakeContainer
+
+	mock := nodeStarterMock{
+		containerCreate: func(ctx context.Context, cConfig *container.Config, hConfig *container.HostConfig, nConfig *network.NetworkingConfig, cName string) (container.ContainerCreateCreatedBody, error) {
+			created = &fakeContainer{
+				name:    cName,
+				cConfig: cConfig,
+				hConfig: hConfig,
+				nConfig: nConfig,
+			}
+
+			return container.ContainerCreateCreatedBody{ID: cName}, nil
+		},
+		containerStart: func(ctx context.Context, cID string, opts types.ContainerStartOptions) error {
+			return nil
+		},
+	}
+
+	cID, err := CreateSocketProxy(ctx, mock, cfg)
+	require.NoError(t, err)
+
+	assert.Equal(t, "sind-TestCluster-socket-proxy", cID)
+	assert.Equal(
+		t,
+		&container.Config{
+			Hostname: "sind-TestCluster-socket-proxy",
+			Image:    cfg.ImageRef,
+			Labels: map[string]string{
+				"com.sind.cluster.name":      "TestCluster",
+				"com.sind.cluster.component": "socket-proxy",
+			},
+			Cmd: []string{
+				"UNIX-LISTEN:/sind/TestCluster.sock,fork,unlink-early,user=1000,mode=600",
+				"TCP-CONNECT:10.0.117.2:2375",
+			},
+		},
+		created.cConfig,
+	)
+	assert.Equal(t, &container.HostConfig{Binds: []string{"/home/test/.sind:/sind"}}, created.hConfig)
+	assert.Equal(
+		t,
+		&network.NetworkingConfig{
+			EndpointsConfig: map[string]*network.EndpointSettings{
+				"bar": {NetworkID: "ababababab"},
+			},
+		},
+		created.nConfig,
+	)
+}
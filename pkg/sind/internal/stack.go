@@ -0,0 +1,256 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+)
+
+// StackNamespaceLabel is the label applied by docker stack deploy to the resources of a stack.
+const StackNamespaceLabel = "com.docker.stack.namespace"
+
+// Stack is a stack deployed on a swarm cluster.
+type Stack struct {
+	Name     string
+	Services []string
+}
+
+type serviceLister interface {
+	ServiceList(context.Context, types.ServiceListOptions) ([]swarm.Service, error)
+}
+
+// ListStacks returns the stacks deployed on a swarm cluster, sorted by name, from the labels of their services.
+func ListStacks(ctx context.Context, client serviceLister) ([]Stack, error) {
+	services, err := client.ServiceList(ctx, types.ServiceListOptions{
+		Filters: filters.NewArgs(filters.Arg("label", StackNamespaceLabel)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list services: %w", err)
+	}
+
+	byName := make(map[string]*Stack)
+
+	for _, service := range services {
+		name := service.Spec.Labels[StackNamespaceLabel]
+
+		stack, ok := byName[name]
+		if !ok {
+			stack = &Stack{Name: name}
+			byName[name] = stack
+		}
+
+		stack.Services = append(stack.Services, service.Spec.Name)
+	}
+
+	stacks := make([]Stack, 0, len(byName))
+
+	for _, stack := range byName {
+		sort.Strings(stack.Services)
+		stacks = append(stacks, *stack)
+	}
+
+	sort.Slice(stacks, func(i, j int) bool { return stacks[i].Name < stacks[j].Name })
+
+	return stacks, nil
+}
+
+type stackTaskLister interface {
+	serviceTaskInspector
+	serviceLister
+}
+
+// StackTasks returns the tasks of the services of a stack, sorted by service, slot then by most recent update.
+func StackTasks(ctx context.Context, client stackTaskLister, name string) ([]ServiceTask, error) {
+	stackFilter := filters.NewArgs(filters.Arg("label", StackNamespaceLabel+"="+name))
+
+	services, err := client.ServiceList(ctx, types.ServiceListOptions{Filters: stackFilter})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list services of stack %q: %w", name, err)
+	}
+
+	if len(services) == 0 {
+		return nil, nil
+	}
+
+	serviceNames := make(map[string]string, len(services))
+	tasksFilter := filters.NewArgs()
+
+	for _, service := range services {
+		serviceNames[service.ID] = service.Spec.Name
+		tasksFilter.Add("service", service.ID)
+	}
+
+	return listTasks(ctx, client, tasksFilter, serviceNames)
+}
+
+type stackDeployer interface {
+	containerContentCopier
+	executor
+}
+
+// DeployStack deploys a stack from the content of a compose file with the docker CLI of a manager node, which also
+// updates the stack if it is already deployed.
+func DeployStack(ctx context.Context, client stackDeployer, cID, name string, compose []byte) error {
+	var archive bytes.Buffer
+
+	fileName := "sind-stack-" + name + ".yml"
+	tarWriter := tar.NewWriter(&archive)
+
+	err := tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: fileName, Size: int64(len(compose)), Mode: 0600})
+	if err != nil {
+		return fmt.Errorf("unable to write the compose file header: %w", err)
+	}
+
+	if _, err = tarWriter.Write(compose); err != nil {
+		return fmt.Errorf("unable to archive the compose file: %w", err)
+	}
+
+	if err = tarWriter.Close(); err != nil {
+		return fmt.Errorf("unable to archive the compose file: %w", err)
+	}
+
+	if err = client.CopyToContainer(ctx, cID, "/tmp", &archive, types.CopyToContainerOptions{}); err != nil {
+		return fmt.Errorf("unable to copy the compose file to container %q: %w", cID, err)
+	}
+
+	composePath := path.Join("/tmp", fileName)
+
+	deployErr := execContainer(ctx, client, cID, []string{"docker", "stack", "deploy", "--compose-file", composePath, name})
+
+	if err = execContainer(ctx, client, cID, []string{"rm", "-f", composePath}); err != nil && deployErr == nil {
+		return fmt.Errorf("unable to remove the compose file from container %q: %w", cID, err)
+	}
+
+	if deployErr != nil {
+		return fmt.Errorf("unable to deploy stack %q: %w", name, deployErr)
+	}
+
+	return nil
+}
+
+type stackRemover interface {
+	serviceRemover
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+	NetworkList(context.Context, types.NetworkListOptions) ([]types.NetworkResource, error)
+	NetworkRemove(context.Context, string) error
+	SecretList(context.Context, types.SecretListOptions) ([]swarm.Secret, error)
+	SecretRemove(context.Context, string) error
+	ConfigList(context.Context, types.ConfigListOptions) ([]swarm.Config, error)
+	ConfigRemove(context.Context, string) error
+}
+
+// RemoveStack removes the services of a stack, waits for their tasks to be gone, then removes its networks, secrets
+// and configs.
+func RemoveStack(ctx context.Context, client stackRemover, name string) error {
+	stackFilter := filters.NewArgs(filters.Arg("label", StackNamespaceLabel+"="+name))
+
+	services, err := client.ServiceList(ctx, types.ServiceListOptions{Filters: stackFilter})
+	if err != nil {
+		return fmt.Errorf("unable to list services of stack %q: %w", name, err)
+	}
+
+	tasksFilter := filters.NewArgs()
+
+	for _, service := range services {
+		if err = client.ServiceRemove(ctx, service.ID); err != nil {
+			return fmt.Errorf("unable to remove service %q: %w", service.Spec.Name, err)
+		}
+
+		tasksFilter.Add("service", service.ID)
+	}
+
+	if len(services) > 0 {
+		if err = waitTasksRemoved(ctx, client, tasksFilter); err != nil {
+			return fmt.Errorf("unable to wait for the tasks of stack %q to be removed: %w", name, err)
+		}
+	}
+
+	networks, err := client.NetworkList(ctx, types.NetworkListOptions{Filters: stackFilter})
+	if err != nil {
+		return fmt.Errorf("unable to list networks of stack %q: %w", name, err)
+	}
+
+	for _, network := range networks {
+		if err = client.NetworkRemove(ctx, network.ID); err != nil {
+			return fmt.Errorf("unable to remove network %q: %w", network.Name, err)
+		}
+	}
+
+	secrets, err := client.SecretList(ctx, types.SecretListOptions{Filters: stackFilter})
+	if err != nil {
+		return fmt.Errorf("unable to list secrets of stack %q: %w", name, err)
+	}
+
+	for _, secret := range secrets {
+		if err = client.SecretRemove(ctx, secret.ID); err != nil {
+			return fmt.Errorf("unable to remove secret %q: %w", secret.Spec.Name, err)
+		}
+	}
+
+	configs, err := client.ConfigList(ctx, types.ConfigListOptions{Filters: stackFilter})
+	if err != nil {
+		return fmt.Errorf("unable to list configs of stack %q: %w", name, err)
+	}
+
+	for _, config := range configs {
+		if err = client.ConfigRemove(ctx, config.ID); err != nil {
+			return fmt.Errorf("unable to remove config %q: %w", config.Spec.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// RemoveStacks removes all the stacks deployed on a swarm cluster, and returns their names.
+func RemoveStacks(ctx context.Context, client stackRemover) ([]string, error) {
+	stacks, err := ListStacks(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(stacks))
+
+	for _, stack := range stacks {
+		if err = RemoveStack(ctx, client, stack.Name); err != nil {
+			return names, err
+		}
+
+		names = append(names, stack.Name)
+	}
+
+	return names, nil
+}
+
+type taskLister interface {
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+// waitTasksRemoved waits until no task matches given filter, for the networks they are attached to to be removable.
+func waitTasksRemoved(ctx context.Context, client taskLister, taskFilter filters.Args) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tasks, err := client.TaskList(ctx, types.TaskListOptions{Filters: taskFilter})
+			if err != nil {
+				return fmt.Errorf("unable to list tasks: %w", err)
+			}
+
+			if len(tasks) == 0 {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package internal
+
+import (
+	"archive/tar"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stackService(id, name, stack string) swarm.Service {
+	return swarm.Service{
+		ID: id,
+		Spec: swarm.ServiceSpec{
+			Annotations: swarm.Annotations{Name: name, Labels: map[string]string{StackNamespaceLabel: stack}},
+		},
+	}
+}
+
+func TestListStacks(t *testing.T) {
+	ctx := context.Background()
+	client := serviceRemoverMock{
+		serviceList: func(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+			assert.True(t, opts.Filters.ExactMatch("label", StackNamespaceLabel))
+
+			return []swarm.Service{
+				stackService("a", "web_nginx", "web"),
+				stackService("b", "db_postgres", "db"),
+				stackService("c", "web_app", "web"),
+			}, nil
+		},
+	}
+
+	stacks, err := ListStacks(ctx, &client)
+	require.NoError(t, err)
+	assert.Equal(
+		t,
+		[]Stack{
+			{Name: "db", Services: []string{"db_postgres"}},
+			{Name: "web", Services: []string{"web_app", "web_nginx"}},
+		},
+		stacks,
+	)
+
+	client.serviceList = func(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+		return nil, errors.New("nope")
+	}
+
+	_, err = ListStacks(ctx, &client)
+	assert.Error(t, err)
+}
+
+type stackRemoverMock struct {
+	serviceRemoverMock
+
+	taskList      func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+	networkList   func(context.Context, types.NetworkListOptions) ([]types.NetworkResource, error)
+	networkRemove func(context.Context, string) error
+	secretList    func(context.Context, types.SecretListOptions) ([]swarm.Secret, error)
+	secretRemove  func(context.Context, string) error
+	configList    func(context.Context, types.ConfigListOptions) ([]swarm.Config, error)
+	configRemove  func(context.Context, string) error
+}
+
+func (m *stackRemoverMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return m.taskList(ctx, opts)
+}
+
+func (m *stackRemoverMock) NetworkList(ctx context.Context, opts types.NetworkListOptions) ([]types.NetworkResource, error) {
+	return m.networkList(ctx, opts)
+}
+
+func (m *stackRemoverMock) NetworkRemove(ctx context.Context, id string) error {
+	return m.networkRemove(ctx, id)
+}
+
+func (m *stackRemoverMock) SecretList(ctx context.Context, opts types.SecretListOptions) ([]swarm.Secret, error) {
+	return m.secretList(ctx, opts)
+}
+
+func (m *stackRemoverMock) SecretRemove(ctx context.Context, id string) error {
+	return m.secretRemove(ctx, id)
+}
+
+func (m *stackRemoverMock) ConfigList(ctx context.Context, opts types.ConfigListOptions) ([]swarm.Config, error) {
+	return m.configList(ctx, opts)
+}
+
+func (m *stackRemoverMock) ConfigRemove(ctx context.Context, id string) error {
+	return m.configRemove(ctx, id)
+}
+
+func TestRemoveStacks(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		removed    []string
+		taskChecks int
+	)
+
+	remove := func(ctx context.Context, id string) error {
+		removed = append(removed, id)
+		return nil
+	}
+
+	client := stackRemoverMock{
+		serviceRemoverMock: serviceRemoverMock{
+			serviceList: func(ctx context.Context, opts types.ServiceListOptions) ([]swarm.Service, error) {
+				return []swarm.Service{stackService("service", "web_nginx", "web")}, nil
+			},
+			serviceRemove: remove,
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.True(t, opts.Filters.ExactMatch("service", "service"))
+
+			// Tasks are shutting down on the first check.
+			taskChecks++
+			if taskChecks == 1 {
+				return []swarm.Task{{ID: "task"}}, nil
+			}
+
+			return nil, nil
+		},
+		networkList: func(ctx context.Context, opts types.NetworkListOptions) ([]types.NetworkResource, error) {
+			return []types.NetworkResource{{ID: "network"}}, nil
+		},
+		networkRemove: remove,
+		secretList: func(ctx context.Context, opts types.SecretListOptions) ([]swarm.Secret, error) {
+			return []swarm.Secret{{ID: "secret"}}, nil
+		},
+		secretRemove: remove,
+		configList: func(ctx context.Context, opts types.ConfigListOptions) ([]swarm.Config, error) {
+			return []swarm.Config{{ID: "config"}}, nil
+		},
+		configRemove: remove,
+	}
+
+	names, err := RemoveStacks(ctx, &client)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web"}, names)
+	assert.Equal(t, []string{"service", "network", "secret", "config"}, removed)
+	assert.Equal(t, 2, taskChecks)
+
+	client.networkRemove = func(ctx context.Context, id string) error {
+		return errors.New("nope")
+	}
+
+	names, err = RemoveStacks(ctx, &client)
+	assert.Error(t, err)
+	assert.Empty(t, names)
+}
+
+type stackDeployerMock struct {
+	executorMock
+	containerContentCopierMock
+}
+
+func TestDeployStack(t *testing.T) {
+	compose := []byte("version: '3.8'\nservices:\n  web:\n    image: nginx:alpine\n")
+
+	var cmds [][]string
+
+	client := stackDeployerMock{
+		executorMock: executorMock{
+			containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+				assert.Equal(t, "primary", cID)
+				cmds = append(cmds, opts.Cmd)
+
+				return types.IDResponse{ID: "exec"}, nil
+			},
+			containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+				return execOutput("", ""), nil
+			},
+		},
+		containerContentCopierMock: func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+			assert.Equal(t, "primary", cID)
+			assert.Equal(t, "/tmp", path)
+
+			tarReader := tar.NewReader(content)
+
+			header, err := tarReader.Next()
+			require.NoError(t, err)
+			assert.Equal(t, "sind-stack-web.yml", header.Name)
+
+			got, err := ioutil.ReadAll(tarReader)
+			require.NoError(t, err)
+			assert.Equal(t, compose, got)
+
+			return nil
+		},
+	}
+
+	require.NoError(t, DeployStack(context.Background(), &client, "primary", "web", compose))
+	assert.Equal(
+		t,
+		[][]string{
+			{"docker", "stack", "deploy", "--compose-file", "/tmp/sind-stack-web.yml", "web"},
+			{"rm", "-f", "/tmp/sind-stack-web.yml"},
+		},
+		cmds,
+	)
+}
@@ -2,12 +2,17 @@ package internal
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
 	"github.com/golang/sync/errgroup"
 )
 
@@ -16,6 +21,9 @@ const (
 	swarmGossipPort  = 2377
 )
 
+// ErrSwarmNodeDown is returned when a node of the swarm is down while waiting for all the nodes to be ready.
+var ErrSwarmNodeDown = errors.New("swarm node is down")
+
 // SwarmDefaultListenAddress returns the defautl join address for the primary container.
 func SwarmDefaultListenAddress() string {
 	return net.JoinHostPort("0.0.0.0", strconv.Itoa(swarmGossipPort))
@@ -26,7 +34,8 @@ func SwarmPort(container types.Container) (uint16, error) {
 	var swarmPort *types.Port
 
 	for _, port := range container.Ports {
-		if port.PrivatePort != dockerDaemonPort {
+		// Ports are exposed but not published on internal networks.
+		if port.PrivatePort != dockerDaemonPort || port.PublicPort == 0 {
 			continue
 		}
 
@@ -42,6 +51,37 @@ func SwarmPort(container types.Container) (uint16, error) {
 	return swarmPort.PublicPort, nil
 }
 
+// HostPort returns the port of the docker host bound to the port privatePort of one of given containers.
+func HostPort(containers []types.Container, privatePort uint16, protocol string) (uint16, bool) {
+	for _, container := range containers {
+		for _, port := range container.Ports {
+			if port.PrivatePort == privatePort && port.Type == protocol && port.PublicPort != 0 {
+				return port.PublicPort, true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// DirectDaemonAddress returns the address of the docker daemon of given container on its network.
+// It is used when the daemon port can't be published on the docker host, for instance with macvlan networks.
+func DirectDaemonAddress(container types.Container) (string, bool) {
+	if container.NetworkSettings == nil {
+		return "", false
+	}
+
+	for _, endpoint := range container.NetworkSettings.Networks {
+		if endpoint.IPAddress == "" {
+			continue
+		}
+
+		return net.JoinHostPort(endpoint.IPAddress, strconv.Itoa(dockerDaemonPort)), true
+	}
+
+	return "", false
+}
+
 type hoster interface {
 	DaemonHost() string
 }
@@ -53,70 +93,279 @@ func SwarmHost(client hoster) (string, error) {
 		return "", err
 	}
 
-	if daemonURL.Scheme == "unix" {
+	switch daemonURL.Scheme {
+	// Local daemons (unix socket, or named pipe on Windows with Docker Desktop) publish ports on localhost.
+	case "unix", "npipe":
 		return "localhost", nil
+	default:
+		return daemonURL.Host, nil
 	}
-
-	return daemonURL.Host, nil
 }
 
 // ClusterParams are the params for the cluster.
 type ClusterParams struct {
 	IDs NodeIDs
 
-	PrimaryNodeIP    string
+	// ManagerIPs are the addresses of the managers to join, tried in order until one accepts the node.
+	ManagerIPs       []string
 	ManagerJoinToken string
 	WorkerJoinToken  string
+
+	// ManagerAvailability and WorkerAvailability are the availabilities the managers and workers join with, active if
+	// empty.
+	ManagerAvailability string
+	WorkerAvailability  string
+
+	// OnJoined is called concurrently with the ID of each node once it joined the swarm.
+	OnJoined func(cID string)
 }
 
-// FormCluster make managers and workers to join the primary node.
+// ManagerIPs returns the current addresses of the running managers among given nodes, primary first.
+func ManagerIPs(nodes []types.Container) []string {
+	var primaryIPs, managerIPs []string
+
+	for _, node := range nodes {
+		role := node.Labels[NodeRoleLabel]
+		if node.State != "running" || (role != NodeRolePrimary && role != NodeRoleManager) {
+			continue
+		}
+
+		_, endpoint, err := NodeNetwork(node)
+		if err != nil || endpoint.IPAddress == "" {
+			continue
+		}
+
+		if role == NodeRolePrimary {
+			primaryIPs = append(primaryIPs, endpoint.IPAddress)
+			continue
+		}
+
+		managerIPs = append(managerIPs, endpoint.IPAddress)
+	}
+
+	sort.Strings(managerIPs)
+
+	return append(primaryIPs, managerIPs...)
+}
+
+// FormCluster make managers and workers to join the cluster.
 func FormCluster(ctx context.Context, client executor, params ClusterParams) error {
+	if len(params.ManagerIPs) == 0 {
+		return errors.New("no manager to join")
+	}
+
+	managerAddrs := make([]string, len(params.ManagerIPs))
+	for i, managerIP := range params.ManagerIPs {
+		managerAddrs[i] = net.JoinHostPort(managerIP, strconv.Itoa(swarmGossipPort))
+	}
+
 	errg, groupCtx := errgroup.WithContext(ctx)
 
-	managerAddr := net.JoinHostPort(params.PrimaryNodeIP, strconv.Itoa(swarmGossipPort))
+	errg.Go(func() error {
+		return joinNodes(groupCtx, client, params.IDs.Managers, params.ManagerJoinToken, params.ManagerAvailability, managerAddrs, params.OnJoined)
+	})
 
-	for _, managerID := range params.IDs.Managers {
-		cid := managerID
+	errg.Go(func() error {
+		return joinNodes(groupCtx, client, params.IDs.Workers, params.WorkerJoinToken, params.WorkerAvailability, managerAddrs, params.OnJoined)
+	})
+
+	if err := errg.Wait(); err != nil {
+		return fmt.Errorf("unable to form the cluster: %w", err)
+	}
+
+	return nil
+}
+
+// JoinSwarm makes given nodes join a swarm with a token and an availability, active if empty, each of them through the
+// first manager address accepting it.
+func JoinSwarm(ctx context.Context, client executor, cIDs []string, token, availability string, managerAddrs []string) error {
+	return joinNodes(ctx, client, cIDs, token, availability, managerAddrs, nil)
+}
+
+// joinNodes is JoinSwarm calling onJoined, if not nil, with the ID of each node once it joined.
+func joinNodes(ctx context.Context, client executor, cIDs []string, token, availability string, managerAddrs []string, onJoined func(string)) error {
+	errg, groupCtx := errgroup.WithContext(ctx)
+
+	for _, cID := range cIDs {
+		cid := cID
 
 		errg.Go(func() error {
-			return execContainer(
-				groupCtx,
-				client,
-				cid,
-				[]string{
-					"docker",
-					"swarm",
-					"join",
-					"--token",
-					params.ManagerJoinToken,
-					managerAddr,
-				},
-			)
+			if err := joinSwarm(groupCtx, client, cid, token, availability, managerAddrs); err != nil {
+				return err
+			}
+
+			if onJoined != nil {
+				onJoined(cid)
+			}
+
+			return nil
 		})
 	}
 
-	for _, workerID := range params.IDs.Workers {
-		cid := workerID
+	return errg.Wait()
+}
+
+// joinSwarm makes a node join the swarm through the first manager accepting it.
+func joinSwarm(ctx context.Context, client executor, cID, token, availability string, managerAddrs []string) error {
+	cmd := []string{"docker", "swarm", "join", "--token", token}
+	if availability != "" {
+		cmd = append(cmd, "--availability", availability)
+	}
 
-		errg.Go(func() error {
-			return execContainer(
-				groupCtx,
-				client,
-				cid,
-				[]string{
-					"docker",
-					"swarm",
-					"join",
-					"--token",
-					params.WorkerJoinToken,
-					managerAddr,
-				},
+	var err error
+
+	for _, managerAddr := range managerAddrs {
+		err = execContainer(ctx, client, cID, append(cmd, managerAddr))
+
+		var execErr *ExecError
+		if err == nil || !errors.As(err, &execErr) {
+			return err
+		}
+	}
+
+	return err
+}
+
+type nodeLister interface {
+	NodeList(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+}
+
+// WaitSwarmNodesReady waits until the swarm has count ready nodes, and fails as soon as one of them is down instead of
+// waiting for the context to be done.
+func WaitSwarmNodesReady(ctx context.Context, client nodeLister, count int) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+			if err != nil {
+				return fmt.Errorf("unable to list the swarm nodes: %w", err)
+			}
+
+			var (
+				ready int
+				down  []string
 			)
-		})
+
+			for _, node := range nodes {
+				switch node.Status.State {
+				case swarm.NodeStateReady:
+					ready++
+				case swarm.NodeStateDown, swarm.NodeStateDisconnected:
+					down = append(down, fmt.Sprintf("%s is %s (%s)", node.Description.Hostname, node.Status.State, node.Status.Message))
+				}
+			}
+
+			if len(down) > 0 {
+				return fmt.Errorf("%w: %s", ErrSwarmNodeDown, strings.Join(down, ", "))
+			}
+
+			if ready == count {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
+}
 
-	if err := errg.Wait(); err != nil {
-		return fmt.Errorf("unable to form the cluster: %v", err)
+// WaitSwarmConverged waits until all the nodes of the swarm are ready, once restarted. Nodes are down until they
+// reconnect to the managers, which don't answer until they elect a leader: both are waited for until the context is
+// done, the nodes which are not ready yet being reported then.
+func WaitSwarmConverged(ctx context.Context, client nodeLister) error {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	var pending []string
+
+	for {
+		nodes, err := client.NodeList(ctx, types.NodeListOptions{})
+		if err == nil {
+			pending = pending[:0]
+
+			for _, node := range nodes {
+				if node.Status.State != swarm.NodeStateReady {
+					pending = append(pending, fmt.Sprintf("%s is %s", node.Description.Hostname, node.Status.State))
+				}
+			}
+
+			if len(pending) == 0 {
+				return nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			if err != nil {
+				return fmt.Errorf("unable to list the swarm nodes: %w", err)
+			}
+
+			return fmt.Errorf("swarm did not converge: %s: %w", strings.Join(pending, ", "), ctx.Err())
+		}
+	}
+}
+
+// LeaveSwarm makes given nodes leave their swarm cluster, workers first.
+func LeaveSwarm(ctx context.Context, client executor, nodes []types.Container) error {
+	var managers, workers []types.Container
+
+	for _, node := range nodes {
+		if node.Labels[NodeRoleLabel] == NodeRoleWorker {
+			workers = append(workers, node)
+			continue
+		}
+
+		managers = append(managers, node)
+	}
+
+	cmd := []string{"docker", "swarm", "leave", "--force"}
+
+	if err := ExecContainers(ctx, client, workers, 0, cmd); err != nil {
+		return fmt.Errorf("unable to make workers leave the swarm: %w", err)
+	}
+
+	if err := ExecContainers(ctx, client, managers, 0, cmd); err != nil {
+		return fmt.Errorf("unable to make managers leave the swarm: %w", err)
+	}
+
+	return nil
+}
+
+type swarmUpdater interface {
+	SwarmInspect(context.Context) (swarm.Swarm, error)
+	SwarmUpdate(context.Context, swarm.Version, swarm.Spec, swarm.UpdateFlags) error
+}
+
+// swarmUpdateAttempts is the maximum amount of attempts of a swarm update, concurrent updates making it fail.
+const swarmUpdateAttempts = 5
+
+// UpdateSwarmSpec applies update to the spec of the swarm, at its current version. The update is applied again to the
+// new spec if the swarm has been updated concurrently.
+func UpdateSwarmSpec(ctx context.Context, client swarmUpdater, update func(*swarm.Spec)) error {
+	var err error
+
+	for attempt := 0; attempt < swarmUpdateAttempts; attempt++ {
+		var swarmInfo swarm.Swarm
+
+		swarmInfo, err = client.SwarmInspect(ctx)
+		if err != nil {
+			return fmt.Errorf("unable to inspect the swarm: %w", err)
+		}
+
+		spec := swarmInfo.Spec
+		update(&spec)
+
+		err = client.SwarmUpdate(ctx, swarmInfo.Version, spec, swarm.UpdateFlags{})
+		if err == nil || !strings.Contains(err.Error(), "update out of sequence") {
+			break
+		}
+	}
+
+	if err != nil {
+		return fmt.Errorf("unable to update the swarm: %w", err)
 	}
 
 	return nil
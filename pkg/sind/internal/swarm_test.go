@@ -5,9 +5,12 @@ import (
 	"errors"
 	"sort"
 	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/docker/api/types/swarm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -30,6 +33,15 @@ func TestSwarmPort(t *testing.T) {
 		},
 		expectedError: errors.New("container does not export port 2375"),
 	},
+		{
+			desc: "exposing docker daemon port without publishing it",
+			container: types.Container{
+				Ports: []types.Port{
+					{PrivatePort: dockerDaemonPort},
+				},
+			},
+			expectedError: errors.New("container does not export port 2375"),
+		},
 		{
 			desc: "exposing docker daemon port",
 			container: types.Container{
@@ -59,6 +71,21 @@ func (h hosterMock) DaemonHost() string {
 	return h()
 }
 
+func TestHostPort(t *testing.T) {
+	containers := []types.Container{
+		{Ports: []types.Port{{PrivatePort: 2375, PublicPort: 32768, Type: "tcp"}}},
+		{Ports: []types.Port{{PrivatePort: 8080, Type: "tcp"}, {PrivatePort: 8080, PublicPort: 80, Type: "udp"}}},
+		{Ports: []types.Port{{PrivatePort: 8080, PublicPort: 8081, Type: "tcp"}}},
+	}
+
+	port, ok := HostPort(containers, 8080, "tcp")
+	assert.True(t, ok)
+	assert.Equal(t, uint16(8081), port)
+
+	_, ok = HostPort(containers, 9000, "tcp")
+	assert.False(t, ok)
+}
+
 func TestSwarmHost(t *testing.T) {
 	testCases := []struct {
 		desc         string
@@ -71,6 +98,11 @@ func TestSwarmHost(t *testing.T) {
 			daemonHost:   "unix:///foo/bar",
 			expectedHost: "localhost",
 		},
+		{
+			desc:         "with a npipe host",
+			daemonHost:   "npipe:////./pipe/docker_engine",
+			expectedHost: "localhost",
+		},
 		{
 			desc:         "with a non unix host",
 			daemonHost:   "tcp://foobarbuz",
@@ -101,7 +133,7 @@ func TestFormCluster(t *testing.T) {
 			Workers:  []string{"d", "e", "f"},
 		},
 
-		PrimaryNodeIP:    "10.0.0.1",
+		ManagerIPs:       []string{"10.0.0.1"},
 		ManagerJoinToken: "zz",
 		WorkerJoinToken:  "hh",
 	}
@@ -123,16 +155,28 @@ func TestFormCluster(t *testing.T) {
 				ID: cID,
 			}, nil
 		},
-		containerExecStart: func(ctx context.Context, eID string, opts types.ExecStartCheck) error {
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
 			execStarted <- eID
-			return nil
+			return execOutput("", ""), nil
 		},
 	}
 
+	joined := make(chan string, len(params.IDs.Managers)+len(params.IDs.Workers))
+	params.OnJoined = func(cID string) { joined <- cID }
+
 	require.NoError(t, FormCluster(ctx, &client, params))
 
 	close(execCreated)
 	close(execStarted)
+	close(joined)
+
+	var joinedIDs []string
+	for cID := range joined {
+		joinedIDs = append(joinedIDs, cID)
+	}
+
+	sort.Strings(joinedIDs)
+	assert.Equal(t, []string{"b", "c", "d", "e", "f"}, joinedIDs)
 
 	var (
 		createdExecs     []execCreation
@@ -171,7 +215,7 @@ func TestFormCluster(t *testing.T) {
 					"join",
 					"--token",
 					params.ManagerJoinToken,
-					params.PrimaryNodeIP + ":" + strconv.Itoa(swarmGossipPort),
+					params.ManagerIPs[0] + ":" + strconv.Itoa(swarmGossipPort),
 				},
 				e.Cmd,
 			)
@@ -185,7 +229,7 @@ func TestFormCluster(t *testing.T) {
 					"join",
 					"--token",
 					params.WorkerJoinToken,
-					params.PrimaryNodeIP + ":" + strconv.Itoa(swarmGossipPort),
+					params.ManagerIPs[0] + ":" + strconv.Itoa(swarmGossipPort),
 				},
 				e.Cmd,
 			)
@@ -195,3 +239,337 @@ func TestFormCluster(t *testing.T) {
 	// Assert that all the created execs are executed.
 	assert.Equal(t, cIDs, startedExecs)
 }
+
+func TestDirectDaemonAddress(t *testing.T) {
+	testCases := []struct {
+		desc            string
+		container       types.Container
+		expectedAddress string
+		expectedFound   bool
+	}{
+		{
+			desc:      "without network settings",
+			container: types.Container{},
+		},
+		{
+			desc: "without IP address",
+			container: types.Container{
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"foo": {}},
+				},
+			},
+		},
+		{
+			desc: "with an IP address",
+			container: types.Container{
+				NetworkSettings: &types.SummaryNetworkSettings{
+					Networks: map[string]*network.EndpointSettings{"foo": {IPAddress: "192.168.1.2"}},
+				},
+			},
+			expectedAddress: "192.168.1.2:2375",
+			expectedFound:   true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			address, found := DirectDaemonAddress(test.container)
+			assert.Equal(t, test.expectedAddress, address)
+			assert.Equal(t, test.expectedFound, found)
+		})
+	}
+}
+
+func TestLeaveSwarm(t *testing.T) {
+	ctx := context.Background()
+	nodes := []types.Container{
+		{ID: "a", Labels: map[string]string{NodeRoleLabel: NodeRolePrimary}},
+		{ID: "b", Labels: map[string]string{NodeRoleLabel: NodeRoleWorker}},
+		{ID: "c", Labels: map[string]string{NodeRoleLabel: NodeRoleManager}},
+		{ID: "d", Labels: map[string]string{NodeRoleLabel: NodeRoleWorker}},
+	}
+
+	execCreated := make(chan string, len(nodes))
+
+	client := executorMock{
+		containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+			assert.Equal(t, []string{"docker", "swarm", "leave", "--force"}, opts.Cmd)
+			execCreated <- cID
+			return types.IDResponse{ID: cID}, nil
+		},
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+			return execOutput("", ""), nil
+		},
+	}
+
+	require.NoError(t, LeaveSwarm(ctx, &client, nodes))
+	close(execCreated)
+
+	var order []string
+	for cID := range execCreated {
+		order = append(order, cID)
+	}
+
+	require.Len(t, order, 4)
+	assert.ElementsMatch(t, []string{"b", "d"}, order[:2])
+	assert.ElementsMatch(t, []string{"a", "c"}, order[2:])
+}
+
+func TestFormClusterFallsBackToOtherManagers(t *testing.T) {
+	ctx := context.Background()
+	params := ClusterParams{
+		IDs:             NodeIDs{Primary: "a", Workers: []string{"d"}},
+		ManagerIPs:      []string{"10.0.0.1", "10.0.0.2"},
+		WorkerJoinToken: "hh",
+	}
+
+	var joined []string
+
+	client := executorMock{
+		containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+			joined = append(joined, opts.Cmd[len(opts.Cmd)-1])
+			return types.IDResponse{ID: opts.Cmd[len(opts.Cmd)-1]}, nil
+		},
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+			return execOutput("", ""), nil
+		},
+		containerExecInspect: func(ctx context.Context, eID string) (types.ContainerExecInspect, error) {
+			// The first manager is unreachable.
+			if eID == "10.0.0.1:2377" {
+				return types.ContainerExecInspect{ExitCode: 1}, nil
+			}
+
+			return types.ContainerExecInspect{}, nil
+		},
+	}
+
+	require.NoError(t, FormCluster(ctx, &client, params))
+	assert.Equal(t, []string{"10.0.0.1:2377", "10.0.0.2:2377"}, joined)
+
+	assert.Error(t, FormCluster(ctx, &client, ClusterParams{IDs: params.IDs}))
+}
+
+func TestFormClusterWithAvailability(t *testing.T) {
+	ctx := context.Background()
+	params := ClusterParams{
+		IDs:                NodeIDs{Primary: "a", Managers: []string{"b"}, Workers: []string{"d"}},
+		ManagerIPs:         []string{"10.0.0.1"},
+		ManagerJoinToken:   "zz",
+		WorkerJoinToken:    "hh",
+		WorkerAvailability: "drain",
+	}
+
+	var (
+		mu    sync.Mutex
+		joins = make(map[string][]string)
+	)
+
+	client := executorMock{
+		containerExecCreate: func(ctx context.Context, cID string, opts types.ExecConfig) (types.IDResponse, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			joins[cID] = opts.Cmd
+			return types.IDResponse{ID: cID}, nil
+		},
+		containerExecAttach: func(ctx context.Context, eID string, opts types.ExecStartCheck) (types.HijackedResponse, error) {
+			return execOutput("", ""), nil
+		},
+	}
+
+	require.NoError(t, FormCluster(ctx, &client, params))
+
+	assert.Equal(t, []string{"docker", "swarm", "join", "--token", "zz", "10.0.0.1:2377"}, joins["b"])
+	assert.Equal(t, []string{"docker", "swarm", "join", "--token", "hh", "--availability", "drain", "10.0.0.1:2377"}, joins["d"])
+}
+
+func TestManagerIPs(t *testing.T) {
+	node := func(role, state, ip string) types.Container {
+		return types.Container{
+			State:  state,
+			Labels: map[string]string{NodeRoleLabel: role},
+			NetworkSettings: &types.SummaryNetworkSettings{
+				Networks: map[string]*network.EndpointSettings{"net": {IPAddress: ip}},
+			},
+		}
+	}
+
+	nodes := []types.Container{
+		node(NodeRoleWorker, "running", "10.0.0.5"),
+		node(NodeRoleManager, "running", "10.0.0.4"),
+		node(NodeRoleManager, "exited", "10.0.0.6"),
+		node(NodeRolePrimary, "running", "10.0.0.2"),
+		node(NodeRoleManager, "running", "10.0.0.3"),
+	}
+
+	assert.Equal(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4"}, ManagerIPs(nodes))
+}
+
+type nodeListerMock func(context.Context, types.NodeListOptions) ([]swarm.Node, error)
+
+func (n nodeListerMock) NodeList(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+	return n(ctx, opts)
+}
+
+func TestWaitSwarmNodesReady(t *testing.T) {
+	node := func(hostname string, state swarm.NodeState, message string) swarm.Node {
+		return swarm.Node{
+			Description: swarm.NodeDescription{Hostname: hostname},
+			Status:      swarm.NodeStatus{State: state, Message: message},
+		}
+	}
+
+	testCases := []struct {
+		desc          string
+		lists         [][]swarm.Node
+		expectedError error
+	}{
+		{
+			desc: "nodes becoming ready",
+			lists: [][]swarm.Node{
+				{node("sind-test-manager-0", swarm.NodeStateReady, ""), node("sind-test-worker-0", swarm.NodeStateUnknown, "")},
+				{node("sind-test-manager-0", swarm.NodeStateReady, ""), node("sind-test-worker-0", swarm.NodeStateReady, "")},
+			},
+		},
+		{
+			desc: "node down",
+			lists: [][]swarm.Node{
+				{node("sind-test-manager-0", swarm.NodeStateReady, ""), node("sind-test-worker-0", swarm.NodeStateDown, "heartbeat failure")},
+			},
+			expectedError: ErrSwarmNodeDown,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			var calls int
+
+			client := nodeListerMock(func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+				nodes := test.lists[calls]
+				calls++
+
+				return nodes, nil
+			})
+
+			err := WaitSwarmNodesReady(context.Background(), client, 2)
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				assert.Equal(t, len(test.lists), calls)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.Contains(t, err.Error(), "sind-test-worker-0 is down (heartbeat failure)")
+		})
+	}
+}
+
+func TestWaitSwarmConverged(t *testing.T) {
+	node := func(hostname string, state swarm.NodeState) swarm.Node {
+		return swarm.Node{
+			Description: swarm.NodeDescription{Hostname: hostname},
+			Status:      swarm.NodeStatus{State: state},
+		}
+	}
+
+	t.Run("swarm converging", func(t *testing.T) {
+		var calls int
+
+		client := nodeListerMock(func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			calls++
+
+			switch calls {
+			case 1:
+				return nil, errors.New("rpc error: code = Unknown desc = The swarm does not have a leader")
+			case 2:
+				return []swarm.Node{node("sind-test-manager-0", swarm.NodeStateReady), node("sind-test-worker-0", swarm.NodeStateDown)}, nil
+			default:
+				return []swarm.Node{node("sind-test-manager-0", swarm.NodeStateReady), node("sind-test-worker-0", swarm.NodeStateReady)}, nil
+			}
+		})
+
+		require.NoError(t, WaitSwarmConverged(context.Background(), client))
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("node never ready", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*servicePollInterval)
+		defer cancel()
+
+		client := nodeListerMock(func(ctx context.Context, opts types.NodeListOptions) ([]swarm.Node, error) {
+			return []swarm.Node{node("sind-test-manager-0", swarm.NodeStateReady), node("sind-test-worker-0", swarm.NodeStateDown)}, nil
+		})
+
+		err := WaitSwarmConverged(ctx, client)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+		assert.Contains(t, err.Error(), "sind-test-worker-0 is down")
+	})
+}
+
+type swarmUpdaterMock struct {
+	swarmInspect func(context.Context) (swarm.Swarm, error)
+	swarmUpdate  func(context.Context, swarm.Version, swarm.Spec, swarm.UpdateFlags) error
+}
+
+func (m swarmUpdaterMock) SwarmInspect(ctx context.Context) (swarm.Swarm, error) {
+	return m.swarmInspect(ctx)
+}
+
+func (m swarmUpdaterMock) SwarmUpdate(ctx context.Context, version swarm.Version, spec swarm.Spec, flags swarm.UpdateFlags) error {
+	return m.swarmUpdate(ctx, version, spec, flags)
+}
+
+func TestUpdateSwarmSpec(t *testing.T) {
+	var (
+		inspections int
+		versions    []uint64
+	)
+
+	client := swarmUpdaterMock{
+		swarmInspect: func(context.Context) (swarm.Swarm, error) {
+			inspections++
+
+			var info swarm.Swarm
+			info.Version = swarm.Version{Index: uint64(inspections)}
+			info.Spec.Annotations.Name = "default"
+
+			return info, nil
+		},
+		swarmUpdate: func(ctx context.Context, version swarm.Version, spec swarm.Spec, flags swarm.UpdateFlags) error {
+			versions = append(versions, version.Index)
+
+			assert.Equal(t, "default", spec.Name)
+			assert.True(t, spec.EncryptionConfig.AutoLockManagers)
+
+			if version.Index == 1 {
+				return errors.New("rpc error: code = Unknown desc = update out of sequence")
+			}
+
+			return nil
+		},
+	}
+
+	err := UpdateSwarmSpec(context.Background(), client, func(spec *swarm.Spec) {
+		spec.EncryptionConfig.AutoLockManagers = true
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{1, 2}, versions)
+}
+
+func TestUpdateSwarmSpecFailure(t *testing.T) {
+	var updates int
+
+	client := swarmUpdaterMock{
+		swarmInspect: func(context.Context) (swarm.Swarm, error) {
+			return swarm.Swarm{}, nil
+		},
+		swarmUpdate: func(context.Context, swarm.Version, swarm.Spec, swarm.UpdateFlags) error {
+			updates++
+			return errors.New("boom")
+		},
+	}
+
+	err := UpdateSwarmSpec(context.Background(), client, func(*swarm.Spec) {})
+	require.Error(t, err)
+	assert.Equal(t, 1, updates)
+}
@@ -0,0 +1,118 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/stdcopy"
+)
+
+// TaskResult is the outcome of a one-off task.
+type TaskResult struct {
+	State    swarm.TaskState
+	ExitCode int
+	Err      string
+
+	Stdout []byte
+	Stderr []byte
+}
+
+type taskRunner interface {
+	ServiceCreate(context.Context, swarm.ServiceSpec, types.ServiceCreateOptions) (types.ServiceCreateResponse, error)
+	ServiceRemove(context.Context, string) error
+	ServiceLogs(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	TaskList(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+// RunTask runs a single replica, never restarted service, waits for its task to terminate, collects its output
+// then removes the service.
+func RunTask(ctx context.Context, client taskRunner, spec swarm.ServiceSpec) (*TaskResult, error) {
+	replicas := uint64(1)
+
+	spec.Mode = swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}}
+	spec.TaskTemplate.RestartPolicy = &swarm.RestartPolicy{Condition: swarm.RestartPolicyConditionNone}
+
+	service, err := client.ServiceCreate(ctx, spec, types.ServiceCreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the task service: %w", err)
+	}
+
+	defer client.ServiceRemove(ctx, service.ID)
+
+	task, err := waitTaskTerminated(ctx, client, service.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := TaskResult{
+		State: task.Status.State,
+		Err:   task.Status.Err,
+	}
+
+	if task.Status.ContainerStatus != nil {
+		result.ExitCode = task.Status.ContainerStatus.ExitCode
+	}
+
+	logs, err := client.ServiceLogs(ctx, service.ID, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the task logs: %w", err)
+	}
+
+	defer logs.Close()
+
+	var stdout, stderr bytes.Buffer
+
+	if _, err = stdcopy.StdCopy(&stdout, &stderr, logs); err != nil {
+		return nil, fmt.Errorf("unable to read the task logs: %w", err)
+	}
+
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+
+	return &result, nil
+}
+
+func waitTaskTerminated(ctx context.Context, client taskRunner, serviceID string) (*swarm.Task, error) {
+	ticker := time.NewTicker(servicePollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			tasks, err := client.TaskList(ctx, types.TaskListOptions{
+				Filters: filters.NewArgs(filters.Arg("service", serviceID)),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("unable to list the task of service %q: %w", serviceID, err)
+			}
+
+			for _, task := range tasks {
+				if taskTerminated(task.Status.State) {
+					return &task, nil
+				}
+			}
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func taskTerminated(state swarm.TaskState) bool {
+	switch state {
+	case swarm.TaskStateComplete,
+		swarm.TaskStateFailed,
+		swarm.TaskStateRejected,
+		swarm.TaskStateShutdown,
+		swarm.TaskStateOrphaned,
+		swarm.TaskStateRemove:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type taskRunnerMock struct {
+	serviceCreate func(context.Context, swarm.ServiceSpec, types.ServiceCreateOptions) (types.ServiceCreateResponse, error)
+	serviceRemove func(context.Context, string) error
+	serviceLogs   func(context.Context, string, types.ContainerLogsOptions) (io.ReadCloser, error)
+	taskList      func(context.Context, types.TaskListOptions) ([]swarm.Task, error)
+}
+
+func (m *taskRunnerMock) ServiceCreate(ctx context.Context, spec swarm.ServiceSpec, opts types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+	return m.serviceCreate(ctx, spec, opts)
+}
+
+func (m *taskRunnerMock) ServiceRemove(ctx context.Context, id string) error {
+	return m.serviceRemove(ctx, id)
+}
+
+func (m *taskRunnerMock) ServiceLogs(ctx context.Context, id string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+	return m.serviceLogs(ctx, id, opts)
+}
+
+func (m *taskRunnerMock) TaskList(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+	return m.taskList(ctx, opts)
+}
+
+func TestRunTask(t *testing.T) {
+	ctx := context.Background()
+
+	var logs bytes.Buffer
+
+	_, err := stdcopy.NewStdWriter(&logs, stdcopy.Stdout).Write([]byte("hello\n"))
+	require.NoError(t, err)
+	_, err = stdcopy.NewStdWriter(&logs, stdcopy.Stderr).Write([]byte("oops\n"))
+	require.NoError(t, err)
+
+	var (
+		sentSpec  swarm.ServiceSpec
+		removedID string
+		polls     int
+	)
+
+	client := taskRunnerMock{
+		serviceCreate: func(ctx context.Context, spec swarm.ServiceSpec, opts types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+			sentSpec = spec
+			return types.ServiceCreateResponse{ID: "task-id"}, nil
+		},
+		serviceRemove: func(ctx context.Context, id string) error {
+			removedID = id
+			return nil
+		},
+		serviceLogs: func(ctx context.Context, id string, opts types.ContainerLogsOptions) (io.ReadCloser, error) {
+			assert.Equal(t, "task-id", id)
+			return ioutil.NopCloser(&logs), nil
+		},
+		taskList: func(ctx context.Context, opts types.TaskListOptions) ([]swarm.Task, error) {
+			assert.True(t, opts.Filters.ExactMatch("service", "task-id"))
+			polls++
+
+			if polls == 1 {
+				return []swarm.Task{{Status: swarm.TaskStatus{State: swarm.TaskStateRunning}}}, nil
+			}
+
+			return []swarm.Task{
+				{
+					Status: swarm.TaskStatus{
+						State:           swarm.TaskStateFailed,
+						Err:             "task: non-zero exit (3)",
+						ContainerStatus: &swarm.ContainerStatus{ExitCode: 3},
+					},
+				},
+			}, nil
+		},
+	}
+
+	spec := swarm.ServiceSpec{
+		TaskTemplate: swarm.TaskSpec{ContainerSpec: &swarm.ContainerSpec{Image: "alpine", Command: []string{"false"}}},
+	}
+
+	res, err := RunTask(ctx, &client, spec)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		&TaskResult{
+			State:    swarm.TaskStateFailed,
+			ExitCode: 3,
+			Err:      "task: non-zero exit (3)",
+			Stdout:   []byte("hello\n"),
+			Stderr:   []byte("oops\n"),
+		},
+		res,
+	)
+	assert.Equal(t, uint64(1), *sentSpec.Mode.Replicated.Replicas)
+	assert.Equal(t, swarm.RestartPolicyConditionNone, sentSpec.TaskTemplate.RestartPolicy.Condition)
+	assert.Equal(t, "task-id", removedID)
+}
+
+func TestRunTaskFailsOnCreateError(t *testing.T) {
+	ctx := context.Background()
+	client := taskRunnerMock{
+		serviceCreate: func(ctx context.Context, spec swarm.ServiceSpec, opts types.ServiceCreateOptions) (types.ServiceCreateResponse, error) {
+			return types.ServiceCreateResponse{}, errors.New("nope")
+		},
+	}
+
+	_, err := RunTask(ctx, &client, swarm.ServiceSpec{})
+	assert.Error(t, err)
+}
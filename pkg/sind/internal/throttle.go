@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// minThrottleBurst is the minimum burst (bytes) of the token bucket filter, for full size packets to get through.
+	minThrottleBurst = 32 * 1024
+	// throttleLatency is the maximum time a packet waits for tokens before being dropped.
+	throttleLatency = "50ms"
+)
+
+// ThrottleContainers caps the outbound bandwidth of the cluster network interface of given containers to rate bits per
+// second, with a token bucket filter. It requires the tc command of iproute2 in the containers.
+func ThrottleContainers(ctx context.Context, client executor, containers []types.Container, rate uint64) error {
+	return ExecContainers(ctx, client, containers, 0, throttleCmd(rate))
+}
+
+// UnthrottleContainers removes the bandwidth caps of the cluster network interface of given containers, if any.
+func UnthrottleContainers(ctx context.Context, client executor, containers []types.Container) error {
+	return ExecContainers(
+		ctx,
+		client,
+		containers,
+		0,
+		// Deleting the root qdisc fails when the interface has the default one.
+		[]string{"sh", "-c", "tc qdisc del dev eth0 root 2>/dev/null || true"},
+	)
+}
+
+// throttleCmd returns the command replacing the root qdisc of the cluster network interface by a token bucket filter
+// capping its bandwidth to rate bits per second, with a burst of 10ms of traffic.
+func throttleCmd(rate uint64) []string {
+	burst := rate / 8 / 100
+	if burst < minThrottleBurst {
+		burst = minThrottleBurst
+	}
+
+	return []string{
+		"tc", "qdisc", "replace", "dev", "eth0", "root", "tbf",
+		"rate", fmt.Sprintf("%dbit", rate),
+		"burst", fmt.Sprintf("%d", burst),
+		"latency", throttleLatency,
+	}
+}
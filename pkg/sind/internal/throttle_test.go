@@ -0,0 +1,20 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottleCmd(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"tc", "qdisc", "replace", "dev", "eth0", "root", "tbf", "rate", "1000000000bit", "burst", "1250000", "latency", "50ms"},
+		throttleCmd(1000000000),
+	)
+	assert.Equal(
+		t,
+		[]string{"tc", "qdisc", "replace", "dev", "eth0", "root", "tbf", "rate", "1000000bit", "burst", "32768", "latency", "50ms"},
+		throttleCmd(1000000),
+	)
+}
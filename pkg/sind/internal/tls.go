@@ -0,0 +1,368 @@
+package internal
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// TLSDir is the directory the TLS material of a node is written to.
+	TLSDir = "/etc/sind/tls"
+
+	caCertFile     = "ca.pem"
+	caKeyFile      = "ca-key.pem"
+	serverCertFile = "server-cert.pem"
+	serverKeyFile  = "server-key.pem"
+	clientCertFile = "cert.pem"
+	clientKeyFile  = "key.pem"
+
+	// certValidity is how long the certificates of a cluster are valid, clusters are not expected to outlive it.
+	certValidity = 10 * 365 * 24 * time.Hour
+)
+
+// errNoCAKey is returned when issuing a certificate with material which CA key has not been kept.
+var errNoCAKey = errors.New("the TLS material has no CA key")
+
+// TLSMaterial is the PEM encoded material securing the daemon port of the nodes with mutual TLS: a CA, the server
+// certificate of the node daemons and the client certificate they accept, both signed by the CA. The CA key and the
+// server material are only kept in the nodes, they are not serialized.
+type TLSMaterial struct {
+	CACert     string `json:"ca_cert"`
+	CAKey      string `json:"-"`
+	ServerCert string `json:"-"`
+	ServerKey  string `json:"-"`
+	ClientCert string `json:"client_cert"`
+	ClientKey  string `json:"client_key"`
+}
+
+// TLSServerName returns the name the server certificate of the nodes of a cluster is always valid for, whatever the
+// address their daemon is reached at.
+func TLSServerName(clusterName string) string {
+	return "sind-" + clusterName
+}
+
+// GenerateTLS generates the TLS material of a cluster, its server certificate being valid for TLSServerName and given
+// hosts, DNS names or IP addresses. The CA key is kept to issue the certificates of the nodes added later, see Reissue.
+func GenerateTLS(clusterName string, hosts []string) (*TLSMaterial, error) {
+	var result TLSMaterial
+
+	notBefore := time.Now().Add(-time.Hour)
+
+	caKey, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	caTemplate, err := certTemplate("sind-"+clusterName+"-ca", notBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	caTemplate.IsCA = true
+	caTemplate.BasicConstraintsValid = true
+	caTemplate.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+
+	caCert, caCertPEM, err := signCert(caTemplate, caTemplate, caKey, caKey)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create the CA certificate: %w", err)
+	}
+
+	result.CACert = caCertPEM
+
+	if result.CAKey, err = encodeKey(caKey); err != nil {
+		return nil, err
+	}
+
+	if result.ServerCert, result.ServerKey, err = issueServerCert(clusterName, hosts, caCert, caKey); err != nil {
+		return nil, err
+	}
+
+	clientKey, err := newKey()
+	if err != nil {
+		return nil, err
+	}
+
+	clientTemplate, err := certTemplate("sind-"+clusterName+"-client", notBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	clientTemplate.KeyUsage = x509.KeyUsageDigitalSignature
+	clientTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+
+	if _, result.ClientCert, err = signCert(clientTemplate, caCert, clientKey, caKey); err != nil {
+		return nil, fmt.Errorf("unable to create the client certificate: %w", err)
+	}
+
+	if result.ClientKey, err = encodeKey(clientKey); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Reissue returns the material of nodes added to a cluster, with a new server certificate signed by the cluster CA and
+// valid for TLSServerName and given hosts. The CA key is not part of the result.
+func (m *TLSMaterial) Reissue(clusterName string, hosts []string) (*TLSMaterial, error) {
+	if m.CAKey == "" {
+		return nil, errNoCAKey
+	}
+
+	ca, err := tls.X509KeyPair([]byte(m.CACert), []byte(m.CAKey))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the CA: %w", err)
+	}
+
+	caCert, err := x509.ParseCertificate(ca.Certificate[0])
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the CA certificate: %w", err)
+	}
+
+	caKey, ok := ca.PrivateKey.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("unexpected CA key type")
+	}
+
+	result := m.withoutCAKey()
+
+	if result.ServerCert, result.ServerKey, err = issueServerCert(clusterName, hosts, caCert, caKey); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ClientConfig returns the configuration of a client of daemons secured with the material. Their certificate is
+// verified against serverName rather than the address they are reached at.
+func (m *TLSMaterial) ClientConfig(serverName string) (*tls.Config, error) {
+	certificate, err := tls.X509KeyPair([]byte(m.ClientCert), []byte(m.ClientKey))
+	if err != nil {
+		return nil, fmt.Errorf("unable to load the client certificate: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM([]byte(m.CACert)) {
+		return nil, errors.New("unable to load the CA certificate")
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      roots,
+		ServerName:   serverName,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// withoutCAKey returns a copy of the material without the CA key, which only the primary node keeps.
+func (m *TLSMaterial) withoutCAKey() *TLSMaterial {
+	result := *m
+	result.CAKey = ""
+
+	return &result
+}
+
+// files returns the files of the material in TLSDir, by name.
+func (m *TLSMaterial) files() map[string]*string {
+	return map[string]*string{
+		caCertFile:     &m.CACert,
+		caKeyFile:      &m.CAKey,
+		serverCertFile: &m.ServerCert,
+		serverKeyFile:  &m.ServerKey,
+		clientCertFile: &m.ClientCert,
+		clientKeyFile:  &m.ClientKey,
+	}
+}
+
+// WriteTLS copies the material to TLSDir in a container, which can be created but not started yet. The material is
+// copied rather than passed through the container environment, for the keys not to be listed by its inspection.
+func WriteTLS(ctx context.Context, client containerContentCopier, cID string, m *TLSMaterial) error {
+	var files []containerFile
+
+	for name, content := range m.files() {
+		// Certificates are public, keys are only readable by root.
+		mode := int64(0o644)
+		if strings.HasSuffix(name, "key.pem") {
+			mode = 0o600
+		}
+
+		files = append(files, containerFile{Name: name, Content: *content, Mode: mode})
+	}
+
+	if err := writeFiles(ctx, client, cID, TLSDir, files); err != nil {
+		return fmt.Errorf("unable to copy the TLS material to container %q: %w", cID, err)
+	}
+
+	return nil
+}
+
+// ReadTLS returns the material written to a container by WriteTLS.
+func ReadTLS(ctx context.Context, client containerContentReader, cID string) (*TLSMaterial, error) {
+	var content io.ReadCloser
+
+	err := retry(ctx, func() error {
+		var err error
+
+		content, _, err = client.CopyFromContainer(ctx, cID, TLSDir)
+
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to copy the TLS material from container %q: %w", cID, err)
+	}
+
+	defer content.Close()
+
+	material, err := readTLSArchive(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read the TLS material of container %q: %w", cID, err)
+	}
+
+	return material, nil
+}
+
+// readTLSArchive returns the material of a tar archive of TLSDir.
+func readTLSArchive(src io.Reader) (*TLSMaterial, error) {
+	var result TLSMaterial
+
+	files := result.files()
+	tarReader := tar.NewReader(src)
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		file, ok := files[path.Base(header.Name)]
+		if !ok || header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		data, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, err
+		}
+
+		*file = string(data)
+	}
+
+	if result.CACert == "" || result.ClientCert == "" || result.ClientKey == "" {
+		return nil, errors.New("incomplete TLS material")
+	}
+
+	return &result, nil
+}
+
+// tlsDaemonArgs make a node daemon only accept clients presenting a certificate signed by the cluster CA.
+func tlsDaemonArgs() []string {
+	return []string{
+		"--tlsverify",
+		"--tlscacert=" + path.Join(TLSDir, caCertFile),
+		"--tlscert=" + path.Join(TLSDir, serverCertFile),
+		"--tlskey=" + path.Join(TLSDir, serverKeyFile),
+	}
+}
+
+// issueServerCert returns the PEM encoded certificate and key of the node daemons, signed by the cluster CA.
+func issueServerCert(clusterName string, hosts []string, caCert *x509.Certificate, caKey *ecdsa.PrivateKey) (string, string, error) {
+	serverKey, err := newKey()
+	if err != nil {
+		return "", "", err
+	}
+
+	serverTemplate, err := certTemplate(TLSServerName(clusterName), time.Now().Add(-time.Hour))
+	if err != nil {
+		return "", "", err
+	}
+
+	serverTemplate.KeyUsage = x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment
+	serverTemplate.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	serverTemplate.DNSNames = []string{TLSServerName(clusterName)}
+
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			serverTemplate.IPAddresses = append(serverTemplate.IPAddresses, ip)
+			continue
+		}
+
+		serverTemplate.DNSNames = append(serverTemplate.DNSNames, host)
+	}
+
+	_, certPEM, err := signCert(serverTemplate, caCert, serverKey, caKey)
+	if err != nil {
+		return "", "", fmt.Errorf("unable to create the server certificate: %w", err)
+	}
+
+	keyPEM, err := encodeKey(serverKey)
+	if err != nil {
+		return "", "", err
+	}
+
+	return certPEM, keyPEM, nil
+}
+
+func newKey() (*ecdsa.PrivateKey, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate a private key: %w", err)
+	}
+
+	return key, nil
+}
+
+func encodeKey(key *ecdsa.PrivateKey) (string, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode a private key: %w", err)
+	}
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+}
+
+func certTemplate(commonName string, notBefore time.Time) (*x509.Certificate, error) {
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("unable to generate a certificate serial number: %w", err)
+	}
+
+	return &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"sind"}},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(certValidity),
+	}, nil
+}
+
+func signCert(template, parent *x509.Certificate, key, signer *ecdsa.PrivateKey) (*x509.Certificate, string, error) {
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, signer)
+	if err != nil {
+		return nil, "", err
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return cert, string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})), nil
+}
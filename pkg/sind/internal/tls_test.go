@@ -0,0 +1,233 @@
+package internal
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateTLS(t *testing.T) {
+	material, err := GenerateTLS("test", []string{"localhost", "127.0.0.1", "docker.example.com"})
+	require.NoError(t, err)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(material.CACert)))
+
+	serverCert := parseCert(t, material.ServerCert)
+
+	for _, host := range []string{"sind-test", "localhost", "127.0.0.1", "docker.example.com"} {
+		_, err = serverCert.Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		assert.NoError(t, err, host)
+	}
+
+	_, err = serverCert.Verify(x509.VerifyOptions{DNSName: "sind-other", Roots: roots})
+	assert.Error(t, err)
+
+	clientCert := parseCert(t, material.ClientCert)
+
+	_, err = clientCert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.NoError(t, err)
+
+	_, err = clientCert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	})
+	assert.Error(t, err)
+
+	_, err = tls.X509KeyPair([]byte(material.ServerCert), []byte(material.ServerKey))
+	assert.NoError(t, err)
+
+	// Each cluster gets its own CA.
+	other, err := GenerateTLS("test", nil)
+	require.NoError(t, err)
+
+	_, err = parseCert(t, other.ClientCert).Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	})
+	assert.Error(t, err)
+}
+
+func TestTLSMaterialClientConfig(t *testing.T) {
+	material, err := GenerateTLS("test", []string{"127.0.0.1"})
+	require.NoError(t, err)
+
+	serverCert, err := tls.X509KeyPair([]byte(material.ServerCert), []byte(material.ServerKey))
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	require.True(t, clientCAs.AppendCertsFromPEM([]byte(material.CACert)))
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	require.NoError(t, err)
+
+	defer listener.Close()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+
+			_ = conn.(*tls.Conn).Handshake()
+			_ = conn.Close()
+		}
+	}()
+
+	clientConfig, err := material.ClientConfig(TLSServerName("test"))
+	require.NoError(t, err)
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", listener.Addr().String(), clientConfig)
+	require.NoError(t, err)
+	assert.NoError(t, conn.Close())
+
+	clientConfig, err = material.ClientConfig(TLSServerName("other"))
+	require.NoError(t, err)
+
+	_, err = tls.DialWithDialer(&net.Dialer{Timeout: 5 * time.Second}, "tcp", listener.Addr().String(), clientConfig)
+	assert.Error(t, err)
+
+	_, err = (&TLSMaterial{CACert: "invalid"}).ClientConfig(TLSServerName("test"))
+	assert.Error(t, err)
+}
+
+func TestTLSMaterialReissue(t *testing.T) {
+	material, err := GenerateTLS("test", []string{"127.0.0.1"})
+	require.NoError(t, err)
+
+	reissued, err := material.Reissue("test", []string{"docker.example.com"})
+	require.NoError(t, err)
+
+	assert.Empty(t, reissued.CAKey)
+	assert.Equal(t, material.CACert, reissued.CACert)
+	assert.Equal(t, material.ClientCert, reissued.ClientCert)
+	assert.Equal(t, material.ClientKey, reissued.ClientKey)
+	assert.NotEqual(t, material.ServerKey, reissued.ServerKey)
+
+	roots := x509.NewCertPool()
+	require.True(t, roots.AppendCertsFromPEM([]byte(material.CACert)))
+
+	for _, host := range []string{"sind-test", "docker.example.com"} {
+		_, err = parseCert(t, reissued.ServerCert).Verify(x509.VerifyOptions{
+			DNSName:   host,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		})
+		assert.NoError(t, err, host)
+	}
+
+	_, err = reissued.Reissue("test", nil)
+	assert.True(t, errors.Is(err, errNoCAKey))
+}
+
+func TestWriteTLS(t *testing.T) {
+	material := TLSMaterial{
+		CACert:     "ca",
+		CAKey:      "ca-key",
+		ServerCert: "server-cert",
+		ServerKey:  "server-key",
+		ClientCert: "client-cert",
+		ClientKey:  "client-key",
+	}
+
+	var archive bytes.Buffer
+
+	client := containerContentCopierMock(func(ctx context.Context, cID, path string, content io.Reader, opts types.CopyToContainerOptions) error {
+		assert.Equal(t, "node", cID)
+		assert.Equal(t, "/", path)
+
+		_, err := io.Copy(&archive, content)
+		return err
+	})
+
+	require.NoError(t, WriteTLS(context.Background(), client, "node", &material))
+
+	modes := make(map[string]int64)
+	tarReader := tar.NewReader(bytes.NewReader(archive.Bytes()))
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		modes[header.Name] = header.Mode
+	}
+
+	assert.Equal(
+		t,
+		map[string]int64{
+			"etc/sind/tls/":                0o700,
+			"etc/sind/tls/ca.pem":          0o644,
+			"etc/sind/tls/ca-key.pem":      0o600,
+			"etc/sind/tls/server-cert.pem": 0o644,
+			"etc/sind/tls/server-key.pem":  0o600,
+			"etc/sind/tls/cert.pem":        0o644,
+			"etc/sind/tls/key.pem":         0o600,
+		},
+		modes,
+	)
+
+	read, err := readTLSArchive(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, &material, read)
+
+	// Empty files are not written.
+	archive.Reset()
+	require.NoError(t, WriteTLS(context.Background(), client, "node", material.withoutCAKey()))
+
+	read, err = readTLSArchive(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, material.withoutCAKey(), read)
+}
+
+func TestReadTLSArchiveIncomplete(t *testing.T) {
+	var archive bytes.Buffer
+
+	tarWriter := tar.NewWriter(&archive)
+	require.NoError(t, tarWriter.WriteHeader(&tar.Header{Typeflag: tar.TypeReg, Name: "tls/ca.pem", Mode: 0o644, Size: 2}))
+	_, err := tarWriter.Write([]byte("ca"))
+	require.NoError(t, err)
+	require.NoError(t, tarWriter.Close())
+
+	_, err = readTLSArchive(&archive)
+	assert.Error(t, err)
+}
+
+func parseCert(t *testing.T, certPEM string) *x509.Certificate {
+	t.Helper()
+
+	block, _ := pem.Decode([]byte(certPEM))
+	require.NotNil(t, block)
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	return cert
+}
@@ -0,0 +1,43 @@
+package internal
+
+import "context"
+
+const (
+	// ClusterAttribute is the span attribute holding the name of the cluster an operation runs on.
+	ClusterAttribute = "sind.cluster"
+	// NodeAttribute is the span attribute holding the name of the node an operation runs on.
+	NodeAttribute = "sind.node"
+	// ContainerAttribute is the span attribute holding the ID of the container an operation runs on.
+	ContainerAttribute = "sind.container"
+)
+
+// Tracer starts the spans of the operations it is given to.
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span)
+}
+
+// Span is a traced operation, ended once with the error it failed with, nil if it succeeded.
+type Span interface {
+	End(err error)
+}
+
+type tracerKey struct{}
+
+// WithTracer returns a context carrying given tracer, starting the spans of the operations using it.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return context.WithValue(ctx, tracerKey{}, tracer)
+}
+
+// StartSpan starts a span with the tracer of the context, a no-op span is returned when it carries none.
+func StartSpan(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	tracer, ok := ctx.Value(tracerKey{}).(Tracer)
+	if !ok {
+		return ctx, noopSpan{}
+	}
+
+	return tracer.Start(ctx, name, attributes)
+}
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
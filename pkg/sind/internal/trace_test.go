@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type recordedSpan struct {
+	name       string
+	attributes map[string]string
+	err        error
+	ended      bool
+}
+
+type tracerMock struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+func (t *tracerMock) Start(ctx context.Context, name string, attributes map[string]string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := &recordedSpan{name: name, attributes: attributes}
+	t.spans = append(t.spans, span)
+
+	return ctx, &spanMock{tracer: t, span: span}
+}
+
+type spanMock struct {
+	tracer *tracerMock
+	span   *recordedSpan
+}
+
+func (s *spanMock) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+
+	s.span.err = err
+	s.span.ended = true
+}
+
+func TestStartSpan(t *testing.T) {
+	t.Run("without tracer", func(t *testing.T) {
+		ctx := context.Background()
+
+		spanCtx, span := StartSpan(ctx, "sind.test", nil)
+
+		assert.Equal(t, ctx, spanCtx)
+		assert.Equal(t, noopSpan{}, span)
+		span.End(errors.New("nope"))
+	})
+
+	t.Run("with tracer", func(t *testing.T) {
+		tracer := &tracerMock{}
+		ctx := WithTracer(context.Background(), tracer)
+
+		_, span := StartSpan(ctx, "sind.test", map[string]string{ClusterAttribute: "test"})
+		span.End(nil)
+
+		require.Len(t, tracer.spans, 1)
+		assert.Equal(t, &recordedSpan{name: "sind.test", attributes: map[string]string{ClusterAttribute: "test"}, ended: true}, tracer.spans[0])
+	})
+}
+
+func TestRemoveContainersTracesEachContainer(t *testing.T) {
+	tracer := &tracerMock{}
+	ctx := WithTracer(context.Background(), tracer)
+	removeErr := errors.New("nope")
+
+	mock := containerRemoverMock(func(ctx context.Context, cID string, opts types.ContainerRemoveOptions) error {
+		if cID == "bbbbb" {
+			return removeErr
+		}
+
+		return nil
+	})
+
+	err := RemoveContainers(ctx, mock, []types.Container{{ID: "aaaaa"}, {ID: "bbbbb"}}, 0, true)
+	require.Error(t, err)
+
+	spans := make(map[string]*recordedSpan)
+	for _, span := range tracer.spans {
+		assert.Equal(t, "sind.container.remove", span.name)
+		assert.True(t, span.ended)
+		spans[span.attributes[ContainerAttribute]] = span
+	}
+
+	require.Len(t, spans, 2)
+	assert.NoError(t, spans["aaaaa"].err)
+	assert.Equal(t, removeErr, spans["bbbbb"].err)
+}
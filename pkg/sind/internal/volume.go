@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+)
+
+const (
+	// nodeDataRoot is the data root of the node daemons, declared as a volume by the dind images.
+	nodeDataRoot = "/var/lib/docker"
+
+	nodeVolumeSuffix = "-data"
+
+	// nodeStartedMarker is created in the nodes on their first start, see swarmStateReset.
+	nodeStartedMarker = "/etc/sind/.started"
+)
+
+// NodeVolume returns the name of the volume holding the daemon state of the node with given hostname. It is labeled
+// with the cluster, and reattached by a node with the same hostname when a cluster deleted with its volumes kept is
+// created again.
+func NodeVolume(hostname string) string {
+	return hostname + nodeVolumeSuffix
+}
+
+// nodeVolumeMount returns the mount of the volume holding the daemon state of a node.
+func nodeVolumeMount(cfg NodesConfig, hostname string) mount.Mount {
+	labels := make(map[string]string, len(cfg.Labels)+1)
+
+	for k, v := range cfg.Labels {
+		labels[k] = v
+	}
+
+	labels[ClusterNameLabel] = cfg.ClusterName
+
+	return mount.Mount{
+		Type:          mount.TypeVolume,
+		Source:        NodeVolume(hostname),
+		Target:        nodeDataRoot,
+		VolumeOptions: &mount.VolumeOptions{Labels: labels},
+	}
+}
+
+// nodeMounts returns the mounts of a node copied from the ones of another node, the volume holding its daemon state
+// being named after its hostname.
+func nodeMounts(mounts []mount.Mount, hostname string) []mount.Mount {
+	if mounts == nil {
+		return nil
+	}
+
+	result := make([]mount.Mount, len(mounts))
+
+	for i, m := range mounts {
+		if m.Type == mount.TypeVolume && m.Target == nodeDataRoot {
+			m.Source = NodeVolume(hostname)
+		}
+
+		result[i] = m
+	}
+
+	return result
+}
+
+// NodeVolumes returns the names of the named volumes holding the daemon state of given nodes.
+func NodeVolumes(nodes []types.Container) []string {
+	var names []string
+
+	for _, node := range nodes {
+		if name := dataVolume(node.Mounts); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// dataVolume returns the name of the volume holding the daemon state of a node from its mounts, empty if it is an
+// anonymous volume, removed with the node.
+func dataVolume(mounts []types.MountPoint) string {
+	for _, m := range mounts {
+		if m.Type == mount.TypeVolume && m.Destination == nodeDataRoot && strings.HasSuffix(m.Name, nodeVolumeSuffix) {
+			return m.Name
+		}
+	}
+
+	return ""
+}
+
+// swarmStateReset drops the swarm state of a reattached volume on the first start of a node, the node joining the swarm
+// of the new cluster. The state is kept when the node is restarted.
+func swarmStateReset() string {
+	return fmt.Sprintf(
+		`{ [ -f %[1]s ] || { rm -rf %[2]s/swarm && mkdir -p %[3]s && touch %[1]s; }; }`,
+		nodeStartedMarker,
+		nodeDataRoot,
+		path.Dir(nodeStartedMarker),
+	)
+}
+
+type volumeLister interface {
+	VolumeList(context.Context, filters.Args) (volume.VolumeListOKBody, error)
+}
+
+// ListVolumes returns the names of the volumes of a cluster, sorted.
+func ListVolumes(ctx context.Context, client volumeLister, clusterName string) ([]string, error) {
+	volumes, err := client.VolumeList(ctx, filters.NewArgs(filters.Arg("label", ClusterLabel(clusterName))))
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(volumes.Volumes))
+
+	for _, vol := range volumes.Volumes {
+		names = append(names, vol.Name)
+	}
+
+	sort.Strings(names)
+
+	return names, nil
+}
+
+type volumeRemover interface {
+	VolumeRemove(context.Context, string, bool) error
+}
+
+// VolumesError reports the volumes an operation failed on, along with their error.
+type VolumesError struct {
+	Operation string
+	Errors    map[string]error
+}
+
+func (e *VolumesError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	failures := make([]string, 0, len(names))
+	for _, name := range names {
+		failures = append(failures, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+
+	return fmt.Sprintf("failed to %s %d volume(s): %s", e.Operation, len(names), strings.Join(failures, ", "))
+}
+
+// RemoveVolumes removes all given volumes. All the volumes are processed, failures are reported by a *VolumesError.
+func RemoveVolumes(ctx context.Context, client volumeRemover, names []string) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures = make(map[string]error)
+	)
+
+	for _, name := range names {
+		wg.Add(1)
+
+		go func(name string) {
+			defer wg.Done()
+
+			if err := client.VolumeRemove(ctx, name, true); err != nil {
+				mu.Lock()
+				failures[name] = err
+				mu.Unlock()
+			}
+		}(name)
+	}
+
+	wg.Wait()
+
+	if len(failures) > 0 {
+		return &VolumesError{Operation: "remove", Errors: failures}
+	}
+
+	return nil
+}
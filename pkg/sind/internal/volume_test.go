@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/api/types/volume"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNodeVolumeMount(t *testing.T) {
+	cfg := NodesConfig{ClusterName: "test", Labels: map[string]string{NamespaceLabel: "ci"}}
+
+	assert.Equal(
+		t,
+		mount.Mount{
+			Type:          mount.TypeVolume,
+			Source:        "sind-test-worker-0-data",
+			Target:        "/var/lib/docker",
+			VolumeOptions: &mount.VolumeOptions{Labels: map[string]string{NamespaceLabel: "ci", ClusterNameLabel: "test"}},
+		},
+		nodeVolumeMount(cfg, "sind-test-worker-0"),
+	)
+	assert.NotContains(t, cfg.Labels, ClusterNameLabel)
+}
+
+func TestNodeMounts(t *testing.T) {
+	mounts := []mount.Mount{
+		{Type: mount.TypeBind, Source: "/tmp", Target: "/tmp"},
+		{Type: mount.TypeVolume, Source: "sind-test-worker-0-data", Target: "/var/lib/docker"},
+	}
+
+	assert.Equal(
+		t,
+		[]mount.Mount{
+			{Type: mount.TypeBind, Source: "/tmp", Target: "/tmp"},
+			{Type: mount.TypeVolume, Source: "sind-test-worker-3-data", Target: "/var/lib/docker"},
+		},
+		nodeMounts(mounts, "sind-test-worker-3"),
+	)
+	assert.Equal(t, "sind-test-worker-0-data", mounts[1].Source)
+	assert.Nil(t, nodeMounts(nil, "sind-test-worker-3"))
+}
+
+func TestNodeVolumes(t *testing.T) {
+	nodes := []types.Container{
+		{Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "sind-test-manager-0-data", Destination: "/var/lib/docker"}}},
+		// Nodes created before their daemon state was held by a named volume.
+		{Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "3f2a9c", Destination: "/var/lib/docker"}}},
+		{Mounts: []types.MountPoint{{Type: mount.TypeVolume, Name: "sind-test-worker-0-data", Destination: "/var/lib/docker"}}},
+		{Mounts: []types.MountPoint{{Type: mount.TypeBind, Source: "/tmp", Destination: "/tmp"}}},
+	}
+
+	assert.Equal(t, []string{"sind-test-manager-0-data", "sind-test-worker-0-data"}, NodeVolumes(nodes))
+}
+
+type volumeListerMock func(context.Context, filters.Args) (volume.VolumeListOKBody, error)
+
+func (m volumeListerMock) VolumeList(ctx context.Context, args filters.Args) (volume.VolumeListOKBody, error) {
+	return m(ctx, args)
+}
+
+func TestListVolumes(t *testing.T) {
+	client := volumeListerMock(func(ctx context.Context, args filters.Args) (volume.VolumeListOKBody, error) {
+		assert.True(t, args.ExactMatch("label", ClusterLabel("test")))
+
+		return volume.VolumeListOKBody{
+			Volumes: []*types.Volume{{Name: "sind-test-worker-0-data"}, {Name: "sind-test-manager-0-data"}},
+		}, nil
+	})
+
+	names, err := ListVolumes(context.Background(), client, "test")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sind-test-manager-0-data", "sind-test-worker-0-data"}, names)
+
+	client = volumeListerMock(func(ctx context.Context, args filters.Args) (volume.VolumeListOKBody, error) {
+		return volume.VolumeListOKBody{}, errors.New("boom")
+	})
+
+	_, err = ListVolumes(context.Background(), client, "test")
+	assert.Error(t, err)
+}
+
+type volumeRemoverMock func(context.Context, string, bool) error
+
+func (m volumeRemoverMock) VolumeRemove(ctx context.Context, name string, force bool) error {
+	return m(ctx, name, force)
+}
+
+func TestRemoveVolumes(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		removed []string
+	)
+
+	client := volumeRemoverMock(func(ctx context.Context, name string, force bool) error {
+		assert.True(t, force)
+
+		if name == "b" {
+			return errors.New("volume is in use")
+		}
+
+		mu.Lock()
+		removed = append(removed, name)
+		mu.Unlock()
+
+		return nil
+	})
+
+	err := RemoveVolumes(context.Background(), client, []string{"a", "b", "c"})
+
+	var volumesErr *VolumesError
+	require.True(t, errors.As(err, &volumesErr))
+	assert.Equal(t, []string{"b"}, failedIDs(volumesErr.Errors))
+	assert.Equal(t, "failed to remove 1 volume(s): b: volume is in use", err.Error())
+
+	sort.Strings(removed)
+	assert.Equal(t, []string{"a", "c"}, removed)
+
+	assert.NoError(t, RemoveVolumes(context.Background(), client, nil))
+}
@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+)
+
+const (
+	kernelReleaseFile = "/proc/sys/kernel/osrelease"
+	// WSL2 kernels are released as X.Y.Z-microsoft-standard(-WSL2), WSL1 ones as X.Y.Z-NNNNN-Microsoft.
+	wsl2KernelMarker = "microsoft-standard"
+	wslInterfaceName = "eth0"
+)
+
+// IsWSL2 returns true if the current process runs inside a WSL2 distribution.
+func IsWSL2() bool {
+	release, err := ioutil.ReadFile(kernelReleaseFile)
+	if err != nil {
+		return false
+	}
+
+	return isWSL2Release(string(release))
+}
+
+func isWSL2Release(release string) bool {
+	return strings.Contains(strings.ToLower(release), wsl2KernelMarker)
+}
+
+// WSLAddress returns the address of the WSL2 VM, reachable from the Windows host.
+func WSLAddress() (string, error) {
+	iface, err := net.InterfaceByName(wslInterfaceName)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the %s interface: %w", wslInterfaceName, err)
+	}
+
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the %s addresses: %w", wslInterfaceName, err)
+	}
+
+	return firstIPv4(addrs)
+}
+
+func firstIPv4(addrs []net.Addr) (string, error) {
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok {
+			continue
+		}
+
+		if ip := ipNet.IP.To4(); ip != nil && !ip.IsLoopback() {
+			return ip.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("no IPv4 address found")
+}
@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsWSL2Release(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		release  string
+		expected bool
+	}{
+		{desc: "WSL2 kernel", release: "5.10.16.3-microsoft-standard-WSL2\n", expected: true},
+		{desc: "older WSL2 kernel", release: "4.19.104-microsoft-standard\n", expected: true},
+		{desc: "WSL1 kernel", release: "4.4.0-19041-Microsoft\n"},
+		{desc: "linux kernel", release: "5.15.0-91-generic\n"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, isWSL2Release(test.release))
+		})
+	}
+}
+
+func TestFirstIPv4(t *testing.T) {
+	addr, err := firstIPv4([]net.Addr{
+		&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)},
+		&net.IPNet{IP: net.ParseIP("172.20.1.2"), Mask: net.CIDRMask(20, 32)},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "172.20.1.2", addr)
+
+	_, err = firstIPv4([]net.Addr{&net.IPNet{IP: net.ParseIP("fe80::1"), Mask: net.CIDRMask(64, 128)}})
+	assert.EqualError(t, err, "no IPv4 address found")
+}
@@ -0,0 +1,136 @@
+package sind
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/docker/docker/pkg/stdcopy"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// JobSpec describes a swarm job, a service which tasks run to completion instead of being restarted. Jobs require nodes
+// running docker 20.10 or later.
+type JobSpec = internal.JobSpec
+
+// JobStatus counts the tasks of a job by state.
+type JobStatus = internal.JobStatus
+
+// JobResult is the outcome of a job.
+type JobResult struct {
+	JobStatus
+
+	// Stdout and Stderr are the outputs of all the tasks of the job.
+	Stdout []byte
+	Stderr []byte
+}
+
+func validateJob(spec JobSpec) error {
+	if spec.Image == "" {
+		return ErrEmptyJobImage
+	}
+
+	if spec.Global && (spec.Completions > 0 || spec.MaxConcurrent > 0) {
+		return ErrInvalidGlobalJob
+	}
+
+	return nil
+}
+
+// CreateJob creates a job on the cluster and returns the ID of its service, without waiting for its completion.
+func CreateJob(ctx context.Context, hostClient *docker.Client, clusterName string, spec JobSpec) (string, error) {
+	if err := validateJob(spec); err != nil {
+		return "", err
+	}
+
+	primary, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	return internal.CreateJob(ctx, hostClient, primary.ID, spec)
+}
+
+// WaitJob waits until the job with given service ID or name is over, when all the tasks of a replicated job completed
+// or one of them failed, or when the tasks of a global job are all terminated. The tasks failing don't make it fail,
+// they are counted in the returned status.
+func WaitJob(ctx context.Context, hostClient *docker.Client, clusterName, job string) (*JobStatus, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	status, err := internal.WaitJob(ctx, swarmClient, job)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// JobLogs writes the outputs of all the tasks of the job with given service ID or name to stdout and stderr.
+func JobLogs(ctx context.Context, hostClient *docker.Client, clusterName, job string, stdout, stderr io.Writer) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	logs, err := swarmClient.ServiceLogs(ctx, job, types.ContainerLogsOptions{ShowStdout: true, ShowStderr: true})
+	if err != nil {
+		return fmt.Errorf("unable to get the logs of job %q: %w", job, err)
+	}
+
+	defer logs.Close()
+
+	if _, err = stdcopy.StdCopy(stdout, stderr, logs); err != nil {
+		return fmt.Errorf("unable to read the logs of job %q: %w", job, err)
+	}
+
+	return nil
+}
+
+// RunJob runs a job on the cluster, waits for it to be over, then returns the status and the outputs of its tasks.
+// The job service is removed once the job is over.
+func RunJob(ctx context.Context, hostClient *docker.Client, clusterName string, spec JobSpec) (*JobResult, error) {
+	ctx, span := internal.StartSpan(ctx, "sind.job", map[string]string{internal.ClusterAttribute: clusterName})
+
+	result, err := runJob(ctx, hostClient, clusterName, spec)
+
+	span.End(err)
+
+	return result, err
+}
+
+func runJob(ctx context.Context, hostClient *docker.Client, clusterName string, spec JobSpec) (*JobResult, error) {
+	job, err := CreateJob(ctx, hostClient, clusterName, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() { _ = RemoveService(ctx, hostClient, clusterName, job) }()
+
+	status, err := WaitJob(ctx, hostClient, clusterName, job)
+	if err != nil {
+		return nil, err
+	}
+
+	result := JobResult{JobStatus: *status}
+
+	var stdout, stderr bytes.Buffer
+
+	if err = JobLogs(ctx, hostClient, clusterName, job, &stdout, &stderr); err != nil {
+		return nil, err
+	}
+
+	result.Stdout = stdout.Bytes()
+	result.Stderr = stderr.Bytes()
+
+	return &result, nil
+}
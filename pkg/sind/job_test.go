@@ -0,0 +1,47 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateJob(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		spec          JobSpec
+		expectedError error
+	}{
+		{
+			desc: "replicated job",
+			spec: JobSpec{Image: "alpine", Completions: 3, MaxConcurrent: 1},
+		},
+		{
+			desc: "global job",
+			spec: JobSpec{Image: "alpine", Global: true},
+		},
+		{
+			desc:          "without image",
+			spec:          JobSpec{},
+			expectedError: ErrEmptyJobImage,
+		},
+		{
+			desc:          "global job with completions",
+			spec:          JobSpec{Image: "alpine", Global: true, Completions: 3},
+			expectedError: ErrInvalidGlobalJob,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := validateJob(test.spec)
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+		})
+	}
+}
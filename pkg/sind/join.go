@@ -0,0 +1,107 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// defaultSwarmPort is the swarm port of the managers of an external swarm when their address doesn't specify it.
+const defaultSwarmPort = 2377
+
+// JoinConfiguration represents the configuration of nodes joining a swarm not managed by sind.
+type JoinConfiguration struct {
+	// Cluster configures the nodes, Managers and Workers being the amount of nodes to create.
+	// Their role in the external swarm is defined by the join token, not by their sind role.
+	Cluster ClusterConfiguration
+
+	// JoinToken is the manager or worker join token of the external swarm.
+	JoinToken string
+	// ManagerAddresses are the addresses of the external swarm managers, tried in order, port 2377 if not specified.
+	ManagerAddresses []string
+	// Availability is the availability (active, drain or pause) the nodes join the external swarm with, active if empty.
+	// The availabilities of the cluster configuration don't apply, the nodes role being defined by the join token.
+	Availability string
+}
+
+func (j *JoinConfiguration) validate() error {
+	if err := j.Cluster.validate(); err != nil {
+		return err
+	}
+
+	if j.Cluster.Plain || j.Cluster.LoadBalancer {
+		return ErrUnsupportedJoinOption
+	}
+
+	if j.JoinToken == "" {
+		return ErrEmptyJoinToken
+	}
+
+	if len(j.ManagerAddresses) == 0 {
+		return ErrEmptyJoinAddress
+	}
+
+	if !validAvailability(j.Availability) {
+		return ErrInvalidNodeAvailability
+	}
+
+	return nil
+}
+
+func (j *JoinConfiguration) managerAddresses() []string {
+	addresses := make([]string, len(j.ManagerAddresses))
+
+	for i, address := range j.ManagerAddresses {
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			address = net.JoinHostPort(address, strconv.Itoa(defaultSwarmPort))
+		}
+
+		addresses[i] = address
+	}
+
+	return addresses
+}
+
+// JoinExternalSwarm creates the nodes of a cluster and makes them join a swarm not managed by sind.
+// Deleting the cluster makes the nodes leave the external swarm, leaving its services untouched.
+func JoinExternalSwarm(ctx context.Context, hostClient *docker.Client, params JoinConfiguration) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	params.Cluster = params.Cluster.namespaced()
+
+	managerAddrs := params.managerAddresses()
+
+	labels := params.Cluster.labels()
+	labels[internal.ExternalSwarmLabel] = strings.Join(managerAddrs, ",")
+
+	if _, err := createNodes(ctx, hostClient, params.Cluster, labels, &CreateTimings{}); err != nil {
+		return err
+	}
+
+	nodes, err := internal.ListNodes(ctx, hostClient, params.Cluster.ClusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list cluster nodes: %w", err)
+	}
+
+	if err = internal.WaitNodesReady(ctx, hostClient, nodes); err != nil {
+		return fmt.Errorf("unable to contact the nodes daemons: %w", err)
+	}
+
+	cIDs := make([]string, len(nodes))
+	for i, node := range nodes {
+		cIDs[i] = node.ID
+	}
+
+	if err = internal.JoinSwarm(ctx, hostClient, cIDs, params.JoinToken, params.Availability, managerAddrs); err != nil {
+		return fmt.Errorf("unable to join the external swarm: %w", err)
+	}
+
+	return nil
+}
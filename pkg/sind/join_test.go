@@ -0,0 +1,70 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJoinConfigurationValidationErrors(t *testing.T) {
+	cluster := ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1}
+
+	testCases := []struct {
+		desc          string
+		config        JoinConfiguration
+		expectedError error
+	}{
+		{
+			desc:          "with an invalid cluster configuration",
+			config:        JoinConfiguration{JoinToken: "token", ManagerAddresses: []string{"10.0.0.1"}},
+			expectedError: ErrEmptyClusterName,
+		},
+		{
+			desc: "with plain nodes",
+			config: JoinConfiguration{
+				Cluster:          ClusterConfiguration{ClusterName: "foo", NetworkName: "foo", Managers: 1, Plain: true},
+				JoinToken:        "token",
+				ManagerAddresses: []string{"10.0.0.1"},
+			},
+			expectedError: ErrUnsupportedJoinOption,
+		},
+		{
+			desc:          "without join token",
+			config:        JoinConfiguration{Cluster: cluster, ManagerAddresses: []string{"10.0.0.1"}},
+			expectedError: ErrEmptyJoinToken,
+		},
+		{
+			desc:          "without manager address",
+			config:        JoinConfiguration{Cluster: cluster, JoinToken: "token"},
+			expectedError: ErrEmptyJoinAddress,
+		},
+		{
+			desc:   "with a valid configuration",
+			config: JoinConfiguration{Cluster: cluster, JoinToken: "token", ManagerAddresses: []string{"10.0.0.1"}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.config.validate()
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
+
+func TestJoinConfigurationManagerAddresses(t *testing.T) {
+	cfg := JoinConfiguration{ManagerAddresses: []string{"10.0.0.1", "10.0.0.2:2378", "manager.local", "fd00::1"}}
+
+	assert.Equal(
+		t,
+		[]string{"10.0.0.1:2377", "10.0.0.2:2378", "manager.local:2377", "[fd00::1]:2377"},
+		cfg.managerAddresses(),
+	)
+}
@@ -36,3 +36,33 @@ func ListClusters(ctx context.Context, hostClient internal.ContainerLister) ([]C
 
 	return result, nil
 }
+
+// FilterNamespace returns the clusters created in given namespace, or all of them if the namespace is empty.
+func FilterNamespace(clusters []ClusterStatus, namespace string) []ClusterStatus {
+	if namespace == "" {
+		return clusters
+	}
+
+	result := make([]ClusterStatus, 0, len(clusters))
+
+	for _, cluster := range clusters {
+		if cluster.Namespace == namespace {
+			result = append(result, cluster)
+		}
+	}
+
+	return result
+}
+
+// FilterClusters returns the clusters having all the given metadata.
+func FilterClusters(clusters []ClusterStatus, metadata map[string]string) []ClusterStatus {
+	result := make([]ClusterStatus, 0, len(clusters))
+
+	for _, cluster := range clusters {
+		if cluster.MatchMetadata(metadata) {
+			result = append(result, cluster)
+		}
+	}
+
+	return result
+}
@@ -120,3 +120,25 @@ func TestListClusters(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterClusters(t *testing.T) {
+	clusters := []ClusterStatus{
+		{Name: "a", Metadata: map[string]string{"team": "payments"}},
+		{Name: "b", Metadata: map[string]string{"team": "billing"}},
+		{Name: "c", Metadata: map[string]string{}},
+	}
+
+	assert.Equal(t, clusters, FilterClusters(clusters, nil))
+	assert.Equal(t, clusters[:1], FilterClusters(clusters, map[string]string{"team": "payments"}))
+}
+
+func TestFilterNamespace(t *testing.T) {
+	clusters := []ClusterStatus{
+		{Name: "ci-1.a", Namespace: "ci-1"},
+		{Name: "ci-2.a", Namespace: "ci-2"},
+		{Name: "a"},
+	}
+
+	assert.Equal(t, clusters, FilterNamespace(clusters, ""))
+	assert.Equal(t, clusters[1:2], FilterNamespace(clusters, "ci-2"))
+}
@@ -0,0 +1,18 @@
+package sind
+
+import (
+	"os"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// HostLock is an advisory lock serializing the sind operations on a docker host.
+type HostLock = internal.Lock
+
+// LockHost takes the advisory lock of the docker host, for operations which would race on network names or host port
+// allocation. All of them take the same lock, whatever the ports they allocate, as the ports picked by the daemon can
+// collide with any of them. It returns ErrHostLocked if another sind process holds the lock.
+func LockHost(hostClient *docker.Client) (*HostLock, error) {
+	return internal.AcquireLock(os.TempDir(), hostClient.DaemonHost())
+}
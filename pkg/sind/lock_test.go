@@ -0,0 +1,46 @@
+package sind
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	docker "github.com/docker/docker/client"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockHost(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sind-lock")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir)
+
+	// The lock files are written in the temporary directory.
+	defer os.Setenv("TMPDIR", os.Getenv("TMPDIR"))
+	require.NoError(t, os.Setenv("TMPDIR", dir))
+
+	hostClient, err := docker.NewClientWithOpts(docker.WithHost("tcp://10.0.0.1:2375"))
+	require.NoError(t, err)
+
+	otherClient, err := docker.NewClientWithOpts(docker.WithHost("tcp://10.0.0.2:2375"))
+	require.NoError(t, err)
+
+	lock, err := LockHost(hostClient)
+	require.NoError(t, err)
+
+	_, err = LockHost(hostClient)
+	assert.True(t, errors.Is(err, ErrHostLocked))
+
+	// Other docker hosts are locked independently.
+	otherLock, err := LockHost(otherClient)
+	require.NoError(t, err)
+	require.NoError(t, otherLock.Release())
+
+	require.NoError(t, lock.Release())
+
+	lock, err = LockHost(hostClient)
+	require.NoError(t, err)
+	require.NoError(t, lock.Release())
+}
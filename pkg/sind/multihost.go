@@ -0,0 +1,152 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// HostNodes represents the nodes of a cluster created on an additional docker host.
+type HostNodes struct {
+	// Client is the client of the additional docker host.
+	Client *docker.Client
+	// Nodes is the amount of worker nodes to create on the host.
+	Nodes uint16
+	// ExistingNetwork is the ID or name of the network of the host to attach the nodes to. Sind doesn't create it: it
+	// must route to the cluster network of the first host, for instance a macvlan network sharing a segment with it.
+	ExistingNetwork string
+	// ImageName is the image of the nodes, the image of the cluster primary node if empty.
+	ImageName string
+	// PullImage pulls the image on the host before creating the nodes.
+	PullImage bool
+	// Availability is the availability (active, drain or pause) the nodes join the swarm with, active if empty.
+	Availability string
+}
+
+// ExtendConfiguration represents the distribution of the nodes of a cluster across additional docker hosts.
+type ExtendConfiguration struct {
+	ClusterName string
+	// Namespace is the namespace of the cluster, see NamespacedName.
+	Namespace string
+
+	// Hosts are the additional docker hosts the new nodes are distributed across.
+	Hosts []HostNodes
+}
+
+func (e *ExtendConfiguration) validate() error {
+	if e.ClusterName == "" {
+		return ErrEmptyClusterName
+	}
+
+	if len(e.Hosts) == 0 {
+		return ErrNoExtensionHost
+	}
+
+	for _, host := range e.Hosts {
+		if host.ExistingNetwork == "" {
+			return ErrEmptyHostNetwork
+		}
+
+		if host.Nodes < 1 {
+			return ErrInvalidHostNodeCount
+		}
+
+		if !validAvailability(host.Availability) {
+			return ErrInvalidNodeAvailability
+		}
+	}
+
+	return nil
+}
+
+// ExtendCluster creates worker nodes on additional docker hosts, joining the swarm of a cluster running on the docker
+// host of hostClient, to simulate swarms too large for a single machine.
+// The nodes of each host form a cluster with the same name on that host, joined to the swarm like JoinExternalSwarm
+// does: it is inspected, stopped and deleted through the client of that host, before deleting the cluster itself.
+// The load balancer of the cluster, if any, does not balance across the nodes of the additional hosts.
+func ExtendCluster(ctx context.Context, hostClient *docker.Client, params ExtendConfiguration) error {
+	if err := params.validate(); err != nil {
+		return err
+	}
+
+	clusterName := NamespacedName(params.Namespace, params.ClusterName)
+
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list cluster nodes: %w", err)
+	}
+
+	primary, ok := primaryNode(nodes)
+	if !ok {
+		return ErrClusterNotFound
+	}
+
+	if !managedSwarm(primary) {
+		return ErrExtendUnmanagedSwarm
+	}
+
+	managerIPs := internal.ManagerIPs(nodes)
+	if len(managerIPs) == 0 {
+		return fmt.Errorf("%w: no manager of cluster %q is running", ErrNodeNotRunning, clusterName)
+	}
+
+	managerAddrs := make([]string, len(managerIPs))
+	for i, managerIP := range managerIPs {
+		managerAddrs[i] = net.JoinHostPort(managerIP, strconv.Itoa(defaultSwarmPort))
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	swarmInfo, err := swarmClient.SwarmInspect(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the swarm join tokens: %w", err)
+	}
+
+	var clusterTLS *internal.TLSMaterial
+
+	// The nodes of a cluster secured with TLS get certificates issued by the CA kept in its primary node.
+	if primary.Labels[internal.TLSLabel] == "true" {
+		if clusterTLS, err = internal.ReadTLS(ctx, hostClient, primary.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, host := range params.Hosts {
+		imageName := host.ImageName
+		if imageName == "" {
+			imageName = primary.Image
+		}
+
+		// Hosts are extended one at a time, for a failure to name the host which caused it.
+		err = JoinExternalSwarm(ctx, host.Client, JoinConfiguration{
+			Cluster: ClusterConfiguration{
+				ClusterName:     params.ClusterName,
+				Namespace:       params.Namespace,
+				ExistingNetwork: host.ExistingNetwork,
+				Managers:        1,
+				Workers:         host.Nodes - 1,
+				ImageName:       imageName,
+				PullImage:       host.PullImage,
+				TLS:             clusterTLS != nil,
+				clusterTLS:      clusterTLS,
+			},
+			JoinToken:        swarmInfo.JoinTokens.Worker,
+			ManagerAddresses: managerAddrs,
+			Availability:     host.Availability,
+		})
+		if err != nil {
+			return fmt.Errorf("unable to create the nodes on docker host %q: %w", host.Client.DaemonHost(), err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtendConfigurationValidationErrors(t *testing.T) {
+	host := HostNodes{Nodes: 2, ExistingNetwork: "routed"}
+
+	testCases := []struct {
+		desc          string
+		config        ExtendConfiguration
+		expectedError error
+	}{
+		{
+			desc:          "without cluster name",
+			config:        ExtendConfiguration{Hosts: []HostNodes{host}},
+			expectedError: ErrEmptyClusterName,
+		},
+		{
+			desc:          "without host",
+			config:        ExtendConfiguration{ClusterName: "foo"},
+			expectedError: ErrNoExtensionHost,
+		},
+		{
+			desc:          "with a host without network",
+			config:        ExtendConfiguration{ClusterName: "foo", Hosts: []HostNodes{host, {Nodes: 1}}},
+			expectedError: ErrEmptyHostNetwork,
+		},
+		{
+			desc:          "with a host without node",
+			config:        ExtendConfiguration{ClusterName: "foo", Hosts: []HostNodes{{ExistingNetwork: "routed"}}},
+			expectedError: ErrInvalidHostNodeCount,
+		},
+		{
+			desc:   "with a valid configuration",
+			config: ExtendConfiguration{ClusterName: "foo", Hosts: []HostNodes{host}},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			err := test.config.validate()
+			if test.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.True(t, errors.Is(err, test.expectedError))
+			assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package sind
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// NodeConfigUpdate represents a new docker daemon configuration for the nodes of a cluster.
+type NodeConfigUpdate struct {
+	// Role restricts the update to the nodes with this role, manager (which includes the primary node) or worker.
+	// All the nodes are updated if empty.
+	Role string
+	// DaemonConfig is the new content of the daemon.json file of the nodes.
+	DaemonConfig string
+	// Restart restarts the nodes instead of making their daemon reload its configuration, which only applies some
+	// settings, see the dockerd documentation.
+	Restart bool
+}
+
+func (u NodeConfigUpdate) validate() error {
+	if err := (NodeSelector{Role: u.Role}).validate(); err != nil {
+		return err
+	}
+
+	if !json.Valid([]byte(u.DaemonConfig)) {
+		return ErrInvalidDaemonConfig
+	}
+
+	return nil
+}
+
+// UpdateNodeConfig writes a new daemon configuration in the nodes of a cluster, and applies it.
+// Nodes are updated one at a time, each of them being back in the swarm before updating the next one, for the swarm to
+// keep its quorum. The configuration is kept when the nodes are restarted, unless the cluster has been created with a
+// daemon configuration by a previous version of sind.
+func UpdateNodeConfig(ctx context.Context, hostClient *docker.Client, clusterName string, update NodeConfigUpdate) error {
+	if err := update.validate(); err != nil {
+		return err
+	}
+
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return ErrClusterNotFound
+	}
+
+	for _, node := range nodes {
+		if update.Role != "" && selectorRole(node) != update.Role {
+			continue
+		}
+
+		if err = updateNodeConfig(ctx, hostClient, node, update); err != nil {
+			return fmt.Errorf("unable to update node %q: %w", containerName(node), err)
+		}
+	}
+
+	return nil
+}
+
+func updateNodeConfig(ctx context.Context, hostClient *docker.Client, node types.Container, update NodeConfigUpdate) error {
+	if err := internal.UpdateDaemonConfig(ctx, hostClient, node.ID, update.DaemonConfig, update.Restart); err != nil {
+		return err
+	}
+
+	if err := internal.WaitNodesReady(ctx, hostClient, []types.Container{node}); err != nil {
+		return fmt.Errorf("unable to wait for the daemon to be ready: %w", err)
+	}
+
+	if node.Labels[internal.PlainClusterLabel] == "true" {
+		return nil
+	}
+
+	if err := internal.WaitSwarmNodeActive(ctx, hostClient, node.ID); err != nil {
+		return fmt.Errorf("unable to wait for the node to be back in the swarm: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,16 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeConfigUpdateValidation(t *testing.T) {
+	assert.NoError(t, NodeConfigUpdate{DaemonConfig: `{"debug":true}`}.validate())
+	assert.NoError(t, NodeConfigUpdate{Role: "worker", DaemonConfig: `{}`}.validate())
+	assert.True(t, errors.Is(NodeConfigUpdate{DaemonConfig: `{"debug":`}.validate(), ErrInvalidDaemonConfig))
+	assert.True(t, errors.Is(NodeConfigUpdate{DaemonConfig: ""}.validate(), ErrInvalidDaemonConfig))
+	assert.True(t, errors.Is(NodeConfigUpdate{Role: "primary", DaemonConfig: `{}`}.validate(), ErrInvalidNodeRole))
+}
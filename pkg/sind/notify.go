@@ -0,0 +1,84 @@
+package sind
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	docker "github.com/docker/docker/client"
+)
+
+// Notifier is a callback fired on the lifecycle changes of clusters, e.g. to track the usage of a shared docker host
+// from a dashboard or a chat.
+type Notifier func(ctx context.Context, notification Notification) error
+
+// WebhookNotifier returns a notifier posting the notifications as JSON to given URL, failing on non 2xx responses.
+func WebhookNotifier(url string) Notifier {
+	return func(ctx context.Context, notification Notification) error {
+		body, err := json.Marshal(notification)
+		if err != nil {
+			return err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		defer resp.Body.Close()
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return fmt.Errorf("webhook %s answered %s", url, resp.Status)
+		}
+
+		return nil
+	}
+}
+
+// Notify fires given notifiers on the lifecycle notifications of all the clusters of the docker host, as sent by
+// WatchClusters, until the context is done. A failing notifier does not stop the notifications, its errors are sent
+// to errs if not nil.
+func Notify(ctx context.Context, hostClient *docker.Client, notifiers []Notifier, errs chan<- error) error {
+	notifications := make(chan Notification)
+	done := make(chan error, 1)
+
+	go func() {
+		done <- WatchClusters(ctx, hostClient, notifications)
+	}()
+
+	for {
+		select {
+		case err := <-done:
+			return err
+		case notification := <-notifications:
+			notify(ctx, notifiers, notification, errs)
+		}
+	}
+}
+
+// notify fires the notifiers on a notification, sending their errors to errs if not nil.
+func notify(ctx context.Context, notifiers []Notifier, notification Notification, errs chan<- error) {
+	for i, notifier := range notifiers {
+		err := notifier(ctx, notification)
+		if err == nil || errs == nil {
+			continue
+		}
+
+		err = fmt.Errorf("%w: notifier #%d, %s of cluster %q: %v", ErrNotifierFailed, i+1, notification.Kind, notification.Cluster, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case errs <- err:
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package sind
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookNotifier(t *testing.T) {
+	notification := Notification{
+		Kind:    NodeUnhealthy,
+		Time:    time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC),
+		Cluster: "test",
+		Node:    "worker-0",
+	}
+
+	var received map[string]interface{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(req.Body).Decode(&received))
+	}))
+	defer server.Close()
+
+	err := WebhookNotifier(server.URL)(context.Background(), notification)
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		map[string]interface{}{
+			"kind":    "node-unhealthy",
+			"time":    "2021-06-01T10:00:00Z",
+			"cluster": "test",
+			"node":    "worker-0",
+		},
+		received,
+	)
+}
+
+func TestWebhookNotifierFailsOnErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		rw.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := WebhookNotifier(server.URL)(context.Background(), Notification{Kind: ClusterCreated, Cluster: "test"})
+	assert.EqualError(t, err, "webhook "+server.URL+" answered 502 Bad Gateway")
+}
+
+func TestNotify(t *testing.T) {
+	notification := Notification{Kind: ClusterDeleted, Cluster: "test"}
+
+	var notified []string
+
+	notifiers := []Notifier{
+		func(ctx context.Context, n Notification) error {
+			notified = append(notified, "first")
+			return errors.New("boom")
+		},
+		func(ctx context.Context, n Notification) error {
+			assert.Equal(t, notification, n)
+
+			notified = append(notified, "second")
+
+			return nil
+		},
+	}
+
+	errs := make(chan error, 1)
+
+	notify(context.Background(), notifiers, notification, errs)
+
+	assert.Equal(t, []string{"first", "second"}, notified)
+
+	err := <-errs
+	assert.True(t, errors.Is(err, ErrNotifierFailed))
+	assert.EqualError(t, err, `notifier failed: notifier #1, cluster-deleted of cluster "test": boom`)
+}
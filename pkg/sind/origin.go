@@ -0,0 +1,36 @@
+package sind
+
+import (
+	"os"
+	"os/user"
+	"time"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// Version is the version of sind stamped on the resources it creates.
+var Version = "unknown"
+
+// creationLabels returns the labels recording when, by whom and with which version of sind a resource is created.
+func creationLabels(now time.Time) map[string]string {
+	return map[string]string{
+		internal.CreatedAtLabel: now.UTC().Format(time.RFC3339),
+		internal.CreatedByLabel: creator(),
+		internal.VersionLabel:   Version,
+	}
+}
+
+// creator returns the user@host running sind, unknown parts being left empty.
+func creator() string {
+	var username, hostname string
+
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+
+	if name, err := os.Hostname(); err == nil {
+		hostname = name
+	}
+
+	return username + "@" + hostname
+}
@@ -0,0 +1,31 @@
+package sind
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreationLabels(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+
+	labels := creationLabels(now)
+
+	assert.Equal(t, "2021-06-01T10:00:00Z", labels[internal.CreatedAtLabel])
+	assert.Equal(t, Version, labels[internal.VersionLabel])
+	assert.True(t, strings.Contains(labels[internal.CreatedByLabel], "@"))
+}
+
+func TestClusterConfigurationLabelsRecordCreation(t *testing.T) {
+	cfg := ClusterConfiguration{Metadata: map[string]string{"team": "payments"}}
+
+	labels := cfg.labels()
+
+	assert.Contains(t, labels, internal.CreatedAtLabel)
+	assert.Contains(t, labels, internal.CreatedByLabel)
+	assert.Contains(t, labels, internal.VersionLabel)
+	assert.Equal(t, "payments", labels[internal.MetadataLabelPrefix+"team"])
+}
@@ -0,0 +1,210 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// ClusterPlan describes what CreateCluster would do on the docker host for a given configuration.
+type ClusterPlan struct {
+	ClusterName string `json:"cluster_name"`
+	// DockerHost is the host on which the container ports are published.
+	DockerHost string          `json:"docker_host"`
+	Images     []ImagePlan     `json:"images"`
+	Network    NetworkPlan     `json:"network"`
+	Containers []ContainerPlan `json:"containers"`
+}
+
+// ImagePlan describes an image used by a cluster.
+type ImagePlan struct {
+	Ref  string `json:"ref"`
+	Pull bool   `json:"pull"`
+}
+
+// NetworkPlan describes the network of a cluster.
+type NetworkPlan struct {
+	Name string `json:"name"`
+	// Existing is true if the network already exists and is only attached to.
+	Existing bool   `json:"existing"`
+	Driver   string `json:"driver,omitempty"`
+	Subnet   string `json:"subnet,omitempty"`
+	// RandomSubnet is true if the subnet is randomly picked, another one is picked at creation.
+	RandomSubnet bool   `json:"random_subnet,omitempty"`
+	Gateway      string `json:"gateway,omitempty"`
+	IPRange      string `json:"ip_range,omitempty"`
+	// Internal is true if the network has no access to external networks.
+	Internal bool `json:"internal,omitempty"`
+}
+
+// ContainerPlan describes a container of a cluster.
+type ContainerPlan struct {
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Image string `json:"image"`
+	// Address is the address of the container on the cluster network, empty if assigned by the network IPAM.
+	Address string `json:"address,omitempty"`
+	// Ports are the port bindings of the container ([HOST_IP:]HOST_PORT:CONTAINER_PORT/PROTOCOL), the host port being
+	// empty if picked by the docker host.
+	Ports []string `json:"ports,omitempty"`
+	// PublishAllPorts is true if all the exposed ports of the container image are published on random host ports.
+	PublishAllPorts bool     `json:"publish_all_ports,omitempty"`
+	Command         []string `json:"command"`
+}
+
+// PlanCluster validates a cluster configuration and returns what CreateCluster would create with it, without creating
+// anything on the docker host.
+func PlanCluster(ctx context.Context, hostClient *docker.Client, params ClusterConfiguration) (*ClusterPlan, error) {
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	params = params.namespaced()
+
+	host, err := internal.SwarmHost(hostClient)
+	if err != nil {
+		return nil, fmt.Errorf("unable to get the docker host: %w", err)
+	}
+
+	plan := ClusterPlan{ClusterName: params.ClusterName, DockerHost: host}
+
+	images := []ImagePlan{{Ref: params.imageName(), Pull: params.PullImage}}
+	if params.LoadBalancer {
+		images = append(images, ImagePlan{Ref: internal.DefaultLoadBalancerImageName})
+	}
+
+	for _, image := range images {
+		exists, err := internal.ImageExists(ctx, hostClient, image.Ref)
+		if err != nil {
+			return nil, fmt.Errorf("unable to check image existence: %w", err)
+		}
+
+		plan.Images = append(plan.Images, ImagePlan{Ref: image.Ref, Pull: image.Pull || !exists})
+	}
+
+	labels := params.labels()
+	nodesCfg := params.nodesConfig(labels)
+
+	if params.ExistingNetwork != "" {
+		existingNet, err := hostClient.NetworkInspect(ctx, params.ExistingNetwork, types.NetworkInspectOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("unable to inspect the existing network: %w", err)
+		}
+
+		nodesCfg.NetworkID = existingNet.ID
+		nodesCfg.NetworkName = existingNet.Name
+
+		plan.Network = NetworkPlan{Name: existingNet.Name, Existing: true, Driver: existingNet.Driver}
+
+		if len(existingNet.IPAM.Config) > 0 {
+			plan.Network.Subnet = existingNet.IPAM.Config[0].Subnet
+		}
+	} else {
+		subnet, err := params.subnet()
+		if err != nil {
+			return nil, fmt.Errorf("unable to pick an internal subnet: %w", err)
+		}
+
+		networkCfg := params.networkConfig(subnet, labels)
+
+		nodesCfg.NetworkName = networkCfg.Name
+
+		if !params.customIPAM() {
+			nodesCfg.Subnet = *subnet
+		}
+
+		plan.Network = NetworkPlan{
+			Name:         networkCfg.Name,
+			Driver:       networkCfg.Driver,
+			Subnet:       networkCfg.Subnet,
+			RandomSubnet: params.NetworkSubnet == "",
+			Gateway:      networkCfg.Gateway,
+			IPRange:      networkCfg.IPRange,
+			Internal:     networkCfg.Internal,
+		}
+
+		if plan.Network.Driver == "" {
+			plan.Network.Driver = "bridge"
+		}
+	}
+
+	if plan.Containers, err = planContainers(ctx, params, nodesCfg); err != nil {
+		return nil, err
+	}
+
+	return &plan, nil
+}
+
+func planContainers(ctx context.Context, params ClusterConfiguration, nodesCfg internal.NodesConfig) ([]ContainerPlan, error) {
+	var recorder internal.ContainerRecorder
+
+	if _, err := internal.CreateNodes(ctx, &recorder, nodesCfg); err != nil {
+		return nil, fmt.Errorf("unable to plan nodes: %w", err)
+	}
+
+	if params.LoadBalancer {
+		var backends []string
+
+		for _, node := range recorder.Containers() {
+			plan := containerPlan(node)
+
+			// Without a static address, the node is reached by name on the cluster network.
+			if plan.Address == "" {
+				plan.Address = plan.Name
+			}
+
+			backends = append(backends, plan.Address)
+		}
+
+		lbCfg := internal.LoadBalancerConfig{
+			ClusterName:  params.ClusterName,
+			ImageRef:     internal.DefaultLoadBalancerImageName,
+			NetworkID:    nodesCfg.NetworkID,
+			NetworkName:  nodesCfg.NetworkName,
+			PortBindings: params.PortBindings,
+			Backends:     backends,
+			Labels:       creationLabels(time.Now()),
+		}
+
+		if _, err := internal.CreateLoadBalancer(ctx, &recorder, lbCfg); err != nil {
+			return nil, fmt.Errorf("unable to plan the load balancer: %w", err)
+		}
+	}
+
+	recorded := recorder.Containers()
+	plans := make([]ContainerPlan, len(recorded))
+
+	for i, container := range recorded {
+		plans[i] = containerPlan(container)
+	}
+
+	return plans, nil
+}
+
+func containerPlan(container internal.RecordedContainer) ContainerPlan {
+	plan := ContainerPlan{
+		Name:            container.Name,
+		Role:            container.Config.Labels[internal.NodeRoleLabel],
+		Image:           container.Config.Image,
+		PublishAllPorts: container.HostConfig.PublishAllPorts,
+		Command:         append(append([]string{}, container.Config.Entrypoint...), container.Config.Cmd...),
+	}
+
+	if plan.Role == "" {
+		plan.Role = container.Config.Labels[internal.ComponentLabel]
+	}
+
+	for _, endpoint := range container.NetworkingConfig.EndpointsConfig {
+		if endpoint.IPAMConfig != nil {
+			plan.Address = endpoint.IPAMConfig.IPv4Address
+		}
+	}
+
+	plan.Ports = internal.PortRanges(container.HostConfig.PortBindings)
+
+	return plan
+}
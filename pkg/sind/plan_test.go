@@ -0,0 +1,71 @@
+package sind
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanContainers(t *testing.T) {
+	params := ClusterConfiguration{
+		ClusterName:  "test",
+		NetworkName:  "test-net",
+		Managers:     1,
+		Workers:      1,
+		PortBindings: []string{"127.0.0.1:8080:80"},
+		LoadBalancer: true,
+	}
+
+	_, subnet, err := net.ParseCIDR("10.0.12.0/24")
+	require.NoError(t, err)
+
+	nodesCfg := params.nodesConfig(params.labels())
+	nodesCfg.NetworkName = params.NetworkName
+	nodesCfg.Subnet = *subnet
+
+	plans, err := planContainers(context.Background(), params, nodesCfg)
+	require.NoError(t, err)
+	require.Len(t, plans, 3)
+
+	entrypoint := []string{
+		"sh",
+		"-c",
+		`{ [ -f /etc/sind/.started ] || { rm -rf /var/lib/docker/swarm && mkdir -p /etc/sind && touch /etc/sind/.started; }; } && ` +
+			`exec dockerd "$@"`,
+		"dockerd",
+	}
+
+	assert.Equal(t, "sind-test-lb", plans[0].Name)
+	assert.Equal(t, internal.ComponentLoadBalancer, plans[0].Role)
+	assert.Equal(t, internal.DefaultLoadBalancerImageName, plans[0].Image)
+	assert.Equal(t, []string{"127.0.0.1:8080:80/tcp"}, plans[0].Ports)
+
+	assert.Equal(
+		t,
+		ContainerPlan{
+			Name:            "sind-test-manager-0",
+			Role:            internal.NodeRolePrimary,
+			Image:           DefaultNodeImageName,
+			Address:         "10.0.12.2",
+			PublishAllPorts: true,
+			Command:         append(entrypoint, "-H unix:///var/run/docker.sock", "-H tcp://0.0.0.0:2375"),
+		},
+		plans[1],
+	)
+
+	assert.Equal(
+		t,
+		ContainerPlan{
+			Name:    "sind-test-worker-0",
+			Role:    internal.NodeRoleWorker,
+			Image:   DefaultNodeImageName,
+			Address: "10.0.12.3",
+			Command: entrypoint,
+		},
+		plans[2],
+	)
+}
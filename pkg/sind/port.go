@@ -0,0 +1,109 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/go-connections/nat"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// daemonPort is the port of the node docker daemons, published on the docker host but not a port binding.
+const daemonPort = 2375
+
+// PublishedPort is a port binding of a cluster, published on the docker host by one of its containers.
+type PublishedPort struct {
+	// Name is the name of the node, or the kind of the auxiliary container, publishing the port.
+	Name string `json:"name"`
+	Role string `json:"role"`
+	// Port is the container port, e.g. 80/tcp.
+	Port     string `json:"port"`
+	HostIP   string `json:"host_ip"`
+	HostPort uint16 `json:"host_port"`
+}
+
+// PublishedPorts returns the host ports the port bindings of a cluster are published on, by each of its containers,
+// sorted by container then by port. The nodes publishing the ports depend on the PublishPortsOn option of the cluster.
+func PublishedPorts(ctx context.Context, hostClient internal.ContainerLister, clusterName string) ([]PublishedPort, error) {
+	containers, err := internal.ListContainers(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(containers) == 0 {
+		return nil, ErrClusterNotFound
+	}
+
+	bound, err := boundPorts(containers)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []PublishedPort
+
+	for _, container := range containers {
+		name, role := containerName(container), container.Labels[internal.NodeRoleLabel]
+		if role == "" {
+			name, role = container.Labels[internal.ComponentLabel], container.Labels[internal.ComponentLabel]
+		} else {
+			name = nodeName(clusterName, name)
+		}
+
+		for _, port := range container.Ports {
+			containerPort := fmt.Sprintf("%d/%s", port.PrivatePort, port.Type)
+
+			if port.PublicPort == 0 || !boundPort(bound, port, containerPort) {
+				continue
+			}
+
+			result = append(result, PublishedPort{
+				Name:     name,
+				Role:     role,
+				Port:     containerPort,
+				HostIP:   port.IP,
+				HostPort: port.PublicPort,
+			})
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Name != result[j].Name {
+			return result[i].Name < result[j].Name
+		}
+
+		return result[i].HostPort < result[j].HostPort
+	})
+
+	return result, nil
+}
+
+// boundPorts returns the container ports of the port bindings recorded on the primary node of a cluster, nil for the
+// clusters created before they were recorded.
+func boundPorts(containers []types.Container) (map[nat.Port]struct{}, error) {
+	primary, ok := primaryNode(containers)
+	if !ok || primary.Labels[internal.PortBindingsLabel] == "" {
+		return nil, nil
+	}
+
+	ports, _, err := nat.ParsePortSpecs(strings.Split(primary.Labels[internal.PortBindingsLabel], ","))
+	if err != nil {
+		return nil, fmt.Errorf("node %q has invalid port bindings: %w", primary.ID, err)
+	}
+
+	return ports, nil
+}
+
+// boundPort returns true if a published port is a port binding of the cluster, any port but the node daemons one
+// without recorded port bindings.
+func boundPort(bound map[nat.Port]struct{}, port types.Port, containerPort string) bool {
+	if bound == nil {
+		return port.PrivatePort != daemonPort
+	}
+
+	_, ok := bound[nat.Port(containerPort)]
+
+	return ok
+}
@@ -0,0 +1,64 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishedPorts(t *testing.T) {
+	containers := []types.Container{
+		{
+			Names: []string{"/sind-foo-manager-1"},
+			Labels: map[string]string{
+				internal.NodeRoleLabel: internal.NodeRoleManager,
+			},
+			Ports: []types.Port{
+				{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 32769, Type: "tcp"},
+				{PrivatePort: 2377, Type: "tcp"},
+			},
+		},
+		{
+			Names: []string{"/sind-foo-manager-0"},
+			Labels: map[string]string{
+				internal.NodeRoleLabel:     internal.NodeRolePrimary,
+				internal.PortBindingsLabel: "8080:80/tcp",
+			},
+			Ports: []types.Port{
+				{IP: "0.0.0.0", PrivatePort: 2375, PublicPort: 32768, Type: "tcp"},
+				{IP: "0.0.0.0", PrivatePort: 80, PublicPort: 8080, Type: "tcp"},
+			},
+		},
+	}
+
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return containers, nil
+	})
+
+	ports, err := PublishedPorts(context.Background(), client, "foo")
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		[]PublishedPort{
+			{Name: "manager-0", Role: internal.NodeRolePrimary, Port: "80/tcp", HostIP: "0.0.0.0", HostPort: 8080},
+			{Name: "manager-1", Role: internal.NodeRoleManager, Port: "80/tcp", HostIP: "0.0.0.0", HostPort: 32769},
+		},
+		ports,
+	)
+}
+
+func TestPublishedPortsClusterNotFound(t *testing.T) {
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return nil, nil
+	})
+
+	_, err := PublishedPorts(context.Background(), client, "foo")
+
+	assert.True(t, errors.Is(err, ErrClusterNotFound))
+}
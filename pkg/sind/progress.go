@@ -0,0 +1,85 @@
+package sind
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// CreateEventKind is the kind of a progress event of a cluster creation.
+type CreateEventKind string
+
+// Create event kinds.
+const (
+	// ImagePulling is sent before pulling an image missing from the docker host, or pulled on demand.
+	ImagePulling CreateEventKind = "image-pulling"
+	// ImagePulled is sent once an image is pulled.
+	ImagePulled CreateEventKind = "image-pulled"
+	// NetworkCreated is sent once the network of the cluster is created, or the existing one found.
+	NetworkCreated CreateEventKind = "network-created"
+	// NodeStarted is sent once the container of a node is started, its daemon not being ready yet.
+	NodeStarted CreateEventKind = "node-started"
+	// SwarmInitialized is sent once the primary node initialized the swarm.
+	SwarmInitialized CreateEventKind = "swarm-initialized"
+	// NodeJoined is sent once a node joined the swarm, before it is ready.
+	NodeJoined CreateEventKind = "node-joined"
+)
+
+// CreateEvent is a progress event of a cluster creation.
+type CreateEvent struct {
+	Kind CreateEventKind
+	Time time.Time
+
+	// ClusterName is the name of the cluster, prefixed with its namespace if any.
+	ClusterName string
+	// Image is the reference of the image of the image events.
+	Image string
+	// Network is the name of the network of the network events.
+	Network string
+	// Node is the name of the node of the node events, e.g. worker-1.
+	Node string
+}
+
+func (e CreateEvent) String() string {
+	switch e.Kind {
+	case ImagePulling:
+		return fmt.Sprintf("Pulling image %s", e.Image)
+	case ImagePulled:
+		return fmt.Sprintf("Pulled image %s", e.Image)
+	case NetworkCreated:
+		return fmt.Sprintf("Network %s ready", e.Network)
+	case NodeStarted:
+		return fmt.Sprintf("Node %s started", e.Node)
+	case SwarmInitialized:
+		return "Swarm initialized"
+	case NodeJoined:
+		return fmt.Sprintf("Node %s joined the swarm", e.Node)
+	default:
+		return string(e.Kind)
+	}
+}
+
+// serializedEvents returns onEvent called by one goroutine at a time, the nodes being created and joining the swarm
+// concurrently.
+func serializedEvents(onEvent func(CreateEvent)) func(CreateEvent) {
+	var mu sync.Mutex
+
+	return func(event CreateEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		onEvent(event)
+	}
+}
+
+// emit sends an event of the creation of the cluster to OnEvent, if set.
+func (n *ClusterConfiguration) emit(event CreateEvent) {
+	if n.OnEvent == nil {
+		return
+	}
+
+	event.Time = time.Now()
+	event.ClusterName = n.ClusterName
+
+	n.OnEvent(event)
+}
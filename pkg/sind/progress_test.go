@@ -0,0 +1,68 @@
+package sind
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterConfigurationEmit(t *testing.T) {
+	var events []CreateEvent
+
+	cfg := ClusterConfiguration{ClusterName: "team-test", OnEvent: func(event CreateEvent) { events = append(events, event) }}
+	cfg.emit(CreateEvent{Kind: NodeStarted, Node: "worker-0"})
+
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, NodeStarted, events[0].Kind)
+		assert.Equal(t, "team-test", events[0].ClusterName)
+		assert.Equal(t, "worker-0", events[0].Node)
+		assert.False(t, events[0].Time.IsZero())
+	}
+
+	// Events are dropped without hook.
+	cfg.OnEvent = nil
+	cfg.emit(CreateEvent{Kind: SwarmInitialized})
+}
+
+func TestSerializedEvents(t *testing.T) {
+	var (
+		count int
+		wg    sync.WaitGroup
+	)
+
+	onEvent := serializedEvents(func(CreateEvent) { count++ })
+
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+			onEvent(CreateEvent{Kind: NodeJoined})
+		}()
+	}
+
+	wg.Wait()
+
+	assert.Equal(t, 10, count)
+}
+
+func TestCreateEventString(t *testing.T) {
+	testCases := []struct {
+		event    CreateEvent
+		expected string
+	}{
+		{event: CreateEvent{Kind: ImagePulling, Image: "docker:dind"}, expected: "Pulling image docker:dind"},
+		{event: CreateEvent{Kind: ImagePulled, Image: "docker:dind"}, expected: "Pulled image docker:dind"},
+		{event: CreateEvent{Kind: NetworkCreated, Network: "sind-default"}, expected: "Network sind-default ready"},
+		{event: CreateEvent{Kind: NodeStarted, Node: "manager-0"}, expected: "Node manager-0 started"},
+		{event: CreateEvent{Kind: SwarmInitialized}, expected: "Swarm initialized"},
+		{event: CreateEvent{Kind: NodeJoined, Node: "worker-1"}, expected: "Node worker-1 joined the swarm"},
+	}
+
+	for _, test := range testCases {
+		t.Run(string(test.event.Kind), func(t *testing.T) {
+			assert.Equal(t, test.expected, test.event.String())
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// PublishPort publishes the port nodePort of target on the port hostPort of the docker host, through a proxy container
+// attached to the cluster network. If target is empty, the primary node of the cluster is used.
+func PublishPort(ctx context.Context, hostClient *docker.Client, clusterName string, hostPort, nodePort uint16, target string) error {
+	_, err := publishPort(ctx, hostClient, clusterName, hostPort, nodePort, target)
+
+	return err
+}
+
+// publishPort publishes a port like PublishPort, and returns the ID of the proxy container.
+func publishPort(ctx context.Context, hostClient *docker.Client, clusterName string, hostPort, nodePort uint16, target string) (string, error) {
+	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the primary node informations: %w", err)
+	}
+
+	proxyCfg, err := portProxyConfig(clusterName, *primaryNode, hostPort, nodePort, target)
+	if err != nil {
+		return "", err
+	}
+
+	proxyCfg.Labels = creationLabels(time.Now())
+
+	if err = ensureImage(ctx, hostClient, internal.DefaultProxyImageName, false, nil); err != nil {
+		return "", fmt.Errorf("unable to get proxy image: %w", err)
+	}
+
+	proxyID, err := internal.CreateProxy(ctx, hostClient, proxyCfg)
+	if err != nil {
+		return "", fmt.Errorf("unable to create the port proxy: %w", err)
+	}
+
+	return proxyID, nil
+}
+
+// portProxyConfig returns the configuration of a proxy publishing the port nodePort of target, the primary node if empty,
+// on the cluster network.
+func portProxyConfig(clusterName string, primaryNode types.Container, hostPort, nodePort uint16, target string) (internal.ProxyConfig, error) {
+	networkName, primaryNodeEndpoint, err := internal.NodeNetwork(primaryNode)
+	if err != nil {
+		return internal.ProxyConfig{}, err
+	}
+
+	if target == "" {
+		target = primaryNodeEndpoint.IPAddress
+	}
+
+	return internal.ProxyConfig{
+		ClusterName: clusterName,
+		ImageRef:    internal.DefaultProxyImageName,
+		NetworkID:   primaryNodeEndpoint.NetworkID,
+		NetworkName: networkName,
+		HostPort:    hostPort,
+		TargetHost:  target,
+		TargetPort:  nodePort,
+	}, nil
+}
+
+// DefaultSocketDir returns the default directory of the unix sockets published by PublishSocket, ~/.sind.
+func DefaultSocketDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("unable to get the user home directory: %w", err)
+	}
+
+	return filepath.Join(home, ".sind"), nil
+}
+
+// ClusterSocketPath returns the path of the unix socket published by PublishSocket for a cluster in socketDir.
+func ClusterSocketPath(socketDir, clusterName string) string {
+	return filepath.Join(socketDir, clusterName+".sock")
+}
+
+// PublishSocket publishes the docker API of the primary node of the cluster as a unix socket in socketDir, through a
+// proxy container bind mounting it. It only works when the docker host is the local machine. The socket path is returned.
+func PublishSocket(ctx context.Context, hostClient *docker.Client, clusterName, socketDir string) (string, error) {
+	primaryNode, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the primary node informations: %w", err)
+	}
+
+	// The proxy forwards the TLS stream of the daemon as is, that clients of a unix socket don't expect.
+	if primaryNode.Labels[internal.TLSLabel] == "true" {
+		return "", ErrSocketTLS
+	}
+
+	networkName, primaryNodeEndpoint, err := internal.NodeNetwork(*primaryNode)
+	if err != nil {
+		return "", err
+	}
+
+	if err = os.MkdirAll(socketDir, 0700); err != nil {
+		return "", fmt.Errorf("unable to create the socket directory: %w", err)
+	}
+
+	if err = ensureImage(ctx, hostClient, internal.DefaultProxyImageName, false, nil); err != nil {
+		return "", fmt.Errorf("unable to get proxy image: %w", err)
+	}
+
+	proxyCfg := internal.SocketProxyConfig{
+		ClusterName: clusterName,
+		ImageRef:    internal.DefaultProxyImageName,
+		NetworkID:   primaryNodeEndpoint.NetworkID,
+		NetworkName: networkName,
+		SocketDir:   socketDir,
+		SocketUID:   os.Getuid(),
+		TargetHost:  primaryNodeEndpoint.IPAddress,
+		Labels:      creationLabels(time.Now()),
+	}
+
+	if _, err = internal.CreateSocketProxy(ctx, hostClient, proxyCfg); err != nil {
+		return "", fmt.Errorf("unable to create the socket proxy: %w", err)
+	}
+
+	return ClusterSocketPath(socketDir, clusterName), nil
+}
@@ -0,0 +1,93 @@
+package sind
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPortProxyConfig(t *testing.T) {
+	primary := types.Container{
+		ID:     "primary",
+		Labels: map[string]string{internal.NetworkNameLabel: "sind-test"},
+		NetworkSettings: &types.SummaryNetworkSettings{
+			Networks: map[string]*network.EndpointSettings{
+				"sind-test": {NetworkID: "net", IPAddress: "10.0.117.2"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		desc          string
+		primary       types.Container
+		target        string
+		expected      internal.ProxyConfig
+		expectedError bool
+	}{
+		{
+			desc:    "targeting the primary node",
+			primary: primary,
+			expected: internal.ProxyConfig{
+				ClusterName: "test",
+				ImageRef:    internal.DefaultProxyImageName,
+				NetworkID:   "net",
+				NetworkName: "sind-test",
+				HostPort:    8080,
+				TargetHost:  "10.0.117.2",
+				TargetPort:  80,
+			},
+		},
+		{
+			desc:    "targeting another node",
+			primary: primary,
+			target:  "10.0.117.3",
+			expected: internal.ProxyConfig{
+				ClusterName: "test",
+				ImageRef:    internal.DefaultProxyImageName,
+				NetworkID:   "net",
+				NetworkName: "sind-test",
+				HostPort:    8080,
+				TargetHost:  "10.0.117.3",
+				TargetPort:  80,
+			},
+		},
+		{
+			desc:          "with a primary node without network",
+			primary:       types.Container{ID: "primary"},
+			expectedError: true,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			cfg, err := portProxyConfig("test", test.primary, 8080, 80, test.target)
+			if test.expectedError {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, cfg)
+		})
+	}
+}
+
+func TestDefaultSocketDir(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	dir, err := DefaultSocketDir()
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".sind"), dir)
+}
+
+func TestClusterSocketPath(t *testing.T) {
+	assert.Equal(t, filepath.Join("sockets", "test.sock"), ClusterSocketPath("sockets", "test"))
+	assert.Equal(t, filepath.Join("sockets", "team.test.sock"), ClusterSocketPath("sockets", NamespacedName("team", "test")))
+}
@@ -3,69 +3,154 @@ package sind
 import (
 	"context"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/sind/internal"
 )
 
-// PushImageRefs pushes given refs to all node of a cluster.
+// PushImageRefs pushes given refs to all node of a cluster. The images are streamed from the host daemon to the nodes,
+// without being stored on the host.
 func PushImageRefs(ctx context.Context, hostClient *docker.Client, clusterName string, jobs int, refs []string) error {
-	imagesFile, err := ioutil.TempFile(os.TempDir(), "sind_images")
-	if err != nil {
-		return fmt.Errorf("unable to create a temporary archive file: %v", err)
-	}
+	ctx, span := internal.StartSpan(ctx, "sind.push", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := pushClusterImageRefs(ctx, hostClient, clusterName, jobs, refs)
 
-	defer os.Remove(imagesFile.Name())
-	defer imagesFile.Close()
+	span.End(err)
+
+	return err
+}
 
-	if err = internal.SaveImages(ctx, hostClient, imagesFile, refs); err != nil {
-		return fmt.Errorf("unable to save images to file: %v", err)
+func pushClusterImageRefs(ctx context.Context, hostClient *docker.Client, clusterName string, jobs int, refs []string) error {
+	containers, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list cluster %q containers: %w", clusterName, err)
 	}
 
-	return PushImageFile(ctx, hostClient, clusterName, jobs, imagesFile)
+	return pushImageRefs(ctx, hostClient, containers, jobs, refs)
 }
 
 // PushImageFile pushes a given image archive file on all the nodes of a given Cluster.
 func PushImageFile(ctx context.Context, hostClient *docker.Client, clusterName string, jobs int, file *os.File) error {
-	containers, err := internal.ListContainers(ctx, hostClient, clusterName)
-	if err != nil {
-		return fmt.Errorf("unable to list cluster %q containers: %v", clusterName, err)
-	}
+	ctx, span := internal.StartSpan(ctx, "sind.push", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := pushClusterImageFile(ctx, hostClient, clusterName, jobs, file)
 
-	archiveFile, err := ioutil.TempFile(os.TempDir(), "sind_archive")
+	span.End(err)
+
+	return err
+}
+
+func pushClusterImageFile(ctx context.Context, hostClient *docker.Client, clusterName string, jobs int, file *os.File) error {
+	containers, err := internal.ListNodes(ctx, hostClient, clusterName)
 	if err != nil {
-		return fmt.Errorf("unable to create a temporary archive file: %v", err)
+		return fmt.Errorf("unable to list cluster %q containers: %w", clusterName, err)
 	}
 
-	defer os.Remove(archiveFile.Name())
-	defer archiveFile.Close()
+	return pushImageFile(ctx, hostClient, containers, jobs, file)
+}
+
+// pushChunkSize is the size of the parts the images archive is streamed to the nodes in, see internal.TarStream.
+const pushChunkSize = 32 << 20
 
-	if err = internal.TarFile(file, archiveFile); err != nil {
-		return fmt.Errorf("unable to tar file: %v", err)
-	}
+func pushImageRefs(ctx context.Context, hostClient *docker.Client, containers []types.Container, jobs int, refs []string) error {
+	// Each batch of nodes is streamed its own save of the images, nothing is stored on the host.
+	return pushImages(ctx, hostClient, containers, jobs, "sind_images", func(ctx context.Context) (io.ReadCloser, error) {
+		content, err := hostClient.ImageSave(ctx, refs)
+		if err != nil {
+			return nil, fmt.Errorf("unable to save the images: %w", err)
+		}
+
+		return content, nil
+	})
+}
 
-	if err = internal.CopyToContainers(ctx, hostClient, containers, jobs, archiveFile.Name(), "/"); err != nil {
-		return fmt.Errorf("unable to copy content to containers: %v", err)
+func pushImageFile(ctx context.Context, hostClient *docker.Client, containers []types.Container, jobs int, file *os.File) error {
+	return pushImages(ctx, hostClient, containers, jobs, filepath.Base(file.Name()), func(context.Context) (io.ReadCloser, error) {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return nil, fmt.Errorf("unable to seek the images file: %w", err)
+		}
+
+		return ioutil.NopCloser(file), nil
+	})
+}
+
+// pushImages streams the images archive read from open to the nodes, then loads it on their daemon.
+func pushImages(
+	ctx context.Context,
+	hostClient *docker.Client,
+	containers []types.Container,
+	jobs int,
+	name string,
+	open func(context.Context) (io.ReadCloser, error),
+) (err error) {
+	archivePath := pushArchivePath(name, time.Now())
+
+	// A failed or interrupted push would leave the archive on the nodes filesystem.
+	defer func() {
+		if err != nil {
+			removeNodesArchive(hostClient, containers, jobs, archivePath)
+		}
+	}()
+
+	err = tracePhase(ctx, "sind.push.copy", nil, func(ctx context.Context) error {
+		return internal.StreamToContainers(ctx, hostClient, containers, jobs, "/", func(ctx context.Context, dest io.Writer) error {
+			content, err := open(ctx)
+			if err != nil {
+				return err
+			}
+
+			defer content.Close()
+
+			return internal.TarStream(content, path.Base(archivePath), pushChunkSize, dest)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("unable to copy content to containers: %w", err)
 	}
 
-	err = internal.ExecContainers(
-		ctx,
-		hostClient,
-		containers,
-		jobs,
-		[]string{
-			"docker",
-			"load",
-			"-i",
-			filepath.Join("/", filepath.Base(file.Name())),
-		},
-	)
+	// The archive is removed once loaded, repeated pushes would fill the node filesystem otherwise.
+	err = tracePhase(ctx, "sind.push.load", nil, func(ctx context.Context) error {
+		return internal.ExecContainers(
+			ctx,
+			hostClient,
+			containers,
+			jobs,
+			loadArchiveCmd(archivePath),
+		)
+	})
 	if err != nil {
-		return fmt.Errorf("unable to load image on nodes daemons: %v", err)
+		return fmt.Errorf("unable to load image on nodes daemons: %w", err)
 	}
 
 	return nil
 }
+
+// pushArchivePath returns the path of the images archive of a push on the nodes. Nodes are linux containers, the path
+// is slash separated whatever the host OS is.
+func pushArchivePath(name string, now time.Time) string {
+	return path.Join("/", fmt.Sprintf("%s_%d", name, now.UnixNano()))
+}
+
+// loadArchiveCmd returns the command loading the images archive streamed in parts at archivePath, then removing it.
+func loadArchiveCmd(archivePath string) []string {
+	return []string{"sh", "-c", fmt.Sprintf("cat %[1]s/* | docker load && rm -rf %[1]s", archivePath)}
+}
+
+// archiveCleanupTimeout bounds the removal of the archive of a failed push from the nodes.
+const archiveCleanupTimeout = 30 * time.Second
+
+// removeNodesArchive removes the archive of a failed push from the nodes, on its own context as the one of the push
+// may be canceled.
+func removeNodesArchive(hostClient *docker.Client, containers []types.Container, jobs int, archivePath string) {
+	ctx, cancel := context.WithTimeout(context.Background(), archiveCleanupTimeout)
+	defer cancel()
+
+	_ = internal.ExecContainers(ctx, hostClient, containers, jobs, []string{"rm", "-rf", archivePath})
+}
@@ -0,0 +1,23 @@
+package sind
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushArchivePath(t *testing.T) {
+	now := time.Unix(0, 1234)
+
+	assert.Equal(t, "/sind_images_1234", pushArchivePath("sind_images", now))
+	assert.Equal(t, "/images.tar_1234", pushArchivePath("images.tar", now))
+}
+
+func TestLoadArchiveCmd(t *testing.T) {
+	assert.Equal(
+		t,
+		[]string{"sh", "-c", "cat /sind_images_1234/* | docker load && rm -rf /sind_images_1234"},
+		loadArchiveCmd("/sind_images_1234"),
+	)
+}
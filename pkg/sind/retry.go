@@ -0,0 +1,19 @@
+package sind
+
+import (
+	"context"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// RetryPolicy configures the retries of the docker API operations on nodes (create, start, exec, copy) failing with a
+// transient error, such as an EOF or a connection reset from a node daemon.
+type RetryPolicy = internal.RetryPolicy
+
+// DefaultRetryPolicy is the retry policy used unless another one is set with WithRetryPolicy.
+var DefaultRetryPolicy = internal.DefaultRetryPolicy
+
+// WithRetryPolicy returns a context applying given retry policy to the operations it is passed to.
+func WithRetryPolicy(ctx context.Context, policy RetryPolicy) context.Context {
+	return internal.WithRetryPolicy(ctx, policy)
+}
@@ -0,0 +1,116 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// SaveImageRefs writes an archive of given images of a node of a cluster to dest, as docker save does, e.g. to retrieve
+// images built inside the cluster. The node is designated by its name, e.g. worker-0, the primary node if empty.
+func SaveImageRefs(ctx context.Context, hostClient *docker.Client, clusterName, node string, refs []string, dest io.Writer) error {
+	ctx, span := internal.StartSpan(ctx, "sind.save", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := saveImageRefs(ctx, hostClient, clusterName, node, refs, dest)
+
+	span.End(err)
+
+	return err
+}
+
+// LoadImageRefs loads given images of a node of a cluster on the docker host, the reverse of PushImageRefs.
+// The node is designated by its name, e.g. worker-0, the primary node if empty.
+func LoadImageRefs(ctx context.Context, hostClient *docker.Client, clusterName, node string, refs []string) error {
+	ctx, span := internal.StartSpan(ctx, "sind.save", map[string]string{internal.ClusterAttribute: clusterName})
+
+	reader, writer := io.Pipe()
+	saved := make(chan error, 1)
+
+	go func() {
+		err := saveImageRefs(ctx, hostClient, clusterName, node, refs, writer)
+		_ = writer.CloseWithError(err)
+		saved <- err
+	}()
+
+	err := internal.LoadImages(ctx, hostClient, reader)
+	_ = reader.Close()
+
+	// The save error explains a load failing on an interrupted archive.
+	if saveErr := <-saved; saveErr != nil {
+		err = saveErr
+	}
+
+	span.End(err)
+
+	return err
+}
+
+func saveImageRefs(ctx context.Context, hostClient *docker.Client, clusterName, node string, refs []string, dest io.Writer) error {
+	if len(refs) == 0 {
+		return ErrNoImageRef
+	}
+
+	container, err := clusterNode(ctx, hostClient, clusterName, node)
+	if err != nil {
+		return err
+	}
+
+	// Nodes are linux containers, use a slash separated path whatever the host OS is.
+	archivePath := path.Join("/", fmt.Sprintf("sind_save_%d.tar", time.Now().UnixNano()))
+
+	err = tracePhase(ctx, "sind.save.save", nil, func(ctx context.Context) error {
+		_, err := internal.ExecOutput(ctx, hostClient, container.ID, append([]string{"docker", "save", "-o", archivePath}, refs...))
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("unable to save images %v on the node: %w", refs, err)
+	}
+
+	// The archive is removed once copied, repeated saves would fill the node filesystem otherwise.
+	defer func() {
+		_, _ = internal.ExecOutput(ctx, hostClient, container.ID, []string{"rm", "-f", archivePath})
+	}()
+
+	err = tracePhase(ctx, "sind.save.copy", nil, func(ctx context.Context) error {
+		return internal.CopyFileFromContainer(ctx, hostClient, container.ID, archivePath, dest)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to copy the images archive from the node: %w", err)
+	}
+
+	return nil
+}
+
+// clusterNode returns the node of a cluster with given name, the primary node if name is empty.
+func clusterNode(ctx context.Context, hostClient internal.ContainerLister, clusterName, name string) (types.Container, error) {
+	if name == "" {
+		primary, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+		if err != nil {
+			return types.Container{}, err
+		}
+
+		return *primary, nil
+	}
+
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return types.Container{}, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return types.Container{}, ErrClusterNotFound
+	}
+
+	selected := selectNodes(clusterName, nodes, []string{name})
+	if len(selected) == 0 {
+		return types.Container{}, fmt.Errorf("%w: %q", ErrNodeNotFound, name)
+	}
+
+	return selected[0], nil
+}
@@ -0,0 +1,56 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterNode(t *testing.T) {
+	primary := types.Container{
+		ID:     "primary",
+		Names:  []string{"/sind-foo-manager-0"},
+		Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary},
+	}
+	worker := types.Container{
+		ID:     "worker",
+		Names:  []string{"/sind-foo-worker-0"},
+		Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRoleWorker},
+	}
+
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		if opts.Filters.ExactMatch("label", internal.PrimaryNodeLabel()) {
+			return []types.Container{primary}, nil
+		}
+
+		return []types.Container{primary, worker}, nil
+	})
+
+	node, err := clusterNode(context.Background(), client, "foo", "worker-0")
+	require.NoError(t, err)
+	assert.Equal(t, worker, node)
+
+	node, err = clusterNode(context.Background(), client, "foo", "")
+	require.NoError(t, err)
+	assert.Equal(t, primary, node)
+
+	_, err = clusterNode(context.Background(), client, "foo", "worker-1")
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+}
+
+func TestClusterNodeNotFound(t *testing.T) {
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return nil, nil
+	})
+
+	_, err := clusterNode(context.Background(), client, "foo", "worker-0")
+	assert.True(t, errors.Is(err, ErrClusterNotFound))
+
+	_, err = clusterNode(context.Background(), client, "foo", "")
+	assert.True(t, errors.Is(err, ErrClusterNotFound))
+}
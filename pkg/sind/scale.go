@@ -0,0 +1,251 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// ScaleWorkers adds delta workers to a cluster, or removes -delta of them if delta is negative. See ScaleManagers.
+func ScaleWorkers(ctx context.Context, hostClient *docker.Client, clusterName string, delta int) error {
+	return scaleNodes(ctx, hostClient, clusterName, internal.NodeRoleWorker, delta)
+}
+
+// ScaleManagers adds delta managers to a cluster, or removes -delta of them if delta is negative. The primary node is
+// never removed.
+// Added nodes copy the configuration of the last node of their role, or of the primary node if there is none, then join
+// the swarm. Removed nodes, highest index first, are drained and demoted, leave the swarm and are removed from it before
+// their container is deleted. The nodes of a plain cluster are only created or deleted.
+func ScaleManagers(ctx context.Context, hostClient *docker.Client, clusterName string, delta int) error {
+	return scaleNodes(ctx, hostClient, clusterName, internal.NodeRoleManager, delta)
+}
+
+func scaleNodes(ctx context.Context, hostClient *docker.Client, clusterName, role string, delta int) error {
+	ctx, span := internal.StartSpan(ctx, "sind.scale", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := scale(ctx, hostClient, clusterName, role, delta)
+
+	span.End(err)
+
+	return err
+}
+
+func scale(ctx context.Context, hostClient *docker.Client, clusterName, role string, delta int) error {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list cluster nodes: %w", err)
+	}
+
+	primary, ok := primaryNode(nodes)
+	if !ok {
+		return ErrClusterNotFound
+	}
+
+	if _, external := primary.Labels[internal.ExternalSwarmLabel]; external {
+		return ErrScaleUnmanagedSwarm
+	}
+
+	if primary.State != "running" {
+		return fmt.Errorf("%w: the primary node of cluster %q is %s", ErrNodeNotRunning, clusterName, primary.State)
+	}
+
+	roleNodes := nodesByIndex(clusterName, nodes, role)
+
+	switch {
+	case delta > 0:
+		err = addNodes(ctx, hostClient, clusterName, primary, nodes, roleNodes, role, delta)
+	case delta < 0:
+		if -delta > len(roleNodes) {
+			return fmt.Errorf("%w: cluster %q has %d %s nodes to remove", ErrInvalidScale, clusterName, len(roleNodes), role)
+		}
+
+		err = removeNodes(ctx, hostClient, clusterName, primary, roleNodes[len(roleNodes)+delta:])
+	default:
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return reloadLoadBalancer(ctx, hostClient, clusterName)
+}
+
+// addNodes creates count nodes with given role, indexed after the existing ones, and makes them join the swarm.
+func addNodes(ctx context.Context, hostClient *docker.Client, clusterName string, primary types.Container, nodes, roleNodes []types.Container, role string, count int) error {
+	template, nextIndex := primary, 0
+
+	// The primary node is manager-0.
+	if role == internal.NodeRoleManager {
+		nextIndex = 1
+	}
+
+	if len(roleNodes) > 0 {
+		template = roleNodes[len(roleNodes)-1]
+		lastIndex, _ := nodeIndex(clusterName, template)
+		nextIndex = lastIndex + 1
+	}
+
+	indexes := make([]int, count)
+	for i := range indexes {
+		indexes[i] = nextIndex + i
+	}
+
+	var material *internal.TLSMaterial
+
+	// The new nodes get a server certificate issued by the CA kept in the primary node.
+	if primary.Labels[internal.TLSLabel] == "true" {
+		clusterTLS, err := internal.ReadTLS(ctx, hostClient, primary.ID)
+		if err != nil {
+			return err
+		}
+
+		if material, err = clusterTLS.Reissue(clusterName, nil); err != nil {
+			return fmt.Errorf("unable to issue the certificates of the new nodes: %w", err)
+		}
+	}
+
+	cIDs, err := internal.ReplicateNode(ctx, hostClient, template.ID, role, indexes, material)
+	if err != nil {
+		return fmt.Errorf("unable to create nodes: %w", err)
+	}
+
+	added := make([]types.Container, len(cIDs))
+	for i, cID := range cIDs {
+		added[i] = types.Container{ID: cID}
+	}
+
+	if err = internal.WaitNodesReady(ctx, hostClient, added); err != nil {
+		return fmt.Errorf("unable to wait for the daemon of the new nodes: %w", err)
+	}
+
+	if !managedSwarm(primary) {
+		return nil
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	swarmInfo, err := swarmClient.SwarmInspect(ctx)
+	if err != nil {
+		return fmt.Errorf("unable to get the swarm join tokens: %w", err)
+	}
+
+	swarmNodes, err := swarmClient.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return fmt.Errorf("unable to list the swarm nodes: %w", err)
+	}
+
+	clusterParams := internal.ClusterParams{
+		ManagerIPs:       internal.ManagerIPs(nodes),
+		ManagerJoinToken: swarmInfo.JoinTokens.Manager,
+		WorkerJoinToken:  swarmInfo.JoinTokens.Worker,
+	}
+
+	if role == internal.NodeRoleManager {
+		clusterParams.IDs.Managers = cIDs
+	} else {
+		clusterParams.IDs.Workers = cIDs
+	}
+
+	if err = internal.FormCluster(ctx, hostClient, clusterParams); err != nil {
+		return fmt.Errorf("unable to make the new nodes join the swarm: %w", err)
+	}
+
+	if err = internal.WaitSwarmNodesReady(ctx, swarmClient, len(swarmNodes)+len(cIDs)); err != nil {
+		return fmt.Errorf("unable to wait for the new nodes to be ready: %w", err)
+	}
+
+	return nil
+}
+
+// removeNodes makes given nodes leave the swarm gracefully, then deletes them.
+func removeNodes(ctx context.Context, hostClient *docker.Client, clusterName string, primary types.Container, removed []types.Container) error {
+	if managedSwarm(primary) {
+		swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+		if err != nil {
+			return err
+		}
+
+		defer swarmClient.Close()
+
+		hostnames := make([]string, len(removed))
+		for i, node := range removed {
+			hostnames[i] = containerName(node)
+		}
+
+		swarmNodeIDs, err := internal.DrainSwarmNodes(ctx, swarmClient, hostnames)
+		if err != nil {
+			return err
+		}
+
+		// Stopped nodes can't leave the swarm, they are only removed from it.
+		if err = internal.LeaveSwarm(ctx, hostClient, runningNodes(removed)); err != nil {
+			return err
+		}
+
+		if err = internal.RemoveSwarmNodes(ctx, swarmClient, swarmNodeIDs); err != nil {
+			return err
+		}
+	}
+
+	if err := internal.RemoveContainers(ctx, hostClient, removed, 0, true); err != nil {
+		return fmt.Errorf("unable to remove nodes: %w", err)
+	}
+
+	if err := internal.RemoveVolumes(ctx, hostClient, internal.NodeVolumes(removed)); err != nil {
+		return fmt.Errorf("unable to remove the volumes of the nodes: %w", err)
+	}
+
+	return nil
+}
+
+// nodesByIndex returns the nodes with given role, the primary node excluded, sorted by index.
+func nodesByIndex(clusterName string, nodes []types.Container, role string) []types.Container {
+	var result []types.Container
+
+	for _, node := range nodes {
+		if node.Labels[internal.NodeRoleLabel] != role {
+			continue
+		}
+
+		if _, ok := nodeIndex(clusterName, node); ok {
+			result = append(result, node)
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		iIndex, _ := nodeIndex(clusterName, result[i])
+		jIndex, _ := nodeIndex(clusterName, result[j])
+
+		return iIndex < jIndex
+	})
+
+	return result
+}
+
+// nodeIndex returns the index of a node, e.g. 2 for worker-2.
+func nodeIndex(clusterName string, node types.Container) (int, bool) {
+	if len(node.Names) == 0 {
+		return 0, false
+	}
+
+	name := nodeName(clusterName, node.Names[0])
+
+	index, err := strconv.Atoi(name[strings.LastIndex(name, "-")+1:])
+	if err != nil {
+		return 0, false
+	}
+
+	return index, true
+}
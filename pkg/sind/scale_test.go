@@ -0,0 +1,55 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodesByIndex(t *testing.T) {
+	node := func(name, role string) types.Container {
+		return types.Container{
+			ID:     name,
+			Names:  []string{"/sind-test-" + name},
+			Labels: map[string]string{internal.NodeRoleLabel: role},
+		}
+	}
+
+	nodes := []types.Container{
+		node("worker-10", internal.NodeRoleWorker),
+		node("manager-0", internal.NodeRolePrimary),
+		node("worker-2", internal.NodeRoleWorker),
+		node("manager-1", internal.NodeRoleManager),
+		node("worker-0", internal.NodeRoleWorker),
+		{ID: "unnamed", Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRoleWorker}},
+	}
+
+	assert.Equal(
+		t,
+		[]types.Container{
+			node("worker-0", internal.NodeRoleWorker),
+			node("worker-2", internal.NodeRoleWorker),
+			node("worker-10", internal.NodeRoleWorker),
+		},
+		nodesByIndex("test", nodes, internal.NodeRoleWorker),
+	)
+	assert.Equal(
+		t,
+		[]types.Container{node("manager-1", internal.NodeRoleManager)},
+		nodesByIndex("test", nodes, internal.NodeRoleManager),
+	)
+}
+
+func TestNodeIndex(t *testing.T) {
+	index, ok := nodeIndex("test-1", types.Container{Names: []string{"/sind-test-1-worker-12"}})
+	assert.True(t, ok)
+	assert.Equal(t, 12, index)
+
+	_, ok = nodeIndex("test", types.Container{Names: []string{"/sind-test-lb"}})
+	assert.False(t, ok)
+
+	_, ok = nodeIndex("test", types.Container{})
+	assert.False(t, ok)
+}
@@ -0,0 +1,130 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// NodeSelector selects a node of a cluster by role rather than by container name.
+type NodeSelector struct {
+	// Role is the role of the node, manager (which includes the primary node) or worker. Any node if empty.
+	Role string
+	// Index is the index of the node among the nodes with the selected role, in the order of their names.
+	Index int
+	// Leader selects the manager leading the swarm, Role and Index are ignored.
+	Leader bool
+}
+
+func (s NodeSelector) validate() error {
+	switch s.Role {
+	case "", internal.NodeRoleManager, internal.NodeRoleWorker:
+	default:
+		return fmt.Errorf("%w: %q", ErrInvalidNodeRole, s.Role)
+	}
+
+	if s.Index < 0 {
+		return ErrInvalidNodeIndex
+	}
+
+	return nil
+}
+
+// SelectNode returns the container of the node of a cluster matching given selector.
+func SelectNode(ctx context.Context, hostClient *docker.Client, clusterName string, selector NodeSelector) (types.Container, error) {
+	if err := selector.validate(); err != nil {
+		return types.Container{}, err
+	}
+
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return types.Container{}, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return types.Container{}, ErrClusterNotFound
+	}
+
+	if !selector.Leader {
+		return selectNode(nodes, selector)
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return types.Container{}, err
+	}
+
+	defer swarmClient.Close()
+
+	managers, err := swarmClient.NodeList(ctx, types.NodeListOptions{Filters: filters.NewArgs(filters.Arg("role", "manager"))})
+	if err != nil {
+		return types.Container{}, fmt.Errorf("unable to list the swarm managers: %w", err)
+	}
+
+	for _, manager := range managers {
+		if manager.ManagerStatus != nil && manager.ManagerStatus.Leader {
+			return leaderNode(nodes, manager.Description.Hostname)
+		}
+	}
+
+	return types.Container{}, fmt.Errorf("%w: the swarm has no leader", ErrNodeNotFound)
+}
+
+// selectNode returns the node matching the role and index of a selector.
+func selectNode(nodes []types.Container, selector NodeSelector) (types.Container, error) {
+	var candidates []types.Container
+
+	for _, node := range nodes {
+		if selector.Role == "" || selector.Role == selectorRole(node) {
+			candidates = append(candidates, node)
+		}
+	}
+
+	// Managers first, then shorter names first for manager-10 to come after manager-2.
+	sort.Slice(candidates, func(i, j int) bool {
+		roleI, roleJ := selectorRole(candidates[i]), selectorRole(candidates[j])
+		if roleI != roleJ {
+			return roleI == internal.NodeRoleManager
+		}
+
+		nameI, nameJ := containerName(candidates[i]), containerName(candidates[j])
+		if len(nameI) != len(nameJ) {
+			return len(nameI) < len(nameJ)
+		}
+
+		return nameI < nameJ
+	})
+
+	if selector.Index >= len(candidates) {
+		return types.Container{}, fmt.Errorf("%w: %d node(s) with role %q, no node at index %d", ErrNodeNotFound, len(candidates), selector.Role, selector.Index)
+	}
+
+	return candidates[selector.Index], nil
+}
+
+// selectorRole returns the role of a node as selected by a NodeSelector, the primary node being a manager.
+func selectorRole(node types.Container) string {
+	if role := node.Labels[internal.NodeRoleLabel]; role != internal.NodeRolePrimary {
+		return role
+	}
+
+	return internal.NodeRoleManager
+}
+
+// leaderNode returns the node which hostname is the one of the swarm leader, the hostname of a container being either
+// its name or the prefix of its ID.
+func leaderNode(nodes []types.Container, hostname string) (types.Container, error) {
+	for _, node := range nodes {
+		if containerName(node) == hostname || strings.HasPrefix(node.ID, hostname) {
+			return node, nil
+		}
+	}
+
+	return types.Container{}, fmt.Errorf("%w: the swarm leader %q is not a node of the cluster", ErrNodeNotFound, hostname)
+}
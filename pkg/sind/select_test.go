@@ -0,0 +1,77 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectNode(t *testing.T) {
+	node := func(name, role string) types.Container {
+		return types.Container{
+			ID:     name + "-id",
+			Names:  []string{"/sind-test-" + name},
+			Labels: map[string]string{internal.NodeRoleLabel: role},
+		}
+	}
+
+	nodes := []types.Container{
+		node("worker-10", internal.NodeRoleWorker),
+		node("manager-1", internal.NodeRoleManager),
+		node("worker-2", internal.NodeRoleWorker),
+		node("manager-0", internal.NodeRolePrimary),
+		node("worker-0", internal.NodeRoleWorker),
+		node("worker-1", internal.NodeRoleWorker),
+	}
+
+	testCases := []struct {
+		desc         string
+		selector     NodeSelector
+		expectedNode string
+	}{
+		{desc: "first node", selector: NodeSelector{}, expectedNode: "manager-0-id"},
+		{desc: "primary as a manager", selector: NodeSelector{Role: internal.NodeRoleManager}, expectedNode: "manager-0-id"},
+		{desc: "second manager", selector: NodeSelector{Role: internal.NodeRoleManager, Index: 1}, expectedNode: "manager-1-id"},
+		{desc: "third worker", selector: NodeSelector{Role: internal.NodeRoleWorker, Index: 2}, expectedNode: "worker-2-id"},
+		{desc: "fourth worker", selector: NodeSelector{Role: internal.NodeRoleWorker, Index: 3}, expectedNode: "worker-10-id"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			selected, err := selectNode(nodes, test.selector)
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedNode, selected.ID)
+		})
+	}
+
+	_, err := selectNode(nodes, NodeSelector{Role: internal.NodeRoleManager, Index: 2})
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+}
+
+func TestNodeSelectorValidation(t *testing.T) {
+	assert.True(t, errors.Is(NodeSelector{Role: internal.NodeRolePrimary}.validate(), ErrInvalidNodeRole))
+	assert.True(t, errors.Is(NodeSelector{Index: -1}.validate(), ErrInvalidNodeIndex))
+	assert.NoError(t, NodeSelector{Role: internal.NodeRoleWorker, Index: 1}.validate())
+}
+
+func TestLeaderNode(t *testing.T) {
+	nodes := []types.Container{
+		{ID: "aaa", Names: []string{"/sind-test-manager-0"}},
+		{ID: "0123456789abcdef", Names: []string{"/adopted"}},
+	}
+
+	leader, err := leaderNode(nodes, "sind-test-manager-0")
+	require.NoError(t, err)
+	assert.Equal(t, "aaa", leader.ID)
+
+	leader, err = leaderNode(nodes, "0123456789ab")
+	require.NoError(t, err)
+	assert.Equal(t, "0123456789abcdef", leader.ID)
+
+	_, err = leaderNode(nodes, "unknown")
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+}
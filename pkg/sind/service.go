@@ -0,0 +1,152 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// Service is a service deployed on a cluster.
+type Service = internal.Service
+
+// ServiceTask is a task of a service deployed on a cluster.
+type ServiceTask = internal.ServiceTask
+
+// ListServices returns the services deployed on a cluster, sorted by name.
+func ListServices(ctx context.Context, hostClient *docker.Client, clusterName string) ([]Service, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.ListServices(ctx, swarmClient)
+}
+
+// ServiceTasks returns the tasks of a service deployed on a cluster, sorted by slot then by most recent update.
+func ServiceTasks(ctx context.Context, hostClient *docker.Client, clusterName, service string) ([]ServiceTask, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.ServiceTasks(ctx, swarmClient, service)
+}
+
+// InspectService returns the raw swarm representation of a service deployed on a cluster.
+func InspectService(ctx context.Context, hostClient *docker.Client, clusterName, service string) (swarm.Service, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return swarm.Service{}, err
+	}
+
+	defer swarmClient.Close()
+
+	result, _, err := swarmClient.ServiceInspectWithRaw(ctx, service, types.ServiceInspectOptions{})
+	if err != nil {
+		return swarm.Service{}, fmt.Errorf("unable to inspect service %q: %w", service, err)
+	}
+
+	return result, nil
+}
+
+// RemoveService removes a service deployed on a cluster.
+func RemoveService(ctx context.Context, hostClient *docker.Client, clusterName, service string) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	if err = swarmClient.ServiceRemove(ctx, service); err != nil {
+		return fmt.Errorf("unable to remove service %q: %w", service, err)
+	}
+
+	return nil
+}
+
+// ScaleService sets the replicas of a service deployed on the cluster, and waits for all of them to be running.
+func ScaleService(ctx context.Context, hostClient *docker.Client, clusterName, service string, replicas uint64) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.ScaleService(ctx, swarmClient, service, replicas)
+}
+
+// UpdateServiceImage updates the image of a service deployed on the cluster, and waits for the rollout to complete.
+// It returns an ErrServiceUpdateFailed error if the update is paused or rolled back.
+func UpdateServiceImage(ctx context.Context, hostClient *docker.Client, clusterName, service, image string) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.UpdateServiceImage(ctx, swarmClient, service, image)
+}
+
+// ServiceEndpoint returns the address (host:port) at which the port targetPort of a service deployed on the cluster is
+// reachable from the docker host. The service must publish the port on the swarm ingress, which must be bound on the
+// docker host by the primary node, the load balancer or a port proxy (see PublishPort). When it is not, the ingress is
+// reached directly on the primary node address, for networks without NAT.
+func ServiceEndpoint(ctx context.Context, hostClient *docker.Client, clusterName, service string, targetPort uint16) (string, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	defer swarmClient.Close()
+
+	publishedPort, err := internal.PublishedPort(ctx, swarmClient, service, targetPort)
+	if err != nil {
+		return "", err
+	}
+
+	containers, err := internal.ListContainers(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", fmt.Errorf("unable to list cluster containers: %w", err)
+	}
+
+	return serviceEndpoint(hostClient, containers, uint16(publishedPort.PublishedPort), string(publishedPort.Protocol))
+}
+
+func serviceEndpoint(hostClient *docker.Client, containers []types.Container, ingressPort uint16, protocol string) (string, error) {
+	if hostPort, ok := internal.HostPort(containers, ingressPort, protocol); ok {
+		host, err := internal.SwarmHost(hostClient)
+		if err != nil {
+			return "", fmt.Errorf("unable to get the docker host: %w", err)
+		}
+
+		return net.JoinHostPort(host, strconv.Itoa(int(hostPort))), nil
+	}
+
+	for _, container := range containers {
+		if container.Labels[internal.NodeRoleLabel] != internal.NodeRolePrimary {
+			continue
+		}
+
+		_, endpoint, err := internal.NodeNetwork(container)
+		if err != nil {
+			return "", err
+		}
+
+		return net.JoinHostPort(endpoint.IPAddress, strconv.Itoa(int(ingressPort))), nil
+	}
+
+	return "", ErrClusterNotFound
+}
@@ -0,0 +1,59 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServiceEndpoint(t *testing.T) {
+	hostClient, err := docker.NewClientWithOpts(docker.WithHost("unix:///var/run/docker.sock"))
+	require.NoError(t, err)
+
+	primary := types.Container{
+		Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary},
+		Ports:  []types.Port{{PrivatePort: 2375, PublicPort: 32768, Type: "tcp"}},
+		NetworkSettings: &types.SummaryNetworkSettings{
+			Networks: map[string]*network.EndpointSettings{"net": {IPAddress: "10.0.0.2"}},
+		},
+	}
+	proxy := types.Container{
+		Labels: map[string]string{internal.ComponentLabel: internal.ComponentPortProxy},
+		Ports:  []types.Port{{PrivatePort: 8080, PublicPort: 80, Type: "tcp"}},
+	}
+
+	testCases := []struct {
+		desc             string
+		containers       []types.Container
+		expectedEndpoint string
+		expectedError    error
+	}{
+		{
+			desc:             "bound on the docker host",
+			containers:       []types.Container{primary, proxy},
+			expectedEndpoint: "localhost:80",
+		},
+		{
+			desc:             "not bound on the docker host",
+			containers:       []types.Container{primary},
+			expectedEndpoint: "10.0.0.2:8080",
+		},
+		{
+			desc:          "without primary node",
+			expectedError: ErrClusterNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			endpoint, err := serviceEndpoint(hostClient, test.containers, 8080, "tcp")
+			assert.Equal(t, test.expectedError, err)
+			assert.Equal(t, test.expectedEndpoint, endpoint)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package sind
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ClusterSpec is the declarative configuration of a cluster, loaded from a YAML file by LoadClusterSpec, for clusters
+// to be reproducible across machines.
+type ClusterSpec struct {
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace"`
+
+	Managers uint16 `yaml:"managers"`
+	Workers  uint16 `yaml:"workers"`
+
+	Image        string   `yaml:"image"`
+	Pull         bool     `yaml:"pull"`
+	DaemonArgs   []string `yaml:"daemon_args"`
+	Ports        []string `yaml:"ports"`
+	LoadBalancer bool     `yaml:"load_balancer"`
+	Plain        bool     `yaml:"plain"`
+	TLS          bool     `yaml:"tls"`
+
+	Network NetworkSpec `yaml:"network"`
+
+	// Metadata are the user defined labels attached to the cluster.
+	Metadata    map[string]string `yaml:"metadata"`
+	TTL         time.Duration     `yaml:"ttl"`
+	IdleTimeout time.Duration     `yaml:"idle_timeout"`
+
+	Swarm SwarmSpec `yaml:"swarm"`
+}
+
+// NetworkSpec is the declarative configuration of the network of a cluster.
+type NetworkSpec struct {
+	Name string `yaml:"name"`
+	// Existing is the ID or the name of an existing network to attach the nodes to, instead of creating one.
+	Existing string            `yaml:"existing"`
+	Driver   string            `yaml:"driver"`
+	Options  map[string]string `yaml:"options"`
+	Subnet   string            `yaml:"subnet"`
+	Gateway  string            `yaml:"gateway"`
+	IPRange  string            `yaml:"ip_range"`
+}
+
+// SwarmSpec is the declarative configuration of the swarm of a cluster.
+type SwarmSpec struct {
+	ManagerAvailability string `yaml:"manager_availability"`
+	WorkerAvailability  string `yaml:"worker_availability"`
+
+	Networks []SwarmNetworkSpec `yaml:"networks"`
+	// Secrets and Configs map the names of the secrets and the configs to the files holding their data, relative to
+	// the spec file.
+	Secrets map[string]string `yaml:"secrets"`
+	Configs map[string]string `yaml:"configs"`
+	// NodeLabels maps the names of the nodes, e.g. manager-0 or worker-2, to the labels added to their swarm node.
+	NodeLabels map[string]map[string]string `yaml:"node_labels"`
+}
+
+// SwarmNetworkSpec is the declarative configuration of an overlay network of a swarm.
+type SwarmNetworkSpec struct {
+	Name       string `yaml:"name"`
+	Attachable bool   `yaml:"attachable"`
+	Internal   bool   `yaml:"internal"`
+	Subnet     string `yaml:"subnet"`
+}
+
+// LoadClusterSpec loads the cluster spec of the YAML file at path, rejecting unknown fields. The paths of the files of
+// the secrets and configs are resolved relatively to the spec file.
+func LoadClusterSpec(path string) (*ClusterSpec, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read cluster spec: %w", err)
+	}
+
+	var spec ClusterSpec
+
+	if err = yaml.UnmarshalStrict(content, &spec); err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrInvalidClusterSpec, path, err)
+	}
+
+	dir := filepath.Dir(path)
+
+	resolvePaths(dir, spec.Swarm.Secrets)
+	resolvePaths(dir, spec.Swarm.Configs)
+
+	return &spec, nil
+}
+
+func resolvePaths(dir string, paths map[string]string) {
+	for name, path := range paths {
+		if !filepath.IsAbs(path) {
+			paths[name] = filepath.Join(dir, path)
+		}
+	}
+}
+
+// Configuration returns the configuration of the cluster declared by the spec, reading the files of its secrets and
+// configs. The network of the cluster is named after the cluster unless set.
+func (s *ClusterSpec) Configuration() (ClusterConfiguration, error) {
+	config := ClusterConfiguration{
+		ClusterName:  s.Name,
+		Namespace:    s.Namespace,
+		NetworkName:  s.Network.Name,
+		Managers:     s.Managers,
+		Workers:      s.Workers,
+		ImageName:    s.Image,
+		PullImage:    s.Pull,
+		DaemonArgs:   s.DaemonArgs,
+		PortBindings: s.Ports,
+		LoadBalancer: s.LoadBalancer,
+		Plain:        s.Plain,
+		TLS:          s.TLS,
+		Metadata:     s.Metadata,
+		TTL:          s.TTL,
+		IdleTimeout:  s.IdleTimeout,
+
+		ExistingNetwork:      s.Network.Existing,
+		NetworkDriver:        s.Network.Driver,
+		NetworkDriverOptions: s.Network.Options,
+		NetworkSubnet:        s.Network.Subnet,
+		NetworkGateway:       s.Network.Gateway,
+		NetworkIPRange:       s.Network.IPRange,
+
+		ManagerAvailability: s.Swarm.ManagerAvailability,
+		WorkerAvailability:  s.Swarm.WorkerAvailability,
+	}
+
+	if config.NetworkName == "" && config.ExistingNetwork == "" {
+		config.NetworkName = "sind-" + s.Name
+	}
+
+	var err error
+
+	for _, network := range s.Swarm.Networks {
+		config.Bootstrap.Networks = append(config.Bootstrap.Networks, BootstrapNetwork(network))
+	}
+
+	if config.Bootstrap.Secrets, err = readSpecFiles(s.Swarm.Secrets); err != nil {
+		return config, fmt.Errorf("unable to read secret: %w", err)
+	}
+
+	if config.Bootstrap.Configs, err = readSpecFiles(s.Swarm.Configs); err != nil {
+		return config, fmt.Errorf("unable to read config: %w", err)
+	}
+
+	config.Bootstrap.NodeLabels = s.Swarm.NodeLabels
+
+	return config, nil
+}
+
+func readSpecFiles(paths map[string]string) (map[string][]byte, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	files := make(map[string][]byte, len(paths))
+
+	for name, path := range paths {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		files[name] = data
+	}
+
+	return files, nil
+}
@@ -0,0 +1,103 @@
+package sind
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const clusterSpec = `
+name: ci
+managers: 3
+workers: 2
+image: docker:20.10-dind
+daemon_args:
+  - --registry-mirror=https://mirror.local
+ports:
+  - 8080:80
+load_balancer: true
+network:
+  driver: bridge
+  subnet: 10.10.0.0/24
+metadata:
+  team: infra
+ttl: 2h
+swarm:
+  worker_availability: drain
+  networks:
+    - name: backend
+      attachable: true
+  secrets:
+    token: token.txt
+  node_labels:
+    worker-0:
+      zone: a
+`
+
+func writeClusterSpec(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "sind-spec")
+	require.NoError(t, err)
+
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "cluster.yaml")
+
+	require.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "token.txt"), []byte("hunter2"), 0600))
+
+	return path
+}
+
+func TestLoadClusterSpec(t *testing.T) {
+	path := writeClusterSpec(t, clusterSpec)
+
+	spec, err := LoadClusterSpec(path)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(filepath.Dir(path), "token.txt"), spec.Swarm.Secrets["token"])
+
+	config, err := spec.Configuration()
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		ClusterConfiguration{
+			ClusterName:  "ci",
+			NetworkName:  "sind-ci",
+			Managers:     3,
+			Workers:      2,
+			ImageName:    "docker:20.10-dind",
+			DaemonArgs:   []string{"--registry-mirror=https://mirror.local"},
+			PortBindings: []string{"8080:80"},
+			LoadBalancer: true,
+			Metadata:     map[string]string{"team": "infra"},
+			TTL:          2 * time.Hour,
+
+			NetworkDriver: "bridge",
+			NetworkSubnet: "10.10.0.0/24",
+
+			WorkerAvailability: "drain",
+
+			Bootstrap: Bootstrap{
+				Networks:   []BootstrapNetwork{{Name: "backend", Attachable: true}},
+				Secrets:    map[string][]byte{"token": []byte("hunter2")},
+				NodeLabels: map[string]map[string]string{"worker-0": {"zone": "a"}},
+			},
+		},
+		config,
+	)
+	assert.NoError(t, config.validate())
+}
+
+func TestLoadClusterSpecRejectsUnknownFields(t *testing.T) {
+	_, err := LoadClusterSpec(writeClusterSpec(t, "name: ci\nmanager: 3\n"))
+
+	assert.True(t, errors.Is(err, ErrInvalidClusterSpec))
+	assert.True(t, errors.Is(err, ErrInvalidConfiguration))
+}
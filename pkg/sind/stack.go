@@ -0,0 +1,90 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// Stack is a stack deployed on a cluster, e.g. with docker stack deploy against the cluster host.
+type Stack = internal.Stack
+
+// ListStacks returns the stacks deployed on a cluster, sorted by name.
+func ListStacks(ctx context.Context, hostClient *docker.Client, clusterName string) ([]Stack, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.ListStacks(ctx, swarmClient)
+}
+
+// DeployStack deploys a stack on a cluster from the content of a compose file, or updates it if it is already
+// deployed. The stack is deployed by the docker CLI of the primary node, the images it uses must be pullable from the
+// nodes or pushed to them beforehand.
+func DeployStack(ctx context.Context, hostClient *docker.Client, clusterName, stackName string, compose []byte) error {
+	primary, err := internal.PrimaryContainer(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	return internal.DeployStack(ctx, hostClient, primary.ID, stackName, compose)
+}
+
+// StackTasks returns the tasks of the services of a stack deployed on a cluster, sorted by service, slot then by most
+// recent update.
+func StackTasks(ctx context.Context, hostClient *docker.Client, clusterName, stackName string) ([]ServiceTask, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	tasks, err := internal.StackTasks(ctx, swarmClient, stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	if tasks == nil {
+		return nil, fmt.Errorf("%w: %q", ErrStackNotFound, stackName)
+	}
+
+	return tasks, nil
+}
+
+// RemoveStack removes a stack deployed on a cluster, with its services, networks, secrets and configs.
+func RemoveStack(ctx context.Context, hostClient *docker.Client, clusterName, stackName string) error {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	stacks, err := internal.ListStacks(ctx, swarmClient)
+	if err != nil {
+		return err
+	}
+
+	if !hasStack(stacks, stackName) {
+		return fmt.Errorf("%w: %q", ErrStackNotFound, stackName)
+	}
+
+	return internal.RemoveStack(ctx, swarmClient, stackName)
+}
+
+// hasStack returns true if a stack with given name is among stacks.
+func hasStack(stacks []Stack, name string) bool {
+	for _, stack := range stacks {
+		if stack.Name == name {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,29 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasStack(t *testing.T) {
+	stacks := []Stack{{Name: "front"}, {Name: "back"}}
+
+	testCases := []struct {
+		desc     string
+		name     string
+		expected bool
+	}{
+		{desc: "with a deployed stack", name: "back", expected: true},
+		{desc: "with an unknown stack", name: "monitoring"},
+		{desc: "with a stack name prefix", name: "fro"},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, hasStack(stacks, test.name))
+		})
+	}
+
+	assert.False(t, hasStack(nil, "front"))
+}
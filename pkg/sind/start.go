@@ -4,16 +4,81 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/sind/internal"
 )
 
-// StartCluster starts all nodes of a cluster.
+// StartCluster starts all nodes of a cluster and waits for their daemon to be ready. The swarm of a cluster which
+// formed its own is then waited for to converge, once the availability of the nodes drained when it was stopped is
+// restored. Unless bound to a fixed port, the daemon port of the primary node is published on a new host port, see
+// ClusterHost.
 func StartCluster(ctx context.Context, hostClient *docker.Client, clusterName string) error {
+	ctx, span := internal.StartSpan(ctx, "sind.start", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err := startCluster(ctx, hostClient, clusterName)
+
+	span.End(err)
+
+	return err
+}
+
+func startCluster(ctx context.Context, hostClient *docker.Client, clusterName string) error {
 	containers, err := internal.ListContainers(ctx, hostClient, clusterName)
 	if err != nil {
-		return fmt.Errorf("unable to get container list %v", err)
+		return fmt.Errorf("unable to get container list %w", err)
+	}
+
+	if err = internal.StartContainers(ctx, hostClient, containers); err != nil {
+		return err
+	}
+
+	primary, ok := primaryNode(containers)
+	if !ok {
+		return nil
+	}
+
+	if err = internal.WaitNodesReady(ctx, hostClient, nodeContainers(containers)); err != nil {
+		return fmt.Errorf("unable to wait for the nodes to be ready: %w", err)
+	}
+
+	// Restarted nodes may get other addresses.
+	if err = reloadLoadBalancer(ctx, hostClient, clusterName); err != nil {
+		return err
+	}
+
+	if !managedSwarm(primary) {
+		return nil
+	}
+
+	// The client resolves the host port the daemon of the primary node is published on again.
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	if err = internal.RestoreDrainedNodes(ctx, swarmClient); err != nil {
+		return fmt.Errorf("unable to restore the drained nodes: %w", err)
+	}
+
+	if err = internal.WaitSwarmConverged(ctx, swarmClient); err != nil {
+		return fmt.Errorf("unable to wait for the swarm to converge: %w", err)
+	}
+
+	return nil
+}
+
+// nodeContainers returns the nodes among the containers of a cluster, leaving auxiliary containers aside.
+func nodeContainers(containers []types.Container) []types.Container {
+	var nodes []types.Container
+
+	for _, container := range containers {
+		if _, ok := container.Labels[internal.ComponentLabel]; !ok {
+			nodes = append(nodes, container)
+		}
 	}
 
-	return internal.StartContainers(ctx, hostClient, containers)
+	return nodes
 }
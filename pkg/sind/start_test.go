@@ -0,0 +1,18 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNodeContainers(t *testing.T) {
+	primary := types.Container{ID: "primary", Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary}}
+	worker := types.Container{ID: "worker", Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRoleWorker}}
+	lb := types.Container{ID: "lb", Labels: map[string]string{internal.ComponentLabel: internal.ComponentLoadBalancer}}
+
+	assert.Equal(t, []types.Container{primary, worker}, nodeContainers([]types.Container{primary, lb, worker}))
+	assert.Empty(t, nodeContainers([]types.Container{lb}))
+}
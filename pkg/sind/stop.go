@@ -3,17 +3,95 @@ package sind
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	docker "github.com/docker/docker/client"
 	"github.com/jlevesy/sind/pkg/sind/internal"
 )
 
+// StopOptions tunes the stop of a cluster.
+type StopOptions struct {
+	// Timeout is the time given to the nodes to stop before being killed, daemon default if zero.
+	Timeout time.Duration
+	// Drain drains the swarm nodes before stopping them, for the tasks to shut down cleanly. The nodes availability is
+	// restored when the cluster is started again.
+	Drain bool
+}
+
 // StopCluster stops all nodes of a cluster.
 func StopCluster(ctx context.Context, hostClient *docker.Client, clusterName string) error {
+	return StopClusterWithOptions(ctx, hostClient, clusterName, StopOptions{})
+}
+
+// StopClusterWithOptions stops all nodes of a cluster according to the given options.
+func StopClusterWithOptions(ctx context.Context, hostClient *docker.Client, clusterName string, opts StopOptions) error {
 	containers, err := internal.ListContainers(ctx, hostClient, clusterName)
 	if err != nil {
-		return fmt.Errorf("unable to get container list %v", err)
+		return fmt.Errorf("unable to get container list %w", err)
+	}
+
+	if opts.Drain {
+		if err = drainCluster(ctx, hostClient, clusterName, containers); err != nil {
+			return err
+		}
+	}
+
+	return internal.StopContainers(ctx, hostClient, containers, stopTimeout(opts.Timeout))
+}
+
+// drainCluster drains the nodes of the swarm of a cluster, a stopped cluster has nothing left to drain.
+func drainCluster(ctx context.Context, hostClient *docker.Client, clusterName string, containers []types.Container) error {
+	primary, ok := primaryNode(containers)
+	if !ok {
+		return ErrClusterNotFound
+	}
+
+	if !managedSwarm(primary) {
+		return ErrDrainUnmanagedSwarm
+	}
+
+	if primary.State != "running" {
+		return nil
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	if err = internal.DrainNodes(ctx, swarmClient); err != nil {
+		return fmt.Errorf("unable to drain the nodes: %w", err)
+	}
+
+	return nil
+}
+
+// primaryNode returns the primary node among the containers of a cluster.
+func primaryNode(containers []types.Container) (types.Container, bool) {
+	for _, container := range containers {
+		if container.Labels[internal.NodeRoleLabel] == internal.NodeRolePrimary {
+			return container, true
+		}
+	}
+
+	return types.Container{}, false
+}
+
+// managedSwarm returns true if the nodes of the cluster of a primary node form their own swarm.
+func managedSwarm(primary types.Container) bool {
+	_, external := primary.Labels[internal.ExternalSwarmLabel]
+
+	return primary.Labels[internal.PlainClusterLabel] != "true" && !external
+}
+
+// stopTimeout returns the stop timeout to give to the daemon, nil for its default if timeout is zero.
+func stopTimeout(timeout time.Duration) *time.Duration {
+	if timeout <= 0 {
+		return nil
 	}
 
-	return internal.StopContainers(ctx, hostClient, containers)
+	return &timeout
 }
@@ -0,0 +1,60 @@
+package sind
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrimaryNode(t *testing.T) {
+	worker := types.Container{ID: "worker", Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRoleWorker}}
+	primary := types.Container{ID: "primary", Labels: map[string]string{internal.NodeRoleLabel: internal.NodeRolePrimary}}
+
+	node, ok := primaryNode([]types.Container{worker, primary})
+	assert.True(t, ok)
+	assert.Equal(t, primary, node)
+
+	_, ok = primaryNode([]types.Container{worker})
+	assert.False(t, ok)
+}
+
+func TestManagedSwarm(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		labels   map[string]string
+		expected bool
+	}{
+		{
+			desc:     "with a swarm formed by sind",
+			labels:   map[string]string{},
+			expected: true,
+		},
+		{
+			desc:   "with plain nodes",
+			labels: map[string]string{internal.PlainClusterLabel: "true"},
+		},
+		{
+			desc:   "with nodes joined to an external swarm",
+			labels: map[string]string{internal.ExternalSwarmLabel: "10.0.0.1:2377"},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, managedSwarm(types.Container{Labels: test.labels}))
+		})
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	assert.Nil(t, stopTimeout(0))
+	assert.Nil(t, stopTimeout(-time.Second))
+
+	timeout := stopTimeout(10 * time.Second)
+	if assert.NotNil(t, timeout) {
+		assert.Equal(t, 10*time.Second, *timeout)
+	}
+}
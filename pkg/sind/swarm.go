@@ -0,0 +1,87 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// SwarmSpecUpdate represents the swarm wide settings to update, the nil ones are left unchanged.
+type SwarmSpecUpdate struct {
+	// TaskHistoryLimit is the amount of terminated tasks kept per slot.
+	TaskHistoryLimit *int64
+	// Autolock locks the managers on restart until they are unlocked with the unlock key of the swarm, see
+	// SwarmUnlockKey. A stopped cluster with autolock enabled can't be started again by sind.
+	Autolock *bool
+	// CertExpiry is the validity period of the node certificates.
+	CertExpiry *time.Duration
+	// DispatcherHeartbeat is the delay between the heartbeats sent by the nodes to the managers.
+	DispatcherHeartbeat *time.Duration
+}
+
+func (u SwarmSpecUpdate) validate() error {
+	if u.TaskHistoryLimit != nil && *u.TaskHistoryLimit < 0 {
+		return ErrInvalidTaskHistoryLimit
+	}
+
+	if (u.CertExpiry != nil && *u.CertExpiry <= 0) || (u.DispatcherHeartbeat != nil && *u.DispatcherHeartbeat <= 0) {
+		return ErrInvalidSwarmPeriod
+	}
+
+	return nil
+}
+
+func (u SwarmSpecUpdate) apply(spec *swarm.Spec) {
+	if u.TaskHistoryLimit != nil {
+		spec.Orchestration.TaskHistoryRetentionLimit = u.TaskHistoryLimit
+	}
+
+	if u.Autolock != nil {
+		spec.EncryptionConfig.AutoLockManagers = *u.Autolock
+	}
+
+	if u.CertExpiry != nil {
+		spec.CAConfig.NodeCertExpiry = *u.CertExpiry
+	}
+
+	if u.DispatcherHeartbeat != nil {
+		spec.Dispatcher.HeartbeatPeriod = *u.DispatcherHeartbeat
+	}
+}
+
+// UpdateSwarmSpec updates the swarm wide settings of a cluster after its creation.
+func UpdateSwarmSpec(ctx context.Context, hostClient *docker.Client, clusterName string, update SwarmSpecUpdate) error {
+	if err := update.validate(); err != nil {
+		return err
+	}
+
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return err
+	}
+
+	defer swarmClient.Close()
+
+	return internal.UpdateSwarmSpec(ctx, swarmClient, update.apply)
+}
+
+// SwarmUnlockKey returns the key unlocking the managers of a cluster which swarm has autolock enabled.
+func SwarmUnlockKey(ctx context.Context, hostClient *docker.Client, clusterName string) (string, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return "", err
+	}
+
+	defer swarmClient.Close()
+
+	resp, err := swarmClient.SwarmGetUnlockKey(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to get the unlock key: %w", err)
+	}
+
+	return resp.UnlockKey, nil
+}
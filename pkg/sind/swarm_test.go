@@ -0,0 +1,38 @@
+package sind
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSwarmSpecUpdate(t *testing.T) {
+	historyLimit := int64(2)
+	autolock := true
+	heartbeat := 2 * time.Second
+
+	spec := swarm.Spec{}
+	spec.CAConfig.NodeCertExpiry = 90 * 24 * time.Hour
+
+	update := SwarmSpecUpdate{TaskHistoryLimit: &historyLimit, Autolock: &autolock, DispatcherHeartbeat: &heartbeat}
+	assert.NoError(t, update.validate())
+
+	update.apply(&spec)
+
+	assert.Equal(t, &historyLimit, spec.Orchestration.TaskHistoryRetentionLimit)
+	assert.True(t, spec.EncryptionConfig.AutoLockManagers)
+	assert.Equal(t, 90*24*time.Hour, spec.CAConfig.NodeCertExpiry)
+	assert.Equal(t, 2*time.Second, spec.Dispatcher.HeartbeatPeriod)
+}
+
+func TestSwarmSpecUpdateValidation(t *testing.T) {
+	negativeLimit := int64(-1)
+	zero := time.Duration(0)
+
+	assert.True(t, errors.Is(SwarmSpecUpdate{TaskHistoryLimit: &negativeLimit}.validate(), ErrInvalidTaskHistoryLimit))
+	assert.True(t, errors.Is(SwarmSpecUpdate{CertExpiry: &zero}.validate(), ErrInvalidSwarmPeriod))
+	assert.True(t, errors.Is(SwarmSpecUpdate{DispatcherHeartbeat: &zero}.validate(), ErrInvalidConfiguration))
+}
@@ -0,0 +1,73 @@
+package sind
+
+import (
+	"context"
+
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// TaskSpec describes a one-off task to run on a cluster.
+type TaskSpec struct {
+	Image    string
+	Command  []string
+	Env      []string
+	Networks []string
+}
+
+// TaskResult is the outcome of a one-off task.
+type TaskResult struct {
+	State    string
+	ExitCode int
+	Err      string
+
+	Stdout []byte
+	Stderr []byte
+}
+
+// RunTask runs a one-off task on the cluster, waits for its completion, then returns its exit status and output.
+// The underlying swarm service is removed once the task is over.
+func RunTask(ctx context.Context, hostClient *docker.Client, clusterName string, spec TaskSpec) (*TaskResult, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	res, err := internal.RunTask(ctx, swarmClient, spec.serviceSpec())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskResult{
+		State:    string(res.State),
+		ExitCode: res.ExitCode,
+		Err:      res.Err,
+		Stdout:   res.Stdout,
+		Stderr:   res.Stderr,
+	}, nil
+}
+
+// serviceSpec returns the spec of the swarm service running the task.
+func (s *TaskSpec) serviceSpec() swarm.ServiceSpec {
+	serviceSpec := swarm.ServiceSpec{
+		TaskTemplate: swarm.TaskSpec{
+			ContainerSpec: &swarm.ContainerSpec{
+				Image:   s.Image,
+				Command: s.Command,
+				Env:     s.Env,
+			},
+		},
+	}
+
+	for _, network := range s.Networks {
+		serviceSpec.TaskTemplate.Networks = append(
+			serviceSpec.TaskTemplate.Networks,
+			swarm.NetworkAttachmentConfig{Target: network},
+		)
+	}
+
+	return serviceSpec
+}
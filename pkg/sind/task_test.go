@@ -0,0 +1,49 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskSpecServiceSpec(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		spec     TaskSpec
+		expected swarm.ServiceSpec
+	}{
+		{
+			desc: "without networks",
+			spec: TaskSpec{Image: "alpine", Command: []string{"echo", "hello"}, Env: []string{"FOO=bar"}},
+			expected: swarm.ServiceSpec{
+				TaskTemplate: swarm.TaskSpec{
+					ContainerSpec: &swarm.ContainerSpec{
+						Image:   "alpine",
+						Command: []string{"echo", "hello"},
+						Env:     []string{"FOO=bar"},
+					},
+				},
+			},
+		},
+		{
+			desc: "with networks",
+			spec: TaskSpec{Image: "alpine", Networks: []string{"front", "back"}},
+			expected: swarm.ServiceSpec{
+				TaskTemplate: swarm.TaskSpec{
+					ContainerSpec: &swarm.ContainerSpec{Image: "alpine"},
+					Networks: []swarm.NetworkAttachmentConfig{
+						{Target: "front"},
+						{Target: "back"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.spec.serviceSpec())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// bandwidthUnits are the units of the bandwidths parsed by ParseBandwidth, longest suffix first.
+var bandwidthUnits = []struct {
+	suffix     string
+	multiplier uint64
+}{
+	{"gbit", 1000 * 1000 * 1000},
+	{"mbit", 1000 * 1000},
+	{"kbit", 1000},
+	{"bit", 1},
+}
+
+// ParseBandwidth parses a bandwidth in bits per second, formatted like the rates of tc, e.g. 500kbit, 10mbit or 1gbit.
+func ParseBandwidth(raw string) (uint64, error) {
+	value := strings.ToLower(strings.TrimSpace(raw))
+
+	for _, unit := range bandwidthUnits {
+		if !strings.HasSuffix(value, unit.suffix) {
+			continue
+		}
+
+		amount, err := strconv.ParseUint(strings.TrimSuffix(value, unit.suffix), 10, 64)
+		if err != nil || amount == 0 {
+			break
+		}
+
+		return amount * unit.multiplier, nil
+	}
+
+	return 0, fmt.Errorf("%w: %q, expected e.g. 500kbit, 10mbit or 1gbit", ErrInvalidBandwidth, raw)
+}
+
+// ThrottleConfiguration represents the bandwidth caps of the nodes of a cluster.
+type ThrottleConfiguration struct {
+	// Rate is the outbound bandwidth of each node on the cluster network, in bits per second.
+	Rate uint64
+	// Nodes are the names of the nodes to throttle (e.g. worker-0), all the nodes of the cluster if empty.
+	Nodes []string
+}
+
+// ThrottleCluster caps the bandwidth of the nodes of a cluster on the cluster network, e.g. to measure the behavior of
+// services and of the swarm gossip under constrained links. Caps apply to the outbound traffic of each node, the
+// traffic between two nodes is then capped by both. Previous caps of the nodes are replaced.
+// It requires the tc command of iproute2 in the node image, and is reset when a node restarts.
+func ThrottleCluster(ctx context.Context, hostClient *docker.Client, clusterName string, params ThrottleConfiguration) error {
+	if params.Rate == 0 {
+		return fmt.Errorf("%w: the rate must be positive", ErrInvalidBandwidth)
+	}
+
+	nodes, err := listSelectedNodes(ctx, hostClient, clusterName, params.Nodes)
+	if err != nil {
+		return err
+	}
+
+	ctx, span := internal.StartSpan(ctx, "sind.throttle", map[string]string{internal.ClusterAttribute: clusterName})
+
+	err = internal.ThrottleContainers(ctx, hostClient, nodes, params.Rate)
+
+	span.End(err)
+
+	if err != nil {
+		return fmt.Errorf("unable to throttle the nodes: %w", err)
+	}
+
+	return nil
+}
+
+// UnthrottleCluster removes the bandwidth caps of given nodes of a cluster, all the nodes of the cluster if empty.
+func UnthrottleCluster(ctx context.Context, hostClient *docker.Client, clusterName string, nodeNames []string) error {
+	nodes, err := listSelectedNodes(ctx, hostClient, clusterName, nodeNames)
+	if err != nil {
+		return err
+	}
+
+	if err = internal.UnthrottleContainers(ctx, hostClient, nodes); err != nil {
+		return fmt.Errorf("unable to unthrottle the nodes: %w", err)
+	}
+
+	return nil
+}
+
+// listSelectedNodes returns the nodes of a cluster with given names, all of them if names is empty.
+func listSelectedNodes(ctx context.Context, hostClient internal.ContainerLister, clusterName string, names []string) ([]types.Container, error) {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return nil, ErrClusterNotFound
+	}
+
+	nodes = selectNodes(clusterName, nodes, names)
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w: none of the nodes %v", ErrNodeNotFound, names)
+	}
+
+	return nodes, nil
+}
@@ -0,0 +1,64 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBandwidth(t *testing.T) {
+	testCases := []struct {
+		raw           string
+		expectedRate  uint64
+		expectedError error
+	}{
+		{raw: "800bit", expectedRate: 800},
+		{raw: "500kbit", expectedRate: 500000},
+		{raw: "10Mbit", expectedRate: 10000000},
+		{raw: "1gbit", expectedRate: 1000000000},
+		{raw: "10mb", expectedError: ErrInvalidBandwidth},
+		{raw: "0mbit", expectedError: ErrInvalidBandwidth},
+		{raw: "-1mbit", expectedError: ErrInvalidBandwidth},
+		{raw: "", expectedError: ErrInvalidBandwidth},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.raw, func(t *testing.T) {
+			rate, err := ParseBandwidth(test.raw)
+			if test.expectedError != nil {
+				assert.True(t, errors.Is(err, test.expectedError))
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expectedRate, rate)
+		})
+	}
+}
+
+func TestListSelectedNodes(t *testing.T) {
+	nodes := []types.Container{
+		{ID: "primary", Names: []string{"/sind-foo-manager-0"}},
+		{ID: "worker", Names: []string{"/sind-foo-worker-0"}},
+	}
+
+	client := internal.ContainerListerMock(func(ctx context.Context, opts types.ContainerListOptions) ([]types.Container, error) {
+		return nodes, nil
+	})
+
+	selected, err := listSelectedNodes(context.Background(), client, "foo", nil)
+	require.NoError(t, err)
+	assert.Equal(t, nodes, selected)
+
+	selected, err = listSelectedNodes(context.Background(), client, "foo", []string{"worker-0"})
+	require.NoError(t, err)
+	assert.Equal(t, nodes[1:], selected)
+
+	_, err = listSelectedNodes(context.Background(), client, "foo", []string{"worker-1"})
+	assert.True(t, errors.Is(err, ErrNodeNotFound))
+}
@@ -0,0 +1,123 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/swarm"
+	docker "github.com/docker/docker/client"
+)
+
+// Topology is a deterministic snapshot of the nodes and services of a cluster, suitable for golden file comparison.
+type Topology struct {
+	Nodes    []TopologyNode    `json:"nodes"`
+	Services []TopologyService `json:"services"`
+}
+
+// TopologyNode describes a node of the cluster.
+type TopologyNode struct {
+	Hostname     string `json:"hostname"`
+	Role         string `json:"role"`
+	Availability string `json:"availability"`
+	State        string `json:"state"`
+}
+
+// TopologyService describes a service deployed on the cluster.
+type TopologyService struct {
+	Name           string   `json:"name"`
+	Image          string   `json:"image"`
+	Mode           string   `json:"mode"`
+	Replicas       uint64   `json:"replicas,omitempty"`
+	PublishedPorts []string `json:"published_ports,omitempty"`
+}
+
+// ClusterTopology returns a snapshot of the current topology of the cluster.
+func ClusterTopology(ctx context.Context, hostClient *docker.Client, clusterName string) (*Topology, error) {
+	swarmClient, err := ClusterClient(ctx, hostClient, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer swarmClient.Close()
+
+	nodes, err := swarmClient.NodeList(ctx, types.NodeListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list swarm nodes: %w", err)
+	}
+
+	services, err := swarmClient.ServiceList(ctx, types.ServiceListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("unable to list swarm services: %w", err)
+	}
+
+	topology := buildTopology(nodes, services)
+
+	return &topology, nil
+}
+
+func buildTopology(nodes []swarm.Node, services []swarm.Service) Topology {
+	topology := Topology{
+		Nodes:    make([]TopologyNode, 0, len(nodes)),
+		Services: make([]TopologyService, 0, len(services)),
+	}
+
+	for _, node := range nodes {
+		topology.Nodes = append(topology.Nodes, TopologyNode{
+			Hostname:     node.Description.Hostname,
+			Role:         string(node.Spec.Role),
+			Availability: string(node.Spec.Availability),
+			State:        string(node.Status.State),
+		})
+	}
+
+	for _, service := range services {
+		topologyService := TopologyService{
+			Name:  service.Spec.Name,
+			Mode:  "global",
+			Image: serviceImage(service),
+		}
+
+		if replicated := service.Spec.Mode.Replicated; replicated != nil {
+			topologyService.Mode = "replicated"
+
+			if replicated.Replicas != nil {
+				topologyService.Replicas = *replicated.Replicas
+			}
+		}
+
+		if service.Spec.EndpointSpec != nil {
+			for _, port := range service.Spec.EndpointSpec.Ports {
+				topologyService.PublishedPorts = append(
+					topologyService.PublishedPorts,
+					fmt.Sprintf("%d:%d/%s", port.PublishedPort, port.TargetPort, port.Protocol),
+				)
+			}
+
+			sort.Strings(topologyService.PublishedPorts)
+		}
+
+		topology.Services = append(topology.Services, topologyService)
+	}
+
+	sort.Slice(topology.Nodes, func(i, j int) bool { return topology.Nodes[i].Hostname < topology.Nodes[j].Hostname })
+	sort.Slice(topology.Services, func(i, j int) bool { return topology.Services[i].Name < topology.Services[j].Name })
+
+	return topology
+}
+
+func serviceImage(service swarm.Service) string {
+	if service.Spec.TaskTemplate.ContainerSpec == nil {
+		return ""
+	}
+
+	// Swarm pins images to their digest, which changes from one run to the other.
+	image := service.Spec.TaskTemplate.ContainerSpec.Image
+	if idx := strings.Index(image, "@"); idx >= 0 {
+		image = image[:idx]
+	}
+
+	return image
+}
@@ -0,0 +1,69 @@
+package sind
+
+import (
+	"testing"
+
+	"github.com/docker/docker/api/types/swarm"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildTopology(t *testing.T) {
+	replicas := uint64(3)
+
+	nodes := []swarm.Node{
+		{
+			ID:          "bbbb",
+			Description: swarm.NodeDescription{Hostname: "sind-foo-worker-0"},
+			Spec:        swarm.NodeSpec{Role: swarm.NodeRoleWorker, Availability: swarm.NodeAvailabilityActive},
+			Status:      swarm.NodeStatus{State: swarm.NodeStateReady},
+		},
+		{
+			ID:          "aaaa",
+			Description: swarm.NodeDescription{Hostname: "sind-foo-manager-0"},
+			Spec:        swarm.NodeSpec{Role: swarm.NodeRoleManager, Availability: swarm.NodeAvailabilityDrain},
+			Status:      swarm.NodeStatus{State: swarm.NodeStateReady},
+		},
+	}
+
+	services := []swarm.Service{
+		{
+			Spec: swarm.ServiceSpec{
+				Annotations: swarm.Annotations{Name: "web"},
+				TaskTemplate: swarm.TaskSpec{
+					ContainerSpec: &swarm.ContainerSpec{Image: "nginx:latest@sha256:abcdef"},
+				},
+				Mode: swarm.ServiceMode{Replicated: &swarm.ReplicatedService{Replicas: &replicas}},
+				EndpointSpec: &swarm.EndpointSpec{
+					Ports: []swarm.PortConfig{
+						{Protocol: swarm.PortConfigProtocolTCP, TargetPort: 443, PublishedPort: 8443},
+						{Protocol: swarm.PortConfigProtocolTCP, TargetPort: 80, PublishedPort: 8080},
+					},
+				},
+			},
+		},
+		{
+			Spec: swarm.ServiceSpec{
+				Annotations: swarm.Annotations{Name: "agent"},
+				TaskTemplate: swarm.TaskSpec{
+					ContainerSpec: &swarm.ContainerSpec{Image: "agent:1.0"},
+				},
+				Mode: swarm.ServiceMode{Global: &swarm.GlobalService{}},
+			},
+		},
+	}
+
+	assert.Equal(
+		t,
+		Topology{
+			Nodes: []TopologyNode{
+				{Hostname: "sind-foo-manager-0", Role: "manager", Availability: "drain", State: "ready"},
+				{Hostname: "sind-foo-worker-0", Role: "worker", Availability: "active", State: "ready"},
+			},
+			Services: []TopologyService{
+				{Name: "agent", Image: "agent:1.0", Mode: "global"},
+				{Name: "web", Image: "nginx:latest", Mode: "replicated", Replicas: 3, PublishedPorts: []string{"8080:80/tcp", "8443:443/tcp"}},
+			},
+		},
+		buildTopology(nodes, services),
+	)
+}
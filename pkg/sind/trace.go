@@ -0,0 +1,36 @@
+package sind
+
+import (
+	"context"
+	"time"
+
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// Tracer starts the spans of the cluster creations, image pushes and cluster deletions, one per operation, per phase
+// and per node. It is shaped after the OpenTelemetry tracer, which an adapter of a few lines can plug in.
+type Tracer = internal.Tracer
+
+// Span is a traced operation, ended once with the error it failed with, nil if it succeeded.
+type Span = internal.Span
+
+// WithTracer returns a context tracing the operations it is passed to with given tracer.
+func WithTracer(ctx context.Context, tracer Tracer) context.Context {
+	return internal.WithTracer(ctx, tracer)
+}
+
+// tracePhase runs a phase of an operation in its own span, and records its duration in d unless it is nil.
+func tracePhase(ctx context.Context, name string, d *time.Duration, phase func(context.Context) error) error {
+	ctx, span := internal.StartSpan(ctx, name, nil)
+	start := time.Now()
+
+	err := phase(ctx)
+
+	if d != nil {
+		*d = time.Since(start)
+	}
+
+	span.End(err)
+
+	return err
+}
@@ -0,0 +1,51 @@
+package sind
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type tracerMock struct {
+	started []string
+	ended   map[string]error
+}
+
+func (t *tracerMock) Start(ctx context.Context, name string, _ map[string]string) (context.Context, Span) {
+	t.started = append(t.started, name)
+
+	return ctx, spanMock(func(err error) { t.ended[name] = err })
+}
+
+type spanMock func(error)
+
+func (s spanMock) End(err error) {
+	s(err)
+}
+
+func TestTracePhase(t *testing.T) {
+	tracer := &tracerMock{ended: make(map[string]error)}
+	ctx := WithTracer(context.Background(), tracer)
+	phaseErr := errors.New("nope")
+
+	var d time.Duration
+
+	err := tracePhase(ctx, "sind.test.ok", &d, func(context.Context) error {
+		time.Sleep(time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.True(t, d >= time.Millisecond)
+
+	err = tracePhase(ctx, "sind.test.failed", nil, func(context.Context) error {
+		return phaseErr
+	})
+	assert.Equal(t, phaseErr, err)
+
+	assert.Equal(t, []string{"sind.test.ok", "sind.test.failed"}, tracer.started)
+	assert.Equal(t, map[string]error{"sind.test.ok": nil, "sind.test.failed": phaseErr}, tracer.ended)
+}
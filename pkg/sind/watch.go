@@ -0,0 +1,229 @@
+package sind
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+)
+
+// NotificationKind is the kind of a cluster lifecycle notification.
+type NotificationKind string
+
+// Cluster lifecycle notifications.
+const (
+	// NodeDied is notified when a node container exits, whatever the reason.
+	NodeDied NotificationKind = "node-died"
+	// NodeRestarted is notified when a node container starts again.
+	NodeRestarted NotificationKind = "node-restarted"
+	// NodeUnhealthy is notified when the healthcheck of a node container starts failing.
+	NodeUnhealthy NotificationKind = "node-unhealthy"
+	// NetworkRemoved is notified when a network the nodes are attached to is removed.
+	NetworkRemoved NotificationKind = "network-removed"
+
+	// ClusterCreated, ClusterStopped and ClusterDeleted are notified when the primary node of a cluster is created,
+	// stopped or removed. They are only notified by WatchClusters.
+	ClusterCreated NotificationKind = "cluster-created"
+	ClusterStopped NotificationKind = "cluster-stopped"
+	ClusterDeleted NotificationKind = "cluster-deleted"
+)
+
+// Notification is a lifecycle change of a cluster, as seen by the docker host.
+type Notification struct {
+	Kind    NotificationKind `json:"kind"`
+	Time    time.Time        `json:"time"`
+	Cluster string           `json:"cluster"`
+
+	// Node is the name of the node concerned by a node notification, e.g. manager-0.
+	Node        string `json:"node,omitempty"`
+	ContainerID string `json:"container_id,omitempty"`
+	// ExitCode is the exit code of the node container of a NodeDied notification.
+	ExitCode int `json:"exit_code,omitempty"`
+
+	// Network is the name of the network concerned by a network notification.
+	Network string `json:"network,omitempty"`
+}
+
+// WatchCluster sends the lifecycle notifications of a cluster to out, until the context is done.
+func WatchCluster(ctx context.Context, hostClient *docker.Client, clusterName string, out chan<- Notification) error {
+	nodes, err := internal.ListNodes(ctx, hostClient, clusterName)
+	if err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	if len(nodes) == 0 {
+		return ErrClusterNotFound
+	}
+
+	containerMessages, containerErrs := internal.HostEvents(ctx, hostClient, clusterName)
+	networkMessages, networkErrs := internal.NetworkEvents(ctx, hostClient, nodeNetworks(nodes))
+
+	for {
+		var msg events.Message
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err = <-containerErrs:
+		case err = <-networkErrs:
+		case msg = <-containerMessages:
+		case msg = <-networkMessages:
+		}
+
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("unable to stream the docker host events: %w", err)
+		}
+
+		notification, ok := notificationOf(clusterName, msg)
+		if !ok {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- notification:
+		}
+	}
+}
+
+// WatchClusters sends the lifecycle notifications of all the clusters of the docker host to out, until the context is
+// done: clusters created, stopped and deleted, and nodes becoming unhealthy.
+func WatchClusters(ctx context.Context, hostClient *docker.Client, out chan<- Notification) error {
+	messages, errs := internal.ClustersEvents(ctx, hostClient)
+
+	for {
+		var msg events.Message
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-errs:
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return fmt.Errorf("unable to stream the docker host events: %w", err)
+		case msg = <-messages:
+		}
+
+		notification, ok := clusterNotificationOf(msg)
+		if !ok {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case out <- notification:
+		}
+	}
+}
+
+// nodeNetworks returns the names of the networks the nodes are attached to.
+func nodeNetworks(nodes []types.Container) []string {
+	networks := make(map[string]struct{})
+
+	for _, node := range nodes {
+		if name, ok := node.Labels[internal.NetworkNameLabel]; ok {
+			networks[name] = struct{}{}
+		}
+	}
+
+	result := make([]string, 0, len(networks))
+
+	for name := range networks {
+		result = append(result, name)
+	}
+
+	sort.Strings(result)
+
+	return result
+}
+
+// notificationOf converts a docker host event to a notification, if it is one.
+func notificationOf(clusterName string, msg events.Message) (Notification, bool) {
+	notification := Notification{Time: time.Unix(0, msg.TimeNano), Cluster: clusterName}
+
+	switch {
+	case msg.Type == events.NetworkEventType && msg.Action == "destroy":
+		notification.Kind = NetworkRemoved
+		notification.Network = msg.Actor.Attributes["name"]
+
+		return notification, true
+	case msg.Type != events.ContainerEventType:
+		return Notification{}, false
+	}
+
+	// Container events carry the container labels, auxiliary containers aren't nodes.
+	if _, ok := msg.Actor.Attributes[internal.ComponentLabel]; ok {
+		return Notification{}, false
+	}
+
+	switch msg.Action {
+	case "die":
+		notification.Kind = NodeDied
+		notification.ExitCode, _ = strconv.Atoi(msg.Actor.Attributes["exitCode"])
+	case "start":
+		notification.Kind = NodeRestarted
+	case unhealthyAction:
+		notification.Kind = NodeUnhealthy
+	default:
+		return Notification{}, false
+	}
+
+	notification.Node = nodeName(clusterName, msg.Actor.Attributes["name"])
+	notification.ContainerID = msg.Actor.ID
+
+	return notification, true
+}
+
+// unhealthyAction is the action of the event emitted when the healthcheck of a container starts failing.
+const unhealthyAction = "health_status: unhealthy"
+
+// clusterNotificationOf converts a docker host event of a cluster container to a notification, if it is one.
+func clusterNotificationOf(msg events.Message) (Notification, bool) {
+	attributes := msg.Actor.Attributes
+
+	if _, ok := attributes[internal.ComponentLabel]; ok {
+		return Notification{}, false
+	}
+
+	notification := Notification{Time: time.Unix(0, msg.TimeNano), Cluster: attributes[internal.ClusterNameLabel]}
+
+	if msg.Action == unhealthyAction {
+		notification.Kind = NodeUnhealthy
+		notification.Node = nodeName(notification.Cluster, attributes["name"])
+		notification.ContainerID = msg.Actor.ID
+
+		return notification, true
+	}
+
+	// The lifecycle of a cluster is the one of its primary node.
+	if attributes[internal.NodeRoleLabel] != internal.NodeRolePrimary {
+		return Notification{}, false
+	}
+
+	switch msg.Action {
+	case "create":
+		notification.Kind = ClusterCreated
+	case "stop":
+		notification.Kind = ClusterStopped
+	case "destroy":
+		notification.Kind = ClusterDeleted
+	default:
+		return Notification{}, false
+	}
+
+	return notification, true
+}
@@ -0,0 +1,195 @@
+package sind
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/events"
+	"github.com/jlevesy/sind/pkg/sind/internal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationOf(t *testing.T) {
+	at := time.Unix(0, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC).UnixNano())
+
+	testCases := []struct {
+		desc                 string
+		msg                  events.Message
+		expectedNotification Notification
+		expectedOK           bool
+	}{
+		{
+			desc: "node died",
+			msg: events.Message{
+				Type:     events.ContainerEventType,
+				Action:   "die",
+				Actor:    events.Actor{ID: "abcd", Attributes: map[string]string{"name": "sind-test-worker-0", "exitCode": "137"}},
+				TimeNano: at.UnixNano(),
+			},
+			expectedNotification: Notification{Kind: NodeDied, Time: at, Cluster: "test", Node: "worker-0", ContainerID: "abcd", ExitCode: 137},
+			expectedOK:           true,
+		},
+		{
+			desc: "node restarted",
+			msg: events.Message{
+				Type:     events.ContainerEventType,
+				Action:   "start",
+				Actor:    events.Actor{ID: "abcd", Attributes: map[string]string{"name": "sind-test-manager-0"}},
+				TimeNano: at.UnixNano(),
+			},
+			expectedNotification: Notification{Kind: NodeRestarted, Time: at, Cluster: "test", Node: "manager-0", ContainerID: "abcd"},
+			expectedOK:           true,
+		},
+		{
+			desc: "node unhealthy",
+			msg: events.Message{
+				Type:     events.ContainerEventType,
+				Action:   "health_status: unhealthy",
+				Actor:    events.Actor{ID: "abcd", Attributes: map[string]string{"name": "sind-test-worker-1"}},
+				TimeNano: at.UnixNano(),
+			},
+			expectedNotification: Notification{Kind: NodeUnhealthy, Time: at, Cluster: "test", Node: "worker-1", ContainerID: "abcd"},
+			expectedOK:           true,
+		},
+		{
+			desc: "network removed",
+			msg: events.Message{
+				Type:     events.NetworkEventType,
+				Action:   "destroy",
+				Actor:    events.Actor{ID: "efgh", Attributes: map[string]string{"name": "test-net"}},
+				TimeNano: at.UnixNano(),
+			},
+			expectedNotification: Notification{Kind: NetworkRemoved, Time: at, Cluster: "test", Network: "test-net"},
+			expectedOK:           true,
+		},
+		{
+			desc: "auxiliary container died",
+			msg: events.Message{
+				Type:   events.ContainerEventType,
+				Action: "die",
+				Actor:  events.Actor{ID: "abcd", Attributes: map[string]string{"name": "sind-test-lb", internal.ComponentLabel: internal.ComponentLoadBalancer}},
+			},
+		},
+		{
+			desc: "other container action",
+			msg: events.Message{
+				Type:   events.ContainerEventType,
+				Action: "exec_start",
+				Actor:  events.Actor{ID: "abcd", Attributes: map[string]string{"name": "sind-test-worker-0"}},
+			},
+		},
+		{
+			desc: "other network action",
+			msg: events.Message{
+				Type:   events.NetworkEventType,
+				Action: "connect",
+				Actor:  events.Actor{ID: "efgh", Attributes: map[string]string{"name": "test-net"}},
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			notification, ok := notificationOf("test", test.msg)
+
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedNotification, notification)
+		})
+	}
+}
+
+func TestClusterNotificationOf(t *testing.T) {
+	at := time.Unix(0, time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC).UnixNano())
+
+	primary := map[string]string{
+		"name":                    "sind-test-manager-0",
+		internal.ClusterNameLabel: "test",
+		internal.NodeRoleLabel:    internal.NodeRolePrimary,
+	}
+	worker := map[string]string{
+		"name":                    "sind-test-worker-0",
+		internal.ClusterNameLabel: "test",
+		internal.NodeRoleLabel:    internal.NodeRoleWorker,
+	}
+
+	testCases := []struct {
+		desc                 string
+		action               string
+		attributes           map[string]string
+		expectedNotification Notification
+		expectedOK           bool
+	}{
+		{
+			desc:                 "primary node created",
+			action:               "create",
+			attributes:           primary,
+			expectedNotification: Notification{Kind: ClusterCreated, Time: at, Cluster: "test"},
+			expectedOK:           true,
+		},
+		{
+			desc:                 "primary node stopped",
+			action:               "stop",
+			attributes:           primary,
+			expectedNotification: Notification{Kind: ClusterStopped, Time: at, Cluster: "test"},
+			expectedOK:           true,
+		},
+		{
+			desc:                 "primary node removed",
+			action:               "destroy",
+			attributes:           primary,
+			expectedNotification: Notification{Kind: ClusterDeleted, Time: at, Cluster: "test"},
+			expectedOK:           true,
+		},
+		{
+			desc:                 "worker unhealthy",
+			action:               "health_status: unhealthy",
+			attributes:           worker,
+			expectedNotification: Notification{Kind: NodeUnhealthy, Time: at, Cluster: "test", Node: "worker-0", ContainerID: "abcd"},
+			expectedOK:           true,
+		},
+		{
+			desc:       "worker removed",
+			action:     "destroy",
+			attributes: worker,
+		},
+		{
+			desc:       "primary node died",
+			action:     "die",
+			attributes: primary,
+		},
+		{
+			desc:   "auxiliary container unhealthy",
+			action: "health_status: unhealthy",
+			attributes: map[string]string{
+				"name":                    "sind-test-lb",
+				internal.ClusterNameLabel: "test",
+				internal.ComponentLabel:   internal.ComponentLoadBalancer,
+			},
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.desc, func(t *testing.T) {
+			notification, ok := clusterNotificationOf(events.Message{
+				Type:     events.ContainerEventType,
+				Action:   test.action,
+				Actor:    events.Actor{ID: "abcd", Attributes: test.attributes},
+				TimeNano: at.UnixNano(),
+			})
+
+			assert.Equal(t, test.expectedOK, ok)
+			assert.Equal(t, test.expectedNotification, notification)
+		})
+	}
+}
+
+func TestNodeNetworks(t *testing.T) {
+	node := func(network string) types.Container {
+		return types.Container{Labels: map[string]string{internal.NetworkNameLabel: network}}
+	}
+
+	networks := nodeNetworks([]types.Container{node("test-net"), node("shared-net"), node("test-net"), {}})
+
+	assert.Equal(t, []string{"shared-net", "test-net"}, networks)
+}
@@ -0,0 +1,98 @@
+// Package sindtest provides helpers to run integration tests against sind clusters.
+package sindtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	docker "github.com/docker/docker/client"
+	"github.com/jlevesy/sind/pkg/sind"
+)
+
+// ArtifactsDirEnv is the environment variable setting the directory the artifacts of the failed tests are captured in.
+const ArtifactsDirEnv = "SIND_ARTIFACTS_DIR"
+
+// captureTimeout bounds the capture of the artifacts of a cluster, the test context may already be done.
+const captureTimeout = 2 * time.Minute
+
+// CreateCluster creates a cluster for the duration of a test, and returns a client of its swarm. Once the test and its
+// subtests complete, the artifacts of the cluster are captured if the test failed, then the cluster is deleted.
+func CreateCluster(t testing.TB, hostClient *docker.Client, params sind.ClusterConfiguration) *docker.Client {
+	t.Helper()
+
+	ctx := context.Background()
+
+	if err := sind.CreateCluster(ctx, hostClient, params); err != nil {
+		// A partially created cluster is worth looking at too.
+		captureArtifacts(t, hostClient, params.ClusterName)
+		deleteCluster(t, hostClient, params.ClusterName)
+
+		t.Fatalf("unable to create cluster %q: %v", params.ClusterName, err)
+	}
+
+	t.Cleanup(func() {
+		deleteCluster(t, hostClient, params.ClusterName)
+	})
+
+	CaptureOnFailure(t, hostClient, params.ClusterName)
+
+	swarmClient, err := sind.ClusterClient(ctx, hostClient, params.ClusterName)
+	if err != nil {
+		t.Fatalf("unable to create a client of cluster %q: %v", params.ClusterName, err)
+	}
+
+	t.Cleanup(func() { _ = swarmClient.Close() })
+
+	return swarmClient
+}
+
+// CaptureOnFailure captures the artifacts of a cluster to ArtifactsDir once the test and its subtests complete, if the
+// test failed. It must be called after the cleanup deleting the cluster is registered, if any, as cleanups run in the
+// reverse order.
+func CaptureOnFailure(t testing.TB, hostClient *docker.Client, clusterName string) {
+	t.Cleanup(func() {
+		if t.Failed() {
+			captureArtifacts(t, hostClient, clusterName)
+		}
+	})
+}
+
+// ArtifactsDir returns the directory the artifacts of a test are captured in: a directory named after the test in the
+// directory set by SIND_ARTIFACTS_DIR, or in sind-artifacts in the temporary directory.
+func ArtifactsDir(t testing.TB) string {
+	root := os.Getenv(ArtifactsDirEnv)
+	if root == "" {
+		root = filepath.Join(os.TempDir(), "sind-artifacts")
+	}
+
+	return filepath.Join(root, testDirName(t.Name()))
+}
+
+// testDirName returns the name of the artifacts directory of a test, subtests being named after their parent.
+func testDirName(testName string) string {
+	return strings.NewReplacer("/", "_", " ", "_", ":", "_").Replace(testName)
+}
+
+func captureArtifacts(t testing.TB, hostClient *docker.Client, clusterName string) {
+	ctx, cancel := context.WithTimeout(context.Background(), captureTimeout)
+	defer cancel()
+
+	dir := filepath.Join(ArtifactsDir(t), clusterName)
+
+	if err := sind.CaptureArtifacts(ctx, hostClient, clusterName, dir); err != nil {
+		t.Logf("artifacts of cluster %q partially captured to %s: %v", clusterName, dir, err)
+		return
+	}
+
+	t.Logf("artifacts of cluster %q captured to %s", clusterName, dir)
+}
+
+func deleteCluster(t testing.TB, hostClient *docker.Client, clusterName string) {
+	if err := sind.DeleteClusterWithOptions(context.Background(), hostClient, clusterName, sind.DeleteOptions{Force: true}); err != nil {
+		t.Errorf("unable to delete cluster %q: %v", clusterName, err)
+	}
+}
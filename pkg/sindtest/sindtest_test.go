@@ -0,0 +1,23 @@
+package sindtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestArtifactsDir(t *testing.T) {
+	defer os.Setenv(ArtifactsDirEnv, os.Getenv(ArtifactsDirEnv))
+
+	os.Setenv(ArtifactsDirEnv, "/tmp/artifacts")
+
+	t.Run("with a subtest: named", func(t *testing.T) {
+		assert.Equal(t, filepath.Join("/tmp/artifacts", "TestArtifactsDir_with_a_subtest__named"), ArtifactsDir(t))
+	})
+
+	os.Unsetenv(ArtifactsDirEnv)
+
+	assert.Equal(t, filepath.Join(os.TempDir(), "sind-artifacts", "TestArtifactsDir"), ArtifactsDir(t))
+}
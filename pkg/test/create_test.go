@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 
@@ -30,6 +31,9 @@ func TestSindCanCreateACluster(t *testing.T) {
 		require.NoError(t, sind.DeleteCluster(ctx, hostClient, params.ClusterName))
 	}()
 
+	err = sind.CreateCluster(ctx, hostClient, params)
+	assert.True(t, errors.Is(err, sind.ErrClusterAlreadyExists))
+
 	swarmHost, err := sind.ClusterHost(ctx, hostClient, params.ClusterName)
 	require.NoError(t, err)
 
@@ -52,6 +56,12 @@ func TestSindCanCreateACluster(t *testing.T) {
 
 	assert.EqualValues(t, params.Workers, clusterInfos.Workers)
 	assert.EqualValues(t, params.Workers, clusterInfos.WorkersRunning)
+
+	topology, err := sind.ClusterTopology(ctx, hostClient, params.ClusterName)
+	require.NoError(t, err)
+
+	assert.Len(t, topology.Nodes, int(params.Managers+params.Workers))
+	assert.Empty(t, topology.Services)
 }
 
 func TestSindCanCreateMultipleClusters(t *testing.T) {
@@ -75,3 +85,80 @@ func TestSindCanCreateMultipleClusters(t *testing.T) {
 		}()
 	}
 }
+
+func TestSindRollsBackAFailedCreation(t *testing.T) {
+	ctx := context.Background()
+
+	hostClient, err := docker.NewClientWithOpts(docker.FromEnv, docker.WithAPIVersionNegotiation())
+	require.NoError(t, err)
+
+	hookErr := errors.New("post create hook failed")
+
+	params := sind.ClusterConfiguration{
+		ClusterName: "test_create_rollback",
+		NetworkName: "test_create_rollback",
+
+		Managers: 1,
+		Workers:  1,
+
+		PostCreateHooks: []sind.Hook{
+			func(context.Context, sind.HookEvent) error { return hookErr },
+		},
+	}
+
+	err = sind.CreateCluster(ctx, hostClient, params)
+	assert.True(t, errors.Is(err, hookErr))
+
+	leftovers, err := sind.LeftoverResources(ctx, hostClient, params.ClusterName)
+	require.NoError(t, err)
+	assert.Zero(t, leftovers)
+
+	params.KeepOnFailure = true
+
+	err = sind.CreateCluster(ctx, hostClient, params)
+	assert.True(t, errors.Is(err, hookErr))
+
+	defer func() {
+		require.NoError(t, sind.DeleteClusterWithOptions(ctx, hostClient, params.ClusterName, sind.DeleteOptions{Force: true}))
+	}()
+
+	clusterInfos, err := sind.InspectCluster(ctx, hostClient, params.ClusterName)
+	require.NoError(t, err)
+	require.NotNil(t, clusterInfos)
+
+	assert.EqualValues(t, params.Workers, clusterInfos.WorkersRunning)
+}
+
+func TestSindRollbackKeepsAReattachedNetwork(t *testing.T) {
+	ctx := context.Background()
+
+	hostClient, err := docker.NewClientWithOpts(docker.FromEnv, docker.WithAPIVersionNegotiation())
+	require.NoError(t, err)
+
+	params := sind.ClusterConfiguration{
+		ClusterName: "test_create_rollback_kept",
+		NetworkName: "test_create_rollback_kept",
+
+		Managers: 1,
+	}
+	require.NoError(t, sind.CreateCluster(ctx, hostClient, params))
+	require.NoError(t, sind.DeleteClusterWithOptions(ctx, hostClient, params.ClusterName, sind.DeleteOptions{KeepNetwork: true}))
+
+	defer func() {
+		require.NoError(t, sind.DeleteCluster(ctx, hostClient, params.ClusterName))
+	}()
+
+	hookErr := errors.New("post create hook failed")
+
+	params.PostCreateHooks = []sind.Hook{
+		func(context.Context, sind.HookEvent) error { return hookErr },
+	}
+
+	err = sind.CreateCluster(ctx, hostClient, params)
+	assert.True(t, errors.Is(err, hookErr))
+
+	leftovers, err := sind.ListLeftovers(ctx, hostClient, params.ClusterName)
+	require.NoError(t, err)
+	assert.Empty(t, leftovers.Containers)
+	assert.Equal(t, []string{params.NetworkName}, leftovers.Networks)
+}